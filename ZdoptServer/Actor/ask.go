@@ -0,0 +1,161 @@
+package Actor
+
+//ask.go
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+var (
+	ErrAskUnsupported = errors.New("actor does not support ask (no BaseActor mailbox)")
+	ErrNoReply        = errors.New("ask handled without a reply")
+)
+
+// Future 请求/响应结果，由处理器回复或请求被拒绝时完成
+type Future struct {
+	once  sync.Once
+	done  chan struct{}
+	value interface{}
+	err   error
+	trace uint64
+}
+
+func newFuture() *Future {
+	return &Future{done: make(chan struct{})}
+}
+
+// Done 结果就绪时关闭
+func (f *Future) Done() <-chan struct{} {
+	return f.done
+}
+
+// Result 等待结果，ctx 结束时返回 ctx 的错误
+func (f *Future) Result(ctx context.Context) (interface{}, error) {
+	select {
+	case <-f.done:
+		return f.value, f.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// TraceID 请求所在的调用链 ID（开启追踪时），用于关联回复与处理方的 span
+func (f *Future) TraceID() uint64 {
+	return f.trace
+}
+
+// resolve 完成 Future，只有第一次生效
+func (f *Future) resolve(value interface{}, err error) bool {
+	resolved := false
+	f.once.Do(func() {
+		f.value, f.err = value, err
+		close(f.done)
+		resolved = true
+	})
+	return resolved
+}
+
+// AskFuture 向目标投递请求并返回 Future；ctx 的截止时间写入信封，过期未处理的请求被丢弃。
+// 目标邮箱满（Block 策略）时等待入队，ctx 结束时放弃并以 ctx 的错误完成 Future
+func (a *BaseActor) AskFuture(ctx context.Context, target *BaseActor, msg interface{}) *Future {
+	f := newFuture()
+	env := &Envelope{
+		Message:  msg,
+		Sender:   a.id,
+		ReplyTo:  a,
+		Priority: a.EffectivePriority(),
+		future:   f,
+		ctx:      ctx,
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		env.Deadline = deadline
+	}
+	stampTrace(env, TraceFrom(ctx))
+	f.trace = env.TraceID
+	a.stampSeq(target, env)
+	target.Post(env)
+	return f
+}
+
+// Ask 向目标发送请求并等待回复
+func (a *BaseActor) Ask(ctx context.Context, target *BaseActor, msg interface{}) (interface{}, error) {
+	return a.AskFuture(ctx, target, msg).Result(ctx)
+}
+
+// Ask 按 ID 向Actor发送请求并等待回复，超时由 ctx 控制
+func (s *System) Ask(ctx context.Context, id int64, msg interface{}) (interface{}, error) {
+	if s.stopping.Load() {
+		return nil, ErrSystemStopping
+	}
+	a, ok := s.Lookup(id)
+	if !ok {
+		return nil, fmt.Errorf("%w: %d", ErrActorNotFound, id)
+	}
+	base := baseOf(a)
+	if base == nil {
+		return nil, fmt.Errorf("%w: %d", ErrAskUnsupported, id)
+	}
+	return ask(ctx, base, msg, Origin{})
+}
+
+// AskName 按名称或别名发送请求并等待回复，语义与 Ask 相同
+func (s *System) AskName(ctx context.Context, name string, msg interface{}) (interface{}, error) {
+	return s.AskNameFrom(ctx, name, Origin{}, msg)
+}
+
+// AskNameFrom 代远端发送方按名称发送请求，信封携带 from 供目标的重放保护去重；重复的请求以 ErrDuplicateMessage 失败
+func (s *System) AskNameFrom(ctx context.Context, name string, from Origin, msg interface{}) (interface{}, error) {
+	if s.stopping.Load() {
+		return nil, ErrSystemStopping
+	}
+	a, ok := s.LookupName(name)
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrNameNotFound, name)
+	}
+	base := baseOf(a)
+	if base == nil {
+		return nil, fmt.Errorf("%w: %q", ErrAskUnsupported, name)
+	}
+	return ask(ctx, base, msg, from)
+}
+
+// ask 以系统身份（无本地发送方）向 base 投递请求并等待回复，from 非零时标记远端发送方
+func ask(ctx context.Context, base *BaseActor, msg interface{}, from Origin) (interface{}, error) {
+	f := newFuture()
+	env := &Envelope{Message: msg, Priority: base.Priority(), future: f, ctx: ctx}
+	from.stamp(env)
+	if deadline, ok := ctx.Deadline(); ok {
+		env.Deadline = deadline
+	}
+	stampTrace(env, TraceFrom(ctx))
+	base.Post(env)
+	return f.Result(ctx)
+}
+
+// HandleAsk 注册请求/响应处理器：返回值自动回复给 Ask 调用方，普通投递时返回值被忽略
+func HandleAsk[T, R any](a HandlerTarget, fn func(*MessageContext, T) (R, error), opts ...HandlerOption) {
+	Handle(a, func(ctx *MessageContext, msg T) {
+		resp, err := fn(ctx, msg)
+		if err != nil {
+			ctx.Fail(err)
+			return
+		}
+		ctx.Reply(resp)
+	}, opts...)
+}
+
+// resolveUnanswered 处理器返回后 Ask 请求仍未完成时以 ErrNoReply 完成（如由 RegisterHandler 注册的处理器处理）
+func resolveUnanswered(env *Envelope, msgType string) {
+	if env != nil && env.future != nil {
+		env.future.resolve(nil, fmt.Errorf("%w: %s", ErrNoReply, msgType))
+	}
+}
+
+// resolveRejected 请求未被处理（过期、限流）时以错误完成 Future
+func resolveRejected(env *Envelope, err error) {
+	if env != nil && env.future != nil {
+		env.future.resolve(nil, err)
+	}
+}
@@ -0,0 +1,110 @@
+package Actor
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// blockingActor 处理 int 消息时阻塞到 release 关闭，用于占满邮箱
+type blockingActor struct {
+	*BaseActor
+	started chan struct{}
+	release chan struct{}
+}
+
+func (a *blockingActor) Start()                     {}
+func (a *blockingActor) Stop()                      {}
+func (a *blockingActor) Update(delta time.Duration) {}
+func (a *blockingActor) Receive(msg interface{})    {}
+
+func TestAskGivesUpWhenMailboxStaysFull(t *testing.T) {
+	s := NewSystem()
+	a := &blockingActor{
+		BaseActor: s.NewBaseActor(2, WithMailboxPolicy(Block)),
+		started:   make(chan struct{}, 1),
+		release:   make(chan struct{}),
+	}
+	RegisterHandler(a.BaseActor, func(int) {
+		select {
+		case a.started <- struct{}{}:
+		default:
+		}
+		<-a.release
+	})
+	a.Init(context.Background())
+	defer stopActor(a)
+	defer close(a.release)
+	if err := s.RegisterName("slow", a); err != nil {
+		t.Fatal(err)
+	}
+
+	a.Send(0)
+	<-a.started
+	for a.mailbox.Len() < a.mailbox.Cap() {
+		a.Send(1)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	result := make(chan error, 1)
+	go func() {
+		_, err := s.AskName(ctx, "slow", "ping")
+		result <- err
+	}()
+	select {
+	case err := <-result:
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("ask on full mailbox = %v, want deadline exceeded", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("ask still blocked on full mailbox after its deadline")
+	}
+}
+
+func TestAskResolvesWhenHandlerDoesNotReply(t *testing.T) {
+	s := NewSystem()
+	target := &blockingActor{BaseActor: s.NewBaseActor(16)}
+	echo := &blockingActor{BaseActor: s.NewBaseActor(16)}
+	RegisterHandler(target.BaseActor, func(string) {})
+	Handle(target.BaseActor, func(*MessageContext, int) {})
+	Handle(target.BaseActor, func(ctx *MessageContext, f float64) { ctx.Forward(echo.BaseActor) })
+	HandleAsk(echo.BaseActor, func(_ *MessageContext, f float64) (float64, error) { return f * 2, nil })
+	for _, a := range []*blockingActor{target, echo} {
+		a.Init(context.Background())
+		defer stopActor(a)
+	}
+	if err := s.RegisterName("target", target); err != nil {
+		t.Fatal(err)
+	}
+
+	// 无截止时间的 Ask 不能因处理器不回复而永久阻塞
+	ask := func(msg interface{}) (interface{}, error) {
+		type result struct {
+			v   interface{}
+			err error
+		}
+		done := make(chan result, 1)
+		go func() {
+			v, err := s.AskName(context.Background(), "target", msg)
+			done <- result{v, err}
+		}()
+		select {
+		case r := <-done:
+			return r.v, r.err
+		case <-time.After(2 * time.Second):
+			t.Fatalf("ask %T never resolved", msg)
+			return nil, nil
+		}
+	}
+	for _, msg := range []interface{}{"plain", 1} {
+		if _, err := ask(msg); !errors.Is(err, ErrNoReply) {
+			t.Fatalf("ask %T = %v, want ErrNoReply", msg, err)
+		}
+	}
+	// 转交后由接收方回复，不被提前以 ErrNoReply 完成
+	if v, err := ask(1.5); err != nil || v != 3.0 {
+		t.Fatalf("forwarded ask = %v, %v", v, err)
+	}
+}
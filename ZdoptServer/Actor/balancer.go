@@ -2,86 +2,522 @@ package Actor
 
 //balancer.go
 import (
+	"errors"
+	"expvar"
 	"golang.org/x/net/context"
 	"runtime"
+	"sync"
 	"sync/atomic"
+	"time"
 )
 
-// Balancer 带动态扩容的工作负载均衡器
+var (
+	balancerSteals  = expvar.NewInt("actors.balancer.steals")
+	balancerExpired = expvar.NewInt("actors.balancer.expired") // 过期未执行而跳过的任务数
+	balancerDepth   [priorityLevels]atomic.Int64               // 全部负载均衡器按优先级的待执行任务数
+)
+
+func init() {
+	expvar.Publish("actors.balancer.depth", expvar.Func(func() interface{} {
+		depth := make(map[string]int64, priorityLevels)
+		for p := range balancerDepth {
+			depth[Priority(p).String()] = balancerDepth[p].Load()
+		}
+		return depth
+	}))
+}
+
+var ErrBalancerClosed = errors.New("balancer closed")
+
+// workerQueueSize 单个 worker 队列的软上限，超过后触发扩容
+const workerQueueSize = 1024
+
+// BalancerConfig 负载均衡器参数
+type BalancerConfig struct {
+	Workers     int           // 常驻 worker 数，收缩不低于此值
+	MaxWorkers  int           // 扩容上限
+	IdleTimeout time.Duration // 超出常驻数的 worker 空闲多久后退出
+}
+
+// DefaultBalancerConfig 默认参数：常驻 CPU 核心数个 worker，最多扩容到 10 倍，空闲 30 秒回收
+func DefaultBalancerConfig() BalancerConfig {
+	return BalancerConfig{
+		Workers:     runtime.NumCPU(),
+		MaxWorkers:  runtime.NumCPU() * 10,
+		IdleTimeout: 30 * time.Second,
+	}
+}
+
+// Balancer 带动态扩缩容与工作窃取的负载均衡器：任务按轮询分配，空闲 worker 从最长的队列尾部窃取一半
 type Balancer struct {
-	workers []*worker
-	index   uint64
-	ctx     context.Context
+	mu        sync.Mutex // 串行化扩容、收缩与关闭
+	workers   atomic.Pointer[[]*worker]
+	index     atomic.Uint64
+	ctx       context.Context
+	cfg       BalancerConfig
+	steal     chan struct{} // 有积压时唤醒一个空闲 worker
+	steals    atomic.Int64
+	retired   atomic.Int64
+	closed    atomic.Bool
+	closing   chan struct{}
+	closeOnce sync.Once
+	wg        sync.WaitGroup
 }
 
 type worker struct {
-	ch     chan func()
+	b      *Balancer
+	queue  workerQueue
+	busy   atomic.Bool // 正在执行任务
+	wake   chan struct{}
 	ctx    context.Context
 	cancel context.CancelFunc
 }
 
+// BalancerStats 负载均衡器状态
+type BalancerStats struct {
+	Workers    int
+	Queued     int                 // 全部 worker 队列中待执行的任务数
+	ByPriority [priorityLevels]int // 按优先级（下标为 Priority）的待执行任务数
+	Steals     int64               // 累计窃取次数
+	Retired    int64               // 累计因空闲回收的 worker 数
+}
+
+// balancerTask 排队中的任务
+type balancerTask struct {
+	fn       func()
+	deadline time.Time // 零值不过期
+}
+
 // NewBalancer 创建负载均衡器，自动匹配CPU核心数
 func NewBalancer(ctx context.Context) *Balancer {
-	numCPU := runtime.NumCPU()
+	return NewBalancerWithConfig(ctx, DefaultBalancerConfig())
+}
+
+// NewBalancerWithWorkers 创建指定常驻 worker 数的负载均衡器
+func NewBalancerWithWorkers(ctx context.Context, n int) *Balancer {
+	return NewBalancerWithConfig(ctx, BalancerConfig{Workers: n})
+}
+
+// NewBalancerWithConfig 按参数创建负载均衡器，零值字段使用默认值
+func NewBalancerWithConfig(ctx context.Context, cfg BalancerConfig) *Balancer {
+	def := DefaultBalancerConfig()
+	if cfg.Workers <= 0 {
+		cfg.Workers = def.Workers
+	}
+	if cfg.MaxWorkers <= 0 {
+		cfg.MaxWorkers = def.MaxWorkers
+	}
+	cfg.MaxWorkers = max(cfg.MaxWorkers, cfg.Workers)
+	if cfg.IdleTimeout <= 0 {
+		cfg.IdleTimeout = def.IdleTimeout
+	}
 	b := &Balancer{
-		workers: make([]*worker, numCPU),
 		ctx:     ctx,
+		cfg:     cfg,
+		steal:   make(chan struct{}, 1),
+		closing: make(chan struct{}),
 	}
 	// 初始化worker池
-	for i := range b.workers {
-		w := &worker{
-			ch: make(chan func(), 1024),
-		}
-		w.ctx, w.cancel = context.WithCancel(ctx)
-		go w.run()
-		b.workers[i] = w
+	workers := make([]*worker, cfg.Workers)
+	for i := range workers {
+		workers[i] = b.newWorker()
 	}
+	b.workers.Store(&workers)
 	return b
 }
 
-// Submit 提交任务，使用轮询策略 + 动态扩容
-func (b *Balancer) Submit(task func()) {
-	if task == nil {
-		return
+func (b *Balancer) newWorker() *worker {
+	w := &worker{
+		b:    b,
+		wake: make(chan struct{}, 1),
 	}
+	for p := range w.queue.lanes {
+		w.queue.lanes[p].depth = &balancerDepth[p]
+	}
+	w.ctx, w.cancel = context.WithCancel(b.ctx)
+	b.wg.Add(1)
+	go w.run()
+	return w
+}
 
-	//轮询选择worker
-	idx := atomic.AddUint64(&b.index, 1) % uint64(len(b.workers))
-	select {
-	case b.workers[idx].ch <- task:
-	default:
-		//触发动态扩容
-		newworker := b.expandWorkers()
-		newworker.ch <- task // 重试提交
+// Submit 以普通优先级提交任务，使用轮询策略 + 动态扩容；不阻塞，积压的任务由空闲 worker 窃取
+func (b *Balancer) Submit(task func()) error {
+	return b.submit(task, PriorityNormal, time.Time{})
+}
+
+// SubmitWithPriority 按优先级提交任务，worker 总是先执行高优先级队列中的任务（如战斗结算）
+func (b *Balancer) SubmitWithPriority(task func(), prio Priority) error {
+	return b.submit(task, prio, time.Time{})
+}
+
+// SubmitWithDeadline 以普通优先级提交任务，到达 deadline 仍未开始执行的任务被跳过
+func (b *Balancer) SubmitWithDeadline(task func(), deadline time.Time) error {
+	return b.submit(task, PriorityNormal, deadline)
+}
+
+func (b *Balancer) submit(fn func(), prio Priority, deadline time.Time) error {
+	if fn == nil {
+		return nil
+	}
+	task := balancerTask{fn: fn, deadline: deadline}
+	prio = clampPriority(prio)
+	for {
+		if b.closed.Load() {
+			return ErrBalancerClosed
+		}
+		//轮询选择worker
+		workers := *b.workers.Load()
+		w := workers[b.index.Add(1)%uint64(len(workers))]
+		if w.queue.Len() >= workerQueueSize {
+			//触发动态扩容
+			if grown := b.expandWorkers(); grown != nil {
+				w = grown
+			}
+		}
+		n, ok := w.queue.push(task, prio)
+		if !ok {
+			// worker 已回收或正在关闭，按最新快照重试
+			continue
+		}
+		if n > 1 || w.busy.Load() {
+			// 目标 worker 正忙或已有积压，唤醒一个空闲 worker 来窃取
+			b.signalSteal()
+		}
+		w.signal()
+		return nil
+	}
+}
+
+// Close 停止接收新任务，等待已提交的任务全部执行完毕；重复调用只等待
+func (b *Balancer) Close() {
+	b.shutdown()
+	b.wg.Wait()
+}
+
+// shutdown 标记关闭并唤醒全部 worker 排空队列，此后 Submit 返回 ErrBalancerClosed
+func (b *Balancer) shutdown() {
+	b.closeOnce.Do(func() {
+		b.mu.Lock()
+		b.closed.Store(true)
+		b.mu.Unlock()
+		close(b.closing)
+	})
+}
+
+// Stats 返回当前状态
+func (b *Balancer) Stats() BalancerStats {
+	workers := *b.workers.Load()
+	stats := BalancerStats{Workers: len(workers), Steals: b.steals.Load(), Retired: b.retired.Load()}
+	for _, w := range workers {
+		for p := range w.queue.lanes {
+			n := w.queue.lanes[p].Len()
+			stats.ByPriority[p] += n
+			stats.Queued += n
+		}
 	}
+	return stats
 }
 
-// expandWorkers 扩容worker池（增加10%）
+// expandWorkers 扩容worker池（增加10%，至少一个，不超过 MaxWorkers），已达上限或已关闭时返回 nil
 func (b *Balancer) expandWorkers() *worker {
-	newSize := len(b.workers) + len(b.workers)/10
-	if newSize > runtime.NumCPU()*10 {
-		newSize = runtime.NumCPU() * 10
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed.Load() {
+		return nil
 	}
-	// 实际实现应考虑最大限制和收缩策略
-	newWorker := &worker{
-		ch: make(chan func(), 1024),
+	old := *b.workers.Load()
+	newSize := min(len(old)+max(len(old)/10, 1), b.cfg.MaxWorkers)
+	if newSize <= len(old) {
+		return nil
 	}
-	newWorker.ctx, newWorker.cancel = context.WithCancel(b.ctx)
-	go newWorker.run()
+	workers := make([]*worker, len(old), newSize)
+	copy(workers, old)
+	for len(workers) < newSize {
+		workers = append(workers, b.newWorker())
+	}
+	b.workers.Store(&workers)
+	return workers[len(old)]
+}
+
+// retire 把空闲 worker 移出快照，worker 数不超过常驻数或已关闭时不回收
+func (b *Balancer) retire(w *worker) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	old := *b.workers.Load()
+	if b.closed.Load() || len(old) <= b.cfg.Workers || w.queue.Len() > 0 {
+		return false
+	}
+	workers := make([]*worker, 0, len(old)-1)
+	for _, cur := range old {
+		if cur != w {
+			workers = append(workers, cur)
+		}
+	}
+	if len(workers) == len(old) {
+		return false
+	}
+	b.workers.Store(&workers)
+	b.retired.Add(1)
+	return true
+}
 
-	b.workers = append(b.workers, newWorker)
-	return newWorker
+func (b *Balancer) signalSteal() {
+	select {
+	case b.steal <- struct{}{}:
+	default:
+	}
+}
+
+func (w *worker) signal() {
+	select {
+	case w.wake <- struct{}{}:
+	default:
+	}
 }
 
-// worker 执行循环
+// worker 执行循环：先执行自身队列，空闲时窃取，均无任务时等待唤醒；
+// 超出常驻数的 worker 空闲超时后退出，关闭或 ctx 取消时排空后退出
 func (w *worker) run() {
+	defer w.b.wg.Done()
 	defer w.cancel()
+	idle := time.NewTimer(w.b.cfg.IdleTimeout)
+	defer idle.Stop()
 	for {
+		if task, ok := w.queue.pop(); ok {
+			w.exec(task)
+			continue
+		}
+		if w.trySteal() {
+			continue
+		}
+		if w.b.closed.Load() {
+			w.exit()
+			return
+		}
+		idle.Reset(w.b.cfg.IdleTimeout)
 		select {
-		case task := <-w.ch:
-			task()
+		case <-w.wake:
+		case <-w.b.steal:
+		case <-w.b.closing:
+		case <-idle.C:
+			if w.b.retire(w) {
+				w.exit()
+				return
+			}
 		case <-w.ctx.Done():
+			// 所属 ctx 取消等同于关闭：拒绝新任务，已接收的任务执行完再退出
+			w.b.shutdown()
+			w.exit()
 			return
 		}
 	}
 }
+
+// exec 执行任务，已过期的任务被跳过
+func (w *worker) exec(task balancerTask) {
+	if !task.deadline.IsZero() && time.Now().After(task.deadline) {
+		balancerExpired.Add(1)
+		return
+	}
+	w.busy.Store(true)
+	defer w.busy.Store(false)
+	task.fn()
+}
+
+// exit 关闭自身队列，执行关闭前最后一刻投递进来的任务
+func (w *worker) exit() {
+	for _, task := range w.queue.close() {
+		w.exec(task)
+	}
+}
+
+// trySteal 从最长的队列中最高优先级的非空队列尾部窃取一半任务到自身队列
+func (w *worker) trySteal() bool {
+	var victim *worker
+	longest := 0
+	for _, other := range *w.b.workers.Load() {
+		if other == w {
+			continue
+		}
+		if n := other.queue.Len(); n > longest {
+			victim, longest = other, n
+		}
+	}
+	if victim == nil {
+		return false
+	}
+	prio, stolen := victim.queue.stealHalf()
+	if len(stolen) == 0 {
+		return false
+	}
+	for i, task := range stolen {
+		if _, ok := w.queue.push(task, prio); !ok {
+			// 自身队列已关闭（不会发生在运行中的 worker 上），直接执行
+			for _, rest := range stolen[i:] {
+				w.exec(rest)
+			}
+			break
+		}
+	}
+	w.b.steals.Add(1)
+	balancerSteals.Add(1)
+	if longest > 2 {
+		// 仍有积压，继续唤醒其他空闲 worker
+		w.b.signalSteal()
+	}
+	return true
+}
+
+// workerQueue worker 的分优先级队列
+type workerQueue struct {
+	lanes [priorityLevels]taskDeque
+}
+
+// Len 全部优先级的待执行任务数
+func (q *workerQueue) Len() int {
+	n := 0
+	for p := range q.lanes {
+		n += q.lanes[p].Len()
+	}
+	return n
+}
+
+// push 追加到对应优先级队列，返回该 worker 追加后的积压数；队列已关闭时返回 false
+func (q *workerQueue) push(task balancerTask, prio Priority) (int, bool) {
+	n, ok := q.lanes[prio].push(task)
+	if !ok {
+		return 0, false
+	}
+	for p := range q.lanes {
+		if Priority(p) != prio {
+			n += q.lanes[p].Len()
+		}
+	}
+	return n, true
+}
+
+// pop 从最高优先级的非空队列头部取出
+func (q *workerQueue) pop() (balancerTask, bool) {
+	for p := priorityLevels - 1; p >= 0; p-- {
+		if task, ok := q.lanes[p].pop(); ok {
+			return task, true
+		}
+	}
+	return balancerTask{}, false
+}
+
+// stealHalf 从最高优先级的非空队列尾部取走一半
+func (q *workerQueue) stealHalf() (Priority, []balancerTask) {
+	for p := priorityLevels - 1; p >= 0; p-- {
+		if stolen := q.lanes[p].stealHalf(); len(stolen) > 0 {
+			return Priority(p), stolen
+		}
+	}
+	return PriorityNormal, nil
+}
+
+// close 关闭全部队列，按优先级从高到低取出剩余任务
+func (q *workerQueue) close() []balancerTask {
+	var rest []balancerTask
+	for p := priorityLevels - 1; p >= 0; p-- {
+		rest = append(rest, q.lanes[p].close()...)
+	}
+	return rest
+}
+
+// taskDeque 环形双端队列：所属 worker 从头部取，窃取者从尾部取
+type taskDeque struct {
+	mu     sync.Mutex
+	depth  *atomic.Int64 // 按优先级的全局深度计数
+	buf    []balancerTask
+	head   int
+	count  atomic.Int64
+	closed bool
+}
+
+// Len 队列长度（无锁读取，可能略有滞后）
+func (q *taskDeque) Len() int {
+	return int(q.count.Load())
+}
+
+// push 追加到尾部，返回追加后的长度；队列已关闭时返回 false
+func (q *taskDeque) push(task balancerTask) (int, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return 0, false
+	}
+	n := int(q.count.Load())
+	if n == len(q.buf) {
+		q.grow()
+	}
+	q.buf[(q.head+n)%len(q.buf)] = task
+	q.count.Store(int64(n + 1))
+	q.depth.Add(1)
+	return n + 1, true
+}
+
+func (q *taskDeque) grow() {
+	buf := make([]balancerTask, max(len(q.buf)*2, 16))
+	n := int(q.count.Load())
+	for i := 0; i < n; i++ {
+		buf[i] = q.buf[(q.head+i)%len(q.buf)]
+	}
+	q.buf, q.head = buf, 0
+}
+
+// pop 从头部取出
+func (q *taskDeque) pop() (balancerTask, bool) {
+	if q.count.Load() == 0 {
+		return balancerTask{}, false
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	n := int(q.count.Load())
+	if n == 0 {
+		return balancerTask{}, false
+	}
+	task := q.buf[q.head]
+	q.buf[q.head] = balancerTask{}
+	q.head = (q.head + 1) % len(q.buf)
+	q.count.Store(int64(n - 1))
+	q.depth.Add(-1)
+	return task, true
+}
+
+// stealHalf 从尾部取走一半（向上取整），保持原有顺序
+func (q *taskDeque) stealHalf() []balancerTask {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	n := int(q.count.Load())
+	k := (n + 1) / 2
+	if k == 0 {
+		return nil
+	}
+	stolen := make([]balancerTask, k)
+	for i := 0; i < k; i++ {
+		j := (q.head + n - k + i) % len(q.buf)
+		stolen[i] = q.buf[j]
+		q.buf[j] = balancerTask{}
+	}
+	q.count.Store(int64(n - k))
+	q.depth.Add(int64(-k))
+	return stolen
+}
+
+// close 关闭队列并取出剩余任务
+func (q *taskDeque) close() []balancerTask {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.closed = true
+	n := int(q.count.Load())
+	rest := make([]balancerTask, n)
+	for i := 0; i < n; i++ {
+		j := (q.head + i) % len(q.buf)
+		rest[i] = q.buf[j]
+		q.buf[j] = balancerTask{}
+	}
+	q.count.Store(0)
+	q.depth.Add(int64(-n))
+	return rest
+}
@@ -0,0 +1,151 @@
+package Actor
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBalancerStealsFromBlockedWorker(t *testing.T) {
+	b := NewBalancerWithConfig(context.Background(), BalancerConfig{Workers: 2, MaxWorkers: 2})
+	defer b.Close()
+
+	release := make(chan struct{})
+	defer close(release)
+	var done sync.WaitGroup
+	// 轮询下一半任务会排在阻塞的 worker 之后，需由另一个 worker 窃取
+	b.Submit(func() { <-release })
+	for i := 0; i < 10; i++ {
+		done.Add(1)
+		b.Submit(done.Done)
+	}
+	finished := make(chan struct{})
+	go func() {
+		done.Wait()
+		close(finished)
+	}()
+	select {
+	case <-finished:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("tasks stuck behind a blocked worker (stats %+v)", b.Stats())
+	}
+}
+
+func TestBalancerDrainsQueueOnContextCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	b := NewBalancerWithConfig(ctx, BalancerConfig{Workers: 1, MaxWorkers: 1})
+
+	release := make(chan struct{})
+	var ran atomic.Int32
+	b.Submit(func() { <-release })
+	for i := 0; i < 10; i++ {
+		b.Submit(func() { ran.Add(1) })
+	}
+	cancel()
+	close(release)
+	b.Close()
+	if got := ran.Load(); got != 10 {
+		t.Fatalf("ran %d of 10 accepted tasks after cancel", got)
+	}
+	if err := b.Submit(func() {}); err != ErrBalancerClosed {
+		t.Fatalf("Submit after cancel = %v, want ErrBalancerClosed", err)
+	}
+}
+
+// roundRobinPool 改造前的设计：每个 worker 一个通道，任务按轮询分配，不窃取
+type roundRobinPool struct {
+	queues []chan func()
+	next   atomic.Uint64
+	wg     sync.WaitGroup
+}
+
+func newRoundRobinPool(n int) *roundRobinPool {
+	p := &roundRobinPool{queues: make([]chan func(), n)}
+	for i := range p.queues {
+		q := make(chan func(), workerQueueSize)
+		p.queues[i] = q
+		p.wg.Add(1)
+		go func() {
+			defer p.wg.Done()
+			for fn := range q {
+				fn()
+			}
+		}()
+	}
+	return p
+}
+
+func (p *roundRobinPool) Submit(fn func()) error {
+	p.queues[p.next.Add(1)%uint64(len(p.queues))] <- fn
+	return nil
+}
+
+func (p *roundRobinPool) Close() {
+	for _, q := range p.queues {
+		close(q)
+	}
+	p.wg.Wait()
+}
+
+// BenchmarkBalancer 每次操作提交一批 64 个任务并等待全部完成（ns/op 为整批耗时），
+// 报告任务从提交到开始执行的延迟分位。skewed 负载中每批第一个任务耗时 1ms，
+// round_robin 下分到同一 worker 的后续任务都排在它之后
+func BenchmarkBalancer(b *testing.B) {
+	const (
+		workers = 4
+		batch   = 64
+	)
+	pools := []struct {
+		name string
+		new  func() (submit func(func()) error, close func())
+	}{
+		{"stealing", func() (func(func()) error, func()) {
+			p := NewBalancerWithConfig(context.Background(), BalancerConfig{Workers: workers, MaxWorkers: workers})
+			return p.Submit, p.Close
+		}},
+		{"round_robin", func() (func(func()) error, func()) {
+			p := newRoundRobinPool(workers)
+			return p.Submit, p.Close
+		}},
+	}
+	for _, skewed := range []bool{false, true} {
+		workload := "uniform"
+		if skewed {
+			workload = "skewed"
+		}
+		for _, p := range pools {
+			b.Run(workload+"/"+p.name, func(b *testing.B) {
+				submit, closePool := p.new()
+				defer closePool()
+				latencies := make([]time.Duration, 0, b.N*batch)
+				starts := make([]time.Duration, batch)
+				var done sync.WaitGroup
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					done.Add(batch)
+					for j := 0; j < batch; j++ {
+						submitted, slow := time.Now(), skewed && j == 0
+						if err := submit(func() {
+							starts[j] = time.Since(submitted)
+							if slow {
+								time.Sleep(time.Millisecond)
+							}
+							done.Done()
+						}); err != nil {
+							b.Fatal(err)
+						}
+					}
+					done.Wait()
+					latencies = append(latencies, starts...)
+				}
+				b.StopTimer()
+				sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+				b.ReportMetric(float64(latencies[len(latencies)/2].Microseconds()), "start-p50-µs")
+				b.ReportMetric(float64(latencies[len(latencies)*99/100].Microseconds()), "start-p99-µs")
+			})
+		}
+	}
+}
@@ -3,11 +3,14 @@ package Actor
 //base.go
 import (
 	"context"
+	"log"
 	"reflect"
-	"runtime"
+	"runtime/debug"
 	"sync"
 	"sync/atomic"
 	"time"
+	"zdopt/ZdoptServer/Limit"
+	"zdopt/ZdoptServer/Strict"
 )
 
 // Actor 基础接口
@@ -18,29 +21,167 @@ type Actor interface {
 	Update(delta time.Duration)
 	Receive(msg interface{})
 }
-type MessageQueue struct {
-	head    uint64
-	tail    uint64
-	buffer  []interface{}
-	modMask uint64
-}
+
+// Task 在Actor消息循环中执行的闭包消息
+type Task func()
 
 type BaseActor struct {
-	id       int64
-	mailbox  chan interface{}
-	ctx      context.Context
-	cancel   context.CancelFunc
-	wg       sync.WaitGroup
-	handlers sync.Map // map[string]HandlerFunc
-	queue    *MessageQueue
+	id          int64
+	mailbox     *MessageQueue
+	urgent      *MessageQueue // 加急通道：优先级高于自身的调用链消息
+	ctx         context.Context
+	cancel      context.CancelFunc
+	wg          sync.WaitGroup
+	handlers    sync.Map     // map[string]*handlerEntry
+	swaps       pendingSwaps // ReplaceHandler 待生效的替换
+	priority    Priority
+	boosts      [priorityLevels]int32 // 正在处理的各优先级调用链消息数
+	order       mailboxOrder          // 严格模式邮箱顺序断言
+	logger      *log.Logger
+	replay      atomic.Pointer[replayGuard]  // 重放保护，未开启时为 nil
+	resend      atomic.Pointer[resendBuffer] // 发送端重发缓冲，未开启时为 nil
+	sendSeq     sync.Map                     // map[*BaseActor]*uint64，发往各目标的序号
+	onPanic     atomic.Pointer[func(interface{}, []byte)]
+	policy      MailboxPolicy
+	counters    mailboxCounters
+	deadLetters atomic.Pointer[DeadLetters] // 接入系统后设置
+	stash       stash
+	tasks       taskRunner
+	behaviors   behaviorStack
+	mode        ProcessingMode
+	orderingKey KeyFunc
+	inbound     *inboundLimit                   // 入站限流，未设置时为 nil
+	middleware  atomic.Pointer[middlewareChain] // 接入系统后设置
+	bus         atomic.Pointer[EventBus]        // 接入系统后设置，停止时据此退订
+	group       atomic.Pointer[Group]           // 所属组，处理的消息计入组的活跃度（自适应 tick）
+	snapshots   snapshotState                   // 实现 Snapshotter 时的最近良好状态
+	idle        atomic.Bool                     // 消息循环已处理完取出的消息，正在等待新消息
+}
+
+// BaseActorOption 基础Actor构造选项
+type BaseActorOption func(*baseActorOptions)
+
+type baseActorOptions struct {
+	mailboxSize   int
+	urgentSize    int
+	logger        *log.Logger
+	policy        MailboxPolicy
+	stashCapacity int
+	taskBudget    time.Duration
+	mode          ProcessingMode
+	orderingKey   KeyFunc
+	inbound       *inboundLimit
+	snapshotEvery int
 }
 
-// NewBaseActor 创建基础Actor
-func NewBaseActor(size uint64) *BaseActor {
+// WithMailboxSize 设置普通邮箱与加急通道容量
+func WithMailboxSize(mailbox, urgent int) BaseActorOption {
+	return func(o *baseActorOptions) {
+		if mailbox > 0 {
+			o.mailboxSize = mailbox
+		}
+		if urgent > 0 {
+			o.urgentSize = urgent
+		}
+	}
+}
+
+// WithLogger 设置处理器上下文使用的日志器
+func WithLogger(logger *log.Logger) BaseActorOption {
+	return func(o *baseActorOptions) {
+		o.logger = logger
+	}
+}
+
+// NewBaseActor 创建基础Actor，size 为普通邮箱容量（0 为 1024），WithMailboxSize 可覆盖
+func NewBaseActor(size uint64, opts ...BaseActorOption) *BaseActor {
+	o := baseActorOptions{mailboxSize: 1024, urgentSize: 256}
+	if size > 0 {
+		o.mailboxSize = int(size)
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
 	return &BaseActor{
-		queue:   NewMessageQueue(size),
-		mailbox: make(chan interface{}, 1024),
+		mailbox:     NewMessageQueue(uint64(o.mailboxSize)),
+		urgent:      NewMessageQueue(uint64(o.urgentSize)),
+		priority:    PriorityNormal,
+		logger:      o.logger,
+		policy:      o.policy,
+		stash:       stash{capacity: o.stashCapacity},
+		tasks:       taskRunner{budget: o.taskBudget},
+		mode:        o.mode,
+		orderingKey: o.orderingKey,
+		inbound:     o.inbound,
+		snapshots:   snapshotState{every: o.snapshotEvery},
+	}
+}
+
+// ID 返回Actor ID
+func (a *BaseActor) ID() int64 {
+	return a.id
+}
+
+// Logger 返回Actor日志器，未设置时使用默认控制台日志器
+func (a *BaseActor) Logger() *log.Logger {
+	if a.logger == nil {
+		return defaultLogger
+	}
+	return a.logger
+}
+
+// SetPriority 设置Actor自身优先级
+func (a *BaseActor) SetPriority(p Priority) {
+	atomic.StoreInt32((*int32)(&a.priority), int32(clampPriority(p)))
+}
+
+// Priority 返回Actor自身优先级
+func (a *BaseActor) Priority() Priority {
+	return Priority(atomic.LoadInt32((*int32)(&a.priority)))
+}
+
+// EffectivePriority 有效优先级：处理高优先级调用链期间临时继承该优先级
+func (a *BaseActor) EffectivePriority() Priority {
+	own := a.Priority()
+	for p := PriorityCritical; p > own; p-- {
+		if atomic.LoadInt32(&a.boosts[p]) > 0 {
+			return p
+		}
+	}
+	return own
+}
+
+// Post 投递信封，优先级高于自身的消息进入加急通道；邮箱满时按邮箱策略处理
+func (a *BaseActor) Post(env *Envelope) {
+	_ = a.Send(env)
+}
+
+// Tell 向目标Actor发送消息，信封携带本Actor的有效优先级（优先级继承）
+func (a *BaseActor) Tell(target *BaseActor, msg interface{}) {
+	env := &Envelope{
+		Message:  msg,
+		Sender:   a.id,
+		ReplyTo:  a,
+		Priority: a.EffectivePriority(),
 	}
+	a.stampSeq(target, env)
+	target.Post(env)
+}
+
+// Execute 将闭包投递到Actor消息循环中执行，邮箱满时等待空位（Actor停止后放弃）
+func (a *BaseActor) Execute(fn func()) {
+	if fn == nil {
+		return
+	}
+	a.mailbox.EnqueueWait(a.done(), Task(fn))
+}
+
+// done 消息循环的停止信号，未启动时为 nil
+func (a *BaseActor) done() <-chan struct{} {
+	if a.ctx == nil {
+		return nil
+	}
+	return a.ctx.Done()
 }
 
 // Init 初始化Actor
@@ -50,78 +191,114 @@ func (a *BaseActor) Init(ctx context.Context) {
 	go a.processMessages()
 }
 
-// processMessages 消息处理主循环
+// processMessages 消息处理主循环：轮询两条队列，都为空时等待新消息或停止信号
 func (a *BaseActor) processMessages() {
 	defer a.wg.Done()
+	actorCount.Add(1)
+	defer actorCount.Add(-1)
 	const batchSize = 64
 	msgs := make([]interface{}, 0, batchSize)
+	streak := 0
 
 	for {
-		select {
-		case msg := <-a.mailbox:
+		// 已释放的暂存消息先于新消息重放
+		a.replayStash()
+
+		// 加急通道优先，连续处理达到上限后让出一次普通消息
+		var (
+			msg  interface{}
+			ok   bool
+			lane = laneUrgent
+		)
+		if streak < maxBoostStreak {
+			msg, ok = a.urgent.Dequeue()
+		}
+		if ok {
+			streak++
+		} else {
+			streak = 0
+			if msg, ok = a.mailbox.Dequeue(); ok {
+				lane = laneMailbox
+			} else {
+				msg, ok = a.urgent.Dequeue()
+			}
+		}
+
+		if ok {
+			if Strict.Enabled() {
+				a.order.check(a, lane, msg)
+			}
 			msgs = append(msgs, msg)
-			if len(msgs) >= batchSize {
-				a.batchHandle(msgs)
-				msgs = msgs[:0]
+			if len(msgs) < batchSize {
+				continue
 			}
-		case <-a.ctx.Done():
+		}
+		if len(msgs) > 0 {
+			a.applySwaps()
 			a.batchHandle(msgs)
-			return
-		default:
-			if len(msgs) > 0 {
-				a.batchHandle(msgs)
-				msgs = msgs[:0]
+			a.checkpoint()
+			msgs = msgs[:0]
+			if a.ctx.Err() == nil {
+				continue
 			}
-			runtime.Gosched()
 		}
+
+		a.idle.Store(true)
+		select {
+		case <-a.ctx.Done():
+			// 停止时排空邮箱，PostStop 在此之后调用
+			a.batchHandle(a.drainPending(msgs))
+			return
+		case <-a.urgent.Ready():
+		case <-a.mailbox.Ready():
+		}
+		a.idle.Store(false)
 	}
 }
 
-// batchHandle 批量消息处理
+// batchHandle 批量消息处理，按处理方式决定并发与顺序
 func (a *BaseActor) batchHandle(msgs []interface{}) {
+	switch a.mode {
+	case Sequential:
+		a.batchSequential(msgs)
+		return
+	case Keyed:
+		a.batchKeyed(msgs)
+		return
+	}
 	var wg sync.WaitGroup
 	for _, msg := range msgs {
 		wg.Add(1)
-		go func(m interface{}) {
+		Limit.Go("actor.batch", func() {
 			defer wg.Done()
-			if handler, ok := a.handlers.Load(getMessageType(m)); ok {
-				handler.(func(interface{}))(m)
-			}
-		}(msg)
+			a.dispatchSafe(msg)
+		})
 	}
 	wg.Wait()
 }
 
-// getMessageType 消息类型获取
-func getMessageType(msg interface{}) string {
-	return reflect.TypeOf(msg).String()
+// setPanicHandler 设置消息处理 panic 的处理函数（由监督者设置）
+func (a *BaseActor) setPanicHandler(fn func(v interface{}, stack []byte)) {
+	a.onPanic.Store(&fn)
 }
 
-func NewMessageQueue(size uint64) *MessageQueue {
-	return &MessageQueue{
-		buffer:  make([]interface{}, 0, size),
-		modMask: (size - 1) & ^uint64(1),
+// recoverPanic 捕获消息处理中的 panic：有监督者时上报，否则记录日志后继续处理后续消息（实现了 Snapshotter 时批次结束后从快照恢复）
+func (a *BaseActor) recoverPanic() {
+	r := recover()
+	if r == nil {
+		return
 	}
-}
-
-func (q *MessageQueue) Enqueue(msg interface{}) bool {
-	tail := atomic.LoadUint64(&q.tail)
-	next := tail + 1
-	if (next & q.modMask) == atomic.LoadUint64(&q.head) {
-		return false
+	stack := debug.Stack()
+	a.recordPanic(r)
+	if fn := a.onPanic.Load(); fn != nil {
+		(*fn)(r, stack)
+		return
 	}
-	q.buffer[tail&q.modMask] = msg
-	atomic.StoreUint64(&q.tail, next)
-	return true
+	actorPanics.Add(1)
+	a.Logger().Printf("actor %d handler panic: %v\n%s", a.id, r, stack)
 }
 
-func (q *MessageQueue) Dequeue() (interface{}, bool) {
-	head := atomic.LoadUint64(&q.head)
-	tail := atomic.LoadUint64(&q.tail)
-	if head == tail {
-		return nil, false
-	}
-	msg := q.buffer[head&q.modMask]
-	atomic.StoreUint64(&q.head, head+1)
-	return msg, true
+// getMessageType 消息类型获取
+func getMessageType(msg interface{}) string {
+	return reflect.TypeOf(msg).String()
 }
@@ -0,0 +1,83 @@
+package Actor
+
+//behavior.go
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// HandlerTarget 可注册处理器的对象：*BaseActor（基础处理器）或 *Behavior（阶段处理器）
+type HandlerTarget interface {
+	handlerSet() *sync.Map
+}
+
+func (a *BaseActor) handlerSet() *sync.Map {
+	return &a.handlers
+}
+
+// Behavior 一组处理器，表示实体的一个阶段（大厅、加载、游戏中、死亡……）
+type Behavior struct {
+	Name     string
+	handlers sync.Map // map[string]*handlerEntry
+}
+
+// NewBehavior 创建行为，处理器通过 RegisterHandler/Handle/HandleAsk 注册
+func NewBehavior(name string) *Behavior {
+	return &Behavior{Name: name}
+}
+
+func (b *Behavior) handlerSet() *sync.Map {
+	return &b.handlers
+}
+
+// behaviorStack 行为栈，栈顶为当前行为
+type behaviorStack struct {
+	mu     sync.Mutex
+	stack  []*Behavior
+	active atomic.Pointer[Behavior]
+}
+
+// Become 切换到新行为（压栈）：之后的消息优先由该行为处理，未注册的类型回落到基础处理器
+func (a *BaseActor) Become(b *Behavior) {
+	s := &a.behaviors
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stack = append(s.stack, b)
+	s.active.Store(b)
+}
+
+// Unbecome 恢复上一个行为（出栈），栈为空时返回 false
+func (a *BaseActor) Unbecome() bool {
+	s := &a.behaviors
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.stack) == 0 {
+		return false
+	}
+	s.stack[len(s.stack)-1] = nil
+	s.stack = s.stack[:len(s.stack)-1]
+	if n := len(s.stack); n > 0 {
+		s.active.Store(s.stack[n-1])
+	} else {
+		s.active.Store(nil)
+	}
+	return true
+}
+
+// Behavior 当前行为，未切换时为 nil
+func (a *BaseActor) Behavior() *Behavior {
+	return a.behaviors.active.Load()
+}
+
+// lookupHandler 先查当前行为，再查基础处理器
+func (a *BaseActor) lookupHandler(msgType string) (*handlerEntry, bool) {
+	if b := a.behaviors.active.Load(); b != nil {
+		if v, ok := b.handlers.Load(msgType); ok {
+			return v.(*handlerEntry), true
+		}
+	}
+	if v, ok := a.handlers.Load(msgType); ok {
+		return v.(*handlerEntry), true
+	}
+	return nil, false
+}
@@ -0,0 +1,169 @@
+package Actor
+
+//blackboard.go
+import (
+	"expvar"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+var blackboardPublishes = expvar.NewInt("actors.blackboard.publishes") // 黑板发布的快照数
+
+// BlackboardTopicPrefix 黑板变更通知的主题前缀，完整主题为前缀 + 键
+const BlackboardTopicPrefix = "blackboard."
+
+// BlackboardChange 一次键变更的通知
+type BlackboardChange struct {
+	Key     string
+	Value   interface{} // 新值，删除时为 nil
+	Deleted bool
+	Version uint64 // 变更后的快照版本
+}
+
+// BlackboardNotifier 由事件总线提供：把变更按主题发布给订阅者。
+// 在发布方协程中同步调用，应尽快返回（如投递到订阅者邮箱）
+type BlackboardNotifier func(topic string, change BlackboardChange)
+
+// BlackboardSnapshot 黑板的不可变快照：发布后不再修改，可在任意协程中无锁读取。
+// 值按约定同样不可变（写入方发布新值而不是修改已发布的值）
+type BlackboardSnapshot struct {
+	version uint64
+	values  map[string]interface{}
+}
+
+// Version 快照版本，每次发布递增
+func (s *BlackboardSnapshot) Version() uint64 {
+	return s.version
+}
+
+// Get 读取键值
+func (s *BlackboardSnapshot) Get(key string) (interface{}, bool) {
+	v, ok := s.values[key]
+	return v, ok
+}
+
+// Keys 全部键（排序）
+func (s *BlackboardSnapshot) Keys() []string {
+	keys := make([]string, 0, len(s.values))
+	for k := range s.values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Len 键数量
+func (s *BlackboardSnapshot) Len() int {
+	return len(s.values)
+}
+
+// BlackboardValue 按类型读取快照中的值，不存在或类型不符时返回零值与 false
+func BlackboardValue[T any](s *BlackboardSnapshot, key string) (T, bool) {
+	v, ok := s.values[key].(T)
+	return v, ok
+}
+
+// Blackboard 供大量 Actor 每个 tick 读取的共享数据（天气、全局增益等）：
+// 写入方复制当前快照、修改后原子替换，读取方无锁加载快照。适合读多写少的数据
+type Blackboard struct {
+	current  atomic.Pointer[BlackboardSnapshot]
+	mu       sync.Mutex // 串行化写入
+	notifier atomic.Pointer[BlackboardNotifier]
+}
+
+// NewBlackboard 创建空黑板
+func NewBlackboard() *Blackboard {
+	b := &Blackboard{}
+	b.current.Store(&BlackboardSnapshot{values: map[string]interface{}{}})
+	return b
+}
+
+// SetNotifier 设置变更通知函数，nil 表示不通知
+func (b *Blackboard) SetNotifier(fn BlackboardNotifier) {
+	if fn == nil {
+		b.notifier.Store(nil)
+		return
+	}
+	b.notifier.Store(&fn)
+}
+
+// Snapshot 当前快照，同一 tick 内多次读取应复用同一快照以获得一致的视图
+func (b *Blackboard) Snapshot() *BlackboardSnapshot {
+	return b.current.Load()
+}
+
+// Get 从当前快照读取键值
+func (b *Blackboard) Get(key string) (interface{}, bool) {
+	return b.current.Load().Get(key)
+}
+
+// Set 发布单个键的新值，返回新快照版本
+func (b *Blackboard) Set(key string, value interface{}) uint64 {
+	return b.Update(func(values map[string]interface{}) {
+		values[key] = value
+	})
+}
+
+// Delete 删除键，返回新快照版本（键不存在时不发布）
+func (b *Blackboard) Delete(key string) uint64 {
+	return b.Update(func(values map[string]interface{}) {
+		delete(values, key)
+	})
+}
+
+// Update 在当前快照的副本上批量修改后一次发布，fn 中不能保留 values 的引用；
+// 没有实际变更时不发布，返回当前版本
+func (b *Blackboard) Update(fn func(values map[string]interface{})) uint64 {
+	b.mu.Lock()
+	old := b.current.Load()
+	values := make(map[string]interface{}, len(old.values)+1)
+	for k, v := range old.values {
+		values[k] = v
+	}
+	fn(values)
+
+	changes := diffBlackboard(old.values, values)
+	if len(changes) == 0 {
+		b.mu.Unlock()
+		return old.version
+	}
+	next := &BlackboardSnapshot{version: old.version + 1, values: values}
+	b.current.Store(next)
+	b.mu.Unlock()
+	blackboardPublishes.Add(1)
+
+	if fn := b.notifier.Load(); fn != nil {
+		for _, c := range changes {
+			c.Version = next.version
+			(*fn)(BlackboardTopicPrefix+c.Key, c)
+		}
+	}
+	return next.version
+}
+
+// diffBlackboard 比较两个快照的键值，值不可比较时按已变更处理
+func diffBlackboard(old, next map[string]interface{}) []BlackboardChange {
+	var changes []BlackboardChange
+	for k, v := range next {
+		if ov, ok := old[k]; !ok || !sameValue(ov, v) {
+			changes = append(changes, BlackboardChange{Key: k, Value: v})
+		}
+	}
+	for k := range old {
+		if _, ok := next[k]; !ok {
+			changes = append(changes, BlackboardChange{Key: k, Deleted: true})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Key < changes[j].Key })
+	return changes
+}
+
+func sameValue(a, b interface{}) (same bool) {
+	defer func() {
+		if recover() != nil {
+			same = false
+		}
+	}()
+	return a == b
+}
@@ -0,0 +1,161 @@
+package Actor
+
+//context.go
+import (
+	"context"
+	"expvar"
+	"fmt"
+	"log"
+	"time"
+	"zdopt/ZdoptServer/Intern"
+	"zdopt/ZdoptServer/Logs"
+)
+
+var (
+	expiredMessages = expvar.NewInt("actors.expired")
+	handlerMetrics  = expvar.NewMap("actors.handlers")
+
+	defaultLogger = Logs.CreateConsoleLogConfig("Actor")
+)
+
+// MessageContext 处理器上下文：随Actor生命周期取消，并受信封 Deadline 约束
+type MessageContext struct {
+	context.Context
+	Self     *BaseActor // 处理消息的Actor
+	Sender   int64
+	ReplyTo  *BaseActor
+	Envelope *Envelope // 未经信封投递的消息为 nil
+	Logger   *log.Logger
+	msgType  string
+	trace    TraceRef // 开启追踪时当前处理过程在调用链中的位置
+	handoff  bool     // 信封已转交（Forward/Stash），Ask 由后续处理方回复
+}
+
+// newMessageContext 基于Actor生命周期与信封 Deadline 派生处理上下文，返回的 cancel 须在处理结束后调用
+func (a *BaseActor) newMessageContext(msgType string, env *Envelope, trace TraceRef) (*MessageContext, context.CancelFunc) {
+	parent := a.ctx
+	if parent == nil {
+		parent = context.Background()
+	}
+	if trace.TraceID != 0 {
+		// 在处理器中以该上下文发起的 Ask 延续调用链
+		parent = context.WithValue(parent, traceKey{}, trace)
+	}
+
+	var (
+		ctx    context.Context
+		cancel context.CancelFunc
+	)
+	if env != nil && !env.Deadline.IsZero() {
+		ctx, cancel = context.WithDeadline(parent, env.Deadline)
+	} else {
+		ctx, cancel = context.WithCancel(parent)
+	}
+
+	mc := &MessageContext{
+		Context:  ctx,
+		Self:     a,
+		Envelope: env,
+		Logger:   a.Logger(),
+		msgType:  msgType,
+		trace:    trace,
+	}
+	if env != nil {
+		mc.Sender = env.Sender
+		mc.ReplyTo = env.ReplyTo
+	}
+	return mc, cancel
+}
+
+// MessageType 当前消息类型
+func (c *MessageContext) MessageType() string {
+	return c.msgType
+}
+
+// Tell 在当前调用链中向目标发送消息，继承优先级、Deadline 与追踪调用链
+func (c *MessageContext) Tell(target *BaseActor, msg interface{}) {
+	var env *Envelope
+	if c.Envelope == nil {
+		env = &Envelope{
+			Message:  msg,
+			Sender:   c.Self.id,
+			ReplyTo:  c.Self,
+			Priority: c.Self.EffectivePriority(),
+		}
+	} else {
+		env = c.Envelope.Derive(c.Self, msg)
+	}
+	stampTrace(env, c.trace)
+	c.Self.stampSeq(target, env)
+	target.Post(env)
+}
+
+// Forward 把当前消息原样转交给目标：保留原发送者、回复地址与 Ask 请求，目标的回复直接到达原请求方
+func (c *MessageContext) Forward(target *BaseActor) {
+	if c.Envelope == nil {
+		return
+	}
+	c.handoff = true
+	env := *c.Envelope
+	env.Seq, env.seq = 0, 0
+	env.TraceID, env.ParentSpan = 0, 0
+	stampTrace(&env, c.trace)
+	target.Post(&env)
+}
+
+// Trace 当前处理过程在调用链中的位置，未开启追踪时为零值
+func (c *MessageContext) Trace() TraceRef {
+	return c.trace
+}
+
+// Reply 回复发送者：Ask 请求完成其 Future，否则投递给 ReplyTo；无处可回时返回 false
+func (c *MessageContext) Reply(msg interface{}) bool {
+	if c.Envelope != nil && c.Envelope.future != nil {
+		return c.Envelope.future.resolve(msg, nil)
+	}
+	if c.ReplyTo == nil {
+		return false
+	}
+	c.Tell(c.ReplyTo, msg)
+	return true
+}
+
+// Fail 以错误回复 Ask 请求，非 Ask 请求时返回 false
+func (c *MessageContext) Fail(err error) bool {
+	if c.Envelope == nil || c.Envelope.future == nil {
+		return false
+	}
+	return c.Envelope.future.resolve(nil, err)
+}
+
+// settleAsk 处理器返回后仍未回复且未转交的 Ask 请求以 ErrNoReply 完成，调用方不会无限等待
+func (c *MessageContext) settleAsk() {
+	if c.handoff {
+		return
+	}
+	resolveUnanswered(c.Envelope, c.msgType)
+}
+
+// Logf 带Actor与消息类型前缀的日志
+func (c *MessageContext) Logf(format string, args ...interface{}) {
+	if c.trace.TraceID != 0 {
+		c.Logger.Printf("[actor %d %s trace=%016x] %s", c.Self.id, c.msgType, c.trace.TraceID, fmt.Sprintf(format, args...))
+		return
+	}
+	c.Logger.Printf("[actor %d %s] %s", c.Self.id, c.msgType, fmt.Sprintf(format, args...))
+}
+
+// Count 累加当前消息类型作用域下的计数指标（actors.handlers.<类型>.<name>）
+func (c *MessageContext) Count(name string, delta int64) {
+	handlerMetrics.Add(Intern.Join(".", c.msgType, name), delta)
+}
+
+// Observe 记录当前消息类型作用域下的耗时指标（纳秒累计）
+func (c *MessageContext) Observe(name string, d time.Duration) {
+	handlerMetrics.Add(Intern.Join("", c.msgType, ".", name, "_ns"), int64(d))
+}
+
+// expired 信封是否已过期
+func expired(env *Envelope, now time.Time) bool {
+	return env != nil && !env.Deadline.IsZero() && now.After(env.Deadline)
+}
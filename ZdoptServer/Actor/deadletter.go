@@ -0,0 +1,149 @@
+package Actor
+
+//deadletter.go
+import (
+	"errors"
+	"expvar"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var (
+	ErrNoHandler = errors.New("no handler registered for message type")
+
+	deadLetterCounts = expvar.NewMap("actors.deadletters") // 按原因计数
+)
+
+// DeadLetterReason 消息无法投递或处理的原因
+type DeadLetterReason int
+
+const (
+	NoHandler     DeadLetterReason = iota // 消息类型没有注册处理器
+	ActorNotFound                         // 按 ID 投递时目标未登记
+	MailboxFull                           // 邮箱满被丢弃或拒绝
+	ActorStopped                          // 目标已停止
+	Expired                               // 超过信封 Deadline
+	RateLimited                           // 超过 Actor 入站限流
+)
+
+var deadLetterReasonNames = [...]string{"no_handler", "actor_not_found", "mailbox_full", "actor_stopped", "expired", "rate_limited"}
+
+func (r DeadLetterReason) String() string {
+	if r >= 0 && int(r) < len(deadLetterReasonNames) {
+		return deadLetterReasonNames[r]
+	}
+	return fmt.Sprintf("DeadLetterReason(%d)", int(r))
+}
+
+// DeadLetter 一条死信
+type DeadLetter struct {
+	Target   int64
+	Message  interface{}
+	Envelope *Envelope // 未经信封投递时为 nil
+	Reason   DeadLetterReason
+	Time     time.Time
+}
+
+// DeadLetters 死信收集：保留最近的死信并分发给订阅者，用于发现路由错误
+type DeadLetters struct {
+	mu     sync.Mutex
+	recent []DeadLetter // 环形缓冲
+	next   int
+	full   bool
+	subs   map[int]func(DeadLetter)
+	subID  int
+	counts [len(deadLetterReasonNames)]int64
+}
+
+// NewDeadLetters 创建死信收集器，keep 为保留的最近死信条数
+func NewDeadLetters(keep int) *DeadLetters {
+	if keep <= 0 {
+		keep = 256
+	}
+	return &DeadLetters{recent: make([]DeadLetter, keep), subs: make(map[int]func(DeadLetter))}
+}
+
+// Subscribe 订阅死信，返回取消订阅函数；回调在投递方协程中同步执行，不应阻塞
+func (d *DeadLetters) Subscribe(fn func(DeadLetter)) (cancel func()) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.subID++
+	id := d.subID
+	d.subs[id] = fn
+	return func() {
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		delete(d.subs, id)
+	}
+}
+
+// Recent 最近的死信（从旧到新）
+func (d *DeadLetters) Recent() []DeadLetter {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.full {
+		return append([]DeadLetter(nil), d.recent[:d.next]...)
+	}
+	return append(append([]DeadLetter(nil), d.recent[d.next:]...), d.recent[:d.next]...)
+}
+
+// Counts 各原因的累计死信数
+func (d *DeadLetters) Counts() map[DeadLetterReason]int64 {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	out := make(map[DeadLetterReason]int64, len(d.counts))
+	for r, n := range d.counts {
+		if n > 0 {
+			out[DeadLetterReason(r)] = n
+		}
+	}
+	return out
+}
+
+// publish 记录死信并通知订阅者
+func (d *DeadLetters) publish(dl DeadLetter) {
+	if dl.Time.IsZero() {
+		dl.Time = time.Now()
+	}
+	deadLetterCounts.Add(dl.Reason.String(), 1)
+
+	d.mu.Lock()
+	d.recent[d.next] = dl
+	d.next++
+	if d.next == len(d.recent) {
+		d.next, d.full = 0, true
+	}
+	if int(dl.Reason) < len(d.counts) {
+		d.counts[dl.Reason]++
+	}
+	subs := make([]func(DeadLetter), 0, len(d.subs))
+	for _, fn := range d.subs {
+		subs = append(subs, fn)
+	}
+	d.mu.Unlock()
+
+	for _, fn := range subs {
+		fn(dl)
+	}
+}
+
+// deadLetter 上报本Actor的死信：未接入系统时只计入指标
+func (a *BaseActor) deadLetter(msg interface{}, reason DeadLetterReason) {
+	payload, env := unwrap(msg)
+	if d := a.deadLetters.Load(); d != nil {
+		d.publish(DeadLetter{Target: a.id, Message: payload, Envelope: env, Reason: reason})
+		return
+	}
+	deadLetterCounts.Add(reason.String(), 1)
+}
+
+// attachDeadLetters 将Actor的死信接入系统
+func (a *BaseActor) attachDeadLetters(d *DeadLetters) {
+	a.deadLetters.CompareAndSwap(nil, d)
+}
+
+// DeadLetters 系统死信收集器
+func (s *System) DeadLetters() *DeadLetters {
+	return s.deadLetters
+}
@@ -0,0 +1,86 @@
+package Actor
+
+//envelope.go
+import (
+	"context"
+	"strconv"
+	"time"
+)
+
+// Priority 消息/Actor 优先级
+type Priority int32
+
+const (
+	PriorityLow Priority = iota
+	PriorityNormal
+	PriorityHigh
+	PriorityCritical
+
+	priorityLevels = int(PriorityCritical) + 1
+)
+
+var priorityNames = [...]string{"low", "normal", "high", "critical"}
+
+func (p Priority) String() string {
+	if p >= PriorityLow && p <= PriorityCritical {
+		return priorityNames[p]
+	}
+	return "Priority(" + strconv.Itoa(int(p)) + ")"
+}
+
+// maxBoostStreak 加急通道连续处理上限，超过后让出一条普通消息，避免普通消息饿死
+const maxBoostStreak = 16
+
+// Envelope 消息信封，携带发送者与调用链优先级
+type Envelope struct {
+	Message    interface{}
+	Sender     int64
+	Node       string     // 远端发送方所在节点（经 Rpc 到达），本地消息为空；重放保护按 (Node, Sender) 区分发送方
+	ReplyTo    *BaseActor // 回复/拒绝通知的接收者，可为空
+	Priority   Priority
+	Deadline   time.Time // 过期时间，零值不过期；过期未处理的消息被丢弃
+	Seq        uint64    // 发送者到接收者的单调序号，0 表示未编号（不参与重放保护）
+	TraceID    uint64    // 调用链 ID，开启追踪后由处理方或发送方分配，0 表示未追踪
+	ParentSpan uint64    // 发出本消息的处理过程的 SpanID
+	seq        uint64    // 严格模式下的通道内序号
+	future     *Future   // Ask 请求的结果，回复时完成
+	// ctx Ask 的上下文，目标邮箱满等待入队（Block 策略）时随其结束放弃
+	ctx context.Context
+}
+
+// Derive 基于当前信封派生下游消息，继承调用链优先级与 Deadline
+func (e *Envelope) Derive(sender *BaseActor, msg interface{}) *Envelope {
+	return &Envelope{
+		Message:  msg,
+		Sender:   sender.id,
+		ReplyTo:  sender,
+		Priority: e.Priority,
+		Deadline: e.Deadline,
+	}
+}
+
+// WithTTL 设置信封存活时间（从当前时刻起算）
+func (e *Envelope) WithTTL(ttl time.Duration) *Envelope {
+	if ttl > 0 {
+		e.Deadline = time.Now().Add(ttl)
+	}
+	return e
+}
+
+// unwrap 拆出信封中的业务消息
+func unwrap(msg interface{}) (interface{}, *Envelope) {
+	if env, ok := msg.(*Envelope); ok {
+		return env.Message, env
+	}
+	return msg, nil
+}
+
+func clampPriority(p Priority) Priority {
+	if p < PriorityLow {
+		return PriorityLow
+	}
+	if p > PriorityCritical {
+		return PriorityCritical
+	}
+	return p
+}
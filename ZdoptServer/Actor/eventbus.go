@@ -0,0 +1,421 @@
+package Actor
+
+//eventbus.go
+import (
+	"errors"
+	"expvar"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	ErrInvalidTopic  = errors.New("invalid event topic")
+	ErrInvalidFilter = errors.New("invalid subscription filter")
+
+	busEvents = expvar.NewMap("actors.eventbus") // published / delivered / failed / unsubscribed
+)
+
+// EventBus 进程内发布订阅：Actor按主题订阅，发布时投递到每个订阅者的邮箱（按其邮箱策略）。
+// 主题以 . 分段，订阅主题中 * 匹配一段，末尾的 > 匹配其后的一段或多段，如 player.*.joined、entity.>。
+// Actor停止时自动退订；订阅同时登记到系统的订阅登记表，监督者重启后按登记重新订阅
+type EventBus struct {
+	sys   *System
+	mu    sync.Mutex // 串行化订阅变更
+	table atomic.Pointer[busTable]
+	taps  atomic.Pointer[[]*busTap]
+}
+
+// busTap 非Actor的旁路订阅，发布时在发布方协程中同步调用
+type busTap struct {
+	segments []string
+	fn       func(topic string, msg interface{})
+}
+
+// busTable 订阅表快照，变更时整体替换，发布方无锁读取
+type busTable struct {
+	exact map[string][]busSub
+	wild  []busPattern
+}
+
+// busSub 一条订阅：同一 Actor 可以不同过滤条件多次订阅同一主题，任一条件通过即投递（至多一次）
+type busSub struct {
+	actor  *BaseActor
+	filter *busFilter // nil 表示不过滤
+}
+
+type busPattern struct {
+	topic    string
+	segments []string
+	busSub
+}
+
+// busFilter 订阅过滤表达式：以 | 分隔的消息类型名（如 Pb.DataPacket|Actor.BlackboardChange），
+// 以 ! 开头时排除所列类型。类型名为 Go 的 包名.类型名，指针类型不带 *
+type busFilter struct {
+	expr   string
+	negate bool
+	types  map[string]struct{}
+}
+
+// parseFilter 解析过滤表达式，空表达式返回 nil（不过滤）
+func parseFilter(expr string) (*busFilter, error) {
+	if expr == "" {
+		return nil, nil
+	}
+	f := &busFilter{expr: expr, types: make(map[string]struct{})}
+	list := expr
+	if rest, ok := strings.CutPrefix(list, "!"); ok {
+		f.negate, list = true, rest
+	}
+	for _, name := range strings.Split(list, "|") {
+		name = strings.TrimSpace(name)
+		if name == "" || strings.ContainsAny(name, "*! ") {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidFilter, expr)
+		}
+		f.types[name] = struct{}{}
+	}
+	return f, nil
+}
+
+// accepts 消息是否通过过滤
+func (f *busFilter) accepts(msg interface{}) bool {
+	if f == nil {
+		return true
+	}
+	name := ""
+	if t := reflect.TypeOf(msg); t != nil {
+		name = strings.TrimPrefix(t.String(), "*")
+	}
+	_, listed := f.types[name]
+	return listed != f.negate
+}
+
+func (f *busFilter) String() string {
+	if f == nil {
+		return ""
+	}
+	return f.expr
+}
+
+func newEventBus(sys *System) *EventBus {
+	b := &EventBus{sys: sys}
+	b.table.Store(&busTable{exact: map[string][]busSub{}})
+	return b
+}
+
+// EventBus 系统的事件总线
+func (s *System) EventBus() *EventBus {
+	return s.bus
+}
+
+// validTopic 检查主题：段不能为空，发布主题不能含通配符，> 只能是订阅主题的最后一段
+func validTopic(topic string, pattern bool) error {
+	if topic == "" {
+		return fmt.Errorf("%w: empty", ErrInvalidTopic)
+	}
+	segments := strings.Split(topic, ".")
+	for i, seg := range segments {
+		switch {
+		case seg == "":
+			return fmt.Errorf("%w: %q has empty segment", ErrInvalidTopic, topic)
+		case !pattern && (seg == "*" || seg == ">"):
+			return fmt.Errorf("%w: %q wildcard in published topic", ErrInvalidTopic, topic)
+		case seg == ">" && i != len(segments)-1:
+			return fmt.Errorf("%w: %q '>' must be last", ErrInvalidTopic, topic)
+		}
+	}
+	return nil
+}
+
+func isWildcard(segments []string) bool {
+	return slices.Contains(segments, "*") || slices.Contains(segments, ">")
+}
+
+// Subscribe 订阅主题（可含通配符），重复订阅无副作用
+func (b *EventBus) Subscribe(a *BaseActor, topic string) error {
+	return b.SubscribeFilter(a, topic, "")
+}
+
+// SubscribeFilter 带过滤条件订阅主题（表达式见 busFilter：以 | 分隔的消息类型名，! 开头表示排除），
+// 只投递通过过滤的消息；过滤条件随订阅登记，重启与迁移后一并恢复
+func (b *EventBus) SubscribeFilter(a *BaseActor, topic, filter string) error {
+	if err := validTopic(topic, true); err != nil {
+		return err
+	}
+	f, err := parseFilter(filter)
+	if err != nil {
+		return err
+	}
+	b.subscribe(a, topic, f)
+	if id := a.ID(); id != 0 {
+		b.sys.subscriptions.Add(Subscription{ActorID: id, Topic: topic, Filter: filter})
+	}
+	return nil
+}
+
+func (b *EventBus) subscribe(a *BaseActor, topic string, filter *busFilter) {
+	a.bus.CompareAndSwap(nil, b)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	old := b.table.Load()
+	sub := busSub{actor: a, filter: filter}
+	segments := strings.Split(topic, ".")
+	if !isWildcard(segments) {
+		if slices.ContainsFunc(old.exact[topic], sub.same) {
+			return
+		}
+		next := old.clone()
+		next.exact[topic] = append(slices.Clip(old.exact[topic]), sub)
+		b.table.Store(next)
+		return
+	}
+	for _, p := range old.wild {
+		if p.topic == topic && sub.same(p.busSub) {
+			return
+		}
+	}
+	next := old.clone()
+	next.wild = append(next.wild, busPattern{topic: topic, segments: segments, busSub: sub})
+	b.table.Store(next)
+}
+
+// same 是否为同一 Actor 的同一过滤条件
+func (s busSub) same(o busSub) bool {
+	return s.actor == o.actor && s.filter.String() == o.filter.String()
+}
+
+// Tap 以回调旁路订阅主题（可含通配符），发布时在发布方协程中同步调用 fn，携带实际发布的主题；
+// 用于导出、审计等需要看到全部消息类型的消费方，fn 不得阻塞。不计入投递数，返回取消函数
+func (b *EventBus) Tap(topic string, fn func(topic string, msg interface{})) (cancel func(), err error) {
+	if err := validTopic(topic, true); err != nil {
+		return nil, err
+	}
+	tap := &busTap{segments: strings.Split(topic, "."), fn: fn}
+	b.mu.Lock()
+	b.storeTaps(append(slices.Clip(b.loadTaps()), tap))
+	b.mu.Unlock()
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		taps := b.loadTaps()
+		if i := slices.Index(taps, tap); i >= 0 {
+			b.storeTaps(slices.Delete(slices.Clone(taps), i, i+1))
+		}
+	}, nil
+}
+
+func (b *EventBus) loadTaps() []*busTap {
+	if p := b.taps.Load(); p != nil {
+		return *p
+	}
+	return nil
+}
+
+func (b *EventBus) storeTaps(taps []*busTap) {
+	b.taps.Store(&taps)
+}
+
+// Unsubscribe 退订主题（含该主题下的全部过滤条件），同时从订阅登记表移除
+func (b *EventBus) Unsubscribe(a *BaseActor, topic string) {
+	b.remove(a, func(t string) bool { return t == topic })
+	if id := a.ID(); id != 0 {
+		for _, sub := range b.sys.subscriptions.List(id) {
+			if sub.Topic == topic {
+				b.sys.subscriptions.Remove(sub)
+			}
+		}
+	}
+}
+
+// unsubscribeActor Actor停止时退订全部主题（保留登记表，供重启后恢复）
+func (b *EventBus) unsubscribeActor(a *BaseActor) {
+	b.remove(a, func(string) bool { return true })
+}
+
+func (b *EventBus) remove(a *BaseActor, match func(topic string) bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	old := b.table.Load()
+	next := &busTable{exact: make(map[string][]busSub, len(old.exact))}
+	removed := 0
+	for topic, subs := range old.exact {
+		kept := subs
+		if match(topic) {
+			kept = make([]busSub, 0, len(subs))
+			for _, s := range subs {
+				if s.actor != a {
+					kept = append(kept, s)
+				}
+			}
+			removed += len(subs) - len(kept)
+		}
+		if len(kept) > 0 {
+			next.exact[topic] = kept
+		}
+	}
+	for _, p := range old.wild {
+		if p.actor == a && match(p.topic) {
+			removed++
+			continue
+		}
+		next.wild = append(next.wild, p)
+	}
+	if removed > 0 {
+		busEvents.Add("unsubscribed", int64(removed))
+		b.table.Store(next)
+	}
+}
+
+// Publish 向订阅了匹配主题的Actor投递消息，每个Actor至多收到一次，返回成功投递数。
+// 已停止的订阅者在此时退订
+func (b *EventBus) Publish(topic string, msg interface{}) (int, error) {
+	if err := validTopic(topic, false); err != nil {
+		return 0, err
+	}
+	if b.sys.stopping.Load() {
+		return 0, ErrSystemStopping
+	}
+	busEvents.Add("published", 1)
+	if taps := b.loadTaps(); len(taps) > 0 {
+		segments := strings.Split(topic, ".")
+		for _, tap := range taps {
+			if matchTopic(tap.segments, segments) {
+				tap.fn(topic, msg)
+			}
+		}
+	}
+	t := b.table.Load()
+	var targets []*BaseActor
+	for _, s := range t.exact[topic] {
+		if s.filter.accepts(msg) && !containsActor(targets, s.actor) {
+			targets = append(targets, s.actor)
+		}
+	}
+	if len(t.wild) > 0 {
+		var segments []string
+		for _, p := range t.wild {
+			if segments == nil {
+				segments = strings.Split(topic, ".")
+			}
+			if matchTopic(p.segments, segments) && p.filter.accepts(msg) && !containsActor(targets, p.actor) {
+				targets = append(targets, p.actor)
+			}
+		}
+	}
+
+	delivered := 0
+	var stale []*BaseActor
+	for _, a := range targets {
+		if a.stopped() {
+			stale = append(stale, a)
+			continue
+		}
+		if err := a.Send(&Envelope{Message: msg, Priority: a.Priority()}); err != nil {
+			busEvents.Add("failed", 1)
+			continue
+		}
+		delivered++
+	}
+	busEvents.Add("delivered", int64(delivered))
+	for _, a := range stale {
+		b.unsubscribeActor(a)
+	}
+	return delivered, nil
+}
+
+// Subscribers 当前匹配该发布主题的订阅者数（不计过滤条件）
+func (b *EventBus) Subscribers(topic string) int {
+	t := b.table.Load()
+	var actors []*BaseActor
+	for _, s := range t.exact[topic] {
+		if !containsActor(actors, s.actor) {
+			actors = append(actors, s.actor)
+		}
+	}
+	segments := strings.Split(topic, ".")
+	for _, p := range t.wild {
+		if matchTopic(p.segments, segments) && !containsActor(actors, p.actor) {
+			actors = append(actors, p.actor)
+		}
+	}
+	return len(actors)
+}
+
+// Topics Actor当前订阅的主题
+func (b *EventBus) Topics(a *BaseActor) []string {
+	t := b.table.Load()
+	var topics []string
+	for topic, subs := range t.exact {
+		if slices.ContainsFunc(subs, func(s busSub) bool { return s.actor == a }) {
+			topics = append(topics, topic)
+		}
+	}
+	for _, p := range t.wild {
+		if p.actor == a && !slices.Contains(topics, p.topic) {
+			topics = append(topics, p.topic)
+		}
+	}
+	return topics
+}
+
+// bind 订阅登记表的绑定函数：按登记的 Actor ID 重新订阅
+func (b *EventBus) bind(sub Subscription) error {
+	a, ok := b.sys.Lookup(sub.ActorID)
+	if !ok {
+		return fmt.Errorf("%w: %d", ErrActorNotFound, sub.ActorID)
+	}
+	base := baseOf(a)
+	if base == nil {
+		return fmt.Errorf("actor %d has no mailbox", sub.ActorID)
+	}
+	filter, err := parseFilter(sub.Filter)
+	if err != nil {
+		return err
+	}
+	b.subscribe(base, sub.Topic, filter)
+	return nil
+}
+
+// notifyBlackboard 黑板变更通知：按 BlackboardTopicPrefix + 键发布
+func (b *EventBus) notifyBlackboard(topic string, change BlackboardChange) {
+	_, _ = b.Publish(topic, change)
+}
+
+func (t *busTable) clone() *busTable {
+	next := &busTable{exact: make(map[string][]busSub, len(t.exact)+1), wild: slices.Clip(t.wild)}
+	for k, v := range t.exact {
+		next.exact[k] = v
+	}
+	return next
+}
+
+// MatchTopic 发布主题 topic 是否匹配订阅主题 pattern（通配符规则同 Subscribe）
+func MatchTopic(pattern, topic string) bool {
+	return matchTopic(strings.Split(pattern, "."), strings.Split(topic, "."))
+}
+
+// matchTopic 订阅主题的段是否匹配发布主题的段
+func matchTopic(pattern, topic []string) bool {
+	for i, seg := range pattern {
+		if seg == ">" {
+			return len(topic) > i
+		}
+		if i >= len(topic) || (seg != "*" && seg != topic[i]) {
+			return false
+		}
+	}
+	return len(pattern) == len(topic)
+}
+
+func containsActor(list []*BaseActor, a *BaseActor) bool {
+	for _, s := range list {
+		if s == a {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,138 @@
+package Actor
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// busProbe 记录经事件总线收到的 int 与 string 消息
+type busProbe struct {
+	*BaseActor
+	got chan interface{}
+}
+
+func (p *busProbe) Start()                     {}
+func (p *busProbe) Stop()                      {}
+func (p *busProbe) Update(delta time.Duration) {}
+func (p *busProbe) Receive(msg interface{})    {}
+
+func newBusProbe(t *testing.T, s *System, id int64) *busProbe {
+	t.Helper()
+	p := &busProbe{BaseActor: s.NewBaseActor(64, WithProcessingMode(Sequential)), got: make(chan interface{}, 64)}
+	RegisterHandler(p.BaseActor, func(v int) { p.got <- v })
+	RegisterHandler(p.BaseActor, func(v string) { p.got <- v })
+	if err := s.Register(id, p); err != nil {
+		t.Fatal(err)
+	}
+	s.AddGroupActors(1, []func() Actor{func() Actor { return p }})
+	return p
+}
+
+func (p *busProbe) expect(t *testing.T, want ...interface{}) {
+	t.Helper()
+	for _, w := range want {
+		select {
+		case v := <-p.got:
+			if v != w {
+				t.Fatalf("received %v, want %v", v, w)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("%v never delivered", w)
+		}
+	}
+	select {
+	case v := <-p.got:
+		t.Fatalf("unexpected extra message %v", v)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestEventBusAppliesSubscriptionFilter(t *testing.T) {
+	s := NewSystem()
+	defer s.Stop()
+	bus := s.EventBus()
+	ints, notInts := newBusProbe(t, s, 1), newBusProbe(t, s, 2)
+	if err := bus.SubscribeFilter(ints.BaseActor, "score.>", "int"); err != nil {
+		t.Fatal(err)
+	}
+	if err := bus.SubscribeFilter(notInts.BaseActor, "score.total", "!int"); err != nil {
+		t.Fatal(err)
+	}
+	if err := bus.SubscribeFilter(ints.BaseActor, "score.total", "|"); !errors.Is(err, ErrInvalidFilter) {
+		t.Fatalf("empty type list = %v, want ErrInvalidFilter", err)
+	}
+
+	for _, msg := range []interface{}{1, "one", 2} {
+		if _, err := bus.Publish("score.total", msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ints.expect(t, 1, 2)
+	notInts.expect(t, "one")
+
+	// 同一 Actor 以另一过滤条件再订阅：任一条件通过即投递，且只投递一次
+	if err := bus.SubscribeFilter(ints.BaseActor, "score.total", "string|int"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bus.Publish("score.total", "two"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bus.Publish("score.total", 3); err != nil {
+		t.Fatal(err)
+	}
+	ints.expect(t, "two", 3)
+	if got := s.Subscriptions(1); len(got) != 2 || got[0].Filter != "int" || got[1].Filter != "string|int" {
+		t.Fatalf("registered subscriptions = %+v", got)
+	}
+}
+
+func TestMigrateActorMovesFilteredSubscriptions(t *testing.T) {
+	s := NewSystem()
+	defer s.Stop()
+	bus := s.EventBus()
+	old := newBusProbe(t, s, 10)
+	if err := bus.SubscribeFilter(old.BaseActor, "room.*", "string"); err != nil {
+		t.Fatal(err)
+	}
+
+	moved := &busProbe{BaseActor: s.NewBaseActor(64, WithProcessingMode(Sequential)), got: make(chan interface{}, 64)}
+	RegisterHandler(moved.BaseActor, func(v int) { moved.got <- v })
+	RegisterHandler(moved.BaseActor, func(v string) { moved.got <- v })
+	s.AddGroupActors(1, []func() Actor{func() Actor { return moved }})
+	if err := s.MigrateActor(10, 20, moved); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := s.Lookup(10); ok {
+		t.Fatal("old id still registered")
+	}
+	if len(s.Subscriptions(10)) != 0 {
+		t.Fatalf("subscriptions left on old id: %+v", s.Subscriptions(10))
+	}
+	if _, err := bus.Publish("room.7", 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bus.Publish("room.7", "joined"); err != nil {
+		t.Fatal(err)
+	}
+	moved.expect(t, "joined")
+	old.expect(t)
+
+	// 跨节点迁移：按源节点导出的订阅描述在本节点重建
+	remote := newBusProbe(t, s, 30)
+	exported := []Subscription{{ActorID: 99, Topic: "guild.chat", Filter: "int"}}
+	if err := s.MigrateActor(99, 30, remote, exported...); !errors.Is(err, ErrActorExists) {
+		t.Fatalf("migrate onto a taken id = %v, want ErrActorExists", err)
+	}
+	s.Unregister(30)
+	if err := s.MigrateActor(99, 30, remote, exported...); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bus.Publish("guild.chat", 5); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bus.Publish("guild.chat", "hi"); err != nil {
+		t.Fatal(err)
+	}
+	remote.expect(t, 5)
+}
@@ -2,43 +2,300 @@ package Actor
 
 //group.go
 import (
+	"context"
+	"math"
+	"slices"
 	"sync"
+	"sync/atomic"
 	"time"
+	"zdopt/ZdoptServer/Clock"
+	"zdopt/ZdoptServer/Limit"
 )
 
 // Group Actor管理组
 type Group struct {
-	id        int
-	deltaTime time.Duration
-	actors    []Actor
-	index     uint64
-	mu        sync.RWMutex
+	id         int
+	deltaTime  time.Duration
+	actors     []Actor                 // 成员列表，mu 保护
+	snapshot   atomic.Pointer[[]Actor] // tick 循环遍历的只读快照，成员变更后置空、下次读取时重建
+	index      uint64
+	mu         sync.RWMutex
+	messages   int64 // 当前评估窗口内的消息数
+	controller *TickController
+	timeScale  atomic.Uint64 // math.Float64bits，传给 Update 的 delta = tick 间隔 × 倍率
+	paused     atomic.Bool
+	running    atomic.Bool
+	wake       chan struct{} // 恢复或修改间隔时唤醒 tick 循环
+	ctx        context.Context
+	stop       context.CancelFunc
+	done       chan struct{}
 }
 
 func NewGroup(id int, delta time.Duration) *Group {
-	return &Group{
+	g := &Group{
 		id:        id,
 		deltaTime: delta,
-		actors:    make([]Actor, 0, 1024),
+		wake:      make(chan struct{}, 1),
+		done:      make(chan struct{}),
 	}
+	g.ctx, g.stop = context.WithCancel(context.Background())
+	g.timeScale.Store(math.Float64bits(1))
+	return g
 }
 
-// AddActor 线程安全的Actor增加
+// AddActor 线程安全的Actor增加，只追加并作废快照，不等待进行中的 tick
 func (g *Group) AddActor(actor Actor) {
 	g.mu.Lock()
 	defer g.mu.Unlock()
 	g.actors = append(g.actors, actor)
+	g.snapshot.Store(nil)
+	if base := baseOf(actor); base != nil {
+		base.group.Store(g)
+	}
+}
+
+// RemoveActor 移除并停止Actor：先移出成员列表（之后的 tick 不再驱动它），
+// 再调用 Stop、等待邮箱排空并调用 PostStop。不能在该Actor自身的消息处理中调用
+func (g *Group) RemoveActor(actor Actor) bool {
+	removed := g.detach(func(a Actor) bool { return a == actor })
+	if removed == nil {
+		return false
+	}
+	stopActor(removed)
+	return true
+}
+
+// RemoveByID 按 ID 移除并停止Actor（仅限内嵌 BaseActor 的Actor），返回是否存在
+func (g *Group) RemoveByID(id int64) bool {
+	removed := g.detach(func(a Actor) bool {
+		base := baseOf(a)
+		return base != nil && base.ID() == id
+	})
+	if removed == nil {
+		return false
+	}
+	stopActor(removed)
+	return true
+}
+
+// detach 从成员列表移除第一个匹配的Actor；成员数降到容量的四分之一以下时收缩，底层数组不随历史峰值增长
+func (g *Group) detach(match func(Actor) bool) Actor {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for i, a := range g.actors {
+		if match(a) {
+			g.actors = slices.Delete(g.actors, i, i+1)
+			if cap(g.actors) > 64 && len(g.actors) < cap(g.actors)/4 {
+				g.actors = slices.Clone(g.actors)
+			}
+			g.snapshot.Store(nil)
+			if base := baseOf(a); base != nil {
+				base.group.CompareAndSwap(g, nil)
+			}
+			return a
+		}
+	}
+	return nil
+}
+
+// Len 当前成员数
+func (g *Group) Len() int {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return len(g.actors)
+}
+
+// Actors 当前成员快照，调用方不得修改。快照在成员变更后的首次读取时复制一次，
+// 多次变更只在下一个 tick 合并复制，遍历快照期间不持有锁
+func (g *Group) Actors() []Actor {
+	if p := g.snapshot.Load(); p != nil {
+		return *p
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if p := g.snapshot.Load(); p != nil {
+		return *p
+	}
+	snap := slices.Clone(g.actors)
+	g.snapshot.Store(&snap)
+	return snap
+}
+
+// SetTickController 启用自适应 tick 频率，nil 表示固定频率
+func (g *Group) SetTickController(tc *TickController) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.controller = tc
+}
+
+// RecordMessages 记录组内消息量，作为自适应 tick 的活跃度输入；内嵌 BaseActor 的成员每处理一条消息自动计入
+func (g *Group) RecordMessages(n int) {
+	atomic.AddInt64(&g.messages, int64(n))
+}
+
+// DeltaTime 当前 tick 间隔
+func (g *Group) DeltaTime() time.Duration {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.deltaTime
+}
+
+// SetDeltaTime 运行时修改 tick 间隔，下一次 tick 起生效
+func (g *Group) SetDeltaTime(d time.Duration) {
+	if d <= 0 {
+		return
+	}
+	g.mu.Lock()
+	g.deltaTime = d
+	g.mu.Unlock()
+	g.signal()
+}
+
+// SetTimeScale 设置模拟倍率：tick 间隔不变，传给 Update 的 delta 乘以倍率（<1 慢动作，>1 追帧）
+func (g *Group) SetTimeScale(scale float64) {
+	if scale > 0 {
+		g.timeScale.Store(math.Float64bits(scale))
+	}
 }
 
+// TimeScale 当前模拟倍率
+func (g *Group) TimeScale() float64 {
+	return math.Float64frombits(g.timeScale.Load())
+}
+
+// Pause 暂停 tick，成员Actor的消息循环不受影响
+func (g *Group) Pause() {
+	g.paused.Store(true)
+}
+
+// Resume 恢复 tick，从恢复时刻起重新计时
+func (g *Group) Resume() {
+	if g.paused.CompareAndSwap(true, false) {
+		g.signal()
+	}
+}
+
+// Paused 是否已暂停
+func (g *Group) Paused() bool {
+	return g.paused.Load()
+}
+
+// Stop 停止 tick 循环并等待其退出，不停止成员Actor；停止后不能再启动
+func (g *Group) Stop() {
+	g.stop()
+	if g.running.Load() {
+		<-g.done
+	}
+}
+
+func (g *Group) signal() {
+	select {
+	case g.wake <- struct{}{}:
+	default:
+	}
+}
+
+// StartUpdate 运行 tick 循环直到 Stop
 func (g *Group) StartUpdate() {
-	ticker := time.NewTicker(g.deltaTime)
+	g.StartUpdateContext(context.Background())
+}
+
+// StartUpdateContext 运行 tick 循环直到 ctx 结束或 Stop，重复调用直接返回
+func (g *Group) StartUpdateContext(ctx context.Context) {
+	if !g.running.CompareAndSwap(false, true) {
+		return
+	}
+	defer close(g.done)
+	// 外部 ctx 结束等同于 Stop
+	unbind := context.AfterFunc(ctx, g.stop)
+	defer unbind()
+	ctx = g.ctx
+
+	interval := g.DeltaTime()
+	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
+	clock := Clock.NewDetector("actor.group")
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-g.wake:
+			if next := g.DeltaTime(); next != interval {
+				interval = next
+				ticker.Reset(interval)
+			}
+			continue
+		case <-ticker.C:
+		}
+
+		if g.paused.Load() {
+			ticker.Stop()
+			for g.paused.Load() {
+				select {
+				case <-ctx.Done():
+					return
+				case <-g.wake:
+				}
+			}
+			interval = g.DeltaTime()
+			ticker.Reset(interval)
+			clock.Reset()
+			continue
+		}
 
-	for range ticker.C {
-		g.mu.Lock()
-		for _, actor := range g.actors {
-			go actor.Update(g.deltaTime)
+		// 时钟跳变（挂起恢复、校时）按 actor.group 的策略处理：跳过、追帧或暂停
+		step, jump := clock.Observe(time.Now(), interval)
+		if jump != nil && jump.Policy == Clock.Pause {
+			g.Pause()
+			continue
 		}
+		delta := time.Duration(float64(step) * g.TimeScale())
+		for _, actor := range g.Actors() {
+			Limit.Go("actor.tick", func() {
+				actor.Update(delta)
+				// 长任务在每次 tick 后推进一个时间片
+				if base := baseOf(actor); base != nil {
+					base.scheduleTaskSlice()
+				}
+			})
+		}
+
+		if next, ok := g.adjustTickRate(); ok {
+			interval = next
+			ticker.Reset(next)
+		}
+	}
+}
+
+// adjustTickRate 根据控制器评估结果调整 tick 间隔
+func (g *Group) adjustTickRate() (time.Duration, bool) {
+	g.mu.Lock()
+	tc := g.controller
+	if tc == nil {
 		g.mu.Unlock()
+		return 0, false
+	}
+
+	old := g.deltaTime
+	members := len(g.actors) // 已持有 mu，不经 Actors()（重建快照需加锁）
+	next, load, changed := tc.next(old, members, atomic.LoadInt64(&g.messages))
+	if tc.tick == 0 {
+		atomic.StoreInt64(&g.messages, 0)
+	}
+	if changed {
+		g.deltaTime = next
+	}
+	g.mu.Unlock()
+
+	if changed && tc.cfg.OnChange != nil {
+		tc.cfg.OnChange(RateChange{
+			GroupID: g.id,
+			Old:     old,
+			New:     next,
+			Members: members,
+			Load:    load,
+		})
 	}
+	return next, changed
 }
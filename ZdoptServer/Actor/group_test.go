@@ -0,0 +1,168 @@
+package Actor
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+	"zdopt/ZdoptServer/Limit"
+)
+
+// tickActor 只记录 Update 次数
+type tickActor struct {
+	updates atomic.Int64
+}
+
+func (a *tickActor) Init(ctx context.Context)   {}
+func (a *tickActor) Start()                     {}
+func (a *tickActor) Stop()                      {}
+func (a *tickActor) Update(delta time.Duration) { a.updates.Add(1) }
+func (a *tickActor) Receive(msg interface{})    {}
+
+func TestGroupTicksActorsAddedDuringTicks(t *testing.T) {
+	g := NewGroup(1, time.Millisecond)
+	go g.StartUpdate()
+	defer g.Stop()
+
+	actors := make([]*tickActor, 64)
+	var wg sync.WaitGroup
+	for i := range actors {
+		actors[i] = &tickActor{}
+		wg.Add(1)
+		go func(a *tickActor) {
+			defer wg.Done()
+			g.AddActor(a)
+		}(actors[i])
+	}
+	wg.Wait()
+	if g.Len() != len(actors) {
+		t.Fatalf("Len = %d, want %d", g.Len(), len(actors))
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for _, a := range actors {
+		for a.updates.Load() == 0 && time.Now().Before(deadline) {
+			time.Sleep(time.Millisecond)
+		}
+		if a.updates.Load() == 0 {
+			t.Fatal("actor added during ticks never updated")
+		}
+	}
+	for _, a := range actors {
+		if !g.RemoveActor(a) {
+			t.Fatal("RemoveActor reported missing member")
+		}
+	}
+	if g.Len() != 0 {
+		t.Fatalf("Len = %d after removing all", g.Len())
+	}
+}
+
+func TestGroupRecordsMessagesHandledByMembers(t *testing.T) {
+	s := NewSystem()
+	a := newCountingActor(s)
+	a.Init(context.Background())
+	defer stopActor(a)
+	g := NewGroup(1, time.Hour)
+	g.AddActor(a)
+	for i := 0; i < 5; i++ {
+		if err := a.Send(i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for a.handled.Load() < 5 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if got := atomic.LoadInt64(&g.messages); got != 5 {
+		t.Fatalf("group recorded %d messages, want 5", got)
+	}
+
+	// 移出组后不再计入
+	g.detach(func(m Actor) bool { return m == a })
+	a.Send(5)
+	for a.handled.Load() < 6 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if got := atomic.LoadInt64(&g.messages); got != 5 {
+		t.Fatalf("group recorded %d messages after detach, want 5", got)
+	}
+}
+
+// lockedGroup 对照组：tick 期间持有写锁遍历成员（改为写时复制之前的做法）
+type lockedGroup struct {
+	mu     sync.Mutex
+	actors []Actor
+}
+
+func (g *lockedGroup) AddActor(a Actor) {
+	g.mu.Lock()
+	g.actors = append(g.actors, a)
+	g.mu.Unlock()
+}
+
+func (g *lockedGroup) RemoveActor(a Actor) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for i, m := range g.actors {
+		if m == a {
+			g.actors = append(g.actors[:i], g.actors[i+1:]...)
+			return
+		}
+	}
+}
+
+func (g *lockedGroup) run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		g.mu.Lock()
+		for _, actor := range g.actors {
+			Limit.Go("actor.tick", func() { actor.Update(interval) })
+		}
+		g.mu.Unlock()
+	}
+}
+
+// BenchmarkGroupMembership tick 循环驱动大量成员时，成员加入与移除的延迟分位：
+// snapshot 为 Group 的快照遍历，locked 为 tick 期间持锁遍历的对照
+func BenchmarkGroupMembership(b *testing.B) {
+	const members = 2000
+	run := func(b *testing.B, add func(Actor), remove func(Actor)) {
+		for i := 0; i < members; i++ {
+			add(&tickActor{})
+		}
+		latencies := make([]time.Duration, 0, b.N)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			a := &tickActor{}
+			start := time.Now()
+			add(a)
+			remove(a)
+			latencies = append(latencies, time.Since(start))
+		}
+		b.StopTimer()
+		sort.Slice(latencies, func(i, k int) bool { return latencies[i] < latencies[k] })
+		b.ReportMetric(float64(latencies[len(latencies)/2].Microseconds()), "p50-µs")
+		b.ReportMetric(float64(latencies[len(latencies)*99/100].Microseconds()), "p99-µs")
+	}
+	b.Run("snapshot", func(b *testing.B) {
+		g := NewGroup(1, time.Millisecond)
+		go g.StartUpdate()
+		defer g.Stop()
+		run(b, g.AddActor, func(a Actor) { g.detach(func(m Actor) bool { return m == a }) })
+	})
+	b.Run("locked", func(b *testing.B) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		g := &lockedGroup{}
+		go g.run(ctx, time.Millisecond)
+		run(b, g.AddActor, g.RemoveActor)
+	})
+}
@@ -0,0 +1,156 @@
+package Actor
+
+//handler.go
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync/atomic"
+	"time"
+)
+
+// HandlerOption 处理器注册选项
+type HandlerOption func(*handlerEntry)
+
+// handlerEntry 处理器及其分发策略
+type handlerEntry struct {
+	msgType string
+	fn      func(*MessageContext, interface{})
+	limiter *rateLimiter[int64]
+	withCtx bool // 处理器需要 MessageContext
+}
+
+// WithRateLimit 按发送者限流：每个发送者在 per 时间内最多处理 n 条该类型消息
+func WithRateLimit(n int, per time.Duration) HandlerOption {
+	return func(h *handlerEntry) {
+		if n > 0 && per > 0 {
+			h.limiter = newRateLimiter[int64](n, per)
+		}
+	}
+}
+
+// RegisterHandler 注册类型化消息处理器，分发时按消息的动态类型匹配
+func RegisterHandler[T any](a HandlerTarget, fn func(T), opts ...HandlerOption) {
+	msgType := typeKey[T]()
+	entry := &handlerEntry{
+		msgType: msgType,
+		fn: func(_ *MessageContext, msg interface{}) {
+			fn(msg.(T))
+		},
+	}
+	for _, opt := range opts {
+		opt(entry)
+	}
+	a.handlerSet().Store(msgType, entry)
+}
+
+// Handle 注册带上下文的类型化消息处理器，上下文携带 Deadline、发送者、日志与指标作用域
+func Handle[T any](a HandlerTarget, fn func(*MessageContext, T), opts ...HandlerOption) {
+	msgType := typeKey[T]()
+	entry := &handlerEntry{
+		msgType: msgType,
+		withCtx: true,
+		fn: func(ctx *MessageContext, msg interface{}) {
+			fn(ctx, msg.(T))
+		},
+	}
+	for _, opt := range opts {
+		opt(entry)
+	}
+	a.handlerSet().Store(msgType, entry)
+}
+
+// UnregisterHandler 注销类型 T 的处理器（含 Handle/HandleAsk 注册的），返回是否存在；之后该类型消息被忽略
+func UnregisterHandler[T any](a HandlerTarget) bool {
+	_, ok := a.handlerSet().LoadAndDelete(typeKey[T]())
+	return ok
+}
+
+// HasHandler 是否已注册类型 T 的处理器
+func HasHandler[T any](a HandlerTarget) bool {
+	_, ok := a.handlerSet().Load(typeKey[T]())
+	return ok
+}
+
+// typeKey 类型 T 对应的处理器键，与 getMessageType 的结果一致
+func typeKey[T any]() string {
+	return reflect.TypeOf((*T)(nil)).Elem().String()
+}
+
+// dispatch 单条消息分发：拆信封、重放保护后交给 handle；开启重发缓冲时由此应答 ResendRequest
+func (a *BaseActor) dispatch(m interface{}) {
+	payload, env := unwrap(m)
+	if guard := a.replay.Load(); guard != nil && !guard.accept(a, env) {
+		resolveRejected(env, ErrDuplicateMessage)
+		return
+	}
+	if req, ok := payload.(*ResendRequest); ok && env != nil && env.ReplyTo != nil {
+		if buf := a.resend.Load(); buf != nil {
+			buf.retransmit(env.ReplyTo, req.From, req.To)
+			return
+		}
+	}
+	a.handle(m)
+}
+
+// handle 优先级继承、闭包任务、过期丢弃、限流与处理器调用
+func (a *BaseActor) handle(m interface{}) {
+	processedMessages.Add(1)
+	if g := a.group.Load(); g != nil {
+		g.RecordMessages(1)
+	}
+	payload, env := unwrap(m)
+	if env != nil {
+		p := clampPriority(env.Priority)
+		atomic.AddInt32(&a.boosts[p], 1)
+		defer atomic.AddInt32(&a.boosts[p], -1)
+	}
+	if task, ok := payload.(Task); ok {
+		task()
+		return
+	}
+
+	handler, ok := a.lookupHandler(getMessageType(payload))
+	if !ok {
+		a.deadLetter(m, NoHandler)
+		resolveRejected(env, fmt.Errorf("%w: %s", ErrNoHandler, getMessageType(payload)))
+		return
+	}
+
+	now := time.Now()
+	if expired(env, now) {
+		expiredMessages.Add(1)
+		a.deadLetter(m, Expired)
+		resolveRejected(env, context.DeadlineExceeded)
+		return
+	}
+
+	if handler.limiter != nil {
+		var sender int64
+		if env != nil {
+			sender = env.Sender
+		}
+		if allowed, retryAfter := handler.limiter.allow(sender, now); !allowed {
+			a.rejectRateLimited(handler.msgType, env, retryAfter)
+			return
+		}
+	}
+
+	defer observeHandler(handler.msgType, now)
+	span := startSpan(a, env, handler.msgType, now)
+	defer finishSpan(span)
+	mws := a.middleware.Load().load()
+	if !handler.withCtx && len(mws) == 0 {
+		defer resolveUnanswered(env, handler.msgType)
+		handler.fn(nil, payload)
+		return
+	}
+	ctx, cancel := a.newMessageContext(handler.msgType, env, span.ref())
+	defer cancel()
+	defer ctx.settleAsk()
+	if len(mws) == 0 {
+		handler.fn(ctx, payload)
+		return
+	}
+	wrapHandler(handler.fn, mws)(ctx, payload)
+}
@@ -0,0 +1,151 @@
+package Actor
+
+//hotswap.go
+import (
+	"errors"
+	"expvar"
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	ErrGroupNotFound = errors.New("actor group not found")
+
+	handlerSwaps = expvar.NewMap("actors.handler_swaps") // 按消息类型统计已生效的处理器替换
+)
+
+// pendingSwaps 待生效的处理器替换：在两批消息之间应用，同一批消息不会混用新旧处理器
+type pendingSwaps struct {
+	mu      sync.Mutex
+	pending map[string]func(*MessageContext, interface{})
+	dirty   atomic.Bool
+}
+
+// ReplaceHandler 替换已注册的 msgType 处理器（保留限流等注册选项），用于长期运行的服务器在线调整玩法逻辑。
+// 消息循环运行中时替换在当前批次处理完后生效；同一类型多次替换以最后一次为准。未注册该类型时返回 ErrNoHandler
+func (a *BaseActor) ReplaceHandler(msgType string, fn func(*MessageContext, interface{})) error {
+	if fn == nil {
+		return fmt.Errorf("replace handler %s: nil function", msgType)
+	}
+	if _, ok := a.handlers.Load(msgType); !ok {
+		return fmt.Errorf("%w: %s", ErrNoHandler, msgType)
+	}
+	if a.ctx == nil {
+		// 消息循环未启动，直接替换
+		a.swapHandler(msgType, fn)
+		return nil
+	}
+	s := &a.swaps
+	s.mu.Lock()
+	if s.pending == nil {
+		s.pending = make(map[string]func(*MessageContext, interface{}))
+	}
+	s.pending[msgType] = fn
+	s.mu.Unlock()
+	s.dirty.Store(true)
+	// 空闲的Actor没有下一批消息，投递一个空任务触发应用
+	a.mailbox.Enqueue(Task(func() {}))
+	return nil
+}
+
+// ReplaceHandlerFunc 按类型替换处理器，见 ReplaceHandler
+func ReplaceHandlerFunc[T any](a *BaseActor, fn func(*MessageContext, T)) error {
+	return a.ReplaceHandler(typeKey[T](), func(ctx *MessageContext, msg interface{}) {
+		fn(ctx, msg.(T))
+	})
+}
+
+// applySwaps 在两批消息之间由消息循环调用
+func (a *BaseActor) applySwaps() {
+	s := &a.swaps
+	if !s.dirty.Load() {
+		return
+	}
+	s.mu.Lock()
+	pending := s.pending
+	s.pending = nil
+	s.dirty.Store(false)
+	s.mu.Unlock()
+	for msgType, fn := range pending {
+		a.swapHandler(msgType, fn)
+	}
+}
+
+// swapHandler 以新函数替换注册项，新处理器总是接收 MessageContext
+func (a *BaseActor) swapHandler(msgType string, fn func(*MessageContext, interface{})) {
+	v, ok := a.handlers.Load(msgType)
+	if !ok {
+		return // 替换生效前已被注销
+	}
+	entry := *v.(*handlerEntry)
+	entry.fn = fn
+	entry.withCtx = true
+	a.handlers.Store(msgType, &entry)
+	handlerSwaps.Add(msgType, 1)
+}
+
+// HandlerPatch 一个处理器替换，由 PatchGroup 应用到组内Actor
+type HandlerPatch struct {
+	MsgType string
+	Fn      func(*MessageContext, interface{})
+}
+
+// NewHandlerPatch 按类型构造处理器替换
+func NewHandlerPatch[T any](fn func(*MessageContext, T)) HandlerPatch {
+	return HandlerPatch{
+		MsgType: typeKey[T](),
+		Fn: func(ctx *MessageContext, msg interface{}) {
+			fn(ctx, msg.(T))
+		},
+	}
+}
+
+// PatchGroup 把处理器替换应用到组内注册了对应类型的全部Actor（含监督者下子Actor的当前实例），返回替换的处理器数。
+// 监督者重启的新实例使用构造时注册的处理器，需要时重新应用
+func (s *System) PatchGroup(groupID int, patches ...HandlerPatch) (int, error) {
+	s.FuncgroupLock.RLock()
+	g, ok := s.groups[groupID]
+	s.FuncgroupLock.RUnlock()
+	if !ok {
+		return 0, fmt.Errorf("%w: %d", ErrGroupNotFound, groupID)
+	}
+	var targets []*BaseActor
+	for _, a := range g.Actors() {
+		targets = appendPatchTargets(targets, a)
+	}
+	patched := 0
+	var errs []error
+	for _, base := range targets {
+		for _, p := range patches {
+			if _, ok := base.handlers.Load(p.MsgType); !ok {
+				continue
+			}
+			if err := base.ReplaceHandler(p.MsgType, p.Fn); err != nil {
+				errs = append(errs, fmt.Errorf("actor %d: %w", base.ID(), err))
+				continue
+			}
+			patched++
+		}
+	}
+	return patched, errors.Join(errs...)
+}
+
+func appendPatchTargets(targets []*BaseActor, a Actor) []*BaseActor {
+	switch v := a.(type) {
+	case *supervised:
+		if cur := v.current(); cur != nil {
+			return appendPatchTargets(targets, cur)
+		}
+		return targets
+	case *Supervisor:
+		for _, child := range v.Children() {
+			targets = appendPatchTargets(targets, child)
+		}
+		return targets
+	}
+	if base := baseOf(a); base != nil {
+		targets = append(targets, base)
+	}
+	return targets
+}
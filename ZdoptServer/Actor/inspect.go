@@ -0,0 +1,111 @@
+package Actor
+
+//inspect.go
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// ActorInfo 单个 Actor 的状态快照（管理端列表用）
+type ActorInfo struct {
+	ID       int64    `json:"id"`
+	Type     string   `json:"type"`
+	Names    []string `json:"names,omitempty"`  // RegisterName 登记的名称
+	Groups   []int    `json:"groups,omitempty"` // 所在的组
+	Depth    int      `json:"depth"`            // 两条邮箱的待处理消息数
+	Capacity int      `json:"capacity"`
+	Stopped  bool     `json:"stopped"`
+}
+
+// GroupInfo 单个组的状态快照
+type GroupInfo struct {
+	ID        int           `json:"id"`
+	Actors    []int64       `json:"actors"`
+	DeltaTime time.Duration `json:"delta_time"`
+	TimeScale float64       `json:"time_scale"`
+	Paused    bool          `json:"paused"`
+	Running   bool          `json:"running"`
+}
+
+// Stopping 是否已调用 Stop 或 Shutdown
+func (s *System) Stopping() bool {
+	return s.stopping.Load()
+}
+
+// Actors 按 ID 登记、按名称登记或在组中的全部 Actor，按 ID 排序；没有 BaseActor 的 Actor 不列出
+func (s *System) Actors() []ActorInfo {
+	byBase := make(map[*BaseActor]*ActorInfo)
+	visit := func(a Actor) *ActorInfo {
+		base := baseOf(a)
+		if base == nil {
+			return nil
+		}
+		if info, ok := byBase[base]; ok {
+			return info
+		}
+		info := &ActorInfo{
+			ID:       base.id,
+			Type:     fmt.Sprintf("%T", a),
+			Depth:    base.mailbox.Len() + base.urgent.Len(),
+			Capacity: base.mailbox.Cap() + base.urgent.Cap(),
+			Stopped:  base.stopped(),
+		}
+		byBase[base] = info
+		return info
+	}
+	s.actors.Range(func(_, v interface{}) bool {
+		visit(v.(Actor))
+		return true
+	})
+	s.names.mu.RLock()
+	for name, a := range s.names.names {
+		if info := visit(a); info != nil {
+			info.Names = append(info.Names, name)
+		}
+	}
+	s.names.mu.RUnlock()
+	s.FuncgroupLock.RLock()
+	for id, g := range s.groups {
+		for _, a := range g.Actors() {
+			if info := visit(a); info != nil {
+				info.Groups = append(info.Groups, id)
+			}
+		}
+	}
+	s.FuncgroupLock.RUnlock()
+
+	out := make([]ActorInfo, 0, len(byBase))
+	for _, info := range byBase {
+		sort.Strings(info.Names)
+		sort.Ints(info.Groups)
+		out = append(out, *info)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
+
+// Groups 全部组的状态，按组 ID 排序
+func (s *System) Groups() []GroupInfo {
+	s.FuncgroupLock.RLock()
+	out := make([]GroupInfo, 0, len(s.groups))
+	for id, g := range s.groups {
+		info := GroupInfo{
+			ID:        id,
+			Actors:    []int64{},
+			DeltaTime: g.DeltaTime(),
+			TimeScale: g.TimeScale(),
+			Paused:    g.Paused(),
+			Running:   g.running.Load(),
+		}
+		for _, a := range g.Actors() {
+			if base := baseOf(a); base != nil {
+				info.Actors = append(info.Actors, base.id)
+			}
+		}
+		out = append(out, info)
+	}
+	s.FuncgroupLock.RUnlock()
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
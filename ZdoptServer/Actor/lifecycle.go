@@ -0,0 +1,76 @@
+package Actor
+
+//lifecycle.go
+import (
+	"context"
+	"fmt"
+)
+
+// PreStarter 消息循环启动前调用，可在此获取资源（如 ObjectPool 对象）；返回错误时Actor不启动
+type PreStarter interface {
+	PreStart(ctx context.Context) error
+}
+
+// PostStopper 停止且邮箱排空后调用，可在此确定性地释放资源
+type PostStopper interface {
+	PostStop()
+}
+
+// PreRestarter 监督者重启前在失败的实例上调用，随后该实例按正常流程停止（含 PostStop）
+type PreRestarter interface {
+	PreRestart(reason error)
+}
+
+// startActor 依次调用 PreStart 与 Init（启动消息循环），实现了 Snapshotter 时记录初始快照
+func startActor(ctx context.Context, a Actor) error {
+	if p, ok := a.(PreStarter); ok {
+		if err := p.PreStart(ctx); err != nil {
+			return fmt.Errorf("actor prestart: %w", err)
+		}
+	}
+	if sn, ok := a.(Snapshotter); ok {
+		if base := baseOf(a); base != nil {
+			base.enableSnapshots(sn)
+		}
+	}
+	a.Init(ctx)
+	return nil
+}
+
+// stopActor 调用 Stop，结束消息循环并等待邮箱排空后调用 PostStop。
+// 不能在该Actor自身的消息处理协程中调用（会等待自身）
+func stopActor(a Actor) {
+	a.Stop()
+	if base := baseOf(a); base != nil {
+		base.shutdown()
+		if bus := base.bus.Load(); bus != nil {
+			bus.unsubscribeActor(base)
+		}
+	}
+	if p, ok := a.(PostStopper); ok {
+		p.PostStop()
+	}
+}
+
+// shutdown 取消消息循环并等待其处理完剩余消息后退出
+func (a *BaseActor) shutdown() {
+	if a.cancel == nil {
+		return
+	}
+	a.cancel()
+	a.wg.Wait()
+}
+
+// drainPending 取出队列中当前剩余的消息（加急优先），用于停止时排空
+func (a *BaseActor) drainPending(msgs []interface{}) []interface{} {
+	for _, q := range []*MessageQueue{a.urgent, a.mailbox} {
+		for n := q.Len(); n > 0; n-- {
+			msg, ok := q.Dequeue()
+			if !ok {
+				break
+			}
+			msgs = append(msgs, msg)
+		}
+	}
+	return msgs
+}
@@ -0,0 +1,219 @@
+package Actor
+
+//longtask.go
+import (
+	"context"
+	"expvar"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var activeTasks = expvar.NewInt("actors.tasks.active")
+
+// defaultTaskBudget 每次 tick 分给长任务的默认时间片
+const defaultTaskBudget = 2 * time.Millisecond
+
+// WithTaskBudget 设置每次 tick 运行长任务的时间片
+func WithTaskBudget(d time.Duration) BaseActorOption {
+	return func(o *baseActorOptions) {
+		if d > 0 {
+			o.taskBudget = d
+		}
+	}
+}
+
+// TaskStep 长任务的单步：执行一小段工作后返回，done 为 true 表示完成。
+// 步骤之间让出执行权，单步耗时应远小于时间片
+type TaskStep func(t *TaskContext) (done bool, err error)
+
+// TaskContext 长任务上下文：任务取消或Actor停止时结束
+type TaskContext struct {
+	context.Context
+	task *LongTask
+}
+
+// SetProgress 上报进度（0~1）
+func (c *TaskContext) SetProgress(p float64) {
+	c.task.progress.Store(math.Float64bits(min(max(p, 0), 1)))
+}
+
+// LongTask 分片执行的长任务句柄
+type LongTask struct {
+	Name     string
+	step     TaskStep
+	ctx      context.Context
+	cancel   context.CancelFunc
+	progress atomic.Uint64 // float64 位模式
+	steps    atomic.Int64
+	done     chan struct{}
+	err      error
+}
+
+// Progress 最近上报的进度
+func (t *LongTask) Progress() float64 {
+	return math.Float64frombits(t.progress.Load())
+}
+
+// Steps 已执行的步数
+func (t *LongTask) Steps() int64 {
+	return t.steps.Load()
+}
+
+// Done 任务结束（完成、失败或取消）时关闭
+func (t *LongTask) Done() <-chan struct{} {
+	return t.done
+}
+
+// Err 任务结束后的错误，取消时为 context.Canceled
+func (t *LongTask) Err() error {
+	select {
+	case <-t.done:
+		return t.err
+	default:
+		return nil
+	}
+}
+
+// Cancel 取消任务，在下一个时间片开始前生效
+func (t *LongTask) Cancel() {
+	t.cancel()
+}
+
+// Wait 等待任务结束
+func (t *LongTask) Wait(ctx context.Context) error {
+	select {
+	case <-t.done:
+		return t.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (t *LongTask) finish(err error) {
+	t.err = err
+	if err == nil {
+		t.progress.Store(math.Float64bits(1))
+	}
+	t.cancel()
+	close(t.done)
+	activeTasks.Add(-1)
+}
+
+// taskRunner Actor的长任务队列
+type taskRunner struct {
+	mu     sync.Mutex
+	tasks  []*LongTask
+	next   int
+	queued atomic.Bool // 已有时间片在邮箱中等待
+	budget time.Duration
+}
+
+// SubmitTask 提交长任务：所在 Group 每次 tick 后在消息循环中按时间片推进，任务之间轮流执行
+func (a *BaseActor) SubmitTask(name string, step TaskStep) *LongTask {
+	parent := a.ctx
+	if parent == nil {
+		parent = context.Background()
+	}
+	t := &LongTask{Name: name, step: step, done: make(chan struct{})}
+	t.ctx, t.cancel = context.WithCancel(parent)
+	activeTasks.Add(1)
+
+	a.tasks.mu.Lock()
+	a.tasks.tasks = append(a.tasks.tasks, t)
+	a.tasks.mu.Unlock()
+	return t
+}
+
+// PendingTasks 未结束的长任务数
+func (a *BaseActor) PendingTasks() int {
+	a.tasks.mu.Lock()
+	defer a.tasks.mu.Unlock()
+	return len(a.tasks.tasks)
+}
+
+// scheduleTaskSlice 有待执行的长任务时向邮箱投递一个时间片（由 Group tick 调用）
+func (a *BaseActor) scheduleTaskSlice() {
+	if a.PendingTasks() == 0 || !a.tasks.queued.CompareAndSwap(false, true) {
+		return
+	}
+	slice := Task(func() {
+		a.tasks.queued.Store(false)
+		a.RunTaskSlice(0)
+	})
+	if !a.mailbox.Enqueue(slice) {
+		// 邮箱已满，下次 tick 再试
+		a.tasks.queued.Store(false)
+	}
+}
+
+// RunTaskSlice 在 budget 时间内轮流推进长任务（0 使用配置的时间片），返回剩余任务数。
+// 不在 Group 中的Actor可在自己的循环中调用
+func (a *BaseActor) RunTaskSlice(budget time.Duration) int {
+	if budget <= 0 {
+		budget = a.tasks.budget
+	}
+	if budget <= 0 {
+		budget = defaultTaskBudget
+	}
+	deadline := time.Now().Add(budget)
+
+	for time.Now().Before(deadline) {
+		t := a.nextTask()
+		if t == nil {
+			break
+		}
+		done, err := a.runStep(t)
+		if err == nil {
+			err = t.ctx.Err()
+		}
+		if done || err != nil {
+			a.removeTask(t)
+			t.finish(err)
+		}
+	}
+	return a.PendingTasks()
+}
+
+func (a *BaseActor) nextTask() *LongTask {
+	r := &a.tasks
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.tasks) == 0 {
+		return nil
+	}
+	r.next %= len(r.tasks)
+	t := r.tasks[r.next]
+	r.next++
+	return t
+}
+
+func (a *BaseActor) removeTask(t *LongTask) {
+	r := &a.tasks
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, cur := range r.tasks {
+		if cur == t {
+			r.tasks = append(r.tasks[:i], r.tasks[i+1:]...)
+			if r.next > i {
+				r.next--
+			}
+			return
+		}
+	}
+}
+
+// runStep 执行一步，已取消的任务不再执行；步骤 panic 视为任务失败
+func (a *BaseActor) runStep(t *LongTask) (done bool, err error) {
+	if err := t.ctx.Err(); err != nil {
+		return false, err
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			done, err = false, &ChildFailure{Child: t.Name, Value: r}
+		}
+	}()
+	t.steps.Add(1)
+	return t.step(&TaskContext{Context: t.ctx, task: t})
+}
@@ -0,0 +1,190 @@
+package Actor
+
+//mailbox.go
+import (
+	"errors"
+	"expvar"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"zdopt/ZdoptServer/Strict"
+)
+
+var (
+	ErrMailboxFull  = errors.New("actor mailbox full")
+	ErrActorStopped = errors.New("actor stopped")
+
+	mailboxMetrics = expvar.NewMap("actors.mailbox") // blocked / dropped_newest / dropped_oldest / rejected
+)
+
+// MailboxPolicy 邮箱已满时的处理策略
+type MailboxPolicy int
+
+const (
+	Block       MailboxPolicy = iota // 阻塞等待空位（默认，与原有行为一致）
+	DropNewest                       // 丢弃新消息
+	DropOldest                       // 丢弃最早的消息后写入
+	ReturnError                      // 返回 ErrMailboxFull
+)
+
+var mailboxPolicyNames = [...]string{"block", "drop_newest", "drop_oldest", "return_error"}
+
+func (p MailboxPolicy) String() string {
+	if p >= 0 && int(p) < len(mailboxPolicyNames) {
+		return mailboxPolicyNames[p]
+	}
+	return fmt.Sprintf("MailboxPolicy(%d)", int(p))
+}
+
+// ParseMailboxPolicy 解析策略名称（block / drop_newest / drop_oldest / return_error），空串为 Block
+func ParseMailboxPolicy(s string) (MailboxPolicy, error) {
+	if s == "" {
+		return Block, nil
+	}
+	for i, name := range mailboxPolicyNames {
+		if strings.EqualFold(s, name) {
+			return MailboxPolicy(i), nil
+		}
+	}
+	return Block, fmt.Errorf("unknown mailbox policy %q", s)
+}
+
+// WithMailboxPolicy 设置邮箱满时的处理策略
+func WithMailboxPolicy(p MailboxPolicy) BaseActorOption {
+	return func(o *baseActorOptions) {
+		o.policy = p
+	}
+}
+
+// MailboxStats 邮箱队列深度与丢弃统计
+type MailboxStats struct {
+	Policy         MailboxPolicy
+	Depth          int
+	Capacity       int
+	UrgentDepth    int
+	UrgentCapacity int
+	HighWater      int64 // 观测到的最大普通邮箱深度
+	Dropped        int64
+	Rejected       int64
+	RateLimited    int64 // 入站限流丢弃数
+}
+
+// mailboxCounters 单个Actor的邮箱统计
+type mailboxCounters struct {
+	highWater   atomic.Int64
+	dropped     atomic.Int64
+	rejected    atomic.Int64
+	rateLimited atomic.Int64
+}
+
+// MailboxStats 当前邮箱统计
+func (a *BaseActor) MailboxStats() MailboxStats {
+	return MailboxStats{
+		Policy:         a.policy,
+		Depth:          a.mailbox.Len(),
+		Capacity:       a.mailbox.Cap(),
+		UrgentDepth:    a.urgent.Len(),
+		UrgentCapacity: a.urgent.Cap(),
+		HighWater:      a.counters.highWater.Load(),
+		Dropped:        a.counters.dropped.Load(),
+		Rejected:       a.counters.rejected.Load(),
+		RateLimited:    a.counters.rateLimited.Load(),
+	}
+}
+
+// Send 按邮箱策略投递消息：信封按优先级进入加急通道或普通邮箱，其他消息进入普通邮箱。
+// 仅 ReturnError 策略在邮箱满时返回错误；被丢弃或拒绝的 Ask 请求以 ErrMailboxFull 完成。
+// 设置了入站限流时，超限的消息返回 *RateLimitedError
+func (a *BaseActor) Send(msg interface{}) error {
+	if a.inbound != nil {
+		if err := a.admitInbound(msg); err != nil {
+			return err
+		}
+	}
+	lane, q := laneMailbox, a.mailbox
+	env, isEnv := msg.(*Envelope)
+	if isEnv && env.Priority > a.Priority() {
+		lane, q = laneUrgent, a.urgent
+	}
+	if isEnv && Strict.Enabled() {
+		var err error
+		a.order.stampAndSend(lane, env, func() bool {
+			err = a.enqueue(q, msg)
+			return err == nil
+		})
+		return err
+	}
+	return a.enqueue(q, msg)
+}
+
+// enqueue 写入队列，满时按策略处理
+func (a *BaseActor) enqueue(q *MessageQueue, msg interface{}) error {
+	if q.Enqueue(msg) {
+		a.observeDepth(q)
+		return nil
+	}
+
+	switch a.policy {
+	case DropNewest:
+		a.drop(msg, "dropped_newest")
+		return nil
+
+	case DropOldest:
+		for !q.Enqueue(msg) {
+			if old, ok := q.Dequeue(); ok {
+				a.drop(old, "dropped_oldest")
+			}
+		}
+		a.observeDepth(q)
+		return nil
+
+	case ReturnError:
+		a.counters.rejected.Add(1)
+		mailboxMetrics.Add("rejected", 1)
+		a.deadLetter(msg, MailboxFull)
+		_, env := unwrap(msg)
+		resolveRejected(env, ErrMailboxFull)
+		return fmt.Errorf("%w: actor %d", ErrMailboxFull, a.id)
+
+	default:
+		mailboxMetrics.Add("blocked", 1)
+		_, env := unwrap(msg)
+		var cancel <-chan struct{}
+		if env != nil && env.ctx != nil {
+			cancel = env.ctx.Done()
+		}
+		if q.enqueueWait(a.done(), cancel, msg) {
+			return nil
+		}
+		if cancel != nil && env.ctx.Err() != nil {
+			// 请求方已放弃，未投递的请求不进死信
+			mailboxMetrics.Add("canceled", 1)
+			resolveRejected(env, env.ctx.Err())
+			return env.ctx.Err()
+		}
+		a.deadLetter(msg, ActorStopped)
+		resolveRejected(env, ErrActorStopped)
+		return fmt.Errorf("%w: actor %d", ErrActorStopped, a.id)
+	}
+}
+
+func (a *BaseActor) drop(msg interface{}, metric string) {
+	a.counters.dropped.Add(1)
+	mailboxMetrics.Add(metric, 1)
+	a.deadLetter(msg, MailboxFull)
+	_, env := unwrap(msg)
+	resolveRejected(env, ErrMailboxFull)
+}
+
+func (a *BaseActor) observeDepth(q *MessageQueue) {
+	if q != a.mailbox {
+		return
+	}
+	depth := int64(q.Len())
+	for {
+		hw := a.counters.highWater.Load()
+		if depth <= hw || a.counters.highWater.CompareAndSwap(hw, depth) {
+			return
+		}
+	}
+}
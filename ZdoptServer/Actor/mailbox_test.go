@@ -0,0 +1,103 @@
+package Actor
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// fill 把邮箱填满 int 消息 1..capacity
+func fill(t *testing.T, a *BaseActor) int {
+	t.Helper()
+	n := a.MailboxStats().Capacity
+	for i := 1; i <= n; i++ {
+		if err := a.Send(i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return n
+}
+
+func TestDropOldestEvictsEarliestMessage(t *testing.T) {
+	a := NewBaseActor(4, WithMailboxPolicy(DropOldest))
+	n := fill(t, a)
+	if err := a.Send(n + 1); err != nil {
+		t.Fatalf("send to full DropOldest mailbox = %v", err)
+	}
+	stats := a.MailboxStats()
+	if stats.Dropped != 1 || stats.Depth != n || stats.HighWater != int64(n) {
+		t.Fatalf("stats after overflow = %+v", stats)
+	}
+	if v, _ := a.mailbox.Dequeue(); v != 2 {
+		t.Fatalf("head after overflow = %v, want 2", v)
+	}
+
+	// 被挤掉的 Ask 请求以 ErrMailboxFull 完成
+	b := NewBaseActor(2, WithMailboxPolicy(DropOldest))
+	sender := NewBaseActor(0)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	evicted := sender.AskFuture(ctx, b, "first")
+	sender.AskFuture(ctx, b, "second")
+	sender.AskFuture(ctx, b, "third")
+	if _, err := evicted.Result(ctx); !errors.Is(err, ErrMailboxFull) {
+		t.Fatalf("evicted ask = %v, want ErrMailboxFull", err)
+	}
+}
+
+func TestReturnErrorRejectsWhenFull(t *testing.T) {
+	a := NewBaseActor(4, WithMailboxPolicy(ReturnError))
+	n := fill(t, a)
+	if err := a.Send(n + 1); !errors.Is(err, ErrMailboxFull) {
+		t.Fatalf("send to full ReturnError mailbox = %v, want ErrMailboxFull", err)
+	}
+	stats := a.MailboxStats()
+	if stats.Rejected != 1 || stats.Dropped != 0 || stats.Depth != n {
+		t.Fatalf("stats after rejection = %+v", stats)
+	}
+	if v, _ := a.mailbox.Dequeue(); v != 1 {
+		t.Fatalf("head after rejection = %v, want 1", v)
+	}
+}
+
+func TestSendRoutesByPriority(t *testing.T) {
+	a := NewBaseActor(4, WithMailboxPolicy(ReturnError))
+	if err := a.Send(&Envelope{Message: 1, Priority: PriorityHigh}); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Send(&Envelope{Message: 2, Priority: PriorityNormal}); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Send(3); err != nil {
+		t.Fatal(err)
+	}
+	if stats := a.MailboxStats(); stats.UrgentDepth != 1 || stats.Depth != 2 {
+		t.Fatalf("urgent/normal depth = %d/%d, want 1/2", stats.UrgentDepth, stats.Depth)
+	}
+
+	// 普通邮箱满时加急通道仍可写入
+	for a.MailboxStats().Depth < a.MailboxStats().Capacity {
+		if err := a.Send(0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := a.Send(&Envelope{Message: 4, Priority: PriorityCritical}); err != nil {
+		t.Fatalf("urgent send with full mailbox = %v", err)
+	}
+}
+
+func TestParseMailboxPolicy(t *testing.T) {
+	for _, p := range []MailboxPolicy{Block, DropNewest, DropOldest, ReturnError} {
+		got, err := ParseMailboxPolicy(p.String())
+		if err != nil || got != p {
+			t.Fatalf("ParseMailboxPolicy(%q) = %v, %v", p, got, err)
+		}
+	}
+	if p, err := ParseMailboxPolicy("DROP_OLDEST"); err != nil || p != DropOldest {
+		t.Fatalf("case-insensitive parse = %v, %v", p, err)
+	}
+	if _, err := ParseMailboxPolicy("spill"); err == nil {
+		t.Fatal("unknown policy accepted")
+	}
+}
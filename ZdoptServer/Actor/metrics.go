@@ -0,0 +1,122 @@
+package Actor
+
+//metrics.go
+import (
+	"expvar"
+	"sort"
+	"sync/atomic"
+	"time"
+	"zdopt/ZdoptServer/Metrics"
+)
+
+var (
+	actorCount        = expvar.NewInt("actors.count")              // 正在运行消息循环的 Actor 数
+	messageQueue      = expvar.NewInt("actors.messages")           // 最近一次采样时全部邮箱的待处理消息数
+	processedMessages = expvar.NewInt("actors.messages.processed") // 累计处理的消息数（含闭包任务）
+	messageRate       = expvar.NewFloat("actors.messages.rate")    // 最近一个采样周期的每秒处理消息数
+
+	// HandlerLatency 按消息类型统计的处理器耗时（Transport 字段为 "actor"），以 actors.latency 发布
+	HandlerLatency = Metrics.NewLatency()
+
+	lastSample atomic.Pointer[ActorMetrics]
+)
+
+func init() {
+	expvar.Publish("actors.latency", expvar.Func(func() interface{} {
+		return HandlerLatency.Summaries(true)
+	}))
+	expvar.Publish("actors.mailbox.deepest", expvar.Func(func() interface{} {
+		if m := lastSample.Load(); m != nil {
+			return m.Deepest
+		}
+		return nil
+	}))
+}
+
+// MailboxDepth 单个 Actor 的邮箱深度
+type MailboxDepth struct {
+	ID       int64   `json:"id"`
+	Depth    int     `json:"depth"`
+	Capacity int     `json:"capacity"`
+	Fill     float64 `json:"fill"` // Depth / Capacity
+}
+
+// ActorMetrics 一次采样的系统指标
+type ActorMetrics struct {
+	At             time.Time
+	Actors         int            // 已登记或在组中的 Actor 数
+	Running        int64          // 正在运行消息循环的 Actor 数（进程级）
+	Queued         int            // 全部邮箱（含加急通道）的待处理消息数
+	MaxFill        float64        // 最满邮箱的占用比例
+	Deepest        []MailboxDepth // 最深的若干邮箱，按深度降序
+	Processed      int64          // 累计处理的消息数（进程级）
+	MessagesPerSec float64        // 与上一次采样之间的处理速率
+}
+
+// deepestLimit 采样保留的最深邮箱数
+const deepestLimit = 10
+
+// Metrics 立即采样当前系统指标
+func (s *System) Metrics() ActorMetrics {
+	m := ActorMetrics{
+		At:        time.Now(),
+		Running:   actorCount.Value(),
+		Processed: processedMessages.Value(),
+	}
+	seen := make(map[*BaseActor]struct{})
+	visit := func(a Actor) {
+		base := baseOf(a)
+		if base == nil {
+			return
+		}
+		if _, ok := seen[base]; ok {
+			return
+		}
+		seen[base] = struct{}{}
+		depth := base.mailbox.Len() + base.urgent.Len()
+		capacity := base.mailbox.Cap() + base.urgent.Cap()
+		fill := float64(depth) / float64(capacity)
+		m.Queued += depth
+		m.MaxFill = max(m.MaxFill, fill)
+		if depth > 0 {
+			m.Deepest = append(m.Deepest, MailboxDepth{ID: base.id, Depth: depth, Capacity: capacity, Fill: fill})
+		}
+	}
+	s.actors.Range(func(_, v interface{}) bool {
+		visit(v.(Actor))
+		return true
+	})
+	s.FuncgroupLock.RLock()
+	for _, g := range s.groups {
+		for _, a := range g.Actors() {
+			visit(a)
+		}
+	}
+	s.FuncgroupLock.RUnlock()
+	m.Actors = len(seen)
+
+	sort.Slice(m.Deepest, func(i, j int) bool { return m.Deepest[i].Depth > m.Deepest[j].Depth })
+	if len(m.Deepest) > deepestLimit {
+		m.Deepest = m.Deepest[:deepestLimit]
+	}
+	return m
+}
+
+// sampleMetrics 采样并发布到 expvar，速率按与上一次采样的差值计算
+func (s *System) sampleMetrics(prev *ActorMetrics) ActorMetrics {
+	m := s.Metrics()
+	if prev != nil {
+		if elapsed := m.At.Sub(prev.At).Seconds(); elapsed > 0 {
+			m.MessagesPerSec = float64(m.Processed-prev.Processed) / elapsed
+		}
+	}
+	messageQueue.Set(int64(m.Queued))
+	messageRate.Set(m.MessagesPerSec)
+	lastSample.Store(&m)
+	return m
+}
+
+// observeHandler 记录一次处理器耗时
+func observeHandler(msgType string, start time.Time) {
+	HandlerLatency.Since("actor", msgType, start)
+}
@@ -0,0 +1,117 @@
+package Actor
+
+//middleware.go
+import (
+	"expvar"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+	"time"
+	"zdopt/ZdoptServer/Logs"
+)
+
+var middlewareRejected = expvar.NewMap("actors.middleware.rejected") // 按消息类型统计被拦截器拒绝的消息
+
+// MessageHandler 经拦截器链调用的消息处理函数，ctx 始终不为 nil
+type MessageHandler func(ctx *MessageContext, msg interface{})
+
+// Middleware 消息处理拦截器，包裹下一层处理函数；不调用 next 即拦截该消息
+type Middleware func(next MessageHandler) MessageHandler
+
+// middlewareChain 系统级拦截器列表，接入系统的Actor共享同一实例，注册后立即对全部Actor生效
+type middlewareChain struct {
+	mu  sync.Mutex
+	mws atomic.Pointer[[]Middleware]
+}
+
+func (c *middlewareChain) add(mw ...Middleware) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var next []Middleware
+	if old := c.mws.Load(); old != nil {
+		next = append(next, *old...)
+	}
+	next = append(next, mw...)
+	c.mws.Store(&next)
+}
+
+func (c *middlewareChain) load() []Middleware {
+	if c == nil {
+		return nil
+	}
+	if mws := c.mws.Load(); mws != nil {
+		return *mws
+	}
+	return nil
+}
+
+// Use 注册系统级拦截器，按注册顺序由外到内包裹每一次处理器调用（日志、指标、panic 恢复、权限检查等）
+func (s *System) Use(mw ...Middleware) {
+	s.middleware.add(mw...)
+}
+
+// attach 将Actor接入系统：死信、拦截器与事件总线
+func (a *BaseActor) attach(s *System) {
+	a.attachDeadLetters(s.deadLetters)
+	a.middleware.CompareAndSwap(nil, s.middleware)
+	a.bus.CompareAndSwap(nil, s.bus)
+}
+
+// wrapHandler 按拦截器链包裹处理函数
+func wrapHandler(fn MessageHandler, mws []Middleware) MessageHandler {
+	for i := len(mws) - 1; i >= 0; i-- {
+		fn = mws[i](fn)
+	}
+	return fn
+}
+
+// RecoverMiddleware 在拦截器层恢复处理器 panic：记录日志并以错误回复 Ask 请求。
+// 恢复后的 panic 不再上报监督者，需要监督重启的Actor不应使用
+func RecoverMiddleware() Middleware {
+	return func(next MessageHandler) MessageHandler {
+		return func(ctx *MessageContext, msg interface{}) {
+			defer func() {
+				if r := recover(); r != nil {
+					actorPanics.Add(1)
+					ctx.Logf("handler panic: %v\n%s", r, debug.Stack())
+					ctx.Fail(fmt.Errorf("handler panic: %v", r))
+				}
+			}()
+			next(ctx, msg)
+		}
+	}
+}
+
+// AuthMiddleware 调用处理器前检查权限，check 返回错误时拦截：Ask 请求以该错误完成，并计入拒绝指标
+func AuthMiddleware(check func(ctx *MessageContext, msg interface{}) error) Middleware {
+	return func(next MessageHandler) MessageHandler {
+		return func(ctx *MessageContext, msg interface{}) {
+			if err := check(ctx, msg); err != nil {
+				middlewareRejected.Add(ctx.MessageType(), 1)
+				ctx.Fail(err)
+				return
+			}
+			next(ctx, msg)
+		}
+	}
+}
+
+// LoggingMiddleware 以调试级键值日志记录每次处理（Actor、消息类型、发送者与耗时），调试级关闭时不做任何格式化
+func LoggingMiddleware(logger *Logs.ZLogger) Middleware {
+	return func(next MessageHandler) MessageHandler {
+		return func(ctx *MessageContext, msg interface{}) {
+			if !logger.Enabled(Logs.Debug) {
+				next(ctx, msg)
+				return
+			}
+			start := time.Now()
+			next(ctx, msg)
+			logger.Debugw("message handled",
+				Logs.Int64("actor", ctx.Self.ID()),
+				Logs.Str("type", ctx.MessageType()),
+				Logs.Int64("sender", ctx.Sender),
+				Logs.Dur("took", time.Since(start)))
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package Actor
+
+import (
+	"context"
+	"io"
+	"testing"
+	"zdopt/ZdoptServer/Logs"
+)
+
+// BenchmarkLoggingMiddleware 处理链上的日志拦截器：调试级关闭时只有一次级别检查，不应产生分配
+func BenchmarkLoggingMiddleware(b *testing.B) {
+	for _, level := range []Logs.Level{Logs.Info, Logs.Debug} {
+		b.Run(level.String(), func(b *testing.B) {
+			logger, err := Logs.NewZLogger("", level)
+			if err != nil {
+				b.Fatal(err)
+			}
+			logger.Logger.SetOutput(io.Discard)
+			handled := 0
+			h := LoggingMiddleware(logger)(func(*MessageContext, interface{}) { handled++ })
+			ctx := &MessageContext{Context: context.Background(), Self: NewBaseActor(16), Sender: 7, msgType: "Ping"}
+			var msg interface{} = &ctx
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				h(ctx, msg)
+			}
+			if handled != b.N {
+				b.Fatalf("handled %d of %d", handled, b.N)
+			}
+		})
+	}
+}
@@ -0,0 +1,317 @@
+package Actor
+
+//mirror.go
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+	"zdopt/ZdoptServer/Clock"
+)
+
+var (
+	ErrMirrorStale   = errors.New("mirror is staler than requested bound")
+	ErrMirrorStopped = errors.New("mirror stopped")
+)
+
+// MirrorEntry 镜像中的一条记录
+type MirrorEntry struct {
+	Key       string
+	Score     float64
+	Value     interface{}
+	UpdatedAt time.Time // 权威 Actor 产生变更的时间
+}
+
+// MirrorUpdate 权威 Actor 发出的变更事件（新增或覆盖）
+type MirrorUpdate struct {
+	Entry MirrorEntry
+	seq   uint64 // 入队序号，由镜像分配
+}
+
+// MirrorDelete 权威 Actor 发出的删除事件
+type MirrorDelete struct {
+	Key string
+	At  time.Time
+	seq uint64
+}
+
+// Mirror 只读镜像 Actor：消费变更事件，维护按分数排序的查询副本
+// 排行榜、房间列表等聚合查询读镜像，不再打扰权威 Actor
+type Mirror struct {
+	*BaseActor
+	mu      sync.RWMutex
+	entries map[string]*MirrorEntry
+	sorted  []*MirrorEntry // 按 Score 降序，Score 相同按 Key 升序
+	deleted map[string]mirrorTombstone
+
+	// 未应用事件按入队顺序排队，队首即最早未应用的事件；批处理并发执行，应用顺序与入队顺序无关
+	pmu     sync.Mutex
+	nextSeq uint64
+	queue   []mirrorStamp
+	head    int
+	settled map[uint64]struct{} // 已应用但前面仍有未应用事件的序号
+}
+
+// mirrorStamp 一个未应用事件的入队序号与单调时间（不受校时影响）
+type mirrorStamp struct {
+	seq uint64
+	at  time.Duration
+}
+
+// mirrorTombstone 删除记录：拒绝变更时间更早、但因并发应用而晚到的更新；
+// 入队早于该删除的事件全部应用后即可清除
+type mirrorTombstone struct {
+	at  time.Time
+	seq uint64
+}
+
+// NewMirror 创建镜像 Actor，使用独立消息循环，不占用权威 Actor 的调度
+func NewMirror() *Mirror {
+	m := &Mirror{
+		BaseActor: NewBaseActor(1024),
+		entries:   make(map[string]*MirrorEntry),
+		deleted:   make(map[string]mirrorTombstone),
+		settled:   make(map[uint64]struct{}),
+	}
+	RegisterHandler(m.BaseActor, m.applyUpdate)
+	RegisterHandler(m.BaseActor, m.applyDelete)
+	return m
+}
+
+func (m *Mirror) Start()                     {}
+func (m *Mirror) Update(delta time.Duration) {}
+
+// Stop 停止镜像消息循环
+func (m *Mirror) Stop() {
+	if m.cancel != nil {
+		m.cancel()
+	}
+	m.wg.Wait()
+}
+
+// Receive 直接投递变更事件（*MirrorUpdate 或 *MirrorDelete），其他类型没有处理器，直接忽略，
+// 不占用陈旧度队列（否则永远不会被标记为已应用，Staleness 只增不减）
+func (m *Mirror) Receive(msg interface{}) {
+	switch msg.(type) {
+	case *MirrorUpdate, *MirrorDelete:
+		_ = m.enqueue(msg)
+	}
+}
+
+// Publish 发布新增/覆盖事件，镜像已停止时返回 ErrMirrorStopped
+func (m *Mirror) Publish(key string, score float64, value interface{}) error {
+	return m.enqueue(&MirrorUpdate{Entry: MirrorEntry{Key: key, Score: score, Value: value, UpdatedAt: time.Now()}})
+}
+
+// Remove 发布删除事件，镜像已停止时返回 ErrMirrorStopped
+func (m *Mirror) Remove(key string) error {
+	return m.enqueue(&MirrorDelete{Key: key, At: time.Now()})
+}
+
+// enqueue 为变更事件分配序号并入队，msg 须为 *MirrorUpdate 或 *MirrorDelete
+func (m *Mirror) enqueue(msg interface{}) error {
+	m.pmu.Lock()
+	m.nextSeq++
+	seq := m.nextSeq
+	m.queue = append(m.queue, mirrorStamp{seq: seq, at: Clock.Mono()})
+	m.pmu.Unlock()
+	switch e := msg.(type) {
+	case *MirrorUpdate:
+		e.seq = seq
+	case *MirrorDelete:
+		e.seq = seq
+	}
+	if !m.mailbox.EnqueueWait(m.done(), msg) {
+		m.applied(seq)
+		return ErrMirrorStopped
+	}
+	return nil
+}
+
+// applied 标记事件已应用（或未能入队），推进队首；队首前进后清除已无用的删除记录
+func (m *Mirror) applied(seq uint64) {
+	m.pmu.Lock()
+	m.settled[seq] = struct{}{}
+	advanced := false
+	for m.head < len(m.queue) {
+		front := m.queue[m.head].seq
+		if _, ok := m.settled[front]; !ok {
+			break
+		}
+		delete(m.settled, front)
+		m.head++
+		advanced = true
+	}
+	if m.head == len(m.queue) {
+		m.queue, m.head = m.queue[:0], 0
+	} else if m.head > len(m.queue)/2 {
+		m.queue, m.head = append(m.queue[:0], m.queue[m.head:]...), 0
+	}
+	low := m.nextSeq + 1 // 最早未应用事件的序号
+	if m.head < len(m.queue) {
+		low = m.queue[m.head].seq
+	}
+	m.pmu.Unlock()
+	if !advanced {
+		return
+	}
+
+	m.mu.Lock()
+	for key, t := range m.deleted {
+		if t.seq < low {
+			delete(m.deleted, key)
+		}
+	}
+	m.mu.Unlock()
+}
+
+// applyUpdate 批处理为并发执行，按变更时间丢弃过期事件保证最终一致
+func (m *Mirror) applyUpdate(u *MirrorUpdate) {
+	defer m.applied(u.seq)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e := u.Entry
+	if t, ok := m.deleted[e.Key]; ok {
+		if !e.UpdatedAt.After(t.at) {
+			return
+		}
+		delete(m.deleted, e.Key)
+	}
+	if old, ok := m.entries[e.Key]; ok {
+		if old.UpdatedAt.After(e.UpdatedAt) {
+			return
+		}
+		m.removeSorted(old)
+	}
+	entry := &e
+	m.entries[e.Key] = entry
+	m.insertSorted(entry)
+}
+
+func (m *Mirror) applyDelete(d *MirrorDelete) {
+	defer m.applied(d.seq)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if old, ok := m.entries[d.Key]; ok {
+		if old.UpdatedAt.After(d.At) {
+			return
+		}
+		m.removeSorted(old)
+		delete(m.entries, d.Key)
+	}
+	t, ok := m.deleted[d.Key]
+	if !ok || d.At.After(t.at) {
+		t.at = d.At
+	}
+	t.seq = max(t.seq, d.seq)
+	m.deleted[d.Key] = t
+}
+
+func mirrorLess(a, b *MirrorEntry) bool {
+	if a.Score != b.Score {
+		return a.Score > b.Score
+	}
+	return a.Key < b.Key
+}
+
+func (m *Mirror) insertSorted(e *MirrorEntry) {
+	i := sort.Search(len(m.sorted), func(i int) bool { return !mirrorLess(m.sorted[i], e) })
+	m.sorted = append(m.sorted, nil)
+	copy(m.sorted[i+1:], m.sorted[i:])
+	m.sorted[i] = e
+}
+
+func (m *Mirror) removeSorted(e *MirrorEntry) {
+	i := sort.Search(len(m.sorted), func(i int) bool { return !mirrorLess(m.sorted[i], e) })
+	if i < len(m.sorted) && m.sorted[i] == e {
+		m.sorted = append(m.sorted[:i], m.sorted[i+1:]...)
+	}
+}
+
+// Staleness 镜像落后权威数据的时长上界：最早未应用事件入队至今的时长（无待应用事件时为 0）
+func (m *Mirror) Staleness() time.Duration {
+	m.pmu.Lock()
+	defer m.pmu.Unlock()
+	if m.head == len(m.queue) {
+		return 0
+	}
+	return Clock.Mono() - m.queue[m.head].at
+}
+
+// Fresh 检查镜像是否满足调用方的陈旧度要求
+func (m *Mirror) Fresh(bound time.Duration) error {
+	if m.Staleness() > bound {
+		return ErrMirrorStale
+	}
+	return nil
+}
+
+// Len 记录数
+func (m *Mirror) Len() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.sorted)
+}
+
+// Get 按键查询
+func (m *Mirror) Get(key string) (MirrorEntry, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if e, ok := m.entries[key]; ok {
+		return *e, true
+	}
+	return MirrorEntry{}, false
+}
+
+// Rank 按键查询排名（从 0 开始）
+func (m *Mirror) Rank(key string) (int, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	e, ok := m.entries[key]
+	if !ok {
+		return 0, false
+	}
+	return sort.Search(len(m.sorted), func(i int) bool { return !mirrorLess(m.sorted[i], e) }), true
+}
+
+// Range 分页查询排序结果
+func (m *Mirror) Range(offset, limit int) []MirrorEntry {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if offset < 0 {
+		offset = 0
+	}
+	if offset >= len(m.sorted) || limit <= 0 {
+		return nil
+	}
+	end := offset + limit
+	if end > len(m.sorted) {
+		end = len(m.sorted)
+	}
+	out := make([]MirrorEntry, 0, end-offset)
+	for _, e := range m.sorted[offset:end] {
+		out = append(out, *e)
+	}
+	return out
+}
+
+// Top 前 n 名
+func (m *Mirror) Top(n int) []MirrorEntry {
+	return m.Range(0, n)
+}
+
+// TopWithin 在陈旧度约束内查询前 n 名
+func (m *Mirror) TopWithin(n int, bound time.Duration) ([]MirrorEntry, error) {
+	if err := m.Fresh(bound); err != nil {
+		return nil, err
+	}
+	return m.Top(n), nil
+}
+
+// 编译期检查 Mirror 满足 Actor 接口
+var _ Actor = (*Mirror)(nil)
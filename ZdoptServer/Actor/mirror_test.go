@@ -0,0 +1,78 @@
+package Actor
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestMirrorStalenessBoundedUnderSteadyTraffic(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	m := NewMirror()
+	m.Init(ctx)
+	defer m.Stop()
+
+	stop := time.After(300 * time.Millisecond)
+	worst := time.Duration(0)
+	for i := 0; ; i++ {
+		select {
+		case <-stop:
+			if worst > 100*time.Millisecond {
+				t.Fatalf("staleness reached %v under steady traffic", worst)
+			}
+			return
+		default:
+		}
+		if err := m.Publish("k"+strconv.Itoa(i%64), float64(i), nil); err != nil {
+			t.Fatal(err)
+		}
+		worst = max(worst, m.Staleness())
+	}
+}
+
+func TestMirrorPrunesTombstones(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	m := NewMirror()
+	m.Init(ctx)
+	defer m.Stop()
+
+	for i := 0; i < 100; i++ {
+		key := "k" + strconv.Itoa(i)
+		m.Publish(key, float64(i), nil)
+		m.Remove(key)
+	}
+	tombstones := func() int {
+		m.mu.RLock()
+		defer m.mu.RUnlock()
+		return len(m.deleted)
+	}
+	// 队首推进与清除之间有短暂间隔，轮询到清除完成
+	deadline := time.Now().Add(time.Second)
+	for (m.Staleness() > 0 || tombstones() > 0) && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if tombstones() != 0 || m.Len() != 0 {
+		t.Fatalf("tombstones = %d, entries = %d after all events applied", tombstones(), m.Len())
+	}
+}
+
+func TestMirrorIgnoresForeignMessages(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	m := NewMirror()
+	m.Init(ctx)
+	defer m.Stop()
+
+	m.Receive("not an event")
+	m.Receive(&MirrorUpdate{Entry: MirrorEntry{Key: "a", Score: 1, UpdatedAt: time.Now()}})
+	deadline := time.Now().Add(time.Second)
+	for (m.Staleness() > 0 || m.Len() == 0) && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if s := m.Staleness(); s != 0 || m.Len() != 1 {
+		t.Fatalf("staleness = %v, entries = %d after the only event was applied", s, m.Len())
+	}
+}
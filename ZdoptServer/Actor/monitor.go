@@ -2,136 +2,128 @@ package Actor
 
 //monitor.go
 import (
-	"expvar"
-	"log"
+	"context"
 	"runtime"
-	"sync"
+	"runtime/debug"
 	"time"
 )
 
-var (
-	actorCount   = expvar.NewInt("actors.count")
-	messageQueue = expvar.NewInt("actors.messages")
-	//goroutineCount = expvar.NewInt("goroutines.count")
-	workerMutex    sync.Mutex
-	currentWorkers int
-)
-
-func startMonitor() {
-	go func() {
-		for range time.Tick(5 * time.Second) {
-			//更新性能指标updateMetres()
-			updateMetrice()
-			//动态调整资源adjustResources()
-			adjustResources()
-		}
-	}()
+// MonitorConfig 系统监控与资源调整参数
+type MonitorConfig struct {
+	Interval    time.Duration // 采样周期
+	HighBacklog int           // 全部邮箱待处理消息数超过该值视为高负载
+	LowBacklog  int           // 低于该值时解除高负载
+	HighFill    float64       // 任一邮箱占用比例超过该值也视为高负载
+	IdleMemory  uint64        // 堆中空闲未归还的内存超过该值时归还给操作系统；0 表示不主动归还
 }
 
-func updateMetrice() {
-	//获取运行时状态
-	numGoRoutines := runtime.NumGoroutine()
-	memStats := runtime.MemStats{}
-	runtime.ReadMemStats(&memStats)
-
-	//设置监控指标
-	actorCount.Set(int64(numGoRoutines))
-	messageQueue.Set(int64(memStats.Mallocs - memStats.Frees))
+// DefaultMonitorConfig 默认参数：5 秒采样，积压 1000/200 条进入/解除高负载，邮箱 80% 满；
+// 默认不主动归还空闲堆，FreeOSMemory 会触发一次完整的 STW GC，需显式设置 IdleMemory 开启
+func DefaultMonitorConfig() MonitorConfig {
+	return MonitorConfig{
+		Interval:    5 * time.Second,
+		HighBacklog: 1000,
+		LowBacklog:  200,
+		HighFill:    0.8,
+	}
 }
 
-func adjustResources() {
-	// 获取当前的运行时状态
-	memStats := runtime.MemStats{}
-	runtime.ReadMemStats(&memStats)
-
-	// 获取当前消息队列的大小和Actor数量
-	numGoRoutines := runtime.NumGoroutine()
-	numActors := actorCount.Value()     //假设 actorCount 是存储当前 Actor 数量的expver变量
-	numMessages := messageQueue.Value() //假设 messageQueue 存储的是待处理的消息数量
-
-	// 设定阈值和动态调整的策略
-	const highLoadThreshold = 1000 // 高负载阈值，消息队列大于该值时触发扩容
-	const lowLoadThreshold = 200   // 低负载阈值，消息队列小于该值时触发收缩
-	const maxWorkers = 100         // 最大工作线程数
-	const minWorkers = 5           // 最小工作线程数
-
-	// 计算当前负载
-	loadFacter := float64(numMessages) / float64(numGoRoutines)
+func (c MonitorConfig) withDefaults() MonitorConfig {
+	def := DefaultMonitorConfig()
+	if c.Interval <= 0 {
+		c.Interval = def.Interval
+	}
+	if c.HighBacklog <= 0 {
+		c.HighBacklog = def.HighBacklog
+	}
+	if c.LowBacklog <= 0 || c.LowBacklog > c.HighBacklog {
+		c.LowBacklog = min(def.LowBacklog, c.HighBacklog)
+	}
+	if c.HighFill <= 0 {
+		c.HighFill = def.HighFill
+	}
+	return c
+}
 
-	// 调整工作线程数量
-	if loadFacter > 5.0 && numActors < maxWorkers { //如果负载数量很高且线程数未达到最大值
-		// 增加工作线程
-		newWorkers := numGoRoutines + 2
-		if newWorkers > maxWorkers {
-			newWorkers = maxWorkers
+// monitor 定期采样系统指标，并据此调整资源：高负载且积压仍在增长时为系统创建的负载均衡器扩容，
+// 开启 IdleMemory 时在堆中空闲内存过多时归还给操作系统
+func (s *System) monitor(ctx context.Context, cfg MonitorConfig) {
+	ticker := time.NewTicker(cfg.Interval)
+	defer ticker.Stop()
+	var (
+		prev     *ActorMetrics
+		highLoad bool
+	)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
 		}
-		// 触发动态扩容
-		for i := numGoRoutines; i <= newWorkers; i++ {
-			go startMonitor() // 启动新的工作线程
+		m := s.sampleMetrics(prev)
+		SampleNetMetrics()
+
+		overloaded := m.Queued > cfg.HighBacklog || m.MaxFill > cfg.HighFill
+		switch {
+		case !highLoad && overloaded:
+			highLoad = true
+			defaultLogger.Printf("high load: %d queued messages, fullest mailbox %.0f%%, %.0f msg/s",
+				m.Queued, m.MaxFill*100, m.MessagesPerSec)
+		case highLoad && m.Queued < cfg.LowBacklog && m.MaxFill <= cfg.HighFill:
+			highLoad = false
+			defaultLogger.Printf("load back to normal: %d queued messages, %.0f msg/s", m.Queued, m.MessagesPerSec)
 		}
-		log.Printf("Increasing worker: %d -> %d", numGoRoutines, newWorkers)
-	} else if loadFacter < 1.0 && numActors > minWorkers { // 如果负载很低且线程数大于最小值
-		// 减少工作线程
-		newWorkers := numGoRoutines - 1
-		if newWorkers < minWorkers {
-			newWorkers = minWorkers
+		if highLoad && prev != nil && m.Queued > prev.Queued {
+			// 积压仍在增长，扩容负载均衡器；空闲后由其自身的空闲回收收缩
+			for _, b := range s.trackedBalancers() {
+				b.expandWorkers()
+			}
 		}
-		// 触发动态收缩，关闭不再需要的工作线程
-		stopWorkers(numGoRoutines - newWorkers)
-		log.Printf("Increasing worker: %d -> %d", numGoRoutines, newWorkers)
-	}
-
-	// 调整内存使用情况
-	if memStats.Mallocs-memStats.Frees > 1024*1024*100 { // 如果分配的内存大于 100MB
-		// 假设需要进行资源释放（收回未使用的对象池资源）
-		cleanupMemory()
-		log.Println("Memory cleanup triggered")
-	}
 
-	// 根据消息处理量调整资源
-	if numMessages > highLoadThreshold {
-		// 如果消息数量过高，可以触发额外资源的扩展
-		log.Println("High load detected, expanding resources...")
-		// 此处可以扩展额外的网络带宽、数据库连接池等
+		if cfg.IdleMemory > 0 {
+			var mem runtime.MemStats
+			runtime.ReadMemStats(&mem)
+			if idle := mem.HeapIdle - mem.HeapReleased; idle > cfg.IdleMemory {
+				debug.FreeOSMemory()
+				defaultLogger.Printf("released %d MB idle heap to the OS", idle>>20)
+			}
+		}
+		prev = &m
 	}
 }
 
-// 停止不再需要的工作线程
-func stopWorkers(count int) {
-	workerMutex.Lock()
-	defer workerMutex.Unlock()
-
-	for i := 0; i < count; i++ {
-		// 优雅关闭线程的实现
-		log.Printf("Stopping worker %d", currentWorkers)
-		currentWorkers--
-		// 可以通过控制某种信号来中断工作线程的执行（使用 context、channel 或其他方式来通知工作线程退出）
+// trackedBalancers 系统创建且未关闭的负载均衡器
+func (s *System) trackedBalancers() []*Balancer {
+	s.balancersMu.Lock()
+	defer s.balancersMu.Unlock()
+	live := s.balancers[:0]
+	for _, b := range s.balancers {
+		if !b.closed.Load() {
+			live = append(live, b)
+		}
 	}
-	log.Printf("Stopped workers, current count: %d", currentWorkers)
+	clear(s.balancers[len(live):])
+	s.balancers = live
+	return append([]*Balancer(nil), live...)
 }
 
-/*// 启动一个新的工作线程
-func startWorker() {
-	workerMutex.Lock()
-	defer workerMutex.Unlock()
-	currentWorkers++
-
-	go func() {
-		// 模拟工作线程的处理逻辑
-		for {
-			select {
-			case <-time.After(10 * time.Second): // 每10秒进行一次任务
-				// 执行实际任务
-				log.Println("Worker executing task")
+// monitorDeadLetters 定期输出新增死信计数，便于发现路由错误
+func monitorDeadLetters(ctx context.Context, d *DeadLetters, every time.Duration) {
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+	last := map[DeadLetterReason]int64{}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		counts := d.Counts()
+		for reason, n := range counts {
+			if delta := n - last[reason]; delta > 0 {
+				defaultLogger.Printf("dead letters: %d %s in last %v (total %d)", delta, reason, every, n)
 			}
 		}
-	}()
-	log.Printf("Started new worker, current count: %d", currentWorkers)
-}*/
-
-// 清理内存，释放未使用的资源
-func cleanupMemory() {
-	// 释放未使用的内存或清理对象池等
-	// 清理消息池或其他资源
-	messagePool.New = nil
+		last = counts
+	}
 }
@@ -0,0 +1,163 @@
+package Actor
+
+//names.go
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+var (
+	ErrNameTaken    = errors.New("actor name already registered")
+	ErrNameNotFound = errors.New("actor name not found")
+	ErrInvalidName  = errors.New("invalid actor name")
+)
+
+// nameRegistry 按角色名登记Actor（matchmaker、leaderboard 等），别名在查找时解析到目标名称当前的Actor
+type nameRegistry struct {
+	mu      sync.RWMutex
+	names   map[string]Actor
+	aliases map[string]string // 别名 -> 名称
+}
+
+func newNameRegistry() *nameRegistry {
+	return &nameRegistry{names: make(map[string]Actor), aliases: make(map[string]string)}
+}
+
+// holdsName 名称当前的持有者是否仍在运行（消息循环已停止的Actor视为已释放名称）
+func holdsName(a Actor) bool {
+	base := baseOf(a)
+	return base == nil || !base.stopped()
+}
+
+// RegisterName 按名称登记Actor，服务之间按角色查找而不必经构造函数传递指针。
+// 同一Actor重复登记无副作用；名称已被其他运行中的Actor持有时返回 ErrNameTaken，原持有者已停止时由新Actor接替
+func (s *System) RegisterName(name string, a Actor) error {
+	if name == "" || a == nil {
+		return fmt.Errorf("%w: %q", ErrInvalidName, name)
+	}
+	r := s.names
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.aliases[name]; ok {
+		return fmt.Errorf("%w: %q is an alias", ErrNameTaken, name)
+	}
+	if old, ok := r.names[name]; ok && old != a && holdsName(old) {
+		return fmt.Errorf("%w: %q", ErrNameTaken, name)
+	}
+	r.names[name] = a
+	return nil
+}
+
+// ReplaceName 无条件把名称转给 a（主备切换等），返回原持有者
+func (s *System) ReplaceName(name string, a Actor) (Actor, error) {
+	if name == "" || a == nil {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidName, name)
+	}
+	r := s.names
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.aliases[name]; ok {
+		return nil, fmt.Errorf("%w: %q is an alias", ErrNameTaken, name)
+	}
+	old := r.names[name]
+	r.names[name] = a
+	return old, nil
+}
+
+// UnregisterName 注销名称，仅当当前持有者是 a 时生效（a 为 nil 时无条件注销），返回是否注销
+func (s *System) UnregisterName(name string, a Actor) bool {
+	r := s.names
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	old, ok := r.names[name]
+	if !ok || (a != nil && old != a) {
+		return false
+	}
+	delete(r.names, name)
+	return true
+}
+
+// Alias 为名称添加别名（如 "mm" -> "matchmaker"），目标名称被重新登记后别名随之指向新Actor
+func (s *System) Alias(alias, name string) error {
+	if alias == "" || name == "" || alias == name {
+		return fmt.Errorf("%w: alias %q -> %q", ErrInvalidName, alias, name)
+	}
+	r := s.names
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.names[alias]; ok {
+		return fmt.Errorf("%w: %q", ErrNameTaken, alias)
+	}
+	if target, ok := r.aliases[name]; ok {
+		name = target // 别名的别名直接指向最终名称
+	}
+	if target, ok := r.aliases[alias]; ok && target != name {
+		return fmt.Errorf("%w: alias %q -> %q", ErrNameTaken, alias, target)
+	}
+	r.aliases[alias] = name
+	return nil
+}
+
+// Unalias 移除别名
+func (s *System) Unalias(alias string) bool {
+	r := s.names
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, ok := r.aliases[alias]
+	delete(r.aliases, alias)
+	return ok
+}
+
+// LookupName 按名称或别名查找运行中的Actor
+func (s *System) LookupName(name string) (Actor, bool) {
+	r := s.names
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if target, ok := r.aliases[name]; ok {
+		name = target
+	}
+	a, ok := r.names[name]
+	if !ok || !holdsName(a) {
+		return nil, false
+	}
+	return a, true
+}
+
+// SendName 按名称或别名投递消息，语义与 Send 相同
+func (s *System) SendName(name string, msg interface{}) error {
+	return s.SendNameFrom(name, Origin{}, msg)
+}
+
+// SendNameFrom 代远端发送方按名称投递消息，信封携带 from 供目标的重放保护去重
+func (s *System) SendNameFrom(name string, from Origin, msg interface{}) error {
+	if s.stopping.Load() {
+		return ErrSystemStopping
+	}
+	a, ok := s.LookupName(name)
+	if !ok {
+		s.deadLetters.publish(DeadLetter{Message: msg, Reason: ActorNotFound})
+		return fmt.Errorf("%w: %q", ErrNameNotFound, name)
+	}
+	if base := baseOf(a); base != nil {
+		env := &Envelope{Message: msg, Priority: base.Priority()}
+		from.stamp(env)
+		return base.Send(env)
+	}
+	a.Receive(msg)
+	return nil
+}
+
+// Names 已登记的名称（不含别名，排序）
+func (s *System) Names() []string {
+	r := s.names
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.names))
+	for name := range r.names {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
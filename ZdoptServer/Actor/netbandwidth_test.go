@@ -0,0 +1,59 @@
+package Actor
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+	"zdopt/ZdoptServer/Net"
+	"zdopt/ZdoptServer/Pb"
+)
+
+func TestSessionBandwidthFedByClientAcks(t *testing.T) {
+	codec := newTestCodec(t)
+	k := NewKCPListener(0, context.Background(),
+		WithCodec(codec), WithBandwidth(Net.BandwidthConfig{Window: 50 * time.Millisecond}),
+		WithHandshake(TokenHandshake(func(string) (Identity, error) { return Identity{}, nil }), time.Second))
+	if err := k.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer k.Stop()
+	port := k.Addr().(*net.UDPAddr).Port
+
+	cfg := Net.DefaultClientConfig()
+	cfg.Codec, cfg.Hello = codec, &Pb.ClientHello{}
+	cfg.Heartbeat.MinInterval, cfg.Heartbeat.MaxInterval = 20*time.Millisecond, 40*time.Millisecond // 确认帧随心跳发出
+	c, err := Net.Dial("127.0.0.1:"+strconv.Itoa(port), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	Net.Handle(c, func(*Pb.DataPacket) {})
+
+	var s *Session
+	for deadline := time.Now().Add(time.Second); s == nil && time.Now().Before(deadline); time.Sleep(5 * time.Millisecond) {
+		k.byID.Range(func(_, v interface{}) bool {
+			s = v.(*Session)
+			return false
+		})
+	}
+	if s == nil || s.Bandwidth() == nil {
+		t.Fatal("session has no bandwidth estimator")
+	}
+
+	payload := strings.Repeat("x", 512)
+	deadline := time.Now().Add(3 * time.Second)
+	for s.Bandwidth().Stats().Delivery == 0 && time.Now().Before(deadline) {
+		for i := 0; i < 8; i++ {
+			if err := s.Send(&Pb.DataPacket{Content: payload}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if s.Bandwidth().Stats().Delivery == 0 {
+		t.Fatal("no delivery rate sample from client acks")
+	}
+}
@@ -0,0 +1,106 @@
+package Actor
+
+//netbroadcast.go
+import (
+	"expvar"
+	"zdopt/ZdoptServer/Net"
+)
+
+var broadcastStats = expvar.NewMap("net.broadcast") // messages / recipients / dropped
+
+// sharedFrame 一次编码、多个会话共享的帧；压缩版本在首个协商了压缩的会话需要时生成一次，
+// 字典压缩的版本只与该消息类别的字典有关，协商了该字典的会话共享
+type sharedFrame struct {
+	f          Net.Frame
+	plain      []byte
+	compressed []byte
+	dict       []byte
+}
+
+func (sf *sharedFrame) bytes(s *Session) []byte {
+	if !s.compressing() {
+		return sf.plain
+	}
+	c := s.listener.opts.compression
+	if dicts := c.Dictionaries(); dicts != nil {
+		if dict, ok := dicts.ForMessage(sf.f.ID); ok && s.Dictionaries().Has(dict.ID) {
+			if sf.dict == nil {
+				sf.dict = c.AppendFrameWith(nil, sf.f.ID, sf.f.Payload, s.Dictionaries())
+			}
+			return sf.dict
+		}
+	}
+	if sf.compressed == nil {
+		sf.compressed = s.listener.opts.compression.AppendFrame(nil, sf.f.ID, sf.f.Payload)
+	}
+	return sf.compressed
+}
+
+// Broadcast 向全部已认证的玩家会话（不含观察者）发送 msg：只编码一次，各会话共享同一帧缓冲入队。
+// 返回成功入队的会话数，发送队列已满或已关闭的会话计入 dropped 后跳过
+func (k *KCPListener) Broadcast(msg interface{}) (int, error) {
+	frame, err := k.encodeFrame(msg)
+	if err != nil {
+		return 0, err
+	}
+	sent, dropped := 0, 0
+	k.byID.Range(func(_, v interface{}) bool {
+		s := v.(*Session)
+		if !s.Authenticated() || s.Observer() != nil {
+			return true
+		}
+		if s.enqueue(frame.bytes(s)) == nil {
+			sent++
+		} else {
+			dropped++
+		}
+		return true
+	})
+	k.recordBroadcast(sent, dropped)
+	return sent, nil
+}
+
+// Multicast 向指定会话发送 msg（只编码一次），不存在、未认证或观察者会话忽略，返回成功入队的会话数
+func (k *KCPListener) Multicast(sessionIDs []uint64, msg interface{}) (int, error) {
+	frame, err := k.encodeFrame(msg)
+	if err != nil {
+		return 0, err
+	}
+	sent, dropped := 0, 0
+	for _, id := range sessionIDs {
+		s, ok := k.SessionByID(id)
+		if !ok || !s.Authenticated() || s.Observer() != nil {
+			continue
+		}
+		if s.enqueue(frame.bytes(s)) == nil {
+			sent++
+		} else {
+			dropped++
+		}
+	}
+	k.recordBroadcast(sent, dropped)
+	return sent, nil
+}
+
+// encodeFrame 经编解码器编码为可共享的整帧
+func (k *KCPListener) encodeFrame(msg interface{}) (*sharedFrame, error) {
+	if k.opts.codec == nil {
+		return nil, ErrNoCodec
+	}
+	f, err := k.opts.codec.Encode(msg)
+	if err != nil {
+		return nil, err
+	}
+	return &sharedFrame{
+		f:     f,
+		plain: Net.AppendFrame(make([]byte, 0, Net.FrameHeaderSize+len(f.Payload)), f.ID, f.Payload),
+	}, nil
+}
+
+func (k *KCPListener) recordBroadcast(sent, dropped int) {
+	broadcastStats.Add("messages", 1)
+	broadcastStats.Add("recipients", int64(sent))
+	if dropped > 0 {
+		broadcastStats.Add("dropped", int64(dropped))
+	}
+}
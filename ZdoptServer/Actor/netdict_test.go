@@ -0,0 +1,107 @@
+package Actor
+
+import (
+	"context"
+	"expvar"
+	"fmt"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+	"zdopt/ZdoptServer/Net"
+	"zdopt/ZdoptServer/Pb"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// newTestDictionaries 以 DataPacket 样本训练的字典，DataPacket（ID 3）归入 data 类别
+func newTestDictionaries(t *testing.T) *Net.Compression {
+	t.Helper()
+	var samples [][]byte
+	for i := 0; i < 64; i++ {
+		b, _ := proto.Marshal(&Pb.DataPacket{Content: fmt.Sprintf(`{"op":"move","x":%d,"y":%d,"facing":"north"}`, i, i*3)})
+		samples = append(samples, b)
+	}
+	raw, err := Net.TrainDictionary(7, samples, 4<<10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dicts := Net.NewDictionaries()
+	if _, err := dicts.Add("data", raw); err != nil {
+		t.Fatal(err)
+	}
+	dicts.Bind("data", 3)
+	c, err := Net.NewCompression(0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.SetDictionaries(dicts)
+	return c
+}
+
+func dictFrames() int64 {
+	if v, ok := expvar.Get("net.compression").(*expvar.Map).Get("dict_frames").(*expvar.Int); ok {
+		return v.Value()
+	}
+	return 0
+}
+
+func TestDictionaryNegotiatedInHandshake(t *testing.T) {
+	codec := newTestCodec(t)
+	k := NewKCPListener(0, context.Background(),
+		WithCodec(codec), WithCompression(newTestDictionaries(t)),
+		WithHandshake(TokenHandshake(func(string) (Identity, error) { return Identity{}, nil }), time.Second))
+	if err := k.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer k.Stop()
+	port := k.Addr().(*net.UDPAddr).Port
+
+	cfg := Net.DefaultClientConfig()
+	cfg.Codec, cfg.Compression = codec, newTestDictionaries(t)
+	cfg.Hello = Net.NewClientHello("", Net.DefaultFeatures())
+	c, err := Net.Dial("127.0.0.1:"+strconv.Itoa(port), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	if !c.Dictionaries().Has(7) {
+		t.Fatalf("client dictionaries = %v, want 7 accepted", c.Dictionaries())
+	}
+	replies := make(chan string, 1)
+	Net.Handle(c, func(p *Pb.DataPacket) { replies <- p.Content })
+
+	before := dictFrames()
+	const content = `{"op":"move","x":5,"y":15,"facing":"north"}`
+	if err := c.Send(&Pb.DataPacket{Content: content}); err != nil {
+		t.Fatal(err)
+	}
+	var msg *Message
+	select {
+	case v := <-k.Messages():
+		msg = v.(*Message)
+	case <-time.After(2 * time.Second):
+		t.Fatal("server received nothing")
+	}
+	defer msg.Release()
+	if p, ok := msg.Value.(*Pb.DataPacket); !ok || p.Content != content {
+		t.Fatalf("server decoded %#v", msg.Value)
+	}
+	if !msg.From.Dictionaries().Has(7) {
+		t.Fatal("session did not accept dictionary 7")
+	}
+	if err := msg.From.Send(&Pb.DataPacket{Content: content}); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case got := <-replies:
+		if got != content {
+			t.Fatalf("client decoded %q", got)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("client received no reply")
+	}
+	if n := dictFrames() - before; n < 2 {
+		t.Fatalf("%d dictionary-compressed frames, want both directions", n)
+	}
+}
@@ -0,0 +1,113 @@
+package Actor
+
+//netdrain.go
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+	"zdopt/ZdoptServer/Net"
+)
+
+const (
+	drainPoll   = 10 * time.Millisecond  // 等待发送队列写完时的检查间隔
+	drainLinger = 200 * time.Millisecond // 发送队列写完后到关闭连接的等待，让 KCP 送达最后的数据
+)
+
+// WithDrainer 排空时由 d 生成重连提示（重连目标、重连等待与恢复令牌），集群内各节点应使用相同的令牌密钥；
+// 未设置时使用 Net.DefaultDrainConfig 且令牌仅本节点可验证
+func WithDrainer(d *Net.Drainer) KCPOption {
+	return func(o *kcpOptions) {
+		o.drainer = d
+	}
+}
+
+// Drain 平滑关闭（滚动发布、停机维护用）：拒绝新会话，向每个会话发送带重连提示的关闭通知控制帧
+// （见 Net.Drainer.ClosingFrame，排在已入队的消息之后），等待发送队列写完后关闭连接并停止监听。
+// reason 为 Net.Reason* 之一，message 展示给玩家。ctx 结束时立即关闭剩余会话并返回 Net.ErrDrainIncomplete。
+// 服务端会话共用监听的 UDP 套接字，因此排空结束前不关闭监听
+func (k *KCPListener) Drain(ctx context.Context, reason, message string) error {
+	k.draining.Store(true)
+	defer k.Stop()
+
+	var sessions []*Session
+	k.sessions.Range(func(_, v interface{}) bool {
+		sessions = append(sessions, v.(*Session))
+		return true
+	})
+	var errs error
+	for _, s := range sessions {
+		closing, err := k.opts.drainer.ClosingFrame(s.drainID(), reason, "", message)
+		if err != nil {
+			errs = errors.Join(errs, fmt.Errorf("session %d: %w", s.id, err))
+			continue
+		}
+		s.sendClosing(ctx, closing)
+	}
+	errs = errors.Join(errs, awaitFlushed(ctx, sessions))
+	for _, s := range sessions {
+		s.close(CloseReasonDrained)
+	}
+	return errs
+}
+
+// Draining 是否已开始排空（此时拒绝新会话）
+func (k *KCPListener) Draining() bool {
+	return k.draining.Load()
+}
+
+// drainID 恢复令牌中的会话标识：已认证玩家为玩家 ID，否则为远端地址
+func (s *Session) drainID() string {
+	if id, ok := s.Identity(); ok && id.PlayerID != 0 {
+		return strconv.FormatInt(id.PlayerID, 10)
+	}
+	return s.remote
+}
+
+// sendClosing 把关闭通知排在已入队的消息之后，之后的发送返回 ErrSessionClosing；队列满时等待至 ctx 结束
+func (s *Session) sendClosing(ctx context.Context, frame []byte) {
+	s.sendMu.Lock()
+	defer s.sendMu.Unlock()
+	s.closing.Store(true)
+	select {
+	case s.queue <- frame:
+		s.unsent.Add(1)
+	case <-s.done:
+	case <-ctx.Done():
+	}
+}
+
+// awaitFlushed 等待各会话的发送队列写完（已关闭的会话不再等待），再停留 drainLinger
+func awaitFlushed(ctx context.Context, sessions []*Session) error {
+	ticker := time.NewTicker(drainPoll)
+	defer ticker.Stop()
+	for {
+		pending := 0
+		for _, s := range sessions {
+			select {
+			case <-s.done:
+				continue
+			default:
+			}
+			if s.unsent.Load() > 0 {
+				pending++
+			}
+		}
+		if pending == 0 {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w: %d sessions not flushed: %v", Net.ErrDrainIncomplete, pending, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+	linger := time.NewTimer(drainLinger)
+	defer linger.Stop()
+	select {
+	case <-ctx.Done():
+	case <-linger.C:
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package Actor
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+	"zdopt/ZdoptServer/Net"
+	"zdopt/ZdoptServer/Pb"
+)
+
+func newTestCodec(t testing.TB) *Net.PbCodec {
+	t.Helper()
+	codec := Net.NewPbCodec()
+	for _, err := range []error{
+		Net.RegisterMessage[*Pb.ClientHello](codec, 1),
+		Net.RegisterMessage[*Pb.ServerHello](codec, 2),
+		Net.RegisterMessage[*Pb.DataPacket](codec, 3),
+	} {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return codec
+}
+
+func TestDrainSendsReconnectHint(t *testing.T) {
+	codec := newTestCodec(t)
+	drainer := Net.NewDrainer(Net.DrainConfig{Endpoint: "10.0.0.2:7777", Key: []byte("cluster-key")})
+	verify := func(token string) (Identity, error) {
+		id, err := strconv.ParseInt(token, 10, 64)
+		return Identity{PlayerID: id}, err
+	}
+	k := NewKCPListener(0, context.Background(),
+		WithCodec(codec), WithHandshake(TokenHandshake(verify), time.Second), WithDrainer(drainer))
+	if err := k.Start(); err != nil {
+		t.Fatal(err)
+	}
+	port := k.Addr().(*net.UDPAddr).Port
+
+	cfg := Net.DefaultClientConfig()
+	cfg.Codec, cfg.Hello = codec, &Pb.ClientHello{Token: "42"}
+	c, err := Net.Dial("127.0.0.1:"+strconv.Itoa(port), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	hints := make(chan *Pb.Reconnect, 1)
+	Net.Handle(c, func(h *Pb.Reconnect) { hints <- h })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := k.Drain(ctx, Net.ReasonMaintenance, "back soon"); err != nil {
+		t.Fatal(err)
+	}
+	if !k.Draining() {
+		t.Fatal("listener not marked draining")
+	}
+
+	select {
+	case h := <-hints:
+		if h.Reason != Net.ReasonMaintenance || h.Message != "back soon" || h.Endpoint != "10.0.0.2:7777" || h.RetryAfterMs == 0 {
+			t.Fatalf("unexpected hint %+v", h)
+		}
+		if id, err := drainer.VerifyToken(h.ResumeToken); err != nil || id != "42" {
+			t.Fatalf("resume token = %q, %v", id, err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("no reconnect hint before close")
+	}
+	<-c.Done()
+	if !errors.Is(c.Err(), Net.ErrServerClosing) {
+		t.Fatalf("client closed with %v, want ErrServerClosing", c.Err())
+	}
+}
@@ -0,0 +1,39 @@
+package Actor
+
+//netgateway.go
+import (
+	"bytes"
+	"expvar"
+	"strconv"
+	"zdopt/ZdoptServer/Gateway"
+	"zdopt/ZdoptServer/Pb"
+)
+
+var netPassthrough = expvar.NewMap("net.passthrough") // forwarded / failed
+
+// WithGateway 编解码器不认识的消息 ID 不再丢弃：帧负载以 *Pb.Passthrough（类型名见 Gateway.FrameType）
+// 交给 p 按路由表转发，不进入消息通道。转发在会话读协程中同步执行，Forwarder 不得阻塞
+func WithGateway(p *Gateway.Passthrough) KCPOption {
+	return func(o *kcpOptions) {
+		o.gateway = p
+	}
+}
+
+// forwardFrame 把未解码的消息原样交给网关，按来源会话的协议版本路由；负载复制后转发，msg 由调用方释放
+func forwardFrame(gateway *Gateway.Passthrough, msg *Message) {
+	var session string
+	if msg.From != nil {
+		session = gatewaySession(msg.From)
+	}
+	frame := &Pb.Passthrough{Type: Gateway.FrameType(msg.ID), Payload: bytes.Clone(msg.Data)}
+	if err := gateway.DispatchSession(session, frame); err != nil {
+		netPassthrough.Add("failed", 1)
+		return
+	}
+	netPassthrough.Add("forwarded", 1)
+}
+
+// gatewaySession 会话在网关版本分配中的键
+func gatewaySession(s *Session) string {
+	return strconv.FormatUint(s.ID(), 10)
+}
@@ -0,0 +1,66 @@
+package Actor
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+	"zdopt/ZdoptServer/Gateway"
+	"zdopt/ZdoptServer/Net"
+	"zdopt/ZdoptServer/Pb"
+)
+
+func TestGatewayForwardsUnknownMessageIDs(t *testing.T) {
+	type forwarded struct {
+		backend string
+		frame   *Pb.Passthrough
+	}
+	got := make(chan forwarded, 4)
+	gw := Gateway.NewPassthrough(Gateway.ForwarderFunc(func(backend string, frame *Pb.Passthrough) error {
+		got <- forwarded{backend, frame}
+		return nil
+	}), Gateway.Route{Match: "#900", Backend: "battle"})
+
+	codec := newTestCodec(t)
+	k := NewKCPListener(0, context.Background(), WithCodec(codec), WithGateway(gw),
+		WithHandshake(TokenHandshake(func(string) (Identity, error) { return Identity{PlayerID: 31}, nil }), time.Second))
+	if err := k.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer k.Stop()
+
+	cfg := Net.DefaultClientConfig()
+	cfg.Codec, cfg.Hello = codec, &Pb.ClientHello{}
+	c, err := Net.Dial("127.0.0.1:"+strconv.Itoa(k.Addr().(*net.UDPAddr).Port), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	// 编解码器未登记的 ID 按路由表原样转发，登记过的类型照常解码投递
+	if err := c.SendFrame(900, []byte("raw")); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Send(&Pb.DataPacket{}); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case f := <-got:
+		if f.backend != "battle" || f.frame.Type != "#900" || string(f.frame.Payload) != "raw" {
+			t.Fatalf("forwarded %s %+v", f.backend, f.frame)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("unknown message id never reached the forwarder")
+	}
+	select {
+	case v := <-k.Messages():
+		msg := v.(*Message)
+		defer msg.Release()
+		if _, ok := msg.Value.(*Pb.DataPacket); !ok {
+			t.Fatalf("delivered %T, want *Pb.DataPacket", msg.Value)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("registered message not delivered")
+	}
+}
@@ -0,0 +1,164 @@
+package Actor
+
+//netmetrics.go
+import (
+	"expvar"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/xtaci/kcp-go"
+)
+
+var (
+	netBytesIn     = expvar.NewInt("net.bytes.in")
+	netBytesOut    = expvar.NewInt("net.bytes.out")
+	netPacketsIn   = expvar.NewInt("net.packets.in")  // 读取次数
+	netPacketsOut  = expvar.NewInt("net.packets.out") // 写出次数（合并写出的多帧计一次）
+	netMessagesIn  = expvar.NewInt("net.messages.in")
+	netMessagesOut = expvar.NewInt("net.messages.out")
+	netDropped     = expvar.NewMap("net.messages.dropped") // inbound（消息通道满）/ outbound（发送队列满）
+	netSessions    = expvar.NewInt("net.sessions.active")  // 最近一次采样时的在线会话数
+	netRTT         = expvar.NewFloat("net.rtt.avg_ms")     // 最近一次采样时各会话心跳 SRTT 的平均值
+
+	netListeners sync.Map // map[*KCPListener]struct{}，运行中的监听，供监控采样
+)
+
+func init() {
+	// KCP 协议层累计统计：重传、丢失段、FEC 恢复等
+	expvar.Publish("net.kcp", expvar.Func(func() interface{} {
+		return kcp.DefaultSnmp.Copy()
+	}))
+	expvar.Publish("net.buffers", expvar.Func(func() interface{} {
+		return netBuffers.Stats()
+	}))
+}
+
+// SessionStats 单个会话的网络统计
+type SessionStats struct {
+	ID          uint64        `json:"id"`
+	Remote      string        `json:"remote"`
+	PlayerID    int64         `json:"player_id,omitempty"`
+	BytesIn     int64         `json:"bytes_in"`
+	BytesOut    int64         `json:"bytes_out"`
+	PacketsIn   int64         `json:"packets_in"`
+	PacketsOut  int64         `json:"packets_out"`
+	MessagesIn  int64         `json:"messages_in"`
+	MessagesOut int64         `json:"messages_out"`
+	Dropped     int64         `json:"dropped"` // 消息通道满或发送队列满丢弃的消息数
+	RateLimited int64         `json:"rate_limited"`
+	Queued      int           `json:"queued"` // 发送队列中待写出的帧数
+	RTT         time.Duration `json:"rtt"`
+	RTTVar      time.Duration `json:"rtt_var"`
+	Loss        float64       `json:"loss"`
+}
+
+// sessionCounters 会话的流量计数
+type sessionCounters struct {
+	bytesIn, bytesOut       atomic.Int64
+	packetsIn, packetsOut   atomic.Int64
+	messagesIn, messagesOut atomic.Int64
+	dropped                 atomic.Int64
+}
+
+func (c *sessionCounters) read(n int) {
+	c.bytesIn.Add(int64(n))
+	c.packetsIn.Add(1)
+	netBytesIn.Add(int64(n))
+	netPacketsIn.Add(1)
+}
+
+func (c *sessionCounters) write(n, frames int) {
+	c.bytesOut.Add(int64(n))
+	c.packetsOut.Add(1)
+	c.messagesOut.Add(int64(frames))
+	netBytesOut.Add(int64(n))
+	netPacketsOut.Add(1)
+	netMessagesOut.Add(int64(frames))
+}
+
+// Stats 会话的网络统计快照，RTT 取自心跳测量
+func (s *Session) Stats() SessionStats {
+	id, _ := s.Identity()
+	hb := s.hb.Stats()
+	return SessionStats{
+		ID:          s.id,
+		Remote:      s.remote,
+		PlayerID:    id.PlayerID,
+		BytesIn:     s.counters.bytesIn.Load(),
+		BytesOut:    s.counters.bytesOut.Load(),
+		PacketsIn:   s.counters.packetsIn.Load(),
+		PacketsOut:  s.counters.packetsOut.Load(),
+		MessagesIn:  s.counters.messagesIn.Load(),
+		MessagesOut: s.counters.messagesOut.Load(),
+		Dropped:     s.counters.dropped.Load(),
+		RateLimited: s.rateLimited.Load(),
+		Queued:      len(s.queue),
+		RTT:         hb.SRTT,
+		RTTVar:      hb.RTTVar,
+		Loss:        hb.Loss,
+	}
+}
+
+// Stats 全部在线会话的网络统计
+func (k *KCPListener) Stats() []SessionStats {
+	var stats []SessionStats
+	k.byID.Range(func(_, v interface{}) bool {
+		stats = append(stats, v.(*Session).Stats())
+		return true
+	})
+	return stats
+}
+
+// NetMetrics 一次采样的网络汇总指标，流量计数为进程级累计值
+type NetMetrics struct {
+	At          time.Time
+	Sessions    int
+	BytesIn     int64
+	BytesOut    int64
+	PacketsIn   int64
+	PacketsOut  int64
+	MessagesIn  int64
+	MessagesOut int64
+	Dropped     int64
+	AvgRTT      time.Duration
+	MaxRTT      time.Duration
+}
+
+// SampleNetMetrics 汇总全部运行中监听的会话并发布到 expvar（由系统监控按采样周期调用）
+func SampleNetMetrics() NetMetrics {
+	m := NetMetrics{
+		At:          time.Now(),
+		BytesIn:     netBytesIn.Value(),
+		BytesOut:    netBytesOut.Value(),
+		PacketsIn:   netPacketsIn.Value(),
+		PacketsOut:  netPacketsOut.Value(),
+		MessagesIn:  netMessagesIn.Value(),
+		MessagesOut: netMessagesOut.Value(),
+	}
+	netDropped.Do(func(kv expvar.KeyValue) {
+		if v, ok := kv.Value.(*expvar.Int); ok {
+			m.Dropped += v.Value()
+		}
+	})
+	var total time.Duration
+	measured := 0
+	netListeners.Range(func(key, _ interface{}) bool {
+		key.(*KCPListener).byID.Range(func(_, v interface{}) bool {
+			m.Sessions++
+			if rtt := v.(*Session).hb.Stats().SRTT; rtt > 0 {
+				total += rtt
+				measured++
+				m.MaxRTT = max(m.MaxRTT, rtt)
+			}
+			return true
+		})
+		return true
+	})
+	if measured > 0 {
+		m.AvgRTT = total / time.Duration(measured)
+	}
+	netSessions.Set(int64(m.Sessions))
+	netRTT.Set(float64(m.AvgRTT) / float64(time.Millisecond))
+	return m
+}
@@ -0,0 +1,57 @@
+package Actor
+
+//netobserver.go
+import (
+	"zdopt/ZdoptServer/Net"
+	"zdopt/ZdoptServer/Pb"
+)
+
+// WithObservers 握手阶段收到 Pb.ObserverHello 时经 hub 校验令牌，会话成为只读观察者：只接收订阅流的事件，
+// 发来的应用消息一律拒绝，也不接收 Broadcast / Multicast。编解码器须登记 Pb.ObserverHello 与 Pb.ObserverEvent，
+// 并需配置 WithHandshake（玩家会话仍由其认证）
+func WithObservers(hub *Net.ObserverHub) KCPOption {
+	return func(o *kcpOptions) {
+		o.observers = hub
+	}
+}
+
+// observerConn 把会话适配为 Net.Session：事件负载按 Pb.ObserverEvent 的消息 ID 成帧后放入发送队列
+type observerConn struct {
+	s  *Session
+	id uint32
+}
+
+func (c observerConn) Write(b []byte) (int, error) {
+	if err := c.s.SendFrame(c.id, b); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+func (c observerConn) Close() error {
+	c.s.Close()
+	return nil
+}
+
+// Observer 会话为观察者时返回其订阅，玩家会话为 nil
+func (s *Session) Observer() *Net.Observer {
+	return s.observer.Load()
+}
+
+// attachObserver 处理观察者握手，令牌无效、流未授权或观察者已满时关闭会话并返回 false
+func (s *Session) attachObserver(hello *Pb.ObserverHello) bool {
+	f, err := s.listener.opts.codec.Encode(&Pb.ObserverEvent{})
+	var o *Net.Observer
+	if err == nil {
+		o, err = s.listener.opts.observers.Attach(observerConn{s: s, id: f.ID}, hello)
+	}
+	if err != nil {
+		sessionEvents.Add("rejected", 1)
+		s.close(CloseReasonHandshake)
+		return false
+	}
+	s.observer.Store(o)
+	s.authenticate(Identity{Name: o.Name()})
+	sessionEvents.Add("observers", 1)
+	return true
+}
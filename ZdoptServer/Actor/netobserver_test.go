@@ -0,0 +1,117 @@
+package Actor
+
+import (
+	"context"
+	"expvar"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+	"zdopt/ZdoptServer/Net"
+	"zdopt/ZdoptServer/Pb"
+)
+
+func newObserverListener(t *testing.T, hub *Net.ObserverHub) (*KCPListener, Net.ClientConfig) {
+	t.Helper()
+	codec := newTestCodec(t)
+	if err := Net.RegisterMessage[*Pb.ObserverHello](codec, 4); err != nil {
+		t.Fatal(err)
+	}
+	if err := Net.RegisterMessage[*Pb.ObserverEvent](codec, 5); err != nil {
+		t.Fatal(err)
+	}
+	k := NewKCPListener(0, context.Background(), WithCodec(codec), WithObservers(hub),
+		WithHandshake(TokenHandshake(func(string) (Identity, error) { return Identity{PlayerID: 1}, nil }), time.Second))
+	if err := k.Start(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(k.Stop)
+	cfg := Net.DefaultClientConfig()
+	cfg.Codec = codec
+	return k, cfg
+}
+
+func TestObserverHelloAttachesReadOnlySession(t *testing.T) {
+	hub := Net.NewObserverHub(Net.ObserverConfig{})
+	k, cfg := newObserverListener(t, hub)
+	c, err := Net.Dial("127.0.0.1:"+strconv.Itoa(k.Addr().(*net.UDPAddr).Port), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	events := make(chan *Pb.ObserverEvent, 4)
+	Net.Handle(c, func(e *Pb.ObserverEvent) { events <- e })
+
+	if err := c.Send(&Pb.ObserverHello{Token: hub.IssueToken("dashboard", Net.StreamLoad)}); err != nil {
+		t.Fatal(err)
+	}
+	for deadline := time.Now().Add(2 * time.Second); hub.Observers() == 0; time.Sleep(5 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("observer never attached")
+		}
+	}
+	hub.Publish(Net.StreamLoad, []byte("cpu=0.5"))
+	hub.Publish(Net.StreamRooms, []byte("not subscribed"))
+	select {
+	case e := <-events:
+		if e.Stream != Net.StreamLoad || string(e.Data) != "cpu=0.5" {
+			t.Fatalf("unexpected event %+v", e)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("observer received no event")
+	}
+
+	// 观察者不接收广播，发来的应用消息也不投递
+	if n, err := k.Broadcast(&Pb.DataPacket{Content: "state"}); err != nil || n != 0 {
+		t.Fatalf("broadcast reached %d sessions, %v", n, err)
+	}
+	if err := c.Send(&Pb.DataPacket{Content: "move"}); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case v := <-k.Messages():
+		t.Fatalf("observer command delivered: %#v", v)
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	// KCP 不通知对端关闭，由服务端踢出会话
+	k.byID.Range(func(_, v interface{}) bool {
+		v.(*Session).Close()
+		return true
+	})
+	for deadline := time.Now().Add(2 * time.Second); hub.Observers() != 0; time.Sleep(5 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("observer not detached after session closed")
+		}
+	}
+}
+
+func TestObserverHelloRejectsUnauthorizedStream(t *testing.T) {
+	hub := Net.NewObserverHub(Net.ObserverConfig{})
+	k, cfg := newObserverListener(t, hub)
+	c, err := Net.Dial("127.0.0.1:"+strconv.Itoa(k.Addr().(*net.UDPAddr).Port), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	rejected := func() int64 {
+		v, _ := sessionEvents.Get("rejected").(*expvar.Int)
+		if v == nil {
+			return 0
+		}
+		return v.Value()
+	}
+	before := rejected()
+	hello := &Pb.ObserverHello{Token: hub.IssueToken("dashboard", Net.StreamLoad), Streams: []string{Net.StreamMatches}}
+	if err := c.Send(hello); err != nil {
+		t.Fatal(err)
+	}
+	for deadline := time.Now().Add(2 * time.Second); rejected() == before; time.Sleep(5 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("observer hello with unauthorized stream not rejected")
+		}
+	}
+	if hub.Observers() != 0 {
+		t.Fatal("rejected observer attached")
+	}
+}
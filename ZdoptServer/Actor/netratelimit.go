@@ -0,0 +1,162 @@
+package Actor
+
+//netratelimit.go
+import (
+	"expvar"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	"zdopt/ZdoptServer/Net"
+)
+
+// CloseReasonRateLimit 入站超限且策略为断开
+const CloseReasonRateLimit = "rate_limited"
+
+var sessionRateLimited = expvar.NewMap("net.ratelimited") // 按 <messages|bytes>.<策略> 统计
+
+// RatePolicy 会话入站超限时的处理策略
+type RatePolicy int
+
+const (
+	RateDrop       RatePolicy = iota // 丢弃超限的应用消息（默认）
+	RateWarn                         // 记录日志后照常投递
+	RateDisconnect                   // 关闭会话
+)
+
+var ratePolicyNames = [...]string{"drop", "warn", "disconnect"}
+
+func (p RatePolicy) String() string {
+	if p >= 0 && int(p) < len(ratePolicyNames) {
+		return ratePolicyNames[p]
+	}
+	return fmt.Sprintf("RatePolicy(%d)", int(p))
+}
+
+// ParseRatePolicy 解析策略名称（drop / warn / disconnect），空串为 RateDrop
+func ParseRatePolicy(s string) (RatePolicy, error) {
+	if s == "" {
+		return RateDrop, nil
+	}
+	for i, name := range ratePolicyNames {
+		if strings.EqualFold(s, name) {
+			return RatePolicy(i), nil
+		}
+	}
+	return RateDrop, fmt.Errorf("unknown rate policy %q", s)
+}
+
+// RateViolation 一次入站超限
+type RateViolation struct {
+	Kind   string // "messages" 或 "bytes"
+	Limit  int    // 每秒上限
+	Policy RatePolicy
+}
+
+// SessionRateLimit 每个会话的入站限流，令牌桶容量为一秒的额度；心跳帧不计入消息数
+type SessionRateLimit struct {
+	Messages int // 每秒应用消息数上限，0 表示不限制
+	Bytes    int // 每秒入站字节数上限，0 表示不限制
+	Policy   RatePolicy
+	// OnLimited 可选钩子，返回本次实际采取的策略（如按玩家身份放宽或升级为断开）
+	OnLimited func(s *Session, v RateViolation) RatePolicy
+}
+
+// WithSessionRateLimit 在读循环中对每个会话执行入站限流
+func WithSessionRateLimit(l SessionRateLimit) KCPOption {
+	return func(o *kcpOptions) {
+		if l.Messages > 0 || l.Bytes > 0 {
+			o.rateLimit = &l
+		}
+	}
+}
+
+// sessionLimiter 单个会话的限流状态，读循环与 UDP 通道共用
+type sessionLimiter struct {
+	mu       sync.Mutex
+	cfg      *SessionRateLimit
+	messages tokenBucket
+	bytes    tokenBucket
+	warned   bool
+}
+
+func newSessionLimiter(cfg *SessionRateLimit, now time.Time) *sessionLimiter {
+	return &sessionLimiter{
+		cfg:      cfg,
+		messages: tokenBucket{tokens: float64(cfg.Messages), last: now},
+		bytes:    tokenBucket{tokens: float64(cfg.Bytes), last: now},
+	}
+}
+
+// take 按每秒 limit 补充令牌后消耗 n 个
+func (b *tokenBucket) take(n, limit float64, now time.Time) bool {
+	b.tokens = min(b.tokens+now.Sub(b.last).Seconds()*limit, limit)
+	b.last = now
+	if b.tokens >= n {
+		b.tokens -= n
+		return true
+	}
+	return false
+}
+
+// filter 对一次读取的 size 字节及其中的完整帧限流，返回放行的帧；返回 false 表示应断开
+func (l *sessionLimiter) filter(s *Session, size int, frames []Net.Frame, now time.Time) ([]Net.Frame, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if limit := l.cfg.Bytes; limit > 0 && !l.bytes.take(float64(size), float64(limit), now) {
+		switch l.violate(s, "bytes", limit) {
+		case RateDisconnect:
+			return nil, false
+		case RateDrop:
+			return controlFrames(frames), true
+		}
+	}
+	limit := l.cfg.Messages
+	if limit <= 0 {
+		return frames, true
+	}
+	kept := frames[:0]
+	for _, f := range frames {
+		if Net.IsControlID(f.ID) || l.messages.take(1, float64(limit), now) {
+			kept = append(kept, f)
+			continue
+		}
+		switch l.violate(s, "messages", limit) {
+		case RateDisconnect:
+			return nil, false
+		case RateWarn:
+			kept = append(kept, f)
+		default:
+			f.Release()
+		}
+	}
+	return kept, true
+}
+
+// violate 经钩子确定策略并计数，警告只在每个会话首次超限时记录日志
+func (l *sessionLimiter) violate(s *Session, kind string, limit int) RatePolicy {
+	v := RateViolation{Kind: kind, Limit: limit, Policy: l.cfg.Policy}
+	if l.cfg.OnLimited != nil {
+		v.Policy = l.cfg.OnLimited(s, v)
+	}
+	sessionRateLimited.Add(kind+"."+v.Policy.String(), 1)
+	s.rateLimited.Add(1)
+	if v.Policy == RateWarn && !l.warned {
+		l.warned = true
+		defaultLogger.Printf("session %d (%s) exceeded %d %s/s", s.id, s.remote, limit, kind)
+	}
+	return v.Policy
+}
+
+// controlFrames 只保留心跳控制帧，其余帧的缓冲归还
+func controlFrames(frames []Net.Frame) []Net.Frame {
+	kept := frames[:0]
+	for _, f := range frames {
+		if Net.IsControlID(f.ID) {
+			kept = append(kept, f)
+		} else {
+			f.Release()
+		}
+	}
+	return kept
+}
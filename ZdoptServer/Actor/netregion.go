@@ -0,0 +1,46 @@
+package Actor
+
+//netregion.go
+import (
+	"zdopt/ZdoptServer/Discovery"
+	"zdopt/ZdoptServer/Pb"
+)
+
+// WithRegion 设置本节点所在地域：握手时记录客户端自报的地域（见 Session.Region），
+// 客户端地域与之不同的会话收发的字节计入 Discovery 的跨地域流量
+func WithRegion(region string) KCPOption {
+	return func(o *kcpOptions) {
+		o.region = region
+	}
+}
+
+// Region 握手时客户端自报的地域及实测延迟，可直接交给 Discovery.Placer 或 Room.WithPlacement；未握手时为零值
+func (s *Session) Region() Discovery.ClientRegion {
+	if r := s.region.Load(); r != nil {
+		return *r
+	}
+	return Discovery.ClientRegion{}
+}
+
+// setRegion 记录 ClientHello 中的地域标签
+func (s *Session) setRegion(hello *Pb.ClientHello) {
+	r := Discovery.ClientRegionFromHello(hello)
+	s.region.Store(&r)
+}
+
+// regionTraffic 本节点与客户端地域均已知且不同时，把收发字节计入跨地域流量
+func (s *Session) regionTraffic(outbound bool, n int) {
+	local := s.listener.opts.region
+	if local == "" {
+		return
+	}
+	r := s.region.Load()
+	if r == nil || r.Region == "" || r.Region == local {
+		return
+	}
+	if outbound {
+		Discovery.RecordCrossRegionTraffic(local, r.Region, n)
+	} else {
+		Discovery.RecordCrossRegionTraffic(r.Region, local, n)
+	}
+}
@@ -0,0 +1,76 @@
+package Actor
+
+import (
+	"context"
+	"expvar"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+	"zdopt/ZdoptServer/Discovery"
+	"zdopt/ZdoptServer/Net"
+	"zdopt/ZdoptServer/Pb"
+	"zdopt/ZdoptServer/Room"
+)
+
+func crossRegionBytes(pair string) int64 {
+	if v, ok := expvar.Get("discovery.region.cross_bytes").(*expvar.Map).Get(pair).(*expvar.Int); ok {
+		return v.Value()
+	}
+	return 0
+}
+
+func TestSessionRegionDrivesPlacementAndTraffic(t *testing.T) {
+	codec := newTestCodec(t)
+	k := NewKCPListener(0, context.Background(), WithCodec(codec), WithRegion("eu"),
+		WithHandshake(TokenHandshake(func(string) (Identity, error) { return Identity{PlayerID: 21}, nil }), time.Second))
+	if err := k.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer k.Stop()
+
+	in, out := crossRegionBytes("ap->eu"), crossRegionBytes("eu->ap")
+	cfg := Net.DefaultClientConfig()
+	cfg.Codec, cfg.Hello = codec, &Pb.ClientHello{Region: "ap", RegionRTT: map[string]uint32{"us": 90, "eu": 180}}
+	c, err := Net.Dial("127.0.0.1:"+strconv.Itoa(k.Addr().(*net.UDPAddr).Port), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	s, ok := k.SessionByPlayer(21)
+	for deadline := time.Now().Add(time.Second); !ok && time.Now().Before(deadline); s, ok = k.SessionByPlayer(21) {
+		time.Sleep(time.Millisecond)
+	}
+	if !ok {
+		t.Fatal("session not registered")
+	}
+	region := s.Region()
+	if region.Region != "ap" || region.RTT["us"] != 90*time.Millisecond {
+		t.Fatalf("session region = %+v", region)
+	}
+	// 握手后的收发按方向计入跨地域流量
+	if err := c.SendFrame(77, []byte("ping")); err != nil {
+		t.Fatal(err)
+	}
+	for deadline := time.Now().Add(time.Second); (crossRegionBytes("ap->eu") == in || crossRegionBytes("eu->ap") == out) && time.Now().Before(deadline); {
+		time.Sleep(time.Millisecond)
+	}
+	if crossRegionBytes("ap->eu") <= in || crossRegionBytes("eu->ap") <= out {
+		t.Fatalf("cross-region bytes in=%d out=%d, unchanged", crossRegionBytes("ap->eu")-in, crossRegionBytes("eu->ap")-out)
+	}
+
+	// 客户端地域无节点时按其实测延迟选择最近的地域
+	endpoints := []Discovery.Endpoint{
+		{Host: "10.0.0.1", Port: 7000, Region: "eu"},
+		{Host: "10.0.1.1", Port: 7000, Region: "us"},
+	}
+	r, err := Room.Create("r1", "match", Room.WithPlacement(Discovery.NewPlacer(Discovery.DefaultRegionConfig()), region, endpoints))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if r.Region != "us" || r.Node.Host != "10.0.1.1" {
+		t.Fatalf("room placed in %q on %+v", r.Region, r.Node)
+	}
+}
@@ -0,0 +1,140 @@
+package Actor
+
+//netresume.go
+import (
+	"expvar"
+	"time"
+)
+
+// SessionResumedTopic 客户端在新连接上恢复会话时在事件总线上发布 SessionResumed 的主题，原会话不再发布 SessionClosed
+const SessionResumedTopic = "net.session.resumed"
+
+var sessionResumes = expvar.NewMap("net.resume") // parked / resumed / expired / failed
+
+// SessionResumed 会话恢复通知，Actor 据此把玩家状态绑定到新会话
+type SessionResumed struct {
+	SessionID uint64
+	Previous  uint64 // 原会话 ID
+	Remote    string
+	Identity  Identity
+	Replayed  int // 重发的未确认消息数
+}
+
+// ResumeConfig 会话恢复：断线后原会话暂存 TTL，期间客户端凭 ServerHello 中的恢复令牌重连即可恢复，
+// 并收到原连接上未确认（见 Net.AckMessageID）的最多 Window 条消息
+type ResumeConfig struct {
+	Window int
+	TTL    time.Duration
+}
+
+// DefaultResumeConfig 默认参数：保留 256 条未确认消息，暂存 30 秒
+func DefaultResumeConfig() ResumeConfig {
+	return ResumeConfig{Window: 256, TTL: 30 * time.Second}
+}
+
+// WithResume 启用会话恢复，零值字段使用默认值
+func WithResume(cfg ResumeConfig) KCPOption {
+	def := DefaultResumeConfig()
+	if cfg.Window <= 0 {
+		cfg.Window = def.Window
+	}
+	if cfg.TTL <= 0 {
+		cfg.TTL = def.TTL
+	}
+	return func(o *kcpOptions) {
+		o.resume = &cfg
+	}
+}
+
+// parkedSession 断线后等待恢复的会话
+type parkedSession struct {
+	s     *Session
+	timer *time.Timer
+}
+
+// ResumeToken 恢复令牌，未启用会话恢复时为空
+func (s *Session) ResumeToken() string {
+	return s.resumeToken
+}
+
+// Resume 在握手中以恢复令牌接管原会话（自定义握手中调用）：lastSeq 为客户端在原连接上收到的最后一条应用消息序号，
+// 之后的消息重新入队。原会话仍在线时将其关闭；令牌无效、已过期或所需消息已不在重发缓冲中时返回 false
+func (s *Session) Resume(token string, lastSeq uint32) (Identity, bool) {
+	k := s.listener
+	if s.resumeBuf == nil {
+		return Identity{}, false
+	}
+	var old *Session
+	if v, ok := k.parked.LoadAndDelete(token); ok {
+		p := v.(*parkedSession)
+		p.timer.Stop()
+		old = p.s
+	} else if v, ok := k.tokens.LoadAndDelete(token); ok {
+		old = v.(*Session)
+		old.close(CloseReasonResumed)
+	} else {
+		sessionResumes.Add("failed", 1)
+		return Identity{}, false
+	}
+
+	frames, ok := old.resumeBuf.Since(lastSeq)
+	if !ok || len(frames) > cap(s.queue)-len(s.queue) {
+		// 无法补齐错过的消息，按断线处理，客户端需重新登录
+		sessionResumes.Add("failed", 1)
+		k.publish(old)
+		return Identity{}, false
+	}
+	s.sendMu.Lock()
+	s.resumeToken, s.resumeBuf = old.resumeToken, old.resumeBuf
+	s.resumeBuf.Ack(lastSeq)
+	for _, f := range frames {
+		s.queue <- f
+		s.unsentBytes.Add(int64(len(f)))
+	}
+	// 沿用原会话的带宽估计，未确认消息的序号与客户端一致
+	if bw := old.bw.Load(); bw != nil {
+		if own := s.bw.Swap(bw); own != nil {
+			own.Close()
+		}
+	}
+	s.sendMu.Unlock()
+	s.SetFeatures(old.Features())
+
+	id, _ := old.Identity()
+	sessionResumes.Add("resumed", 1)
+	if bus := k.opts.bus; bus != nil {
+		_, _ = bus.Publish(SessionResumedTopic, SessionResumed{
+			SessionID: s.id,
+			Previous:  old.id,
+			Remote:    s.remote,
+			Identity:  id,
+			Replayed:  len(frames),
+		})
+	}
+	return id, true
+}
+
+// park 暂存断线的会话，TTL 内未恢复时发布 SessionClosed
+func (k *KCPListener) park(s *Session) {
+	p := &parkedSession{s: s}
+	p.timer = time.AfterFunc(k.opts.resume.TTL, func() {
+		if k.parked.CompareAndDelete(s.resumeToken, p) {
+			sessionResumes.Add("expired", 1)
+			k.publish(s)
+		}
+	})
+	k.parked.Store(s.resumeToken, p)
+	sessionResumes.Add("parked", 1)
+}
+
+// expireParked 停止时发布全部暂存会话的关闭通知
+func (k *KCPListener) expireParked() {
+	k.parked.Range(func(token, v interface{}) bool {
+		p := v.(*parkedSession)
+		if k.parked.CompareAndDelete(token, p) {
+			p.timer.Stop()
+			k.publish(p.s)
+		}
+		return true
+	})
+}
@@ -0,0 +1,164 @@
+package Actor
+
+//netroute.go
+import (
+	"errors"
+	"expvar"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"zdopt/ZdoptServer/Net"
+	"zdopt/ZdoptServer/Pb"
+
+	"google.golang.org/protobuf/proto"
+)
+
+var (
+	ErrNoMessageRoute  = errors.New("no route for message id")
+	ErrReservedMessage = errors.New("message id reserved for control frames")
+	ErrRouteExists     = errors.New("message id already routed")
+	ErrUnboundMessage  = errors.New("message type has no id")
+
+	netRouted = expvar.NewMap("net.routed") // delivered / fallback / unrouted / failed
+)
+
+// MessageRoute 路由表中的一项，Target 为目标 Actor 名称，处理函数路由为空；Type 为 ID 绑定的协议全名
+type MessageRoute struct {
+	ID     uint32 `json:"id"`
+	Type   string `json:"type,omitempty"`
+	Target string `json:"target,omitempty"`
+}
+
+type messageRoute struct {
+	target string
+	fn     func(*Message)
+}
+
+// MessageRouter 按帧头的消息 ID 把网络消息分发给目标：目标为 RegisterName 登记的名称（或别名）时
+// 以 *Message 经 SendName 投递到其邮箱，为处理函数时在分发协程中直接调用；接收方负责 Release。
+// 路由表一般在启动时配置，运行中修改同样安全
+type MessageRouter struct {
+	system   *System
+	mu       sync.RWMutex
+	routes   map[uint32]messageRoute
+	fallback string
+}
+
+// NewMessageRouter 创建空路由表
+func NewMessageRouter(system *System) *MessageRouter {
+	return &MessageRouter{system: system, routes: make(map[uint32]messageRoute)}
+}
+
+// Route 把消息 ID 路由到名为 target 的 Actor；目标在投递时才解析，可先于 Actor 登记配置
+func (r *MessageRouter) Route(id uint32, target string) error {
+	return r.add(id, messageRoute{target: target})
+}
+
+// RouteMessage 把类型 T 的消息路由到名为 target 的 Actor，ID 取自 Pb 的消息 ID 表（编解码器登记时绑定），
+// 与编解码器收发的 ID 一致；T 尚未绑定 ID 时返回 ErrUnboundMessage
+func RouteMessage[T proto.Message](r *MessageRouter, target string) error {
+	var zero T
+	id, ok := Pb.MessageID(zero)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnboundMessage, Pb.TypeName(zero))
+	}
+	return r.Route(uint32(id), target)
+}
+
+// RouteFunc 把消息 ID 路由到处理函数，fn 在分发协程中执行，不得阻塞
+func (r *MessageRouter) RouteFunc(id uint32, fn func(*Message)) error {
+	return r.add(id, messageRoute{fn: fn})
+}
+
+func (r *MessageRouter) add(id uint32, route messageRoute) error {
+	if Net.IsControlID(id) {
+		return fmt.Errorf("%w: %#x", ErrReservedMessage, id)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.routes[id]; ok {
+		return fmt.Errorf("%w: %d", ErrRouteExists, id)
+	}
+	r.routes[id] = route
+	return nil
+}
+
+// Unroute 删除消息 ID 的路由
+func (r *MessageRouter) Unroute(id uint32) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.routes, id)
+}
+
+// SetFallback 未配置路由的消息投递到名为 target 的 Actor，空串表示丢弃
+func (r *MessageRouter) SetFallback(target string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.fallback = target
+}
+
+// Routes 当前路由表，按消息 ID 排序
+func (r *MessageRouter) Routes() []MessageRoute {
+	r.mu.RLock()
+	out := make([]MessageRoute, 0, len(r.routes))
+	for id, route := range r.routes {
+		out = append(out, MessageRoute{ID: id, Target: route.target})
+	}
+	r.mu.RUnlock()
+	for i := range out {
+		if id := out[i].ID; id <= math.MaxUint16 {
+			out[i].Type, _ = Pb.NameOf(uint16(id))
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
+
+// Dispatch 按 msg.ID 分发一条消息；没有路由时释放消息并返回错误。投递失败时消息已进入死信
+// （系统停止中除外），由死信持有而不释放
+func (r *MessageRouter) Dispatch(msg *Message) error {
+	r.mu.RLock()
+	route, ok := r.routes[msg.ID]
+	fallback := r.fallback
+	r.mu.RUnlock()
+
+	result := "delivered"
+	switch {
+	case ok && route.fn != nil:
+		netRouted.Add(result, 1)
+		route.fn(msg)
+		return nil
+	case !ok && fallback == "":
+		netRouted.Add("unrouted", 1)
+		msg.Release()
+		return fmt.Errorf("%w: %d", ErrNoMessageRoute, msg.ID)
+	case !ok:
+		route.target, result = fallback, "fallback"
+	}
+	if err := r.system.SendName(route.target, msg); err != nil {
+		netRouted.Add("failed", 1)
+		if errors.Is(err, ErrSystemStopping) {
+			msg.Release()
+		}
+		return err
+	}
+	netRouted.Add(result, 1)
+	return nil
+}
+
+// Run 持续分发 messages 中的 *Message 直到通道关闭（如 KCPListener.Messages()）
+func (r *MessageRouter) Run(messages <-chan interface{}) {
+	for v := range messages {
+		if msg, ok := v.(*Message); ok {
+			_ = r.Dispatch(msg)
+		}
+	}
+}
+
+// WithRouter 监听启动后由 r 分发收到的消息，此时不应再读取 Messages()
+func WithRouter(r *MessageRouter) KCPOption {
+	return func(o *kcpOptions) {
+		o.router = r
+	}
+}
@@ -0,0 +1,56 @@
+package Actor
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"zdopt/ZdoptServer/Net"
+	"zdopt/ZdoptServer/Pb"
+)
+
+func TestMessageIDsSharedByCodecEnvelopeAndRouter(t *testing.T) {
+	const id = 40
+	a, b := Net.NewPbCodec(), Net.NewPbCodec()
+	if err := Net.RegisterMessage[*Pb.Passthrough](a, id); err != nil {
+		t.Fatal(err)
+	}
+	// 同一类型在另一个编解码器中换 ID、或同一 ID 绑定其他类型都被拒绝
+	if err := Net.RegisterMessage[*Pb.Passthrough](b, id+1); !errors.Is(err, Pb.ErrIDConflict) {
+		t.Fatalf("rebinding type to another id = %v", err)
+	}
+	if err := Net.RegisterMessage[*Pb.ExportBatch](b, id); !errors.Is(err, Pb.ErrIDConflict) {
+		t.Fatalf("binding id to another type = %v", err)
+	}
+	// 消息 ID 表与信封头同为 2 字节，超出的帧 ID 在登记时拒绝
+	if err := Net.RegisterMessage[*Pb.ExportBatch](b, math.MaxUint16+1); err == nil {
+		t.Fatal("id above 16 bits accepted")
+	}
+	if err := Net.RegisterMessage[*Pb.Passthrough](b, id); err != nil {
+		t.Fatal(err)
+	}
+
+	msg := &Pb.Passthrough{Type: "x"}
+	f, err := a.Encode(msg)
+	if err != nil || f.ID != id {
+		t.Fatalf("codec frame id = %d, %v", f.ID, err)
+	}
+	env, err := Pb.Pack(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, envID, err := Pb.Unpack(env); err != nil || envID != id {
+		t.Fatalf("envelope id = %d, %v", envID, err)
+	}
+
+	r := NewMessageRouter(nil)
+	if err := RouteMessage[*Pb.Passthrough](r, "gateway"); err != nil {
+		t.Fatal(err)
+	}
+	routes := r.Routes()
+	if len(routes) != 1 || routes[0].ID != id || routes[0].Type != Pb.TypeName(msg) {
+		t.Fatalf("routes = %+v", routes)
+	}
+	if err := RouteMessage[*Pb.DataPushAck](r, "gateway"); !errors.Is(err, ErrUnboundMessage) {
+		t.Fatalf("routing unbound type = %v", err)
+	}
+}
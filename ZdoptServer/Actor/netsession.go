@@ -0,0 +1,629 @@
+package Actor
+
+//netsession.go
+import (
+	"context"
+	"errors"
+	"expvar"
+	"fmt"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+	"zdopt/ZdoptServer/Discovery"
+	"zdopt/ZdoptServer/I18n"
+	"zdopt/ZdoptServer/Net"
+	"zdopt/ZdoptServer/Pb"
+)
+
+// SessionClosedTopic 网络会话关闭时在事件总线上发布 SessionClosed 的主题
+const SessionClosedTopic = "net.session.closed"
+
+// 会话关闭原因
+const (
+	CloseReasonHeartbeat = "heartbeat_timeout" // 超时未收到任何数据（含心跳回应）
+	CloseReasonIdle      = "idle_timeout"      // 超时未收到应用消息
+	CloseReasonRemote    = "remote_closed"     // 读取失败：对端关闭或网络错误
+	CloseReasonFrame     = "frame_error"       // 帧长度非法，流无法对齐
+	CloseReasonHandshake = "handshake_failed"  // 握手被拒绝或超时未完成
+	CloseReasonReplaced  = "replaced"          // 同一玩家在新会话登录
+	CloseReasonKicked    = "kicked"            // 服务端主动关闭
+	CloseReasonResumed   = "resumed"           // 客户端已在新连接上恢复会话
+	CloseReasonDrained   = "drained"           // 监听排空：已发送关闭通知并写完发送队列
+	CloseReasonShutdown  = "shutdown"
+)
+
+var (
+	ErrHandshakeRejected = errors.New("session handshake rejected")
+	ErrSessionClosed     = errors.New("session closed")
+	ErrSendQueueFull     = errors.New("session send queue full")
+	ErrSessionClosing    = errors.New("session closing")
+
+	sessionsClosed = expvar.NewMap("net.sessions.closed") // 按关闭原因统计
+	sessionEvents  = expvar.NewMap("net.sessions")        // authenticated / rejected
+	schemaChecks   = expvar.NewMap("net.schema")          // 握手协议比对结果：compatible / degraded / incompatible
+)
+
+// SessionClosed 会话关闭通知，Actor订阅 SessionClosedTopic 后据此清理玩家状态
+type SessionClosed struct {
+	SessionID uint64
+	Remote    string
+	Identity  Identity // 未完成握手时为零值
+	Reason    string
+	Heartbeat Net.HeartbeatStats // 关闭时的心跳状态
+}
+
+// Identity 握手确认的玩家身份
+type Identity struct {
+	PlayerID int64
+	Name     string
+	Claims   map[string]string
+}
+
+// Handshake 可插拔的握手步骤：会话建立后收到的消息先交给握手处理，完成前不会投递到消息通道。
+// 返回 done 为 true 表示认证完成；返回错误时关闭会话。握手中可经 s.Send 回复
+type Handshake interface {
+	Authenticate(s *Session, msg *Message) (id Identity, done bool, err error)
+}
+
+// HandshakeFunc 函数形式的 Handshake
+type HandshakeFunc func(s *Session, msg *Message) (Identity, bool, error)
+
+func (f HandshakeFunc) Authenticate(s *Session, msg *Message) (Identity, bool, error) {
+	return f(s, msg)
+}
+
+// TokenHandshake 以第一条 Pb.ClientHello 中的 Token 认证（需设置编解码器），verify 校验令牌并返回身份；
+// ClientHello 带有效的恢复令牌时直接恢复原会话。ClientHello 携带的协议摘要与本端不兼容时拒绝，
+// 认证通过后按 ClientHello 中的特性协商并回复 Pb.ServerHello
+func TokenHandshake(verify func(token string) (Identity, error)) Handshake {
+	return HandshakeFunc(func(s *Session, msg *Message) (Identity, bool, error) {
+		hello, ok := msg.Value.(*Pb.ClientHello)
+		if !ok {
+			return Identity{}, false, fmt.Errorf("%w: expected ClientHello, got %T", ErrHandshakeRejected, msg.Value)
+		}
+		if err := s.checkSchema(hello); err != nil {
+			return Identity{}, false, err
+		}
+		if token := hello.GetResumeToken(); token != "" {
+			if id, ok := s.Resume(token, hello.GetLastSeq()); ok {
+				s.acceptHello(hello, true)
+				return id, true, nil
+			}
+		}
+		id, err := verify(hello.GetToken())
+		if err != nil {
+			return Identity{}, false, fmt.Errorf("%w: %w", ErrHandshakeRejected, err)
+		}
+		s.acceptHello(hello, false)
+		return id, true, nil
+	})
+}
+
+// WithHeartbeat 设置会话心跳参数（默认 Net.DefaultHeartbeatConfig），间隔与超时按链路质量自适应
+func WithHeartbeat(cfg Net.HeartbeatConfig) KCPOption {
+	return func(o *kcpOptions) {
+		o.heartbeat = cfg
+	}
+}
+
+// WithBandwidth 为每个会话估计可用带宽（见 Session.Bandwidth）：投递速率来自客户端确认帧，
+// 排空速率来自发送队列积压（随心跳采样），零值字段使用 Net.DefaultBandwidthConfig
+func WithBandwidth(cfg Net.BandwidthConfig) KCPOption {
+	return func(o *kcpOptions) {
+		o.bandwidth = &cfg
+	}
+}
+
+// WithCatalog 握手时按 ClientHello 的语言偏好在 c 中协商会话语言（默认 I18n.Default），Session.SendError 据此本地化
+func WithCatalog(c *I18n.Catalog) KCPOption {
+	return func(o *kcpOptions) {
+		if c != nil {
+			o.catalog = c
+		}
+	}
+}
+
+// WithIdleTimeout 超过 d 未收到应用消息（心跳不计）时关闭会话，0 表示不限制
+func WithIdleTimeout(d time.Duration) KCPOption {
+	return func(o *kcpOptions) {
+		o.idleTimeout = d
+	}
+}
+
+// WithEventBus 会话关闭时在 bus 上发布 SessionClosed
+func WithEventBus(bus *EventBus) KCPOption {
+	return func(o *kcpOptions) {
+		o.bus = bus
+	}
+}
+
+// WithHandshake 设置握手步骤，timeout 内未完成时关闭会话（0 为 10 秒）；未设置时会话建立即视为匿名已认证
+func WithHandshake(h Handshake, timeout time.Duration) KCPOption {
+	return func(o *kcpOptions) {
+		o.handshake = h
+		if timeout > 0 {
+			o.handshakeTimeout = timeout
+		}
+	}
+}
+
+// WithSendQueue 设置每个会话的发送队列长度（默认 256），队列满时 Send 返回 ErrSendQueueFull
+func WithSendQueue(n int) KCPOption {
+	return func(o *kcpOptions) {
+		if n > 0 {
+			o.sendQueue = n
+		}
+	}
+}
+
+// Session 监听端的一个网络会话：握手确认的身份、心跳状态与独立的发送队列
+type Session struct {
+	id          uint64
+	sess        net.Conn // KCP 会话或 TCP 连接
+	remote      string
+	listener    *KCPListener
+	hb          *Net.Heartbeat
+	identity    atomic.Pointer[Identity] // 握手完成后设置
+	features    atomic.Pointer[Net.Features]
+	dicts       atomic.Pointer[Net.AcceptedDictionaries] // 握手协商的压缩字典
+	queue       chan []byte
+	unsent      atomic.Int64 // 已入队尚未写出的帧数
+	unsentBytes atomic.Int64 // 已入队尚未写出的字节数
+	closing     atomic.Bool  // 已发送关闭通知，不再接受新的发送
+	lastMessage atomic.Int64 // 最近一条应用消息的时间（UnixNano）
+	rateLimited atomic.Int64 // 入站超限次数
+	counters    sessionCounters
+	sendMu      sync.Mutex // 保证入队顺序与重发缓冲序号一致
+	resumeToken string
+	resumeBuf   *Net.ResumeBuffer // 未启用会话恢复时为 nil
+	limiter     *sessionLimiter   // 未启用入站限流时为 nil
+	udpKey      uint64            // 未开启 UDP 通道时为 0
+	udpAddr     atomic.Pointer[net.UDPAddr]
+	bw          atomic.Pointer[Net.BandwidthEstimator] // 未启用带宽估计时为 nil，恢复会话时沿用原会话的估计
+	observer    atomic.Pointer[Net.Observer]           // 观察者会话的订阅，玩家会话为 nil
+	locale      atomic.Pointer[string]                 // 握手协商的会话语言
+	schema      atomic.Pointer[Pb.SchemaReport]        // 与客户端协议摘要的比对，客户端未携带摘要时为 nil
+	region      atomic.Pointer[Discovery.ClientRegion] // 握手时客户端自报的地域
+	closeOnce   sync.Once
+	reason      string
+	done        chan struct{}
+}
+
+func newSession(id uint64, sess net.Conn, remote string, k *KCPListener) *Session {
+	s := &Session{
+		id:       id,
+		sess:     sess,
+		remote:   remote,
+		listener: k,
+		hb:       Net.NewHeartbeat(k.opts.heartbeat),
+		queue:    make(chan []byte, k.opts.sendQueue),
+		done:     make(chan struct{}),
+	}
+	s.lastMessage.Store(time.Now().UnixNano())
+	if r := k.opts.resume; r != nil {
+		s.resumeToken = Net.NewResumeToken()
+		s.resumeBuf = Net.NewResumeBuffer(r.Window)
+	}
+	if k.opts.rateLimit != nil {
+		s.limiter = newSessionLimiter(k.opts.rateLimit, time.Now())
+	}
+	if k.opts.udpAddr != "" {
+		s.udpKey = Net.NewDatagramKey()
+	}
+	if k.opts.bandwidth != nil {
+		s.bw.Store(Net.NewBandwidthEstimator(*k.opts.bandwidth))
+	}
+	return s
+}
+
+// ID 会话 ID（本监听内唯一）
+func (s *Session) ID() uint64 {
+	return s.id
+}
+
+// Remote 远端地址，TCP 会话带 tcp:// 前缀
+func (s *Session) Remote() string {
+	return s.remote
+}
+
+// Identity 握手确认的身份，未完成握手时返回 false
+func (s *Session) Identity() (Identity, bool) {
+	if id := s.identity.Load(); id != nil {
+		return *id, true
+	}
+	return Identity{}, false
+}
+
+// Authenticated 是否已完成握手
+func (s *Session) Authenticated() bool {
+	return s.identity.Load() != nil
+}
+
+// Heartbeat 心跳状态
+func (s *Session) Heartbeat() Net.HeartbeatStats {
+	return s.hb.Stats()
+}
+
+// Bandwidth 会话的带宽估计，可作为 StateSync.QualitySource；未启用 WithBandwidth 时为 nil
+func (s *Session) Bandwidth() *Net.BandwidthEstimator {
+	return s.bw.Load()
+}
+
+// acked 处理客户端确认帧：释放重发缓冲并计入带宽估计
+func (s *Session) acked(seq uint32) {
+	if s.resumeBuf != nil {
+		s.resumeBuf.Ack(seq)
+	}
+	if bw := s.bw.Load(); bw != nil {
+		bw.AckedThrough(seq, time.Now())
+	}
+}
+
+// Locale 握手协商的会话语言，未协商时为目录的缺省语言
+func (s *Session) Locale() string {
+	if l := s.locale.Load(); l != nil {
+		return *l
+	}
+	return s.listener.opts.catalog.Negotiate(nil)
+}
+
+// checkSchema 比对 ClientHello 携带的协议摘要，同名消息字段不一致时返回错误；未携带摘要时不比对
+func (s *Session) checkSchema(hello *Pb.ClientHello) error {
+	remote := hello.GetSchema()
+	if remote == nil {
+		return nil
+	}
+	report := Pb.CompareSchemas(Pb.LocalDigest(), remote)
+	schemaChecks.Add(report.Status().String(), 1)
+	s.schema.Store(report)
+	if err := report.Err(); err != nil {
+		return fmt.Errorf("%w: %w", ErrHandshakeRejected, err)
+	}
+	return nil
+}
+
+// Schema 与客户端协议注册表的比对报告，客户端未携带摘要时为 nil；
+// SchemaDegraded 时不应向其发送 MissingRemote 中的类型
+func (s *Session) Schema() *Pb.SchemaReport {
+	return s.schema.Load()
+}
+
+// SendError 发送按会话语言本地化的 Pb.ErrorResponse，args 为文本的格式化参数
+func (s *Session) SendError(code I18n.Code, args ...interface{}) error {
+	return s.Send(s.listener.opts.catalog.NewErrorResponse(s.Locale(), code, args...))
+}
+
+// Features 握手协商的协议特性，未协商时为空
+func (s *Session) Features() Net.Features {
+	if f := s.features.Load(); f != nil {
+		return *f
+	}
+	return Net.Features{}
+}
+
+// SetFeatures 设置协商结果（自定义握手中调用），启用 Net.FeatureCompression 且监听配置了压缩时发送的大负载将被压缩
+func (s *Session) SetFeatures(f Net.Features) {
+	s.features.Store(&f)
+}
+
+// Dictionaries 握手协商的压缩字典，未协商时为 nil
+func (s *Session) Dictionaries() Net.AcceptedDictionaries {
+	if a := s.dicts.Load(); a != nil {
+		return *a
+	}
+	return nil
+}
+
+// compressing 发往该会话的帧是否压缩
+func (s *Session) compressing() bool {
+	return s.listener.opts.compression != nil && s.Features().Has(Net.FeatureCompression)
+}
+
+// acceptHello 按本端支持的特性协商并回复 ServerHello（编解码器未注册 ServerHello 时不回复），
+// 启用了会话恢复时回复中带恢复令牌；同时记录客户端地域、协商会话语言，协商了压缩且压缩配置了字典时一并协商字典
+func (s *Session) acceptHello(hello *Pb.ClientHello, resumed bool) {
+	local := Net.DefaultFeatures()
+	if s.listener.opts.compression == nil {
+		delete(local, Net.FeatureCompression)
+	}
+	reply, negotiated := Net.AcceptHello(local, hello)
+	reply.ResumeToken, reply.Resumed = s.resumeToken, resumed
+	if addr := s.listener.UDPAddr(); addr != nil {
+		reply.UdpKey, reply.UdpPort = s.udpKey, uint32(addr.Port)
+	}
+	reply.Locale, reply.Schema = s.negotiateLocale(hello), Pb.LocalDigest()
+	s.setRegion(hello)
+	var accepted Net.AcceptedDictionaries
+	if dicts := s.listener.opts.compression.Dictionaries(); dicts != nil && negotiated.Has(Net.FeatureCompression) {
+		accepted = dicts.AcceptDictionaries(hello, reply)
+	}
+	s.SetFeatures(negotiated)
+	_ = s.Send(reply)
+	// 回复发出后才以字典压缩，对端收到回复前不必识别字典帧
+	if accepted != nil {
+		s.dicts.Store(&accepted)
+	}
+}
+
+// negotiateLocale 按 ClientHello 的语言偏好协商会话语言
+func (s *Session) negotiateLocale(hello *Pb.ClientHello) string {
+	locale := s.listener.opts.catalog.Negotiate(hello.GetLocales())
+	s.locale.Store(&locale)
+	return locale
+}
+
+// RateLimited 入站超限次数（含仅警告的）
+func (s *Session) RateLimited() int64 {
+	return s.rateLimited.Load()
+}
+
+// Send 经编解码器编码后放入发送队列
+func (s *Session) Send(msg interface{}) error {
+	codec := s.listener.opts.codec
+	if codec == nil {
+		return ErrNoCodec
+	}
+	f, err := codec.Encode(msg)
+	if err != nil {
+		return err
+	}
+	return s.SendFrame(f.ID, f.Payload)
+}
+
+// SendFrame 把一帧放入发送队列，由会话的写协程按序写出
+func (s *Session) SendFrame(id uint32, payload []byte) error {
+	buf := make([]byte, 0, Net.FrameHeaderSize+len(payload))
+	if s.compressing() {
+		return s.enqueue(s.listener.opts.compression.AppendFrameWith(buf, id, payload, s.Dictionaries()))
+	}
+	return s.enqueue(Net.AppendFrame(buf, id, payload))
+}
+
+// enqueue 把编码好的整帧放入发送队列，frame 可被多个会话共享，入队后不得修改
+func (s *Session) enqueue(frame []byte) error {
+	select {
+	case <-s.done:
+		return ErrSessionClosed
+	default:
+	}
+	s.sendMu.Lock()
+	defer s.sendMu.Unlock()
+	if s.closing.Load() {
+		return ErrSessionClosing
+	}
+	select {
+	case s.queue <- frame:
+		s.unsent.Add(1)
+		s.unsentBytes.Add(int64(len(frame)))
+		if s.resumeBuf != nil {
+			s.resumeBuf.Push(frame)
+		}
+		if bw := s.bw.Load(); bw != nil {
+			bw.SentMessage(len(frame))
+		}
+		return nil
+	default:
+		s.counters.dropped.Add(1)
+		netDropped.Add("outbound", 1)
+		return ErrSendQueueFull
+	}
+}
+
+// Close 主动关闭会话
+func (s *Session) Close() {
+	s.close(CloseReasonKicked)
+}
+
+// close 以首次给出的原因关闭会话
+func (s *Session) close(reason string) {
+	s.closeOnce.Do(func() {
+		s.reason = reason
+		close(s.done)
+		_ = s.sess.Close()
+	})
+}
+
+// authenticate 握手完成：同一玩家已有会话时关闭旧会话
+func (s *Session) authenticate(id Identity) {
+	s.identity.Store(&id)
+	sessionEvents.Add("authenticated", 1)
+	if s.resumeBuf != nil {
+		s.listener.tokens.Store(s.resumeToken, s)
+	}
+	if s.udpKey != 0 {
+		s.listener.udpKeys.Store(s.udpKey, s)
+	}
+	if id.PlayerID == 0 {
+		return
+	}
+	if old, loaded := s.listener.players.Swap(id.PlayerID, s); loaded && old.(*Session) != s {
+		old.(*Session).close(CloseReasonReplaced)
+	}
+}
+
+// handshake 握手阶段处理一条消息，返回 false 表示会话已因握手失败关闭
+func (s *Session) handshake(msg *Message) bool {
+	if hello, ok := msg.Value.(*Pb.ObserverHello); ok && s.listener.opts.observers != nil {
+		return s.attachObserver(hello)
+	}
+	if s.closing.Load() {
+		return false // 已拒绝，等待发送队列写完后关闭
+	}
+	if hello, ok := msg.Value.(*Pb.ClientHello); ok && s.locale.Load() == nil {
+		s.negotiateLocale(hello) // 先于认证协商，拒绝通知也按客户端语言本地化
+	}
+	id, done, err := s.listener.opts.handshake.Authenticate(s, msg)
+	if err != nil {
+		s.rejectHandshake(err)
+		return false
+	}
+	if done {
+		s.authenticate(id)
+	}
+	return true
+}
+
+// rejectFlushTimeout 握手被拒绝后等待拒绝通知写出的时限
+const rejectFlushTimeout = time.Second
+
+// rejectHandshake 握手被拒绝：协议不兼容时先下发比对报告（编解码器未登记 Pb.SchemaMismatch 时跳过）并记录日志，
+// 再按客户端语言下发 Pb.ErrorResponse（错误码见 handshakeCode，未登记时跳过）；之后不再接受发送，写完发送队列后关闭会话
+func (s *Session) rejectHandshake(err error) {
+	sessionEvents.Add("rejected", 1)
+	if report := s.Schema(); report != nil && errors.Is(err, Pb.ErrSchemaIncompatible) {
+		defaultLogger.Printf("session %d (%s) rejected: %s", s.id, s.remote, report)
+		_ = s.Send(report.Proto())
+	}
+	_ = s.SendError(handshakeCode(err))
+	s.sendMu.Lock()
+	s.closing.Store(true)
+	s.sendMu.Unlock()
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), rejectFlushTimeout)
+		defer cancel()
+		_ = awaitFlushed(ctx, []*Session{s})
+		s.close(CloseReasonHandshake)
+	}()
+}
+
+// handshakeCode 握手拒绝时下发的错误码：错误链中带 I18n 错误码（I18n.WithCode）时取之，
+// 协议不兼容为 CodeIncompatibleProtocol，其余为 CodeUnauthorized
+func handshakeCode(err error) I18n.Code {
+	if errors.Is(err, Pb.ErrSchemaIncompatible) {
+		return I18n.CodeOf(err, I18n.CodeIncompatibleProtocol)
+	}
+	return I18n.CodeOf(err, I18n.CodeUnauthorized)
+}
+
+// writeBatchSize 写协程一次合并写出的字节上限
+const writeBatchSize = 32 << 10
+
+// writeLoop 按序写出发送队列，已排队的多帧合并为一次写出；写失败时关闭会话
+func (k *KCPListener) writeLoop(s *Session) {
+	defer k.wg.Done()
+	batch := make([]byte, 0, writeBatchSize)
+	for {
+		var frame []byte
+		select {
+		case <-s.done:
+			return
+		case frame = <-s.queue:
+		}
+		batch = append(batch[:0], frame...)
+		frames := 1
+	drain:
+		for len(batch) < writeBatchSize {
+			select {
+			case frame = <-s.queue:
+				batch = append(batch, frame...)
+				frames++
+			default:
+				break drain
+			}
+		}
+		if _, err := s.sess.Write(batch); err != nil {
+			s.close(CloseReasonRemote)
+			return
+		}
+		s.counters.write(len(batch), frames)
+		s.regionTraffic(true, len(batch))
+		s.unsent.Add(-int64(frames))
+		s.unsentBytes.Add(-int64(len(batch)))
+		if bw := s.bw.Load(); bw != nil {
+			bw.Sent(len(batch))
+		}
+	}
+}
+
+// heartbeatLoop 按自适应间隔发送 Ping，心跳超时、空闲超时或握手超时时关闭会话
+func (k *KCPListener) heartbeatLoop(s *Session) {
+	defer k.wg.Done()
+	var seq uint32
+	timer := time.NewTimer(s.hb.Interval())
+	defer timer.Stop()
+	opened := time.Now()
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-timer.C:
+		}
+		now := time.Now()
+		if s.hb.Expired(now) {
+			s.close(CloseReasonHeartbeat)
+			return
+		}
+		if idle := k.opts.idleTimeout; idle > 0 && now.Sub(time.Unix(0, s.lastMessage.Load())) > idle {
+			s.close(CloseReasonIdle)
+			return
+		}
+		if !s.Authenticated() && now.Sub(opened) > k.opts.handshakeTimeout {
+			sessionEvents.Add("rejected", 1)
+			s.close(CloseReasonHandshake)
+			return
+		}
+		if bw := s.bw.Load(); bw != nil {
+			bw.Queue(int(s.unsentBytes.Load()), now)
+		}
+		seq++
+		s.hb.Sent(seq, now)
+		ping := Net.AppendHeartbeatFrame(nil, Net.PingMessageID, seq)
+		if _, err := s.sess.Write(ping); err != nil {
+			s.close(CloseReasonRemote)
+			return
+		}
+		s.counters.write(len(ping), 0)
+		s.regionTraffic(true, len(ping))
+		next := s.hb.Interval()
+		if !s.Authenticated() {
+			next = min(next, k.opts.handshakeTimeout)
+		}
+		timer.Reset(next)
+	}
+}
+
+// publishClosed 注销、统计并发布会话关闭通知（读循环退出时调用一次）；
+// 可恢复的会话先暂存，恢复期内未被恢复时才发布
+func (k *KCPListener) publishClosed(s *Session) {
+	if o := s.Observer(); o != nil {
+		_ = o.Close()
+	}
+	k.sessions.CompareAndDelete(s.remote, s)
+	k.byID.Delete(s.id)
+	k.udpKeys.CompareAndDelete(s.udpKey, s)
+	if g := k.opts.gateway; g != nil {
+		g.ReleaseSession(gatewaySession(s))
+	}
+	id, _ := s.Identity()
+	if id.PlayerID != 0 {
+		k.players.CompareAndDelete(id.PlayerID, s)
+	}
+	sessionsClosed.Add(s.reason, 1)
+	if s.resumeBuf != nil && s.Authenticated() {
+		if !k.tokens.CompareAndDelete(s.resumeToken, s) {
+			return // 已在新连接上恢复
+		}
+		if s.reason == CloseReasonHeartbeat || s.reason == CloseReasonRemote {
+			k.park(s)
+			return
+		}
+	}
+	k.publish(s)
+}
+
+func (k *KCPListener) publish(s *Session) {
+	if bw := s.bw.Load(); bw != nil {
+		bw.Close()
+	}
+	if bus := k.opts.bus; bus != nil {
+		id, _ := s.Identity()
+		_, _ = bus.Publish(SessionClosedTopic, SessionClosed{
+			SessionID: s.id,
+			Remote:    s.remote,
+			Identity:  id,
+			Reason:    s.reason,
+			Heartbeat: s.hb.Stats(),
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package Actor
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+	"zdopt/ZdoptServer/I18n"
+	"zdopt/ZdoptServer/Net"
+	"zdopt/ZdoptServer/Pb"
+)
+
+func TestHandshakeNegotiatesLocale(t *testing.T) {
+	codec := newTestCodec(t)
+	if err := Net.RegisterMessage[*Pb.ErrorResponse](codec, 6); err != nil {
+		t.Fatal(err)
+	}
+	k := NewKCPListener(0, context.Background(), WithCodec(codec),
+		WithHandshake(TokenHandshake(func(string) (Identity, error) { return Identity{PlayerID: 9}, nil }), time.Second))
+	if err := k.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer k.Stop()
+
+	cfg := Net.DefaultClientConfig()
+	cfg.Codec, cfg.Hello = codec, &Pb.ClientHello{Locales: []string{"zh_CN", "en"}}
+	c, err := Net.Dial("127.0.0.1:"+strconv.Itoa(k.Addr().(*net.UDPAddr).Port), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	if got := c.ServerHello().GetLocale(); got != "zh" {
+		t.Fatalf("ServerHello.Locale = %q, want zh", got)
+	}
+	errs := make(chan *Pb.ErrorResponse, 1)
+	Net.Handle(c, func(e *Pb.ErrorResponse) { errs <- e })
+
+	// 回复先于认证完成发出，等待会话登记
+	s, ok := k.SessionByPlayer(9)
+	for deadline := time.Now().Add(time.Second); !ok && time.Now().Before(deadline); s, ok = k.SessionByPlayer(9) {
+		time.Sleep(time.Millisecond)
+	}
+	if !ok {
+		t.Fatal("session not registered")
+	}
+	if s.Locale() != "zh" {
+		t.Fatalf("session locale = %q", s.Locale())
+	}
+	if err := s.SendError(I18n.CodeRateLimited); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case e := <-errs:
+		want := I18n.Default.Text("zh", I18n.CodeRateLimited)
+		if e.Code != uint32(I18n.CodeRateLimited) || e.Message != want || e.Locale != "zh" {
+			t.Fatalf("error response %+v, want %q", e, want)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("no error response")
+	}
+}
+
+func TestHandshakeRejectionSendsLocalizedError(t *testing.T) {
+	codec := newTestCodec(t)
+	if err := Net.RegisterMessage[*Pb.ErrorResponse](codec, 6); err != nil {
+		t.Fatal(err)
+	}
+	denied := errors.New("bad token")
+	k := NewKCPListener(0, context.Background(), WithCodec(codec),
+		WithHandshake(TokenHandshake(func(token string) (Identity, error) {
+			if token == "busy" {
+				return Identity{}, I18n.WithCode(I18n.CodeServerBusy, denied)
+			}
+			return Identity{}, denied
+		}), time.Second))
+	if err := k.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer k.Stop()
+	addr := "127.0.0.1:" + strconv.Itoa(k.Addr().(*net.UDPAddr).Port)
+
+	for _, tc := range []struct {
+		token string
+		code  I18n.Code
+	}{
+		{"forged", I18n.CodeUnauthorized},
+		{"busy", I18n.CodeServerBusy},
+	} {
+		cfg := Net.DefaultClientConfig()
+		cfg.Codec, cfg.Hello = codec, &Pb.ClientHello{Token: tc.token, Locales: []string{"zh_CN"}}
+		_, err := Net.Dial(addr, cfg)
+		want := I18n.Default.Text("zh", tc.code)
+		if !errors.Is(err, Net.ErrHandshakeRejected) || !strings.Contains(err.Error(), want) {
+			t.Fatalf("dial with token %q = %v, want rejection %q", tc.token, err, want)
+		}
+	}
+}
+
+func TestHandshakeComparesSchemas(t *testing.T) {
+	codec := newTestCodec(t)
+	k := NewKCPListener(0, context.Background(), WithCodec(codec),
+		WithHandshake(TokenHandshake(func(string) (Identity, error) { return Identity{PlayerID: 11}, nil }), time.Second))
+	if err := k.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer k.Stop()
+	addr := "127.0.0.1:" + strconv.Itoa(k.Addr().(*net.UDPAddr).Port)
+
+	// 未设置摘要时 Dial 自动填入本端摘要，双方一致
+	cfg := Net.DefaultClientConfig()
+	cfg.Codec, cfg.Hello = codec, &Pb.ClientHello{}
+	c, err := Net.Dial(addr, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := c.Schema().Status(); got != Pb.SchemaCompatible {
+		t.Fatalf("client schema status = %v", got)
+	}
+	c.Close()
+
+	// 同名消息字段哈希不同：服务端拒绝握手
+	incompatible := func() int64 {
+		v, _ := schemaChecks.Get("incompatible").(*expvar.Int)
+		if v == nil {
+			return 0
+		}
+		return v.Value()
+	}
+	before := incompatible()
+	digest := Pb.LocalDigest()
+	digest.Messages[string(Pb.TypeName(&Pb.DataPacket{}))]++
+	cfg.Hello = &Pb.ClientHello{Schema: digest}
+	cfg.HandshakeTimeout = 300 * time.Millisecond
+	if _, err := Net.Dial(addr, cfg); !errors.Is(err, Net.ErrHandshakeTimeout) {
+		t.Fatalf("dial with incompatible schema = %v", err)
+	}
+	if incompatible() != before+1 {
+		t.Fatal("incompatible schema not counted")
+	}
+
+	// 编解码器登记了 SchemaMismatch 时，客户端在关闭前收到比对报告，Dial 立即以不兼容失败
+	if err := Net.RegisterMessage[*Pb.SchemaMismatch](codec, 7); err != nil {
+		t.Fatal(err)
+	}
+	cfg.HandshakeTimeout = 2 * time.Second
+	start := time.Now()
+	_, err = Net.Dial(addr, cfg)
+	if !errors.Is(err, Pb.ErrSchemaIncompatible) || !strings.Contains(err.Error(), "DataPacket") {
+		t.Fatalf("dial with incompatible schema = %v, want mismatch report", err)
+	}
+	if time.Since(start) >= cfg.HandshakeTimeout {
+		t.Fatal("rejection waited for the handshake timeout")
+	}
+}
@@ -0,0 +1,49 @@
+package Actor
+
+//nettcp.go
+import (
+	"errors"
+	"net"
+	"time"
+)
+
+// WithTCP 在 addr 上同时接受 TCP 会话（传输配置了 TLS 时为 TLS，见 Net.TransportConfig.ListenTCP），
+// 供 UDP 受限的网络使用：帧格式、握手、心跳与发送队列与 KCP 会话一致，Message.Session 为 nil
+func WithTCP(addr string) KCPOption {
+	return func(o *kcpOptions) {
+		o.tcpAddr = addr
+	}
+}
+
+// TCPAddr TCP 传输的实际监听地址，未开启时为 nil
+func (k *KCPListener) TCPAddr() net.Addr {
+	if k.tcp == nil {
+		return nil
+	}
+	return k.tcp.Addr()
+}
+
+// acceptTCPLoop 接受 TCP 会话，临时错误时短暂退避后重试
+func (k *KCPListener) acceptTCPLoop() {
+	defer k.wg.Done()
+	for {
+		conn, err := k.tcp.Accept()
+		if err != nil {
+			var ne net.Error
+			if k.ctx.Err() == nil && errors.As(err, &ne) && ne.Timeout() {
+				time.Sleep(5 * time.Millisecond)
+				continue
+			}
+			return
+		}
+		if k.ctx.Err() != nil {
+			_ = conn.Close()
+			return
+		}
+		if k.draining.Load() {
+			_ = conn.Close()
+			continue
+		}
+		k.accept(conn, "tcp://"+conn.RemoteAddr().String())
+	}
+}
@@ -0,0 +1,125 @@
+package Actor
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"zdopt/ZdoptServer/Net"
+	"zdopt/ZdoptServer/Pb"
+)
+
+// selfSignedCert 为 127.0.0.1 生成自签名证书，返回证书与私钥文件路径及客户端信任的根证书池
+func selfSignedCert(t *testing.T) (certFile, keyFile string, roots *x509.CertPool) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "zdopt-test"},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	certFile, keyFile = filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	roots = x509.NewCertPool()
+	roots.AddCert(cert)
+	return certFile, keyFile, roots
+}
+
+func TestTCPSessionEcho(t *testing.T) {
+	certFile, keyFile, roots := selfSignedCert(t)
+	for _, tc := range []struct {
+		name string
+		tls  bool
+	}{{"plain", false}, {"tls", true}} {
+		t.Run(tc.name, func(t *testing.T) {
+			transport := Net.DefaultTransportConfig()
+			cfg := Net.DefaultClientConfig()
+			if tc.tls {
+				transport.TLS = Net.TLSConfig{CertFile: certFile, KeyFile: keyFile}
+				cfg.TLS = &tls.Config{RootCAs: roots, ServerName: "127.0.0.1"}
+			}
+			codec := newTestCodec(t)
+			k := NewKCPListener(0, context.Background(), WithCodec(codec), WithTransport(transport), WithTCP("127.0.0.1:0"),
+				WithHandshake(TokenHandshake(func(string) (Identity, error) { return Identity{PlayerID: 5}, nil }), time.Second))
+			if err := k.Start(); err != nil {
+				t.Fatal(err)
+			}
+			defer k.Stop()
+
+			cfg.Transport, cfg.Network = transport, Net.NetworkTCP
+			cfg.Codec, cfg.Hello = codec, &Pb.ClientHello{}
+			c, err := Net.Dial(k.TCPAddr().String(), cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer c.Close()
+			replies := make(chan string, 1)
+			Net.Handle(c, func(p *Pb.DataPacket) { replies <- p.Content })
+			if err := c.Send(&Pb.DataPacket{Content: "ping"}); err != nil {
+				t.Fatal(err)
+			}
+
+			var msg *Message
+			select {
+			case v := <-k.Messages():
+				msg = v.(*Message)
+			case <-time.After(2 * time.Second):
+				t.Fatal("no message over tcp")
+			}
+			if msg.Session != nil || msg.From == nil || !strings.HasPrefix(msg.From.Remote(), "tcp://") {
+				t.Fatalf("message session %v, from %v", msg.Session, msg.From)
+			}
+			if id, _ := msg.From.Identity(); id.PlayerID != 5 {
+				t.Fatalf("identity = %+v", id)
+			}
+			if err := msg.From.Send(&Pb.DataPacket{Content: "pong"}); err != nil {
+				t.Fatal(err)
+			}
+			msg.Release()
+			select {
+			case got := <-replies:
+				if got != "pong" {
+					t.Fatalf("reply = %q", got)
+				}
+			case <-time.After(2 * time.Second):
+				t.Fatal("no reply over tcp")
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package Actor
+
+//netudp.go
+import (
+	"errors"
+	"expvar"
+	"net"
+	"time"
+	"zdopt/ZdoptServer/Net"
+)
+
+var (
+	ErrUDPDisabled = errors.New("udp channel not enabled")
+	ErrNoUDPPath   = errors.New("udp path not established")
+
+	netUDP = expvar.NewMap("net.udp") // in / out / malformed / unknown_key
+)
+
+// WithUDP 在 addr 上同时开启不可靠的 UDP 通道，供移动增量等对延迟敏感、可丢失的消息使用。
+// 握手完成的会话各有一个通道密钥，经 ServerHello 下发；客户端以 Net.DialDatagram 连接后，
+// 经该通道收到的消息与 KCP 消息一样投递到消息通道（Message.Unreliable 为 true、From 为同一会话）
+func WithUDP(addr string) KCPOption {
+	return func(o *kcpOptions) {
+		o.udpAddr = addr
+	}
+}
+
+func listenUDP(addr string) (*net.UDPConn, error) {
+	uaddr, err := net.ResolveUDPAddr("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+	return net.ListenUDP("udp", uaddr)
+}
+
+// UDPAddr UDP 通道的实际监听地址，未开启时为 nil
+func (k *KCPListener) UDPAddr() *net.UDPAddr {
+	if k.udp == nil {
+		return nil
+	}
+	return k.udp.LocalAddr().(*net.UDPAddr)
+}
+
+// UDPKey 会话的 UDP 通道密钥（自定义握手中下发给客户端），未开启时为 0
+func (s *Session) UDPKey() uint64 {
+	return s.udpKey
+}
+
+// SendUnreliable 经编解码器编码后从 UDP 通道直接发出：不排队、不重发、不压缩，
+// 客户端尚未经通道发来数据报时返回 ErrNoUDPPath
+func (s *Session) SendUnreliable(msg interface{}) error {
+	codec := s.listener.opts.codec
+	if codec == nil {
+		return ErrNoCodec
+	}
+	f, err := codec.Encode(msg)
+	if err != nil {
+		return err
+	}
+	return s.SendDatagram(f.ID, f.Payload)
+}
+
+// SendDatagram 以一个数据报发出一帧，负载上限见 Net.MaxDatagramSize
+func (s *Session) SendDatagram(id uint32, payload []byte) error {
+	k := s.listener
+	if k.udp == nil {
+		return ErrUDPDisabled
+	}
+	select {
+	case <-s.done:
+		return ErrSessionClosed
+	default:
+	}
+	addr := s.udpAddr.Load()
+	if addr == nil {
+		return ErrNoUDPPath
+	}
+	b, err := Net.AppendDatagram(make([]byte, 0, Net.DatagramHeaderSize+Net.FrameHeaderSize+len(payload)), s.udpKey, id, payload)
+	if err != nil {
+		return err
+	}
+	if _, err := k.udp.WriteToUDP(b, addr); err != nil {
+		return err
+	}
+	s.counters.write(len(b), 1)
+	s.regionTraffic(true, len(b))
+	netUDP.Add("out", 1)
+	return nil
+}
+
+// udpLoop UDP 通道读循环：按密钥找到会话并记录其最新来源地址（NAT 重绑定后随之更新），
+// Ping 经通道回 Pong，应用消息经限流与解压后投递
+func (k *KCPListener) udpLoop() {
+	defer k.wg.Done()
+	buf := make([]byte, Net.MaxDatagramSize)
+	for {
+		n, addr, err := k.udp.ReadFromUDP(buf)
+		if err != nil {
+			if k.ctx.Err() != nil || errors.Is(err, net.ErrClosed) {
+				return
+			}
+			continue
+		}
+		key, f, err := Net.ParseDatagram(buf[:n])
+		if err != nil {
+			netUDP.Add("malformed", 1)
+			continue
+		}
+		v, ok := k.udpKeys.Load(key)
+		if !ok {
+			netUDP.Add("unknown_key", 1)
+			continue
+		}
+		s := v.(*Session)
+		now := time.Now()
+		s.udpAddr.Store(addr)
+		s.counters.read(n)
+		s.regionTraffic(false, n)
+		s.hb.Received(now)
+		netUDP.Add("in", 1)
+		if f.ID == Net.PingMessageID {
+			if _, ok := Net.HeartbeatSeq(f); ok {
+				pong, _ := Net.AppendDatagram(nil, key, Net.PongMessageID, f.Payload)
+				_, _ = k.udp.WriteToUDP(pong, addr)
+			}
+			continue
+		}
+		if Net.IsControlID(f.ID) {
+			continue
+		}
+		// 负载引用读缓冲，复制到池化缓冲后随消息交出
+		pb := netBuffers.Get(len(f.Payload))
+		copy(pb.B, f.Payload)
+		f.Payload, f.Buf = pb.B, pb
+		frames := []Net.Frame{f}
+		if s.limiter != nil {
+			if frames, ok = s.limiter.filter(s, n, frames, now); !ok {
+				s.close(CloseReasonRateLimit)
+				continue
+			}
+		}
+		frames, err = k.opts.compression.ExpandWith(frames, s.Dictionaries())
+		if err != nil {
+			continue
+		}
+		if deliverFrames(k.messages, k.opts.codec, k.opts.gateway, s.sess, frames, s.hb, s, true) > 0 {
+			s.lastMessage.Store(now.UnixNano())
+		}
+	}
+}
@@ -4,23 +4,73 @@ package Actor
 
 import (
 	"context"
+	"errors"
+	"net"
 	"runtime"
 	"strconv"
 	"sync"
+	"sync/atomic"
+	"time"
+	"zdopt/ZdoptServer/Gateway"
+	"zdopt/ZdoptServer/I18n"
+	"zdopt/ZdoptServer/Net"
+	"zdopt/ZdoptServer/ObjectPool"
+	"zdopt/ZdoptServer/Strict"
 
 	"github.com/xtaci/kcp-go"
 )
 
+var ErrNoCodec = errors.New("network codec not set")
+
+// Message 一条完整的网络消息（已按帧重组）
 type Message struct {
-	Data []byte
+	ID      uint32
+	Data    []byte
+	Value   interface{}     // 设置了编解码器时为解码后的消息；服务端关闭通知（Net.ClosingMessageID）为 *Pb.Reconnect
+	Session *kcp.UDPSession // 来源 KCP 连接，TCP 会话为 nil（经 From 回包）
+	From    *Session        // 监听端的来源会话（已完成握手），可据此识别玩家与回包
+	// Unreliable 经会话的 UDP 通道收到（见 WithUDP），可能丢失、重复或乱序
+	Unreliable bool
+
+	buf      *ObjectPool.Buffer // Data 所在的池化缓冲，随 Release 归还
+	released atomic.Bool        // 已归还对象池，重复 Release 时忽略（严格模式下报告）
+}
+
+// Release 消费方处理完后把消息对象与 Data 所在的缓冲归还对象池，之后不得再访问（含 Data）；
+// 重复调用无效果，不会把同一对象两次放回对象池
+func (m *Message) Release() {
+	if !m.released.CompareAndSwap(false, true) {
+		if Strict.Enabled() {
+			Strict.Failf("net.message-double-release", "message %p (id %d) released twice", m, m.ID)
+		}
+		return
+	}
+	m.buf.Release()
+	m.ID, m.Data, m.Value, m.Session, m.From, m.Unreliable, m.buf = 0, nil, nil, nil, nil, false, nil
+	messagePool.Put(m)
+}
+
+// newMessage 从对象池取出消息对象
+func newMessage() *Message {
+	m := messagePool.Get().(*Message)
+	m.released.Store(false)
+	return m
 }
 
-// Parse 解析并保存接收到的数据
+// Parse 把接收到的数据复制到池化缓冲中保存
 func (m *Message) Parse(data []byte) {
-	m.Data = make([]byte, len(data))
+	m.buf.Release()
+	m.buf = netBuffers.Get(len(data))
+	m.Data = m.buf.B
 	copy(m.Data, data)
 }
 
+// netBuffers 网络读取与帧负载的分档缓冲池
+var netBuffers = ObjectPool.NewBufferPool()
+
+// readBufferSize 单次读取的缓冲大小
+const readBufferSize = 4 << 10
+
 // messagePool 全局消息对象池，避免频繁内存分配
 var messagePool = sync.Pool{
 	New: func() interface{} {
@@ -28,20 +78,188 @@ var messagePool = sync.Pool{
 	},
 }
 
-// KCPConn 使用连接池优化网络层
+// KCPOption 网络层选项
+type KCPOption func(*kcpOptions)
+
+type kcpOptions struct {
+	codec       Net.Codec
+	maxFrame    int
+	transport   Net.TransportConfig
+	heartbeat   Net.HeartbeatConfig
+	idleTimeout time.Duration
+	bus         *EventBus
+
+	handshake        Handshake
+	handshakeTimeout time.Duration
+	sendQueue        int
+	rateLimit        *SessionRateLimit
+	compression      *Net.Compression
+	resume           *ResumeConfig
+	udpAddr          string
+	tcpAddr          string
+	router           *MessageRouter
+	drainer          *Net.Drainer
+	bandwidth        *Net.BandwidthConfig
+	observers        *Net.ObserverHub
+	catalog          *I18n.Catalog
+	region           string
+	gateway          *Gateway.Passthrough
+}
+
+// WithTransport 设置加密与 FEC 参数（默认不加密，FEC 10/3），两端须一致
+func WithTransport(cfg Net.TransportConfig) KCPOption {
+	return func(o *kcpOptions) {
+		o.transport = cfg
+	}
+}
+
+// WithCodec 设置编解码器，收到的帧解码后放入 Message.Value，解码失败的帧丢弃（未知消息 ID 见 WithGateway）
+func WithCodec(codec Net.Codec) KCPOption {
+	return func(o *kcpOptions) {
+		o.codec = codec
+	}
+}
+
+// WithMaxFrameSize 设置单帧负载上限（默认 Net.DefaultMaxFrameSize），超出时关闭连接
+func WithMaxFrameSize(n int) KCPOption {
+	return func(o *kcpOptions) {
+		if n > 0 {
+			o.maxFrame = n
+		}
+	}
+}
+
+// WithCompression 启用负载压缩：收到的压缩帧经 c 解压，发往协商了 Net.FeatureCompression 的会话的大负载经 c 压缩
+func WithCompression(c *Net.Compression) KCPOption {
+	return func(o *kcpOptions) {
+		o.compression = c
+	}
+}
+
+func newKCPOptions(opts []KCPOption) kcpOptions {
+	o := kcpOptions{
+		maxFrame:  Net.DefaultMaxFrameSize,
+		transport: Net.DefaultTransportConfig(),
+		heartbeat: Net.DefaultHeartbeatConfig(),
+
+		handshakeTimeout: 10 * time.Second,
+		sendQueue:        256,
+		catalog:          I18n.Default,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.drainer == nil {
+		o.drainer = Net.NewDrainer(Net.DefaultDrainConfig())
+	}
+	return o
+}
+
+// deliverFrames 把完整帧投递到消息通道，通道满时快速失败；心跳控制帧在此应答（hb 为 nil 时不记录 RTT）。
+// codec 不认识的消息 ID 在 gateway 非 nil 时按其路由表原样转发，否则丢弃。
+// from 未完成握手时消息交给握手处理而不投递，返回应用消息帧数
+func deliverFrames(messages chan interface{}, codec Net.Codec, gateway *Gateway.Passthrough, sess net.Conn, frames []Net.Frame, hb *Net.Heartbeat, from *Session, unreliable bool) int {
+	app := 0
+	for _, f := range frames {
+		switch f.ID {
+		case Net.PingMessageID:
+			if seq, ok := Net.HeartbeatSeq(f); ok {
+				_, _ = sess.Write(Net.AppendHeartbeatFrame(nil, Net.PongMessageID, seq))
+			}
+			f.Release()
+			continue
+		case Net.PongMessageID:
+			if seq, ok := Net.HeartbeatSeq(f); ok && hb != nil {
+				hb.Acked(seq, time.Now())
+			}
+			f.Release()
+			continue
+		case Net.AckMessageID:
+			if seq, ok := Net.HeartbeatSeq(f); ok && from != nil {
+				from.acked(seq)
+			}
+			f.Release()
+			continue
+		}
+		app++
+		netMessagesIn.Add(1)
+		if from != nil {
+			from.counters.messagesIn.Add(1)
+		}
+		var value interface{}
+		var passthrough bool
+		switch {
+		case f.ID == Net.ClosingMessageID:
+			// 服务端关闭通知：客户端以 *Pb.Reconnect 投递，监听端忽略
+			hint, err := Net.ClosingHint(f)
+			if err != nil || from != nil {
+				f.Release()
+				continue
+			}
+			value = hint
+		case codec != nil:
+			v, err := codec.Decode(f)
+			passthrough = err != nil && gateway != nil && errors.Is(err, Net.ErrUnknownMessageID)
+			if err != nil && !passthrough {
+				f.Release()
+				continue
+			}
+			value = v
+		}
+		msg := newMessage()
+		msg.ID, msg.Data, msg.Value, msg.From, msg.buf = f.ID, f.Payload, value, from, f.Buf
+		msg.Session, _ = sess.(*kcp.UDPSession)
+		msg.Unreliable = unreliable
+		if from != nil && !from.Authenticated() {
+			ok := from.handshake(msg)
+			msg.Release()
+			if !ok {
+				return app
+			}
+			continue
+		}
+		if from != nil {
+			if o := from.Observer(); o != nil {
+				// 观察者只读，任何应用消息都不投递
+				_ = o.Inbound(msg.Data)
+				msg.Release()
+				continue
+			}
+		}
+		if passthrough {
+			forwardFrame(gateway, msg)
+			msg.Release()
+			continue
+		}
+		select {
+		case messages <- msg:
+		default:
+			netDropped.Add("inbound", 1)
+			if from != nil {
+				from.counters.dropped.Add(1)
+			}
+			msg.Release()
+		}
+	}
+	return app
+}
+
+// KCPConn 客户端拨号模式：使用连接池复用出站连接，服务端监听见 KCPListener
 type KCPConn struct {
 	connPool sync.Pool        // 存储 *kcp.UDPSession 连接对象
-	sessions sync.Map         // 存储会话（根据需要扩展）
+	sessions sync.Map         // map[*kcp.UDPSession]*Net.Reassembler，各连接未完成的半帧
 	messages chan interface{} // 用于传递解析后的消息
 	ctx      context.Context  // 上下文控制停止
+	opts     kcpOptions
 }
 
-// NewKCPConn 创建KCPConn实例，监听指定端口
-func NewKCPConn(port int, ctx context.Context) *KCPConn {
+// NewKCPConn 创建KCPConn实例，拨号到指定端口
+func NewKCPConn(port int, ctx context.Context, opts ...KCPOption) *KCPConn {
+	o := newKCPOptions(opts)
 	return &KCPConn{
 		connPool: sync.Pool{
 			New: func() interface{} {
-				conn, err := kcp.DialWithOptions(":"+strconv.Itoa(port), nil, 10, 3)
+				conn, err := o.transport.DialKCP(":" + strconv.Itoa(port))
 				if err != nil {
 					// 这里简单处理错误，实际使用中建议做更完善的错误处理
 					panic(err)
@@ -51,6 +269,7 @@ func NewKCPConn(port int, ctx context.Context) *KCPConn {
 		},
 		messages: make(chan interface{}, 1024),
 		ctx:      ctx,
+		opts:     o,
 	}
 }
 
@@ -69,26 +288,32 @@ func (k *KCPConn) readWorker() {
 		default:
 			// 从连接池中获取连接，注意类型断言为 *kcp.UDPSession
 			conn := k.connPool.Get().(*kcp.UDPSession)
-			data := make([]byte, 4096)
+			rb := netBuffers.Get(readBufferSize)
 
-			// 使用零拷贝优化读取数据
-			n, err := conn.Read(data)
+			n, err := conn.Read(rb.B)
 			if err != nil {
 				// 读取失败，将连接放回连接池后继续
+				rb.Release()
 				k.connPool.Put(conn)
 				continue
 			}
 
-			// 从消息池获取 Message 对象，避免频繁内存分配
-			msg := messagePool.Get().(*Message)
-			msg.Parse(data[:n])
-
-			// 非阻塞方式将消息投递到消息通道
-			select {
-			case k.messages <- msg:
-			default:
-				// 通道满时，将消息对象返回对象池，快速失败
-				messagePool.Put(msg)
+			// 一次读取可能是半帧或多帧，按连接重组，帧负载从缓冲池分配
+			v, ok := k.sessions.Load(conn)
+			if !ok {
+				r := Net.NewReassembler(k.opts.maxFrame)
+				r.SetBufferPool(netBuffers)
+				v, _ = k.sessions.LoadOrStore(conn, r)
+			}
+			frames, err := v.(*Net.Reassembler).Feed(rb.B[:n])
+			rb.Release()
+			frames, xerr := k.opts.compression.Expand(frames)
+			deliverFrames(k.messages, k.opts.codec, k.opts.gateway, conn, frames, nil, nil, false)
+			if err != nil || xerr != nil {
+				// 帧长度非法或无法解压，流已无法对齐，丢弃该连接
+				k.sessions.Delete(conn)
+				_ = conn.Close()
+				continue
 			}
 
 			// 将连接放回连接池
@@ -96,3 +321,242 @@ func (k *KCPConn) readWorker() {
 		}
 	}
 }
+
+// KCPListener 服务端监听模式：接受会话并为每个会话启动读循环与心跳，收到的消息投递到消息通道
+type KCPListener struct {
+	addr     string
+	listener *kcp.Listener
+	sessions sync.Map // map[string]*Session，按远端地址
+	byID     sync.Map // map[uint64]*Session
+	players  sync.Map // map[int64]*Session，已认证玩家的当前会话
+	tokens   sync.Map // map[string]*Session，恢复令牌 -> 在线会话
+	parked   sync.Map // map[string]*parkedSession，恢复令牌 -> 等待恢复的已断开会话
+	udp      *net.UDPConn
+	tcp      net.Listener
+	udpKeys  sync.Map // map[uint64]*Session，UDP 通道密钥 -> 已认证会话
+	nextID   atomic.Uint64
+	draining atomic.Bool
+	messages chan interface{} // *Message，From 为来源会话
+	ctx      context.Context
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+	stopOnce sync.Once
+	opts     kcpOptions
+}
+
+// NewKCPListener 创建监听指定端口的 KCPListener，Start 后开始接受会话
+func NewKCPListener(port int, ctx context.Context, opts ...KCPOption) *KCPListener {
+	ctx, cancel := context.WithCancel(ctx)
+	return &KCPListener{
+		addr:     ":" + strconv.Itoa(port),
+		messages: make(chan interface{}, 1024),
+		ctx:      ctx,
+		cancel:   cancel,
+		opts:     newKCPOptions(opts),
+	}
+}
+
+// Start 开始监听并接受会话
+func (k *KCPListener) Start() error {
+	listener, err := k.opts.transport.ListenKCP(k.addr)
+	if err != nil {
+		return err
+	}
+	if k.opts.udpAddr != "" {
+		if k.udp, err = listenUDP(k.opts.udpAddr); err != nil {
+			_ = listener.Close()
+			return err
+		}
+		k.wg.Add(1)
+		go k.udpLoop()
+	}
+	if k.opts.tcpAddr != "" {
+		if k.tcp, err = k.opts.transport.ListenTCP(k.opts.tcpAddr); err != nil {
+			_ = listener.Close()
+			if k.udp != nil {
+				_ = k.udp.Close()
+			}
+			return err
+		}
+		k.wg.Add(1)
+		go k.acceptTCPLoop()
+	}
+	k.listener = listener
+	netListeners.Store(k, struct{}{})
+	if k.opts.router != nil {
+		go k.opts.router.Run(k.messages)
+	}
+	k.wg.Add(1)
+	go k.acceptLoop()
+	// 上下文取消时同样停止
+	go func() {
+		<-k.ctx.Done()
+		k.Stop()
+	}()
+	return nil
+}
+
+// Addr 实际监听地址（端口为 0 时由系统分配），Start 前为 nil
+func (k *KCPListener) Addr() net.Addr {
+	if k.listener == nil {
+		return nil
+	}
+	return k.listener.Addr()
+}
+
+// Messages 消息通道，Stop 后关闭；设置了 WithRouter 时由路由表消费
+func (k *KCPListener) Messages() <-chan interface{} {
+	return k.messages
+}
+
+// Session 按远端地址查找会话
+func (k *KCPListener) Session(remote string) (*Session, bool) {
+	v, ok := k.sessions.Load(remote)
+	if !ok {
+		return nil, false
+	}
+	return v.(*Session), true
+}
+
+// SessionByID 按会话 ID 查找
+func (k *KCPListener) SessionByID(id uint64) (*Session, bool) {
+	v, ok := k.byID.Load(id)
+	if !ok {
+		return nil, false
+	}
+	return v.(*Session), true
+}
+
+// SessionByPlayer 按玩家 ID 查找已认证的会话
+func (k *KCPListener) SessionByPlayer(playerID int64) (*Session, bool) {
+	v, ok := k.players.Load(playerID)
+	if !ok {
+		return nil, false
+	}
+	return v.(*Session), true
+}
+
+// SessionCount 当前会话数
+func (k *KCPListener) SessionCount() int {
+	n := 0
+	k.sessions.Range(func(_, _ interface{}) bool {
+		n++
+		return true
+	})
+	return n
+}
+
+// Stop 停止监听并关闭全部会话，等待读循环退出后关闭消息通道（可重复调用）
+func (k *KCPListener) Stop() {
+	k.stopOnce.Do(func() {
+		netListeners.Delete(k)
+		k.cancel()
+		if k.listener != nil {
+			_ = k.listener.Close()
+		}
+		if k.udp != nil {
+			_ = k.udp.Close()
+		}
+		if k.tcp != nil {
+			_ = k.tcp.Close()
+		}
+		k.sessions.Range(func(_, v interface{}) bool {
+			v.(*Session).close(CloseReasonShutdown)
+			return true
+		})
+		k.wg.Wait()
+		k.expireParked()
+		close(k.messages)
+	})
+}
+
+func (k *KCPListener) acceptLoop() {
+	defer k.wg.Done()
+	for {
+		sess, err := k.listener.AcceptKCP()
+		if err != nil {
+			var ne net.Error
+			if k.ctx.Err() == nil && errors.As(err, &ne) && ne.Timeout() {
+				continue
+			}
+			return
+		}
+		if k.ctx.Err() != nil {
+			_ = sess.Close()
+			return
+		}
+		if k.draining.Load() {
+			_ = sess.Close()
+			continue
+		}
+		k.opts.transport.KCP.Apply(sess)
+		k.accept(sess, sess.RemoteAddr().String())
+	}
+}
+
+// accept 为新连接创建会话并启动读、写与心跳协程
+func (k *KCPListener) accept(conn net.Conn, remote string) {
+	s := newSession(k.nextID.Add(1), conn, remote, k)
+	if k.opts.handshake == nil {
+		s.authenticate(Identity{})
+	}
+	k.sessions.Store(s.remote, s)
+	k.byID.Store(s.id, s)
+	k.wg.Add(3)
+	go k.readLoop(s)
+	go k.writeLoop(s)
+	go k.heartbeatLoop(s)
+}
+
+// Send 经编解码器编码后以一帧直接写往连接（不经会话发送队列，见 Session.Send）
+func (k *KCPListener) Send(sess *kcp.UDPSession, msg interface{}) error {
+	if k.opts.codec == nil {
+		return ErrNoCodec
+	}
+	return Net.WriteMessage(sess, k.opts.codec, msg)
+}
+
+// readLoop 单会话读循环：按帧重组后投递，帧长度非法时关闭会话；退出时发布 SessionClosed
+func (k *KCPListener) readLoop(s *Session) {
+	defer k.wg.Done()
+	defer func() {
+		reason := CloseReasonRemote
+		if k.ctx.Err() != nil {
+			reason = CloseReasonShutdown
+		}
+		s.close(reason)
+		k.publishClosed(s)
+	}()
+	rb := netBuffers.Get(readBufferSize)
+	defer rb.Release()
+	data := rb.B
+	frames := Net.NewReassembler(k.opts.maxFrame)
+	frames.SetBufferPool(netBuffers)
+	limiter := s.limiter
+	for k.ctx.Err() == nil {
+		n, err := s.sess.Read(data)
+		if err != nil {
+			return
+		}
+		s.counters.read(n)
+		s.regionTraffic(false, n)
+		now := time.Now()
+		s.hb.Received(now)
+		complete, err := frames.Feed(data[:n])
+		if limiter != nil {
+			var ok bool
+			if complete, ok = limiter.filter(s, n, complete, now); !ok {
+				s.close(CloseReasonRateLimit)
+				return
+			}
+		}
+		complete, xerr := k.opts.compression.ExpandWith(complete, s.Dictionaries())
+		if deliverFrames(k.messages, k.opts.codec, k.opts.gateway, s.sess, complete, s.hb, s, false) > 0 {
+			s.lastMessage.Store(now.UnixNano())
+		}
+		if err != nil || xerr != nil {
+			s.close(CloseReasonFrame)
+			return
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package Actor
+
+import (
+	"errors"
+	"testing"
+	"zdopt/ZdoptServer/Net"
+)
+
+func TestMessageDoubleReleaseReturnsOnce(t *testing.T) {
+	m := newMessage()
+	m.Parse([]byte("payload"))
+	m.Release()
+	m.Release()
+	a, b := newMessage(), newMessage()
+	defer a.Release()
+	defer b.Release()
+	if a == b {
+		t.Fatal("double release put the same message into the pool twice")
+	}
+}
+
+func TestDispatchKeepsDeadLetteredMessage(t *testing.T) {
+	s := NewSystem()
+	defer s.Stop()
+	r := NewMessageRouter(s)
+	if err := r.Route(7, "missing"); err != nil {
+		t.Fatal(err)
+	}
+	msg := newMessage()
+	msg.ID = 7
+	msg.Parse([]byte("payload"))
+	if err := r.Dispatch(msg); !errors.Is(err, ErrNameNotFound) {
+		t.Fatalf("dispatch = %v, want ErrNameNotFound", err)
+	}
+	recent := s.DeadLetters().Recent()
+	if len(recent) == 0 || recent[len(recent)-1].Message != msg {
+		t.Fatal("message not recorded as dead letter")
+	}
+	// 死信持有的消息未被释放，内容仍可读取
+	if msg.ID != 7 || string(msg.Data) != "payload" {
+		t.Fatalf("dead-lettered message was released: id=%d data=%q", msg.ID, msg.Data)
+	}
+}
+
+// BenchmarkReadPath 单次读取到消费方处理完的分配：pooled 为当前的池化缓冲与消息对象，
+// alloc 为改造前每次读取分配读缓冲、Parse 再复制一次负载的做法
+func BenchmarkReadPath(b *testing.B) {
+	packet := Net.AppendFrame(nil, 1, make([]byte, 512))
+	messages := make(chan interface{}, 1)
+
+	b.Run("pooled", func(b *testing.B) {
+		frames := Net.NewReassembler(0)
+		frames.SetBufferPool(netBuffers)
+		rb := netBuffers.Get(readBufferSize)
+		defer rb.Release()
+		b.ReportAllocs()
+		b.SetBytes(int64(len(packet)))
+		for i := 0; i < b.N; i++ {
+			n := copy(rb.B, packet)
+			complete, err := frames.Feed(rb.B[:n])
+			if err != nil {
+				b.Fatal(err)
+			}
+			deliverFrames(messages, nil, nil, nil, complete, nil, nil, false)
+			(<-messages).(*Message).Release()
+		}
+	})
+
+	b.Run("alloc", func(b *testing.B) {
+		frames := Net.NewReassembler(0)
+		b.ReportAllocs()
+		b.SetBytes(int64(len(packet)))
+		for i := 0; i < b.N; i++ {
+			data := make([]byte, readBufferSize)
+			n := copy(data, packet)
+			complete, err := frames.Feed(data[:n])
+			if err != nil {
+				b.Fatal(err)
+			}
+			for _, f := range complete {
+				m := &Message{ID: f.ID}
+				m.Data = append([]byte(nil), f.Payload...)
+				messages <- m
+				<-messages
+			}
+		}
+	})
+}
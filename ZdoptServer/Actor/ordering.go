@@ -0,0 +1,118 @@
+package Actor
+
+//ordering.go
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"zdopt/ZdoptServer/Limit"
+)
+
+// ProcessingMode 批量消息的处理方式
+type ProcessingMode int
+
+const (
+	Concurrent ProcessingMode = iota // 每条消息一个协程并发处理（默认，与原有行为一致），不保证顺序
+	Sequential                       // 按到达顺序逐条处理
+	Keyed                            // 同一键（默认为发送者）的消息按到达顺序处理，不同键之间并发
+)
+
+var processingModeNames = [...]string{"concurrent", "sequential", "keyed"}
+
+func (m ProcessingMode) String() string {
+	if m >= 0 && int(m) < len(processingModeNames) {
+		return processingModeNames[m]
+	}
+	return "ProcessingMode(" + strconv.Itoa(int(m)) + ")"
+}
+
+// ParseProcessingMode 解析配置中的处理方式名称，空串为 Concurrent
+func ParseProcessingMode(s string) (ProcessingMode, error) {
+	if s == "" {
+		return Concurrent, nil
+	}
+	for i, name := range processingModeNames {
+		if strings.EqualFold(s, name) {
+			return ProcessingMode(i), nil
+		}
+	}
+	return Concurrent, fmt.Errorf("unknown processing mode %q", s)
+}
+
+// WithProcessingMode 设置批量消息的处理方式
+func WithProcessingMode(mode ProcessingMode) BaseActorOption {
+	return func(o *baseActorOptions) {
+		o.mode = mode
+	}
+}
+
+// WithOrderingKey 使用 Keyed 模式并按 key 提取排序键（如实体 ID），参数为拆信封后的消息；
+// key 返回空串时按发送者排序
+func WithOrderingKey(key KeyFunc) BaseActorOption {
+	return func(o *baseActorOptions) {
+		o.mode = Keyed
+		o.orderingKey = key
+	}
+}
+
+// batchSequential 逐条处理
+func (a *BaseActor) batchSequential(msgs []interface{}) {
+	for _, msg := range msgs {
+		a.dispatchSafe(msg)
+	}
+}
+
+// batchKeyed 按排序键分组，组内顺序处理，组间并发
+func (a *BaseActor) batchKeyed(msgs []interface{}) {
+	if len(msgs) == 1 {
+		a.dispatchSafe(msgs[0])
+		return
+	}
+	groups := make(map[string][]interface{})
+	order := make([]string, 0, len(msgs))
+	for _, msg := range msgs {
+		key := a.orderingKeyOf(msg)
+		if _, ok := groups[key]; !ok {
+			order = append(order, key)
+		}
+		groups[key] = append(groups[key], msg)
+	}
+	if len(order) == 1 {
+		a.batchSequential(msgs)
+		return
+	}
+
+	var wg sync.WaitGroup
+	for _, key := range order {
+		wg.Add(1)
+		group := groups[key]
+		Limit.Go("actor.batch", func() {
+			defer wg.Done()
+			a.batchSequential(group)
+		})
+	}
+	wg.Wait()
+}
+
+// orderingKeyOf 消息的排序键：自定义键优先，其次为发送者 ID；闭包任务与无信封消息共用一个键
+func (a *BaseActor) orderingKeyOf(msg interface{}) string {
+	payload, env := unwrap(msg)
+	if a.orderingKey != nil {
+		if _, isTask := payload.(Task); !isTask {
+			if key := a.orderingKey(payload); key != "" {
+				return key
+			}
+		}
+	}
+	if env != nil {
+		return "sender:" + strconv.FormatInt(env.Sender, 10)
+	}
+	return ""
+}
+
+// dispatchSafe 分发单条消息，panic 按 recoverPanic 处理
+func (a *BaseActor) dispatchSafe(msg interface{}) {
+	defer a.recoverPanic()
+	a.dispatch(msg)
+}
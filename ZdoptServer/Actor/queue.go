@@ -0,0 +1,155 @@
+package Actor
+
+//queue.go
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// queueCell 环形缓冲区的槽位：seq 等于写入位置时可写，等于写入位置+1 时可读
+type queueCell struct {
+	seq atomic.Uint64
+	msg interface{}
+}
+
+// MessageQueue 有界无锁环形队列（Vyukov 算法）：多生产者并发写入，消费者轮询 Dequeue，
+// 队列空时在 Ready 上等待；生产者可在 EnqueueWait 上阻塞等待空位
+type MessageQueue struct {
+	_     [64]byte
+	tail  atomic.Uint64 // 下一个写入位置
+	_     [56]byte
+	head  atomic.Uint64 // 下一个读取位置
+	_     [56]byte
+	cells []queueCell
+	mask  uint64
+
+	ready   chan struct{} // 有新消息时非阻塞地放入一个令牌
+	waiters atomic.Int32  // 阻塞等待空位的生产者数
+	spaceMu sync.Mutex
+	space   chan struct{} // 出队后关闭并替换，唤醒全部等待空位的生产者
+}
+
+// NewMessageQueue 创建容量不小于 size 的队列（向上取 2 的幂，最小为 2）
+func NewMessageQueue(size uint64) *MessageQueue {
+	n := uint64(2)
+	for n < size {
+		n <<= 1
+	}
+	q := &MessageQueue{
+		cells: make([]queueCell, n),
+		mask:  n - 1,
+		ready: make(chan struct{}, 1),
+		space: make(chan struct{}),
+	}
+	for i := range q.cells {
+		q.cells[i].seq.Store(uint64(i))
+	}
+	return q
+}
+
+// Enqueue 写入消息，队列满时返回 false
+func (q *MessageQueue) Enqueue(msg interface{}) bool {
+	pos := q.tail.Load()
+	for {
+		c := &q.cells[pos&q.mask]
+		switch dif := int64(c.seq.Load() - pos); {
+		case dif == 0:
+			if q.tail.CompareAndSwap(pos, pos+1) {
+				c.msg = msg
+				c.seq.Store(pos + 1)
+				q.Notify()
+				return true
+			}
+			pos = q.tail.Load()
+		case dif < 0:
+			return false
+		default:
+			pos = q.tail.Load()
+		}
+	}
+}
+
+// EnqueueWait 写入消息，队列满时阻塞等待空位；done 关闭时放弃并返回 false（nil 表示一直等待）
+func (q *MessageQueue) EnqueueWait(done <-chan struct{}, msg interface{}) bool {
+	return q.enqueueWait(done, nil, msg)
+}
+
+// enqueueWait 同 EnqueueWait，cancel 关闭时同样放弃（Ask 的 ctx 结束）
+func (q *MessageQueue) enqueueWait(done, cancel <-chan struct{}, msg interface{}) bool {
+	if q.Enqueue(msg) {
+		return true
+	}
+	q.waiters.Add(1)
+	defer q.waiters.Add(-1)
+	for {
+		// 先取通知通道再重试，保证重试失败后的出队一定能唤醒本次等待
+		q.spaceMu.Lock()
+		space := q.space
+		q.spaceMu.Unlock()
+		if q.Enqueue(msg) {
+			return true
+		}
+		select {
+		case <-space:
+		case <-done:
+			return false
+		case <-cancel:
+			return false
+		}
+	}
+}
+
+// Dequeue 取出最早的消息，队列空时返回 false。丢弃最早消息的生产者也可调用
+func (q *MessageQueue) Dequeue() (interface{}, bool) {
+	pos := q.head.Load()
+	for {
+		c := &q.cells[pos&q.mask]
+		switch dif := int64(c.seq.Load() - (pos + 1)); {
+		case dif == 0:
+			if q.head.CompareAndSwap(pos, pos+1) {
+				msg := c.msg
+				c.msg = nil
+				c.seq.Store(pos + q.mask + 1)
+				if q.waiters.Load() > 0 {
+					q.spaceMu.Lock()
+					close(q.space)
+					q.space = make(chan struct{})
+					q.spaceMu.Unlock()
+				}
+				return msg, true
+			}
+			pos = q.head.Load()
+		case dif < 0:
+			return nil, false
+		default:
+			pos = q.head.Load()
+		}
+	}
+}
+
+// Ready 有新消息（或 Notify）时可读，消费者在队列空时等待
+func (q *MessageQueue) Ready() <-chan struct{} {
+	return q.ready
+}
+
+// Notify 唤醒等待中的消费者
+func (q *MessageQueue) Notify() {
+	select {
+	case q.ready <- struct{}{}:
+	default:
+	}
+}
+
+// Len 当前消息数（并发写入时为近似值）
+func (q *MessageQueue) Len() int {
+	n := int64(q.tail.Load() - q.head.Load())
+	if n < 0 {
+		return 0
+	}
+	return int(min(n, int64(len(q.cells))))
+}
+
+// Cap 队列容量
+func (q *MessageQueue) Cap() int {
+	return len(q.cells)
+}
@@ -0,0 +1,196 @@
+package Actor
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMessageQueueCapacity(t *testing.T) {
+	for size, want := range map[uint64]int{0: 2, 1: 2, 2: 2, 3: 4, 100: 128, 1024: 1024} {
+		if got := NewMessageQueue(size).Cap(); got != want {
+			t.Errorf("NewMessageQueue(%d).Cap() = %d, want %d", size, got, want)
+		}
+	}
+}
+
+func TestMessageQueueFIFOAndWraparound(t *testing.T) {
+	q := NewMessageQueue(4)
+	next := 0
+	// 多轮填满再取空，覆盖位置回绕
+	for round := 0; round < 10; round++ {
+		for i := 0; i < q.Cap(); i++ {
+			if !q.Enqueue(round*q.Cap() + i) {
+				t.Fatalf("round %d: enqueue %d failed before full", round, i)
+			}
+		}
+		if q.Enqueue(-1) {
+			t.Fatalf("round %d: enqueue succeeded on full queue", round)
+		}
+		if q.Len() != q.Cap() {
+			t.Fatalf("round %d: Len = %d on full queue", round, q.Len())
+		}
+		for i := 0; i < q.Cap(); i++ {
+			v, ok := q.Dequeue()
+			if !ok || v.(int) != next {
+				t.Fatalf("round %d: dequeue = %v, %v, want %d", round, v, ok, next)
+			}
+			next++
+		}
+		if _, ok := q.Dequeue(); ok {
+			t.Fatalf("round %d: dequeue succeeded on empty queue", round)
+		}
+	}
+}
+
+func TestMessageQueueConcurrentProducers(t *testing.T) {
+	const producers, perProducer = 8, 5000
+	q := NewMessageQueue(64)
+	type item struct{ producer, seq int }
+
+	var wg sync.WaitGroup
+	for p := 0; p < producers; p++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perProducer; i++ {
+				q.EnqueueWait(nil, item{p, i})
+			}
+		}()
+	}
+
+	last := make([]int, producers)
+	for i := range last {
+		last[i] = -1
+	}
+	for received := 0; received < producers*perProducer; {
+		v, ok := q.Dequeue()
+		if !ok {
+			select {
+			case <-q.Ready():
+			case <-time.After(time.Second):
+				t.Fatalf("consumer starved after %d messages", received)
+			}
+			continue
+		}
+		it := v.(item)
+		if it.seq != last[it.producer]+1 {
+			t.Fatalf("producer %d: got seq %d after %d", it.producer, it.seq, last[it.producer])
+		}
+		last[it.producer] = it.seq
+		received++
+	}
+	wg.Wait()
+	if q.Len() != 0 {
+		t.Fatalf("Len = %d after draining", q.Len())
+	}
+}
+
+func TestMessageQueueEnqueueWait(t *testing.T) {
+	q := NewMessageQueue(2)
+	q.Enqueue(1)
+	q.Enqueue(2)
+
+	// done 关闭时放弃等待
+	done := make(chan struct{})
+	close(done)
+	if q.EnqueueWait(done, 3) {
+		t.Fatal("EnqueueWait succeeded on full queue with closed done")
+	}
+
+	// 出队后唤醒等待空位的生产者
+	enqueued := make(chan bool)
+	go func() { enqueued <- q.EnqueueWait(nil, 3) }()
+	select {
+	case <-enqueued:
+		t.Fatal("EnqueueWait returned while queue was full")
+	case <-time.After(20 * time.Millisecond):
+	}
+	if v, _ := q.Dequeue(); v.(int) != 1 {
+		t.Fatalf("dequeue = %v", v)
+	}
+	select {
+	case ok := <-enqueued:
+		if !ok {
+			t.Fatal("EnqueueWait failed after space freed")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("EnqueueWait not woken by dequeue")
+	}
+	for _, want := range []int{2, 3} {
+		if v, ok := q.Dequeue(); !ok || v.(int) != want {
+			t.Fatalf("dequeue = %v, %v, want %d", v, ok, want)
+		}
+	}
+}
+
+func TestMessageQueueReady(t *testing.T) {
+	q := NewMessageQueue(4)
+	select {
+	case <-q.Ready():
+		t.Fatal("Ready signalled on empty queue")
+	default:
+	}
+	q.Enqueue(1)
+	q.Enqueue(2) // 令牌不累积
+	select {
+	case <-q.Ready():
+	default:
+		t.Fatal("Ready not signalled after enqueue")
+	}
+	select {
+	case <-q.Ready():
+		t.Fatal("Ready signalled twice for one wakeup")
+	default:
+	}
+}
+
+// BenchmarkMessageQueue 多生产者单消费者吞吐，与同容量的带缓冲通道对比
+func BenchmarkMessageQueue(b *testing.B) {
+	const size = 1024
+	b.Run("queue", func(b *testing.B) {
+		q := NewMessageQueue(size)
+		stop := make(chan struct{})
+		consumed := make(chan struct{})
+		go func() {
+			defer close(consumed)
+			for {
+				if _, ok := q.Dequeue(); ok {
+					continue
+				}
+				select {
+				case <-q.Ready():
+				case <-stop:
+					return
+				}
+			}
+		}()
+		b.ReportAllocs()
+		b.SetParallelism(8)
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				q.EnqueueWait(nil, struct{}{})
+			}
+		})
+		close(stop)
+		<-consumed
+	})
+	b.Run("channel", func(b *testing.B) {
+		ch := make(chan interface{}, size)
+		consumed := make(chan struct{})
+		go func() {
+			defer close(consumed)
+			for range ch {
+			}
+		}()
+		b.ReportAllocs()
+		b.SetParallelism(8)
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				ch <- struct{}{}
+			}
+		})
+		close(ch)
+		<-consumed
+	})
+}
@@ -0,0 +1,197 @@
+package Actor
+
+//ratelimit.go
+import (
+	"errors"
+	"expvar"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var (
+	ErrRateLimited = errors.New("message rate limited")
+
+	rateLimitedCount = expvar.NewInt("actors.ratelimited")
+	rateLimitedTypes = expvar.NewMap("actors.ratelimited.types")
+)
+
+// RateLimitedError 被限流时回给发送者的类型化错误
+type RateLimitedError struct {
+	MessageType string
+	Sender      int64
+	RetryAfter  time.Duration
+}
+
+func (e *RateLimitedError) Error() string {
+	return fmt.Sprintf("%s from %d rate limited, retry after %v", e.MessageType, e.Sender, e.RetryAfter)
+}
+
+func (e *RateLimitedError) Is(target error) bool {
+	return target == ErrRateLimited
+}
+
+// tokenBucket 单个发送者的令牌桶
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// rateLimiter 按键（发送者或消息类型）分桶的令牌桶限流器
+type rateLimiter[K comparable] struct {
+	mu      sync.Mutex
+	rate    float64 // 每秒补充令牌数
+	burst   float64
+	buckets map[K]*tokenBucket
+	calls   int
+}
+
+func newRateLimiter[K comparable](n int, per time.Duration) *rateLimiter[K] {
+	return &rateLimiter[K]{
+		rate:    float64(n) / per.Seconds(),
+		burst:   float64(n),
+		buckets: make(map[K]*tokenBucket),
+	}
+}
+
+// allow 消耗一个令牌，失败时返回建议的重试等待时间
+func (l *rateLimiter[K]) allow(sender K, now time.Time) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.calls++
+	if l.calls%1024 == 0 {
+		l.prune(now)
+	}
+
+	b, ok := l.buckets[sender]
+	if !ok {
+		b = &tokenBucket{tokens: l.burst, last: now}
+		l.buckets[sender] = b
+	}
+
+	b.tokens += now.Sub(b.last).Seconds() * l.rate
+	if b.tokens > l.burst {
+		b.tokens = l.burst
+	}
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	return false, time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+}
+
+// prune 清理已回满的空闲桶，避免发送者离开后桶无限增长
+func (l *rateLimiter[K]) prune(now time.Time) {
+	for sender, b := range l.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*l.rate >= l.burst {
+			delete(l.buckets, sender)
+		}
+	}
+}
+
+// RateLimitKey Actor级限流的分桶方式
+type RateLimitKey int
+
+const (
+	LimitBySender RateLimitKey = iota // 每个发送者一个桶（非信封消息的发送者为 0）
+	LimitByType                       // 每种消息类型一个桶
+)
+
+func (k RateLimitKey) String() string {
+	switch k {
+	case LimitBySender:
+		return "sender"
+	case LimitByType:
+		return "type"
+	default:
+		return fmt.Sprintf("RateLimitKey(%d)", int(k))
+	}
+}
+
+// inboundLimit Actor级入站限流，在消息进入邮箱前检查
+type inboundLimit struct {
+	by      RateLimitKey
+	senders *rateLimiter[int64]
+	types   *rateLimiter[string]
+}
+
+// WithInboundRateLimit 为Actor设置入站限流：按 by 分桶，每个桶在 per 时间内最多接收 n 条消息。
+// 超出的消息不进入邮箱，直接进入死信（原因 rate_limited），避免单个客户端刷屏占满邮箱、拖慢整个组
+func WithInboundRateLimit(n int, per time.Duration, by RateLimitKey) BaseActorOption {
+	return func(o *baseActorOptions) {
+		if n <= 0 || per <= 0 {
+			o.inbound = nil
+			return
+		}
+		l := &inboundLimit{by: by}
+		if by == LimitByType {
+			l.types = newRateLimiter[string](n, per)
+		} else {
+			l.senders = newRateLimiter[int64](n, per)
+		}
+		o.inbound = l
+	}
+}
+
+// admitInbound 入站限流检查，超限时记入死信并通知发送者，返回类型化错误
+func (a *BaseActor) admitInbound(msg interface{}) error {
+	payload, env := unwrap(msg)
+	msgType := getMessageType(payload)
+	now := time.Now()
+
+	var (
+		allowed    bool
+		retryAfter time.Duration
+	)
+	if a.inbound.types != nil {
+		allowed, retryAfter = a.inbound.types.allow(msgType, now)
+	} else {
+		var sender int64
+		if env != nil {
+			sender = env.Sender
+		}
+		allowed, retryAfter = a.inbound.senders.allow(sender, now)
+	}
+	if allowed {
+		return nil
+	}
+	a.counters.rateLimited.Add(1)
+	a.deadLetter(msg, RateLimited)
+	a.rejectRateLimited(msgType, env, retryAfter)
+
+	rejection := &RateLimitedError{MessageType: msgType, RetryAfter: retryAfter}
+	if env != nil {
+		rejection.Sender = env.Sender
+	}
+	return rejection
+}
+
+// rejectRateLimited 记录限流指标，并把类型化错误回给发送者（Ask 请求以该错误完成）
+func (a *BaseActor) rejectRateLimited(msgType string, env *Envelope, retryAfter time.Duration) {
+	rateLimitedCount.Add(1)
+	rateLimitedTypes.Add(msgType, 1)
+
+	if env == nil {
+		return
+	}
+	rejection := &RateLimitedError{
+		MessageType: msgType,
+		Sender:      env.Sender,
+		RetryAfter:  retryAfter,
+	}
+	if env.future != nil {
+		resolveRejected(env, rejection)
+		return
+	}
+	if env.ReplyTo == nil {
+		return
+	}
+	env.ReplyTo.Post(&Envelope{
+		Message:  rejection,
+		Sender:   a.id,
+		Priority: env.Priority,
+	})
+}
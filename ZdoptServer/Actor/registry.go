@@ -0,0 +1,127 @@
+package Actor
+
+//registry.go
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+)
+
+var (
+	ErrActorExists   = errors.New("actor id already registered")
+	ErrActorNotFound = errors.New("actor not found")
+)
+
+// mailboxOwner 内嵌 *BaseActor 的Actor，Send 经其邮箱投递
+type mailboxOwner interface {
+	baseActor() *BaseActor
+}
+
+func (a *BaseActor) baseActor() *BaseActor {
+	return a
+}
+
+// SetID 设置Actor ID（注册到系统时自动设置）
+func (a *BaseActor) SetID(id int64) {
+	a.id = id
+}
+
+// NextID 分配一个系统内唯一的Actor ID
+func (s *System) NextID() int64 {
+	return atomic.AddInt64(&s.lastID, 1)
+}
+
+// Register 按 ID 登记Actor，供其他子系统（定时器、网络层等）不持有指针即可投递消息
+func (s *System) Register(id int64, a Actor) error {
+	if _, loaded := s.actors.LoadOrStore(id, a); loaded {
+		return fmt.Errorf("%w: %d", ErrActorExists, id)
+	}
+	if base := baseOf(a); base != nil {
+		base.SetID(id)
+		base.attach(s)
+	}
+	return nil
+}
+
+// Unregister 注销Actor，并使各解析缓存中的该 ID 失效
+func (s *System) Unregister(id int64) {
+	s.actors.Delete(id)
+	s.Invalidate(id)
+}
+
+// MigrateActor Actor 迁移后以新 ID 接管旧 ID：注销 fromID 并让旧实例（在本节点时）退订，以 toID 登记 a，
+// 再把事件总线订阅（含过滤条件）从 fromID 转移到 toID 并重新建立。
+// 跨节点迁移时 subs 为源节点 Subscriptions(fromID) 导出的订阅描述，先按 fromID 导入本节点登记表
+func (s *System) MigrateActor(fromID, toID int64, a Actor, subs ...Subscription) error {
+	old, hasOld := s.Lookup(fromID)
+	if hasOld {
+		s.Unregister(fromID)
+	}
+	if err := s.Register(toID, a); err != nil {
+		if hasOld {
+			s.actors.Store(fromID, old)
+		}
+		return err
+	}
+	if hasOld && old != a {
+		if base := baseOf(old); base != nil {
+			s.bus.unsubscribeActor(base)
+		}
+	}
+	for _, sub := range subs {
+		sub.ActorID = fromID
+		s.subscriptions.Add(sub)
+	}
+	return s.subscriptions.Migrate(fromID, toID)
+}
+
+// Lookup 按 ID 查找Actor
+func (s *System) Lookup(id int64) (Actor, bool) {
+	v, ok := s.actors.Load(id)
+	if !ok {
+		return nil, false
+	}
+	return v.(Actor), true
+}
+
+// Send 按 ID 投递消息：内嵌 BaseActor 的Actor按邮箱策略进入其邮箱，其余直接调用 Receive
+func (s *System) Send(id int64, msg interface{}) error {
+	if s.stopping.Load() {
+		return ErrSystemStopping
+	}
+	a, ok := s.Lookup(id)
+	if !ok {
+		s.deadLetters.publish(DeadLetter{Target: id, Message: msg, Reason: ActorNotFound})
+		return fmt.Errorf("%w: %d", ErrActorNotFound, id)
+	}
+	if base := baseOf(a); base != nil {
+		return base.Send(&Envelope{Message: msg, Priority: base.Priority()})
+	}
+	a.Receive(msg)
+	return nil
+}
+
+// SendFrom 以 sender 的身份按 ID 投递（携带回复地址并继承优先级）
+func (s *System) SendFrom(sender *BaseActor, id int64, msg interface{}) error {
+	if s.stopping.Load() {
+		return ErrSystemStopping
+	}
+	a, ok := s.Lookup(id)
+	if !ok {
+		s.deadLetters.publish(DeadLetter{Target: id, Message: msg, Reason: ActorNotFound})
+		return fmt.Errorf("%w: %d", ErrActorNotFound, id)
+	}
+	if base := baseOf(a); base != nil {
+		sender.Tell(base, msg)
+		return nil
+	}
+	a.Receive(msg)
+	return nil
+}
+
+func baseOf(a Actor) *BaseActor {
+	if owner, ok := a.(mailboxOwner); ok {
+		return owner.baseActor()
+	}
+	return nil
+}
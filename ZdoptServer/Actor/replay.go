@@ -0,0 +1,250 @@
+package Actor
+
+//replay.go
+import (
+	"errors"
+	"expvar"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	ErrDuplicateMessage = errors.New("duplicate message dropped by replay protection")
+
+	replayDuplicates    = expvar.NewInt("actors.replay.duplicates")
+	replayGaps          = expvar.NewInt("actors.replay.gaps")
+	replayResent        = expvar.NewInt("actors.replay.resent")
+	replayUnrecoverable = expvar.NewInt("actors.replay.unrecoverable") // 请求重发时已不在发送端缓冲中的消息数
+	replayEvicted       = expvar.NewInt("actors.replay.evicted")
+)
+
+// DeliveryMode 链路投递语义
+type DeliveryMode int
+
+const (
+	AtMostOnce  DeliveryMode = iota // 丢弃重复消息，缺口视为丢失
+	AtLeastOnce                     // 丢弃重复消息，发现缺口时向发送者请求重发（发送者须开启 EnableResendBuffer）
+)
+
+// replayWindowSize 每个发送者保留的乱序窗口大小，同时是发送端重发缓冲的默认容量
+const replayWindowSize = 64
+
+// defaultReplayIdle 发送者空闲多久后回收其接收窗口
+const defaultReplayIdle = 10 * time.Minute
+
+// ResendRequest 接收方发现序号缺口时回给发送者的重发请求（闭区间）
+type ResendRequest struct {
+	Target   int64 // 请求重发的接收方
+	From, To uint64
+}
+
+// ReplayConfig 接收端重放保护配置
+type ReplayConfig struct {
+	Mode DeliveryMode
+	// IdleTimeout 发送者超过该时长没有消息时回收其窗口，默认 10 分钟；
+	// 回收后该发送者的旧消息再次到达时无法识别为重复
+	IdleTimeout time.Duration
+}
+
+// Origin 经 Rpc 到达的消息的远端发送方：所在节点、Actor ID 与其发往本目标的序号，Seq 为 0 时不参与重放保护
+type Origin struct {
+	Node   string
+	Sender int64
+	Seq    uint64
+}
+
+// stamp 把远端发送方写入信封，未给出节点时不标记
+func (o Origin) stamp(env *Envelope) {
+	if o.Node == "" {
+		return
+	}
+	env.Node, env.Sender, env.Seq = o.Node, o.Sender, o.Seq
+}
+
+// replayKey 重放窗口按发送方区分，远端发送方的 ID 只在其节点内唯一
+type replayKey struct {
+	node   string
+	sender int64
+}
+
+// replayWindow 单个发送者的接收窗口：最大序号 + 其下 64 个序号的位图
+type replayWindow struct {
+	max  uint64
+	seen uint64 // 第 i 位表示 max-i 已收到
+	last time.Time
+}
+
+// replayGuard 按发送者去重并检测缺口
+type replayGuard struct {
+	mu        sync.Mutex
+	mode      DeliveryMode
+	idle      time.Duration
+	lastSweep time.Time
+	windows   map[replayKey]*replayWindow
+}
+
+// EnableReplayProtection 为该Actor开启重放保护：带序号的信封按发送者去重
+func (a *BaseActor) EnableReplayProtection(cfg ReplayConfig) {
+	if cfg.IdleTimeout <= 0 {
+		cfg.IdleTimeout = defaultReplayIdle
+	}
+	a.replay.Store(&replayGuard{
+		mode:      cfg.Mode,
+		idle:      cfg.IdleTimeout,
+		lastSweep: time.Now(),
+		windows:   make(map[replayKey]*replayWindow),
+	})
+}
+
+// nextSeq 本Actor发往 target 的下一个序号（未登记 ID 的发送者不编号）
+func (a *BaseActor) nextSeq(target *BaseActor) uint64 {
+	if a.id == 0 {
+		return 0
+	}
+	v, _ := a.sendSeq.LoadOrStore(target, new(uint64))
+	return atomic.AddUint64(v.(*uint64), 1)
+}
+
+// stampSeq 为发往 target 的信封编号，开启重发缓冲时同时留存（Ask 请求的结果已由 Future 告知调用方，不留存）
+func (a *BaseActor) stampSeq(target *BaseActor, env *Envelope) {
+	env.Seq = a.nextSeq(target)
+	if buf := a.resend.Load(); buf != nil && env.Seq != 0 && env.future == nil {
+		buf.record(target, env)
+	}
+}
+
+// accept 检查信封序号，重复消息返回 false；缺口在 AtLeastOnce 下向发送者请求重发
+func (g *replayGuard) accept(self *BaseActor, env *Envelope) bool {
+	if env == nil || env.Seq == 0 || env.Sender == 0 {
+		return true
+	}
+
+	now := time.Now()
+	key := replayKey{node: env.Node, sender: env.Sender}
+	g.mu.Lock()
+	g.sweep(now)
+	w, ok := g.windows[key]
+	if !ok {
+		w = &replayWindow{}
+		g.windows[key] = w
+	}
+	w.last = now
+
+	var gapFrom, gapTo uint64
+	switch s := env.Seq; {
+	case s > w.max:
+		if s > w.max+1 {
+			gapFrom, gapTo = w.max+1, s-1
+		}
+		shift := s - w.max
+		if shift >= replayWindowSize {
+			w.seen = 0
+		} else {
+			w.seen <<= shift
+		}
+		w.seen |= 1
+		w.max = s
+	case w.max-s >= replayWindowSize:
+		// 早于窗口，无法判断是否处理过，按重复丢弃
+		g.mu.Unlock()
+		replayDuplicates.Add(1)
+		return false
+	default:
+		bit := uint64(1) << (w.max - s)
+		if w.seen&bit != 0 {
+			g.mu.Unlock()
+			replayDuplicates.Add(1)
+			return false
+		}
+		w.seen |= bit
+	}
+	mode := g.mode
+	g.mu.Unlock()
+
+	if gapTo > 0 {
+		replayGaps.Add(1)
+		if mode == AtLeastOnce && env.ReplyTo != nil {
+			env.ReplyTo.Post(&Envelope{
+				Message:  &ResendRequest{Target: self.id, From: gapFrom, To: gapTo},
+				Sender:   self.id,
+				ReplyTo:  self,
+				Priority: env.Priority,
+			})
+		}
+	}
+	return true
+}
+
+// sweep 回收空闲发送者的窗口，每半个空闲期至多扫描一次（调用方持有 mu）
+func (g *replayGuard) sweep(now time.Time) {
+	if now.Sub(g.lastSweep) < g.idle/2 {
+		return
+	}
+	g.lastSweep = now
+	for key, w := range g.windows {
+		if now.Sub(w.last) >= g.idle {
+			delete(g.windows, key)
+			replayEvicted.Add(1)
+		}
+	}
+}
+
+// resendBuffer 发送端按接收方保留最近发出的带序号信封，收到 ResendRequest 时重发
+type resendBuffer struct {
+	mu   sync.Mutex
+	size int
+	sent map[*BaseActor][]*Envelope // 按序号递增
+}
+
+// EnableResendBuffer 开启发送端重发缓冲：每个接收方保留最近 size 条（<=0 时取接收窗口大小 64）经 Tell 发出的消息，
+// 接收方以 AtLeastOnce 重放保护发现缺口时按 ResendRequest 从缓冲重发；未开启时 ResendRequest 按普通消息交给处理器
+func (a *BaseActor) EnableResendBuffer(size int) {
+	if size <= 0 {
+		size = replayWindowSize
+	}
+	a.resend.Store(&resendBuffer{size: size, sent: make(map[*BaseActor][]*Envelope)})
+}
+
+// record 留存信封副本，新增接收方时顺带清理已停止的接收方
+func (b *resendBuffer) record(target *BaseActor, env *Envelope) {
+	cp := *env
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	list, ok := b.sent[target]
+	if !ok {
+		for t := range b.sent {
+			if t.stopped() {
+				delete(b.sent, t)
+			}
+		}
+	}
+	if len(list) >= b.size {
+		list[0] = nil
+		list = list[1:]
+	}
+	b.sent[target] = append(list, &cp)
+}
+
+// retransmit 把 [from, to] 内仍在缓冲中的信封重新投递给 target，返回重发条数
+func (b *resendBuffer) retransmit(target *BaseActor, from, to uint64) int {
+	b.mu.Lock()
+	var resend []*Envelope
+	for _, env := range b.sent[target] {
+		if env.Seq >= from && env.Seq <= to {
+			resend = append(resend, env)
+		}
+	}
+	b.mu.Unlock()
+
+	if missing := int64(to-from+1) - int64(len(resend)); missing > 0 {
+		replayUnrecoverable.Add(missing)
+	}
+	for _, env := range resend {
+		cp := *env
+		cp.seq = 0
+		target.Post(&cp)
+	}
+	replayResent.Add(int64(len(resend)))
+	return len(resend)
+}
@@ -0,0 +1,127 @@
+package Actor
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// recordingActor 把收到的 int 消息依次写入 got，以字符串回复（发送方不处理字符串，避免互相回复）
+type recordingActor struct {
+	*BaseActor
+	got chan int
+}
+
+func (a *recordingActor) Start()                     {}
+func (a *recordingActor) Stop()                      {}
+func (a *recordingActor) Update(delta time.Duration) {}
+func (a *recordingActor) Receive(msg interface{})    {}
+
+func newRecordingActor(t *testing.T, s *System) *recordingActor {
+	t.Helper()
+	a := &recordingActor{BaseActor: s.NewBaseActor(64, WithProcessingMode(Sequential)), got: make(chan int, 64)}
+	HandleAsk(a.BaseActor, func(_ *MessageContext, v int) (string, error) {
+		a.got <- v
+		return strconv.Itoa(v), nil
+	})
+	if err := s.Register(s.NextID(), a); err != nil {
+		t.Fatal(err)
+	}
+	s.AddGroupActors(1, []func() Actor{func() Actor { return a }})
+	return a
+}
+
+func (a *recordingActor) expect(t *testing.T, want ...int) {
+	t.Helper()
+	for _, w := range want {
+		select {
+		case v := <-a.got:
+			if v != w {
+				t.Fatalf("received %d, want %d", v, w)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("message %d never delivered", w)
+		}
+	}
+	select {
+	case v := <-a.got:
+		t.Fatalf("unexpected extra message %d", v)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestResendBufferFillsGap(t *testing.T) {
+	s := NewSystem()
+	defer s.Stop()
+	recv := newRecordingActor(t, s)
+	recv.EnableReplayProtection(ReplayConfig{Mode: AtLeastOnce})
+	sender := newRecordingActor(t, s)
+	sender.EnableResendBuffer(0)
+
+	sender.Tell(recv.BaseActor, 1)
+	// 第 2 条已编号但丢失在途中（如被 DropOldest 挤掉）
+	lost := &Envelope{Message: 2, Sender: sender.ID(), ReplyTo: sender.BaseActor}
+	sender.stampSeq(recv.BaseActor, lost)
+	sender.Tell(recv.BaseActor, 3)
+
+	// 接收方看到 3 时发现缺口，向发送方请求重发，发送方从缓冲补发 2；重发不会再次交给发送方自身的处理器
+	recv.expect(t, 1, 3, 2)
+	sender.expect(t)
+
+	// 重复到达的重发被丢弃
+	dup := &Envelope{Message: 2, Sender: sender.ID(), ReplyTo: sender.BaseActor, Seq: lost.Seq}
+	recv.Post(dup)
+	recv.expect(t)
+}
+
+func TestReplayDropsRemoteRedelivery(t *testing.T) {
+	s := NewSystem()
+	defer s.Stop()
+	recv := newRecordingActor(t, s)
+	recv.EnableReplayProtection(ReplayConfig{})
+	if err := s.RegisterName("recv", recv); err != nil {
+		t.Fatal(err)
+	}
+
+	from := Origin{Node: "node-a", Sender: 7, Seq: 1}
+	for i := 0; i < 2; i++ {
+		if err := s.SendNameFrom("recv", from, 1); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 另一节点上的同 ID 发送方是不同的发送方
+	if err := s.SendNameFrom("recv", Origin{Node: "node-b", Sender: 7, Seq: 1}, 2); err != nil {
+		t.Fatal(err)
+	}
+	recv.expect(t, 1, 2)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	from.Seq = 2
+	if v, err := s.AskNameFrom(ctx, "recv", from, 3); err != nil || v != "3" {
+		t.Fatalf("first ask = %v, %v", v, err)
+	}
+	if _, err := s.AskNameFrom(ctx, "recv", from, 3); !errors.Is(err, ErrDuplicateMessage) {
+		t.Fatalf("redelivered ask = %v, want ErrDuplicateMessage", err)
+	}
+	recv.expect(t, 3)
+}
+
+func TestReplayEvictsIdleWindows(t *testing.T) {
+	a := NewBaseActor(8)
+	a.EnableReplayProtection(ReplayConfig{IdleTimeout: 20 * time.Millisecond})
+	g := a.replay.Load()
+	for sender := int64(1); sender <= 3; sender++ {
+		g.accept(a, &Envelope{Sender: sender, Seq: 1})
+	}
+	time.Sleep(30 * time.Millisecond)
+	g.accept(a, &Envelope{Sender: 4, Seq: 1})
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if len(g.windows) != 1 {
+		t.Fatalf("%d windows kept, want only the active sender", len(g.windows))
+	}
+}
@@ -0,0 +1,215 @@
+package Actor
+
+//resolve.go
+import (
+	"expvar"
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+var resolveMetrics = expvar.NewMap("actors.resolve") // hits / misses / invalidations
+
+// resolveFlushEvery 本地命中计数累计到该值后汇总到全局指标，避免热循环中频繁原子操作
+const resolveFlushEvery = 1024
+
+// defaultResolveCapacity ResolveCache 默认容量
+const defaultResolveCapacity = 4096
+
+type resolved struct {
+	actor Actor
+	base  *BaseActor // 内嵌 BaseActor 时非 nil，用于检查是否已停止
+}
+
+// resolveInbox 系统向缓存投递的失效 ID，由缓存所属协程在下次解析时取出
+type resolveInbox struct {
+	mu    sync.Mutex
+	ids   []int64
+	all   bool
+	dirty atomic.Bool
+}
+
+func (in *resolveInbox) push(ids []int64) {
+	in.mu.Lock()
+	if !in.all {
+		in.ids = append(in.ids, ids...)
+	}
+	in.mu.Unlock()
+	in.dirty.Store(true)
+}
+
+func (in *resolveInbox) pushAll() {
+	in.mu.Lock()
+	in.all, in.ids = true, in.ids[:0]
+	in.mu.Unlock()
+	in.dirty.Store(true)
+}
+
+// ResolveStats 缓存命中统计
+type ResolveStats struct {
+	Hits          uint64
+	Misses        uint64
+	Invalidations uint64
+	Entries       int
+}
+
+// HitRate 命中率，未查询过时为 0
+func (s ResolveStats) HitRate() float64 {
+	if total := s.Hits + s.Misses; total > 0 {
+		return float64(s.Hits) / float64(total)
+	}
+	return 0
+}
+
+// ResolveCache 实体 ID 到Actor的解析缓存：每个分发协程（Group tick、Balancer worker）各持有一个，
+// 命中时不访问系统登记表。非并发安全，只能在创建它的协程中使用。
+// Actor注销、停止或经 Invalidate 迁移后对应条目失效，下次解析走登记表
+type ResolveCache struct {
+	sys      *System
+	entries  map[int64]resolved
+	capacity int
+	inbox    *resolveInbox
+	stats    ResolveStats
+	flushed  ResolveStats // 已汇总到全局指标的部分
+}
+
+// NewResolveCache 创建解析缓存，capacity <= 0 时使用默认容量；不再使用时调用 Close
+func (s *System) NewResolveCache(capacity int) *ResolveCache {
+	if capacity <= 0 {
+		capacity = defaultResolveCapacity
+	}
+	c := &ResolveCache{
+		sys:      s,
+		entries:  make(map[int64]resolved, min(capacity, 256)),
+		capacity: capacity,
+		inbox:    &resolveInbox{},
+	}
+	s.resolveCaches.Store(c.inbox, struct{}{})
+	return c
+}
+
+// Resolve 按 ID 解析Actor：先查缓存，未命中时查登记表并缓存结果（未找到的 ID 不缓存）
+func (c *ResolveCache) Resolve(id int64) (Actor, bool) {
+	if c.inbox.dirty.Load() {
+		c.applyInvalidations()
+	}
+	if e, ok := c.entries[id]; ok {
+		if e.base == nil || !e.base.stopped() {
+			c.stats.Hits++
+			c.maybeFlush()
+			return e.actor, true
+		}
+		delete(c.entries, id)
+		c.stats.Invalidations++
+	}
+	c.stats.Misses++
+	c.maybeFlush()
+	a, ok := c.sys.Lookup(id)
+	if !ok {
+		return nil, false
+	}
+	e := resolved{actor: a, base: baseOf(a)}
+	if e.base != nil && e.base.stopped() {
+		return a, true
+	}
+	if len(c.entries) >= c.capacity {
+		// 容量满时整体清空，热点 ID 会在随后的解析中重新进入
+		clear(c.entries)
+	}
+	c.entries[id] = e
+	return a, true
+}
+
+// Send 经缓存解析后投递，语义与 System.Send 相同
+func (c *ResolveCache) Send(id int64, msg interface{}) error {
+	if c.sys.stopping.Load() {
+		return ErrSystemStopping
+	}
+	a, ok := c.Resolve(id)
+	if !ok {
+		c.sys.deadLetters.publish(DeadLetter{Target: id, Message: msg, Reason: ActorNotFound})
+		return fmt.Errorf("%w: %d", ErrActorNotFound, id)
+	}
+	if base := baseOf(a); base != nil {
+		return base.Send(&Envelope{Message: msg, Priority: base.Priority()})
+	}
+	a.Receive(msg)
+	return nil
+}
+
+// Forget 丢弃本缓存中的条目（仅影响本缓存，跨缓存失效使用 System.Invalidate）
+func (c *ResolveCache) Forget(id int64) {
+	if _, ok := c.entries[id]; ok {
+		delete(c.entries, id)
+		c.stats.Invalidations++
+	}
+}
+
+// Stats 本缓存的命中统计
+func (c *ResolveCache) Stats() ResolveStats {
+	s := c.stats
+	s.Entries = len(c.entries)
+	return s
+}
+
+// Close 从系统注销，之后不再接收失效通知
+func (c *ResolveCache) Close() {
+	c.flush()
+	c.sys.resolveCaches.Delete(c.inbox)
+	clear(c.entries)
+}
+
+func (c *ResolveCache) applyInvalidations() {
+	in := c.inbox
+	in.mu.Lock()
+	in.dirty.Store(false)
+	if in.all {
+		c.stats.Invalidations += uint64(len(c.entries))
+		clear(c.entries)
+	} else {
+		for _, id := range in.ids {
+			if _, ok := c.entries[id]; ok {
+				delete(c.entries, id)
+				c.stats.Invalidations++
+			}
+		}
+	}
+	in.all, in.ids = false, in.ids[:0]
+	in.mu.Unlock()
+}
+
+func (c *ResolveCache) maybeFlush() {
+	if c.stats.Hits+c.stats.Misses-c.flushed.Hits-c.flushed.Misses >= resolveFlushEvery {
+		c.flush()
+	}
+}
+
+func (c *ResolveCache) flush() {
+	resolveMetrics.Add("hits", int64(c.stats.Hits-c.flushed.Hits))
+	resolveMetrics.Add("misses", int64(c.stats.Misses-c.flushed.Misses))
+	resolveMetrics.Add("invalidations", int64(c.stats.Invalidations-c.flushed.Invalidations))
+	c.flushed = c.stats
+}
+
+// Invalidate 使全部解析缓存中的这些 ID 失效：实体迁移到其他Actor或节点后调用。
+// Unregister 会自动调用；未指定 ID 时清空全部缓存
+func (s *System) Invalidate(ids ...int64) {
+	s.resolveCaches.Range(func(k, _ interface{}) bool {
+		if len(ids) == 0 {
+			k.(*resolveInbox).pushAll()
+		} else {
+			k.(*resolveInbox).push(ids)
+		}
+		return true
+	})
+}
+
+// stopped 消息循环是否已停止
+func (a *BaseActor) stopped() bool {
+	select {
+	case <-a.done():
+		return true
+	default:
+		return false
+	}
+}
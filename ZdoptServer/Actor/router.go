@@ -0,0 +1,235 @@
+package Actor
+
+//router.go
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math/rand/v2"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var ErrNoRoutees = errors.New("router has no routees")
+
+// RoutingStrategy 路由策略
+type RoutingStrategy int
+
+const (
+	RoundRobin     RoutingStrategy = iota // 轮询
+	Random                                // 随机
+	Broadcast                             // 广播给全部 worker
+	ConsistentHash                        // 按 Key 一致性哈希，同一键固定路由到同一 worker
+)
+
+// KeyFunc 从消息中提取路由键（如玩家 ID），返回空串时该消息按轮询路由
+type KeyFunc func(msg interface{}) string
+
+// RouterConfig 路由参数
+type RouterConfig struct {
+	Strategy     RoutingStrategy
+	Key          KeyFunc // ConsistentHash 策略使用，参数为拆信封后的消息
+	VirtualNodes int     // 一致性哈希每个 worker 的虚拟节点数
+}
+
+// Router 路由Actor：前置一组 worker，按策略转发消息；worker 增减时一致性哈希只迁移少量键
+type Router struct {
+	cfg     RouterConfig
+	mu      sync.Mutex // 串行化成员变更
+	routees atomic.Pointer[routeeSet]
+	next    atomic.Uint64
+}
+
+// routeeSet 不可变的成员快照及其哈希环
+type routeeSet struct {
+	actors []Actor
+	ring   []ringPoint
+}
+
+type ringPoint struct {
+	hash  uint32
+	index int
+}
+
+// NewRouter 创建路由器
+func NewRouter(cfg RouterConfig, routees ...Actor) *Router {
+	if cfg.VirtualNodes <= 0 {
+		cfg.VirtualNodes = 128
+	}
+	r := &Router{cfg: cfg}
+	r.routees.Store(r.build(append([]Actor(nil), routees...)))
+	return r
+}
+
+// Routees 当前 worker 快照
+func (r *Router) Routees() []Actor {
+	return r.routees.Load().actors
+}
+
+// AddRoutee 增加 worker（调用方负责其启动）
+func (r *Router) AddRoutee(a Actor) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	old := r.routees.Load().actors
+	next := make([]Actor, len(old), len(old)+1)
+	copy(next, old)
+	r.routees.Store(r.build(append(next, a)))
+}
+
+// RemoveRoutee 移除 worker，返回是否存在（调用方负责其停止）
+func (r *Router) RemoveRoutee(a Actor) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	old := r.routees.Load().actors
+	for i, cur := range old {
+		if cur == a {
+			next := make([]Actor, 0, len(old)-1)
+			next = append(next, old[:i]...)
+			next = append(next, old[i+1:]...)
+			r.routees.Store(r.build(next))
+			return true
+		}
+	}
+	return false
+}
+
+// build 生成成员快照，一致性哈希按成员位置生成虚拟节点
+func (r *Router) build(actors []Actor) *routeeSet {
+	set := &routeeSet{actors: actors}
+	if r.cfg.Strategy != ConsistentHash {
+		return set
+	}
+	set.ring = make([]ringPoint, 0, len(actors)*r.cfg.VirtualNodes)
+	for i, a := range actors {
+		id := routeeKey(a, i)
+		for v := 0; v < r.cfg.VirtualNodes; v++ {
+			set.ring = append(set.ring, ringPoint{hash: hashKey(id + "#" + strconv.Itoa(v)), index: i})
+		}
+	}
+	sort.Slice(set.ring, func(i, j int) bool { return set.ring[i].hash < set.ring[j].hash })
+	return set
+}
+
+// routeeKey worker 在哈希环上的标识：优先使用登记的 ID，其次 BaseActor 地址，保证增删其他 worker 时位置稳定
+func routeeKey(a Actor, index int) string {
+	if base := baseOf(a); base != nil {
+		if base.id != 0 {
+			return "id:" + strconv.FormatInt(base.id, 10)
+		}
+		return fmt.Sprintf("ptr:%p", base)
+	}
+	return fmt.Sprintf("%T#%d", a, index)
+}
+
+// hashKey FNV-1a 后经 murmur3 末端混合，改善相近键（如连续玩家 ID）的分布
+func hashKey(key string) uint32 {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	x := h.Sum32()
+	x ^= x >> 16
+	x *= 0x85ebca6b
+	x ^= x >> 13
+	x *= 0xc2b2ae35
+	x ^= x >> 16
+	return x
+}
+
+// Pick 按策略选择一个 worker（Broadcast 策略返回轮询结果）
+func (r *Router) Pick(msg interface{}) (Actor, error) {
+	set := r.routees.Load()
+	if len(set.actors) == 0 {
+		return nil, ErrNoRoutees
+	}
+	switch r.cfg.Strategy {
+	case Random:
+		return set.actors[rand.IntN(len(set.actors))], nil
+	case ConsistentHash:
+		if r.cfg.Key != nil {
+			payload, _ := unwrap(msg)
+			if key := r.cfg.Key(payload); key != "" {
+				h := hashKey(key)
+				i := sort.Search(len(set.ring), func(i int) bool { return set.ring[i].hash >= h })
+				if i == len(set.ring) {
+					i = 0
+				}
+				return set.actors[set.ring[i].index], nil
+			}
+		}
+	}
+	return set.actors[r.next.Add(1)%uint64(len(set.actors))], nil
+}
+
+// Route 按策略转发消息：内嵌 BaseActor 的 worker 按邮箱策略入队，其余直接调用 Receive
+func (r *Router) Route(msg interface{}) error {
+	if r.cfg.Strategy != Broadcast {
+		a, err := r.Pick(msg)
+		if err != nil {
+			return err
+		}
+		return deliver(a, msg)
+	}
+
+	actors := r.Routees()
+	if len(actors) == 0 {
+		return ErrNoRoutees
+	}
+	var errs error
+	for _, a := range actors {
+		errs = errors.Join(errs, deliver(a, msg))
+	}
+	return errs
+}
+
+func deliver(a Actor, msg interface{}) error {
+	if base := baseOf(a); base != nil {
+		if _, ok := msg.(*Envelope); !ok {
+			msg = &Envelope{Message: msg, Priority: base.Priority()}
+		}
+		return base.Send(msg)
+	}
+	a.Receive(msg)
+	return nil
+}
+
+// Init 启动全部 worker（含 PreStart）
+func (r *Router) Init(ctx context.Context) {
+	for _, a := range r.Routees() {
+		if err := startActor(ctx, a); err != nil {
+			defaultLogger.Printf("router: %v", err)
+		}
+	}
+}
+
+func (r *Router) Start() {
+	for _, a := range r.Routees() {
+		a.Start()
+	}
+}
+
+// Stop 停止全部 worker（排空邮箱后调用 PostStop）
+func (r *Router) Stop() {
+	for _, a := range r.Routees() {
+		stopActor(a)
+	}
+}
+
+// Update 驱动全部 worker
+func (r *Router) Update(delta time.Duration) {
+	for _, a := range r.Routees() {
+		a.Update(delta)
+		if base := baseOf(a); base != nil {
+			base.scheduleTaskSlice()
+		}
+	}
+}
+
+// Receive 转发消息，失败计入死信统计
+func (r *Router) Receive(msg interface{}) {
+	if err := r.Route(msg); err != nil && errors.Is(err, ErrNoRoutees) {
+		deadLetterCounts.Add(ActorNotFound.String(), 1)
+	}
+}
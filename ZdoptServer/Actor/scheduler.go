@@ -0,0 +1,197 @@
+package Actor
+
+//scheduler.go
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"expvar"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var scheduledMessages = expvar.NewMap("actors.scheduled") // fired / skipped / failed / cancelled
+
+// minRepeatInterval SendRepeatedly 的最小间隔
+const minRepeatInterval = time.Millisecond
+
+// Schedule 定时投递的取消句柄
+type Schedule struct {
+	sched     *scheduler
+	target    int64
+	msg       interface{}
+	at        time.Time
+	interval  time.Duration // 0 表示只投递一次
+	index     int           // 在堆中的位置，-1 表示已出堆
+	cancelled atomic.Bool
+	inflight  atomic.Bool // 上一次投递仍阻塞在邮箱上时跳过本次（仅重复投递）
+}
+
+// Cancel 取消后续投递，返回是否由本次调用取消（已取消或一次性投递已完成时返回 false）
+func (h *Schedule) Cancel() bool {
+	if !h.cancelled.CompareAndSwap(false, true) {
+		return false
+	}
+	if !h.sched.remove(h) && h.interval == 0 {
+		return false // 一次性投递已出堆（已投递或正在投递）
+	}
+	scheduledMessages.Add("cancelled", 1)
+	return true
+}
+
+// Cancelled 是否已取消
+func (h *Schedule) Cancelled() bool {
+	return h.cancelled.Load()
+}
+
+// SendAfter 在 delay 后按 ID 投递消息（如 10 秒后的增益到期），不需要为每个效果创建 ZTimer
+func (s *System) SendAfter(delay time.Duration, id int64, msg interface{}) *Schedule {
+	return s.scheduler.add(id, msg, max(delay, 0), 0)
+}
+
+// SendRepeatedly 每隔 interval 按 ID 投递一次消息，直到取消、目标注销或系统停止。
+// 目标邮箱阻塞时跳过期间的投递，不会堆积
+func (s *System) SendRepeatedly(interval time.Duration, id int64, msg interface{}) *Schedule {
+	interval = max(interval, minRepeatInterval)
+	return s.scheduler.add(id, msg, interval, interval)
+}
+
+// scheduler 系统共享的定时投递：一个协程按到期时间驱动最小堆
+type scheduler struct {
+	mu    sync.Mutex
+	queue scheduleHeap
+	wake  chan struct{}
+	send  func(id int64, msg interface{}) error
+}
+
+func newScheduler(send func(id int64, msg interface{}) error) *scheduler {
+	return &scheduler{wake: make(chan struct{}, 1), send: send}
+}
+
+func (sc *scheduler) add(id int64, msg interface{}, delay, interval time.Duration) *Schedule {
+	h := &Schedule{sched: sc, target: id, msg: msg, at: time.Now().Add(delay), interval: interval}
+	sc.mu.Lock()
+	heap.Push(&sc.queue, h)
+	first := h.index == 0
+	sc.mu.Unlock()
+	if first {
+		sc.signal()
+	}
+	return h
+}
+
+// remove 从堆中移除，返回是否仍在堆中
+func (sc *scheduler) remove(h *Schedule) bool {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	if h.index < 0 {
+		return false
+	}
+	heap.Remove(&sc.queue, h.index)
+	return true
+}
+
+func (sc *scheduler) signal() {
+	select {
+	case sc.wake <- struct{}{}:
+	default:
+	}
+}
+
+// run 调度循环，ctx 结束时退出，未到期的投递随之放弃
+func (sc *scheduler) run(ctx context.Context) {
+	timer := time.NewTimer(time.Hour)
+	defer timer.Stop()
+	var due []*Schedule
+	for {
+		now := time.Now()
+		wait := time.Hour
+		due = due[:0]
+		sc.mu.Lock()
+		for len(sc.queue) > 0 {
+			h := sc.queue[0]
+			if h.at.After(now) {
+				wait = h.at.Sub(now)
+				break
+			}
+			if h.interval > 0 {
+				// 重复投递留在堆中；错过多个周期时从现在起算，不补发
+				h.at = h.at.Add(h.interval)
+				if !h.at.After(now) {
+					h.at = now.Add(h.interval)
+				}
+				heap.Fix(&sc.queue, 0)
+			} else {
+				heap.Pop(&sc.queue)
+			}
+			due = append(due, h)
+		}
+		sc.mu.Unlock()
+
+		for _, h := range due {
+			sc.fire(h)
+		}
+
+		timer.Reset(wait)
+		select {
+		case <-ctx.Done():
+			return
+		case <-sc.wake:
+		case <-timer.C:
+		}
+	}
+}
+
+// fire 在独立协程中投递，避免 Block 策略的满邮箱阻塞调度循环
+func (sc *scheduler) fire(h *Schedule) {
+	if h.interval > 0 && h.cancelled.Load() {
+		return
+	}
+	if !h.inflight.CompareAndSwap(false, true) {
+		scheduledMessages.Add("skipped", 1)
+		return
+	}
+	go func() {
+		defer h.inflight.Store(false)
+		err := sc.send(h.target, h.msg)
+		if err == nil {
+			scheduledMessages.Add("fired", 1)
+			return
+		}
+		scheduledMessages.Add("failed", 1)
+		if h.interval > 0 && (errors.Is(err, ErrActorNotFound) || errors.Is(err, ErrSystemStopping)) {
+			// 目标已注销或系统停止，不再重复投递
+			if h.cancelled.CompareAndSwap(false, true) {
+				sc.remove(h)
+			}
+		}
+	}()
+}
+
+// scheduleHeap 按到期时间排序的最小堆
+type scheduleHeap []*Schedule
+
+func (q scheduleHeap) Len() int           { return len(q) }
+func (q scheduleHeap) Less(i, j int) bool { return q[i].at.Before(q[j].at) }
+func (q scheduleHeap) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *scheduleHeap) Push(x interface{}) {
+	h := x.(*Schedule)
+	h.index = len(*q)
+	*q = append(*q, h)
+}
+
+func (q *scheduleHeap) Pop() interface{} {
+	old := *q
+	n := len(old)
+	h := old[n-1]
+	old[n-1] = nil
+	h.index = -1
+	*q = old[:n-1]
+	return h
+}
@@ -0,0 +1,184 @@
+package Actor
+
+//shutdown.go
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var (
+	ErrSystemStopping     = errors.New("actor system stopping")
+	ErrShutdownIncomplete = errors.New("actor shutdown incomplete")
+)
+
+// drainPollInterval 等待邮箱排空的轮询间隔
+const drainPollInterval = 5 * time.Millisecond
+
+// Shutdown 优雅停止整个系统：不再接收新消息（System 的 Send/SendFrom/Ask 返回 ErrSystemStopping），
+// 停止组的 tick，等待各Actor（组成员以及仅经 Register/RegisterName 登记的）处理完邮箱中的消息后
+// 依次调用 Stop 与 PostStop，最后关闭系统创建的负载均衡器。
+// ctx 结束时丢弃剩余消息（进入死信）并返回 ErrShutdownIncomplete；仍在处理中的Actor在后台完成停止。
+// 返回各Actor停止过程中的错误（含 PostStop panic）的汇总。Actor之间的直接投递不受拦截
+func (s *System) Shutdown(ctx context.Context) error {
+	if !s.stopping.CompareAndSwap(false, true) {
+		return ErrSystemStopping
+	}
+	defer s.cancel()
+
+	s.FuncgroupLock.RLock()
+	actors := s.stopGroups()
+	s.FuncgroupLock.RUnlock()
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+	for _, a := range actors {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := drainActor(ctx, a); err != nil {
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	for _, b := range s.trackedBalancers() {
+		closed := make(chan struct{})
+		go func() {
+			b.Close()
+			close(closed)
+		}()
+		select {
+		case <-closed:
+		case <-ctx.Done():
+			errs = append(errs, fmt.Errorf("%w: balancer still draining: %v", ErrShutdownIncomplete, ctx.Err()))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// stopGroups 停止各组的 tick，返回需要停止的Actor：组成员，以及不在任何组中、经 ID 或名称登记且仍在运行的Actor
+// （同一Actor只出现一次）。调用方持有 FuncgroupLock
+func (s *System) stopGroups() []Actor {
+	var actors []Actor
+	seen := make(map[Actor]struct{})
+	for _, g := range s.groups {
+		g.Stop()
+		for _, a := range g.Actors() {
+			if _, ok := seen[a]; !ok {
+				seen[a] = struct{}{}
+				actors = append(actors, a)
+			}
+		}
+	}
+	registered := func(a Actor) {
+		if _, ok := seen[a]; ok {
+			return
+		}
+		seen[a] = struct{}{}
+		if base := baseOf(a); base != nil && base.stopped() {
+			return // 已单独停止，不再重复调用 Stop 与 PostStop
+		}
+		actors = append(actors, a)
+	}
+	s.actors.Range(func(_, v interface{}) bool {
+		registered(v.(Actor))
+		return true
+	})
+	s.names.mu.RLock()
+	named := make([]Actor, 0, len(s.names.names))
+	for _, a := range s.names.names {
+		named = append(named, a)
+	}
+	s.names.mu.RUnlock()
+	for _, a := range named {
+		registered(a)
+	}
+	return actors
+}
+
+// drainActor 等待邮箱排空后停止Actor，ctx 结束时丢弃剩余消息
+func drainActor(ctx context.Context, a Actor) error {
+	var errs []error
+	base := baseOf(a)
+	if base != nil {
+		if err := base.awaitIdle(ctx); err != nil {
+			n := base.discardPending()
+			errs = append(errs, fmt.Errorf("%w: actor %d dropped %d messages: %v", ErrShutdownIncomplete, base.id, n, err))
+		}
+	}
+
+	stopped := make(chan error, 1)
+	go func() {
+		stopped <- stopActorSafe(a)
+	}()
+	select {
+	case err := <-stopped:
+		errs = append(errs, err)
+	case <-ctx.Done():
+		select {
+		case err := <-stopped:
+			errs = append(errs, err)
+		default:
+			errs = append(errs, fmt.Errorf("%w: %s still stopping: %v", ErrShutdownIncomplete, actorName(a), ctx.Err()))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// stopActorSafe stopActor，Stop 或 PostStop 的 panic 转为错误
+func stopActorSafe(a Actor) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%s stop panic: %v", actorName(a), r)
+		}
+	}()
+	stopActor(a)
+	return nil
+}
+
+func actorName(a Actor) string {
+	if base := baseOf(a); base != nil {
+		return fmt.Sprintf("actor %d", base.id)
+	}
+	return fmt.Sprintf("actor %T", a)
+}
+
+// awaitIdle 等待两条队列为空且消息循环处理完已取出的消息；未启动的Actor立即返回
+func (a *BaseActor) awaitIdle(ctx context.Context) error {
+	if a.ctx == nil {
+		return nil
+	}
+	ticker := time.NewTicker(drainPollInterval)
+	defer ticker.Stop()
+	for a.mailbox.Len()+a.urgent.Len() > 0 || !a.idle.Load() {
+		if a.ctx.Err() != nil {
+			return nil // 消息循环已退出或正在排空
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+	return nil
+}
+
+// discardPending 丢弃队列中剩余的消息（进入死信，请求以 ErrActorStopped 完成），返回丢弃条数
+func (a *BaseActor) discardPending() int {
+	msgs := a.drainPending(nil)
+	for _, msg := range msgs {
+		a.deadLetter(msg, ActorStopped)
+		_, env := unwrap(msg)
+		resolveRejected(env, ErrActorStopped)
+	}
+	return len(msgs)
+}
@@ -0,0 +1,83 @@
+package Actor
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// countingActor 记录处理的消息数与 PostStop 调用次数
+type countingActor struct {
+	*BaseActor
+	handled  atomic.Int64
+	postStop atomic.Int64
+}
+
+func newCountingActor(s *System) *countingActor {
+	a := &countingActor{BaseActor: s.NewBaseActor(64, WithProcessingMode(Sequential))}
+	RegisterHandler(a.BaseActor, func(int) {
+		time.Sleep(time.Millisecond)
+		a.handled.Add(1)
+	})
+	return a
+}
+
+func (a *countingActor) Start()                     {}
+func (a *countingActor) Stop()                      {}
+func (a *countingActor) Update(delta time.Duration) {}
+func (a *countingActor) Receive(msg interface{})    {}
+func (a *countingActor) PostStop()                  { a.postStop.Add(1) }
+
+func TestShutdownDrainsRegisteredActors(t *testing.T) {
+	s := NewSystem()
+	named, byID := newCountingActor(s), newCountingActor(s)
+	named.Init(context.Background())
+	byID.Init(context.Background())
+	if err := s.RegisterName("named", named); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Register(s.NextID(), byID); err != nil {
+		t.Fatal(err)
+	}
+	// 同一Actor同时以名称与 ID 登记时只停止一次
+	if err := s.RegisterName("alias", byID); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 20; i++ {
+		s.SendName("named", i)
+		s.SendName("alias", i)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := s.Shutdown(ctx); err != nil {
+		t.Fatal(err)
+	}
+	for name, a := range map[string]*countingActor{"named": named, "registered": byID} {
+		if got := a.handled.Load(); got != 20 {
+			t.Errorf("%s actor handled %d of 20 messages before stop", name, got)
+		}
+		if got := a.postStop.Load(); got != 1 {
+			t.Errorf("%s actor PostStop called %d times", name, got)
+		}
+	}
+}
+
+func TestStopStopsRegisteredActors(t *testing.T) {
+	s := NewSystem()
+	named, stopped := newCountingActor(s), newCountingActor(s)
+	named.Init(context.Background())
+	stopped.Init(context.Background())
+	s.RegisterName("named", named)
+	s.Register(s.NextID(), stopped)
+	stopActor(stopped) // 已单独停止的Actor不再重复 PostStop
+
+	s.Stop()
+	if !named.stopped() || named.postStop.Load() != 1 {
+		t.Fatalf("named actor stopped=%v postStop=%d", named.stopped(), named.postStop.Load())
+	}
+	if got := stopped.postStop.Load(); got != 1 {
+		t.Fatalf("already stopped actor PostStop called %d times", got)
+	}
+}
@@ -0,0 +1,111 @@
+package Actor
+
+//snapshot.go
+import (
+	"expvar"
+	"sync/atomic"
+)
+
+// ActorRestartedTopic 处理器 panic 后Actor从快照恢复时，在事件总线上发布 ActorRestarted 的主题
+const ActorRestartedTopic = "actor.restarted"
+
+var snapshotRestores = expvar.NewMap("actors.snapshot_restores") // restored / failed
+
+// Snapshotter 可选接口：Actor实现后，未被监督的Actor在处理器 panic 后从最近一次良好状态恢复，
+// 被监督的Actor重启时新实例从旧实例的最近快照恢复。
+// Snapshot 在每批消息处理成功后于消息循环中调用，返回值不能与Actor共享可变数据
+type Snapshotter interface {
+	Snapshot() interface{}
+	Restore(state interface{}) error
+}
+
+// ActorRestarted panic 恢复通知
+type ActorRestarted struct {
+	ID       int64
+	Panic    interface{}
+	Restored bool  // 是否已从快照恢复
+	Err      error // Restore 返回的错误
+}
+
+// WithSnapshotEvery 每处理 n 批消息做一次快照（默认每批），快照代价高的Actor可调大，panic 时回退到更早的状态
+func WithSnapshotEvery(n int) BaseActorOption {
+	return func(o *baseActorOptions) {
+		if n > 0 {
+			o.snapshotEvery = n
+		}
+	}
+}
+
+// snapshotState 最近一次良好状态
+type snapshotState struct {
+	owner    Snapshotter
+	every    int
+	batches  int
+	last     atomic.Pointer[snapshotBox]
+	panicked atomic.Bool // 当前批次中有处理器 panic
+	cause    atomic.Pointer[snapshotBox]
+}
+
+type snapshotBox struct {
+	state interface{}
+}
+
+// enableSnapshots startActor 时为实现了 Snapshotter 的Actor开启快照，并记录初始状态
+func (a *BaseActor) enableSnapshots(owner Snapshotter) {
+	a.snapshots.owner = owner
+	a.snapshots.last.Store(&snapshotBox{state: owner.Snapshot()})
+}
+
+// lastSnapshot 最近一次良好状态
+func (a *BaseActor) lastSnapshot() (interface{}, bool) {
+	if box := a.snapshots.last.Load(); box != nil {
+		return box.state, true
+	}
+	return nil, false
+}
+
+// checkpoint 每批消息处理后由消息循环调用：批次中有 panic 时不记录快照，
+// 未被监督时从快照恢复；否则按间隔记录快照
+func (a *BaseActor) checkpoint() {
+	s := &a.snapshots
+	if s.owner == nil {
+		s.panicked.Store(false)
+		return
+	}
+	if s.panicked.Swap(false) {
+		if a.onPanic.Load() == nil {
+			a.restoreSnapshot()
+		}
+		return
+	}
+	s.batches++
+	if s.batches >= max(s.every, 1) {
+		s.batches = 0
+		s.last.Store(&snapshotBox{state: s.owner.Snapshot()})
+	}
+}
+
+// restoreSnapshot 从最近快照恢复并发布通知
+func (a *BaseActor) restoreSnapshot() {
+	ev := ActorRestarted{ID: a.id}
+	if cause := a.snapshots.cause.Load(); cause != nil {
+		ev.Panic = cause.state
+	}
+	state, _ := a.lastSnapshot()
+	if ev.Err = a.snapshots.owner.Restore(state); ev.Err != nil {
+		snapshotRestores.Add("failed", 1)
+		a.Logger().Printf("actor %d restore snapshot: %v", a.id, ev.Err)
+	} else {
+		ev.Restored = true
+		snapshotRestores.Add("restored", 1)
+	}
+	if bus := a.bus.Load(); bus != nil {
+		_, _ = bus.Publish(ActorRestartedTopic, ev)
+	}
+}
+
+// recordPanic 记录批次中发生的 panic，批次结束后由 checkpoint 处理
+func (a *BaseActor) recordPanic(v interface{}) {
+	a.snapshots.cause.Store(&snapshotBox{state: v})
+	a.snapshots.panicked.Store(true)
+}
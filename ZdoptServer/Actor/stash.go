@@ -0,0 +1,117 @@
+package Actor
+
+//stash.go
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+var ErrStashFull = errors.New("actor stash full")
+
+// defaultStashCapacity 暂存区默认容量
+const defaultStashCapacity = 1024
+
+// WithStashCapacity 设置暂存区容量
+func WithStashCapacity(n int) BaseActorOption {
+	return func(o *baseActorOptions) {
+		if n > 0 {
+			o.stashCapacity = n
+		}
+	}
+}
+
+// stash 暂存区：Actor未就绪（如等待数据库加载）时延后处理的消息
+type stash struct {
+	mu       sync.Mutex
+	msgs     []interface{}
+	capacity int
+	released []interface{} // UnstashAll 后等待消息循环按序重放
+	ready    atomic.Bool
+}
+
+// Stash 暂存消息（拆信封前的原始消息或处理器收到的消息），UnstashAll 后按暂存顺序重放
+func (a *BaseActor) Stash(msg interface{}) error {
+	s := &a.stash
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	capacity := s.capacity
+	if capacity <= 0 {
+		capacity = defaultStashCapacity
+	}
+	if len(s.msgs) >= capacity {
+		return fmt.Errorf("%w: actor %d (%d)", ErrStashFull, a.id, capacity)
+	}
+	s.msgs = append(s.msgs, msg)
+	return nil
+}
+
+// UnstashAll 释放全部暂存消息：消息循环在处理新消息前按暂存顺序逐条重放，返回释放的条数
+func (a *BaseActor) UnstashAll() int {
+	s := &a.stash
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := len(s.msgs)
+	if n == 0 {
+		return 0
+	}
+	s.released = append(s.released, s.msgs...)
+	s.msgs = nil
+	s.ready.Store(true)
+	// 在消息循环外调用时唤醒空闲的消息循环
+	a.mailbox.Notify()
+	return n
+}
+
+// StashSize 暂存中的消息数
+func (a *BaseActor) StashSize() int {
+	a.stash.mu.Lock()
+	defer a.stash.mu.Unlock()
+	return len(a.stash.msgs)
+}
+
+// ClearStash 丢弃全部暂存消息（计入死信），返回丢弃的条数
+func (a *BaseActor) ClearStash() int {
+	a.stash.mu.Lock()
+	msgs := a.stash.msgs
+	a.stash.msgs = nil
+	a.stash.mu.Unlock()
+	for _, msg := range msgs {
+		a.deadLetter(msg, ActorStopped)
+		_, env := unwrap(msg)
+		resolveRejected(env, ErrActorStopped)
+	}
+	return len(msgs)
+}
+
+// replayStash 在消息循环中逐条重放已释放的暂存消息（保持暂存顺序，不经重放保护重复校验）
+func (a *BaseActor) replayStash() {
+	if !a.stash.ready.Load() {
+		return
+	}
+	a.stash.mu.Lock()
+	msgs := a.stash.released
+	a.stash.released = nil
+	a.stash.ready.Store(false)
+	a.stash.mu.Unlock()
+
+	for _, msg := range msgs {
+		func() {
+			defer a.recoverPanic()
+			a.handle(msg)
+		}()
+	}
+}
+
+// Stash 暂存当前消息（保留信封，重放时仍保持发送者、Deadline 与 Ask 回复）
+func (c *MessageContext) Stash() error {
+	if c.Envelope != nil {
+		if err := c.Self.Stash(c.Envelope); err != nil {
+			return err
+		}
+		c.handoff = true
+		return nil
+	}
+	return fmt.Errorf("stash %s: message was not delivered in an envelope", c.msgType)
+}
@@ -0,0 +1,43 @@
+package Actor
+
+import (
+	"sync"
+	"zdopt/ZdoptServer/Strict"
+)
+
+// 邮箱通道编号（严格模式顺序断言使用）
+const (
+	laneMailbox = iota
+	laneUrgent
+	laneCount
+)
+
+// mailboxOrder 严格模式下的邮箱顺序断言：投递时按通道编号，取出时要求严格递增
+type mailboxOrder struct {
+	mu   sync.Mutex // 串行化"编号+入队"，避免并发投递造成误报
+	post [laneCount]uint64
+	recv [laneCount]uint64
+}
+
+// stampAndSend 编号后经 send 写入通道，未写入时撤回编号
+func (o *mailboxOrder) stampAndSend(lane int, env *Envelope, send func() bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.post[lane]++
+	env.seq = o.post[lane]
+	if !send() {
+		o.post[lane]--
+	}
+}
+
+// check 校验取出顺序（仅由消息循环协程调用）
+func (o *mailboxOrder) check(a *BaseActor, lane int, msg interface{}) {
+	env, ok := msg.(*Envelope)
+	if !ok || env.seq == 0 {
+		return
+	}
+	if env.seq <= o.recv[lane] {
+		Strict.Failf("actor.mailbox-order", "actor %d lane %d: received seq %d after %d", a.id, lane, env.seq, o.recv[lane])
+	}
+	o.recv[lane] = env.seq
+}
@@ -0,0 +1,133 @@
+package Actor
+
+//subscriptions.go
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+var ErrNoSubscriptionBinder = errors.New("subscription binder not set")
+
+// Subscription 可持久化的订阅描述（不含闭包），Actor 重启或迁移后据此重新订阅
+type Subscription struct {
+	ActorID int64
+	Topic   string
+	Filter  string // 由事件总线解释的过滤表达式（见 EventBus.SubscribeFilter），空表示不过滤
+}
+
+// SubscriptionBinder 由事件总线提供：按描述把订阅重新挂到新的 Actor 实例上
+type SubscriptionBinder func(sub Subscription) error
+
+// SubscriptionRegistry 由 System 持有的订阅登记表，生命周期独立于 Actor 实例
+type SubscriptionRegistry struct {
+	mu     sync.RWMutex
+	subs   map[int64]map[Subscription]struct{}
+	binder SubscriptionBinder
+}
+
+// NewSubscriptionRegistry 创建订阅登记表
+func NewSubscriptionRegistry() *SubscriptionRegistry {
+	return &SubscriptionRegistry{
+		subs: make(map[int64]map[Subscription]struct{}),
+	}
+}
+
+// SetBinder 设置重新订阅时使用的绑定函数
+func (r *SubscriptionRegistry) SetBinder(binder SubscriptionBinder) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.binder = binder
+}
+
+// Add 登记订阅（重复登记无副作用）
+func (r *SubscriptionRegistry) Add(sub Subscription) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	set, ok := r.subs[sub.ActorID]
+	if !ok {
+		set = make(map[Subscription]struct{})
+		r.subs[sub.ActorID] = set
+	}
+	set[sub] = struct{}{}
+}
+
+// Remove 注销单条订阅
+func (r *SubscriptionRegistry) Remove(sub Subscription) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if set, ok := r.subs[sub.ActorID]; ok {
+		delete(set, sub)
+		if len(set) == 0 {
+			delete(r.subs, sub.ActorID)
+		}
+	}
+}
+
+// RemoveActor 注销 Actor 的全部订阅（Actor 永久退出时调用）
+func (r *SubscriptionRegistry) RemoveActor(actorID int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.subs, actorID)
+}
+
+// List 返回 Actor 的全部订阅（按主题、过滤条件排序）
+func (r *SubscriptionRegistry) List(actorID int64) []Subscription {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	list := make([]Subscription, 0, len(r.subs[actorID]))
+	for sub := range r.subs[actorID] {
+		list = append(list, sub)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Topic != list[j].Topic {
+			return list[i].Topic < list[j].Topic
+		}
+		return list[i].Filter < list[j].Filter
+	})
+	return list
+}
+
+// Restore 在 Actor 重启或迁移后重新建立其全部订阅
+func (r *SubscriptionRegistry) Restore(actorID int64) error {
+	r.mu.RLock()
+	binder := r.binder
+	r.mu.RUnlock()
+
+	if binder == nil {
+		return ErrNoSubscriptionBinder
+	}
+
+	var errs []error
+	for _, sub := range r.List(actorID) {
+		if err := binder(sub); err != nil {
+			errs = append(errs, fmt.Errorf("resubscribe %q: %w", sub.Topic, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Migrate 将订阅从旧 Actor ID 转移到新 ID 并重新建立（跨节点迁移后 ID 变化时使用）
+func (r *SubscriptionRegistry) Migrate(fromID, toID int64) error {
+	r.mu.Lock()
+	set := r.subs[fromID]
+	delete(r.subs, fromID)
+	if len(set) > 0 {
+		dst, ok := r.subs[toID]
+		if !ok {
+			dst = make(map[Subscription]struct{}, len(set))
+			r.subs[toID] = dst
+		}
+		for sub := range set {
+			sub.ActorID = toID
+			dst[sub] = struct{}{}
+		}
+	}
+	r.mu.Unlock()
+
+	return r.Restore(toID)
+}
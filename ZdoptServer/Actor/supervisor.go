@@ -0,0 +1,428 @@
+package Actor
+
+//supervisor.go
+import (
+	"context"
+	"errors"
+	"expvar"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+var (
+	ErrRestartBudgetExceeded = errors.New("supervisor restart budget exceeded")
+
+	actorPanics   = expvar.NewInt("actors.panics")
+	actorRestarts = expvar.NewInt("actors.restarts")
+)
+
+// Strategy 子Actor失败时的重启策略
+type Strategy int
+
+const (
+	OneForOne Strategy = iota // 只重启失败的子Actor
+	AllForOne                 // 重启全部子Actor
+)
+
+// ChildFailure 子Actor panic 信息
+type ChildFailure struct {
+	Child string
+	Value interface{}
+	Stack []byte
+}
+
+func (f *ChildFailure) Error() string {
+	return fmt.Sprintf("child %s panicked: %v", f.Child, f.Value)
+}
+
+// ChildSpec 子Actor定义：New 在首次启动和每次重启时调用；ID 非零且设置了 System 时重启后重新登记
+type ChildSpec struct {
+	Name string
+	ID   int64
+	New  func() Actor
+}
+
+// SupervisorConfig 监督参数
+type SupervisorConfig struct {
+	Strategy    Strategy
+	MaxRestarts int           // Within 时间内允许的最大重启次数，超出后上报父监督者
+	Within      time.Duration // 重启计数窗口
+	Backoff     time.Duration // 首次重启等待，连续失败时翻倍
+	MaxBackoff  time.Duration
+	System      *System           // 用于按 ID 重新登记重启后的Actor，可为空
+	OnEscalate  func(err error)   // 顶层监督者预算耗尽时调用
+	OnRestart   func(name string) // 子Actor重启后调用
+}
+
+// DefaultSupervisorConfig 默认监督参数：一分钟内最多重启 5 次
+func DefaultSupervisorConfig() SupervisorConfig {
+	return SupervisorConfig{
+		Strategy:    OneForOne,
+		MaxRestarts: 5,
+		Within:      time.Minute,
+		Backoff:     100 * time.Millisecond,
+		MaxBackoff:  5 * time.Second,
+	}
+}
+
+// Supervisor 监督者：持有子Actor，子Actor的 Receive/Update/消息处理 panic 时按策略重启。
+// Supervisor 本身实现 Actor，可作为其他监督者的子Actor组成监督树，预算耗尽时逐级上报
+type Supervisor struct {
+	cfg      SupervisorConfig
+	mu       sync.Mutex
+	children []*supervised
+	restarts []time.Time
+	backoff  time.Duration
+	ctx      context.Context
+	cancel   context.CancelFunc
+	parent   *supervised // 作为子Actor时在父监督者中的封装
+	stopped  bool
+}
+
+// NewSupervisor 创建监督者，未设置的参数使用默认值
+func NewSupervisor(cfg SupervisorConfig, specs ...ChildSpec) *Supervisor {
+	def := DefaultSupervisorConfig()
+	if cfg.MaxRestarts <= 0 {
+		cfg.MaxRestarts = def.MaxRestarts
+	}
+	if cfg.Within <= 0 {
+		cfg.Within = def.Within
+	}
+	if cfg.Backoff <= 0 {
+		cfg.Backoff = def.Backoff
+	}
+	if cfg.MaxBackoff < cfg.Backoff {
+		cfg.MaxBackoff = max(def.MaxBackoff, cfg.Backoff)
+	}
+
+	s := &Supervisor{cfg: cfg, backoff: cfg.Backoff}
+	for _, spec := range specs {
+		s.children = append(s.children, &supervised{spec: spec, sup: s})
+	}
+	return s
+}
+
+// Children 子Actor的稳定引用（重启后仍指向最新实例），可加入 Group 或按需调用
+func (s *Supervisor) Children() []Actor {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]Actor, len(s.children))
+	for i, c := range s.children {
+		out[i] = c
+	}
+	return out
+}
+
+// Child 按名称返回当前的子Actor实例
+func (s *Supervisor) Child(name string) (Actor, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, c := range s.children {
+		if c.spec.Name == name {
+			return c.current(), true
+		}
+	}
+	return nil, false
+}
+
+// Init 启动全部子Actor
+func (s *Supervisor) Init(ctx context.Context) {
+	s.mu.Lock()
+	s.ctx, s.cancel = context.WithCancel(ctx)
+	s.stopped = false
+	children := append([]*supervised(nil), s.children...)
+	s.mu.Unlock()
+
+	for _, c := range children {
+		c.Init(s.ctx)
+	}
+}
+
+func (s *Supervisor) Start() {
+	for _, c := range s.Children() {
+		c.Start()
+	}
+}
+
+// Stop 停止全部子Actor
+func (s *Supervisor) Stop() {
+	s.mu.Lock()
+	s.stopped = true
+	children := append([]*supervised(nil), s.children...)
+	cancel := s.cancel
+	s.mu.Unlock()
+
+	for _, c := range children {
+		c.Stop()
+	}
+	if cancel != nil {
+		cancel()
+	}
+}
+
+// Update 并发驱动全部子Actor
+func (s *Supervisor) Update(delta time.Duration) {
+	var wg sync.WaitGroup
+	for _, c := range s.Children() {
+		wg.Add(1)
+		go func(a Actor) {
+			defer wg.Done()
+			a.Update(delta)
+		}(c)
+	}
+	wg.Wait()
+}
+
+// Receive 监督者自身不处理业务消息
+func (s *Supervisor) Receive(msg interface{}) {}
+
+// childFailed 子Actor失败：预算内按策略延迟重启，超出预算则停止全部子Actor并上报
+func (s *Supervisor) childFailed(c *supervised, failure *ChildFailure) {
+	s.mu.Lock()
+	if s.stopped {
+		s.mu.Unlock()
+		return
+	}
+
+	now := time.Now()
+	kept := s.restarts[:0]
+	for _, t := range s.restarts {
+		if now.Sub(t) < s.cfg.Within {
+			kept = append(kept, t)
+		}
+	}
+	s.restarts = append(kept, now)
+
+	if len(s.restarts) > s.cfg.MaxRestarts {
+		s.mu.Unlock()
+		// 可能处于失败子Actor的消息处理协程中，停止子Actor需等待其退出，故异步上报
+		go s.escalate(fmt.Errorf("%w: %d restarts within %v: %v", ErrRestartBudgetExceeded, s.cfg.MaxRestarts, s.cfg.Within, failure))
+		return
+	}
+
+	delay := s.backoff
+	if len(s.restarts) == 1 {
+		delay = s.cfg.Backoff
+	}
+	s.backoff = min(delay*2, s.cfg.MaxBackoff)
+
+	targets := []*supervised{c}
+	if s.cfg.Strategy == AllForOne {
+		targets = append([]*supervised(nil), s.children...)
+	}
+	s.mu.Unlock()
+
+	// 先同步标记失败以丢弃后续调用，再异步停止（PreRestart、排空邮箱、PostStop），停止完成后才重启
+	instances := make([]Actor, len(targets))
+	for i, t := range targets {
+		instances[i] = t.suspend()
+	}
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		for i, t := range targets {
+			if instances[i] != nil {
+				t.stopInstance(instances[i], failure)
+			}
+		}
+	}()
+	time.AfterFunc(delay, func() {
+		<-stopped
+		for _, t := range targets {
+			s.restart(t)
+		}
+	})
+}
+
+func (s *Supervisor) restart(c *supervised) {
+	s.mu.Lock()
+	if s.stopped {
+		s.mu.Unlock()
+		return
+	}
+	ctx := s.ctx
+	s.mu.Unlock()
+
+	c.Init(ctx)
+	c.Start()
+	actorRestarts.Add(1)
+	if s.cfg.OnRestart != nil {
+		s.cfg.OnRestart(c.spec.Name)
+	}
+}
+
+// escalate 停止全部子Actor，上报父监督者；没有父监督者时调用 OnEscalate
+func (s *Supervisor) escalate(err error) {
+	s.Stop()
+	if s.parent != nil {
+		s.parent.fail(err, nil)
+		return
+	}
+	if s.cfg.OnEscalate != nil {
+		s.cfg.OnEscalate(err)
+		return
+	}
+	defaultLogger.Printf("supervisor escalated with no parent: %v", err)
+}
+
+// supervised 子Actor封装：捕获 Receive/Update 及邮箱处理中的 panic 并报告监督者
+type supervised struct {
+	spec    ChildSpec
+	sup     *Supervisor
+	mu      sync.RWMutex
+	actor   Actor
+	down    bool // 已失败、等待重启，期间丢弃调用
+	started bool // 当前实例已通过 PreStart 并启动
+}
+
+func (c *supervised) current() Actor {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.actor
+}
+
+// Init 创建新实例并启动（首次启动与重启共用），PreStart 失败按子Actor失败处理
+func (c *supervised) Init(ctx context.Context) {
+	var prev interface{}
+	hasPrev := false
+	if old := c.current(); old != nil {
+		if base := baseOf(old); base != nil {
+			prev, hasPrev = base.lastSnapshot()
+		}
+	}
+	a := c.spec.New()
+	if sn, ok := a.(Snapshotter); ok && hasPrev {
+		// 重启的新实例从旧实例最近一次良好状态继续
+		if err := sn.Restore(prev); err != nil {
+			snapshotRestores.Add("failed", 1)
+			defaultLogger.Printf("supervisor: restore %s from snapshot: %v", c.spec.Name, err)
+		} else {
+			snapshotRestores.Add("restored", 1)
+		}
+	}
+	if child, ok := a.(*Supervisor); ok {
+		child.parent = c
+	}
+	if base := baseOf(a); base != nil {
+		base.setPanicHandler(func(v interface{}, stack []byte) {
+			c.fail(v, stack)
+		})
+	}
+	if sys := c.sup.cfg.System; sys != nil {
+		base := baseOf(a)
+		if base != nil {
+			base.attach(sys)
+		}
+		if c.spec.ID != 0 {
+			sys.actors.Store(c.spec.ID, a)
+			if base != nil {
+				base.SetID(c.spec.ID)
+			}
+		}
+	}
+	err := startActor(ctx, a)
+	if sys := c.sup.cfg.System; sys != nil && err == nil && c.spec.ID != 0 {
+		// 重启后按登记表恢复事件总线订阅
+		if rerr := sys.subscriptions.Restore(c.spec.ID); rerr != nil {
+			defaultLogger.Printf("supervisor: restore subscriptions of %d: %v", c.spec.ID, rerr)
+		}
+	}
+
+	c.mu.Lock()
+	c.actor = a
+	c.down = false
+	c.started = err == nil
+	c.mu.Unlock()
+	if err != nil {
+		c.fail(err, nil)
+	}
+}
+
+func (c *supervised) Start() {
+	if a := c.live(); a != nil {
+		c.guard(a.Start)
+	}
+}
+
+func (c *supervised) Stop() {
+	if a := c.suspend(); a != nil {
+		c.stopInstance(a, nil)
+	}
+}
+
+func (c *supervised) Update(delta time.Duration) {
+	if a := c.live(); a != nil {
+		c.guard(func() { a.Update(delta) })
+		if base := baseOf(a); base != nil {
+			base.scheduleTaskSlice()
+		}
+	}
+}
+
+func (c *supervised) Receive(msg interface{}) {
+	if a := c.live(); a != nil {
+		c.guard(func() { a.Receive(msg) })
+	}
+}
+
+func (c *supervised) live() Actor {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.down {
+		return nil
+	}
+	return c.actor
+}
+
+func (c *supervised) guard(fn func()) {
+	defer func() {
+		if r := recover(); r != nil {
+			c.fail(r, debug.Stack())
+		}
+	}()
+	fn()
+}
+
+// fail 标记失败并报告监督者（同一实例只报告一次）
+func (c *supervised) fail(v interface{}, stack []byte) {
+	actorPanics.Add(1)
+	c.mu.Lock()
+	if c.down {
+		c.mu.Unlock()
+		return
+	}
+	c.down = true
+	c.mu.Unlock()
+
+	failure, ok := v.(*ChildFailure)
+	if !ok {
+		failure = &ChildFailure{Child: c.spec.Name, Value: v, Stack: stack}
+	}
+	c.sup.childFailed(c, failure)
+}
+
+// suspend 标记等待重启，返回需停止的实例（已停止时为 nil）
+func (c *supervised) suspend() Actor {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.down = true
+	if !c.started {
+		return nil
+	}
+	c.started = false
+	return c.actor
+}
+
+// stopInstance 停止实例：重启时先调用 PreRestart，再排空邮箱并调用 PostStop；钩子自身 panic 不再上报
+func (c *supervised) stopInstance(a Actor, reason error) {
+	defer func() { _ = recover() }()
+	if p, ok := a.(PreRestarter); ok && reason != nil {
+		func() {
+			defer func() { _ = recover() }()
+			p.PreRestart(reason)
+		}()
+	}
+	stopActor(a)
+}
@@ -3,69 +3,201 @@ package Actor
 // actor/system.go
 import (
 	"context"
+	"runtime"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
+// SystemConfig Actor系统运行参数
+type SystemConfig struct {
+	MailboxSize       int           // 普通邮箱容量
+	UrgentMailboxSize int           // 加急通道容量
+	Dispatchers       int           // 任务分发 worker 数（Balancer）
+	TickInterval      time.Duration // Group 默认 tick 间隔
+	MailboxPolicy     MailboxPolicy // 邮箱满时的处理策略
+	Monitor           MonitorConfig // 指标采样与资源调整，零值字段使用默认值
+}
+
+// DefaultSystemConfig 默认参数，与原有硬编码值一致
+func DefaultSystemConfig() SystemConfig {
+	return SystemConfig{
+		MailboxSize:       1024,
+		UrgentMailboxSize: 256,
+		Dispatchers:       runtime.NumCPU(),
+		TickInterval:      33 * time.Millisecond,
+	}
+}
+
 type System struct {
+	config        SystemConfig
 	groups        map[int]*Group
-	actors        sync.Map
+	actors        sync.Map // map[int64]Actor，按 ID 登记的Actor
+	lastID        int64
 	ctx           context.Context
 	cancel        context.CancelFunc
 	FuncgroupLock sync.RWMutex
+	subscriptions *SubscriptionRegistry
+	deadLetters   *DeadLetters
+	blackboard    *Blackboard
+	scheduler     *scheduler
+	middleware    *middlewareChain // Use 注册的系统级拦截器
+	bus           *EventBus
+	names         *nameRegistry // RegisterName 登记的角色名与别名
+	resolveCaches sync.Map      // *resolveInbox -> struct{}，NewResolveCache 创建的解析缓存
+	balancersMu   sync.Mutex
+	balancers     []*Balancer // NewBalancer 创建的负载均衡器，高负载时由监控扩容
+	stopping      atomic.Bool // Stop 或 Shutdown 已调用，不再接收新消息与新Actor
 }
 
 func NewSystem() *System {
+	return NewSystemWithConfig(DefaultSystemConfig())
+}
+
+// NewSystemWithConfig 按配置创建Actor系统，未设置的字段使用默认值
+func NewSystemWithConfig(cfg SystemConfig) *System {
+	def := DefaultSystemConfig()
+	if cfg.MailboxSize <= 0 {
+		cfg.MailboxSize = def.MailboxSize
+	}
+	if cfg.UrgentMailboxSize <= 0 {
+		cfg.UrgentMailboxSize = def.UrgentMailboxSize
+	}
+	if cfg.Dispatchers <= 0 {
+		cfg.Dispatchers = def.Dispatchers
+	}
+	if cfg.TickInterval <= 0 {
+		cfg.TickInterval = def.TickInterval
+	}
+	cfg.Monitor = cfg.Monitor.withDefaults()
+
 	sxt, cancel := context.WithCancel(context.Background())
-	return &System{
-		groups: make(map[int]*Group),
-		ctx:    sxt,
-		cancel: cancel,
+	s := &System{
+		config:        cfg,
+		groups:        make(map[int]*Group),
+		ctx:           sxt,
+		cancel:        cancel,
+		subscriptions: NewSubscriptionRegistry(),
+		deadLetters:   NewDeadLetters(0),
+		blackboard:    NewBlackboard(),
+		middleware:    &middlewareChain{},
+		names:         newNameRegistry(),
 	}
+	s.bus = newEventBus(s)
+	s.subscriptions.SetBinder(s.bus.bind)
+	s.blackboard.SetNotifier(s.bus.notifyBlackboard)
+	s.scheduler = newScheduler(s.Send)
+	go s.scheduler.run(sxt)
+	go monitorDeadLetters(sxt, s.deadLetters, 5*time.Second)
+	go s.monitor(sxt, cfg.Monitor)
+	return s
+}
+
+// Config 返回系统运行参数
+func (s *System) Config() SystemConfig {
+	return s.config
+}
+
+// NewBaseActor 按系统配置的邮箱容量与策略创建基础Actor，opts 可覆盖系统配置
+func (s *System) NewBaseActor(size uint64, opts ...BaseActorOption) *BaseActor {
+	opts = append([]BaseActorOption{
+		WithMailboxSize(s.config.MailboxSize, s.config.UrgentMailboxSize),
+		WithMailboxPolicy(s.config.MailboxPolicy),
+	}, opts...)
+	a := NewBaseActor(size, opts...)
+	a.attach(s)
+	return a
+}
+
+// NewBalancer 按系统配置的 worker 数创建负载均衡器
+func (s *System) NewBalancer() *Balancer {
+	b := NewBalancerWithWorkers(s.ctx, s.config.Dispatchers)
+	s.balancersMu.Lock()
+	s.balancers = append(s.balancers, b)
+	s.balancersMu.Unlock()
+	return b
+}
+
+// SubscriptionRegistry 返回系统级订阅登记表
+func (s *System) SubscriptionRegistry() *SubscriptionRegistry {
+	return s.subscriptions
+}
+
+// Blackboard 返回系统级共享黑板
+func (s *System) Blackboard() *Blackboard {
+	return s.blackboard
+}
+
+// Subscriptions 列出指定 Actor 的全部订阅
+func (s *System) Subscriptions(actorID int64) []Subscription {
+	return s.subscriptions.List(actorID)
 }
 
 // AddGroupActors 添加Actor组
 func (s *System) AddGroupActors(groupID int, creators []func() Actor) {
+	for _, create := range creators {
+		if err := s.addToGroup(groupID, create()); err != nil {
+			defaultLogger.Printf("group %d: %v", groupID, err)
+		}
+	}
+}
+
+// addToGroup 启动Actor（含 PreStart）并加入组，PreStart 失败时不加入
+func (s *System) addToGroup(groupID int, actor Actor) error {
 	s.FuncgroupLock.Lock()
 	defer s.FuncgroupLock.Unlock()
 
+	if s.stopping.Load() {
+		return ErrSystemStopping
+	}
 	g := s.getOrCreateGroup(groupID)
-	for _, create := range creators {
-		actor := create()
-		actor.Init(s.ctx)
-		g.AddActor(actor)
+	if base := baseOf(actor); base != nil {
+		base.attach(s)
 	}
+	if err := startActor(s.ctx, actor); err != nil {
+		return err
+	}
+	g.AddActor(actor)
+	return nil
 }
 
-// getOrCreateGroup 获取或创建组（双重检查锁）
-func (s *System) getOrCreateGroup(id int) *Group {
-	if g, ok := s.groups[id]; ok {
-		return g
+// RemoveGroupActor 从组中移除并停止Actor，同时注销其 ID 登记与订阅（玩家离开房间时调用）
+func (s *System) RemoveGroupActor(groupID int, id int64) bool {
+	s.FuncgroupLock.RLock()
+	g, ok := s.groups[groupID]
+	s.FuncgroupLock.RUnlock()
+	if !ok || !g.RemoveByID(id) {
+		return false
 	}
+	s.Unregister(id)
+	s.subscriptions.RemoveActor(id)
+	return true
+}
 
-	s.FuncgroupLock.Lock()
-	defer s.FuncgroupLock.Unlock()
-
+// getOrCreateGroup 获取或创建组（调用方需持有 FuncgroupLock 写锁）
+func (s *System) getOrCreateGroup(id int) *Group {
 	if g, ok := s.groups[id]; ok {
 		return g
 	}
 
-	g := NewGroup(id, 33*time.Millisecond)
+	g := NewGroup(id, s.config.TickInterval)
 	s.groups[id] = g
-	go g.StartUpdate()
+	go g.StartUpdateContext(s.ctx)
 	return g
 }
 
-// Stop 停止整个系统
+// Stop 立即停止整个系统：取消全部上下文，各Actor（含仅经 Register/RegisterName 登记的）排空邮箱后调用 PostStop。
+// 需要等待处理中的消息完成时使用 Shutdown
 func (s *System) Stop() {
-	s.cancel()
+	if !s.stopping.CompareAndSwap(false, true) {
+		return
+	}
 	s.FuncgroupLock.Lock()
 	defer s.FuncgroupLock.Unlock()
-	for _, g := range s.groups {
-		g.mu.Lock()
-		for _, a := range g.actors {
-			a.Stop()
-		}
-		g.mu.Unlock()
+	// 取消上下文前收集，此时仍能区分已单独停止的登记Actor
+	actors := s.stopGroups()
+	s.cancel()
+	for _, a := range actors {
+		stopActor(a)
 	}
 }
@@ -0,0 +1,92 @@
+package Actor
+
+//tick_controller.go
+import (
+	"math"
+	"time"
+)
+
+// TickConfig 自适应 tick 频率配置
+type TickConfig struct {
+	MinInterval  time.Duration // 最短间隔（最高频率），如 33ms ≈ 30Hz
+	MaxInterval  time.Duration // 最长间隔（最低频率），如 500ms = 2Hz
+	BusyMembers  int           // 成员数达到该值视为满负载
+	BusyMessages int64         // 单个评估窗口内消息数达到该值视为满负载
+	Hysteresis   float64       // 目标间隔与当前间隔相对差小于该比例时不调整
+	EvalEvery    int           // 每隔多少个 tick 评估一次
+	OnChange     func(RateChange)
+}
+
+// DefaultTickConfig 默认配置：30Hz ~ 2Hz
+func DefaultTickConfig() TickConfig {
+	return TickConfig{
+		MinInterval:  33 * time.Millisecond,
+		MaxInterval:  500 * time.Millisecond,
+		BusyMembers:  8,
+		BusyMessages: 200,
+		Hysteresis:   0.2,
+		EvalEvery:    30,
+	}
+}
+
+// RateChange tick 频率变化事件，客户端可据此调整插值
+type RateChange struct {
+	GroupID int
+	Old     time.Duration
+	New     time.Duration
+	Members int
+	Load    float64
+}
+
+// TickController 根据成员数与近期消息量在上下限之间调整 Group 的 tick 间隔
+type TickController struct {
+	cfg  TickConfig
+	tick int
+}
+
+// NewTickController 创建自适应控制器
+func NewTickController(cfg TickConfig) *TickController {
+	def := DefaultTickConfig()
+	if cfg.MinInterval <= 0 {
+		cfg.MinInterval = def.MinInterval
+	}
+	if cfg.MaxInterval < cfg.MinInterval {
+		cfg.MaxInterval = cfg.MinInterval
+	}
+	if cfg.BusyMembers <= 0 {
+		cfg.BusyMembers = def.BusyMembers
+	}
+	if cfg.BusyMessages <= 0 {
+		cfg.BusyMessages = def.BusyMessages
+	}
+	if cfg.EvalEvery <= 0 {
+		cfg.EvalEvery = def.EvalEvery
+	}
+	return &TickController{cfg: cfg}
+}
+
+// load 计算负载系数 [0,1]
+func (tc *TickController) load(members int, messages int64) float64 {
+	byMembers := float64(members) / float64(tc.cfg.BusyMembers)
+	byMessages := float64(messages) / float64(tc.cfg.BusyMessages)
+	return math.Min(1, math.Max(byMembers, byMessages))
+}
+
+// next 每个 tick 调用一次；到达评估点时返回新间隔与是否需要调整
+func (tc *TickController) next(current time.Duration, members int, messages int64) (time.Duration, float64, bool) {
+	tc.tick++
+	if tc.tick < tc.cfg.EvalEvery {
+		return current, 0, false
+	}
+	tc.tick = 0
+
+	load := tc.load(members, messages)
+	span := float64(tc.cfg.MaxInterval - tc.cfg.MinInterval)
+	target := tc.cfg.MaxInterval - time.Duration(load*span)
+
+	// 迟滞：变化幅度不足时保持当前间隔，避免来回抖动
+	if current > 0 && math.Abs(float64(target-current))/float64(current) < tc.cfg.Hysteresis {
+		return current, load, false
+	}
+	return target, load, target != current
+}
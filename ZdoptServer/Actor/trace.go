@@ -0,0 +1,152 @@
+package Actor
+
+//trace.go
+import (
+	"context"
+	"expvar"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"sync/atomic"
+	"time"
+	"zdopt/ZdoptServer/Logs"
+)
+
+var tracedSpans = expvar.NewInt("actors.trace.spans") // 导出的 span 数
+
+// Span 一条消息在一个Actor中的处理记录；同一调用链（含 Ask 回复与转发）共享 TraceID
+type Span struct {
+	TraceID     uint64
+	SpanID      uint64
+	ParentID    uint64 // 发出本消息的处理过程的 SpanID，调用链起点为 0
+	Actor       int64
+	Sender      int64
+	MessageType string
+	Start       time.Time
+	Duration    time.Duration
+}
+
+func (s Span) String() string {
+	return fmt.Sprintf("trace=%016x span=%016x parent=%016x actor=%d sender=%d type=%s took=%v",
+		s.TraceID, s.SpanID, s.ParentID, s.Actor, s.Sender, s.MessageType, s.Duration)
+}
+
+// SpanExporter 接收处理完成的 span，在处理协程中同步调用，应尽快返回
+type SpanExporter interface {
+	ExportSpan(Span)
+}
+
+// SpanExporterFunc 函数形式的 SpanExporter
+type SpanExporterFunc func(Span)
+
+func (f SpanExporterFunc) ExportSpan(s Span) {
+	f(s)
+}
+
+// LogSpanExporter 把 span 写入日志
+type LogSpanExporter struct {
+	Logger *log.Logger
+}
+
+// NewLogSpanExporter 创建写入 Trace 日志的导出器
+func NewLogSpanExporter() *LogSpanExporter {
+	return &LogSpanExporter{Logger: Logs.CreateConsoleLogConfig("Trace")}
+}
+
+func (e *LogSpanExporter) ExportSpan(s Span) {
+	e.Logger.Print(s.String())
+}
+
+type exporterBox struct {
+	SpanExporter
+}
+
+var spanExporter atomic.Pointer[exporterBox]
+
+// EnableTracing 开启消息追踪：每条消息分配调用链 ID 并随 Tell、Ask、Reply 与 Forward 传递，
+// 处理完成后把 span 交给 exporter。exporter 为 nil 时关闭追踪
+func EnableTracing(exporter SpanExporter) {
+	if exporter == nil {
+		spanExporter.Store(nil)
+		return
+	}
+	spanExporter.Store(&exporterBox{exporter})
+}
+
+// TracingEnabled 是否开启了消息追踪
+func TracingEnabled() bool {
+	return spanExporter.Load() != nil
+}
+
+// TraceRef 调用链位置：当前所在的调用链与处理过程
+type TraceRef struct {
+	TraceID uint64
+	SpanID  uint64
+}
+
+type traceKey struct{}
+
+// TraceFrom 读取 ctx（如处理器收到的 MessageContext）所在的调用链位置，未追踪时为零值
+func TraceFrom(ctx context.Context) TraceRef {
+	if ctx == nil {
+		return TraceRef{}
+	}
+	ref, _ := ctx.Value(traceKey{}).(TraceRef)
+	return ref
+}
+
+func newTraceID() uint64 {
+	for {
+		if id := rand.Uint64(); id != 0 {
+			return id
+		}
+	}
+}
+
+// stampTrace 开启追踪时为出站信封标记调用链：延续 parent，没有上游时作为新调用链的起点
+func stampTrace(env *Envelope, parent TraceRef) {
+	if !TracingEnabled() || env.TraceID != 0 {
+		return
+	}
+	if parent.TraceID != 0 {
+		env.TraceID, env.ParentSpan = parent.TraceID, parent.SpanID
+		return
+	}
+	env.TraceID = newTraceID()
+}
+
+// startSpan 开始处理一条消息：未标记调用链的消息作为新调用链的起点
+func startSpan(a *BaseActor, env *Envelope, msgType string, now time.Time) *Span {
+	if !TracingEnabled() {
+		return nil
+	}
+	s := &Span{SpanID: newTraceID(), Actor: a.id, MessageType: msgType, Start: now}
+	if env != nil {
+		s.TraceID, s.ParentID, s.Sender = env.TraceID, env.ParentSpan, env.Sender
+	}
+	if s.TraceID == 0 {
+		s.TraceID = newTraceID()
+	}
+	return s
+}
+
+// finishSpan 结束处理并导出
+func finishSpan(s *Span) {
+	if s == nil {
+		return
+	}
+	box := spanExporter.Load()
+	if box == nil {
+		return
+	}
+	s.Duration = time.Since(s.Start)
+	tracedSpans.Add(1)
+	box.ExportSpan(*s)
+}
+
+func (s *Span) ref() TraceRef {
+	if s == nil {
+		return TraceRef{}
+	}
+	return TraceRef{TraceID: s.TraceID, SpanID: s.SpanID}
+}
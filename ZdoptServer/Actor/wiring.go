@@ -0,0 +1,331 @@
+package Actor
+
+//wiring.go
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrUnknownFactory  = errors.New("unknown actor factory")
+	ErrUnknownKeyFunc  = errors.New("unknown router key func")
+	ErrInvalidTopology = errors.New("invalid actor topology")
+)
+
+var (
+	factoryMu sync.RWMutex
+	factories = make(map[string]Factory)
+	keyFuncs  = make(map[string]KeyFunc)
+)
+
+// FactoryContext 工厂创建单个实例时的参数
+type FactoryContext struct {
+	System  *System
+	Name    string // 实例名，Count 大于 1 时带序号后缀（worker-0、worker-1……）
+	Index   int
+	Params  json.RawMessage   // 配置中的 params 原文
+	Options []BaseActorOption // 配置中的邮箱设置
+}
+
+// NewBaseActor 按系统配置与本实例的邮箱设置创建基础Actor
+func (c *FactoryContext) NewBaseActor() *BaseActor {
+	return c.System.NewBaseActor(0, c.Options...)
+}
+
+// DecodeParams 把 params 解析到 v，未配置时不修改 v
+func (c *FactoryContext) DecodeParams(v interface{}) error {
+	if len(c.Params) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(c.Params, v); err != nil {
+		return fmt.Errorf("actor %s params: %w", c.Name, err)
+	}
+	return nil
+}
+
+// Factory 按名称登记的Actor构造函数，供配置声明的拓扑在启动时实例化
+type Factory func(ctx *FactoryContext) (Actor, error)
+
+// RegisterFactory 登记Actor工厂，同名覆盖
+func RegisterFactory(name string, f Factory) {
+	factoryMu.Lock()
+	defer factoryMu.Unlock()
+	factories[name] = f
+}
+
+// RegisterKeyFunc 登记一致性哈希路由键函数，供配置按名称引用
+func RegisterKeyFunc(name string, fn KeyFunc) {
+	factoryMu.Lock()
+	defer factoryMu.Unlock()
+	keyFuncs[name] = fn
+}
+
+// Factories 已登记的工厂名称
+func Factories() []string {
+	factoryMu.RLock()
+	defer factoryMu.RUnlock()
+	names := make([]string, 0, len(factories))
+	for name := range factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func lookupFactory(name string) (Factory, bool) {
+	factoryMu.RLock()
+	defer factoryMu.RUnlock()
+	f, ok := factories[name]
+	return f, ok
+}
+
+func lookupKeyFunc(name string) (KeyFunc, bool) {
+	factoryMu.RLock()
+	defer factoryMu.RUnlock()
+	fn, ok := keyFuncs[name]
+	return fn, ok
+}
+
+var routingStrategyNames = [...]string{"round_robin", "random", "broadcast", "consistent_hash"}
+
+func (s RoutingStrategy) String() string {
+	if s >= 0 && int(s) < len(routingStrategyNames) {
+		return routingStrategyNames[s]
+	}
+	return fmt.Sprintf("RoutingStrategy(%d)", int(s))
+}
+
+// ParseRoutingStrategy 解析策略名称（round_robin / random / broadcast / consistent_hash），空串为 RoundRobin
+func ParseRoutingStrategy(s string) (RoutingStrategy, error) {
+	if s == "" {
+		return RoundRobin, nil
+	}
+	for i, name := range routingStrategyNames {
+		if strings.EqualFold(s, name) {
+			return RoutingStrategy(i), nil
+		}
+	}
+	return RoundRobin, fmt.Errorf("unknown routing strategy %q", s)
+}
+
+// MailboxSpec 实例的邮箱设置，零值字段使用系统配置
+type MailboxSpec struct {
+	Size        int
+	UrgentSize  int
+	Policy      string
+	Processing  string // concurrent / sequential / keyed
+	OrderingKey string // keyed 模式的排序键函数名（RegisterKeyFunc），为空按发送者排序
+}
+
+// options 转换为构造选项
+func (m MailboxSpec) options() ([]BaseActorOption, error) {
+	var opts []BaseActorOption
+	if m.Size > 0 || m.UrgentSize > 0 {
+		opts = append(opts, WithMailboxSize(m.Size, m.UrgentSize))
+	}
+	if m.Policy != "" {
+		policy, err := ParseMailboxPolicy(m.Policy)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, WithMailboxPolicy(policy))
+	}
+	if m.Processing != "" {
+		mode, err := ParseProcessingMode(m.Processing)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, WithProcessingMode(mode))
+	}
+	if m.OrderingKey != "" {
+		key, ok := lookupKeyFunc(m.OrderingKey)
+		if !ok {
+			return nil, fmt.Errorf("%w: %q", ErrUnknownKeyFunc, m.OrderingKey)
+		}
+		opts = append(opts, WithOrderingKey(key))
+	}
+	return opts, nil
+}
+
+// ActorSpec 声明一组同类Actor实例
+type ActorSpec struct {
+	Name    string
+	Factory string
+	Group   int   // 加入的 tick 组
+	Count   int   // 实例数，默认 1
+	ID      int64 // 非零时按 ID、ID+1…… 登记到系统
+	Mailbox MailboxSpec
+	Params  json.RawMessage
+}
+
+func (a ActorSpec) count() int {
+	return max(a.Count, 1)
+}
+
+// RouterSpec 声明一个路由池：Routee 描述 worker，其 Count 为 worker 数
+type RouterSpec struct {
+	Name         string
+	Group        int
+	ID           int64
+	Strategy     string
+	Key          string // 一致性哈希键函数名（RegisterKeyFunc）
+	VirtualNodes int
+	Routee       ActorSpec
+}
+
+// GroupSpec 声明 tick 组参数
+type GroupSpec struct {
+	ID           int
+	TickInterval time.Duration
+}
+
+// Topology 配置声明的Actor拓扑
+type Topology struct {
+	Groups  []GroupSpec
+	Actors  []ActorSpec
+	Routers []RouterSpec
+}
+
+// Empty 是否未声明任何内容
+func (t Topology) Empty() bool {
+	return len(t.Groups) == 0 && len(t.Actors) == 0 && len(t.Routers) == 0
+}
+
+// Wiring 拓扑实例化结果，按声明名称查找
+type Wiring struct {
+	Actors  map[string][]Actor
+	Routers map[string]*Router
+}
+
+// Validate 检查工厂、键函数、策略名称与名称唯一性，不创建任何实例
+func (t Topology) Validate() error {
+	var errs error
+	names := make(map[string]bool)
+	checkName := func(kind, name string) {
+		switch {
+		case name == "":
+			errs = errors.Join(errs, fmt.Errorf("%w: %s without name", ErrInvalidTopology, kind))
+		case names[name]:
+			errs = errors.Join(errs, fmt.Errorf("%w: duplicate name %q", ErrInvalidTopology, name))
+		}
+		names[name] = true
+	}
+	checkActor := func(a ActorSpec) {
+		if _, ok := lookupFactory(a.Factory); !ok {
+			errs = errors.Join(errs, fmt.Errorf("%w: %q (actor %s)", ErrUnknownFactory, a.Factory, a.Name))
+		}
+		if _, err := a.Mailbox.options(); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("actor %s: %w", a.Name, err))
+		}
+	}
+	for _, a := range t.Actors {
+		checkName("actor", a.Name)
+		checkActor(a)
+	}
+	for _, r := range t.Routers {
+		checkName("router", r.Name)
+		if r.Routee.Name == "" {
+			r.Routee.Name = r.Name
+		}
+		checkActor(r.Routee)
+		strategy, err := ParseRoutingStrategy(r.Strategy)
+		if err != nil {
+			errs = errors.Join(errs, fmt.Errorf("router %s: %w", r.Name, err))
+		}
+		if r.Key != "" {
+			if _, ok := lookupKeyFunc(r.Key); !ok {
+				errs = errors.Join(errs, fmt.Errorf("%w: %q (router %s)", ErrUnknownKeyFunc, r.Key, r.Name))
+			}
+		} else if strategy == ConsistentHash {
+			errs = errors.Join(errs, fmt.Errorf("%w: router %s uses consistent_hash without key", ErrInvalidTopology, r.Name))
+		}
+	}
+	return errs
+}
+
+// Build 校验并实例化拓扑：设置组参数，创建并启动Actor与路由池，按声明登记 ID。
+// 校验失败时不创建任何实例；实例化中途失败时已启动的实例保留在系统中，随 System.Stop 停止
+func (s *System) Build(t Topology) (*Wiring, error) {
+	if err := t.Validate(); err != nil {
+		return nil, err
+	}
+	w := &Wiring{Actors: make(map[string][]Actor), Routers: make(map[string]*Router)}
+
+	s.FuncgroupLock.Lock()
+	for _, g := range t.Groups {
+		group := s.getOrCreateGroup(g.ID)
+		if g.TickInterval > 0 {
+			group.SetDeltaTime(g.TickInterval)
+		}
+	}
+	s.FuncgroupLock.Unlock()
+
+	for _, spec := range t.Actors {
+		actors, err := s.instantiate(spec)
+		if err != nil {
+			return w, err
+		}
+		for _, a := range actors {
+			if err := s.addToGroup(spec.Group, a); err != nil {
+				return w, fmt.Errorf("actor %s: %w", spec.Name, err)
+			}
+		}
+		w.Actors[spec.Name] = actors
+	}
+
+	for _, spec := range t.Routers {
+		if spec.Routee.Name == "" {
+			spec.Routee.Name = spec.Name
+		}
+		routees, err := s.instantiate(spec.Routee)
+		if err != nil {
+			return w, fmt.Errorf("router %s: %w", spec.Name, err)
+		}
+		strategy, _ := ParseRoutingStrategy(spec.Strategy)
+		cfg := RouterConfig{Strategy: strategy, VirtualNodes: spec.VirtualNodes}
+		if spec.Key != "" {
+			cfg.Key, _ = lookupKeyFunc(spec.Key)
+		}
+		r := NewRouter(cfg, routees...)
+		if spec.ID != 0 {
+			if err := s.Register(spec.ID, r); err != nil {
+				return w, fmt.Errorf("router %s: %w", spec.Name, err)
+			}
+		}
+		if err := s.addToGroup(spec.Group, r); err != nil {
+			return w, fmt.Errorf("router %s: %w", spec.Name, err)
+		}
+		w.Routers[spec.Name] = r
+	}
+	return w, nil
+}
+
+// instantiate 按声明创建实例（未启动），ID 非零时登记
+func (s *System) instantiate(spec ActorSpec) ([]Actor, error) {
+	factory, _ := lookupFactory(spec.Factory)
+	opts, _ := spec.Mailbox.options()
+	n := spec.count()
+	actors := make([]Actor, 0, n)
+	for i := 0; i < n; i++ {
+		name := spec.Name
+		if n > 1 {
+			name = fmt.Sprintf("%s-%d", spec.Name, i)
+		}
+		a, err := factory(&FactoryContext{System: s, Name: name, Index: i, Params: spec.Params, Options: opts})
+		if err != nil {
+			return actors, fmt.Errorf("create %s: %w", name, err)
+		}
+		if spec.ID != 0 {
+			if err := s.Register(spec.ID+int64(i), a); err != nil {
+				return actors, fmt.Errorf("create %s: %w", name, err)
+			}
+		}
+		actors = append(actors, a)
+	}
+	return actors, nil
+}
@@ -0,0 +1,254 @@
+package Admin
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"net"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+	"zdopt/ZdoptServer/Actor"
+	"zdopt/ZdoptServer/Logs"
+	"zdopt/ZdoptServer/Metrics"
+)
+
+var ErrNoAddr = errors.New("admin address not set")
+
+// shutdownTimeout ctx 结束后等待进行中请求完成的时限
+const shutdownTimeout = 5 * time.Second
+
+// Config 管理端参数
+type Config struct {
+	Addr  string         // 监听地址，如 127.0.0.1:6060
+	Token string         // 非空时除 /healthz 外要求 Authorization: Bearer <Token>
+	Store *Metrics.Store // 可选，挂载 /debug/timeseries
+}
+
+// Server 内嵌的 HTTP 管理端：/healthz、/metrics（expvar）、/actors、/groups、/sessions、/loglevel，
+// 以及 Metrics.AdminMux 的调试接口；其他模块的管理接口经 Handle 挂载
+type Server struct {
+	cfg       Config
+	mux       *http.ServeMux
+	mu        sync.RWMutex
+	listeners map[string]*Actor.KCPListener
+	loggers   map[string]*Logs.ZLogger
+	checks    map[string]func() error
+	addr      net.Addr
+}
+
+// New 创建管理端，system 为 nil 时不提供 Actor 相关接口
+func New(cfg Config, system *Actor.System) *Server {
+	s := &Server{
+		cfg:       cfg,
+		mux:       Metrics.AdminMux(cfg.Store),
+		listeners: make(map[string]*Actor.KCPListener),
+		loggers:   make(map[string]*Logs.ZLogger),
+		checks:    make(map[string]func() error),
+	}
+	s.mux.Handle("/healthz", http.HandlerFunc(s.healthz))
+	s.mux.Handle("/metrics", expvar.Handler())
+	s.mux.Handle("/sessions", getOnly(s.sessions))
+	s.mux.Handle("/loglevel", http.HandlerFunc(s.logLevel))
+	if system != nil {
+		s.mux.Handle("/actors", getOnly(func(w http.ResponseWriter, r *http.Request) {
+			writeJSON(w, http.StatusOK, system.Actors())
+		}))
+		s.mux.Handle("/groups", getOnly(func(w http.ResponseWriter, r *http.Request) {
+			writeJSON(w, http.StatusOK, system.Groups())
+		}))
+		s.AddCheck("actors", func() error {
+			if system.Stopping() {
+				return Actor.ErrSystemStopping
+			}
+			return nil
+		})
+	}
+	return s
+}
+
+// Handle 挂载其他模块的管理接口（同样受 Token 保护）
+func (s *Server) Handle(pattern string, h http.Handler) {
+	s.mux.Handle(pattern, h)
+}
+
+// Mux 底层路由，供按 *http.ServeMux 挂载接口的模块使用
+func (s *Server) Mux() *http.ServeMux {
+	return s.mux
+}
+
+// AddListener 登记监听，/sessions 按 name 列出其会话数
+func (s *Server) AddListener(name string, l *Actor.KCPListener) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.listeners[name] = l
+}
+
+// AddLogger 登记日志器，可经 /loglevel 在运行时调整级别
+func (s *Server) AddLogger(name string, l *Logs.ZLogger) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.loggers[name] = l
+}
+
+// AddCheck 登记健康检查，任一返回错误时 /healthz 返回 503
+func (s *Server) AddCheck(name string, check func() error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.checks[name] = check
+}
+
+// Handler 带鉴权的全部接口，供嵌入已有的 HTTP 服务
+func (s *Server) Handler() http.Handler {
+	if s.cfg.Token == "" {
+		return s.mux
+	}
+	want := []byte("Bearer " + s.cfg.Token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/healthz" && subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), want) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		s.mux.ServeHTTP(w, r)
+	})
+}
+
+// Start 开始监听，ctx 结束时停止（等待进行中的请求至多 5 秒）
+func (s *Server) Start(ctx context.Context) error {
+	if s.cfg.Addr == "" {
+		return ErrNoAddr
+	}
+	ln, err := net.Listen("tcp", s.cfg.Addr)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.addr = ln.Addr()
+	s.mu.Unlock()
+	srv := &http.Server{Handler: s.Handler(), ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-ctx.Done()
+		sctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		_ = srv.Shutdown(sctx)
+	}()
+	go func() {
+		_ = srv.Serve(ln)
+	}()
+	return nil
+}
+
+// Addr 实际监听地址，Start 前为 nil
+func (s *Server) Addr() net.Addr {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.addr
+}
+
+type healthReport struct {
+	Status string            `json:"status"` // ok / unavailable
+	Checks map[string]string `json:"checks"` // 检查名 -> ok 或错误信息
+}
+
+// healthz 供负载均衡与编排探活，不需要鉴权
+func (s *Server) healthz(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	checks := make(map[string]func() error, len(s.checks))
+	for name, check := range s.checks {
+		checks[name] = check
+	}
+	s.mu.RUnlock()
+
+	report := healthReport{Status: "ok", Checks: make(map[string]string, len(checks))}
+	status := http.StatusOK
+	for name, check := range checks {
+		if err := check(); err != nil {
+			report.Checks[name] = err.Error()
+			report.Status, status = "unavailable", http.StatusServiceUnavailable
+			continue
+		}
+		report.Checks[name] = "ok"
+	}
+	writeJSON(w, status, report)
+}
+
+type listenerSessions struct {
+	Sessions int                  `json:"sessions"`
+	Stats    []Actor.SessionStats `json:"stats,omitempty"`
+}
+
+// sessions GET 各监听的会话数，?detail=1 时附带每个会话的网络统计
+func (s *Server) sessions(w http.ResponseWriter, r *http.Request) {
+	detail := r.URL.Query().Get("detail") == "1"
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := struct {
+		Total     int                         `json:"total"`
+		Listeners map[string]listenerSessions `json:"listeners"`
+	}{Listeners: make(map[string]listenerSessions, len(s.listeners))}
+	for name, l := range s.listeners {
+		ls := listenerSessions{Sessions: l.SessionCount()}
+		if detail {
+			ls.Stats = l.Stats()
+			sort.Slice(ls.Stats, func(i, j int) bool { return ls.Stats[i].ID < ls.Stats[j].ID })
+		}
+		out.Total += ls.Sessions
+		out.Listeners[name] = ls
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// logLevel GET 列出日志器级别，POST ?logger=&level=debug|info|warn|error|fatal 调整级别
+func (s *Server) logLevel(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		s.mu.RLock()
+		levels := make(map[string]string, len(s.loggers))
+		for name, l := range s.loggers {
+			levels[name] = l.Level().String()
+		}
+		s.mu.RUnlock()
+		writeJSON(w, http.StatusOK, levels)
+
+	case http.MethodPost:
+		q := r.URL.Query()
+		level, err := Logs.ParseLevel(q.Get("level"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.mu.RLock()
+		l, ok := s.loggers[q.Get("logger")]
+		s.mu.RUnlock()
+		if !ok {
+			http.Error(w, "unknown logger: "+q.Get("logger"), http.StatusNotFound)
+			return
+		}
+		l.SetLevel(level)
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func getOnly(h http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", "GET")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		h(w, r)
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
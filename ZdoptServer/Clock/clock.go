@@ -0,0 +1,269 @@
+package Clock
+
+import (
+	"expvar"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+	"zdopt/ZdoptServer/Logs"
+)
+
+var (
+	jumps = expvar.NewMap("clock.jumps") // 按子系统统计检测到的时钟跳变
+
+	logger = Logs.CreateConsoleLogConfig("Clock")
+	start  = time.Now()
+)
+
+// Mono 进程启动以来的单调时长，不受墙上时钟调整影响，可安全存为整数比较
+func Mono() time.Duration {
+	return time.Since(start)
+}
+
+// Policy 检测到时钟跳变后的处理方式
+type Policy int
+
+const (
+	Skip        Policy = iota // 丢弃异常时长，按正常步长推进（默认）
+	FastForward               // 按实际经过的时长推进（不超过 MaxDelta），追上错过的逻辑
+	Pause                     // 暂停该子系统，等待人工恢复
+)
+
+func (p Policy) String() string {
+	switch p {
+	case Skip:
+		return "skip"
+	case FastForward:
+		return "fast_forward"
+	case Pause:
+		return "pause"
+	default:
+		return fmt.Sprintf("policy(%d)", int(p))
+	}
+}
+
+// ParsePolicy 解析配置中的策略名称，空串为 skip
+func ParsePolicy(name string) (Policy, error) {
+	switch name {
+	case "", "skip":
+		return Skip, nil
+	case "fast_forward":
+		return FastForward, nil
+	case "pause":
+		return Pause, nil
+	default:
+		return Skip, fmt.Errorf("unknown clock jump policy %q", name)
+	}
+}
+
+// Kind 跳变类型
+type Kind int
+
+const (
+	Stall    Kind = iota // 单调时钟两次观测间隔远超预期（进程被挂起、虚拟机暂停、严重卡顿）
+	WallStep             // 墙上时钟相对单调时钟跳变（NTP 校时、系统休眠后恢复）
+	Absurd               // 调用方传入的步长为负、非数或超过上限
+)
+
+func (k Kind) String() string {
+	switch k {
+	case Stall:
+		return "stall"
+	case WallStep:
+		return "wall_step"
+	case Absurd:
+		return "absurd"
+	default:
+		return fmt.Sprintf("kind(%d)", int(k))
+	}
+}
+
+// Jump 一次时钟跳变事件
+type Jump struct {
+	Subsystem string
+	Kind      Kind
+	Expected  time.Duration // 预期步长
+	Elapsed   time.Duration // 实际经过的时长（墙上时钟向前跳时取较大者）
+	Wall      time.Duration // 同一区间的墙上时钟时长
+	Policy    Policy
+	At        time.Time
+}
+
+func (j Jump) String() string {
+	return fmt.Sprintf("%s: %s, expected %v, elapsed %v (wall %v), policy %s",
+		j.Subsystem, j.Kind, j.Expected, j.Elapsed, j.Wall, j.Policy)
+}
+
+// Config 跳变检测参数与各子系统策略
+type Config struct {
+	StallFactor   float64           // 间隔超过预期的倍数视为卡顿
+	MinStall      time.Duration     // 同时超出预期至少该时长才视为卡顿，避免短间隔下的误报
+	WallTolerance time.Duration     // 墙上时钟与单调时钟偏差超过该值视为跳变
+	MaxDelta      time.Duration     // FastForward 单步推进的上限，也是步长合理性上限
+	Policies      map[string]Policy // 子系统 -> 策略，未列出的使用 Skip
+}
+
+// DefaultConfig 默认参数
+func DefaultConfig() Config {
+	return Config{
+		StallFactor:   4,
+		MinStall:      250 * time.Millisecond,
+		WallTolerance: time.Second,
+		MaxDelta:      5 * time.Second,
+	}
+}
+
+var (
+	current  atomic.Pointer[Config]
+	hooksMu  sync.RWMutex
+	hooks    []func(Jump)
+	defaults = DefaultConfig()
+)
+
+func init() {
+	Configure(DefaultConfig())
+}
+
+// Configure 应用跳变检测参数，零值字段使用默认值
+func Configure(cfg Config) {
+	if cfg.StallFactor <= 1 {
+		cfg.StallFactor = defaults.StallFactor
+	}
+	if cfg.MinStall <= 0 {
+		cfg.MinStall = defaults.MinStall
+	}
+	if cfg.WallTolerance <= 0 {
+		cfg.WallTolerance = defaults.WallTolerance
+	}
+	if cfg.MaxDelta <= 0 {
+		cfg.MaxDelta = defaults.MaxDelta
+	}
+	policies := make(map[string]Policy, len(cfg.Policies))
+	for name, p := range cfg.Policies {
+		policies[name] = p
+	}
+	cfg.Policies = policies
+	current.Store(&cfg)
+}
+
+// Settings 当前参数
+func Settings() Config {
+	return *current.Load()
+}
+
+// SetPolicy 设置子系统的跳变策略
+func SetPolicy(subsystem string, p Policy) {
+	cfg := Settings()
+	policies := make(map[string]Policy, len(cfg.Policies)+1)
+	for name, v := range cfg.Policies {
+		policies[name] = v
+	}
+	policies[subsystem] = p
+	cfg.Policies = policies
+	current.Store(&cfg)
+}
+
+// PolicyFor 子系统的跳变策略
+func PolicyFor(subsystem string) Policy {
+	return current.Load().Policies[subsystem]
+}
+
+// OnJump 注册跳变事件回调（如告警、上报），回调在检测方协程中同步执行，应尽快返回
+func OnJump(fn func(Jump)) {
+	hooksMu.Lock()
+	defer hooksMu.Unlock()
+	hooks = append(hooks, fn)
+}
+
+// Report 补全策略后记录并分发跳变事件，返回带策略的事件
+func Report(j Jump) Jump {
+	j.Policy = PolicyFor(j.Subsystem)
+	if j.At.IsZero() {
+		j.At = time.Now()
+	}
+	jumps.Add(j.Subsystem, 1)
+	logger.Printf("clock jump %s", j)
+
+	hooksMu.RLock()
+	fns := hooks
+	hooksMu.RUnlock()
+	for _, fn := range fns {
+		fn(j)
+	}
+	return j
+}
+
+// Clamp 检查调用方传入的步长：非正、非数或超过 MaxDelta 时报告 Absurd 跳变并按策略给出实际步长。
+// expected 为正常步长：Skip 按它推进（为 0 时丢弃本步），FastForward 按 MaxDelta 推进；
+// ok 为 false 表示本步应丢弃（Pause 策略、步长非正或 Skip 没有正常步长）
+func Clamp(subsystem string, delta, expected time.Duration) (time.Duration, Jump, bool) {
+	limit := current.Load().MaxDelta
+	if delta > 0 && delta <= limit {
+		return delta, Jump{}, true
+	}
+	j := Report(Jump{Subsystem: subsystem, Kind: Absurd, Expected: expected, Elapsed: delta})
+	if delta <= 0 {
+		return 0, j, false
+	}
+	switch j.Policy {
+	case Pause:
+		return 0, j, false
+	case FastForward:
+		return limit, j, true
+	default:
+		if expected <= 0 {
+			return 0, j, false
+		}
+		return min(expected, limit), j, true
+	}
+}
+
+// Detector 周期循环的跳变检测：比较相邻两次观测的单调时长与墙上时长
+type Detector struct {
+	subsystem string
+	last      time.Time
+}
+
+// NewDetector 创建检测器
+func NewDetector(subsystem string) *Detector {
+	return &Detector{subsystem: subsystem}
+}
+
+// Reset 丢弃上一次观测（暂停恢复、修改间隔后调用，避免把等待时间当作跳变）
+func (d *Detector) Reset() {
+	d.last = time.Time{}
+}
+
+// Observe 记录一次 tick，expected 为预期间隔；检测到跳变时返回事件（已报告）与按策略给出的步长
+func (d *Detector) Observe(now time.Time, expected time.Duration) (time.Duration, *Jump) {
+	last := d.last
+	d.last = now
+	if last.IsZero() {
+		return expected, nil
+	}
+	cfg := current.Load()
+	mono := now.Sub(last)
+	wall := now.Round(0).Sub(last.Round(0))
+
+	j := Jump{Subsystem: d.subsystem, Expected: expected, Elapsed: mono, Wall: wall, At: now}
+	switch {
+	case float64(mono) > float64(expected)*cfg.StallFactor && mono-expected > cfg.MinStall:
+		j.Kind = Stall
+	case wall-mono > cfg.WallTolerance || mono-wall > cfg.WallTolerance:
+		j.Kind = WallStep
+		// 休眠期间单调时钟可能不走，墙上时钟向前跳时以其为准
+		j.Elapsed = max(mono, wall)
+	default:
+		return expected, nil
+	}
+	j = Report(j)
+	switch j.Policy {
+	case FastForward:
+		return min(j.Elapsed, cfg.MaxDelta), &j
+	case Pause:
+		return 0, &j
+	default:
+		return expected, &j
+	}
+}
@@ -0,0 +1,34 @@
+package Clock
+
+import (
+	"testing"
+	"time"
+)
+
+func TestClampPolicies(t *testing.T) {
+	limit := Settings().MaxDelta
+	huge := limit * 100
+	normal := 33 * time.Millisecond
+
+	cases := []struct {
+		policy   Policy
+		expected time.Duration
+		step     time.Duration
+		ok       bool
+	}{
+		{Skip, normal, normal, true},
+		{Skip, 0, 0, false},
+		{FastForward, normal, limit, true},
+		{Pause, normal, 0, false},
+	}
+	for _, c := range cases {
+		SetPolicy("test", c.policy)
+		step, j, ok := Clamp("test", huge, c.expected)
+		if step != c.step || ok != c.ok || j.Kind != Absurd || j.Policy != c.policy {
+			t.Errorf("%v: Clamp = %v, %v (jump %v), want %v, %v", c.policy, step, ok, j.Kind, c.step, c.ok)
+		}
+	}
+	if step, _, ok := Clamp("test", normal, normal); step != normal || !ok {
+		t.Errorf("normal step clamped to %v, %v", step, ok)
+	}
+}
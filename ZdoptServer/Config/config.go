@@ -0,0 +1,461 @@
+package Config
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+	"zdopt/ZdoptServer/Actor"
+	"zdopt/ZdoptServer/Admin"
+	"zdopt/ZdoptServer/Clock"
+	"zdopt/ZdoptServer/License"
+	"zdopt/ZdoptServer/Limit"
+	"zdopt/ZdoptServer/Metrics"
+	"zdopt/ZdoptServer/Net"
+	"zdopt/ZdoptServer/Script"
+	"zdopt/ZdoptServer/Strict"
+)
+
+var ErrUnknownPreset = errors.New("unknown config preset")
+
+// Duration 支持 "33ms"、"1s" 写法的 JSON 时长
+type Duration time.Duration
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		// 兼容纳秒整数写法
+		var n int64
+		if err := json.Unmarshal(data, &n); err != nil {
+			return fmt.Errorf("invalid duration %s", data)
+		}
+		*d = Duration(n)
+		return nil
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// ActorConfig Actor 系统参数，零值字段使用预设值
+type ActorConfig struct {
+	MailboxSize       int      `json:"mailbox_size,omitempty"`
+	UrgentMailboxSize int      `json:"urgent_mailbox_size,omitempty"`
+	Dispatchers       int      `json:"dispatchers,omitempty"`
+	TickInterval      Duration `json:"tick_interval,omitempty"`
+	PoolWarmup        int      `json:"pool_warmup,omitempty"`    // 启动时预热的对象数
+	MailboxPolicy     string   `json:"mailbox_policy,omitempty"` // block / drop_newest / drop_oldest / return_error
+}
+
+// StrictConfig 严格模式（调试用不变量检查），发布构建（-tags zdopt_release）中无效
+type StrictConfig struct {
+	Enabled         bool     `json:"enabled,omitempty"`
+	Panic           bool     `json:"panic,omitempty"`
+	TimerDriftLimit Duration `json:"timer_drift_limit,omitempty"`
+}
+
+// HeartbeatConfig 自适应心跳边界，零值字段使用默认值
+type HeartbeatConfig struct {
+	MinInterval Duration `json:"min_interval,omitempty"`
+	MaxInterval Duration `json:"max_interval,omitempty"`
+	MinTimeout  Duration `json:"min_timeout,omitempty"`
+	MaxTimeout  Duration `json:"max_timeout,omitempty"`
+	MissedBeats int      `json:"missed_beats,omitempty"`
+	LossWindow  int      `json:"loss_window,omitempty"`
+	IdleTimeout Duration `json:"idle_timeout,omitempty"` // 超时未收到应用消息时关闭会话，0 表示不限制
+}
+
+// RateLimitConfig 每个会话的入站限流，0 表示不限制；policy 为 drop（默认）/ warn / disconnect
+type RateLimitConfig struct {
+	Messages int    `json:"messages,omitempty"` // 每秒应用消息数
+	Bytes    int    `json:"bytes,omitempty"`    // 每秒字节数
+	Policy   string `json:"policy,omitempty"`
+}
+
+// CompressionConfig 负载压缩，只对握手协商了 compression 特性的会话生效；threshold 为尝试压缩的最小负载字节数。
+// dictionaries 为预训练字典目录（<class>.dict），classes 把消息 ID 归入类别，协商了字典的会话对这些消息使用字典压缩
+type CompressionConfig struct {
+	Enabled      bool                `json:"enabled,omitempty"`
+	Threshold    int                 `json:"threshold,omitempty"`
+	Dictionaries string              `json:"dictionaries,omitempty"`
+	Classes      map[string][]uint32 `json:"classes,omitempty"`
+}
+
+// ResumeConfig 断线重连恢复会话：window 为保留的未确认消息数，ttl 为断线后会话保留时长
+type ResumeConfig struct {
+	Enabled bool     `json:"enabled,omitempty"`
+	Window  int      `json:"window,omitempty"`
+	TTL     Duration `json:"ttl,omitempty"`
+}
+
+// ScriptConfig 脚本模块，Dir 为空时不启用
+type ScriptConfig struct {
+	Dir       string   `json:"dir,omitempty"`
+	Timeout   Duration `json:"timeout,omitempty"`    // 单次调用时间上限
+	CPUBudget Duration `json:"cpu_budget,omitempty"` // 每个脚本每秒可用的执行时间
+}
+
+// LimitConfig 全局并发上限：global 为所有子系统合计的协程额度，quotas 按子系统（actor.batch、actor.tick、timer.keyframe 等）限制
+type LimitConfig struct {
+	Global int64            `json:"global,omitempty"`
+	Quotas map[string]int64 `json:"quotas,omitempty"`
+}
+
+// ClockConfig 时钟跳变处理：policies 按子系统（actor.group、timer 等）设置 skip / fast_forward / pause
+type ClockConfig struct {
+	MaxDelta      Duration          `json:"max_delta,omitempty"`      // 单步推进上限
+	WallTolerance Duration          `json:"wall_tolerance,omitempty"` // 墙上时钟偏差容忍度
+	Policies      map[string]string `json:"policies,omitempty"`
+}
+
+// LicenseConfig 托管授权：file 为签名的上限文件，public_key 为 base64 编码的 Ed25519 验签公钥，node 为本节点名
+type LicenseConfig struct {
+	File      string `json:"file,omitempty"`
+	PublicKey string `json:"public_key,omitempty"`
+	Node      string `json:"node,omitempty"`
+}
+
+// AdminConfig HTTP 管理端：addr 为空时不启用；token 非空时除 /healthz 外要求 Bearer 令牌；
+// backup_dir 非空时在 /admin/backup 提供持久化存储的备份与恢复，备份写入该目录
+type AdminConfig struct {
+	Addr      string `json:"addr,omitempty"`
+	Token     string `json:"token,omitempty"`
+	BackupDir string `json:"backup_dir,omitempty"`
+}
+
+// TransportConfig 传输加密：cipher 为 KCP 分组加密（none / aes / aes-128 / aes-192 / salsa20 / sm4 / twofish），
+// key 为两端一致的预共享密钥；kcp 段为每个会话的协议调优；tls 段配置 TCP 监听（见 tcp_port）的证书
+type TransportConfig struct {
+	Cipher       string    `json:"cipher,omitempty"`
+	Key          string    `json:"key,omitempty"`
+	Salt         string    `json:"salt,omitempty"`
+	DataShards   int       `json:"data_shards,omitempty"`
+	ParityShards int       `json:"parity_shards,omitempty"`
+	KCP          KCPConfig `json:"kcp"`
+	TLS          TLSConfig `json:"tls"`
+}
+
+// KCPConfig KCP 调优：profile 为 turbo（默认）/ fast / normal，其余非零字段覆盖档位的值
+type KCPConfig struct {
+	Profile    string `json:"profile,omitempty"`
+	Interval   int    `json:"interval,omitempty"` // 毫秒
+	Resend     int    `json:"resend,omitempty"`
+	SndWnd     int    `json:"sndwnd,omitempty"`
+	RcvWnd     int    `json:"rcvwnd,omitempty"`
+	MTU        int    `json:"mtu,omitempty"`
+	AckNoDelay bool   `json:"ack_nodelay,omitempty"`
+	DisableFEC bool   `json:"disable_fec,omitempty"`
+}
+
+// TLSConfig TCP 监听证书，cert_file 为空时不启用；client_ca_file 非空时校验客户端证书
+type TLSConfig struct {
+	CertFile     string `json:"cert_file,omitempty"`
+	KeyFile      string `json:"key_file,omitempty"`
+	ClientCAFile string `json:"client_ca_file,omitempty"`
+	MinVersion   string `json:"min_version,omitempty"` // "1.2"（默认）或 "1.3"
+}
+
+// Config 服务配置
+type Config struct {
+	Preset      Preset            `json:"preset,omitempty"`
+	Port        int               `json:"port,omitempty"`
+	UDPPort     int               `json:"udp_port,omitempty"` // 不可靠 UDP 通道端口（见 Actor.WithUDP），0 表示不开启
+	TCPPort     int               `json:"tcp_port,omitempty"` // TCP 会话端口（见 Actor.WithTCP），配置了 transport.tls 时为 TLS，0 表示不开启
+	Actor       ActorConfig       `json:"actor"`
+	Strict      StrictConfig      `json:"strict"`
+	Heartbeat   HeartbeatConfig   `json:"heartbeat"`
+	RateLimit   RateLimitConfig   `json:"rate_limit"`
+	Compression CompressionConfig `json:"compression"`
+	Resume      ResumeConfig      `json:"resume"`
+	Script      ScriptConfig      `json:"script"`
+	Topology    TopologyConfig    `json:"topology"`
+	Routing     RoutesConfig      `json:"routing"`
+	Limits      LimitConfig       `json:"limits"`
+	Clock       ClockConfig       `json:"clock"`
+	License     LicenseConfig     `json:"license"`
+	Admin       AdminConfig       `json:"admin"`
+	Transport   TransportConfig   `json:"transport"`
+	// Modules 启用的可选模块（Lifecycle.RegisterModule 登记的名称）及其配置段
+	Modules map[string]json.RawMessage `json:"modules,omitempty"`
+}
+
+// Default 默认配置（SmallGame 预设）
+func Default() *Config {
+	cfg := &Config{Preset: SmallGame, Port: 7777}
+	cfg.Actor, _ = PresetActorConfig(SmallGame)
+	return cfg
+}
+
+// Load 从 JSON 文件加载配置：先套用预设，再以文件中显式设置的字段覆盖
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read config: %w", err)
+	}
+	return Parse(data)
+}
+
+// Parse 解析 JSON 配置
+func Parse(data []byte) (*Config, error) {
+	cfg := &Config{}
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("parse config: %w", err)
+	}
+	if cfg.Preset == "" {
+		cfg.Preset = SmallGame
+	}
+	if cfg.Port == 0 {
+		cfg.Port = 7777
+	}
+
+	preset, err := PresetActorConfig(cfg.Preset)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Actor = overlay(preset, cfg.Actor)
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("parse config: %w", err)
+	}
+	return cfg, nil
+}
+
+// Validate 校验端口范围与各段中的策略、拓扑、路由、传输及授权公钥配置
+func (c *Config) Validate() error {
+	if c.Port <= 0 || c.Port > 65535 {
+		return fmt.Errorf("port %d out of range", c.Port)
+	}
+	if c.UDPPort < 0 || c.UDPPort > 65535 {
+		return fmt.Errorf("udp_port %d out of range", c.UDPPort)
+	}
+	if c.TCPPort < 0 || c.TCPPort > 65535 {
+		return fmt.Errorf("tcp_port %d out of range", c.TCPPort)
+	}
+	if _, err := Actor.ParseMailboxPolicy(c.Actor.MailboxPolicy); err != nil {
+		return err
+	}
+	if _, err := Actor.ParseRatePolicy(c.RateLimit.Policy); err != nil {
+		return fmt.Errorf("rate_limit: %w", err)
+	}
+	for name, policy := range c.Clock.Policies {
+		if _, err := Clock.ParsePolicy(policy); err != nil {
+			return fmt.Errorf("clock policy for %s: %w", name, err)
+		}
+	}
+	if err := c.Topology.validate(); err != nil {
+		return err
+	}
+	if _, err := c.Routing.MessageRouter(nil); err != nil {
+		return fmt.Errorf("routing: %w", err)
+	}
+	if _, err := Net.KCPProfile(c.Transport.KCP.Profile); err != nil {
+		return err
+	}
+	if err := c.Transport.TransportConfig().Validate(); err != nil {
+		return err
+	}
+	if c.License.File != "" {
+		if _, err := c.License.publicKey(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// overlay 以 override 中的非零字段覆盖 base
+func overlay(base, override ActorConfig) ActorConfig {
+	if override.MailboxSize > 0 {
+		base.MailboxSize = override.MailboxSize
+	}
+	if override.UrgentMailboxSize > 0 {
+		base.UrgentMailboxSize = override.UrgentMailboxSize
+	}
+	if override.Dispatchers > 0 {
+		base.Dispatchers = override.Dispatchers
+	}
+	if override.TickInterval > 0 {
+		base.TickInterval = override.TickInterval
+	}
+	if override.PoolWarmup > 0 {
+		base.PoolWarmup = override.PoolWarmup
+	}
+	if override.MailboxPolicy != "" {
+		base.MailboxPolicy = override.MailboxPolicy
+	}
+	return base
+}
+
+// SystemConfig 转换为 Actor 系统参数
+func (c ActorConfig) SystemConfig() Actor.SystemConfig {
+	// Parse 已校验策略名称，未经 Parse 构造的非法名称按 Block 处理
+	policy, _ := Actor.ParseMailboxPolicy(c.MailboxPolicy)
+	return Actor.SystemConfig{
+		MailboxSize:       c.MailboxSize,
+		UrgentMailboxSize: c.UrgentMailboxSize,
+		Dispatchers:       c.Dispatchers,
+		TickInterval:      time.Duration(c.TickInterval),
+		MailboxPolicy:     policy,
+	}
+}
+
+// StrictConfig 转换为严格模式参数
+func (c StrictConfig) StrictConfig() Strict.Config {
+	return Strict.Config{
+		Enabled:         c.Enabled,
+		Panic:           c.Panic,
+		TimerDriftLimit: time.Duration(c.TimerDriftLimit),
+	}
+}
+
+// HeartbeatConfig 转换为网络层心跳参数
+func (c HeartbeatConfig) HeartbeatConfig() Net.HeartbeatConfig {
+	return Net.HeartbeatConfig{
+		MinInterval: time.Duration(c.MinInterval),
+		MaxInterval: time.Duration(c.MaxInterval),
+		MinTimeout:  time.Duration(c.MinTimeout),
+		MaxTimeout:  time.Duration(c.MaxTimeout),
+		MissedBeats: c.MissedBeats,
+		LossWindow:  c.LossWindow,
+	}
+}
+
+// SessionRateLimit 转换为会话入站限流参数
+func (c RateLimitConfig) SessionRateLimit() Actor.SessionRateLimit {
+	policy, _ := Actor.ParseRatePolicy(c.Policy)
+	return Actor.SessionRateLimit{Messages: c.Messages, Bytes: c.Bytes, Policy: policy}
+}
+
+// Compression 创建压缩器，未启用时返回 nil；maxSize 为解压后负载上限
+func (c CompressionConfig) Compression(maxSize int) (*Net.Compression, error) {
+	if !c.Enabled {
+		return nil, nil
+	}
+	comp, err := Net.NewCompression(c.Threshold, maxSize)
+	if err != nil || c.Dictionaries == "" {
+		return comp, err
+	}
+	dicts := Net.NewDictionaries()
+	if err := dicts.LoadDir(c.Dictionaries); err != nil {
+		return nil, fmt.Errorf("load dictionaries: %w", err)
+	}
+	for class, ids := range c.Classes {
+		dicts.Bind(class, ids...)
+	}
+	comp.SetDictionaries(dicts)
+	return comp, nil
+}
+
+// AdminConfig 转换为管理端参数，store 可为 nil
+func (c AdminConfig) AdminConfig(store *Metrics.Store) Admin.Config {
+	return Admin.Config{Addr: c.Addr, Token: c.Token, Store: store}
+}
+
+// ResumeConfig 转换为会话恢复参数
+func (c ResumeConfig) ResumeConfig() Actor.ResumeConfig {
+	return Actor.ResumeConfig{Window: c.Window, TTL: time.Duration(c.TTL)}
+}
+
+// TransportConfig 转换为传输层参数，未配置分片数时使用 Net.DefaultTransportConfig 的 FEC 参数，与默认参数的客户端一致
+func (c TransportConfig) TransportConfig() Net.TransportConfig {
+	t := Net.TransportConfig{
+		Cipher:       c.Cipher,
+		Key:          c.Key,
+		Salt:         c.Salt,
+		DataShards:   c.DataShards,
+		ParityShards: c.ParityShards,
+		KCP:          c.KCP.KCPConfig(),
+		TLS:          Net.TLSConfig(c.TLS),
+	}
+	if c.DataShards == 0 && c.ParityShards == 0 {
+		def := Net.DefaultTransportConfig()
+		t.DataShards, t.ParityShards = def.DataShards, def.ParityShards
+	}
+	return t
+}
+
+// KCPConfig 转换为 KCP 协议参数
+func (c KCPConfig) KCPConfig() Net.KCPConfig {
+	// Parse 已校验档位名称，未经 Parse 构造的非法名称按默认档位处理
+	k, err := Net.KCPProfile(c.Profile)
+	if err != nil {
+		k = Net.DefaultKCPConfig()
+	}
+	if c.Interval > 0 {
+		k.Interval = c.Interval
+	}
+	if c.Resend > 0 {
+		k.Resend = c.Resend
+	}
+	if c.SndWnd > 0 {
+		k.SndWnd = c.SndWnd
+	}
+	if c.RcvWnd > 0 {
+		k.RcvWnd = c.RcvWnd
+	}
+	if c.MTU > 0 {
+		k.MTU = c.MTU
+	}
+	k.AckNoDelay = c.AckNoDelay
+	k.FEC = !c.DisableFEC
+	return k
+}
+
+// LimitConfig 转换为并发限制器参数
+func (c LimitConfig) LimitConfig() Limit.Config {
+	return Limit.Config{Global: c.Global, Quotas: c.Quotas}
+}
+
+// ClockConfig 转换为时钟跳变检测参数
+func (c ClockConfig) ClockConfig() Clock.Config {
+	cfg := Clock.Config{
+		MaxDelta:      time.Duration(c.MaxDelta),
+		WallTolerance: time.Duration(c.WallTolerance),
+		Policies:      make(map[string]Clock.Policy, len(c.Policies)),
+	}
+	for name, policy := range c.Policies {
+		// Parse 已校验策略名称，未经 Parse 构造的非法名称按 Skip 处理
+		cfg.Policies[name], _ = Clock.ParsePolicy(policy)
+	}
+	return cfg
+}
+
+// EngineConfig 转换为脚本引擎参数
+func (c ScriptConfig) EngineConfig() Script.Config {
+	return Script.Config{
+		Dir:       c.Dir,
+		Timeout:   time.Duration(c.Timeout),
+		CPUBudget: time.Duration(c.CPUBudget),
+	}
+}
+
+// Limits 读取并校验签名的授权文件，未配置文件时返回不限制的上限
+func (c LicenseConfig) Limits() (License.Limits, error) {
+	if c.File == "" {
+		return License.Limits{}, nil
+	}
+	pub, err := c.publicKey()
+	if err != nil {
+		return License.Limits{}, err
+	}
+	return License.Load(c.File, pub, c.Node)
+}
+
+func (c LicenseConfig) publicKey() (ed25519.PublicKey, error) {
+	key, err := base64.StdEncoding.DecodeString(c.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("license public key: %w", err)
+	}
+	if len(key) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("license public key: want %d bytes, got %d", ed25519.PublicKeySize, len(key))
+	}
+	return ed25519.PublicKey(key), nil
+}
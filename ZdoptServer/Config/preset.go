@@ -0,0 +1,67 @@
+package Config
+
+import (
+	"fmt"
+	"runtime"
+	"sort"
+	"time"
+)
+
+// Preset 常见部署拓扑的预设
+type Preset string
+
+const (
+	SmallGame   Preset = "SmallGame"   // 小型房间制对局：少量 Actor，高 tick
+	LargeWorld  Preset = "LargeWorld"  // 大世界/MMO：大量 Actor，大邮箱，多 worker，对象池充分预热
+	GatewayOnly Preset = "GatewayOnly" // 纯网关：几乎无逻辑 tick，邮箱以吞吐为主
+)
+
+// presets 预设参数表，Dispatchers 为 0 时按 CPU 核数推导
+var presets = map[Preset]func() ActorConfig{
+	SmallGame: func() ActorConfig {
+		return ActorConfig{
+			MailboxSize:       512,
+			UrgentMailboxSize: 128,
+			Dispatchers:       runtime.NumCPU(),
+			TickInterval:      Duration(33 * time.Millisecond),
+			PoolWarmup:        256,
+		}
+	},
+	LargeWorld: func() ActorConfig {
+		return ActorConfig{
+			MailboxSize:       4096,
+			UrgentMailboxSize: 1024,
+			Dispatchers:       runtime.NumCPU() * 2,
+			TickInterval:      Duration(50 * time.Millisecond),
+			PoolWarmup:        8192,
+		}
+	},
+	GatewayOnly: func() ActorConfig {
+		return ActorConfig{
+			MailboxSize:       8192,
+			UrgentMailboxSize: 512,
+			Dispatchers:       runtime.NumCPU(),
+			TickInterval:      Duration(time.Second),
+			PoolWarmup:        0,
+		}
+	},
+}
+
+// PresetActorConfig 返回预设的 Actor 参数
+func PresetActorConfig(p Preset) (ActorConfig, error) {
+	fn, ok := presets[p]
+	if !ok {
+		return ActorConfig{}, fmt.Errorf("%w: %q", ErrUnknownPreset, p)
+	}
+	return fn(), nil
+}
+
+// Presets 返回所有可选预设名称
+func Presets() []Preset {
+	names := make([]Preset, 0, len(presets))
+	for p := range presets {
+		names = append(names, p)
+	}
+	sort.Slice(names, func(i, j int) bool { return names[i] < names[j] })
+	return names
+}
@@ -0,0 +1,144 @@
+package Config
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+	"zdopt/ZdoptServer/Actor"
+)
+
+// MailboxConfig 实例邮箱设置，零值字段使用 actor 段的系统配置
+type MailboxConfig struct {
+	Size       int    `json:"size,omitempty"`
+	UrgentSize int    `json:"urgent_size,omitempty"`
+	Policy     string `json:"policy,omitempty"`       // block / drop_newest / drop_oldest / return_error
+	Processing string `json:"processing,omitempty"`   // concurrent / sequential / keyed
+	Ordering   string `json:"ordering_key,omitempty"` // keyed 模式的排序键函数名
+}
+
+// ActorDecl 声明一组同类 Actor，factory 为代码中以 Actor.RegisterFactory 登记的名称
+type ActorDecl struct {
+	Name    string          `json:"name"`
+	Factory string          `json:"factory"`
+	Group   int             `json:"group,omitempty"`
+	Count   int             `json:"count,omitempty"`
+	ID      int64           `json:"id,omitempty"`
+	Mailbox MailboxConfig   `json:"mailbox"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// RouterDecl 声明路由池，routee.count 为 worker 数
+type RouterDecl struct {
+	Name         string    `json:"name"`
+	Group        int       `json:"group,omitempty"`
+	ID           int64     `json:"id,omitempty"`
+	Strategy     string    `json:"strategy,omitempty"` // round_robin / random / broadcast / consistent_hash
+	Key          string    `json:"key,omitempty"`      // 一致性哈希键函数名
+	VirtualNodes int       `json:"virtual_nodes,omitempty"`
+	Routee       ActorDecl `json:"routee"`
+}
+
+// GroupDecl 声明 tick 组
+type GroupDecl struct {
+	ID           int      `json:"id"`
+	TickInterval Duration `json:"tick_interval,omitempty"`
+}
+
+// TopologyConfig 启动时实例化的 Actor 拓扑
+type TopologyConfig struct {
+	Groups  []GroupDecl  `json:"groups,omitempty"`
+	Actors  []ActorDecl  `json:"actors,omitempty"`
+	Routers []RouterDecl `json:"routers,omitempty"`
+}
+
+// RouteDecl 把网络消息 ID 路由到名为 actor 的 Actor
+type RouteDecl struct {
+	ID    uint32 `json:"id"`
+	Actor string `json:"actor"`
+}
+
+// RoutesConfig 网络消息路由表，fallback 非空时未配置的消息投递到该 Actor
+type RoutesConfig struct {
+	Routes   []RouteDecl `json:"routes,omitempty"`
+	Fallback string      `json:"fallback,omitempty"`
+}
+
+// MessageRouter 按配置创建路由表，消息 ID 为控制帧或重复时返回错误
+func (c RoutesConfig) MessageRouter(system *Actor.System) (*Actor.MessageRouter, error) {
+	r := Actor.NewMessageRouter(system)
+	for _, d := range c.Routes {
+		if err := r.Route(d.ID, d.Actor); err != nil {
+			return nil, fmt.Errorf("route %d: %w", d.ID, err)
+		}
+	}
+	r.SetFallback(c.Fallback)
+	return r, nil
+}
+
+// validate 检查与工厂无关的字段（策略名称），工厂是否存在在 System.Build 时检查
+func (c TopologyConfig) validate() error {
+	check := func(kind, name string, m MailboxConfig) error {
+		if _, err := Actor.ParseMailboxPolicy(m.Policy); err != nil {
+			return fmt.Errorf("%s %s: %w", kind, name, err)
+		}
+		if _, err := Actor.ParseProcessingMode(m.Processing); err != nil {
+			return fmt.Errorf("%s %s: %w", kind, name, err)
+		}
+		return nil
+	}
+	for _, a := range c.Actors {
+		if err := check("actor", a.Name, a.Mailbox); err != nil {
+			return err
+		}
+	}
+	for _, r := range c.Routers {
+		if err := check("router", r.Name, r.Routee.Mailbox); err != nil {
+			return err
+		}
+		if _, err := Actor.ParseRoutingStrategy(r.Strategy); err != nil {
+			return fmt.Errorf("router %s: %w", r.Name, err)
+		}
+	}
+	return nil
+}
+
+// Topology 转换为 Actor 拓扑声明
+func (c TopologyConfig) Topology() Actor.Topology {
+	var t Actor.Topology
+	for _, g := range c.Groups {
+		t.Groups = append(t.Groups, Actor.GroupSpec{ID: g.ID, TickInterval: time.Duration(g.TickInterval)})
+	}
+	for _, a := range c.Actors {
+		t.Actors = append(t.Actors, a.spec())
+	}
+	for _, r := range c.Routers {
+		t.Routers = append(t.Routers, Actor.RouterSpec{
+			Name:         r.Name,
+			Group:        r.Group,
+			ID:           r.ID,
+			Strategy:     r.Strategy,
+			Key:          r.Key,
+			VirtualNodes: r.VirtualNodes,
+			Routee:       r.Routee.spec(),
+		})
+	}
+	return t
+}
+
+func (a ActorDecl) spec() Actor.ActorSpec {
+	return Actor.ActorSpec{
+		Name:    a.Name,
+		Factory: a.Factory,
+		Group:   a.Group,
+		Count:   a.Count,
+		ID:      a.ID,
+		Mailbox: Actor.MailboxSpec{
+			Size:        a.Mailbox.Size,
+			UrgentSize:  a.Mailbox.UrgentSize,
+			Policy:      a.Mailbox.Policy,
+			Processing:  a.Mailbox.Processing,
+			OrderingKey: a.Mailbox.Ordering,
+		},
+		Params: a.Params,
+	}
+}
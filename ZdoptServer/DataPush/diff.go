@@ -0,0 +1,200 @@
+package DataPush
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+)
+
+var ErrBadPatch = errors.New("invalid data patch")
+
+// MaxPatchedSize Patch 接受的目标长度上限，防止伪造的补丁头申请过大的内存
+const MaxPatchedSize = 1 << 30
+
+// 补丁格式：magic | uvarint 源长度 | uvarint 目标长度 | uint32 目标 CRC32 | 操作序列
+// 操作：opCopy uvarint 源偏移 uvarint 长度；opInsert uvarint 长度 原始字节
+const (
+	patchMagic = "ZDP1"
+	blockSize  = 16 // 匹配块大小，小于该长度的相同片段按插入处理
+	maxProbe   = 8  // 每个哈希最多比较的候选位置
+
+	opCopy   byte = 0
+	opInsert byte = 1
+
+	rollBase uint32 = 16777619
+)
+
+// Diff 计算从 old 到 new 的二进制补丁（滚动哈希匹配 old 中的块，其余内容按插入编码）
+func Diff(old, new []byte) []byte {
+	out := make([]byte, 0, 64)
+	out = append(out, patchMagic...)
+	out = binary.AppendUvarint(out, uint64(len(old)))
+	out = binary.AppendUvarint(out, uint64(len(new)))
+	out = binary.BigEndian.AppendUint32(out, crc32.ChecksumIEEE(new))
+
+	index := indexBlocks(old)
+	pending := 0 // 尚未编码的插入内容起点
+	flushInsert := func(end int) {
+		if end > pending {
+			out = append(out, opInsert)
+			out = binary.AppendUvarint(out, uint64(end-pending))
+			out = append(out, new[pending:end]...)
+		}
+	}
+
+	if len(new) >= blockSize && len(index) > 0 {
+		pow := rollPow()
+		h := rollHash(new[:blockSize])
+		for i := 0; i+blockSize <= len(new); {
+			srcOff, n := longestMatch(index[h], old, new, i)
+			if n > 0 {
+				// 向前扩展到尚未编码的插入内容中
+				for srcOff > 0 && i > pending && old[srcOff-1] == new[i-1] {
+					srcOff--
+					i--
+					n++
+				}
+				flushInsert(i)
+				out = append(out, opCopy)
+				out = binary.AppendUvarint(out, uint64(srcOff))
+				out = binary.AppendUvarint(out, uint64(n))
+				i += n
+				pending = i
+				if i+blockSize <= len(new) {
+					h = rollHash(new[i : i+blockSize])
+				}
+				continue
+			}
+			if i+blockSize < len(new) {
+				h = (h-uint32(new[i])*pow)*rollBase + uint32(new[i+blockSize])
+			}
+			i++
+		}
+	}
+	flushInsert(len(new))
+	return out
+}
+
+// Patch 将补丁应用到 old，校验源长度与结果 CRC32
+func Patch(old, patch []byte) ([]byte, error) {
+	if !bytes.HasPrefix(patch, []byte(patchMagic)) {
+		return nil, fmt.Errorf("%w: bad magic", ErrBadPatch)
+	}
+	r := patch[len(patchMagic):]
+	srcLen, r, err := readUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+	dstLen, r, err := readUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+	if srcLen != uint64(len(old)) {
+		return nil, fmt.Errorf("%w: source length %d, have %d", ErrBadPatch, srcLen, len(old))
+	}
+	if len(r) < 4 {
+		return nil, fmt.Errorf("%w: truncated header", ErrBadPatch)
+	}
+	if dstLen > MaxPatchedSize {
+		return nil, fmt.Errorf("%w: target length %d exceeds %d", ErrBadPatch, dstLen, MaxPatchedSize)
+	}
+	sum := binary.BigEndian.Uint32(r)
+	r = r[4:]
+
+	// 复制操作可以多次引用 old，目标长度不受补丁与源长度之和约束；预分配按两者较小值，输出随操作检查不超过目标长度
+	out := make([]byte, 0, min(dstLen, uint64(len(old))+uint64(len(patch))))
+	for len(r) > 0 {
+		op := r[0]
+		r = r[1:]
+		switch op {
+		case opCopy:
+			var off, n uint64
+			if off, r, err = readUvarint(r); err != nil {
+				return nil, err
+			}
+			if n, r, err = readUvarint(r); err != nil {
+				return nil, err
+			}
+			if off > uint64(len(old)) || n > uint64(len(old))-off {
+				return nil, fmt.Errorf("%w: copy out of range", ErrBadPatch)
+			}
+			if n > dstLen-uint64(len(out)) {
+				return nil, fmt.Errorf("%w: output exceeds target length", ErrBadPatch)
+			}
+			out = append(out, old[off:off+n]...)
+		case opInsert:
+			var n uint64
+			if n, r, err = readUvarint(r); err != nil {
+				return nil, err
+			}
+			if n > uint64(len(r)) {
+				return nil, fmt.Errorf("%w: truncated insert", ErrBadPatch)
+			}
+			if n > dstLen-uint64(len(out)) {
+				return nil, fmt.Errorf("%w: output exceeds target length", ErrBadPatch)
+			}
+			out = append(out, r[:n]...)
+			r = r[n:]
+		default:
+			return nil, fmt.Errorf("%w: unknown op %d", ErrBadPatch, op)
+		}
+	}
+	if uint64(len(out)) != dstLen || crc32.ChecksumIEEE(out) != sum {
+		return nil, fmt.Errorf("%w: checksum mismatch", ErrBadPatch)
+	}
+	return out, nil
+}
+
+// indexBlocks 对 old 中按块对齐的位置建立滚动哈希索引
+func indexBlocks(old []byte) map[uint32][]int {
+	index := make(map[uint32][]int, len(old)/blockSize)
+	for off := 0; off+blockSize <= len(old); off += blockSize {
+		h := rollHash(old[off : off+blockSize])
+		if len(index[h]) < maxProbe {
+			index[h] = append(index[h], off)
+		}
+	}
+	return index
+}
+
+// longestMatch 在候选位置中找与 new[i:] 最长的相同前缀（至少一个完整块）
+func longestMatch(candidates []int, old, new []byte, i int) (int, int) {
+	bestOff, bestLen := 0, 0
+	for _, off := range candidates {
+		n := 0
+		for off+n < len(old) && i+n < len(new) && old[off+n] == new[i+n] {
+			n++
+		}
+		if n >= blockSize && n > bestLen {
+			bestOff, bestLen = off, n
+		}
+	}
+	return bestOff, bestLen
+}
+
+func rollHash(b []byte) uint32 {
+	var h uint32
+	for _, c := range b {
+		h = h*rollBase + uint32(c)
+	}
+	return h
+}
+
+// rollPow rollBase^(blockSize-1)，滚动时移出首字节用
+func rollPow() uint32 {
+	p := uint32(1)
+	for i := 1; i < blockSize; i++ {
+		p *= rollBase
+	}
+	return p
+}
+
+func readUvarint(b []byte) (uint64, []byte, error) {
+	v, n := binary.Uvarint(b)
+	if n <= 0 {
+		return 0, nil, fmt.Errorf("%w: bad varint", ErrBadPatch)
+	}
+	return v, b[n:], nil
+}
@@ -0,0 +1,63 @@
+package DataPush
+
+import (
+	"bytes"
+	"errors"
+	"math/rand"
+	"testing"
+)
+
+func TestDiffPatchRoundTrip(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	random := func(n int) []byte {
+		b := make([]byte, n)
+		rng.Read(b)
+		return b
+	}
+	old := random(4096)
+	edited := append([]byte(nil), old...)
+	copy(edited[1000:], random(50))
+	edited = append(edited[:3000], append(random(200), edited[3000:]...)...)
+
+	cases := map[string]struct{ old, new []byte }{
+		"empty":       {nil, nil},
+		"from empty":  {nil, random(100)},
+		"to empty":    {old, nil},
+		"same":        {old, old},
+		"edited":      {old, edited},
+		"repeated":    {old[:64], bytes.Repeat(old[:64], 3)},
+		"repeat long": {old, bytes.Repeat(old, 5)},
+		"unrelated":   {old, random(4096)},
+		"short":       {[]byte("abc"), []byte("abcd")},
+	}
+	for name, c := range cases {
+		patch := Diff(c.old, c.new)
+		got, err := Patch(c.old, patch)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !bytes.Equal(got, c.new) {
+			t.Fatalf("%s: round trip mismatch", name)
+		}
+	}
+	// 新内容复用旧内容时补丁远小于结果
+	if patch := Diff(old, bytes.Repeat(old, 5)); len(patch) > 100 {
+		t.Fatalf("repeat patch is %d bytes", len(patch))
+	}
+}
+
+func TestPatchRejectsCorruptPatch(t *testing.T) {
+	old := bytes.Repeat([]byte("0123456789abcdef"), 8)
+	patch := Diff(old, append(bytes.Repeat(old, 2), "tail"...))
+	if _, err := Patch(old[1:], patch); !errors.Is(err, ErrBadPatch) {
+		t.Fatalf("wrong source = %v", err)
+	}
+	corrupt := append([]byte(nil), patch...)
+	corrupt[len(corrupt)-1] ^= 0xff
+	if _, err := Patch(old, corrupt); !errors.Is(err, ErrBadPatch) {
+		t.Fatalf("corrupt payload = %v", err)
+	}
+	if _, err := Patch(old, patch[:len(patch)-2]); !errors.Is(err, ErrBadPatch) {
+		t.Fatalf("truncated = %v", err)
+	}
+}
@@ -0,0 +1,153 @@
+package DataPush
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"zdopt/ZdoptServer/Actor"
+	"zdopt/ZdoptServer/Lifecycle"
+)
+
+// ModuleName 可选模块名，配置 modules 段出现该名称时启用
+const ModuleName = "datapush"
+
+func init() {
+	Lifecycle.RegisterModule(ModuleName, func() Lifecycle.Module { return &module{} })
+}
+
+// moduleConfig 模块配置段，如 {"dir":"data/tables","keep":8}；dir 下每个文件是一张表，文件名为表名
+type moduleConfig struct {
+	Dir       string `json:"dir"`
+	Keep      int    `json:"keep,omitempty"`
+	ChunkSize int    `json:"chunk_size,omitempty"`
+	Window    int    `json:"window,omitempty"`
+	Group     int    `json:"group,omitempty"` // 推送服务Actor所在的组，默认 91
+}
+
+// module 从目录加载数据表并以 ActorName 运行推送服务的可选模块
+type module struct {
+	cfg     moduleConfig
+	sys     *Actor.System
+	store   *Store
+	service *Service
+}
+
+func (m *module) Name() string { return ModuleName }
+
+func (m *module) Init(raw json.RawMessage, deps *Lifecycle.Deps) error {
+	cfg, err := parseModuleConfig(raw)
+	if err != nil {
+		return err
+	}
+	m.cfg, m.sys = cfg, deps.System
+	m.store = NewStore(cfg.Keep)
+	m.service = NewService(deps.System, NewPusher(m.store, Config{ChunkSize: cfg.ChunkSize, Window: cfg.Window}))
+	return nil
+}
+
+// SelfTest 启动自检：校验配置段并检查表目录可读
+func (m *module) SelfTest(raw json.RawMessage) error {
+	cfg, err := parseModuleConfig(raw)
+	if err != nil {
+		return err
+	}
+	_, err = os.ReadDir(cfg.Dir)
+	return err
+}
+
+func parseModuleConfig(raw json.RawMessage) (moduleConfig, error) {
+	cfg := moduleConfig{Group: 91}
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &cfg); err != nil {
+			return cfg, fmt.Errorf("datapush config: %w", err)
+		}
+	}
+	if cfg.Dir == "" {
+		return cfg, fmt.Errorf("datapush config: dir not configured")
+	}
+	return cfg, nil
+}
+
+// Start 发布目录中的表，启动推送服务Actor并登记名称
+func (m *module) Start(ctx context.Context) error {
+	if _, err := m.reload(); err != nil {
+		return err
+	}
+	m.sys.AddGroupActors(m.cfg.Group, []func() Actor.Actor{
+		func() Actor.Actor { return m.service },
+	})
+	return m.sys.RegisterName(ActorName, m.service)
+}
+
+func (m *module) Stop(ctx context.Context) error {
+	m.sys.UnregisterName(ActorName, m.service)
+	m.sys.RemoveGroupActor(m.cfg.Group, m.service.ID())
+	return nil
+}
+
+// reload 重新读取表目录，内容变化的表发布新版本并推送给在线客户端，返回发布后的各表版本
+func (m *module) reload() (map[string]uint64, error) {
+	entries, err := os.ReadDir(m.cfg.Dir)
+	if err != nil {
+		return nil, err
+	}
+	versions := make(map[string]uint64, len(entries))
+	for _, e := range entries {
+		if !e.Type().IsRegular() {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(m.cfg.Dir, e.Name()))
+		if err != nil {
+			return versions, err
+		}
+		versions[e.Name()] = m.store.Publish(e.Name(), data)
+	}
+	return versions, nil
+}
+
+type tableInfo struct {
+	Name     string `json:"name"`
+	Version  uint64 `json:"version"`
+	Size     int    `json:"size"`
+	Checksum uint32 `json:"checksum"`
+}
+
+// AdminRoutes GET 列出各表当前版本，POST reload 重新加载表目录
+func (m *module) AdminRoutes() map[string]http.Handler {
+	return map[string]http.Handler{
+		"": http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var tables []tableInfo
+			for _, name := range m.store.Names() {
+				if t, ok := m.store.Current(name); ok {
+					tables = append(tables, tableInfo{Name: name, Version: t.Version, Size: len(t.Data), Checksum: t.Checksum})
+				}
+			}
+			writeJSON(w, http.StatusOK, tables)
+		}),
+		"reload": http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodPost {
+				w.Header().Set("Allow", "POST")
+				http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+				return
+			}
+			versions, err := m.reload()
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			writeJSON(w, http.StatusOK, versions)
+		}),
+	}
+}
+
+// Metrics 指标已发布在 expvar 的 datapush.* 下
+func (m *module) Metrics() interface{} { return nil }
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,57 @@
+package DataPush
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"zdopt/ZdoptServer/Actor"
+	"zdopt/ZdoptServer/Lifecycle"
+)
+
+func TestModulePublishesTableDirectory(t *testing.T) {
+	sys := Actor.NewSystem()
+	defer sys.Stop()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "items"), []byte("v1"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	raw, _ := json.Marshal(moduleConfig{Dir: dir})
+	srv := Lifecycle.NewServer(Lifecycle.NewManager(nil), Lifecycle.Deps{System: sys})
+	if err := srv.Load(map[string]json.RawMessage{ModuleName: raw}); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if err := srv.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := sys.LookupName(ActorName); !ok {
+		t.Fatal("push service not registered by name")
+	}
+	mod, _ := srv.Module(ModuleName)
+	m := mod.(*module)
+	if cur, ok := m.store.Current("items"); !ok || cur.Version != 1 {
+		t.Fatalf("items after start = %+v", cur)
+	}
+
+	// 管理接口重新加载目录，内容变化的表发布新版本
+	if err := os.WriteFile(filepath.Join(dir, "items"), []byte("v2"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	rec := httptest.NewRecorder()
+	m.AdminRoutes()["reload"].ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", nil))
+	var versions map[string]uint64
+	if err := json.NewDecoder(rec.Body).Decode(&versions); err != nil || versions["items"] != 2 {
+		t.Fatalf("reload = %d %v %v", rec.Code, versions, err)
+	}
+
+	if err := srv.Stop(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := sys.LookupName(ActorName); ok {
+		t.Fatal("push service still registered after stop")
+	}
+}
@@ -0,0 +1,256 @@
+package DataPush
+
+import (
+	"errors"
+	"expvar"
+	"fmt"
+	"sync"
+
+	"google.golang.org/protobuf/proto"
+	"zdopt/ZdoptServer/Pb"
+)
+
+var (
+	ErrClientNotFound = errors.New("data push client not attached")
+
+	transfers  = expvar.NewInt("datapush.transfers")
+	bytesSent  = expvar.NewInt("datapush.bytes_sent")
+	bytesSaved = expvar.NewInt("datapush.bytes_saved") // 补丁相对完整内容节省的字节
+	resumes    = expvar.NewInt("datapush.resumes")
+	fallbacks  = expvar.NewInt("datapush.fallbacks") // 客户端无法应用补丁后改发完整内容
+)
+
+// SendFunc 经可靠消息通道发送给客户端
+type SendFunc func(msg proto.Message) error
+
+// Config 推送参数
+type Config struct {
+	ChunkSize int // 单个分片的最大字节数
+	Window    int // 未确认分片的最大数量
+}
+
+// DefaultConfig 默认推送参数
+func DefaultConfig() Config {
+	return Config{ChunkSize: 16 << 10, Window: 4}
+}
+
+// Pusher 数据表热推送：客户端握手上报版本后推送差量，按确认推进窗口，断线重连后从已确认位置续传
+type Pusher struct {
+	store   *Store
+	cfg     Config
+	mu      sync.Mutex
+	clients map[string]*pushClient
+}
+
+type pushClient struct {
+	send      SendFunc
+	versions  map[string]uint64
+	transfers map[string]*transfer
+}
+
+// transfer 一张表的进行中传输
+type transfer struct {
+	table    string
+	from, to uint64
+	full     bool
+	payload  []byte
+	checksum uint32
+	acked    uint64
+	sent     uint64
+}
+
+// NewPusher 创建推送器，store 发布新版本时自动推送给空闲的客户端
+func NewPusher(store *Store, cfg Config) *Pusher {
+	def := DefaultConfig()
+	if cfg.ChunkSize <= 0 {
+		cfg.ChunkSize = def.ChunkSize
+	}
+	if cfg.Window <= 0 {
+		cfg.Window = def.Window
+	}
+	p := &Pusher{store: store, cfg: cfg, clients: make(map[string]*pushClient)}
+	store.OnPublish(p.published)
+	return p
+}
+
+// Attach 客户端握手：记录其版本并开始推送过期的表；Partial 中与当前计划一致的传输从已确认位置续传
+func (p *Pusher) Attach(id string, hello *Pb.DataPushHello, send SendFunc) error {
+	c := &pushClient{
+		send:      send,
+		versions:  make(map[string]uint64),
+		transfers: make(map[string]*transfer),
+	}
+	for name, v := range hello.GetVersions() {
+		c.versions[name] = v
+	}
+	partial := make(map[string]*Pb.DataPushAck, len(hello.GetPartial()))
+	for _, ack := range hello.GetPartial() {
+		partial[ack.GetTable()] = ack
+	}
+
+	p.mu.Lock()
+	p.clients[id] = c
+	var out []*Pb.DataPushChunk
+	for _, name := range p.store.Names() {
+		t := p.planLocked(c, name)
+		if t == nil {
+			continue
+		}
+		if ack, ok := partial[name]; ok && ack.GetFromVersion() == t.from && ack.GetToVersion() == t.to &&
+			ack.GetOffset() <= uint64(len(t.payload)) {
+			t.acked, t.sent = ack.GetOffset(), ack.GetOffset()
+			resumes.Add(1)
+		}
+		out = append(out, p.pumpLocked(t)...)
+	}
+	p.mu.Unlock()
+	return sendAll(send, out)
+}
+
+// Detach 客户端断开，进行中的传输在下次 Attach 时按客户端上报的进度续传
+func (p *Pusher) Detach(id string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.clients, id)
+}
+
+// Ack 处理客户端确认：推进发送窗口，传输完成后继续推送更新的版本；
+// Failed 确认（补丁无法应用或校验失败）放弃该传输，改发当前版本的完整内容
+func (p *Pusher) Ack(id string, ack *Pb.DataPushAck) error {
+	p.mu.Lock()
+	c, ok := p.clients[id]
+	if !ok {
+		p.mu.Unlock()
+		return fmt.Errorf("%w: %s", ErrClientNotFound, id)
+	}
+	t, ok := c.transfers[ack.GetTable()]
+	if !ok || t.to != ack.GetToVersion() || t.from != ack.GetFromVersion() {
+		p.mu.Unlock()
+		return nil
+	}
+
+	var out []*Pb.DataPushChunk
+	if ack.GetFailed() {
+		delete(c.transfers, t.table)
+		if next := p.planFullLocked(c, t.table); next != nil {
+			fallbacks.Add(1)
+			out = p.pumpLocked(next)
+		}
+		send := c.send
+		p.mu.Unlock()
+		return sendAll(send, out)
+	}
+	offset := min(ack.GetOffset(), uint64(len(t.payload)))
+	switch {
+	case offset > t.acked:
+		t.acked = offset
+		t.sent = max(t.sent, t.acked)
+	case offset == t.acked && t.sent > t.acked:
+		// 确认未前进说明客户端收到了不连续的分片：从确认处重发
+		t.sent = t.acked
+	}
+
+	if t.acked == uint64(len(t.payload)) {
+		delete(c.transfers, t.table)
+		c.versions[t.table] = t.to
+		if next := p.planLocked(c, t.table); next != nil {
+			out = p.pumpLocked(next)
+		}
+	} else {
+		out = p.pumpLocked(t)
+	}
+	send := c.send
+	p.mu.Unlock()
+	return sendAll(send, out)
+}
+
+// published 新版本发布：推送给该表没有进行中传输的客户端
+func (p *Pusher) published(name string, version uint64) {
+	type pending struct {
+		send   SendFunc
+		chunks []*Pb.DataPushChunk
+	}
+	var batch []pending
+
+	p.mu.Lock()
+	for _, c := range p.clients {
+		if _, busy := c.transfers[name]; busy {
+			continue
+		}
+		if t := p.planLocked(c, name); t != nil {
+			batch = append(batch, pending{send: c.send, chunks: p.pumpLocked(t)})
+		}
+	}
+	p.mu.Unlock()
+
+	for _, b := range batch {
+		_ = sendAll(b.send, b.chunks)
+	}
+}
+
+// planLocked 为客户端创建表的传输，已是最新版本时返回 nil
+func (p *Pusher) planLocked(c *pushClient, name string) *transfer {
+	from := c.versions[name]
+	payload, full, to, ok := p.store.Plan(name, from)
+	if !ok {
+		return nil
+	}
+	if full {
+		from = 0
+	} else {
+		bytesSaved.Add(int64(len(to.Data) - len(payload)))
+	}
+	t := &transfer{table: name, from: from, to: to.Version, full: full, payload: payload, checksum: to.Checksum}
+	c.transfers[name] = t
+	transfers.Add(1)
+	return t
+}
+
+// planFullLocked 为客户端创建表当前版本的完整传输，表不存在时返回 nil
+func (p *Pusher) planFullLocked(c *pushClient, name string) *transfer {
+	to, ok := p.store.Current(name)
+	if !ok {
+		return nil
+	}
+	t := &transfer{table: name, to: to.Version, full: true, payload: to.Data, checksum: to.Checksum}
+	c.transfers[name] = t
+	transfers.Add(1)
+	return t
+}
+
+// pumpLocked 在窗口允许范围内生成待发送分片
+func (p *Pusher) pumpLocked(t *transfer) []*Pb.DataPushChunk {
+	total := uint64(len(t.payload))
+	limit := t.acked + uint64(p.cfg.ChunkSize*p.cfg.Window)
+	if total > 0 && t.sent >= total || t.sent >= limit {
+		return nil
+	}
+	var out []*Pb.DataPushChunk
+	for {
+		end := min(t.sent+uint64(p.cfg.ChunkSize), total)
+		out = append(out, &Pb.DataPushChunk{
+			Table:       t.table,
+			FromVersion: t.from,
+			ToVersion:   t.to,
+			Full:        t.full,
+			Offset:      t.sent,
+			Total:       total,
+			Data:        t.payload[t.sent:end],
+			Checksum:    t.checksum,
+		})
+		bytesSent.Add(int64(end - t.sent))
+		t.sent = end
+		if t.sent >= total || t.sent >= limit {
+			return out
+		}
+	}
+}
+
+func sendAll(send SendFunc, chunks []*Pb.DataPushChunk) error {
+	for _, chunk := range chunks {
+		if err := send(chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
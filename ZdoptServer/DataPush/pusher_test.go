@@ -0,0 +1,146 @@
+package DataPush
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"zdopt/ZdoptServer/Pb"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// link 把 Pusher 发出的分片交给 Receiver，可选择丢弃分片或在传输中途断开
+type link struct {
+	t        *testing.T
+	pusher   *Pusher
+	receiver *Receiver
+	id       string
+	queue    []*Pb.DataPushChunk
+	drop     func(c *Pb.DataPushChunk) bool
+}
+
+func (l *link) send(m proto.Message) error {
+	l.queue = append(l.queue, m.(*Pb.DataPushChunk))
+	return nil
+}
+
+// run 投递排队的分片并回送确认，直到没有分片或 limit 个分片后停止（模拟断线）；返回处理错误
+func (l *link) run(limit int) []error {
+	var errs []error
+	for n := 0; len(l.queue) > 0 && (limit <= 0 || n < limit); n++ {
+		c := l.queue[0]
+		l.queue = l.queue[1:]
+		if l.drop != nil && l.drop(c) {
+			continue
+		}
+		ack, err := l.receiver.Handle(c)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		if ack != nil {
+			if err := l.pusher.Ack(l.id, ack); err != nil {
+				l.t.Fatal(err)
+			}
+		}
+	}
+	return errs
+}
+
+func table(n int, seed byte) []byte {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = byte(i*7) + seed
+	}
+	return b
+}
+
+func TestPushResumesFromAckedOffset(t *testing.T) {
+	store := NewStore(4)
+	v1 := table(10000, 0)
+	store.Publish("items", v1)
+	p := NewPusher(store, Config{ChunkSize: 1000, Window: 2})
+	r := NewReceiver(nil)
+	l := &link{t: t, pusher: p, receiver: r, id: "c1"}
+
+	if err := p.Attach("c1", r.Hello(), l.send); err != nil {
+		t.Fatal(err)
+	}
+	// 收到 3 个分片后断线，未投递的分片丢失
+	l.run(3)
+	l.queue = nil
+	p.Detach("c1")
+	hello := r.Hello()
+	if len(hello.Partial) != 1 || hello.Partial[0].Offset != 3000 {
+		t.Fatalf("partial = %+v", hello.Partial)
+	}
+
+	var sent uint64
+	l.drop = func(c *Pb.DataPushChunk) bool {
+		sent += uint64(len(c.Data))
+		return false
+	}
+	before := resumes.Value()
+	if err := p.Attach("c1", hello, l.send); err != nil {
+		t.Fatal(err)
+	}
+	if errs := l.run(0); len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	if resumes.Value() != before+1 || sent != 7000 {
+		t.Fatalf("resumed %d times, sent %d bytes after reconnect", resumes.Value()-before, sent)
+	}
+	if got, ok := r.Table("items"); !ok || got.Version != 1 || !bytes.Equal(got.Data, v1) {
+		t.Fatalf("table after resume = %+v", got)
+	}
+
+	// 新版本以补丁推送，丢失的分片经不前进的确认重发
+	v2 := append(append([]byte(nil), v1...), table(500, 3)...)
+	dropped := false
+	l.drop = func(c *Pb.DataPushChunk) bool {
+		if !dropped && c.Offset > 0 {
+			dropped = true
+			return true
+		}
+		return false
+	}
+	store.Publish("items", v2)
+	if errs := l.run(0); len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	if got, _ := r.Table("items"); got.Version != 2 || !bytes.Equal(got.Data, v2) {
+		t.Fatalf("table after patch = v%d", got.Version)
+	}
+}
+
+func TestPushFallsBackToFullOnFailedPatch(t *testing.T) {
+	store := NewStore(4)
+	v1 := table(4000, 0)
+	store.Publish("items", v1)
+	p := NewPusher(store, Config{ChunkSize: 512, Window: 4})
+	r := NewReceiver(nil)
+	// 客户端本地的 v1 已损坏：补丁应用结果校验失败
+	broken := append([]byte(nil), v1...)
+	broken[10] ^= 0xff
+	r.Load("items", 1, broken)
+	l := &link{t: t, pusher: p, receiver: r, id: "c1"}
+	if err := p.Attach("c1", r.Hello(), l.send); err != nil {
+		t.Fatal(err)
+	}
+
+	v2 := append(append([]byte(nil), v1...), table(300, 9)...)
+	before := fallbacks.Value()
+	store.Publish("items", v2)
+	if len(l.queue) == 0 || l.queue[0].Full {
+		t.Fatalf("expected a patch transfer, queued %d chunks", len(l.queue))
+	}
+	errs := l.run(0)
+	if len(errs) != 1 || !errors.Is(errs[0], ErrBadPatch) {
+		t.Fatalf("errors = %v", errs)
+	}
+	if fallbacks.Value() != before+1 {
+		t.Fatal("failed patch did not fall back to the full payload")
+	}
+	if got, _ := r.Table("items"); got.Version != 2 || !bytes.Equal(got.Data, v2) {
+		t.Fatalf("table after fallback = v%d", got.Version)
+	}
+}
@@ -0,0 +1,123 @@
+package DataPush
+
+import (
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"sync"
+
+	"zdopt/ZdoptServer/Pb"
+)
+
+var ErrChecksumMismatch = errors.New("data push checksum mismatch")
+
+// Receiver 客户端侧接收器：拼接分片、应用补丁并校验，生成确认与重连握手（用于 Go 客户端与机器人）
+type Receiver struct {
+	mu       sync.Mutex
+	tables   map[string]*Table
+	incoming map[string]*incoming
+	onUpdate func(t *Table)
+}
+
+type incoming struct {
+	from, to uint64
+	full     bool
+	buf      []byte
+}
+
+// NewReceiver 创建接收器，onUpdate 在表更新完成后调用，可为空
+func NewReceiver(onUpdate func(t *Table)) *Receiver {
+	return &Receiver{
+		tables:   make(map[string]*Table),
+		incoming: make(map[string]*incoming),
+		onUpdate: onUpdate,
+	}
+}
+
+// Load 载入本地缓存的表版本
+func (r *Receiver) Load(name string, version uint64, data []byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.tables[name] = &Table{Name: name, Version: version, Data: data, Checksum: crc32.ChecksumIEEE(data)}
+}
+
+// Table 本地的表版本
+func (r *Receiver) Table(name string) (*Table, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t, ok := r.tables[name]
+	return t, ok
+}
+
+// Hello 连接/重连时的握手：本地版本与未完成传输的进度
+func (r *Receiver) Hello() *Pb.DataPushHello {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	hello := &Pb.DataPushHello{Versions: make(map[string]uint64, len(r.tables))}
+	for name, t := range r.tables {
+		hello.Versions[name] = t.Version
+	}
+	for name, in := range r.incoming {
+		hello.Partial = append(hello.Partial, &Pb.DataPushAck{
+			Table: name, FromVersion: in.from, ToVersion: in.to, Offset: uint64(len(in.buf)),
+		})
+	}
+	return hello
+}
+
+// Handle 处理分片并返回确认；分片不连续时确认当前位置以请求重发。
+// 补丁无法应用或结果校验失败时丢弃本次传输，返回错误及 Failed 确认，服务端据此改发完整内容
+func (r *Receiver) Handle(chunk *Pb.DataPushChunk) (*Pb.DataPushAck, error) {
+	r.mu.Lock()
+	name := chunk.GetTable()
+	in, ok := r.incoming[name]
+	if !ok || in.to != chunk.GetToVersion() || in.from != chunk.GetFromVersion() {
+		in = &incoming{from: chunk.GetFromVersion(), to: chunk.GetToVersion(), full: chunk.GetFull()}
+		r.incoming[name] = in
+	}
+	if chunk.GetOffset() == uint64(len(in.buf)) {
+		in.buf = append(in.buf, chunk.GetData()...)
+	}
+	ack := &Pb.DataPushAck{Table: name, FromVersion: in.from, ToVersion: in.to, Offset: uint64(len(in.buf))}
+	if uint64(len(in.buf)) < chunk.GetTotal() {
+		r.mu.Unlock()
+		return ack, nil
+	}
+
+	delete(r.incoming, name)
+	data, err := r.assembleLocked(name, in, chunk.GetChecksum())
+	if err != nil {
+		r.mu.Unlock()
+		ack.Failed = true
+		return ack, err
+	}
+	t := &Table{Name: name, Version: in.to, Data: data, Checksum: chunk.GetChecksum()}
+	r.tables[name] = t
+	onUpdate := r.onUpdate
+	r.mu.Unlock()
+
+	if onUpdate != nil {
+		onUpdate(t)
+	}
+	return ack, nil
+}
+
+// assembleLocked 由收齐的传输内容得到新版本并校验
+func (r *Receiver) assembleLocked(name string, in *incoming, checksum uint32) ([]byte, error) {
+	data := in.buf
+	if !in.full {
+		base, ok := r.tables[name]
+		if !ok || base.Version != in.from {
+			return nil, fmt.Errorf("%w: no base version %d for %s", ErrBadPatch, in.from, name)
+		}
+		patched, err := Patch(base.Data, in.buf)
+		if err != nil {
+			return nil, err
+		}
+		data = patched
+	}
+	if crc32.ChecksumIEEE(data) != checksum {
+		return nil, fmt.Errorf("%w: %s v%d", ErrChecksumMismatch, name, in.to)
+	}
+	return data, nil
+}
@@ -0,0 +1,67 @@
+package DataPush
+
+import (
+	"context"
+	"strconv"
+	"time"
+	"zdopt/ZdoptServer/Actor"
+	"zdopt/ZdoptServer/Logs"
+	"zdopt/ZdoptServer/Pb"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// ActorName 推送服务Actor的登记名称，MessageRouter 把 DataPushHello 与 DataPushAck 路由到该名称
+const ActorName = "datapush"
+
+var logger = Logs.CreateConsoleLogConfig("DataPush")
+
+// Service 推送服务Actor：收到会话的 DataPushHello 时 Attach，DataPushAck 时推进传输，会话关闭时 Detach
+type Service struct {
+	*Actor.BaseActor
+	sys    *Actor.System
+	pusher *Pusher
+}
+
+// NewService 创建推送服务Actor，之后由调用方启动（如 AddGroupActors）并登记名称
+func NewService(sys *Actor.System, pusher *Pusher) *Service {
+	s := &Service{BaseActor: sys.NewBaseActor(1024), sys: sys, pusher: pusher}
+	Actor.RegisterHandler(s, s.onMessage)
+	Actor.RegisterHandler(s, s.onSessionClosed)
+	return s
+}
+
+// PreStart 订阅会话关闭事件
+func (s *Service) PreStart(ctx context.Context) error {
+	return s.sys.EventBus().Subscribe(s.BaseActor, Actor.SessionClosedTopic)
+}
+
+func (s *Service) Start()                     {}
+func (s *Service) Stop()                      {}
+func (s *Service) Update(delta time.Duration) {}
+func (s *Service) Receive(msg interface{})    {}
+
+func (s *Service) onMessage(msg *Actor.Message) {
+	defer msg.Release()
+	from := msg.From
+	if from == nil {
+		return
+	}
+	id := strconv.FormatUint(from.ID(), 10)
+	var err error
+	switch v := msg.Value.(type) {
+	case *Pb.DataPushHello:
+		err = s.pusher.Attach(id, v, func(m proto.Message) error { return from.Send(m) })
+	case *Pb.DataPushAck:
+		err = s.pusher.Ack(id, v)
+	default:
+		return
+	}
+	if err != nil {
+		logger.Printf("session %s: %v", id, err)
+	}
+}
+
+func (s *Service) onSessionClosed(ev Actor.SessionClosed) {
+	s.pusher.Detach(strconv.FormatUint(ev.SessionID, 10))
+}
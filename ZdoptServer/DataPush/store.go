@@ -0,0 +1,170 @@
+package DataPush
+
+import (
+	"bytes"
+	"hash/crc32"
+	"sort"
+	"sync"
+)
+
+// Table 数据表的某个版本
+type Table struct {
+	Name     string
+	Version  uint64
+	Data     []byte
+	Checksum uint32
+}
+
+// Store 数据表版本库：保留每张表最近若干版本用于生成补丁
+type Store struct {
+	mu      sync.RWMutex
+	keep    int
+	tables  map[string][]*Table // 按版本升序
+	patches map[patchKey][]byte
+	subs    []func(name string, version uint64)
+}
+
+type patchKey struct {
+	name     string
+	from, to uint64
+}
+
+// NewStore 创建版本库，keep 为每张表保留的历史版本数（含当前版本）
+func NewStore(keep int) *Store {
+	if keep <= 0 {
+		keep = 8
+	}
+	return &Store{
+		keep:    keep,
+		tables:  make(map[string][]*Table),
+		patches: make(map[patchKey][]byte),
+	}
+}
+
+// OnPublish 订阅新版本发布
+func (s *Store) OnPublish(fn func(name string, version uint64)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.subs = append(s.subs, fn)
+}
+
+// Publish 发布新版本，返回版本号；内容与当前版本相同时不产生新版本
+func (s *Store) Publish(name string, data []byte) uint64 {
+	s.mu.Lock()
+	versions := s.tables[name]
+	if n := len(versions); n > 0 && bytes.Equal(versions[n-1].Data, data) {
+		s.mu.Unlock()
+		return versions[n-1].Version
+	}
+
+	var version uint64 = 1
+	if n := len(versions); n > 0 {
+		version = versions[n-1].Version + 1
+	}
+	t := &Table{
+		Name:     name,
+		Version:  version,
+		Data:     append([]byte(nil), data...),
+		Checksum: crc32.ChecksumIEEE(data),
+	}
+	versions = append(versions, t)
+	if len(versions) > s.keep {
+		for _, old := range versions[:len(versions)-s.keep] {
+			s.dropPatchesLocked(name, old.Version)
+		}
+		versions = append([]*Table(nil), versions[len(versions)-s.keep:]...)
+	}
+	s.tables[name] = versions
+	subs := append([]func(string, uint64){}, s.subs...)
+	s.mu.Unlock()
+
+	for _, fn := range subs {
+		fn(name, version)
+	}
+	return version
+}
+
+// Current 表的当前版本
+func (s *Store) Current(name string) (*Table, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	versions := s.tables[name]
+	if len(versions) == 0 {
+		return nil, false
+	}
+	return versions[len(versions)-1], true
+}
+
+// Version 表的指定版本（仅限保留范围内）
+func (s *Store) Version(name string, version uint64) (*Table, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.versionLocked(name, version)
+}
+
+func (s *Store) versionLocked(name string, version uint64) (*Table, bool) {
+	for _, t := range s.tables[name] {
+		if t.Version == version {
+			return t, true
+		}
+	}
+	return nil, false
+}
+
+// Names 所有表名（排序）
+func (s *Store) Names() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	names := make([]string, 0, len(s.tables))
+	for name := range s.tables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Plan 计算客户端从 from 版本更新到当前版本的传输内容：
+// 基础版本仍保留且补丁更小时返回补丁，否则返回完整内容；已是最新版本时 ok 为 false
+func (s *Store) Plan(name string, from uint64) (payload []byte, full bool, to *Table, ok bool) {
+	s.mu.RLock()
+	versions := s.tables[name]
+	if len(versions) == 0 {
+		s.mu.RUnlock()
+		return nil, false, nil, false
+	}
+	to = versions[len(versions)-1]
+	if from == to.Version {
+		s.mu.RUnlock()
+		return nil, false, to, false
+	}
+	key := patchKey{name: name, from: from, to: to.Version}
+	if patch, cached := s.patches[key]; cached {
+		s.mu.RUnlock()
+		return patch, false, to, true
+	}
+	base, hasBase := s.versionLocked(name, from)
+	s.mu.RUnlock()
+
+	if !hasBase {
+		return to.Data, true, to, true
+	}
+	patch := Diff(base.Data, to.Data)
+	if len(patch) >= len(to.Data) {
+		return to.Data, true, to, true
+	}
+
+	s.mu.Lock()
+	if _, still := s.versionLocked(name, from); still {
+		s.patches[key] = patch
+	}
+	s.mu.Unlock()
+	return patch, false, to, true
+}
+
+func (s *Store) dropPatchesLocked(name string, version uint64) {
+	for key := range s.patches {
+		if key.name == name && (key.from == version || key.to == version) {
+			delete(s.patches, key)
+		}
+	}
+}
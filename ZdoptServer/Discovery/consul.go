@@ -0,0 +1,91 @@
+package Discovery
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// ConsulResolver 通过 Consul HTTP API 查询健康实例
+type ConsulResolver struct {
+	Address    string // 如 http://127.0.0.1:8500
+	Datacenter string
+	Token      string
+	Client     *http.Client
+}
+
+type consulServiceEntry struct {
+	Node struct {
+		Address    string
+		Datacenter string
+	}
+	Service struct {
+		Address string
+		Port    int
+		Meta    map[string]string
+		Weights struct {
+			Passing int
+		}
+	}
+}
+
+func (r *ConsulResolver) Resolve(ctx context.Context, service string) ([]Endpoint, error) {
+	client := r.Client
+	if client == nil {
+		client = &http.Client{Timeout: 5 * time.Second}
+	}
+
+	query := url.Values{"passing": {"true"}}
+	if r.Datacenter != "" {
+		query.Set("dc", r.Datacenter)
+	}
+	reqURL := fmt.Sprintf("%s/v1/health/service/%s?%s", r.Address, url.PathEscape(service), query.Encode())
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if r.Token != "" {
+		req.Header.Set("X-Consul-Token", r.Token)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("consul query %s: %w", service, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("consul query %s: unexpected status %s", service, resp.Status)
+	}
+
+	var entries []consulServiceEntry
+	if err := json.NewDecoder(resp.Body).Decode(&entries); err != nil {
+		return nil, fmt.Errorf("consul decode %s: %w", service, err)
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrNoEndpoints, service)
+	}
+
+	endpoints := make([]Endpoint, 0, len(entries))
+	for _, e := range entries {
+		host := e.Service.Address
+		if host == "" {
+			host = e.Node.Address
+		}
+		// 地域取服务元数据 region，未设置时以数据中心代替
+		region := e.Service.Meta["region"]
+		if region == "" {
+			region = e.Node.Datacenter
+		}
+		endpoints = append(endpoints, Endpoint{
+			Host:   host,
+			Port:   e.Service.Port,
+			Weight: e.Service.Weights.Passing,
+			Region: region,
+		})
+	}
+	return endpoints, nil
+}
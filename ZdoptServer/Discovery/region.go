@@ -0,0 +1,201 @@
+package Discovery
+
+import (
+	"errors"
+	"expvar"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+	"zdopt/ZdoptServer/Pb"
+)
+
+var (
+	ErrNoPlacement = errors.New("no endpoint available for placement")
+
+	placementsLocal  = expvar.NewInt("discovery.placement.local")
+	placementsCross  = expvar.NewMap("discovery.placement.cross")    // "客户端地域->节点地域" -> 次数
+	crossRegionBytes = expvar.NewMap("discovery.region.cross_bytes") // "源地域->目标地域" -> 字节数
+)
+
+// ClientRegion 会话的地域标签：握手时自报的地域及到各地域的实测往返延迟
+type ClientRegion struct {
+	Region string
+	RTT    map[string]time.Duration
+}
+
+// ClientRegionFromHello 从握手消息提取地域标签
+func ClientRegionFromHello(hello *Pb.ClientHello) ClientRegion {
+	c := ClientRegion{Region: hello.GetRegion()}
+	if len(hello.GetRegionRTT()) > 0 {
+		c.RTT = make(map[string]time.Duration, len(hello.GetRegionRTT()))
+		for region, ms := range hello.GetRegionRTT() {
+			c.RTT[region] = time.Duration(ms) * time.Millisecond
+		}
+	}
+	return c
+}
+
+// RegionConfig 就近分配参数
+type RegionConfig struct {
+	Fallbacks map[string][]string // 地域 -> 本地域无节点且没有延迟数据时依次尝试的地域
+	MaxRTT    time.Duration       // 跨地域时已知延迟超过该值的地域不选，0 表示不限制
+	Smoothing float64             // 地域间延迟 EWMA 系数，默认 0.2
+}
+
+// DefaultRegionConfig 默认参数：不限制跨地域延迟
+func DefaultRegionConfig() RegionConfig {
+	return RegionConfig{Smoothing: 0.2}
+}
+
+// Placement 分配结果
+type Placement struct {
+	Endpoint    Endpoint
+	CrossRegion bool // 节点不在客户端地域（含地域未知）
+}
+
+// Placer 按地域就近选择节点：优先客户端所在地域，其次按延迟（客户端实测 > 历史汇总 > 静态备选顺序）跨地域回退。
+// 房间创建时经 Room.WithPlacement 调用 Place 选择承载节点
+type Placer struct {
+	cfg  RegionConfig
+	mu   sync.RWMutex
+	rtt  map[regionPair]time.Duration // 由客户端上报汇总的地域间延迟
+	next atomic.Uint64                // 同地域多个节点时轮转
+}
+
+type regionPair struct{ from, to string }
+
+// NewPlacer 创建就近分配器，未设置的参数使用默认值
+func NewPlacer(cfg RegionConfig) *Placer {
+	if cfg.Smoothing <= 0 || cfg.Smoothing > 1 {
+		cfg.Smoothing = DefaultRegionConfig().Smoothing
+	}
+	return &Placer{cfg: cfg, rtt: make(map[regionPair]time.Duration)}
+}
+
+// ObserveRTT 记录从地域 from 到地域 to 的一次延迟样本
+func (p *Placer) ObserveRTT(from, to string, rtt time.Duration) {
+	if from == "" || to == "" || rtt <= 0 {
+		return
+	}
+	key := regionPair{from, to}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if old, ok := p.rtt[key]; ok {
+		rtt = old + time.Duration(p.cfg.Smoothing*float64(rtt-old))
+	}
+	p.rtt[key] = rtt
+}
+
+// RTT 地域间汇总延迟
+func (p *Placer) RTT(from, to string) (time.Duration, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	rtt, ok := p.rtt[regionPair{from, to}]
+	return rtt, ok
+}
+
+// Place 为客户端选择节点，客户端上报的延迟同时计入地域间汇总
+func (p *Placer) Place(client ClientRegion, endpoints []Endpoint) (Placement, error) {
+	for region, rtt := range client.RTT {
+		p.ObserveRTT(client.Region, region, rtt)
+	}
+
+	byRegion := make(map[string][]Endpoint)
+	for _, e := range endpoints {
+		byRegion[e.Region] = append(byRegion[e.Region], e)
+	}
+	regions := make([]string, 0, len(byRegion))
+	for region := range byRegion {
+		regions = append(regions, region)
+	}
+
+	order := p.Order(client, regions)
+	if len(order) == 0 {
+		return Placement{}, fmt.Errorf("%w: client region %q", ErrNoPlacement, client.Region)
+	}
+	region := order[0]
+	candidates := byRegion[region]
+	sortEndpoints(candidates)
+	// 只在最高优先级（Priority 最小）的节点间轮转
+	n := 1
+	for n < len(candidates) && candidates[n].Priority == candidates[0].Priority {
+		n++
+	}
+	chosen := candidates[(p.next.Add(1)-1)%uint64(n)]
+
+	cross := client.Region == "" || region != client.Region
+	if cross {
+		placementsCross.Add(client.Region+"->"+region, 1)
+	} else {
+		placementsLocal.Add(1)
+	}
+	return Placement{Endpoint: chosen, CrossRegion: cross}, nil
+}
+
+// Order 候选地域的优先顺序：客户端地域，其次有延迟数据的地域按延迟升序，再按静态备选顺序，
+// 其余按名称，地域未知的节点最后；已知延迟超过 MaxRTT 的跨地域候选被排除
+func (p *Placer) Order(client ClientRegion, available []string) []string {
+	type ranked struct {
+		region string
+		class  int
+		value  int64
+	}
+	fallback := make(map[string]int)
+	for i, region := range p.cfg.Fallbacks[client.Region] {
+		if _, ok := fallback[region]; !ok {
+			fallback[region] = i
+		}
+	}
+
+	list := make([]ranked, 0, len(available))
+	for _, region := range available {
+		r := ranked{region: region}
+		switch {
+		case region == client.Region && region != "":
+			r.class = 0
+		case region == "":
+			r.class = 4
+		default:
+			rtt, ok := client.RTT[region]
+			if !ok {
+				rtt, ok = p.RTT(client.Region, region)
+			}
+			if idx, isFallback := fallback[region]; ok {
+				if p.cfg.MaxRTT > 0 && rtt > p.cfg.MaxRTT {
+					continue
+				}
+				r.class, r.value = 1, int64(rtt)
+			} else if isFallback {
+				r.class, r.value = 2, int64(idx)
+			} else {
+				r.class = 3
+			}
+		}
+		list = append(list, r)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].class != list[j].class {
+			return list[i].class < list[j].class
+		}
+		if list[i].value != list[j].value {
+			return list[i].value < list[j].value
+		}
+		return list[i].region < list[j].region
+	})
+
+	out := make([]string, len(list))
+	for i, r := range list {
+		out[i] = r.region
+	}
+	return out
+}
+
+// RecordCrossRegionTraffic 记录跨地域转发的流量（同地域不计）
+func RecordCrossRegionTraffic(from, to string, bytes int) {
+	if from == to {
+		return
+	}
+	crossRegionBytes.Add(from+"->"+to, int64(bytes))
+}
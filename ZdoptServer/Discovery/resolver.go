@@ -0,0 +1,169 @@
+package Discovery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+var ErrNoEndpoints = errors.New("no endpoints resolved")
+
+// Endpoint 对端节点地址
+type Endpoint struct {
+	Host     string
+	Port     int
+	Priority int // 越小越优先（SRV 语义）
+	Weight   int
+	Region   string // 所在地域，空表示未知
+}
+
+// Addr host:port 形式地址
+func (e Endpoint) Addr() string {
+	return net.JoinHostPort(e.Host, strconv.Itoa(e.Port))
+}
+
+// Resolver 服务发现接口：把服务名解析为当前可用的端点列表
+type Resolver interface {
+	Resolve(ctx context.Context, service string) ([]Endpoint, error)
+}
+
+// StaticResolver 静态配置的端点表
+type StaticResolver struct {
+	mu        sync.RWMutex
+	endpoints map[string][]Endpoint
+}
+
+// NewStaticResolver 创建静态解析器
+func NewStaticResolver() *StaticResolver {
+	return &StaticResolver{endpoints: make(map[string][]Endpoint)}
+}
+
+// Set 设置服务的端点（可在运行时修改，Watcher 下次刷新生效）
+func (r *StaticResolver) Set(service string, addrs ...string) error {
+	endpoints := make([]Endpoint, 0, len(addrs))
+	for _, addr := range addrs {
+		host, portStr, err := net.SplitHostPort(addr)
+		if err != nil {
+			return fmt.Errorf("invalid address %q: %w", addr, err)
+		}
+		port, err := strconv.Atoi(portStr)
+		if err != nil {
+			return fmt.Errorf("invalid port in %q: %w", addr, err)
+		}
+		endpoints = append(endpoints, Endpoint{Host: host, Port: port})
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.endpoints[service] = endpoints
+	return nil
+}
+
+// SetRegion 设置服务在某个地域的端点，替换该地域原有端点，其他地域不变
+func (r *StaticResolver) SetRegion(service, region string, addrs ...string) error {
+	endpoints := make([]Endpoint, 0, len(addrs))
+	for _, addr := range addrs {
+		host, portStr, err := net.SplitHostPort(addr)
+		if err != nil {
+			return fmt.Errorf("invalid address %q: %w", addr, err)
+		}
+		port, err := strconv.Atoi(portStr)
+		if err != nil {
+			return fmt.Errorf("invalid port in %q: %w", addr, err)
+		}
+		endpoints = append(endpoints, Endpoint{Host: host, Port: port, Region: region})
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, e := range r.endpoints[service] {
+		if e.Region != region {
+			endpoints = append(endpoints, e)
+		}
+	}
+	r.endpoints[service] = endpoints
+	return nil
+}
+
+func (r *StaticResolver) Resolve(_ context.Context, service string) ([]Endpoint, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	endpoints := r.endpoints[service]
+	if len(endpoints) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrNoEndpoints, service)
+	}
+	return append([]Endpoint(nil), endpoints...), nil
+}
+
+// DNSResolver 基于 DNS SRV 记录解析（_service._proto.domain）
+// 没有 SRV 记录时退化为 A/AAAA 查询 + 默认端口
+type DNSResolver struct {
+	Proto       string // "udp" / "tcp"，默认 udp
+	Domain      string
+	DefaultPort int
+	Resolver    *net.Resolver
+}
+
+func (r *DNSResolver) Resolve(ctx context.Context, service string) ([]Endpoint, error) {
+	resolver := r.Resolver
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+	proto := r.Proto
+	if proto == "" {
+		proto = "udp"
+	}
+
+	_, srvs, err := resolver.LookupSRV(ctx, service, proto, r.Domain)
+	if err == nil && len(srvs) > 0 {
+		endpoints := make([]Endpoint, 0, len(srvs))
+		for _, srv := range srvs {
+			endpoints = append(endpoints, Endpoint{
+				Host:     trimDot(srv.Target),
+				Port:     int(srv.Port),
+				Priority: int(srv.Priority),
+				Weight:   int(srv.Weight),
+			})
+		}
+		return endpoints, nil
+	}
+
+	if r.DefaultPort == 0 {
+		return nil, fmt.Errorf("srv lookup %s: %w", service, err)
+	}
+	host := service
+	if r.Domain != "" {
+		host = service + "." + r.Domain
+	}
+	addrs, hostErr := resolver.LookupHost(ctx, host)
+	if hostErr != nil {
+		return nil, fmt.Errorf("lookup %s: %w", host, errors.Join(err, hostErr))
+	}
+	endpoints := make([]Endpoint, 0, len(addrs))
+	for _, addr := range addrs {
+		endpoints = append(endpoints, Endpoint{Host: addr, Port: r.DefaultPort})
+	}
+	return endpoints, nil
+}
+
+func trimDot(host string) string {
+	if len(host) > 0 && host[len(host)-1] == '.' {
+		return host[:len(host)-1]
+	}
+	return host
+}
+
+// sortEndpoints 稳定排序，便于比较两次解析结果
+func sortEndpoints(endpoints []Endpoint) {
+	sort.Slice(endpoints, func(i, j int) bool {
+		if endpoints[i].Priority != endpoints[j].Priority {
+			return endpoints[i].Priority < endpoints[j].Priority
+		}
+		return endpoints[i].Addr() < endpoints[j].Addr()
+	})
+}
@@ -0,0 +1,191 @@
+package Discovery
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Event 端点变化或解析失败事件
+// 解析失败时保留上一次成功的端点，不会清空连接
+type Event struct {
+	Service   string
+	Endpoints []Endpoint // 变化后的完整端点列表
+	Added     []Endpoint
+	Removed   []Endpoint
+	Err       error
+}
+
+// Watcher 周期性刷新服务端点，变化时通知远程层重建连接
+type Watcher struct {
+	resolver Resolver
+	interval time.Duration
+	handler  func(Event)
+
+	mu        sync.RWMutex
+	services  map[string][]Endpoint
+	watches   map[string]int // 各服务的 Watch 次数，Unwatch 减到 0 时停止解析
+	listeners map[int]func(Event)
+	nextID    int
+	refreshCh chan struct{}
+}
+
+// NewWatcher 创建端点监视器，handler 在监视协程中串行调用
+func NewWatcher(resolver Resolver, interval time.Duration, handler func(Event)) *Watcher {
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+	return &Watcher{
+		resolver:  resolver,
+		interval:  interval,
+		handler:   handler,
+		services:  make(map[string][]Endpoint),
+		watches:   make(map[string]int),
+		listeners: make(map[int]func(Event)),
+		refreshCh: make(chan struct{}, 1),
+	}
+}
+
+// Watch 添加需要监视的服务，下一次刷新时解析；每次 Watch 对应一次 Unwatch
+func (w *Watcher) Watch(service string) {
+	w.mu.Lock()
+	if _, ok := w.services[service]; !ok {
+		w.services[service] = nil
+	}
+	w.watches[service]++
+	w.mu.Unlock()
+	w.Refresh()
+}
+
+// Unwatch 撤销一次 Watch，全部撤销后不再解析该服务并丢弃其端点
+func (w *Watcher) Unwatch(service string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.watches[service]--; w.watches[service] > 0 {
+		return
+	}
+	delete(w.watches, service)
+	delete(w.services, service)
+}
+
+// Notify 追加事件监听（如 Rpc 的 gRPC 解析器），与 handler 一样在监视协程中串行调用；返回取消函数
+func (w *Watcher) Notify(fn func(Event)) (cancel func()) {
+	w.mu.Lock()
+	id := w.nextID
+	w.nextID++
+	w.listeners[id] = fn
+	w.mu.Unlock()
+	return func() {
+		w.mu.Lock()
+		delete(w.listeners, id)
+		w.mu.Unlock()
+	}
+}
+
+// Endpoints 服务当前已知端点
+func (w *Watcher) Endpoints(service string) []Endpoint {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return append([]Endpoint(nil), w.services[service]...)
+}
+
+// Refresh 请求立即刷新（如连接断开后）
+func (w *Watcher) Refresh() {
+	select {
+	case w.refreshCh <- struct{}{}:
+	default:
+	}
+}
+
+// Start 启动监视循环，ctx 取消后退出
+func (w *Watcher) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+
+		w.refreshAll(ctx)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			case <-w.refreshCh:
+			}
+			w.refreshAll(ctx)
+		}
+	}()
+}
+
+func (w *Watcher) refreshAll(ctx context.Context) {
+	w.mu.RLock()
+	services := make([]string, 0, len(w.services))
+	for service := range w.services {
+		services = append(services, service)
+	}
+	w.mu.RUnlock()
+
+	for _, service := range services {
+		w.refresh(ctx, service)
+	}
+}
+
+func (w *Watcher) refresh(ctx context.Context, service string) {
+	resolveCtx, cancel := context.WithTimeout(ctx, w.interval)
+	endpoints, err := w.resolver.Resolve(resolveCtx, service)
+	cancel()
+
+	w.mu.Lock()
+	old, watched := w.services[service]
+	if watched && err == nil {
+		sortEndpoints(endpoints)
+		w.services[service] = endpoints
+	}
+	w.mu.Unlock()
+	if !watched {
+		return // 解析期间已 Unwatch
+	}
+	if err != nil {
+		w.emit(Event{Service: service, Endpoints: append([]Endpoint(nil), old...), Err: err})
+		return
+	}
+
+	added, removed := diffEndpoints(old, endpoints)
+	if len(added) > 0 || len(removed) > 0 {
+		w.emit(Event{Service: service, Endpoints: endpoints, Added: added, Removed: removed})
+	}
+}
+
+func (w *Watcher) emit(ev Event) {
+	if w.handler != nil {
+		w.handler(ev)
+	}
+	w.mu.RLock()
+	listeners := make([]func(Event), 0, len(w.listeners))
+	for _, fn := range w.listeners {
+		listeners = append(listeners, fn)
+	}
+	w.mu.RUnlock()
+	for _, fn := range listeners {
+		fn(ev)
+	}
+}
+
+func diffEndpoints(old, cur []Endpoint) (added, removed []Endpoint) {
+	oldSet := make(map[string]struct{}, len(old))
+	for _, e := range old {
+		oldSet[e.Addr()] = struct{}{}
+	}
+	curSet := make(map[string]struct{}, len(cur))
+	for _, e := range cur {
+		curSet[e.Addr()] = struct{}{}
+		if _, ok := oldSet[e.Addr()]; !ok {
+			added = append(added, e)
+		}
+	}
+	for _, e := range old {
+		if _, ok := curSet[e.Addr()]; !ok {
+			removed = append(removed, e)
+		}
+	}
+	return added, removed
+}
@@ -0,0 +1,244 @@
+package Export
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"fmt"
+	"sync"
+	"time"
+	"zdopt/ZdoptServer/Actor"
+	"zdopt/ZdoptServer/Logs"
+)
+
+var (
+	ErrExporterFull   = errors.New("exporter queue full")
+	ErrExporterClosed = errors.New("exporter closed")
+
+	exportedEvents = expvar.NewInt("export.events")
+	droppedEvents  = expvar.NewInt("export.dropped")
+	failedBatches  = expvar.NewInt("export.failed_batches")
+)
+
+// Event 待导出的服务器事件
+type Event struct {
+	Topic   string
+	Time    time.Time
+	Payload interface{} // proto.Message 或可 JSON 序列化的值
+}
+
+// Sink 导出目标（HTTP、Kafka 等），Send 失败时由 Exporter 重试
+type Sink interface {
+	Send(ctx context.Context, batch []Event) error
+}
+
+// Config 导出配置
+type Config struct {
+	Topics        []string      // 需要导出的主题（可含事件总线通配符），空表示全部
+	BatchSize     int           // 单批最大事件数
+	FlushInterval time.Duration // 未攒满一批时的最长等待
+	QueueSize     int           // 缓冲队列容量，满时 Export 返回 ErrExporterFull（背压）
+	MaxRetries    int           // 单批最大重试次数，0 使用默认值，负数表示不重试
+	RetryBackoff  time.Duration // 首次重试等待，之后指数退避
+}
+
+// DefaultConfig 默认导出配置
+func DefaultConfig() Config {
+	return Config{
+		BatchSize:     100,
+		FlushInterval: time.Second,
+		QueueSize:     10000,
+		MaxRetries:    5,
+		RetryBackoff:  200 * time.Millisecond,
+	}
+}
+
+// Exporter 订阅指定主题，批量、带重试地推送到外部分析系统
+type Exporter struct {
+	sink   Sink
+	cfg    Config
+	queue  chan Event
+	logger *Logs.ZLogger
+
+	mu     sync.RWMutex
+	closed bool
+	done   chan struct{}
+}
+
+// NewExporter 创建导出器，未设置的配置项使用默认值
+func NewExporter(sink Sink, cfg Config, logger *Logs.ZLogger) *Exporter {
+	def := DefaultConfig()
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = def.BatchSize
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = def.FlushInterval
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = def.QueueSize
+	}
+	if cfg.MaxRetries == 0 {
+		cfg.MaxRetries = def.MaxRetries
+	} else if cfg.MaxRetries < 0 {
+		cfg.MaxRetries = 0
+	}
+	if cfg.RetryBackoff <= 0 {
+		cfg.RetryBackoff = def.RetryBackoff
+	}
+
+	return &Exporter{
+		sink:   sink,
+		cfg:    cfg,
+		queue:  make(chan Event, cfg.QueueSize),
+		logger: logger,
+		done:   make(chan struct{}),
+	}
+}
+
+// Wants 主题是否在导出范围内
+func (e *Exporter) Wants(topic string) bool {
+	if len(e.cfg.Topics) == 0 {
+		return true
+	}
+	for _, pattern := range e.cfg.Topics {
+		if Actor.MatchTopic(pattern, topic) {
+			return true
+		}
+	}
+	return false
+}
+
+// Export 提交事件（非阻塞），队列满时返回 ErrExporterFull 由调用方决定丢弃或降级
+func (e *Exporter) Export(topic string, payload interface{}) error {
+	if !e.Wants(topic) {
+		return nil
+	}
+	return e.export(topic, payload)
+}
+
+// export 不经主题过滤入队（Attach 订阅时已按主题匹配，配置中可含通配符）
+func (e *Exporter) export(topic string, payload interface{}) error {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.closed {
+		return ErrExporterClosed
+	}
+
+	select {
+	case e.queue <- Event{Topic: topic, Time: time.Now(), Payload: payload}:
+		return nil
+	default:
+		droppedEvents.Add(1)
+		return ErrExporterFull
+	}
+}
+
+// Attach 在事件总线上订阅配置的主题（可含通配符，未配置时订阅全部），发布的事件经 Export 进入队列；
+// 队列满时事件被丢弃并计入 export.dropped，不阻塞发布方。返回取消订阅的函数
+func (e *Exporter) Attach(bus *Actor.EventBus) (detach func(), err error) {
+	topics := e.cfg.Topics
+	if len(topics) == 0 {
+		topics = []string{">"}
+	}
+	var cancels []func()
+	detach = func() {
+		for _, cancel := range cancels {
+			cancel()
+		}
+	}
+	for _, topic := range topics {
+		cancel, err := bus.Tap(topic, func(topic string, msg interface{}) {
+			_ = e.export(topic, msg)
+		})
+		if err != nil {
+			detach()
+			return nil, err
+		}
+		cancels = append(cancels, cancel)
+	}
+	return detach, nil
+}
+
+// Start 启动批量发送循环
+func (e *Exporter) Start(ctx context.Context) {
+	go e.run(ctx)
+}
+
+// Close 停止接收新事件，发送剩余事件，ctx 到期后放弃
+func (e *Exporter) Close(ctx context.Context) error {
+	e.mu.Lock()
+	if !e.closed {
+		e.closed = true
+		close(e.queue)
+	}
+	e.mu.Unlock()
+
+	select {
+	case <-e.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (e *Exporter) run(ctx context.Context) {
+	defer close(e.done)
+
+	ticker := time.NewTicker(e.cfg.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]Event, 0, e.cfg.BatchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		e.send(ctx, batch)
+		batch = make([]Event, 0, e.cfg.BatchSize)
+	}
+
+	for {
+		select {
+		case ev, ok := <-e.queue:
+			if !ok {
+				flush()
+				return
+			}
+			batch = append(batch, ev)
+			if len(batch) >= e.cfg.BatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// send 发送一批事件，失败时指数退避重试
+func (e *Exporter) send(ctx context.Context, batch []Event) {
+	backoff := e.cfg.RetryBackoff
+	var err error
+	for attempt := 0; attempt <= e.cfg.MaxRetries; attempt++ {
+		if err = e.sink.Send(ctx, batch); err == nil {
+			exportedEvents.Add(int64(len(batch)))
+			return
+		}
+		if attempt == e.cfg.MaxRetries {
+			break
+		}
+		select {
+		case <-time.After(backoff):
+			backoff *= 2
+		case <-ctx.Done():
+			err = ctx.Err()
+			attempt = e.cfg.MaxRetries
+		}
+	}
+
+	failedBatches.Add(1)
+	droppedEvents.Add(int64(len(batch)))
+	if e.logger != nil {
+		e.logger.Error(fmt.Sprintf("export batch of %d events failed: %v", len(batch), err))
+	}
+}
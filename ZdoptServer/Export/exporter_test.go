@@ -0,0 +1,200 @@
+package Export
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+	"zdopt/ZdoptServer/Actor"
+	"zdopt/ZdoptServer/Lifecycle"
+)
+
+// recordingSink 记录收到的批次，fail 返回非 nil 时该次发送失败
+type recordingSink struct {
+	mu       sync.Mutex
+	batches  [][]Event
+	attempts int
+	fail     func(attempt int) error
+}
+
+func (s *recordingSink) Send(ctx context.Context, batch []Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.attempts++
+	if s.fail != nil {
+		if err := s.fail(s.attempts); err != nil {
+			return err
+		}
+	}
+	s.batches = append(s.batches, append([]Event(nil), batch...))
+	return nil
+}
+
+func (s *recordingSink) snapshot() ([][]Event, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([][]Event(nil), s.batches...), s.attempts
+}
+
+func TestExporterBatchesBySizeAndOnClose(t *testing.T) {
+	sink := &recordingSink{}
+	e := NewExporter(sink, Config{BatchSize: 3, FlushInterval: time.Hour}, nil)
+	e.Start(context.Background())
+	for i := 0; i < 7; i++ {
+		if err := e.Export("match.ended", i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := e.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	batches, _ := sink.snapshot()
+	if len(batches) != 3 || len(batches[0]) != 3 || len(batches[1]) != 3 || len(batches[2]) != 1 {
+		t.Fatalf("batches = %v", batches)
+	}
+	if batches[2][0].Payload != 6 {
+		t.Fatalf("last batch = %+v", batches[2])
+	}
+	if err := e.Export("match.ended", 8); !errors.Is(err, ErrExporterClosed) {
+		t.Fatalf("export after close = %v", err)
+	}
+}
+
+func TestExporterFlushesOnInterval(t *testing.T) {
+	sink := &recordingSink{}
+	e := NewExporter(sink, Config{BatchSize: 100, FlushInterval: 10 * time.Millisecond}, nil)
+	e.Start(context.Background())
+	defer e.Close(context.Background())
+	_ = e.Export("match.ended", 1)
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(5 * time.Millisecond) {
+		if batches, _ := sink.snapshot(); len(batches) == 1 {
+			return
+		}
+	}
+	t.Fatal("partial batch not flushed on interval")
+}
+
+func TestExporterRetriesFailedBatches(t *testing.T) {
+	sink := &recordingSink{fail: func(attempt int) error {
+		if attempt <= 2 {
+			return errors.New("collector unavailable")
+		}
+		return nil
+	}}
+	e := NewExporter(sink, Config{BatchSize: 1, MaxRetries: 3, RetryBackoff: time.Millisecond}, nil)
+	e.Start(context.Background())
+	_ = e.Export("match.ended", 1)
+	if err := e.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if batches, attempts := sink.snapshot(); len(batches) != 1 || attempts != 3 {
+		t.Fatalf("delivered %d batches in %d attempts", len(batches), attempts)
+	}
+
+	// 重试用尽后丢弃该批，不阻塞后续批次
+	failed, dropped := failedBatches.Value(), droppedEvents.Value()
+	sink = &recordingSink{fail: func(attempt int) error {
+		if attempt <= 2 {
+			return errors.New("collector unavailable")
+		}
+		return nil
+	}}
+	e = NewExporter(sink, Config{BatchSize: 1, MaxRetries: 1, RetryBackoff: time.Millisecond}, nil)
+	e.Start(context.Background())
+	_ = e.Export("match.ended", 1)
+	_ = e.Export("match.ended", 2)
+	if err := e.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	batches, _ := sink.snapshot()
+	if failedBatches.Value() != failed+1 || droppedEvents.Value() != dropped+1 || len(batches) != 1 || batches[0][0].Payload != 2 {
+		t.Fatalf("failed %d dropped %d batches %v", failedBatches.Value()-failed, droppedEvents.Value()-dropped, batches)
+	}
+}
+
+func TestExporterAppliesBackpressure(t *testing.T) {
+	// 发送阻塞时队列填满，Export 立即返回 ErrExporterFull 而不阻塞调用方
+	release := make(chan struct{})
+	var once sync.Once
+	sink := &recordingSink{fail: func(int) error {
+		<-release
+		return nil
+	}}
+	e := NewExporter(sink, Config{BatchSize: 1, QueueSize: 2}, nil)
+	e.Start(context.Background())
+	defer func() {
+		once.Do(func() { close(release) })
+		_ = e.Close(context.Background())
+	}()
+
+	var full error
+	for i := 0; i < 10 && full == nil; i++ {
+		full = e.Export("match.ended", i)
+	}
+	if !errors.Is(full, ErrExporterFull) {
+		t.Fatalf("export with blocked sink = %v", full)
+	}
+}
+
+func TestExporterAttachesToEventBus(t *testing.T) {
+	sys := Actor.NewSystem()
+	defer sys.Stop()
+	sink := &recordingSink{}
+	e := NewExporter(sink, Config{Topics: []string{"match.>"}, BatchSize: 10, FlushInterval: time.Hour}, nil)
+	e.Start(context.Background())
+	detach, err := e.Attach(sys.EventBus())
+	if err != nil {
+		t.Fatal(err)
+	}
+	bus := sys.EventBus()
+	_, _ = bus.Publish("match.ended", "m1")
+	_, _ = bus.Publish("player.joined", "p1")
+	_, _ = bus.Publish("match.round.ended", "r1")
+	detach()
+	_, _ = bus.Publish("match.ended", "m2")
+	if err := e.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	batches, _ := sink.snapshot()
+	if len(batches) != 1 || len(batches[0]) != 2 || batches[0][0].Topic != "match.ended" || batches[0][1].Topic != "match.round.ended" {
+		t.Fatalf("exported = %+v", batches)
+	}
+}
+
+func TestModuleExportsBusTopics(t *testing.T) {
+	received := make(chan []jsonEvent, 1)
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var events []jsonEvent
+		_ = json.Unmarshal(body, &events)
+		received <- events
+	}))
+	defer collector.Close()
+
+	sys := Actor.NewSystem()
+	defer sys.Stop()
+	raw, _ := json.Marshal(moduleConfig{URL: collector.URL, Topics: []string{"match.ended"}, FlushInterval: "10ms"})
+	srv := Lifecycle.NewServer(Lifecycle.NewManager(nil), Lifecycle.Deps{System: sys})
+	if err := srv.Load(map[string]json.RawMessage{ModuleName: raw}); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if err := srv.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Stop(ctx)
+	_, _ = sys.EventBus().Publish("match.ended", map[string]string{"id": "m1"})
+	select {
+	case events := <-received:
+		if len(events) != 1 || events[0].Topic != "match.ended" || string(events[0].Payload) != `{"id":"m1"}` {
+			t.Fatalf("collector received %+v", events)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("event never reached the collector")
+	}
+}
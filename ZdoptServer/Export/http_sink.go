@@ -0,0 +1,127 @@
+package Export
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"zdopt/ZdoptServer/Pb"
+)
+
+// Format 批量负载编码格式
+type Format int
+
+const (
+	FormatJSON     Format = iota // JSON 数组，proto 负载使用 protojson
+	FormatProtobuf               // Pb.ExportBatch，负载必须是 proto.Message
+)
+
+// HTTPSink 以 POST 方式推送到 HTTP 端点
+type HTTPSink struct {
+	URL     string
+	Format  Format
+	Node    string // 节点标识，写入批次元数据
+	Headers map[string]string
+	Client  *http.Client
+}
+
+type jsonEvent struct {
+	Topic     string          `json:"topic"`
+	Timestamp int64           `json:"ts"`
+	Node      string          `json:"node,omitempty"`
+	Payload   json.RawMessage `json:"payload"`
+}
+
+func (s *HTTPSink) Send(ctx context.Context, batch []Event) error {
+	var (
+		body        []byte
+		contentType string
+		err         error
+	)
+	switch s.Format {
+	case FormatProtobuf:
+		body, err = s.encodeProtobuf(batch)
+		contentType = "application/x-protobuf"
+	default:
+		body, err = s.encodeJSON(batch)
+		contentType = "application/json"
+	}
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	for k, v := range s.Headers {
+		req.Header.Set(k, v)
+	}
+
+	client := s.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("export endpoint returned %s", resp.Status)
+	}
+	return nil
+}
+
+func (s *HTTPSink) encodeJSON(batch []Event) ([]byte, error) {
+	events := make([]jsonEvent, 0, len(batch))
+	for _, ev := range batch {
+		var payload []byte
+		var err error
+		if msg, ok := ev.Payload.(proto.Message); ok {
+			payload, err = protojson.Marshal(msg)
+		} else {
+			payload, err = json.Marshal(ev.Payload)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("encode %s event: %w", ev.Topic, err)
+		}
+		events = append(events, jsonEvent{
+			Topic:     ev.Topic,
+			Timestamp: ev.Time.UnixMilli(),
+			Node:      s.Node,
+			Payload:   payload,
+		})
+	}
+	return json.Marshal(events)
+}
+
+func (s *HTTPSink) encodeProtobuf(batch []Event) ([]byte, error) {
+	out := &Pb.ExportBatch{Node: s.Node, Events: make([]*Pb.ExportEvent, 0, len(batch))}
+	for _, ev := range batch {
+		msg, ok := ev.Payload.(proto.Message)
+		if !ok {
+			return nil, fmt.Errorf("event %s payload %T is not a protobuf message", ev.Topic, ev.Payload)
+		}
+		payload, err := proto.Marshal(msg)
+		if err != nil {
+			return nil, fmt.Errorf("encode %s event: %w", ev.Topic, err)
+		}
+		out.Events = append(out.Events, &Pb.ExportEvent{
+			Topic:     ev.Topic,
+			Timestamp: ev.Time.UnixMilli(),
+			Type:      string(msg.ProtoReflect().Descriptor().FullName()),
+			Payload:   payload,
+		})
+	}
+	return proto.Marshal(out)
+}
@@ -0,0 +1,128 @@
+package Export
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+	"zdopt/ZdoptServer/Actor"
+	"zdopt/ZdoptServer/Lifecycle"
+	"zdopt/ZdoptServer/Logs"
+)
+
+// ModuleName 可选模块名，配置 modules 段出现该名称时启用
+const ModuleName = "export"
+
+func init() {
+	Lifecycle.RegisterModule(ModuleName, func() Lifecycle.Module { return &module{} })
+}
+
+// moduleConfig 模块配置段，如 {"url":"http://collector/events","topics":["match.>"]}
+type moduleConfig struct {
+	URL           string            `json:"url"`
+	Format        string            `json:"format,omitempty"` // json（默认）/ protobuf
+	Node          string            `json:"node,omitempty"`
+	Headers       map[string]string `json:"headers,omitempty"`
+	Topics        []string          `json:"topics,omitempty"` // 事件总线主题，可含通配符，空表示全部
+	BatchSize     int               `json:"batch_size,omitempty"`
+	FlushInterval string            `json:"flush_interval,omitempty"` // 如 "1s"
+	QueueSize     int               `json:"queue_size,omitempty"`
+	MaxRetries    int               `json:"max_retries,omitempty"`
+	RetryBackoff  string            `json:"retry_backoff,omitempty"`
+}
+
+// module 把事件总线上配置的主题批量导出到 HTTP 端点的可选模块
+type module struct {
+	bus      *Actor.EventBus
+	exporter *Exporter
+	detach   func()
+	cancel   context.CancelFunc
+}
+
+func (m *module) Name() string { return ModuleName }
+
+func (m *module) Init(raw json.RawMessage, deps *Lifecycle.Deps) error {
+	sink, cfg, err := parseModuleConfig(raw)
+	if err != nil {
+		return err
+	}
+	logger, err := Logs.NewZLogger("", Logs.Info)
+	if err != nil {
+		return err
+	}
+	m.bus = deps.System.EventBus()
+	m.exporter = NewExporter(sink, cfg, logger)
+	return nil
+}
+
+// SelfTest 启动自检：校验配置段
+func (m *module) SelfTest(raw json.RawMessage) error {
+	_, _, err := parseModuleConfig(raw)
+	return err
+}
+
+func parseModuleConfig(raw json.RawMessage) (*HTTPSink, Config, error) {
+	var mc moduleConfig
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &mc); err != nil {
+			return nil, Config{}, fmt.Errorf("export config: %w", err)
+		}
+	}
+	if mc.URL == "" {
+		return nil, Config{}, fmt.Errorf("export config: url not configured")
+	}
+	sink := &HTTPSink{URL: mc.URL, Node: mc.Node, Headers: mc.Headers}
+	switch mc.Format {
+	case "", "json":
+	case "protobuf":
+		sink.Format = FormatProtobuf
+	default:
+		return nil, Config{}, fmt.Errorf("export config: unknown format %q", mc.Format)
+	}
+	cfg := Config{Topics: mc.Topics, BatchSize: mc.BatchSize, QueueSize: mc.QueueSize, MaxRetries: mc.MaxRetries}
+	for _, d := range []struct {
+		name  string
+		value string
+		into  *time.Duration
+	}{
+		{"flush_interval", mc.FlushInterval, &cfg.FlushInterval},
+		{"retry_backoff", mc.RetryBackoff, &cfg.RetryBackoff},
+	} {
+		if d.value == "" {
+			continue
+		}
+		v, err := time.ParseDuration(d.value)
+		if err != nil {
+			return nil, Config{}, fmt.Errorf("export config: %s: %w", d.name, err)
+		}
+		*d.into = v
+	}
+	return sink, cfg, nil
+}
+
+// Start 启动发送循环并订阅事件总线；发送循环独立于启动上下文，Stop 时结束
+func (m *module) Start(ctx context.Context) error {
+	runCtx, cancel := context.WithCancel(context.Background())
+	m.exporter.Start(runCtx)
+	detach, err := m.exporter.Attach(m.bus)
+	if err != nil {
+		cancel()
+		return err
+	}
+	m.detach, m.cancel = detach, cancel
+	return nil
+}
+
+// Stop 取消订阅，在 ctx 到期前发送剩余事件
+func (m *module) Stop(ctx context.Context) error {
+	m.detach()
+	err := m.exporter.Close(ctx)
+	m.cancel()
+	return err
+}
+
+func (m *module) AdminRoutes() map[string]http.Handler { return nil }
+
+// Metrics 指标已发布在 expvar 的 export.* 下
+func (m *module) Metrics() interface{} { return nil }
@@ -0,0 +1,189 @@
+package Gateway
+
+import (
+	"errors"
+	"expvar"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+	"zdopt/ZdoptServer/Pb"
+)
+
+var (
+	ErrNoRoute = errors.New("no backend route for message type")
+
+	decodedFrames    = expvar.NewInt("gateway.decoded")
+	forwardedFrames  = expvar.NewMap("gateway.forwarded") // 按后端统计
+	unroutableFrames = expvar.NewInt("gateway.unroutable")
+)
+
+// Route 路由规则：类型全名等于 Match，或以 Match+"." 开头（按包名/前缀匹配）时转发到 Backend
+// 本地未绑定类型的消息 ID 以 "#<ID>" 作为类型名（见 FrameType），可按此精确匹配；Match 为空表示默认路由；Version 非空时规则只对该协议版本的会话生效，且优先于无版本规则
+type Route struct {
+	Match   string
+	Backend string
+	Version string
+}
+
+// Forwarder 把未解码的帧转发给后端节点
+type Forwarder interface {
+	Forward(backend string, frame *Pb.Passthrough) error
+}
+
+// ForwarderFunc 函数形式的 Forwarder
+type ForwarderFunc func(backend string, frame *Pb.Passthrough) error
+
+func (f ForwarderFunc) Forward(backend string, frame *Pb.Passthrough) error {
+	return f(backend, frame)
+}
+
+// Passthrough 网关透传路由：只有本地注册了处理器的类型才解码，其余按规则原样转发
+type Passthrough struct {
+	mu        sync.RWMutex
+	routes    []Route // 按 Match 长度降序，最长前缀优先
+	handlers  map[string]func(proto.Message) error
+	versions  map[string]map[string]func(proto.Message) error // 类型 -> 版本 -> 处理器
+	forwarder Forwarder
+	policy    VersionPolicy
+	sessions  sync.Map // 会话 -> 已分配版本
+}
+
+// NewPassthrough 创建透传路由
+func NewPassthrough(forwarder Forwarder, routes ...Route) *Passthrough {
+	p := &Passthrough{
+		handlers:  make(map[string]func(proto.Message) error),
+		versions:  make(map[string]map[string]func(proto.Message) error),
+		forwarder: forwarder,
+	}
+	p.SetRoutes(routes)
+	return p
+}
+
+// SetRoutes 替换全部路由规则
+func (p *Passthrough) SetRoutes(routes []Route) {
+	sorted := append([]Route(nil), routes...)
+	sort.SliceStable(sorted, func(i, j int) bool { return len(sorted[i].Match) > len(sorted[j].Match) })
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.routes = sorted
+}
+
+// HandleLocal 注册本地处理器，对应类型的帧在网关解码处理而不转发
+func HandleLocal[T proto.Message](p *Passthrough, fn func(T) error) {
+	var zero T
+	name := Pb.TypeName(zero)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.handlers[name] = func(msg proto.Message) error {
+		return fn(msg.(T))
+	}
+}
+
+// Route 查找类型对应的后端（默认版本）
+func (p *Passthrough) Route(typeName string) (string, bool) {
+	backend, _, ok := p.route(typeName, DefaultVersion)
+	return backend, ok
+}
+
+// route 先匹配该版本的规则，再匹配无版本规则，返回后端与命中规则的版本
+func (p *Passthrough) route(typeName, version string) (string, string, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if version != DefaultVersion {
+		for _, r := range p.routes {
+			if r.Version == version && r.matches(typeName) {
+				return r.Backend, version, true
+			}
+		}
+	}
+	for _, r := range p.routes {
+		if r.Version == DefaultVersion && r.matches(typeName) {
+			return r.Backend, DefaultVersion, true
+		}
+	}
+	return "", DefaultVersion, false
+}
+
+func (r Route) matches(typeName string) bool {
+	return r.Match == "" || typeName == r.Match || strings.HasPrefix(typeName, r.Match+".")
+}
+
+// handler 查找本地处理器，版本处理器优先，返回实际使用的版本
+func (p *Passthrough) handler(typeName, version string) (func(proto.Message) error, string, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if version != DefaultVersion {
+		if h, ok := p.versions[typeName][version]; ok {
+			return h, version, true
+		}
+	}
+	h, ok := p.handlers[typeName]
+	return h, DefaultVersion, ok
+}
+
+// Dispatch 处理一条透传帧（默认版本）
+func (p *Passthrough) Dispatch(frame *Pb.Passthrough) error {
+	return p.dispatch(DefaultVersion, frame)
+}
+
+func (p *Passthrough) dispatch(version string, frame *Pb.Passthrough) (err error) {
+	start := time.Now()
+	handler, served, local := p.handler(frame.Type, version)
+
+	if local && Pb.IsRegistered(frame.Type) {
+		msg, decodeErr := Pb.DeserializeByName(frame.Type, frame.Payload)
+		if decodeErr != nil {
+			return decodeErr
+		}
+		decodedFrames.Add(1)
+		defer func() { observeVersion(served, frame.Type, start, err) }()
+		return handler(msg)
+	}
+
+	backend, served, ok := p.route(frame.Type, version)
+	if !ok {
+		unroutableFrames.Add(1)
+		return fmt.Errorf("%w: %s", ErrNoRoute, frame.Type)
+	}
+	defer func() { observeVersion(served, frame.Type, start, err) }()
+	if fwdErr := p.forwarder.Forward(backend, frame); fwdErr != nil {
+		return fmt.Errorf("forward %s to %s: %w", frame.Type, backend, fwdErr)
+	}
+	forwardedFrames.Add(backend, 1)
+	return nil
+}
+
+// DispatchBytes 解析透传帧外层后处理，负载本身不解码
+func (p *Passthrough) DispatchBytes(data []byte) error {
+	frame, err := Pb.Deserialize[*Pb.Passthrough](data)
+	if err != nil {
+		return err
+	}
+	return p.Dispatch(frame)
+}
+
+// FrameType 网络帧消息 ID 对应的路由类型名：ID 已绑定协议类型（Pb.BindID）时为类型全名，否则为 "#<ID>"
+func FrameType(id uint32) string {
+	if id <= math.MaxUint16 {
+		if name, ok := Pb.NameOf(uint16(id)); ok {
+			return name
+		}
+	}
+	return "#" + strconv.FormatUint(uint64(id), 10)
+}
+
+// Wrap 把消息打包为透传帧（客户端/后端侧使用）
+func Wrap(msg proto.Message) (*Pb.Passthrough, error) {
+	payload, err := proto.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+	return &Pb.Passthrough{Type: Pb.TypeName(msg), Payload: payload}, nil
+}
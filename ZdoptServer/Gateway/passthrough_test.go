@@ -0,0 +1,147 @@
+package Gateway
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"zdopt/ZdoptServer/Pb"
+)
+
+// forwarded 记录转发到各后端的帧类型
+type forwarded map[string][]string
+
+func (f forwarded) Forward(backend string, frame *Pb.Passthrough) error {
+	f[backend] = append(f[backend], frame.Type)
+	return nil
+}
+
+func TestPassthroughRoutesByLongestMatch(t *testing.T) {
+	fwd := forwarded{}
+	p := NewPassthrough(fwd,
+		Route{Match: "", Backend: "lobby"},
+		Route{Match: "battle", Backend: "battle"},
+		Route{Match: "battle.skill", Backend: "skill"},
+		Route{Match: "#900", Backend: "legacy"},
+	)
+	for typ, want := range map[string]string{
+		"battle.skill.Cast": "skill",
+		"battle.Move":       "battle",
+		"battleground.Join": "lobby", // 前缀须按段匹配
+		"#900":              "legacy",
+		"chat.Say":          "lobby",
+	} {
+		if got, ok := p.Route(typ); !ok || got != want {
+			t.Errorf("Route(%q) = %q, %v; want %q", typ, got, ok, want)
+		}
+	}
+
+	if err := p.Dispatch(&Pb.Passthrough{Type: "battle.Move"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := fwd["battle"]; len(got) != 1 || got[0] != "battle.Move" {
+		t.Fatalf("forwarded to battle: %v", got)
+	}
+
+	p.SetRoutes([]Route{{Match: "battle", Backend: "battle"}})
+	if err := p.Dispatch(&Pb.Passthrough{Type: "chat.Say"}); !errors.Is(err, ErrNoRoute) {
+		t.Fatalf("unroutable dispatch = %v, want ErrNoRoute", err)
+	}
+}
+
+func TestPassthroughDecodesOnlyLocalTypes(t *testing.T) {
+	fwd := forwarded{}
+	p := NewPassthrough(fwd, Route{Backend: "backend"})
+	var got []string
+	HandleLocal(p, func(m *Pb.DataPacket) error {
+		got = append(got, m.GetContent())
+		return nil
+	})
+
+	frame, err := Wrap(&Pb.DataPacket{Content: "hello"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Dispatch(frame); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0] != "hello" || len(fwd) != 0 {
+		t.Fatalf("local handler got %v, forwarded %v", got, fwd)
+	}
+
+	other, _ := Wrap(&Pb.ErrorResponse{Code: 1})
+	data, err := Pb.Serialize(other)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.DispatchBytes(data); err != nil {
+		t.Fatal(err)
+	}
+	if types := fwd["backend"]; len(types) != 1 || types[0] != Pb.TypeName(&Pb.ErrorResponse{}) {
+		t.Fatalf("forwarded %v", fwd)
+	}
+}
+
+func TestVersionedSessionsUseTheirRoutesAndHandlers(t *testing.T) {
+	fwd := forwarded{}
+	p := NewPassthrough(fwd,
+		Route{Match: "battle", Backend: "battle-v1"},
+		Route{Match: "battle", Backend: "battle-v2", Version: "v2"},
+	)
+	var served []string
+	HandleLocal(p, func(*Pb.DataPacket) error { served = append(served, "default"); return nil })
+	HandleLocalVersion(p, "v2", func(*Pb.DataPacket) error { served = append(served, "v2"); return nil })
+	p.SetVersionPolicy(Rollout{Version: "v2", Allowlist: []string{"canary"}}, false)
+
+	if v := p.SessionVersion("canary"); v != "v2" {
+		t.Fatalf("allowlisted session version = %q", v)
+	}
+	if v := p.SessionVersion("player"); v != DefaultVersion {
+		t.Fatalf("0%% rollout assigned %q", v)
+	}
+	packet, _ := Wrap(&Pb.DataPacket{})
+	for _, session := range []string{"canary", "player"} {
+		if err := p.DispatchSession(session, packet); err != nil {
+			t.Fatal(err)
+		}
+		if err := p.DispatchSession(session, &Pb.Passthrough{Type: "battle.Move"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if fmt.Sprint(served) != "[v2 default]" {
+		t.Fatalf("local handlers served %v", served)
+	}
+	if len(fwd["battle-v2"]) != 1 || len(fwd["battle-v1"]) != 1 {
+		t.Fatalf("forwarded %v", fwd)
+	}
+
+	// 已分配的会话保持原版本，reassign 时按新策略重新分配
+	p.SetVersionPolicy(Rollout{Version: "v2", Percent: 100}, false)
+	if v := p.SessionVersion("player"); v != DefaultVersion {
+		t.Fatalf("pinned session moved to %q", v)
+	}
+	p.SetVersionPolicy(Rollout{Version: "v2", Percent: 100}, true)
+	if v := p.SessionVersion("player"); v != "v2" {
+		t.Fatalf("reassigned session version = %q", v)
+	}
+}
+
+func TestRolloutIsStableAndMonotonic(t *testing.T) {
+	low, high := Rollout{Version: "v2", Percent: 10}, Rollout{Version: "v2", Percent: 50}
+	hits := 0
+	for i := 0; i < 2000; i++ {
+		session := fmt.Sprintf("s%d", i)
+		if low.Assign(session) != low.Assign(session) {
+			t.Fatalf("assignment of %s not stable", session)
+		}
+		if low.Assign(session) == "v2" {
+			hits++
+			if high.Assign(session) != "v2" {
+				t.Fatalf("%s fell back when the rollout widened", session)
+			}
+		}
+	}
+	if hits < 100 || hits > 300 {
+		t.Fatalf("10%% rollout hit %d of 2000 sessions", hits)
+	}
+}
@@ -0,0 +1,130 @@
+package Gateway
+
+import (
+	"expvar"
+	"hash/fnv"
+	"slices"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+	"zdopt/ZdoptServer/Metrics"
+	"zdopt/ZdoptServer/Pb"
+)
+
+// DefaultVersion 未参与灰度的会话使用的版本，指标中记为 "default"
+const DefaultVersion = ""
+
+var (
+	versionCalls  = expvar.NewMap("gateway.versions.calls")  // 按实际处理版本统计
+	versionErrors = expvar.NewMap("gateway.versions.errors") // 按实际处理版本统计
+
+	// VersionLatency 按处理版本（Transport 字段）与消息类型分组的处理延迟，以 gateway.versions.latency 发布
+	VersionLatency = Metrics.NewLatency()
+)
+
+func init() {
+	expvar.Publish("gateway.versions.latency", expvar.Func(func() interface{} {
+		return VersionLatency.Summaries(false)
+	}))
+}
+
+// VersionPolicy 为新会话分配协议版本
+type VersionPolicy interface {
+	Assign(session string) string
+}
+
+// VersionPolicyFunc 函数形式的 VersionPolicy
+type VersionPolicyFunc func(session string) string
+
+func (f VersionPolicyFunc) Assign(session string) string {
+	return f(session)
+}
+
+// Rollout 灰度策略：名单内的会话固定使用 Version，其余按会话哈希取 Percent% 使用 Version
+type Rollout struct {
+	Version   string
+	Percent   float64 // 0~100
+	Allowlist []string
+}
+
+// Assign 同一会话在同一 Version 下的结果稳定，调高 Percent 时已命中的会话不会回退
+func (r Rollout) Assign(session string) string {
+	if slices.Contains(r.Allowlist, session) {
+		return r.Version
+	}
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(r.Version + "/" + session))
+	if float64(h.Sum32()%10000) < r.Percent*100 {
+		return r.Version
+	}
+	return DefaultVersion
+}
+
+// HandleLocalVersion 注册指定版本的本地处理器；该版本的会话优先使用，未注册时回退到默认处理器
+func HandleLocalVersion[T proto.Message](p *Passthrough, version string, fn func(T) error) {
+	if version == DefaultVersion {
+		HandleLocal(p, fn)
+		return
+	}
+	var zero T
+	name := Pb.TypeName(zero)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.versions[name] == nil {
+		p.versions[name] = make(map[string]func(proto.Message) error)
+	}
+	p.versions[name][version] = func(msg proto.Message) error {
+		return fn(msg.(T))
+	}
+}
+
+// SetVersionPolicy 设置版本分配策略；已分配的会话保持原版本，reassign 为 true 时全部会话按新策略重新分配（如回滚）
+func (p *Passthrough) SetVersionPolicy(policy VersionPolicy, reassign bool) {
+	p.mu.Lock()
+	p.policy = policy
+	p.mu.Unlock()
+	if reassign {
+		p.sessions.Clear()
+	}
+}
+
+// SessionVersion 会话的协议版本，首次查询时按策略分配并固定；未设置策略时为默认版本
+func (p *Passthrough) SessionVersion(session string) string {
+	if session == "" {
+		return DefaultVersion
+	}
+	if v, ok := p.sessions.Load(session); ok {
+		return v.(string)
+	}
+	p.mu.RLock()
+	policy := p.policy
+	p.mu.RUnlock()
+	if policy == nil {
+		return DefaultVersion
+	}
+	v, _ := p.sessions.LoadOrStore(session, policy.Assign(session))
+	return v.(string)
+}
+
+// ReleaseSession 会话结束时释放版本分配
+func (p *Passthrough) ReleaseSession(session string) {
+	p.sessions.Delete(session)
+}
+
+// DispatchSession 按会话版本处理一条透传帧
+func (p *Passthrough) DispatchSession(session string, frame *Pb.Passthrough) error {
+	return p.dispatch(p.SessionVersion(session), frame)
+}
+
+// observeVersion 记录实际处理版本的调用、错误与延迟
+func observeVersion(version, msgType string, start time.Time, err error) {
+	label := version
+	if label == DefaultVersion {
+		label = "default"
+	}
+	versionCalls.Add(label, 1)
+	if err != nil {
+		versionErrors.Add(label, 1)
+	}
+	VersionLatency.Since(label, msgType, start)
+}
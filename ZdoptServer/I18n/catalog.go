@@ -0,0 +1,169 @@
+package I18n
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"zdopt/ZdoptServer/Pb"
+)
+
+// Code 面向客户端的稳定错误码，协议中只传递 Code，文本按会话语言本地化
+type Code uint32
+
+// 预定义错误码
+const (
+	CodeOK Code = iota
+	CodeInternal
+	CodeInvalidMessage
+	CodeUnauthorized
+	CodeRateLimited
+	CodeServerBusy
+	CodeNotFound
+	CodeTimeout
+	CodeIncompatibleProtocol // 客户端协议与服务端不兼容，需要更新
+)
+
+// DefaultLocale 缺省语言，协商失败时使用
+const DefaultLocale = "en"
+
+// Catalog 本地化文本目录（线程安全），按 locale -> code -> text 存储
+type Catalog struct {
+	mu       sync.RWMutex
+	fallback string
+	texts    map[string]map[Code]string
+}
+
+// NewCatalog 创建文本目录，fallback 为找不到对应语言时使用的语言
+func NewCatalog(fallback string) *Catalog {
+	if fallback == "" {
+		fallback = DefaultLocale
+	}
+	return &Catalog{
+		fallback: normalize(fallback),
+		texts:    make(map[string]map[Code]string),
+	}
+}
+
+// Register 注册单条文本，text 支持 fmt 格式化参数
+func (c *Catalog) Register(locale string, code Code, text string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	locale = normalize(locale)
+	m, ok := c.texts[locale]
+	if !ok {
+		m = make(map[Code]string)
+		c.texts[locale] = m
+	}
+	m[code] = text
+}
+
+// RegisterAll 批量注册某个语言的文本
+func (c *Catalog) RegisterAll(locale string, texts map[Code]string) {
+	for code, text := range texts {
+		c.Register(locale, code, text)
+	}
+}
+
+// Locales 返回已注册的语言列表（有序）
+func (c *Catalog) Locales() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	locales := make([]string, 0, len(c.texts))
+	for locale := range c.texts {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// Negotiate 按客户端握手时给出的语言偏好顺序协商会话语言
+// 先精确匹配（zh-cn），再匹配基础语言（zh），都失败时返回 fallback
+func (c *Catalog) Negotiate(preferred []string) string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for _, p := range preferred {
+		locale := normalize(p)
+		if _, ok := c.texts[locale]; ok {
+			return locale
+		}
+		if base := baseLanguage(locale); base != locale {
+			if _, ok := c.texts[base]; ok {
+				return base
+			}
+		}
+	}
+	return c.fallback
+}
+
+// Text 查找本地化文本，查找顺序：locale -> 基础语言 -> fallback
+func (c *Catalog) Text(locale string, code Code, args ...interface{}) string {
+	text, _ := c.lookup(locale, code, args...)
+	return text
+}
+
+// lookup 查找本地化文本并返回实际使用的语言，各语言都没有该错误码时返回通用文本与 fallback
+func (c *Catalog) lookup(locale string, code Code, args ...interface{}) (string, string) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	locale = normalize(locale)
+	for _, l := range []string{locale, baseLanguage(locale), c.fallback} {
+		if text, ok := c.texts[l][code]; ok {
+			if len(args) > 0 {
+				return fmt.Sprintf(text, args...), l
+			}
+			return text, l
+		}
+	}
+	return fmt.Sprintf("error %d", code), c.fallback
+}
+
+// NewErrorResponse 构造携带错误码与本地化文本的错误回包，Locale 为文本实际使用的语言（可能是基础语言或 fallback）
+func (c *Catalog) NewErrorResponse(locale string, code Code, args ...interface{}) *Pb.ErrorResponse {
+	text, used := c.lookup(locale, code, args...)
+	return &Pb.ErrorResponse{
+		Code:    uint32(code),
+		Message: text,
+		Locale:  used,
+	}
+}
+
+// Error 携带稳定错误码的错误，握手等拒绝路径据此选择下发给客户端的 Code
+type Error struct {
+	Code Code
+	Err  error
+}
+
+// WithCode 为 err 附加错误码
+func WithCode(code Code, err error) error {
+	return &Error{Code: code, Err: err}
+}
+
+func (e *Error) Error() string { return e.Err.Error() }
+func (e *Error) Unwrap() error { return e.Err }
+
+// CodeOf 错误链中最外层的错误码，没有时返回 fallback
+func CodeOf(err error, fallback Code) Code {
+	var coded *Error
+	if errors.As(err, &coded) {
+		return coded.Code
+	}
+	return fallback
+}
+
+// normalize 统一语言标签格式：小写、下划线转连字符
+func normalize(locale string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(locale)), "_", "-")
+}
+
+func baseLanguage(locale string) string {
+	if i := strings.IndexByte(locale, '-'); i > 0 {
+		return locale[:i]
+	}
+	return locale
+}
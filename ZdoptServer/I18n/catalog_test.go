@@ -0,0 +1,47 @@
+package I18n
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestNewErrorResponseReportsUsedLocale(t *testing.T) {
+	c := NewCatalog("en")
+	c.Register("en", CodeTimeout, "timed out")
+	c.Register("zh", CodeTimeout, "超时")
+	c.Register("zh", CodeNotFound, "未找到 %s")
+
+	for _, tc := range []struct {
+		locale string
+		code   Code
+		args   []interface{}
+		text   string
+		used   string
+	}{
+		{"zh", CodeTimeout, nil, "超时", "zh"},
+		{"zh-TW", CodeTimeout, nil, "超时", "zh"}, // 基础语言
+		{"fr", CodeTimeout, nil, "timed out", "en"},
+		{"zh", CodeNotFound, []interface{}{"room"}, "未找到 room", "zh"},
+		{"fr", CodeNotFound, nil, "error 6", "en"}, // 各语言都没有该错误码
+	} {
+		resp := c.NewErrorResponse(tc.locale, tc.code, tc.args...)
+		if resp.GetCode() != uint32(tc.code) || resp.GetMessage() != tc.text || resp.GetLocale() != tc.used {
+			t.Errorf("NewErrorResponse(%q, %d) = %+v, want %q in %q", tc.locale, tc.code, resp, tc.text, tc.used)
+		}
+	}
+}
+
+func TestCodeOf(t *testing.T) {
+	base := errors.New("sessions exhausted")
+	err := fmt.Errorf("admit: %w", WithCode(CodeServerBusy, base))
+	if got := CodeOf(err, CodeUnauthorized); got != CodeServerBusy {
+		t.Fatalf("CodeOf(coded) = %d", got)
+	}
+	if !errors.Is(err, base) || err.Error() != "admit: sessions exhausted" {
+		t.Fatalf("coded error lost its cause: %v", err)
+	}
+	if got := CodeOf(base, CodeUnauthorized); got != CodeUnauthorized {
+		t.Fatalf("CodeOf(plain) = %d", got)
+	}
+}
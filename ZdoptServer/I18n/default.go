@@ -0,0 +1,31 @@
+package I18n
+
+// Default 全局默认文本目录，内置中英文错误文本
+var Default = NewCatalog(DefaultLocale)
+
+func init() {
+	Default.RegisterAll("en", map[Code]string{
+		CodeOK:             "ok",
+		CodeInternal:       "internal server error",
+		CodeInvalidMessage: "invalid message",
+		CodeUnauthorized:   "unauthorized",
+		CodeRateLimited:    "too many requests, please try again later",
+		CodeServerBusy:     "server is busy",
+		CodeNotFound:       "not found",
+		CodeTimeout:        "request timed out",
+
+		CodeIncompatibleProtocol: "client version is incompatible, please update",
+	})
+	Default.RegisterAll("zh", map[Code]string{
+		CodeOK:             "成功",
+		CodeInternal:       "服务器内部错误",
+		CodeInvalidMessage: "无效的消息",
+		CodeUnauthorized:   "未授权",
+		CodeRateLimited:    "请求过于频繁，请稍后再试",
+		CodeServerBusy:     "服务器繁忙",
+		CodeNotFound:       "未找到",
+		CodeTimeout:        "请求超时",
+
+		CodeIncompatibleProtocol: "客户端版本不兼容，请更新",
+	})
+}
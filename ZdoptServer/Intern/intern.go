@@ -0,0 +1,106 @@
+// Package Intern 并发安全的字符串驻留：消息类型名、指标标签等高频重复字符串只保留一份，
+// 路由与指标的 map 键共享同一底层数组，热路径上避免重复拼接与分配
+package Intern
+
+import (
+	"expvar"
+	"hash/maphash"
+	"sync"
+)
+
+const shardCount = 32
+
+var internMisses = expvar.NewInt("intern.misses") // 新驻留的字符串数（命中路径不计数，避免争用）
+
+// Pool 分片字符串驻留池
+type Pool struct {
+	seed   maphash.Seed
+	shards [shardCount]shard
+}
+
+type shard struct {
+	mu sync.RWMutex
+	m  map[string]string
+}
+
+// Default 进程级驻留池
+var Default = NewPool()
+
+// NewPool 创建驻留池
+func NewPool() *Pool {
+	p := &Pool{seed: maphash.MakeSeed()}
+	for i := range p.shards {
+		p.shards[i].m = make(map[string]string)
+	}
+	return p
+}
+
+// String 返回与 s 相等的规范字符串
+func (p *Pool) String(s string) string {
+	sh := &p.shards[maphash.String(p.seed, s)%shardCount]
+	sh.mu.RLock()
+	v, ok := sh.m[s]
+	sh.mu.RUnlock()
+	if ok {
+		return v
+	}
+	return sh.store(s)
+}
+
+// Bytes 返回与 b 内容相等的规范字符串，已驻留时不分配
+func (p *Pool) Bytes(b []byte) string {
+	sh := &p.shards[maphash.Bytes(p.seed, b)%shardCount]
+	sh.mu.RLock()
+	v, ok := sh.m[string(b)]
+	sh.mu.RUnlock()
+	if ok {
+		return v
+	}
+	return sh.store(string(b))
+}
+
+// Len 驻留的字符串数
+func (p *Pool) Len() int {
+	n := 0
+	for i := range p.shards {
+		sh := &p.shards[i]
+		sh.mu.RLock()
+		n += len(sh.m)
+		sh.mu.RUnlock()
+	}
+	return n
+}
+
+func (sh *shard) store(s string) string {
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	if v, ok := sh.m[s]; ok {
+		return v
+	}
+	internMisses.Add(1)
+	sh.m[s] = s
+	return s
+}
+
+// String 经默认池驻留
+func String(s string) string {
+	return Default.String(s)
+}
+
+// Bytes 经默认池驻留
+func Bytes(b []byte) string {
+	return Default.Bytes(b)
+}
+
+// Join 以 sep 连接各段并驻留：在栈上缓冲拼接，已驻留的组合不分配（用于指标标签等动态键）
+func Join(sep string, parts ...string) string {
+	var stack [128]byte
+	buf := stack[:0]
+	for i, part := range parts {
+		if i > 0 {
+			buf = append(buf, sep...)
+		}
+		buf = append(buf, part...)
+	}
+	return Bytes(buf)
+}
@@ -0,0 +1,118 @@
+package Intern
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+	"unsafe"
+)
+
+func sameData(a, b string) bool {
+	return unsafe.StringData(a) == unsafe.StringData(b)
+}
+
+func TestStringReturnsCanonicalCopy(t *testing.T) {
+	p := NewPool()
+	first := p.String(string([]byte("Actor.Ping")))
+	second := p.String(string([]byte("Actor.Ping")))
+	if first != second || !sameData(first, second) {
+		t.Fatal("equal strings not canonicalized to one backing array")
+	}
+	if got := p.Bytes([]byte("Actor.Ping")); !sameData(got, first) {
+		t.Fatal("Bytes did not return the interned string")
+	}
+	if p.Len() != 1 {
+		t.Fatalf("Len = %d, want 1", p.Len())
+	}
+}
+
+func TestHitsDoNotAllocate(t *testing.T) {
+	Join(".", "Ping", "handled")
+	key := []byte("Ping.handled")
+	allocs := testing.AllocsPerRun(1000, func() {
+		Bytes(key)
+		Join(".", "Ping", "handled")
+	})
+	if allocs != 0 {
+		t.Fatalf("interned lookups allocate %v times per run", allocs)
+	}
+}
+
+func TestConcurrentInternAgrees(t *testing.T) {
+	p := NewPool()
+	const workers, keys = 8, 256
+	results := make([][]string, workers)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			out := make([]string, keys)
+			for i := range out {
+				out[i] = p.String("type." + strconv.Itoa(i))
+			}
+			results[w] = out
+		}(w)
+	}
+	wg.Wait()
+	for w := 1; w < workers; w++ {
+		for i := range results[w] {
+			if !sameData(results[w][i], results[0][i]) {
+				t.Fatalf("worker %d key %d got a different copy", w, i)
+			}
+		}
+	}
+	if p.Len() != keys {
+		t.Fatalf("Len = %d, want %d", p.Len(), keys)
+	}
+}
+
+// BenchmarkIntern 热路径上的指标键：intern 为驻留拼接（命中后零分配），concat 为每次拼接新字符串；
+// 两者都以结果作 map 键累加计数
+func BenchmarkIntern(b *testing.B) {
+	types := make([]string, 64)
+	for i := range types {
+		types[i] = "Pb.Message" + strconv.Itoa(i)
+	}
+	b.Run("intern", func(b *testing.B) {
+		counts := make(map[string]int, len(types))
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			counts[Join(".", types[i%len(types)], "handled")]++
+		}
+	})
+	b.Run("concat", func(b *testing.B) {
+		counts := make(map[string]int, len(types))
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			counts[types[i%len(types)]+".handled"]++
+		}
+	})
+	b.Run("parallel", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetParallelism(16) // 多个 Actor 同时上报
+		b.RunParallel(func(pb *testing.PB) {
+			for i := 0; pb.Next(); i++ {
+				String(types[i%len(types)])
+			}
+		})
+	})
+	b.Run("parallel_mutex_map", func(b *testing.B) {
+		var (
+			mu sync.Mutex
+			m  = make(map[string]string)
+		)
+		b.ReportAllocs()
+		b.SetParallelism(16)
+		b.RunParallel(func(pb *testing.PB) {
+			for i := 0; pb.Next(); i++ {
+				s := types[i%len(types)]
+				mu.Lock()
+				if _, ok := m[s]; !ok {
+					m[s] = s
+				}
+				mu.Unlock()
+			}
+		})
+	})
+}
@@ -0,0 +1,59 @@
+package License
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+type overrideRequest struct {
+	Kind  string `json:"kind"`
+	Limit int    `json:"limit"`
+	TTL   string `json:"ttl"` // 如 "2h"
+	Note  string `json:"note,omitempty"`
+}
+
+// Handler 管理接口：GET 查看授权与占用，POST 临时调整上限，DELETE ?kind= 取消调整
+func (e *Enforcer) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			writeJSON(w, http.StatusOK, e.Status())
+
+		case http.MethodPost:
+			var req overrideRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				http.Error(w, "invalid request: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+			ttl, err := time.ParseDuration(req.TTL)
+			if err != nil {
+				http.Error(w, "invalid ttl: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+			o, err := e.SetOverride(req.Kind, req.Limit, ttl, req.Note)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			writeJSON(w, http.StatusCreated, o)
+
+		case http.MethodDelete:
+			if !e.ClearOverride(r.URL.Query().Get("kind")) {
+				http.Error(w, "no override", http.StatusNotFound)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+
+		default:
+			w.Header().Set("Allow", "GET, POST, DELETE")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,272 @@
+package License
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+	"zdopt/ZdoptServer/Logs"
+)
+
+var (
+	ErrInvalidSignature = errors.New("license signature invalid")
+	ErrLicenseExpired   = errors.New("license expired")
+	ErrWrongNode        = errors.New("license issued for another node")
+	ErrLimitReached     = errors.New("license limit reached")
+
+	blockedActions = expvar.NewMap("license.blocked") // 按限制项统计被拒绝的操作
+	overrideSets   = expvar.NewInt("license.overrides")
+
+	logger = Logs.CreateConsoleLogConfig("License")
+)
+
+// 限制项
+const (
+	KindCCU   = "ccu"   // 同时在线会话数
+	KindRooms = "rooms" // 本节点房间数
+)
+
+// Limits 托管方授权给本节点的上限，0 表示不限制
+type Limits struct {
+	Node     string    `json:"node,omitempty"` // 绑定的节点，为空时不限节点
+	MaxCCU   int       `json:"max_ccu,omitempty"`
+	MaxRooms int       `json:"max_rooms,omitempty"`
+	Expires  time.Time `json:"expires,omitempty"` // 零值表示不过期
+}
+
+func (l Limits) limit(kind string) int {
+	switch kind {
+	case KindCCU:
+		return l.MaxCCU
+	case KindRooms:
+		return l.MaxRooms
+	}
+	return 0
+}
+
+// signedFile 签名文件：signature 是对 limits 紧凑 JSON 的 Ed25519 签名（文件可重新排版）
+type signedFile struct {
+	Limits    json.RawMessage `json:"limits"`
+	Signature []byte          `json:"signature"`
+}
+
+// Sign 生成签名文件内容（签发工具使用）
+func Sign(limits Limits, key ed25519.PrivateKey) ([]byte, error) {
+	raw, err := json.Marshal(limits)
+	if err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(signedFile{Limits: raw, Signature: ed25519.Sign(key, raw)}, "", "  ")
+}
+
+// Verify 校验签名文件并返回授权上限；node 非空且与授权绑定的节点不一致时返回 ErrWrongNode
+func Verify(data []byte, pub ed25519.PublicKey, node string) (Limits, error) {
+	var f signedFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return Limits{}, fmt.Errorf("parse license: %w", err)
+	}
+	var raw bytes.Buffer
+	if err := json.Compact(&raw, f.Limits); err != nil {
+		return Limits{}, fmt.Errorf("parse license limits: %w", err)
+	}
+	if len(pub) != ed25519.PublicKeySize || !ed25519.Verify(pub, raw.Bytes(), f.Signature) {
+		return Limits{}, ErrInvalidSignature
+	}
+	var limits Limits
+	if err := json.Unmarshal(f.Limits, &limits); err != nil {
+		return Limits{}, fmt.Errorf("parse license limits: %w", err)
+	}
+	if limits.Node != "" && node != "" && limits.Node != node {
+		return Limits{}, fmt.Errorf("%w: %s", ErrWrongNode, limits.Node)
+	}
+	return limits, nil
+}
+
+// Load 读取并校验签名文件
+func Load(path string, pub ed25519.PublicKey, node string) (Limits, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Limits{}, fmt.Errorf("read license: %w", err)
+	}
+	return Verify(data, pub, node)
+}
+
+// BlockedEvent 限制拦截操作时发出的结构化事件
+type BlockedEvent struct {
+	Kind    string    `json:"kind"`
+	Subject string    `json:"subject,omitempty"` // 会话地址或房间 ID
+	Limit   int       `json:"limit"`
+	Current int       `json:"current"`
+	Reason  string    `json:"reason"`
+	Time    time.Time `json:"time"`
+}
+
+// Override 管理接口设置的临时上限，到期后恢复授权值
+type Override struct {
+	Limit int       `json:"limit"` // 0 表示临时不限制
+	Until time.Time `json:"until"`
+	Note  string    `json:"note,omitempty"`
+}
+
+// Enforcer 在会话接入与房间创建时检查授权上限
+type Enforcer struct {
+	mu        sync.Mutex
+	limits    Limits
+	overrides map[string]Override
+	used      map[string]int
+	onBlocked func(BlockedEvent)
+	now       func() time.Time
+}
+
+// NewEnforcer 创建限制检查，onBlocked 为空时只记录日志与指标
+func NewEnforcer(limits Limits, onBlocked func(BlockedEvent)) *Enforcer {
+	return &Enforcer{
+		limits:    limits,
+		overrides: make(map[string]Override),
+		used:      make(map[string]int),
+		onBlocked: onBlocked,
+		now:       time.Now,
+	}
+}
+
+// SetLimits 替换授权上限（续期或更换授权文件），已占用的额度不受影响
+func (e *Enforcer) SetLimits(limits Limits) {
+	e.mu.Lock()
+	e.limits = limits
+	e.mu.Unlock()
+}
+
+// Limits 当前授权上限
+func (e *Enforcer) Limits() Limits {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.limits
+}
+
+// AdmitSession 会话接入时调用，成功时返回释放函数（会话结束时调用一次）
+func (e *Enforcer) AdmitSession(remote string) (func(), error) {
+	return e.acquire(KindCCU, remote)
+}
+
+// AdmitRoom 房间创建时调用，成功时返回释放函数（房间销毁时调用一次）
+func (e *Enforcer) AdmitRoom(id string) (func(), error) {
+	return e.acquire(KindRooms, id)
+}
+
+// SetOverride 管理接口临时调整某项上限，ttl 到期后恢复授权值
+func (e *Enforcer) SetOverride(kind string, limit int, ttl time.Duration, note string) (Override, error) {
+	if kind != KindCCU && kind != KindRooms {
+		return Override{}, fmt.Errorf("unknown limit kind %q", kind)
+	}
+	if limit < 0 || ttl <= 0 {
+		return Override{}, fmt.Errorf("invalid override: limit %d ttl %v", limit, ttl)
+	}
+	o := Override{Limit: limit, Until: e.now().Add(ttl), Note: note}
+	e.mu.Lock()
+	e.overrides[kind] = o
+	e.mu.Unlock()
+	overrideSets.Add(1)
+	logger.Printf("override %s limit to %d until %s: %s", kind, limit, o.Until.Format(time.RFC3339), note)
+	return o, nil
+}
+
+// ClearOverride 取消临时调整，返回是否存在
+func (e *Enforcer) ClearOverride(kind string) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	_, ok := e.overrides[kind]
+	delete(e.overrides, kind)
+	return ok
+}
+
+// Status 各项上限的当前状态
+type Status struct {
+	Limits    Limits              `json:"limits"`
+	Expired   bool                `json:"expired"`
+	Used      map[string]int      `json:"used"`
+	Effective map[string]int      `json:"effective"` // 生效的上限（含临时调整），0 表示不限制
+	Overrides map[string]Override `json:"overrides,omitempty"`
+}
+
+// Status 返回当前状态
+func (e *Enforcer) Status() Status {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	now := e.now()
+	st := Status{
+		Limits:    e.limits,
+		Expired:   e.expired(now),
+		Used:      make(map[string]int, 2),
+		Effective: make(map[string]int, 2),
+		Overrides: make(map[string]Override, len(e.overrides)),
+	}
+	for _, kind := range []string{KindCCU, KindRooms} {
+		st.Used[kind] = e.used[kind]
+		st.Effective[kind], _ = e.effective(kind, now)
+	}
+	for kind, o := range e.overrides {
+		if now.Before(o.Until) {
+			st.Overrides[kind] = o
+		}
+	}
+	return st
+}
+
+func (e *Enforcer) acquire(kind, subject string) (func(), error) {
+	e.mu.Lock()
+	now := e.now()
+	limit, overridden := e.effective(kind, now)
+	current := e.used[kind]
+	var err error
+	var reason string
+	switch {
+	case !overridden && e.expired(now):
+		err, reason = ErrLicenseExpired, "license expired"
+	case limit > 0 && current >= limit:
+		err, reason = fmt.Errorf("%w: %s %d/%d", ErrLimitReached, kind, current, limit), "limit reached"
+	default:
+		e.used[kind] = current + 1
+	}
+	e.mu.Unlock()
+
+	if err != nil {
+		e.blocked(BlockedEvent{Kind: kind, Subject: subject, Limit: limit, Current: current, Reason: reason, Time: now})
+		return nil, err
+	}
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			e.mu.Lock()
+			e.used[kind]--
+			e.mu.Unlock()
+		})
+	}, nil
+}
+
+// effective 生效的上限：未过期的临时调整优先（调用方持有锁）
+func (e *Enforcer) effective(kind string, now time.Time) (int, bool) {
+	if o, ok := e.overrides[kind]; ok {
+		if now.Before(o.Until) {
+			return o.Limit, true
+		}
+		delete(e.overrides, kind)
+	}
+	return e.limits.limit(kind), false
+}
+
+func (e *Enforcer) expired(now time.Time) bool {
+	return !e.limits.Expires.IsZero() && now.After(e.limits.Expires)
+}
+
+func (e *Enforcer) blocked(ev BlockedEvent) {
+	blockedActions.Add(ev.Kind, 1)
+	logger.Printf("blocked %s for %s: %s (%d/%d)", ev.Kind, ev.Subject, ev.Reason, ev.Current, ev.Limit)
+	if e.onBlocked != nil {
+		e.onBlocked(ev)
+	}
+}
@@ -0,0 +1,45 @@
+package Lifecycle
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// Handler 管理接口：GET 列出模块状态，POST ?module=&action=enable|disable 手动恢复或停用
+func (m *Manager) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(m.Statuses())
+
+		case http.MethodPost:
+			q := r.URL.Query()
+			name := q.Get("module")
+			var err error
+			switch q.Get("action") {
+			case "enable":
+				err = m.Enable(name)
+			case "disable":
+				err = m.Disable(name)
+			default:
+				http.Error(w, "action must be enable or disable", http.StatusBadRequest)
+				return
+			}
+			if err != nil {
+				status := http.StatusInternalServerError
+				if errors.Is(err, ErrModuleNotFound) {
+					status = http.StatusNotFound
+				}
+				http.Error(w, err.Error(), status)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+
+		default:
+			w.Header().Set("Allow", "GET, POST")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+}
@@ -0,0 +1,67 @@
+package Lifecycle
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"zdopt/ZdoptServer/Persist"
+)
+
+// Backup 对模块登记的所有持久化存储做时间点备份，写入 dir 下带清单与校验和的备份目录并返回其路径
+func (s *Server) Backup(dir string) (string, error) {
+	return Persist.Backup(dir)
+}
+
+// Restore 校验备份后恢复已登记的存储，返回备份中存在但当前未登记而被跳过的存储名
+func (s *Server) Restore(backup string) ([]string, error) {
+	return Persist.Restore(backup)
+}
+
+// BackupHandler 管理接口：POST ?action=backup 在 dir 下创建备份，
+// POST ?action=restore&backup=<备份目录名> 从 dir 下的该备份恢复
+func (s *Server) BackupHandler(dir string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", "POST")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		q := r.URL.Query()
+		var resp interface{}
+		var err error
+		switch q.Get("action") {
+		case "backup":
+			var path string
+			path, err = s.Backup(dir)
+			resp = map[string]string{"backup": filepath.Base(path)}
+		case "restore":
+			name := q.Get("backup")
+			if name == "" {
+				http.Error(w, "backup required", http.StatusBadRequest)
+				return
+			}
+			var skipped []string
+			// 只接受 dir 下的备份目录名
+			skipped, err = s.Restore(filepath.Join(dir, filepath.Base(name)))
+			resp = map[string][]string{"skipped": skipped}
+		default:
+			http.Error(w, "action must be backup or restore", http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			status := http.StatusInternalServerError
+			switch {
+			case errors.Is(err, os.ErrNotExist):
+				status = http.StatusNotFound
+			case errors.Is(err, Persist.ErrJournalsOpen):
+				status = http.StatusConflict
+			}
+			http.Error(w, err.Error(), status)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(resp)
+	})
+}
@@ -0,0 +1,312 @@
+package Lifecycle
+
+import (
+	"errors"
+	"expvar"
+	"fmt"
+	"runtime/debug"
+	"sort"
+	"sync"
+	"time"
+)
+
+var (
+	ErrModuleExists   = errors.New("module already registered")
+	ErrModuleNotFound = errors.New("module not found")
+	ErrModuleDisabled = errors.New("module disabled")
+
+	modulePanics   = expvar.NewMap("lifecycle.panics")
+	moduleDisabled = expvar.NewMap("lifecycle.disabled") // 1 表示已停用
+)
+
+// Budget 模块 panic 预算
+type Budget struct {
+	MaxPanics int           // Within 时间内允许的 panic 次数，超出后停用模块
+	Within    time.Duration // 计数窗口
+	Cooldown  time.Duration // 停用后自动恢复的等待时间，0 表示需手动恢复
+}
+
+// DefaultBudget 默认预算：一分钟内最多 3 次，五分钟后自动恢复
+func DefaultBudget() Budget {
+	return Budget{MaxPanics: 3, Within: time.Minute, Cooldown: 5 * time.Minute}
+}
+
+// AlertKind 告警类型
+type AlertKind int
+
+const (
+	AlertPanic    AlertKind = iota // 模块发生 panic（预算内）
+	AlertDisabled                  // 超出预算，模块已停用
+	AlertEnabled                   // 模块已恢复
+)
+
+func (k AlertKind) String() string {
+	switch k {
+	case AlertPanic:
+		return "panic"
+	case AlertDisabled:
+		return "disabled"
+	case AlertEnabled:
+		return "enabled"
+	default:
+		return fmt.Sprintf("alert(%d)", int(k))
+	}
+}
+
+// Alert 模块状态告警
+type Alert struct {
+	Module string
+	Kind   AlertKind
+	Value  interface{} // panic 值
+	Stack  []byte
+	Time   time.Time
+}
+
+// Hooks 模块停用/恢复时的回调（关闭功能入口、释放资源等）
+type Hooks struct {
+	OnDisable func()
+	OnEnable  func()
+}
+
+// Status 模块状态快照
+type Status struct {
+	Name          string
+	Enabled       bool
+	RecentPanics  int
+	DisabledUntil time.Time // 零值表示未停用或需手动恢复
+}
+
+// Manager 模块生命周期管理：统计各模块 panic，超出预算时停用并告警，冷却后可自动恢复
+type Manager struct {
+	mu      sync.Mutex
+	modules map[string]*Guard
+	onAlert func(Alert)
+}
+
+// NewManager 创建生命周期管理器，onAlert 可为空
+func NewManager(onAlert func(Alert)) *Manager {
+	return &Manager{modules: make(map[string]*Guard), onAlert: onAlert}
+}
+
+// Register 登记模块并返回其守卫，模块代码经守卫执行以受预算保护
+func (m *Manager) Register(name string, budget Budget, hooks Hooks) (*Guard, error) {
+	def := DefaultBudget()
+	if budget.MaxPanics <= 0 {
+		budget.MaxPanics = def.MaxPanics
+	}
+	if budget.Within <= 0 {
+		budget.Within = def.Within
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.modules[name]; ok {
+		return nil, fmt.Errorf("%w: %s", ErrModuleExists, name)
+	}
+	g := &Guard{name: name, budget: budget, hooks: hooks, manager: m, enabled: true}
+	m.modules[name] = g
+	moduleDisabled.Add(name, 0)
+	return g, nil
+}
+
+// Guard 返回已登记模块的守卫
+func (m *Manager) Guard(name string) (*Guard, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	g, ok := m.modules[name]
+	return g, ok
+}
+
+// Enable 手动恢复模块（管理接口覆盖）
+func (m *Manager) Enable(name string) error {
+	g, ok := m.Guard(name)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrModuleNotFound, name)
+	}
+	g.enable()
+	return nil
+}
+
+// Disable 手动停用模块
+func (m *Manager) Disable(name string) error {
+	g, ok := m.Guard(name)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrModuleNotFound, name)
+	}
+	g.disable(nil, nil)
+	return nil
+}
+
+// Statuses 所有模块状态（按名称排序）
+func (m *Manager) Statuses() []Status {
+	m.mu.Lock()
+	guards := make([]*Guard, 0, len(m.modules))
+	for _, g := range m.modules {
+		guards = append(guards, g)
+	}
+	m.mu.Unlock()
+
+	out := make([]Status, 0, len(guards))
+	for _, g := range guards {
+		out = append(out, g.Status())
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+func (m *Manager) alert(a Alert) {
+	if m.onAlert != nil {
+		m.onAlert(a)
+	}
+}
+
+// Guard 单个模块的 panic 守卫
+type Guard struct {
+	name    string
+	budget  Budget
+	hooks   Hooks
+	manager *Manager
+
+	mu      sync.Mutex
+	enabled bool
+	panics  []time.Time
+	until   time.Time
+	timer   *time.Timer
+}
+
+// Name 模块名
+func (g *Guard) Name() string {
+	return g.name
+}
+
+// Enabled 模块是否启用；入口处应检查，停用期间拒绝新请求
+func (g *Guard) Enabled() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.enabled
+}
+
+// Run 在守卫下执行：模块停用时返回 ErrModuleDisabled，panic 被捕获计入预算并以错误返回
+func (g *Guard) Run(fn func()) (err error) {
+	if !g.Enabled() {
+		return fmt.Errorf("%w: %s", ErrModuleDisabled, g.name)
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			g.record(r, debug.Stack())
+			err = fmt.Errorf("module %s panicked: %v", g.name, r)
+		}
+	}()
+	fn()
+	return nil
+}
+
+// Go 在新协程中以守卫执行，模块停用时不启动
+func (g *Guard) Go(fn func()) bool {
+	if !g.Enabled() {
+		return false
+	}
+	go func() { _ = g.Run(fn) }()
+	return true
+}
+
+// Recover 供 defer 使用：捕获 panic 并计入预算（用于无法包装为闭包的调用点）
+func (g *Guard) Recover() {
+	if r := recover(); r != nil {
+		g.record(r, debug.Stack())
+	}
+}
+
+// Status 模块状态快照
+func (g *Guard) Status() Status {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return Status{
+		Name:          g.name,
+		Enabled:       g.enabled,
+		RecentPanics:  g.recentLocked(time.Now()),
+		DisabledUntil: g.until,
+	}
+}
+
+// record 记录一次 panic，超出预算时停用模块
+func (g *Guard) record(v interface{}, stack []byte) {
+	modulePanics.Add(g.name, 1)
+	now := time.Now()
+
+	g.mu.Lock()
+	g.panics = append(g.panics, now)
+	exceeded := g.recentLocked(now) > g.budget.MaxPanics
+	g.mu.Unlock()
+
+	g.manager.alert(Alert{Module: g.name, Kind: AlertPanic, Value: v, Stack: stack, Time: now})
+	if exceeded {
+		g.disable(v, stack)
+	}
+}
+
+// recentLocked 统计窗口内的 panic 数并清理过期记录（调用方持有 g.mu）
+func (g *Guard) recentLocked(now time.Time) int {
+	kept := g.panics[:0]
+	for _, t := range g.panics {
+		if now.Sub(t) < g.budget.Within {
+			kept = append(kept, t)
+		}
+	}
+	g.panics = kept
+	return len(kept)
+}
+
+func (g *Guard) disable(v interface{}, stack []byte) {
+	now := time.Now()
+	g.mu.Lock()
+	if !g.enabled {
+		g.mu.Unlock()
+		return
+	}
+	g.enabled = false
+	g.panics = g.panics[:0]
+	if g.budget.Cooldown > 0 {
+		g.until = now.Add(g.budget.Cooldown)
+		g.timer = time.AfterFunc(g.budget.Cooldown, g.enable)
+	}
+	g.mu.Unlock()
+
+	moduleDisabled.Add(g.name, 1)
+	if g.hooks.OnDisable != nil {
+		_ = g.safe(g.hooks.OnDisable)
+	}
+	g.manager.alert(Alert{Module: g.name, Kind: AlertDisabled, Value: v, Stack: stack, Time: now})
+}
+
+func (g *Guard) enable() {
+	g.mu.Lock()
+	if g.enabled {
+		g.mu.Unlock()
+		return
+	}
+	g.enabled = true
+	g.until = time.Time{}
+	if g.timer != nil {
+		g.timer.Stop()
+		g.timer = nil
+	}
+	g.mu.Unlock()
+
+	moduleDisabled.Add(g.name, -1)
+	if g.hooks.OnEnable != nil {
+		_ = g.safe(g.hooks.OnEnable)
+	}
+	g.manager.alert(Alert{Module: g.name, Kind: AlertEnabled, Time: time.Now()})
+}
+
+// safe 执行回调，回调自身的 panic 不计入预算
+func (g *Guard) safe(fn func()) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("module %s hook panicked: %v", g.name, r)
+		}
+	}()
+	fn()
+	return nil
+}
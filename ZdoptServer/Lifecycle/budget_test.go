@@ -0,0 +1,116 @@
+package Lifecycle
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// alerts 记录告警类型
+type alerts struct {
+	mu    sync.Mutex
+	kinds []AlertKind
+}
+
+func (a *alerts) add(al Alert) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.kinds = append(a.kinds, al.Kind)
+}
+
+func (a *alerts) count(kind AlertKind) int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	n := 0
+	for _, k := range a.kinds {
+		if k == kind {
+			n++
+		}
+	}
+	return n
+}
+
+func TestGuardDisablesModuleOverBudget(t *testing.T) {
+	var got alerts
+	m := NewManager(got.add)
+	disabled, enabled := 0, 0
+	g, err := m.Register("chat", Budget{MaxPanics: 2, Within: time.Minute}, Hooks{
+		OnDisable: func() { disabled++ },
+		OnEnable:  func() { enabled++ },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Register("chat", Budget{}, Hooks{}); !errors.Is(err, ErrModuleExists) {
+		t.Fatalf("duplicate register = %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := g.Run(func() { panic("boom") }); err == nil {
+			t.Fatal("panic not reported")
+		}
+	}
+	if !g.Enabled() || g.Status().RecentPanics != 2 {
+		t.Fatalf("status within budget = %+v", g.Status())
+	}
+	_ = g.Run(func() { panic("boom") })
+	if g.Enabled() || disabled != 1 {
+		t.Fatalf("module still enabled after exceeding budget (hook calls %d)", disabled)
+	}
+	if err := g.Run(func() {}); !errors.Is(err, ErrModuleDisabled) {
+		t.Fatalf("run while disabled = %v", err)
+	}
+	if g.Go(func() {}) {
+		t.Fatal("Go started while disabled")
+	}
+	if got.count(AlertPanic) != 3 || got.count(AlertDisabled) != 1 {
+		t.Fatalf("alerts = %v", got.kinds)
+	}
+
+	// 没有冷却时间时需手动恢复
+	if err := m.Enable("chat"); err != nil {
+		t.Fatal(err)
+	}
+	if !g.Enabled() || enabled != 1 || got.count(AlertEnabled) != 1 {
+		t.Fatal("manual enable did not restore the module")
+	}
+	if err := m.Enable("missing"); !errors.Is(err, ErrModuleNotFound) {
+		t.Fatalf("enable unknown module = %v", err)
+	}
+}
+
+func TestGuardRecoversAfterCooldown(t *testing.T) {
+	m := NewManager(nil)
+	g, err := m.Register("match", Budget{MaxPanics: 1, Within: time.Minute, Cooldown: 20 * time.Millisecond}, Hooks{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Disable("match"); err != nil {
+		t.Fatal(err)
+	}
+	if st := m.Statuses(); len(st) != 1 || st[0].Enabled || st[0].DisabledUntil.IsZero() {
+		t.Fatalf("statuses while disabled = %+v", st)
+	}
+	deadline := time.Now().Add(time.Second)
+	for !g.Enabled() && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if !g.Enabled() || !g.Status().DisabledUntil.IsZero() {
+		t.Fatalf("status after cooldown = %+v", g.Status())
+	}
+}
+
+func TestGuardHookPanicsAreContained(t *testing.T) {
+	m := NewManager(nil)
+	g, err := m.Register("shop", Budget{MaxPanics: 5, Within: time.Minute}, Hooks{OnDisable: func() { panic("hook") }})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Disable("shop"); err != nil {
+		t.Fatal(err)
+	}
+	if g.Enabled() || g.Status().RecentPanics != 0 {
+		t.Fatalf("hook panic counted against the budget: %+v", g.Status())
+	}
+}
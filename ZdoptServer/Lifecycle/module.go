@@ -0,0 +1,283 @@
+package Lifecycle
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"zdopt/ZdoptServer/Actor"
+	"zdopt/ZdoptServer/Config"
+	"zdopt/ZdoptServer/Logs"
+)
+
+var (
+	ErrUnknownModule    = errors.New("unknown module")
+	ErrModuleDependency = errors.New("module dependency unresolved")
+
+	moduleMetrics = expvar.NewMap("modules") // 各模块 Metrics 快照
+)
+
+// Module 可插拔的服务器子系统：先按依赖顺序 Init，全部成功后依次 Start，关闭时逆序 Stop
+type Module interface {
+	Name() string
+	// Init 解析本模块的配置段（未配置时为 nil）并获取依赖，不应启动协程
+	Init(cfg json.RawMessage, deps *Deps) error
+	Start(ctx context.Context) error
+	Stop(ctx context.Context) error
+	// AdminRoutes 管理接口，键为挂载在 /admin/<模块名> 下的子路径（"" 表示模块根路径）
+	AdminRoutes() map[string]http.Handler
+	// Metrics 可 JSON 序列化的指标快照，发布在 expvar 的 modules.<模块名> 下，nil 表示无指标
+	Metrics() interface{}
+}
+
+// Dependent 可选接口：声明必须先于本模块初始化的模块
+type Dependent interface {
+	Requires() []string
+}
+
+// ModuleFactory 按名称登记的模块构造函数
+type ModuleFactory func() Module
+
+var (
+	moduleMu        sync.RWMutex
+	moduleFactories = make(map[string]ModuleFactory)
+)
+
+// RegisterModule 登记模块工厂（通常在模块包的 init 中调用），同名覆盖
+func RegisterModule(name string, f ModuleFactory) {
+	moduleMu.Lock()
+	defer moduleMu.Unlock()
+	moduleFactories[name] = f
+}
+
+// RegisteredModules 已登记的模块名称
+func RegisteredModules() []string {
+	moduleMu.RLock()
+	defer moduleMu.RUnlock()
+	names := make([]string, 0, len(moduleFactories))
+	for name := range moduleFactories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Deps 模块初始化时可用的共享依赖
+type Deps struct {
+	System *Actor.System
+	Config *Config.Config // 服务器加载的配置，Server.SelfTest 据此检查
+	Logger *log.Logger
+	Guard  *Guard // 本模块的 panic 守卫，模块入口应经守卫执行
+	server *Server
+}
+
+// Module 按名称获取已初始化的模块，依赖须在 Requires 中声明
+func (d *Deps) Module(name string) (Module, bool) {
+	return d.server.Module(name)
+}
+
+type loadedModule struct {
+	module Module
+	cfg    json.RawMessage
+	deps   Deps
+}
+
+// Server 模块引导：统一完成第一方与第三方模块的初始化、启动、管理接口挂载与关闭
+type Server struct {
+	manager *Manager
+	base    Deps
+
+	mu      sync.Mutex
+	modules map[string]*loadedModule
+	order   []*loadedModule // 初始化顺序
+	started int             // order 中已启动的数量
+}
+
+// NewServer 创建模块引导，模块以默认预算登记到 manager
+func NewServer(manager *Manager, deps Deps) *Server {
+	return &Server{manager: manager, base: deps, modules: make(map[string]*loadedModule)}
+}
+
+// Add 加入已构造的模块，cfg 为其配置段
+func (s *Server) Add(m Module, cfg json.RawMessage) error {
+	name := m.Name()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.modules[name]; ok {
+		return fmt.Errorf("%w: %s", ErrModuleExists, name)
+	}
+	s.modules[name] = &loadedModule{module: m, cfg: cfg}
+	return nil
+}
+
+// Load 按配置启用已登记的模块：configs 中出现的名称即启用，值为模块配置段
+func (s *Server) Load(configs map[string]json.RawMessage) error {
+	names := make([]string, 0, len(configs))
+	for name := range configs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		moduleMu.RLock()
+		f, ok := moduleFactories[name]
+		moduleMu.RUnlock()
+		if !ok {
+			return fmt.Errorf("%w: %s", ErrUnknownModule, name)
+		}
+		if err := s.Add(f(), configs[name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Module 按名称获取模块
+func (s *Server) Module(name string) (Module, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if lm, ok := s.modules[name]; ok {
+		return lm.module, true
+	}
+	return nil, false
+}
+
+// Start 按依赖顺序初始化全部模块，再依次启动；任一步失败时逆序停止已启动的模块
+func (s *Server) Start(ctx context.Context) error {
+	order, err := s.resolve()
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.order = order
+	s.mu.Unlock()
+
+	for _, lm := range order {
+		name := lm.module.Name()
+		guard, err := s.manager.Register(name, DefaultBudget(), Hooks{})
+		if err != nil {
+			return err
+		}
+		lm.deps = s.base
+		lm.deps.Guard = guard
+		lm.deps.server = s
+		if lm.deps.Logger == nil {
+			lm.deps.Logger = Logs.CreateConsoleLogConfig(name)
+		}
+		if err := guardErr(guard, func() error { return lm.module.Init(lm.cfg, &lm.deps) }); err != nil {
+			return fmt.Errorf("init module %s: %w", name, err)
+		}
+		if m := lm.module; m.Metrics() != nil {
+			moduleMetrics.Set(name, expvar.Func(m.Metrics))
+		}
+	}
+	for _, lm := range order {
+		if err := guardErr(lm.deps.Guard, func() error { return lm.module.Start(ctx) }); err != nil {
+			return errors.Join(fmt.Errorf("start module %s: %w", lm.module.Name(), err), s.Stop(ctx))
+		}
+		s.mu.Lock()
+		s.started++
+		s.mu.Unlock()
+	}
+	return nil
+}
+
+// Stop 逆序停止已启动的模块（可重复调用）
+func (s *Server) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	started := s.order[:s.started]
+	s.started = 0
+	s.mu.Unlock()
+
+	var errs []error
+	for i := len(started) - 1; i >= 0; i-- {
+		lm := started[i]
+		// 停用的模块同样需要释放资源，不经守卫
+		if err := lm.deps.Guard.safe(func() {
+			if err := lm.module.Stop(ctx); err != nil {
+				errs = append(errs, fmt.Errorf("stop module %s: %w", lm.module.Name(), err))
+			}
+		}); err != nil {
+			errs = append(errs, fmt.Errorf("stop module %s: %w", lm.module.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Mount 把各模块的管理接口挂载到 mux 的 /admin/<模块名> 下
+func (s *Server) Mount(mux *http.ServeMux) {
+	s.mu.Lock()
+	order := s.order
+	s.mu.Unlock()
+	for _, lm := range order {
+		for sub, h := range lm.module.AdminRoutes() {
+			path := "/admin/" + lm.module.Name()
+			if sub != "" {
+				path += "/" + strings.TrimPrefix(sub, "/")
+			}
+			mux.Handle(path, h)
+		}
+	}
+}
+
+// resolve 按 Requires 拓扑排序，无依赖关系的模块按名称排序
+func (s *Server) resolve() ([]*loadedModule, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	names := make([]string, 0, len(s.modules))
+	for name := range s.modules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	const (
+		visiting = 1
+		done     = 2
+	)
+	state := make(map[string]int, len(names))
+	order := make([]*loadedModule, 0, len(names))
+	var visit func(name string, path []string) error
+	visit = func(name string, path []string) error {
+		lm, ok := s.modules[name]
+		if !ok {
+			return fmt.Errorf("%w: %s requires %s", ErrModuleDependency, path[len(path)-1], name)
+		}
+		switch state[name] {
+		case done:
+			return nil
+		case visiting:
+			return fmt.Errorf("%w: cycle %s", ErrModuleDependency, strings.Join(append(path, name), " -> "))
+		}
+		state[name] = visiting
+		if d, ok := lm.module.(Dependent); ok {
+			for _, req := range d.Requires() {
+				if err := visit(req, append(path, name)); err != nil {
+					return err
+				}
+			}
+		}
+		state[name] = done
+		order = append(order, lm)
+		return nil
+	}
+	for _, name := range names {
+		if err := visit(name, nil); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
+
+// guardErr 在守卫下执行返回错误的函数
+func guardErr(g *Guard, fn func() error) error {
+	var err error
+	if runErr := g.Run(func() { err = fn() }); runErr != nil {
+		return runErr
+	}
+	return err
+}
@@ -0,0 +1,147 @@
+package Lifecycle
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// fakeModule 记录生命周期调用顺序，startErr 非 nil 时启动失败
+type fakeModule struct {
+	name     string
+	requires []string
+	startErr error
+	log      *[]string
+	cfg      json.RawMessage
+}
+
+func (m *fakeModule) Name() string       { return m.name }
+func (m *fakeModule) Requires() []string { return m.requires }
+
+func (m *fakeModule) Init(cfg json.RawMessage, deps *Deps) error {
+	for _, req := range m.requires {
+		if _, ok := deps.Module(req); !ok {
+			return errors.New("missing " + req)
+		}
+	}
+	m.cfg = cfg
+	*m.log = append(*m.log, "init "+m.name)
+	return nil
+}
+
+func (m *fakeModule) Start(context.Context) error {
+	if m.startErr != nil {
+		return m.startErr
+	}
+	*m.log = append(*m.log, "start "+m.name)
+	return nil
+}
+
+func (m *fakeModule) Stop(context.Context) error {
+	*m.log = append(*m.log, "stop "+m.name)
+	return nil
+}
+
+func (m *fakeModule) AdminRoutes() map[string]http.Handler {
+	return map[string]http.Handler{"status": http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(m.name))
+	})}
+}
+
+func (m *fakeModule) Metrics() interface{} { return nil }
+
+func TestServerStartsModulesInDependencyOrder(t *testing.T) {
+	var log []string
+	s := NewServer(NewManager(nil), Deps{})
+	for _, m := range []*fakeModule{
+		{name: "a", requires: []string{"c"}, log: &log},
+		{name: "b", log: &log},
+		{name: "c", requires: []string{"b"}, log: &log},
+	} {
+		if err := s.Add(m, json.RawMessage(`{}`)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.Add(&fakeModule{name: "a", log: &log}, nil); !errors.Is(err, ErrModuleExists) {
+		t.Fatalf("duplicate add = %v", err)
+	}
+	ctx := context.Background()
+	if err := s.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	mux := http.NewServeMux()
+	s.Mount(mux)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/c/status", nil))
+	if rec.Body.String() != "c" {
+		t.Fatalf("admin route served %q", rec.Body.String())
+	}
+	if err := s.Stop(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Stop(ctx); err != nil {
+		t.Fatal(err)
+	}
+	want := "init b,init c,init a,start b,start c,start a,stop a,stop c,stop b"
+	if got := strings.Join(log, ","); got != want {
+		t.Fatalf("lifecycle order\n got %s\nwant %s", got, want)
+	}
+}
+
+func TestServerStopsStartedModulesOnFailure(t *testing.T) {
+	var log []string
+	s := NewServer(NewManager(nil), Deps{})
+	failed := errors.New("port in use")
+	_ = s.Add(&fakeModule{name: "a", log: &log}, nil)
+	_ = s.Add(&fakeModule{name: "b", requires: []string{"a"}, startErr: failed, log: &log}, nil)
+	if err := s.Start(context.Background()); !errors.Is(err, failed) {
+		t.Fatalf("start = %v, want the module error", err)
+	}
+	if got := strings.Join(log, ","); got != "init a,init b,start a,stop a" {
+		t.Fatalf("lifecycle order %s", got)
+	}
+}
+
+func TestServerRejectsUnresolvedDependencies(t *testing.T) {
+	var log []string
+	missing := NewServer(NewManager(nil), Deps{})
+	_ = missing.Add(&fakeModule{name: "a", requires: []string{"ghost"}, log: &log}, nil)
+	if err := missing.Start(context.Background()); !errors.Is(err, ErrModuleDependency) {
+		t.Fatalf("missing dependency = %v", err)
+	}
+
+	cycle := NewServer(NewManager(nil), Deps{})
+	_ = cycle.Add(&fakeModule{name: "a", requires: []string{"b"}, log: &log}, nil)
+	_ = cycle.Add(&fakeModule{name: "b", requires: []string{"a"}, log: &log}, nil)
+	if err := cycle.Start(context.Background()); !errors.Is(err, ErrModuleDependency) || !strings.Contains(err.Error(), "cycle") {
+		t.Fatalf("cycle = %v", err)
+	}
+	if len(log) != 0 {
+		t.Fatalf("modules initialized despite unresolved dependencies: %v", log)
+	}
+
+	if err := NewServer(NewManager(nil), Deps{}).Load(map[string]json.RawMessage{"lifecycle.test.unknown": nil}); !errors.Is(err, ErrUnknownModule) {
+		t.Fatalf("load unknown module = %v", err)
+	}
+}
+
+func TestServerLoadsRegisteredModules(t *testing.T) {
+	var log []string
+	RegisterModule("lifecycle.test.echo", func() Module { return &fakeModule{name: "lifecycle.test.echo", log: &log} })
+	s := NewServer(NewManager(nil), Deps{})
+	if err := s.Load(map[string]json.RawMessage{"lifecycle.test.echo": json.RawMessage(`{"n":1}`)}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Stop(context.Background())
+	m, ok := s.Module("lifecycle.test.echo")
+	if !ok || string(m.(*fakeModule).cfg) != `{"n":1}` {
+		t.Fatalf("loaded module = %v, %v", m, ok)
+	}
+}
@@ -0,0 +1,119 @@
+package Lifecycle
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"zdopt/ZdoptServer/Config"
+	"zdopt/ZdoptServer/Persist"
+	"zdopt/ZdoptServer/Script"
+	"zdopt/ZdoptServer/SelfTest"
+)
+
+// SelfTester 可选接口：启动自检时校验模块的配置段（未配置时为 nil）与其依赖的运行环境，
+// 在 Init 之前调用，不应产生副作用
+type SelfTester interface {
+	SelfTest(cfg json.RawMessage) error
+}
+
+// SelfTest 启动自检：按 Deps.Config（未设置时为 Config.Default）执行 SelfTest 的内置检查、配置校验，
+// 以及每个额外监听、已登记的持久化存储、脚本目录和每个已加入或配置启用的模块的检查，各项单独报告；
+// 应在监听端口与启动模块之前调用
+func (s *Server) SelfTest(extra ...SelfTest.Check) *SelfTest.Report {
+	cfg := s.base.Config
+	if cfg == nil {
+		cfg = Config.Default()
+	}
+	st := SelfTest.DefaultConfig(cfg.Port)
+
+	checks := []SelfTest.Check{{Name: "server-config", Fn: func(SelfTest.Config) error { return cfg.Validate() }}}
+	listen := func(name, network, addr string) {
+		checks = append(checks, SelfTest.Check{Name: "listener/" + name, Fn: func(SelfTest.Config) error {
+			return SelfTest.CheckListen(network, addr)
+		}})
+	}
+	if cfg.TCPPort != 0 {
+		listen("tcp", "tcp", ":"+strconv.Itoa(cfg.TCPPort))
+	}
+	if cfg.UDPPort != 0 {
+		listen("udp", "udp", ":"+strconv.Itoa(cfg.UDPPort))
+	}
+	if cfg.Admin.Addr != "" {
+		listen("admin", "tcp", cfg.Admin.Addr)
+	}
+	dir := func(name, path string) {
+		checks = append(checks, SelfTest.Check{Name: name, Fn: func(c SelfTest.Config) error {
+			return SelfTest.CheckDir(path, c.MinFreeDisk)
+		}})
+	}
+	for _, store := range Persist.Stores() {
+		dir("store/"+store.Name, store.Dir)
+	}
+	if cfg.Admin.BackupDir != "" {
+		dir("backup-dir", cfg.Admin.BackupDir)
+	}
+	if cfg.Script.Dir != "" {
+		checks = append(checks, SelfTest.Check{Name: "scripts", Fn: func(SelfTest.Config) error {
+			return Script.Check(cfg.Script.Dir)
+		}})
+	}
+	checks = append(checks, s.moduleChecks(cfg.Modules)...)
+	return SelfTest.Run(st, append(checks, extra...)...)
+}
+
+// moduleChecks 已加入与 configs 中启用的模块各一项检查：名称已登记、依赖齐全，实现 SelfTester 时校验其配置段
+func (s *Server) moduleChecks(configs map[string]json.RawMessage) []SelfTest.Check {
+	type candidate struct {
+		module Module
+		cfg    json.RawMessage
+	}
+	modules := make(map[string]candidate)
+	s.mu.Lock()
+	for name, lm := range s.modules {
+		modules[name] = candidate{module: lm.module, cfg: lm.cfg}
+	}
+	s.mu.Unlock()
+	for name, raw := range configs {
+		if _, ok := modules[name]; ok {
+			continue
+		}
+		moduleMu.RLock()
+		f, ok := moduleFactories[name]
+		moduleMu.RUnlock()
+		var m Module
+		if ok {
+			m = f()
+		}
+		modules[name] = candidate{module: m, cfg: raw}
+	}
+
+	names := make([]string, 0, len(modules))
+	for name := range modules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	checks := make([]SelfTest.Check, 0, len(names))
+	for _, name := range names {
+		c := modules[name]
+		checks = append(checks, SelfTest.Check{Name: "module/" + name, Fn: func(SelfTest.Config) error {
+			if c.module == nil {
+				return fmt.Errorf("%w: %s", ErrUnknownModule, name)
+			}
+			var errs []error
+			if d, ok := c.module.(Dependent); ok {
+				for _, req := range d.Requires() {
+					if _, ok := modules[req]; !ok {
+						errs = append(errs, fmt.Errorf("%w: %s requires %s", ErrModuleDependency, name, req))
+					}
+				}
+			}
+			if t, ok := c.module.(SelfTester); ok {
+				errs = append(errs, t.SelfTest(c.cfg))
+			}
+			return errors.Join(errs...)
+		}})
+	}
+	return checks
+}
@@ -0,0 +1,278 @@
+package Limit
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var ErrWeightTooLarge = errors.New("limiter weight exceeds capacity")
+
+var (
+	acquired = expvar.NewMap("limit.acquired") // 按子系统统计成功获取次数
+	waited   = expvar.NewMap("limit.waited")   // 需要排队才获取到的次数（争用）
+	waitTime = expvar.NewMap("limit.wait_us")  // 累计排队时间（微秒）
+	inline   = expvar.NewMap("limit.inline")   // Go 因额度耗尽在调用方协程执行的次数
+	rejected = expvar.NewMap("limit.rejected") // TryAcquire 失败或 Acquire 被取消的次数
+)
+
+func init() {
+	expvar.Publish("limit.inuse", expvar.Func(func() interface{} {
+		return Default().Stats()
+	}))
+}
+
+// Config 并发上限：Global 为全部子系统合计的额度，Quotas 为各子系统额度（未列出的子系统只受全局额度约束）
+type Config struct {
+	Global int64
+	Quotas map[string]int64
+}
+
+// DefaultConfig 默认配置：全局 65536，无子系统配额
+func DefaultConfig() Config {
+	return Config{Global: 65536}
+}
+
+// Stats 当前占用
+type Stats struct {
+	Global    int64            `json:"global"`
+	InUse     int64            `json:"in_use"`
+	Waiting   int              `json:"waiting"`
+	Subsystem map[string]int64 `json:"subsystem"`
+}
+
+// Limiter 带权信号量：全局额度之外每个子系统可再设配额；排队者按 FIFO 获取全局额度，
+// 只因自身子系统配额不足而等待的排队者不阻塞其他子系统
+type Limiter struct {
+	mu      sync.Mutex
+	global  int64
+	inUse   int64
+	quotas  map[string]int64
+	used    map[string]int64
+	waiters []*waiter
+}
+
+type waiter struct {
+	subsystem string
+	n         int64
+	ready     chan struct{}
+}
+
+// New 创建限制器，零值字段使用默认值
+func New(cfg Config) *Limiter {
+	l := &Limiter{used: make(map[string]int64)}
+	l.Configure(cfg)
+	return l
+}
+
+// Configure 调整额度，已持有的额度不受影响，放宽后唤醒排队者
+func (l *Limiter) Configure(cfg Config) {
+	if cfg.Global <= 0 {
+		cfg.Global = DefaultConfig().Global
+	}
+	quotas := make(map[string]int64, len(cfg.Quotas))
+	for name, q := range cfg.Quotas {
+		if q > 0 {
+			quotas[name] = q
+		}
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.global = cfg.Global
+	l.quotas = quotas
+	l.notify()
+}
+
+// fits 是否可立即获取，返回值区分全局额度不足与子系统配额不足
+func (l *Limiter) fits(subsystem string, n int64) (ok, globalFull bool) {
+	if l.inUse+n > l.global {
+		return false, true
+	}
+	if q, limited := l.quotas[subsystem]; limited && l.used[subsystem]+n > q {
+		return false, false
+	}
+	return true, false
+}
+
+func (l *Limiter) take(subsystem string, n int64) {
+	l.inUse += n
+	l.used[subsystem] += n
+}
+
+// notify 按顺序唤醒可获取的排队者，遇到全局额度不足的排队者即停止，保证大权重请求不被饿死
+func (l *Limiter) notify() {
+	kept := l.waiters[:0]
+	blocked := false
+	for _, w := range l.waiters {
+		if !blocked {
+			ok, globalFull := l.fits(w.subsystem, w.n)
+			if ok {
+				l.take(w.subsystem, w.n)
+				close(w.ready)
+				continue
+			}
+			blocked = globalFull
+		}
+		kept = append(kept, w)
+	}
+	clear(l.waiters[len(kept):])
+	l.waiters = kept
+}
+
+// Acquire 获取 n 个额度，不足时排队直到 ctx 结束
+func (l *Limiter) Acquire(ctx context.Context, subsystem string, n int64) error {
+	l.mu.Lock()
+	if n > l.global {
+		l.mu.Unlock()
+		return fmt.Errorf("%w: %s wants %d of %d", ErrWeightTooLarge, subsystem, n, l.global)
+	}
+	if ok, _ := l.fits(subsystem, n); ok && !l.queuedAhead(subsystem) {
+		l.take(subsystem, n)
+		l.mu.Unlock()
+		acquired.Add(subsystem, 1)
+		return nil
+	}
+	w := &waiter{subsystem: subsystem, n: n, ready: make(chan struct{})}
+	l.waiters = append(l.waiters, w)
+	l.mu.Unlock()
+
+	start := time.Now()
+	select {
+	case <-w.ready:
+	case <-ctx.Done():
+		l.mu.Lock()
+		select {
+		case <-w.ready:
+			// 取消与获取同时发生，归还额度
+			l.release(subsystem, n)
+		default:
+			l.remove(w)
+		}
+		l.mu.Unlock()
+		rejected.Add(subsystem, 1)
+		return ctx.Err()
+	}
+	acquired.Add(subsystem, 1)
+	waited.Add(subsystem, 1)
+	waitTime.Add(subsystem, time.Since(start).Microseconds())
+	return nil
+}
+
+// queuedAhead 是否有排队者因全局额度等待（新请求不插队）
+func (l *Limiter) queuedAhead(subsystem string) bool {
+	for _, w := range l.waiters {
+		if ok, globalFull := l.fits(w.subsystem, w.n); !ok && globalFull {
+			return true
+		}
+		if w.subsystem == subsystem {
+			return true
+		}
+	}
+	return false
+}
+
+func (l *Limiter) remove(w *waiter) {
+	for i, cur := range l.waiters {
+		if cur == w {
+			l.waiters = append(l.waiters[:i], l.waiters[i+1:]...)
+			break
+		}
+	}
+	// 被移除的排队者可能挡住了后续排队者
+	l.notify()
+}
+
+// TryAcquire 尝试立即获取 n 个额度
+func (l *Limiter) TryAcquire(subsystem string, n int64) bool {
+	l.mu.Lock()
+	ok, _ := l.fits(subsystem, n)
+	if ok && !l.queuedAhead(subsystem) {
+		l.take(subsystem, n)
+	} else {
+		ok = false
+	}
+	l.mu.Unlock()
+	if !ok {
+		rejected.Add(subsystem, 1)
+		return false
+	}
+	acquired.Add(subsystem, 1)
+	return true
+}
+
+// Release 归还 n 个额度
+func (l *Limiter) Release(subsystem string, n int64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.release(subsystem, n)
+}
+
+func (l *Limiter) release(subsystem string, n int64) {
+	l.inUse -= n
+	if l.used[subsystem] -= n; l.used[subsystem] <= 0 {
+		delete(l.used, subsystem)
+	}
+	if l.inUse < 0 {
+		panic("Limit: released more than held")
+	}
+	l.notify()
+}
+
+// Go 有额度时在新协程中执行 fn，额度耗尽时在调用方协程中执行（调用方执行策略，形成背压而不是无限增长）
+func (l *Limiter) Go(subsystem string, fn func()) {
+	if !l.TryAcquire(subsystem, 1) {
+		inline.Add(subsystem, 1)
+		fn()
+		return
+	}
+	go func() {
+		defer l.Release(subsystem, 1)
+		fn()
+	}()
+}
+
+// Stats 当前占用
+func (l *Limiter) Stats() Stats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	s := Stats{Global: l.global, InUse: l.inUse, Waiting: len(l.waiters), Subsystem: make(map[string]int64, len(l.used))}
+	for name, n := range l.used {
+		s.Subsystem[name] = n
+	}
+	return s
+}
+
+var global = New(DefaultConfig())
+
+// Default 进程级限制器，热点路径（批量消息处理、tick 扇出、异步关键帧等）从中获取额度
+func Default() *Limiter {
+	return global
+}
+
+// Configure 调整进程级限制器的额度
+func Configure(cfg Config) {
+	global.Configure(cfg)
+}
+
+// Go 使用进程级限制器执行 fn
+func Go(subsystem string, fn func()) {
+	global.Go(subsystem, fn)
+}
+
+// Acquire 从进程级限制器获取额度
+func Acquire(ctx context.Context, subsystem string, n int64) error {
+	return global.Acquire(ctx, subsystem, n)
+}
+
+// TryAcquire 尝试从进程级限制器立即获取额度
+func TryAcquire(subsystem string, n int64) bool {
+	return global.TryAcquire(subsystem, n)
+}
+
+// Release 归还进程级限制器的额度
+func Release(subsystem string, n int64) {
+	global.Release(subsystem, n)
+}
@@ -0,0 +1,133 @@
+package Limit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// acquireAsync 在新协程中获取额度，结果写入返回的通道
+func acquireAsync(l *Limiter, ctx context.Context, subsystem string, n int64) <-chan error {
+	done := make(chan error, 1)
+	go func() { done <- l.Acquire(ctx, subsystem, n) }()
+	return done
+}
+
+func waitQueued(t *testing.T, l *Limiter, n int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for l.Stats().Waiting != n {
+		if time.Now().After(deadline) {
+			t.Fatalf("waiting = %d, want %d", l.Stats().Waiting, n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestSubsystemQuotaDoesNotBlockOthers(t *testing.T) {
+	l := New(Config{Global: 10, Quotas: map[string]int64{"tick": 2}})
+	ctx := context.Background()
+	if !l.TryAcquire("tick", 2) {
+		t.Fatal("tick within quota rejected")
+	}
+	if l.TryAcquire("tick", 1) {
+		t.Fatal("tick beyond quota accepted")
+	}
+	blocked := acquireAsync(l, ctx, "tick", 1)
+	waitQueued(t, l, 1)
+
+	// 只因 tick 配额不足而排队，不挡住其他子系统
+	if !l.TryAcquire("io", 5) {
+		t.Fatal("io blocked by a tick waiter")
+	}
+	l.Release("tick", 1)
+	if err := <-blocked; err != nil {
+		t.Fatal(err)
+	}
+	if s := l.Stats(); s.InUse != 7 || s.Subsystem["tick"] != 2 || s.Subsystem["io"] != 5 {
+		t.Fatalf("stats = %+v", s)
+	}
+}
+
+func TestGlobalWaitersAreServedInOrder(t *testing.T) {
+	l := New(Config{Global: 4})
+	ctx := context.Background()
+	if err := l.Acquire(ctx, "a", 3); err != nil {
+		t.Fatal(err)
+	}
+	big := acquireAsync(l, ctx, "b", 4)
+	waitQueued(t, l, 1)
+	// 排队中的大权重请求之后，小请求不插队
+	if l.TryAcquire("c", 1) {
+		t.Fatal("small request jumped the queue")
+	}
+	small := acquireAsync(l, ctx, "c", 1)
+	waitQueued(t, l, 2)
+
+	l.Release("a", 3)
+	if err := <-big; err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-small:
+		t.Fatal("small request served before the big one released")
+	case <-time.After(20 * time.Millisecond):
+	}
+	l.Release("b", 4)
+	if err := <-small; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAcquireCancellation(t *testing.T) {
+	l := New(Config{Global: 2})
+	if err := l.Acquire(context.Background(), "x", 3); !errors.Is(err, ErrWeightTooLarge) {
+		t.Fatalf("oversized weight = %v, want ErrWeightTooLarge", err)
+	}
+	if !l.TryAcquire("x", 2) {
+		t.Fatal("initial acquire failed")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	canceled := acquireAsync(l, ctx, "y", 2)
+	waitQueued(t, l, 1)
+	cancel()
+	if err := <-canceled; !errors.Is(err, context.Canceled) {
+		t.Fatalf("canceled acquire = %v", err)
+	}
+	if s := l.Stats(); s.Waiting != 0 || s.InUse != 2 {
+		t.Fatalf("stats after cancel = %+v", s)
+	}
+
+	// 放宽额度后唤醒排队者
+	waiting := acquireAsync(l, context.Background(), "y", 2)
+	waitQueued(t, l, 1)
+	l.Configure(Config{Global: 4})
+	if err := <-waiting; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGoRunsInlineWhenExhausted(t *testing.T) {
+	l := New(Config{Global: 1})
+	release := make(chan struct{})
+	started := make(chan struct{})
+	l.Go("job", func() {
+		close(started)
+		<-release
+	})
+	<-started
+	ran := false
+	l.Go("job", func() { ran = true })
+	if !ran {
+		t.Fatal("exhausted Go did not run on the caller goroutine")
+	}
+	close(release)
+	deadline := time.Now().Add(time.Second)
+	for l.Stats().InUse != 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if l.Stats().InUse != 0 {
+		t.Fatal("Go did not release its slot")
+	}
+}
@@ -0,0 +1,274 @@
+package Logs
+
+import (
+	"expvar"
+	"io"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+var (
+	asyncDropped = expvar.NewInt("logs.async.dropped") // 异步队列满时丢弃的日志行数
+	asyncWritten = expvar.NewInt("logs.async.written")
+)
+
+type fieldKind uint8
+
+const (
+	kindString fieldKind = iota
+	kindInt
+	kindUint
+	kindFloat
+	kindBool
+	kindDuration
+	kindError
+)
+
+// Field 键值日志字段：按值传递，构造与编码都不分配内存
+type Field struct {
+	Key  string
+	kind fieldKind
+	num  uint64
+	str  string
+	err  error
+}
+
+// Str 字符串字段
+func Str(key, v string) Field {
+	return Field{Key: key, kind: kindString, str: v}
+}
+
+// Int 整数字段
+func Int(key string, v int) Field {
+	return Field{Key: key, kind: kindInt, num: uint64(v)}
+}
+
+// Int64 整数字段
+func Int64(key string, v int64) Field {
+	return Field{Key: key, kind: kindInt, num: uint64(v)}
+}
+
+// Uint64 无符号整数字段
+func Uint64(key string, v uint64) Field {
+	return Field{Key: key, kind: kindUint, num: v}
+}
+
+// Float64 浮点字段
+func Float64(key string, v float64) Field {
+	return Field{Key: key, kind: kindFloat, num: math.Float64bits(v)}
+}
+
+// Bool 布尔字段
+func Bool(key string, v bool) Field {
+	f := Field{Key: key, kind: kindBool}
+	if v {
+		f.num = 1
+	}
+	return f
+}
+
+// Dur 时长字段
+func Dur(key string, v time.Duration) Field {
+	return Field{Key: key, kind: kindDuration, num: uint64(v)}
+}
+
+// Err 错误字段，键固定为 error
+func Err(err error) Field {
+	return Field{Key: "error", kind: kindError, err: err}
+}
+
+// appendTo 以 key=value 形式追加，含空格、引号或控制字符的值加引号转义
+func (f Field) appendTo(buf []byte) []byte {
+	buf = append(buf, f.Key...)
+	buf = append(buf, '=')
+	switch f.kind {
+	case kindString:
+		buf = appendValue(buf, f.str)
+	case kindInt:
+		buf = strconv.AppendInt(buf, int64(f.num), 10)
+	case kindUint:
+		buf = strconv.AppendUint(buf, f.num, 10)
+	case kindFloat:
+		buf = strconv.AppendFloat(buf, math.Float64frombits(f.num), 'g', -1, 64)
+	case kindBool:
+		buf = strconv.AppendBool(buf, f.num == 1)
+	case kindDuration:
+		// time.Duration.String 会分配，按微秒整数输出后加单位
+		buf = strconv.AppendInt(buf, int64(f.num)/int64(time.Microsecond), 10)
+		buf = append(buf, "us"...)
+	case kindError:
+		if f.err == nil {
+			buf = append(buf, "<nil>"...)
+		} else {
+			buf = appendValue(buf, f.err.Error())
+		}
+	}
+	return buf
+}
+
+func appendValue(buf []byte, s string) []byte {
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r <= ' ' || r == '"' || r == '=' || r == utf8.RuneError {
+			return strconv.AppendQuote(buf, s)
+		}
+		i += size
+	}
+	if s == "" {
+		return append(buf, `""`...)
+	}
+	return append(buf, s...)
+}
+
+var bufPool = sync.Pool{New: func() interface{} {
+	b := make([]byte, 0, 256)
+	return &b
+}}
+
+// encodeLine 编码一行：时间 级别 [名称] 消息 key=value...
+func encodeLine(buf []byte, now time.Time, level Level, name, msg string, fields []Field) []byte {
+	buf = now.AppendFormat(buf, "2006-01-02T15:04:05.000Z07:00")
+	buf = append(buf, ' ')
+	buf = append(buf, level.String()...)
+	if name != "" {
+		buf = append(buf, " ["...)
+		buf = append(buf, name...)
+		buf = append(buf, ']')
+	}
+	buf = append(buf, ' ')
+	buf = append(buf, msg...)
+	for i := range fields {
+		buf = append(buf, ' ')
+		buf = fields[i].appendTo(buf)
+	}
+	return append(buf, '\n')
+}
+
+// AsyncWriter 异步写出：调用方把编码好的行放入队列后立即返回，由后台协程写出；队列满时丢弃，不阻塞热路径
+type AsyncWriter struct {
+	out     io.Writer
+	queue   chan *[]byte
+	closing chan struct{}
+	done    chan struct{}
+	once    sync.Once
+}
+
+// NewAsyncWriter 创建异步写出，queue 为队列长度
+func NewAsyncWriter(out io.Writer, queue int) *AsyncWriter {
+	if queue <= 0 {
+		queue = 1024
+	}
+	w := &AsyncWriter{
+		out:     out,
+		queue:   make(chan *[]byte, queue),
+		closing: make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	go w.run()
+	return w
+}
+
+func (w *AsyncWriter) run() {
+	defer close(w.done)
+	for {
+		select {
+		case buf := <-w.queue:
+			w.write(buf)
+		case <-w.closing:
+			for {
+				select {
+				case buf := <-w.queue:
+					w.write(buf)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (w *AsyncWriter) write(buf *[]byte) {
+	_, _ = w.out.Write(*buf)
+	asyncWritten.Add(1)
+	*buf = (*buf)[:0]
+	bufPool.Put(buf)
+}
+
+// enqueue 接管 buf（写出后归还缓冲池），队列满时丢弃
+func (w *AsyncWriter) enqueue(buf *[]byte) {
+	select {
+	case <-w.closing:
+		asyncDropped.Add(1)
+	case w.queue <- buf:
+	default:
+		asyncDropped.Add(1)
+		*buf = (*buf)[:0]
+		bufPool.Put(buf)
+	}
+}
+
+// Close 写完队列中剩余的行后返回，之后写入的行被丢弃
+func (w *AsyncWriter) Close() error {
+	w.once.Do(func() {
+		close(w.closing)
+	})
+	<-w.done
+	return nil
+}
+
+// Enabled 该级别是否会输出；热路径在拼接参数前调用，低于级别时不做任何格式化
+func (zl *ZLogger) Enabled(level Level) bool {
+	return level >= Level(zl.level.Load())
+}
+
+// SetAsync 键值日志（Debugw 等）改为经队列异步写出到当前输出，queue <= 0 时恢复同步写出
+func (zl *ZLogger) SetAsync(queue int) {
+	var next *AsyncWriter
+	if queue > 0 {
+		next = NewAsyncWriter(zl.Logger.Writer(), queue)
+	}
+	if old := zl.async.Swap(next); old != nil {
+		_ = old.Close()
+	}
+}
+
+// Logw 键值日志：级别检查先于任何编码，低于级别时零开销；编码使用池化缓冲，不分配内存
+func (zl *ZLogger) Logw(level Level, msg string, fields ...Field) {
+	if !zl.Enabled(level) {
+		return
+	}
+	bp := bufPool.Get().(*[]byte)
+	*bp = encodeLine((*bp)[:0], time.Now(), level, zl.loggerName, msg, fields)
+	if w := zl.async.Load(); w != nil {
+		w.enqueue(bp)
+		return
+	}
+	zl.mu.Lock()
+	_, _ = zl.Logger.Writer().Write(*bp)
+	zl.mu.Unlock()
+	*bp = (*bp)[:0]
+	bufPool.Put(bp)
+}
+
+// Debugw 调试级键值日志
+func (zl *ZLogger) Debugw(msg string, fields ...Field) {
+	zl.Logw(Debug, msg, fields...)
+}
+
+// Infow 信息级键值日志
+func (zl *ZLogger) Infow(msg string, fields ...Field) {
+	zl.Logw(Info, msg, fields...)
+}
+
+// Warnw 警告级键值日志
+func (zl *ZLogger) Warnw(msg string, fields ...Field) {
+	zl.Logw(Warn, msg, fields...)
+}
+
+// Errorw 错误级键值日志
+func (zl *ZLogger) Errorw(msg string, fields ...Field) {
+	zl.Logw(Error, msg, fields...)
+}
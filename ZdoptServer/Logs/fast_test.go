@@ -0,0 +1,109 @@
+package Logs
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newDiscardLogger 输出到 io.Discard 的日志器，不创建日志文件
+func newDiscardLogger(tb testing.TB, level Level) *ZLogger {
+	tb.Helper()
+	zl, err := NewZLogger("", level)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	zl.Logger.SetOutput(io.Discard)
+	return zl
+}
+
+func TestLogwEncoding(t *testing.T) {
+	var out bytes.Buffer
+	zl := newDiscardLogger(t, Debug)
+	zl.Logger.SetOutput(&out)
+	zl.Infow("handled",
+		Str("name", "a b"),
+		Int("n", -3),
+		Bool("ok", true),
+		Dur("took", 1500*time.Microsecond),
+		Err(errors.New("boom")))
+	line := out.String()
+	for _, want := range []string{` INFO handled `, `name="a b"`, `n=-3`, `ok=true`, `took=1500us`, `error=boom`} {
+		if !strings.Contains(line, want) {
+			t.Fatalf("line %q missing %q", line, want)
+		}
+	}
+	if !strings.HasSuffix(line, "\n") {
+		t.Fatalf("line %q not newline terminated", line)
+	}
+}
+
+func TestLogwDisabledDoesNotAllocate(t *testing.T) {
+	zl := newDiscardLogger(t, Info)
+	var id int64 = 42
+	allocs := testing.AllocsPerRun(1000, func() {
+		zl.Debugw("message handled", Int64("actor", id), Str("type", "Ping"), Int64("sender", id), Dur("took", time.Millisecond))
+	})
+	if allocs != 0 {
+		t.Fatalf("disabled Debugw allocates %v times per call", allocs)
+	}
+}
+
+func TestLogwEnabledDoesNotAllocate(t *testing.T) {
+	zl := newDiscardLogger(t, Debug)
+	var id int64 = 42
+	zl.Debugw("warm up") // 预热缓冲池
+	allocs := testing.AllocsPerRun(1000, func() {
+		zl.Debugw("message handled", Int64("actor", id), Str("type", "Ping"), Int64("sender", id), Dur("took", time.Millisecond))
+	})
+	if allocs != 0 {
+		t.Fatalf("enabled Debugw allocates %v times per call", allocs)
+	}
+}
+
+// BenchmarkLogw Actor 热路径上的调试日志：disabled 为低于级别时的开销（应为 0 分配），
+// sprintf 为旧写法在调用方先格式化的开销，sync / async 为实际输出时的编码与写出
+func BenchmarkLogw(b *testing.B) {
+	var id int64 = 42
+	b.Run("disabled", func(b *testing.B) {
+		zl := newDiscardLogger(b, Info)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			zl.Debugw("message handled", Int64("actor", id), Str("type", "Ping"), Int64("sender", id), Dur("took", time.Millisecond))
+		}
+	})
+	b.Run("disabled_sprintf", func(b *testing.B) {
+		zl := newDiscardLogger(b, Info)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			zl.Debug(fmt.Sprintf("message handled actor=%d type=%s sender=%d took=%v", id, "Ping", id, time.Millisecond))
+		}
+	})
+	b.Run("sync", func(b *testing.B) {
+		zl := newDiscardLogger(b, Debug)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			zl.Debugw("message handled", Int64("actor", id), Str("type", "Ping"), Int64("sender", id), Dur("took", time.Millisecond))
+		}
+	})
+	b.Run("async", func(b *testing.B) {
+		zl := newDiscardLogger(b, Debug)
+		zl.SetAsync(4096)
+		defer zl.SetAsync(0)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			zl.Debugw("message handled", Int64("actor", id), Str("type", "Ping"), Int64("sender", id), Dur("took", time.Millisecond))
+		}
+	})
+	b.Run("legacy", func(b *testing.B) {
+		zl := newDiscardLogger(b, Debug)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			zl.Debug(fmt.Sprintf("message handled actor=%d type=%s sender=%d took=%v", id, "Ping", id, time.Millisecond))
+		}
+	})
+}
@@ -1,12 +1,15 @@
 package Logs
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 )
 
 type Level int
@@ -23,12 +26,14 @@ const (
 var (
 	logDir      = "logs"
 	logDirMutex sync.Mutex
+
+	ErrUnknownLevel = errors.New("unknown log level")
 )
 
 type Logger struct {
 	*log.Logger
 	mu     sync.Mutex
-	level  Level
+	level  atomic.Int32 // Level，热路径无锁读取
 	writer logWriter
 }
 
@@ -44,11 +49,12 @@ func NewLogger(level Level, loggerName string) (*Logger, error) {
 		return nil, err
 	}
 
-	return &Logger{
+	l := &Logger{
 		Logger: baseLogger,
-		level:  level,
 		writer: writer,
-	}, nil
+	}
+	l.level.Store(int32(level))
+	return l, nil
 }
 
 func createLogger(loggerName string) (*log.Logger, logWriter, error) {
@@ -82,7 +88,20 @@ func ensureLogDir() error {
 }
 
 func (l Level) String() string {
-	return [...]string{"INFO", "WARN", "ERROR", "FATAL"}[l]
+	if l < Debug || l > Fatal {
+		return "Level(" + strconv.Itoa(int(l)) + ")"
+	}
+	return [...]string{"DEBUG", "INFO", "WARN", "ERROR", "FATAL"}[l]
+}
+
+// ParseLevel 按名称解析日志级别（不区分大小写）
+func ParseLevel(name string) (Level, error) {
+	for l := Debug; l <= Fatal; l++ {
+		if strings.EqualFold(name, l.String()) {
+			return l, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: %q", ErrUnknownLevel, name)
 }
 
 // CreateConsoleLogConfig 创建控制台日志配置
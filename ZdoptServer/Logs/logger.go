@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"sync"
+	"sync/atomic"
 )
 
 // ZLogger 结构体包含一个 logger 实例
@@ -11,6 +12,7 @@ type ZLogger struct {
 	*Logger
 	mu         sync.Mutex
 	loggerName string
+	async      atomic.Pointer[AsyncWriter] // 键值日志的异步写出，nil 为同步
 }
 
 // NewZLogger 创建一个新的 ZLogger 实例
@@ -28,14 +30,17 @@ func NewZLogger(loggerName string, level Level) (*ZLogger, error) {
 
 // SetLevel 动态设置日志级别
 func (zl *ZLogger) SetLevel(level Level) {
-	zl.mu.Lock()
-	defer zl.mu.Unlock()
-	zl.level = level
+	zl.level.Store(int32(level))
+}
+
+// Level 当前日志级别
+func (zl *ZLogger) Level() Level {
+	return Level(zl.level.Load())
 }
 
 // Log 线程安全日志记录
 func (zl *ZLogger) Log(level Level, message string) {
-	if level < zl.level {
+	if !zl.Enabled(level) {
 		return
 	}
 
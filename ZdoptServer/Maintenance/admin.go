@@ -0,0 +1,79 @@
+package Maintenance
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type scheduleRequest struct {
+	Start    time.Time `json:"start"`
+	In       string    `json:"in,omitempty"` // 相对当前时间，如 "15m"，与 Start 二选一
+	Duration string    `json:"duration"`
+	Reason   string    `json:"reason,omitempty"`
+}
+
+// Handler 管理接口：GET 列出窗口，POST 排期，DELETE ?id= 取消
+func (s *Scheduler) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			writeJSON(w, http.StatusOK, s.Windows())
+
+		case http.MethodPost:
+			var req scheduleRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				http.Error(w, "invalid request: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+			duration, err := time.ParseDuration(req.Duration)
+			if err != nil {
+				http.Error(w, "invalid duration: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+			start := req.Start
+			if req.In != "" {
+				in, err := time.ParseDuration(req.In)
+				if err != nil {
+					http.Error(w, "invalid in: "+err.Error(), http.StatusBadRequest)
+					return
+				}
+				start = s.now().Add(in)
+			}
+			win, err := s.Schedule(start, duration, req.Reason)
+			if err != nil {
+				status := http.StatusBadRequest
+				if errors.Is(err, ErrWindowOverlap) {
+					status = http.StatusConflict
+				}
+				http.Error(w, err.Error(), status)
+				return
+			}
+			writeJSON(w, http.StatusCreated, win)
+
+		case http.MethodDelete:
+			id, err := strconv.ParseInt(r.URL.Query().Get("id"), 10, 64)
+			if err != nil {
+				http.Error(w, "invalid id", http.StatusBadRequest)
+				return
+			}
+			if err := s.Cancel(id); err != nil {
+				http.Error(w, err.Error(), http.StatusNotFound)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+
+		default:
+			w.Header().Set("Allow", "GET, POST, DELETE")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
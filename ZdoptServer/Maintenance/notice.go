@@ -0,0 +1,26 @@
+package Maintenance
+
+import (
+	"zdopt/ZdoptServer/Pb"
+)
+
+// Proto 转为下发给客户端的 Pb.MaintenanceNotice
+func (n Notice) Proto() *Pb.MaintenanceNotice {
+	return &Pb.MaintenanceNotice{
+		WindowID:    n.Window.ID,
+		StartUnixMs: n.Window.Start.UnixMilli(),
+		DurationMs:  n.Window.Duration.Milliseconds(),
+		RemainingMs: max(n.Remaining, 0).Milliseconds(),
+		Reason:      n.Window.Reason,
+	}
+}
+
+// Broadcast 返回把通知经 broadcast（如 Actor.KCPListener.Broadcast）下发给全部在线会话的 Notifier，
+// 编码或发送失败时交给 onError（可为 nil）
+func Broadcast(broadcast func(msg interface{}) (int, error), onError func(Notice, error)) func(Notice) {
+	return func(n Notice) {
+		if _, err := broadcast(n.Proto()); err != nil && onError != nil {
+			onError(n, err)
+		}
+	}
+}
@@ -0,0 +1,266 @@
+package Maintenance
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+var (
+	ErrWindowInPast    = errors.New("maintenance window starts in the past")
+	ErrWindowOverlap   = errors.New("maintenance window overlaps an existing window")
+	ErrWindowNotFound  = errors.New("maintenance window not found")
+	ErrInvalidDuration = errors.New("maintenance window duration must be positive")
+	ErrMatchesBlocked  = errors.New("new matches blocked by upcoming maintenance")
+
+	noticesSent  = expvar.NewInt("maintenance.notices")
+	drainsPosted = expvar.NewInt("maintenance.drains")
+)
+
+// Window 一次维护窗口
+type Window struct {
+	ID       int64         `json:"id"`
+	Start    time.Time     `json:"start"`
+	Duration time.Duration `json:"duration"`
+	Reason   string        `json:"reason,omitempty"`
+}
+
+// End 窗口结束时间
+func (w Window) End() time.Time {
+	return w.Start.Add(w.Duration)
+}
+
+// Notice 维护倒计时通知，由 Notifier 广播给在线会话
+type Notice struct {
+	Window    Window
+	Remaining time.Duration // 距开始的剩余时间，0 表示已开始
+}
+
+// Config 维护调度参数
+type Config struct {
+	NoticeAt       []time.Duration // 开始前的各通知时间点
+	BlockMatches   time.Duration   // 开始前多久起不再开新对局
+	CheckInterval  time.Duration
+	DrainTimeout   time.Duration // 传给 Drainer 的排空时限
+	Notifier       func(Notice)
+	Drainer        func(ctx context.Context, w Window) error
+	OnDrainFailure func(w Window, err error)
+}
+
+// DefaultConfig 默认维护调度参数
+func DefaultConfig() Config {
+	return Config{
+		NoticeAt: []time.Duration{
+			30 * time.Minute, 10 * time.Minute, 5 * time.Minute,
+			time.Minute, 30 * time.Second, 10 * time.Second,
+		},
+		BlockMatches:  5 * time.Minute,
+		CheckInterval: time.Second,
+		DrainTimeout:  2 * time.Minute,
+	}
+}
+
+// scheduled 已排期窗口及其通知进度
+type scheduled struct {
+	Window
+	notified int  // 已发出的通知数（NoticeAt 降序）
+	drained  bool // 已在开始时刻触发排空
+}
+
+// Scheduler 维护窗口调度：倒计时通知、临近窗口阻止新对局、开始时自动排空
+type Scheduler struct {
+	mu      sync.Mutex
+	cfg     Config
+	windows []*scheduled // 按开始时间升序
+	lastID  int64
+	now     func() time.Time
+}
+
+// NewScheduler 创建维护调度器，未设置的参数使用默认值
+func NewScheduler(cfg Config) *Scheduler {
+	def := DefaultConfig()
+	if len(cfg.NoticeAt) == 0 {
+		cfg.NoticeAt = def.NoticeAt
+	}
+	if cfg.BlockMatches <= 0 {
+		cfg.BlockMatches = def.BlockMatches
+	}
+	if cfg.CheckInterval <= 0 {
+		cfg.CheckInterval = def.CheckInterval
+	}
+	if cfg.DrainTimeout <= 0 {
+		cfg.DrainTimeout = def.DrainTimeout
+	}
+	notices := append([]time.Duration(nil), cfg.NoticeAt...)
+	sort.Slice(notices, func(i, j int) bool { return notices[i] > notices[j] })
+	cfg.NoticeAt = notices
+
+	return &Scheduler{cfg: cfg, now: time.Now}
+}
+
+// Schedule 排期维护窗口，返回带 ID 的窗口
+func (s *Scheduler) Schedule(start time.Time, duration time.Duration, reason string) (Window, error) {
+	if duration <= 0 {
+		return Window{}, ErrInvalidDuration
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if start.Before(now) {
+		return Window{}, ErrWindowInPast
+	}
+	w := Window{Start: start, Duration: duration, Reason: reason}
+	for _, other := range s.windows {
+		if w.Start.Before(other.End()) && other.Start.Before(w.End()) {
+			return Window{}, fmt.Errorf("%w: #%d", ErrWindowOverlap, other.ID)
+		}
+	}
+
+	s.lastID++
+	w.ID = s.lastID
+	sw := &scheduled{Window: w}
+	// 排期时已错过的通知点不再补发
+	for sw.notified < len(s.cfg.NoticeAt) && start.Sub(now) < s.cfg.NoticeAt[sw.notified] {
+		sw.notified++
+	}
+	s.windows = append(s.windows, sw)
+	sort.Slice(s.windows, func(i, j int) bool { return s.windows[i].Start.Before(s.windows[j].Start) })
+	return w, nil
+}
+
+// Cancel 取消尚未结束的维护窗口
+func (s *Scheduler) Cancel(id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, w := range s.windows {
+		if w.ID == id {
+			s.windows = append(s.windows[:i], s.windows[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: #%d", ErrWindowNotFound, id)
+}
+
+// Windows 所有未结束的窗口
+func (s *Scheduler) Windows() []Window {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]Window, 0, len(s.windows))
+	for _, w := range s.windows {
+		out = append(out, w.Window)
+	}
+	return out
+}
+
+// Active 当前是否处于维护窗口中
+func (s *Scheduler) Active() (Window, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	for _, w := range s.windows {
+		if !now.Before(w.Start) && now.Before(w.End()) {
+			return w.Window, true
+		}
+	}
+	return Window{}, false
+}
+
+// AllowNewMatch 是否允许开新对局：维护中或距开始不足 BlockMatches 时返回 false 及对应窗口
+func (s *Scheduler) AllowNewMatch() (bool, *Window) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	for _, w := range s.windows {
+		if now.Before(w.End()) && w.Start.Sub(now) <= s.cfg.BlockMatches {
+			win := w.Window
+			return false, &win
+		}
+	}
+	return true, nil
+}
+
+// AdmitRoom 房间创建准入（满足 Room.Admission，经 Room.WithAdmission 使用）：AllowNewMatch 为 false 时以 ErrMatchesBlocked 拒绝
+func (s *Scheduler) AdmitRoom(id string) (func(), error) {
+	if ok, w := s.AllowNewMatch(); !ok {
+		return nil, fmt.Errorf("%w: room %q, maintenance #%d at %s", ErrMatchesBlocked, id, w.ID, w.Start.Format(time.RFC3339))
+	}
+	return func() {}, nil
+}
+
+// Run 按检查间隔推进调度，直到 ctx 结束
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.CheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.Tick(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Tick 检查一次：发出到期的倒计时通知，窗口开始时触发排空，清理已结束窗口
+func (s *Scheduler) Tick(ctx context.Context) {
+	var (
+		notices []Notice
+		drains  []Window
+	)
+
+	s.mu.Lock()
+	now := s.now()
+	kept := s.windows[:0]
+	for _, w := range s.windows {
+		if !now.Before(w.End()) {
+			continue
+		}
+		kept = append(kept, w)
+
+		remaining := w.Start.Sub(now)
+		if remaining <= 0 {
+			// 已开始：未发出的倒计时通知由开始通知代替
+			w.notified = len(s.cfg.NoticeAt)
+		}
+		for w.notified < len(s.cfg.NoticeAt) && remaining <= s.cfg.NoticeAt[w.notified] {
+			w.notified++
+			// 同一次检查跨过多个通知点时只发最近的一条
+			if w.notified == len(s.cfg.NoticeAt) || remaining > s.cfg.NoticeAt[w.notified] {
+				notices = append(notices, Notice{Window: w.Window, Remaining: remaining})
+			}
+		}
+		if remaining <= 0 && !w.drained {
+			w.drained = true
+			notices = append(notices, Notice{Window: w.Window})
+			drains = append(drains, w.Window)
+		}
+	}
+	s.windows = kept
+	s.mu.Unlock()
+
+	if s.cfg.Notifier != nil {
+		for _, n := range notices {
+			noticesSent.Add(1)
+			s.cfg.Notifier(n)
+		}
+	}
+	if s.cfg.Drainer != nil {
+		for _, w := range drains {
+			drainsPosted.Add(1)
+			go s.drain(ctx, w)
+		}
+	}
+}
+
+func (s *Scheduler) drain(ctx context.Context, w Window) {
+	ctx, cancel := context.WithTimeout(ctx, s.cfg.DrainTimeout)
+	defer cancel()
+	if err := s.cfg.Drainer(ctx, w); err != nil && s.cfg.OnDrainFailure != nil {
+		s.cfg.OnDrainFailure(w, err)
+	}
+}
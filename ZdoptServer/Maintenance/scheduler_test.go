@@ -0,0 +1,113 @@
+package Maintenance
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+	"zdopt/ZdoptServer/Pb"
+	"zdopt/ZdoptServer/Room"
+)
+
+// fakeClock 可手动推进的时钟
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	c.now = c.now.Add(d)
+	c.mu.Unlock()
+}
+
+func newTestScheduler(cfg Config) (*Scheduler, *fakeClock) {
+	clock := &fakeClock{now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	s := NewScheduler(cfg)
+	s.now = clock.Now
+	return s, clock
+}
+
+func TestTickBroadcastsCountdownNotices(t *testing.T) {
+	var sent []*Pb.MaintenanceNotice
+	broadcast := func(msg interface{}) (int, error) {
+		sent = append(sent, msg.(*Pb.MaintenanceNotice))
+		return 1, nil
+	}
+	drained := make(chan Window, 1)
+	s, clock := newTestScheduler(Config{
+		NoticeAt: []time.Duration{time.Minute, 10 * time.Minute},
+		Notifier: Broadcast(broadcast, nil),
+		Drainer: func(ctx context.Context, w Window) error {
+			drained <- w
+			return nil
+		},
+	})
+	w, err := s.Schedule(clock.Now().Add(15*time.Minute), time.Hour, "upgrade")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	for _, step := range []time.Duration{4 * time.Minute, time.Minute, 8 * time.Minute, time.Minute, time.Minute} {
+		clock.Advance(step)
+		s.Tick(ctx)
+	}
+	// 10 分钟、1 分钟两个通知点各一条，开始时一条
+	want := []int64{(10 * time.Minute).Milliseconds(), time.Minute.Milliseconds(), 0}
+	if len(sent) != len(want) {
+		t.Fatalf("broadcast %d notices, want %d", len(sent), len(want))
+	}
+	for i, n := range sent {
+		if n.GetRemainingMs() != want[i] || n.GetWindowID() != w.ID || n.GetReason() != "upgrade" ||
+			n.GetStartUnixMs() != w.Start.UnixMilli() || n.GetDurationMs() != time.Hour.Milliseconds() {
+			t.Fatalf("notice %d = %v", i, n)
+		}
+	}
+	select {
+	case got := <-drained:
+		if got.ID != w.ID {
+			t.Fatalf("drained window %d, want %d", got.ID, w.ID)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("window start did not trigger drain")
+	}
+}
+
+func TestBroadcastReportsFailures(t *testing.T) {
+	failed := errors.New("codec missing")
+	var reported error
+	notify := Broadcast(func(interface{}) (int, error) { return 0, failed }, func(_ Notice, err error) { reported = err })
+	notify(Notice{Window: Window{ID: 1}})
+	if !errors.Is(reported, failed) {
+		t.Fatalf("reported %v", reported)
+	}
+}
+
+func TestRoomCreationBlockedBeforeWindow(t *testing.T) {
+	s, clock := newTestScheduler(Config{BlockMatches: 5 * time.Minute})
+	if _, err := s.Schedule(clock.Now().Add(10*time.Minute), time.Hour, ""); err != nil {
+		t.Fatal(err)
+	}
+	r, err := Room.Create("r1", "arena", Room.WithAdmission(s))
+	if err != nil {
+		t.Fatalf("create 10m before window: %v", err)
+	}
+	r.Close()
+
+	clock.Advance(6 * time.Minute)
+	if _, err := Room.Create("r2", "arena", Room.WithAdmission(s)); !errors.Is(err, ErrMatchesBlocked) {
+		t.Fatalf("create 4m before window = %v, want ErrMatchesBlocked", err)
+	}
+	clock.Advance(2 * time.Hour)
+	if _, err := Room.Create("r3", "arena", Room.WithAdmission(s)); err != nil {
+		t.Fatalf("create after window: %v", err)
+	}
+}
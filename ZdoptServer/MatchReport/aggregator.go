@@ -0,0 +1,208 @@
+package MatchReport
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"time"
+	"zdopt/ZdoptServer/Actor"
+	"zdopt/ZdoptServer/Export"
+	"zdopt/ZdoptServer/Logs"
+)
+
+var (
+	ErrNoStorage = errors.New("match report storage not set")
+
+	reportMetrics = expvar.NewMap("matchreport") // received / persisted / save_failed / dropped / exported / export_dropped
+
+	logger = Logs.CreateConsoleLogConfig("MatchReport")
+)
+
+// Config 聚合参数，零值字段使用默认值
+type Config struct {
+	Node            string        // 节点标识，写入报告
+	BatchSize       int           // 攒满该条数立即持久化
+	FlushInterval   time.Duration // 未攒满时的最长等待，同时是重试检查间隔
+	WriteTimeout    time.Duration // 单批写入超时
+	RetryBackoff    time.Duration // 首次重试等待，之后指数退避
+	MaxRetryBackoff time.Duration
+	MaxPending      int // 未持久化报告上限，超出时丢弃最旧的批次
+}
+
+// DefaultConfig 默认参数：每 50 条或 5 秒写一批，重试 1 秒起、最长 1 分钟，最多积压 10000 条
+func DefaultConfig() Config {
+	return Config{
+		BatchSize:       50,
+		FlushInterval:   5 * time.Second,
+		WriteTimeout:    10 * time.Second,
+		RetryBackoff:    time.Second,
+		MaxRetryBackoff: time.Minute,
+		MaxPending:      10000,
+	}
+}
+
+func (c Config) withDefaults() Config {
+	def := DefaultConfig()
+	if c.BatchSize <= 0 {
+		c.BatchSize = def.BatchSize
+	}
+	if c.FlushInterval <= 0 {
+		c.FlushInterval = def.FlushInterval
+	}
+	if c.WriteTimeout <= 0 {
+		c.WriteTimeout = def.WriteTimeout
+	}
+	if c.RetryBackoff <= 0 {
+		c.RetryBackoff = def.RetryBackoff
+	}
+	if c.MaxRetryBackoff < c.RetryBackoff {
+		c.MaxRetryBackoff = max(def.MaxRetryBackoff, c.RetryBackoff)
+	}
+	if c.MaxPending <= 0 {
+		c.MaxPending = def.MaxPending
+	}
+	return c
+}
+
+// flushTick 定时投递给聚合Actor的刷新信号
+type flushTick struct{}
+
+// Aggregator 对局报告聚合Actor：订阅事件总线上的 Topic，补全后按批持久化到 Storage，
+// 持久化成功后经 exporter（可为 nil）导出。写入失败的批次保留在内存中按退避重试，不阻塞后续对局
+type Aggregator struct {
+	*Actor.BaseActor
+	sys      *Actor.System
+	cfg      Config
+	storage  Storage
+	exporter *Export.Exporter
+
+	batch    []Report   // 正在攒的批次
+	unsaved  [][]Report // 等待写入（含失败待重试）的批次，按时间顺序
+	queued   int        // unsaved 中的报告条数
+	backoff  time.Duration
+	retryAt  time.Time
+	schedule *Actor.Schedule
+}
+
+// NewAggregator 创建聚合Actor并登记到系统，之后由调用方启动（如 AddGroupActors）
+func NewAggregator(sys *Actor.System, storage Storage, exporter *Export.Exporter, cfg Config) (*Aggregator, error) {
+	if storage == nil {
+		return nil, ErrNoStorage
+	}
+	a := &Aggregator{
+		BaseActor: sys.NewBaseActor(1024),
+		sys:       sys,
+		cfg:       cfg.withDefaults(),
+		storage:   storage,
+		exporter:  exporter,
+	}
+	if err := sys.Register(sys.NextID(), a); err != nil {
+		return nil, err
+	}
+	Actor.RegisterHandler(a, a.onMatchEnded)
+	Actor.RegisterHandler(a, func(flushTick) { a.flush(time.Now()) })
+	return a, nil
+}
+
+// PreStart 订阅对局结束事件并开始定时刷新
+func (a *Aggregator) PreStart(ctx context.Context) error {
+	if err := a.sys.EventBus().Subscribe(a.BaseActor, Topic); err != nil {
+		return err
+	}
+	a.schedule = a.sys.SendRepeatedly(a.cfg.FlushInterval, a.ID(), flushTick{})
+	return nil
+}
+
+// PostStop 停止定时刷新，对剩余报告做最后一次写入尝试
+func (a *Aggregator) PostStop() {
+	if a.schedule != nil {
+		a.schedule.Cancel()
+	}
+	a.retryAt = time.Time{}
+	a.flush(time.Now())
+	if a.queued > 0 {
+		reportMetrics.Add("dropped", int64(a.queued))
+		logger.Printf("stopped with %d unsaved reports", a.queued)
+	}
+	a.sys.Unregister(a.ID())
+}
+
+func (a *Aggregator) Start()                     {}
+func (a *Aggregator) Stop()                      {}
+func (a *Aggregator) Update(delta time.Duration) {}
+func (a *Aggregator) Receive(msg interface{})    {}
+
+// Submit 直接提交对局事件（不经事件总线）
+func (a *Aggregator) Submit(ev *MatchEnded) error {
+	return a.sys.Send(a.ID(), ev)
+}
+
+// Pending 尚未持久化的报告条数（在Actor协程外读取时仅供参考）
+func (a *Aggregator) Pending() int {
+	return len(a.batch) + a.queued
+}
+
+func (a *Aggregator) onMatchEnded(ev *MatchEnded) {
+	reportMetrics.Add("received", 1)
+	a.batch = append(a.batch, Enrich(ev, a.cfg.Node, time.Now()))
+	if len(a.batch) >= a.cfg.BatchSize {
+		a.flush(time.Now())
+	}
+}
+
+// flush 把当前批次排入待写队列，未处于退避期时按顺序写入
+func (a *Aggregator) flush(now time.Time) {
+	if len(a.batch) > 0 {
+		a.unsaved = append(a.unsaved, a.batch)
+		a.queued += len(a.batch)
+		a.batch = nil
+		a.trim()
+	}
+	if now.Before(a.retryAt) {
+		return
+	}
+	for len(a.unsaved) > 0 {
+		batch := a.unsaved[0]
+		ctx, cancel := context.WithTimeout(context.Background(), a.cfg.WriteTimeout)
+		err := a.storage.Save(ctx, batch)
+		cancel()
+		if err != nil {
+			reportMetrics.Add("save_failed", 1)
+			a.backoff = min(max(a.backoff*2, a.cfg.RetryBackoff), a.cfg.MaxRetryBackoff)
+			a.retryAt = now.Add(a.backoff)
+			logger.Printf("save %d reports failed, retry in %v (%d unsaved): %v", len(batch), a.backoff, a.queued, err)
+			return
+		}
+		a.unsaved[0] = nil
+		a.unsaved = a.unsaved[1:]
+		a.queued -= len(batch)
+		a.backoff, a.retryAt = 0, time.Time{}
+		reportMetrics.Add("persisted", int64(len(batch)))
+		a.export(batch)
+	}
+}
+
+// trim 积压超过上限时丢弃最旧的批次
+func (a *Aggregator) trim() {
+	for a.queued > a.cfg.MaxPending && len(a.unsaved) > 1 {
+		dropped := len(a.unsaved[0])
+		a.unsaved[0] = nil
+		a.unsaved = a.unsaved[1:]
+		a.queued -= dropped
+		reportMetrics.Add("dropped", int64(dropped))
+		logger.Printf("unsaved reports over limit %d, dropped oldest batch of %d", a.cfg.MaxPending, dropped)
+	}
+}
+
+func (a *Aggregator) export(batch []Report) {
+	if a.exporter == nil {
+		return
+	}
+	for i := range batch {
+		if err := a.exporter.Export(Topic, &batch[i]); err != nil {
+			reportMetrics.Add("export_dropped", 1)
+			continue
+		}
+		reportMetrics.Add("exported", 1)
+	}
+}
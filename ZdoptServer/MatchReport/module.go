@@ -0,0 +1,111 @@
+package MatchReport
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+	"zdopt/ZdoptServer/Actor"
+	"zdopt/ZdoptServer/Lifecycle"
+	"zdopt/ZdoptServer/Persist"
+	"zdopt/ZdoptServer/SelfTest"
+)
+
+// ModuleName 可选模块名，配置 modules 段出现该名称时启用
+const ModuleName = "matchreport"
+
+func init() {
+	Lifecycle.RegisterModule(ModuleName, func() Lifecycle.Module { return &module{} })
+}
+
+// moduleConfig 模块配置段，如 {"dir":"data/reports","group":90}
+type moduleConfig struct {
+	Dir           string `json:"dir"`
+	Node          string `json:"node,omitempty"`
+	Group         int    `json:"group,omitempty"` // 聚合Actor所在的组，默认 90
+	BatchSize     int    `json:"batch_size,omitempty"`
+	FlushInterval string `json:"flush_interval,omitempty"` // 如 "5s"
+	MaxPending    int    `json:"max_pending,omitempty"`
+}
+
+// module 以文件存储运行聚合Actor的可选模块
+type module struct {
+	cfg        moduleConfig
+	sys        *Actor.System
+	store      *Persist.Store
+	aggregator *Aggregator
+}
+
+func (m *module) Name() string { return ModuleName }
+
+func (m *module) Init(raw json.RawMessage, deps *Lifecycle.Deps) error {
+	mc, cfg, err := parseModuleConfig(raw)
+	if err != nil {
+		return err
+	}
+	m.cfg = mc
+	m.sys = deps.System
+	// 报告目录登记为持久化存储，参与服务器备份与恢复
+	store, err := Persist.RegisterStore(ModuleName, m.cfg.Dir)
+	if err != nil {
+		return err
+	}
+	a, err := NewAggregator(deps.System, NewStoreStorage(store, Persist.StoreOptions{}), nil, cfg)
+	if err != nil {
+		Persist.UnregisterStore(ModuleName)
+		return err
+	}
+	m.store, m.aggregator = store, a
+	return nil
+}
+
+// SelfTest 启动自检：校验配置段并检查报告目录可写
+func (m *module) SelfTest(raw json.RawMessage) error {
+	mc, _, err := parseModuleConfig(raw)
+	if err != nil {
+		return err
+	}
+	return SelfTest.CheckDir(mc.Dir, 0)
+}
+
+// parseModuleConfig 解析模块配置段，返回模块参数与聚合参数
+func parseModuleConfig(raw json.RawMessage) (moduleConfig, Config, error) {
+	mc := moduleConfig{Group: 90}
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &mc); err != nil {
+			return mc, Config{}, fmt.Errorf("matchreport config: %w", err)
+		}
+	}
+	if mc.Dir == "" {
+		return mc, Config{}, fmt.Errorf("%w: dir not configured", ErrNoStorage)
+	}
+	cfg := Config{Node: mc.Node, BatchSize: mc.BatchSize, MaxPending: mc.MaxPending}
+	if mc.FlushInterval != "" {
+		d, err := time.ParseDuration(mc.FlushInterval)
+		if err != nil {
+			return mc, Config{}, fmt.Errorf("matchreport config: flush_interval: %w", err)
+		}
+		cfg.FlushInterval = d
+	}
+	return mc, cfg, nil
+}
+
+func (m *module) Start(ctx context.Context) error {
+	m.sys.AddGroupActors(m.cfg.Group, []func() Actor.Actor{
+		func() Actor.Actor { return m.aggregator },
+	})
+	return nil
+}
+
+// Stop 停止聚合Actor，PostStop 中完成最后一次写入
+func (m *module) Stop(ctx context.Context) error {
+	m.sys.RemoveGroupActor(m.cfg.Group, m.aggregator.ID())
+	Persist.UnregisterStore(m.store.Name)
+	return nil
+}
+
+func (m *module) AdminRoutes() map[string]http.Handler { return nil }
+
+// Metrics 指标已发布在 expvar 的 matchreport 下
+func (m *module) Metrics() interface{} { return nil }
@@ -0,0 +1,103 @@
+package MatchReport
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+	"zdopt/ZdoptServer/Actor"
+	ServerConfig "zdopt/ZdoptServer/Config"
+	"zdopt/ZdoptServer/Lifecycle"
+	"zdopt/ZdoptServer/Persist"
+)
+
+func TestModuleStoreBackupAndRestore(t *testing.T) {
+	sys := Actor.NewSystem()
+	defer sys.Stop()
+	dir := filepath.Join(t.TempDir(), "reports")
+	raw, _ := json.Marshal(moduleConfig{Dir: dir, Group: 90})
+	srv := Lifecycle.NewServer(Lifecycle.NewManager(nil), Lifecycle.Deps{System: sys})
+	if err := srv.Load(map[string]json.RawMessage{ModuleName: raw}); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if err := srv.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	mod, _ := srv.Module(ModuleName)
+	storage := NewStoreStorage(mod.(*module).store, Persist.StoreOptions{})
+
+	now := time.Now()
+	if err := storage.Save(ctx, []Report{{MatchID: "m1", GeneratedAt: now}}); err != nil {
+		t.Fatal(err)
+	}
+	backup, err := srv.Backup(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := storage.Save(ctx, []Report{{MatchID: "m2", GeneratedAt: now.Add(time.Second)}}); err != nil {
+		t.Fatal(err)
+	}
+
+	// 恢复后只剩备份时写入的报告
+	skipped, err := srv.Restore(backup)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files, _ := filepath.Glob(filepath.Join(dir, "*", "*.json"))
+	if len(files) != 1 || len(skipped) != 0 {
+		t.Fatalf("files after restore = %v, skipped %v", files, skipped)
+	}
+	var got []Report
+	data, err := Persist.ReadFile(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(data, &got); err != nil || len(got) != 1 || got[0].MatchID != "m1" {
+		t.Fatalf("restored reports = %+v, %v", got, err)
+	}
+
+	if err := srv.Stop(ctx); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range Persist.Stores() {
+		if s.Name == ModuleName {
+			t.Fatal("store still registered after stop")
+		}
+	}
+}
+
+func TestServerSelfTestReportsPerModule(t *testing.T) {
+	cfg := ServerConfig.Default()
+	cfg.Port = 0 // 非法端口只应使配置项失败
+	cfg.Modules = map[string]json.RawMessage{
+		ModuleName: json.RawMessage(`{"flush_interval":"5s"}`),
+		"missing":  nil,
+	}
+	srv := Lifecycle.NewServer(Lifecycle.NewManager(nil), Lifecycle.Deps{Config: cfg})
+	report := srv.SelfTest()
+
+	results := make(map[string]error)
+	for _, r := range report.Results {
+		results[r.Name] = r.Err
+	}
+	if err := results["server-config"]; err == nil {
+		t.Fatal("server-config passed with port 0")
+	}
+	if err, ok := results["module/"+ModuleName]; !ok || !errors.Is(err, ErrNoStorage) {
+		t.Fatalf("module/%s = %v, want ErrNoStorage", ModuleName, err)
+	}
+	if err := results["module/missing"]; !errors.Is(err, Lifecycle.ErrUnknownModule) {
+		t.Fatalf("module/missing = %v, want ErrUnknownModule", err)
+	}
+
+	// 配置修正后模块项通过
+	cfg.Modules = map[string]json.RawMessage{ModuleName: json.RawMessage(`{"dir":"` + filepath.Join(t.TempDir(), "reports") + `"}`)}
+	for _, r := range srv.SelfTest().Results {
+		if r.Name == "module/"+ModuleName && r.Err != nil {
+			t.Fatalf("module/%s = %v", ModuleName, r.Err)
+		}
+	}
+}
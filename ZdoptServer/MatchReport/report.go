@@ -0,0 +1,116 @@
+package MatchReport
+
+import (
+	"cmp"
+	"slices"
+	"time"
+)
+
+// Topic 房间Actor在事件总线上发布对局结束事件的主题
+const Topic = "match.ended"
+
+// MatchEnded 房间Actor在对局结束时发布的结构化事件
+type MatchEnded struct {
+	MatchID   string
+	RoomID    string
+	Mode      string
+	StartedAt time.Time
+	EndedAt   time.Time
+	Winner    string // 获胜队伍或玩家，平局为空
+	Players   []PlayerResult
+}
+
+// PlayerResult 单个玩家的对局结果
+type PlayerResult struct {
+	PlayerID int64              `json:"player_id"`
+	Team     string             `json:"team,omitempty"`
+	Score    int64              `json:"score"`
+	Kills    int                `json:"kills"`
+	Deaths   int                `json:"deaths"`
+	Assists  int                `json:"assists"`
+	JoinedAt time.Time          `json:"joined_at"`           // 零值表示开局时加入
+	LeftAt   time.Time          `json:"left_at"`             // 零值表示打完全场
+	Stats    map[string]float64 `json:"stats,omitempty"`     // 玩法自定义统计
+	Abandons bool               `json:"abandoned,omitempty"` // 中途退出
+}
+
+// Report 补全后的对局报告：持久化与导出的单位
+type Report struct {
+	MatchID     string         `json:"match_id"`
+	RoomID      string         `json:"room_id"`
+	Mode        string         `json:"mode,omitempty"`
+	Node        string         `json:"node,omitempty"`
+	StartedAt   time.Time      `json:"started_at"`
+	EndedAt     time.Time      `json:"ended_at"`
+	Duration    time.Duration  `json:"duration_ns"`
+	Winner      string         `json:"winner,omitempty"`
+	Players     []PlayerReport `json:"players"`
+	TotalKills  int            `json:"total_kills"`
+	GeneratedAt time.Time      `json:"generated_at"`
+}
+
+// PlayerReport 补全后的玩家统计
+type PlayerReport struct {
+	PlayerResult
+	PlayTime       time.Duration `json:"play_time_ns"`
+	Rank           int           `json:"rank"` // 按得分排名，从 1 开始，同分同名次
+	KDA            float64       `json:"kda"`  // (击杀 + 助攻) / max(死亡, 1)
+	ScorePerMinute float64       `json:"score_per_minute"`
+}
+
+// Enrich 由对局事件生成报告：计算对局时长、玩家在场时长、排名与 KDA
+func Enrich(ev *MatchEnded, node string, now time.Time) Report {
+	r := Report{
+		MatchID:     ev.MatchID,
+		RoomID:      ev.RoomID,
+		Mode:        ev.Mode,
+		Node:        node,
+		StartedAt:   ev.StartedAt,
+		EndedAt:     ev.EndedAt,
+		Winner:      ev.Winner,
+		Players:     make([]PlayerReport, len(ev.Players)),
+		GeneratedAt: now,
+	}
+	if r.EndedAt.IsZero() {
+		r.EndedAt = now
+	}
+	if !r.StartedAt.IsZero() && r.EndedAt.After(r.StartedAt) {
+		r.Duration = r.EndedAt.Sub(r.StartedAt)
+	}
+
+	for i, p := range ev.Players {
+		pr := PlayerReport{PlayerResult: p}
+		joined, left := p.JoinedAt, p.LeftAt
+		if joined.IsZero() || joined.Before(r.StartedAt) {
+			joined = r.StartedAt
+		}
+		if left.IsZero() || left.After(r.EndedAt) {
+			left = r.EndedAt
+		}
+		if !joined.IsZero() && left.After(joined) {
+			pr.PlayTime = left.Sub(joined)
+		}
+		pr.KDA = float64(p.Kills+p.Assists) / float64(max(p.Deaths, 1))
+		if minutes := pr.PlayTime.Minutes(); minutes > 0 {
+			pr.ScorePerMinute = float64(p.Score) / minutes
+		}
+		r.TotalKills += p.Kills
+		r.Players[i] = pr
+	}
+
+	byScore := make([]int, len(r.Players))
+	for i := range byScore {
+		byScore[i] = i
+	}
+	slices.SortStableFunc(byScore, func(a, b int) int {
+		return cmp.Compare(r.Players[b].Score, r.Players[a].Score)
+	})
+	for n, i := range byScore {
+		if n > 0 && r.Players[i].Score == r.Players[byScore[n-1]].Score {
+			r.Players[i].Rank = r.Players[byScore[n-1]].Rank
+		} else {
+			r.Players[i].Rank = n + 1
+		}
+	}
+	return r
+}
@@ -0,0 +1,52 @@
+package MatchReport
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"zdopt/ZdoptServer/Persist"
+)
+
+// Storage 报告持久化，Save 失败时由 Aggregator 保留该批并退避重试；实现需保证同一批重复写入是幂等的
+type Storage interface {
+	Save(ctx context.Context, batch []Report) error
+}
+
+// FileStorage 以持久化容器文件保存报告：<Dir>/<日期>/<首条生成时间>-<条数>.json，同一批重试时覆盖同一文件
+type FileStorage struct {
+	Dir     string
+	Options Persist.StoreOptions
+	// Store 非 nil 时写入经其存储锁执行，与备份互斥
+	Store *Persist.Store
+}
+
+// NewFileStorage 创建文件报告存储
+func NewFileStorage(dir string, opts Persist.StoreOptions) *FileStorage {
+	return &FileStorage{Dir: dir, Options: opts}
+}
+
+// NewStoreStorage 在已登记的持久化存储目录下创建报告存储，参与备份与恢复
+func NewStoreStorage(store *Persist.Store, opts Persist.StoreOptions) *FileStorage {
+	return &FileStorage{Dir: store.Dir, Options: opts, Store: store}
+}
+
+func (s *FileStorage) Save(ctx context.Context, batch []Report) error {
+	if len(batch) == 0 {
+		return nil
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	data, err := json.Marshal(batch)
+	if err != nil {
+		return err
+	}
+	first := batch[0].GeneratedAt
+	name := fmt.Sprintf("%020d-%d.json", first.UnixNano(), len(batch))
+	path := filepath.Join(s.Dir, first.Format("20060102"), name)
+	if s.Store == nil {
+		return Persist.WriteFile(path, data, s.Options)
+	}
+	return s.Store.Write(func() error { return Persist.WriteFile(path, data, s.Options) })
+}
@@ -0,0 +1,59 @@
+package Metrics
+
+import (
+	"encoding/json"
+	"expvar"
+	"net/http"
+	"time"
+)
+
+// Series 查询结果中的一条时序
+type Series struct {
+	Name    string   `json:"name"`
+	Samples []Sample `json:"samples"`
+}
+
+// Handler 时序查询接口：GET ?name=a&name=b&window=5m，不带 name 返回全部指标
+func (s *Store) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+
+		var window time.Duration
+		if raw := q.Get("window"); raw != "" {
+			d, err := time.ParseDuration(raw)
+			if err != nil {
+				http.Error(w, "invalid window: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+			window = d
+		}
+
+		names := q["name"]
+		if len(names) == 0 {
+			names = s.Names()
+		}
+		out := make([]Series, 0, len(names))
+		for _, name := range names {
+			samples, ok := s.Query(name, window)
+			if !ok {
+				http.Error(w, "unknown metric: "+name, http.StatusNotFound)
+				return
+			}
+			out = append(out, Series{Name: name, Samples: samples})
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(out)
+	})
+}
+
+// AdminMux 管理端点：/debug/vars（expvar）、/debug/latency 与 /debug/timeseries
+func AdminMux(store *Store) *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.Handle("/debug/vars", expvar.Handler())
+	mux.Handle("/debug/latency", DefaultLatency.Handler())
+	if store != nil {
+		mux.Handle("/debug/timeseries", store.Handler())
+	}
+	return mux
+}
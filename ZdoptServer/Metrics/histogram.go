@@ -0,0 +1,118 @@
+package Metrics
+
+import (
+	"math"
+	"sort"
+	"sync/atomic"
+	"time"
+)
+
+// 桶边界：10µs 起按 1.2 倍递增至 60s 以上，相对误差约 10%
+var histBounds = func() []time.Duration {
+	var bounds []time.Duration
+	for b := float64(10 * time.Microsecond); b < float64(60*time.Second); b *= 1.2 {
+		bounds = append(bounds, time.Duration(b))
+	}
+	return append(bounds, time.Duration(math.MaxInt64))
+}()
+
+// Histogram 固定指数桶的无锁耗时直方图
+type Histogram struct {
+	counts []atomic.Uint64
+	count  atomic.Uint64
+	sum    atomic.Int64
+	max    atomic.Int64
+}
+
+// NewHistogram 创建直方图
+func NewHistogram() *Histogram {
+	return &Histogram{counts: make([]atomic.Uint64, len(histBounds))}
+}
+
+// Observe 记录一次耗时
+func (h *Histogram) Observe(d time.Duration) {
+	if d < 0 {
+		d = 0
+	}
+	i := sort.Search(len(histBounds), func(i int) bool { return histBounds[i] >= d })
+	h.counts[i].Add(1)
+	h.count.Add(1)
+	h.sum.Add(int64(d))
+	for {
+		m := h.max.Load()
+		if int64(d) <= m || h.max.CompareAndSwap(m, int64(d)) {
+			return
+		}
+	}
+}
+
+// Snapshot 当前计数快照
+func (h *Histogram) Snapshot() HistogramSnapshot {
+	s := HistogramSnapshot{
+		Count:   h.count.Load(),
+		Sum:     time.Duration(h.sum.Load()),
+		Max:     time.Duration(h.max.Load()),
+		Buckets: make([]uint64, len(h.counts)),
+	}
+	for i := range h.counts {
+		s.Buckets[i] = h.counts[i].Load()
+	}
+	return s
+}
+
+// HistogramSnapshot 直方图快照，可合并
+type HistogramSnapshot struct {
+	Count   uint64
+	Sum     time.Duration
+	Max     time.Duration
+	Buckets []uint64
+}
+
+// Merge 合并另一个快照
+func (s *HistogramSnapshot) Merge(o HistogramSnapshot) {
+	if s.Buckets == nil {
+		s.Buckets = make([]uint64, len(histBounds))
+	}
+	s.Count += o.Count
+	s.Sum += o.Sum
+	s.Max = max(s.Max, o.Max)
+	for i, c := range o.Buckets {
+		s.Buckets[i] += c
+	}
+}
+
+// Mean 平均耗时
+func (s HistogramSnapshot) Mean() time.Duration {
+	if s.Count == 0 {
+		return 0
+	}
+	return s.Sum / time.Duration(s.Count)
+}
+
+// Quantile 估算分位数（q 取 0~1），在桶内线性插值，不超过观测到的最大值
+func (s HistogramSnapshot) Quantile(q float64) time.Duration {
+	if s.Count == 0 {
+		return 0
+	}
+	rank := q * float64(s.Count)
+	var seen float64
+	for i, c := range s.Buckets {
+		if c == 0 {
+			continue
+		}
+		if seen+float64(c) >= rank {
+			var lo time.Duration
+			if i > 0 {
+				lo = histBounds[i-1]
+			}
+			hi := min(histBounds[i], s.Max)
+			if hi < lo {
+				return hi
+			}
+			frac := (rank - seen) / float64(c)
+			return lo + time.Duration(frac*float64(hi-lo))
+		}
+		seen += float64(c)
+	}
+	return s.Max
+}
@@ -0,0 +1,143 @@
+package Metrics
+
+import (
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"zdopt/ZdoptServer/Intern"
+)
+
+// DefaultLatency 进程级请求延迟记录（解码开始到处理器完成），以 net.latency 发布到 expvar
+var DefaultLatency = NewLatency()
+
+func init() {
+	expvar.Publish("net.latency", expvar.Func(func() interface{} {
+		return DefaultLatency.Summaries(true)
+	}))
+}
+
+type latencyKey struct {
+	transport string
+	msgType   string
+}
+
+// Latency 按传输层与消息类型分组的延迟直方图
+type Latency struct {
+	hists sync.Map // latencyKey -> *Histogram
+}
+
+// NewLatency 创建延迟记录
+func NewLatency() *Latency {
+	return &Latency{}
+}
+
+// Observe 记录一次请求延迟
+func (l *Latency) Observe(transport, msgType string, d time.Duration) {
+	key := latencyKey{transport: transport, msgType: msgType}
+	h, ok := l.hists.Load(key)
+	if !ok {
+		// 新键驻留后保存，避免调用方临时拼接的字符串长期占用
+		key = latencyKey{transport: Intern.String(transport), msgType: Intern.String(msgType)}
+		h, _ = l.hists.LoadOrStore(key, NewHistogram())
+	}
+	h.(*Histogram).Observe(d)
+}
+
+// Since 记录从 start 到现在的延迟
+func (l *Latency) Since(transport, msgType string, start time.Time) {
+	l.Observe(transport, msgType, time.Since(start))
+}
+
+// LatencySummary 一组延迟的分位数摘要
+type LatencySummary struct {
+	Transport string        `json:"transport"`
+	Type      string        `json:"type,omitempty"` // 按传输层汇总时为空
+	Count     uint64        `json:"count"`
+	Mean      time.Duration `json:"mean"`
+	P50       time.Duration `json:"p50"`
+	P90       time.Duration `json:"p90"`
+	P99       time.Duration `json:"p99"`
+	Max       time.Duration `json:"max"`
+}
+
+// Summaries 延迟摘要，byType 为 false 时按传输层汇总所有消息类型
+func (l *Latency) Summaries(byType bool) []LatencySummary {
+	groups := make(map[latencyKey]*HistogramSnapshot)
+	l.hists.Range(func(k, v interface{}) bool {
+		key := k.(latencyKey)
+		if !byType {
+			key.msgType = ""
+		}
+		s, ok := groups[key]
+		if !ok {
+			s = &HistogramSnapshot{}
+			groups[key] = s
+		}
+		s.Merge(v.(*Histogram).Snapshot())
+		return true
+	})
+
+	out := make([]LatencySummary, 0, len(groups))
+	for key, s := range groups {
+		out = append(out, LatencySummary{
+			Transport: key.transport,
+			Type:      key.msgType,
+			Count:     s.Count,
+			Mean:      s.Mean(),
+			P50:       s.Quantile(0.50),
+			P90:       s.Quantile(0.90),
+			P99:       s.Quantile(0.99),
+			Max:       s.Max,
+		})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Transport != out[j].Transport {
+			return out[i].Transport < out[j].Transport
+		}
+		return out[i].Type < out[j].Type
+	})
+	return out
+}
+
+// Handler 延迟报告：GET ?by=type 按消息类型细分，?format=text 输出对比表格
+func (l *Latency) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		rows := l.Summaries(q.Get("by") == "type")
+		if q.Get("format") != "text" {
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(rows)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "TRANSPORT\tTYPE\tCOUNT\tMEAN\tP50\tP90\tP99\tMAX\t")
+		for _, s := range rows {
+			typ := s.Type
+			if typ == "" {
+				typ = "*"
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%d\t%v\t%v\t%v\t%v\t%v\t\n", s.Transport, typ, s.Count,
+				round(s.Mean), round(s.P50), round(s.P90), round(s.P99), round(s.Max))
+		}
+		_ = tw.Flush()
+	})
+}
+
+func round(d time.Duration) time.Duration {
+	switch {
+	case d >= time.Second:
+		return d.Round(time.Millisecond)
+	case d >= time.Millisecond:
+		return d.Round(10 * time.Microsecond)
+	default:
+		return d.Round(time.Microsecond)
+	}
+}
@@ -0,0 +1,199 @@
+package Metrics
+
+import (
+	"context"
+	"expvar"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Sample 一个采样点
+type Sample struct {
+	Time  time.Time `json:"t"`
+	Value float64   `json:"v"`
+}
+
+// Source 指标取值函数
+type Source func() float64
+
+// series 定长环形缓冲，保存最近 capacity 个采样点
+type series struct {
+	source  Source
+	samples []Sample
+	next    int
+	full    bool
+}
+
+func (s *series) add(sample Sample) {
+	s.samples[s.next] = sample
+	s.next = (s.next + 1) % len(s.samples)
+	if s.next == 0 {
+		s.full = true
+	}
+}
+
+// since 按时间顺序返回不早于 from 的采样点
+func (s *series) since(from time.Time) []Sample {
+	var ordered []Sample
+	if s.full {
+		ordered = append(ordered, s.samples[s.next:]...)
+	}
+	ordered = append(ordered, s.samples[:s.next]...)
+
+	i := sort.Search(len(ordered), func(i int) bool { return !ordered[i].Time.Before(from) })
+	return ordered[i:]
+}
+
+// Store 节点本地时序缓冲：定期采样关键指标，保留最近一段时间，无需外部监控设施
+type Store struct {
+	mu       sync.RWMutex
+	interval time.Duration
+	capacity int
+	series   map[string]*series
+}
+
+// NewStore 创建时序缓冲，保留 retention 时长、每 interval 采样一次
+func NewStore(interval, retention time.Duration) *Store {
+	if interval <= 0 {
+		interval = time.Second
+	}
+	capacity := int(retention / interval)
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &Store{
+		interval: interval,
+		capacity: capacity,
+		series:   make(map[string]*series),
+	}
+}
+
+// NewDefaultStore 每秒采样、保留 10 分钟，预置运行时与已注册的 expvar 指标
+func NewDefaultStore() *Store {
+	s := NewStore(time.Second, 10*time.Minute)
+	s.TrackRuntime()
+	s.TrackExpvars()
+	return s
+}
+
+// Interval 采样间隔
+func (s *Store) Interval() time.Duration {
+	return s.interval
+}
+
+// Track 注册指标，同名指标会被替换
+func (s *Store) Track(name string, source Source) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.series[name] = &series{source: source, samples: make([]Sample, s.capacity)}
+}
+
+// TrackExpvar 以 expvar 变量作为指标来源，Map 类型取各项之和
+func (s *Store) TrackExpvar(name string) bool {
+	v := expvar.Get(name)
+	if v == nil {
+		return false
+	}
+	if _, ok := numeric(v); !ok {
+		return false
+	}
+	s.Track(name, func() float64 {
+		f, _ := numeric(v)
+		return f
+	})
+	return true
+}
+
+// TrackExpvars 注册当前所有数值型 expvar 变量（cmdline、memstats 除外）
+func (s *Store) TrackExpvars() {
+	expvar.Do(func(kv expvar.KeyValue) {
+		if kv.Key == "cmdline" || kv.Key == "memstats" {
+			return
+		}
+		s.TrackExpvar(kv.Key)
+	})
+}
+
+// TrackRuntime 注册 goroutine 数与堆内存指标
+func (s *Store) TrackRuntime() {
+	s.Track("runtime.goroutines", func() float64 { return float64(runtime.NumGoroutine()) })
+	s.Track("runtime.heap_alloc", func() float64 {
+		var m runtime.MemStats
+		runtime.ReadMemStats(&m)
+		return float64(m.HeapAlloc)
+	})
+}
+
+// Names 已注册的指标名（有序）
+func (s *Store) Names() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	names := make([]string, 0, len(s.series))
+	for name := range s.series {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Query 返回指标最近 window 内的采样点，window <= 0 表示全部
+func (s *Store) Query(name string, window time.Duration) ([]Sample, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	sr, ok := s.series[name]
+	if !ok {
+		return nil, false
+	}
+	var from time.Time
+	if window > 0 {
+		from = time.Now().Add(-window)
+	}
+	return sr.since(from), true
+}
+
+// Sample 立即对所有指标采样一次
+func (s *Store) Sample(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, sr := range s.series {
+		sr.add(Sample{Time: now, Value: sr.source()})
+	}
+}
+
+// Start 按采样间隔持续采样，直到 ctx 结束
+func (s *Store) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case now := <-ticker.C:
+				s.Sample(now)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// numeric 读取 expvar 数值，Map 取所有数值项之和
+func numeric(v expvar.Var) (float64, bool) {
+	switch x := v.(type) {
+	case *expvar.Int:
+		return float64(x.Value()), true
+	case *expvar.Float:
+		return x.Value(), true
+	case *expvar.Map:
+		var sum float64
+		x.Do(func(kv expvar.KeyValue) {
+			if f, ok := numeric(kv.Value); ok {
+				sum += f
+			}
+		})
+		return sum, true
+	default:
+		return 0, false
+	}
+}
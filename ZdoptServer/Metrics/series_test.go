@@ -0,0 +1,56 @@
+package Metrics
+
+import (
+	"expvar"
+	"testing"
+	"time"
+)
+
+func TestStoreKeepsMostRecentSamples(t *testing.T) {
+	s := NewStore(time.Second, 3*time.Second)
+	v := 0.0
+	s.Track("counter", func() float64 { v++; return v })
+
+	start := time.Now().Add(-time.Minute)
+	for i := 0; i < 5; i++ {
+		s.Sample(start.Add(time.Duration(i) * time.Second))
+	}
+	samples, ok := s.Query("counter", 0)
+	if !ok {
+		t.Fatal("series not tracked")
+	}
+	if got := Values(samples); len(got) != 3 || got[0] != 3 || got[2] != 5 {
+		t.Fatalf("retained values = %v, want [3 4 5]", got)
+	}
+	for i := 1; i < len(samples); i++ {
+		if !samples[i].Time.After(samples[i-1].Time) {
+			t.Fatal("samples not in time order")
+		}
+	}
+	if recent, _ := s.Query("counter", time.Second); len(recent) != 0 {
+		t.Fatalf("%d samples within the last second, want 0", len(recent))
+	}
+	if _, ok := s.Query("missing", 0); ok {
+		t.Fatal("unknown series reported as tracked")
+	}
+}
+
+func TestStoreTracksExpvars(t *testing.T) {
+	m := expvar.NewMap("metrics.test.map")
+	m.Add("a", 2)
+	m.Add("b", 3)
+	expvar.NewString("metrics.test.string").Set("x")
+
+	s := NewStore(time.Second, time.Minute)
+	if !s.TrackExpvar("metrics.test.map") {
+		t.Fatal("numeric map not tracked")
+	}
+	if s.TrackExpvar("metrics.test.string") || s.TrackExpvar("metrics.test.missing") {
+		t.Fatal("non-numeric or missing expvar tracked")
+	}
+	s.Sample(time.Now())
+	samples, _ := s.Query("metrics.test.map", 0)
+	if got := Values(samples); len(got) != 1 || got[0] != 5 {
+		t.Fatalf("map sum = %v, want [5]", got)
+	}
+}
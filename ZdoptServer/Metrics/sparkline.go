@@ -0,0 +1,80 @@
+package Metrics
+
+import (
+	"math"
+	"strings"
+)
+
+var sparkTicks = []rune("▁▂▃▄▅▆▇█")
+
+// sparkGap 无法绘制的采样点（NaN、±Inf）占位
+const sparkGap = ' '
+
+// Sparkline 将数值序列渲染为 Unicode 方块字符（▁ 到 █）组成的迷你折线，超过 width 时按桶取均值压缩（width <= 0 不压缩）。
+// NaN 与 ±Inf 不参与取值范围，渲染为空格
+func Sparkline(values []float64, width int) string {
+	values = downsample(values, width)
+	if len(values) == 0 {
+		return ""
+	}
+
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, v := range values {
+		if finite(v) {
+			lo = math.Min(lo, v)
+			hi = math.Max(hi, v)
+		}
+	}
+
+	var b strings.Builder
+	for _, v := range values {
+		if !finite(v) {
+			b.WriteRune(sparkGap)
+			continue
+		}
+		idx := 0
+		if hi > lo {
+			idx = int((v - lo) / (hi - lo) * float64(len(sparkTicks)-1))
+		}
+		b.WriteRune(sparkTicks[min(max(idx, 0), len(sparkTicks)-1)])
+	}
+	return b.String()
+}
+
+func finite(v float64) bool {
+	return !math.IsNaN(v) && !math.IsInf(v, 0)
+}
+
+// Values 提取采样值
+func Values(samples []Sample) []float64 {
+	values := make([]float64, len(samples))
+	for i, s := range samples {
+		values[i] = s.Value
+	}
+	return values
+}
+
+func downsample(values []float64, width int) []float64 {
+	if width <= 0 || len(values) <= width {
+		return values
+	}
+	out := make([]float64, width)
+	for i := range out {
+		start := i * len(values) / width
+		end := (i + 1) * len(values) / width
+		// 桶内只对有限值取均值，全部无效时整桶为 NaN
+		var sum float64
+		n := 0
+		for _, v := range values[start:end] {
+			if finite(v) {
+				sum += v
+				n++
+			}
+		}
+		out[i] = math.NaN()
+		if n > 0 {
+			out[i] = sum / float64(n)
+		}
+	}
+	return out
+}
@@ -0,0 +1,42 @@
+package Metrics
+
+import (
+	"math"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestSparklineScalesToBlocks(t *testing.T) {
+	if got := Sparkline([]float64{0, 7, 3.5, 7}, 0); got != "▁█▄█" {
+		t.Fatalf("Sparkline = %q", got)
+	}
+	if got := Sparkline([]float64{5, 5, 5}, 0); got != "▁▁▁" {
+		t.Fatalf("flat series = %q", got)
+	}
+	if got := Sparkline(nil, 10); got != "" {
+		t.Fatalf("empty series = %q", got)
+	}
+}
+
+func TestSparklineSkipsNonFiniteSamples(t *testing.T) {
+	values := []float64{0, math.NaN(), 7, math.Inf(1), math.Inf(-1), 7}
+	if got := Sparkline(values, 0); got != "▁ █  █" {
+		t.Fatalf("Sparkline = %q", got)
+	}
+	// 全部无效
+	if got := Sparkline([]float64{math.NaN(), math.Inf(1)}, 0); got != "  " {
+		t.Fatalf("all non-finite = %q", got)
+	}
+}
+
+func TestSparklineDownsamples(t *testing.T) {
+	values := []float64{0, 0, 7, 7, math.NaN(), math.NaN(), 7, math.Inf(1)}
+	got := Sparkline(values, 4)
+	if utf8.RuneCountInString(got) != 4 {
+		t.Fatalf("width = %d, want 4", utf8.RuneCountInString(got))
+	}
+	// 最后一桶忽略 +Inf 取 7；整桶无效时为空格
+	if got != "▁█ █" {
+		t.Fatalf("downsampled = %q", got)
+	}
+}
@@ -0,0 +1,229 @@
+package Net
+
+import (
+	"expvar"
+	"sync"
+	"time"
+	"zdopt/ZdoptServer/StateSync"
+)
+
+var (
+	bandwidthTiers       = expvar.NewMap("net.bandwidth.tiers") // 各质量档位当前的会话数
+	bandwidthTransitions = expvar.NewInt("net.bandwidth.transitions")
+)
+
+// BandwidthConfig 带宽估计参数
+type BandwidthConfig struct {
+	Window       time.Duration // 采样窗口：窗口内确认的字节数构成一次投递速率样本
+	ReducedBelow float64       // 估计带宽低于该值（字节/秒）时降为 TierReduced
+	MinimalBelow float64       // 低于该值时降为 TierMinimal
+	Hysteresis   float64       // 升档需超过阈值的倍数，避免在阈值附近来回切换
+}
+
+// DefaultBandwidthConfig 默认参数：500ms 窗口，低于 64KB/s 降级、低于 16KB/s 最低档，升档需超出 25%
+func DefaultBandwidthConfig() BandwidthConfig {
+	return BandwidthConfig{
+		Window:       500 * time.Millisecond,
+		ReducedBelow: 64 << 10,
+		MinimalBelow: 16 << 10,
+		Hysteresis:   1.25,
+	}
+}
+
+// BandwidthStats 带宽估计快照
+type BandwidthStats struct {
+	Estimate float64 // 估计可用带宽（字节/秒），尚无样本时为 0
+	Delivery float64 // 平滑后的投递速率
+	Drain    float64 // 最近一次测得的发送队列排空速率
+	Queued   int     // 发送队列积压字节数
+	Tier     StateSync.QualityTier
+}
+
+// BandwidthEstimator 单个会话的可用带宽估计：以确认时序得到投递速率，
+// 发送队列持续积压时以队列排空速率为上限，并据此给出 StateSync 的质量档位
+type BandwidthEstimator struct {
+	mu  sync.Mutex
+	cfg BandwidthConfig
+
+	windowStart time.Time
+	acked       int   // 当前窗口内确认的字节数
+	sent        int64 // 上次测量队列后交给传输层的字节数
+	queued      int
+	queuedAt    time.Time
+	growing     int // 队列连续增长的测量次数
+
+	delivery float64
+	drain    float64
+	estimate float64
+	tier     StateSync.QualityTier
+	closed   bool
+
+	unacked []int  // 已发送未确认的各条消息字节数，按序号排列
+	first   uint32 // unacked[0] 的序号
+}
+
+// maxUnacked 未确认消息记录上限，对端长时间不确认时丢弃最早的记录
+const maxUnacked = 4096
+
+// NewBandwidthEstimator 创建会话带宽估计，未设置的参数使用默认值
+func NewBandwidthEstimator(cfg BandwidthConfig) *BandwidthEstimator {
+	def := DefaultBandwidthConfig()
+	if cfg.Window <= 0 {
+		cfg.Window = def.Window
+	}
+	if cfg.ReducedBelow <= 0 {
+		cfg.ReducedBelow = def.ReducedBelow
+	}
+	if cfg.MinimalBelow <= 0 || cfg.MinimalBelow > cfg.ReducedBelow {
+		cfg.MinimalBelow = min(def.MinimalBelow, cfg.ReducedBelow)
+	}
+	if cfg.Hysteresis < 1 {
+		cfg.Hysteresis = def.Hysteresis
+	}
+	bandwidthTiers.Add(StateSync.TierFull.String(), 1)
+	return &BandwidthEstimator{cfg: cfg, tier: StateSync.TierFull, first: 1}
+}
+
+// SentMessage 按发送顺序记录一条应用消息的字节数（序号从 1 起，与对端确认帧的序号一致）
+func (b *BandwidthEstimator) SentMessage(n int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(b.unacked) >= maxUnacked {
+		b.unacked = b.unacked[1:]
+		b.first++
+	}
+	b.unacked = append(b.unacked, n)
+}
+
+// AckedThrough 对端确认已按序收到序号不大于 seq 的消息（见 AckMessageID），新确认的字节数计入 Acked；
+// 没有新确认的重复确认同样推进采样窗口
+func (b *BandwidthEstimator) AckedThrough(seq uint32, now time.Time) {
+	b.mu.Lock()
+	n := min(max(int(int64(seq)-int64(b.first)+1), 0), len(b.unacked))
+	bytes := 0
+	for _, size := range b.unacked[:n] {
+		bytes += size
+	}
+	b.unacked = b.unacked[n:]
+	b.first += uint32(n)
+	b.mu.Unlock()
+	b.Acked(bytes, now)
+}
+
+// Sent 记录交给传输层的字节数
+func (b *BandwidthEstimator) Sent(n int) {
+	b.mu.Lock()
+	b.sent += int64(n)
+	b.mu.Unlock()
+}
+
+// Acked 记录对端确认的字节数（如 KCP 确认推进的段数 × MSS），窗口结束时产生一次投递速率样本
+func (b *BandwidthEstimator) Acked(n int, now time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.windowStart.IsZero() || now.Sub(b.windowStart) > 4*b.cfg.Window {
+		// 长时间没有确认说明发送方空闲而不是链路受限，重新开始窗口，不产生低速样本
+		b.windowStart, b.acked = now, 0
+	}
+	b.acked += n
+	elapsed := now.Sub(b.windowStart)
+	if elapsed < b.cfg.Window {
+		return
+	}
+	b.delivery = ewma(b.delivery, float64(b.acked)/elapsed.Seconds())
+	b.acked, b.windowStart = 0, now
+	b.update()
+}
+
+// Queue 记录当前发送队列积压字节数（如 KCP WaitSnd × MSS），由两次测量之间的发送量计算排空速率
+func (b *BandwidthEstimator) Queue(bytes int, now time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.queuedAt.IsZero() {
+		if elapsed := now.Sub(b.queuedAt).Seconds(); elapsed > 0 {
+			drained := float64(b.queued) + float64(b.sent) - float64(bytes)
+			b.drain = max(drained, 0) / elapsed
+		}
+		if bytes > b.queued {
+			b.growing++
+		} else {
+			b.growing = 0
+		}
+	}
+	b.queued, b.queuedAt, b.sent = bytes, now, 0
+	b.update()
+}
+
+// QualityTier 当前质量档位，实现 StateSync.QualitySource
+func (b *BandwidthEstimator) QualityTier() StateSync.QualityTier {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.tier
+}
+
+// Stats 返回估计快照
+func (b *BandwidthEstimator) Stats() BandwidthStats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return BandwidthStats{
+		Estimate: b.estimate,
+		Delivery: b.delivery,
+		Drain:    b.drain,
+		Queued:   b.queued,
+		Tier:     b.tier,
+	}
+}
+
+// Close 会话结束时调用，从档位统计中移除
+func (b *BandwidthEstimator) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.closed {
+		b.closed = true
+		bandwidthTiers.Add(b.tier.String(), -1)
+	}
+}
+
+// update 重新计算估计带宽与档位（调用方持有锁）
+func (b *BandwidthEstimator) update() {
+	b.estimate = b.delivery
+	if b.growing >= 3 && b.drain > 0 && (b.estimate == 0 || b.drain < b.estimate) {
+		// 队列持续积压说明发送速率超过链路容量，实际可用带宽不高于排空速率
+		b.estimate = b.drain
+	}
+	if b.estimate == 0 {
+		return // 尚无样本，保持当前档位
+	}
+
+	var next StateSync.QualityTier
+	switch {
+	case b.estimate < b.cfg.MinimalBelow:
+		next = StateSync.TierMinimal
+	case b.estimate < b.cfg.ReducedBelow:
+		next = StateSync.TierReduced
+		if b.tier == StateSync.TierMinimal && b.estimate < b.cfg.MinimalBelow*b.cfg.Hysteresis {
+			next = StateSync.TierMinimal
+		}
+	default:
+		next = StateSync.TierFull
+		if b.tier != StateSync.TierFull && b.estimate < b.cfg.ReducedBelow*b.cfg.Hysteresis {
+			next = StateSync.TierReduced
+		}
+	}
+	if next == b.tier || b.closed {
+		b.tier = next
+		return
+	}
+	bandwidthTiers.Add(b.tier.String(), -1)
+	bandwidthTiers.Add(next.String(), 1)
+	bandwidthTransitions.Add(1)
+	b.tier = next
+}
+
+// ewma 指数平滑，首个样本直接采用
+func ewma(prev, sample float64) float64 {
+	if prev == 0 {
+		return sample
+	}
+	return prev*0.75 + sample*0.25
+}
@@ -0,0 +1,34 @@
+package Net
+
+import (
+	"testing"
+	"time"
+	"zdopt/ZdoptServer/StateSync"
+)
+
+func TestBandwidthAckedThroughCountsSentBytes(t *testing.T) {
+	b := NewBandwidthEstimator(BandwidthConfig{Window: 100 * time.Millisecond, ReducedBelow: 8 << 10, MinimalBelow: 2 << 10})
+	defer b.Close()
+	start := time.Now()
+	for i := 0; i < 10; i++ {
+		b.SentMessage(100)
+	}
+	b.AckedThrough(0, start) // 开始窗口
+	b.AckedThrough(4, start.Add(50*time.Millisecond))
+	b.AckedThrough(4, start.Add(60*time.Millisecond)) // 重复确认不再计入
+	b.AckedThrough(10, start.Add(100*time.Millisecond))
+	// 100ms 内确认 1000 字节
+	if got := b.Stats().Delivery; got != 10000 {
+		t.Fatalf("delivery = %v B/s, want 10000", got)
+	}
+	if b.QualityTier() != StateSync.TierFull {
+		t.Fatalf("tier = %v, want full", b.QualityTier())
+	}
+
+	b.SentMessage(100)
+	b.AckedThrough(11, start.Add(300*time.Millisecond))
+	// 200ms 内只确认 100 字节，平滑后仍低于 8KB/s
+	if b.QualityTier() != StateSync.TierReduced {
+		t.Fatalf("tier = %v after slow window, want reduced", b.QualityTier())
+	}
+}
@@ -0,0 +1,487 @@
+package Net
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"reflect"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+	"zdopt/ZdoptServer/Pb"
+
+	"google.golang.org/protobuf/proto"
+)
+
+var (
+	ErrClientClosed      = errors.New("client closed")
+	ErrNoClientCodec     = errors.New("client codec not set")
+	ErrHandshakeTimeout  = errors.New("handshake timed out")
+	ErrHeartbeatTimeout  = errors.New("heartbeat timed out")
+	ErrServerClosing     = errors.New("server closing connection")
+	ErrNoDatagram        = errors.New("udp channel not established")
+	ErrHandshakeRejected = errors.New("handshake rejected")
+)
+
+// ClientConfig 客户端参数，零值字段使用默认值
+type ClientConfig struct {
+	Transport    TransportConfig // 加密与 FEC，须与服务端一致
+	Network      string          // NetworkKCP（默认）或 NetworkTCP，须为服务端开启的传输
+	TLS          *tls.Config     // Network 为 NetworkTCP 时非 nil 则以 TLS 连接
+	Codec        Codec           // 必填，消息 ID 映射须与服务端一致；设置 Hello 时须登记 Pb.ServerHello
+	Heartbeat    HeartbeatConfig
+	MaxFrameSize int
+	// Compression 收到的压缩帧经此解压；协商了 FeatureCompression 时发出的大负载经此压缩。
+	// 为 nil 时 Hello 不应声明该特性；设置了字典且 Hello 未声明 Dictionaries 时按全部字典协商
+	Compression *Compression
+	// Hello 非 nil 时连接后先发送，等待 Pb.ServerHello 后 Dial 才返回（服务端配置了 TokenHandshake 时必需）；
+	// 带 ResumeToken 与 LastSeq 时恢复原会话；未设置 Schema 时填入 Pb.LocalDigest()，与服务端协议不兼容时 Dial 失败；
+	// 登记了 Pb.ErrorResponse 时，服务端拒绝握手的错误码与文本随 ErrHandshakeRejected 返回
+	Hello            *Pb.ClientHello
+	HandshakeTimeout time.Duration
+	// UDP 为 true 且 ServerHello 提供了 UDP 通道时建立通道，供 SendUnreliable 使用，经通道收到的消息同样交给处理函数
+	UDP bool
+}
+
+// DefaultClientConfig 默认参数：不加密，握手超时 10 秒
+func DefaultClientConfig() ClientConfig {
+	return ClientConfig{
+		Transport:        DefaultTransportConfig(),
+		Heartbeat:        DefaultHeartbeatConfig(),
+		MaxFrameSize:     DefaultMaxFrameSize,
+		HandshakeTimeout: 10 * time.Second,
+	}
+}
+
+// Client 连接服务端 KCP（或 TCP）监听的客户端（机器人、集成测试、内部工具）：按服务端的帧格式与编解码器收发消息，
+// 自动应答心跳并按自适应间隔发送心跳与确认帧。收到的消息在接收协程中按类型交给 Handle 登记的处理函数，
+// 处理函数不会并发执行，不得阻塞；服务端的关闭通知以 *Pb.Reconnect 交给处理函数后关闭连接（Err 为 ErrServerClosing）
+type Client struct {
+	cfg    ClientConfig
+	sess   net.Conn                     // KCP 会话或 TCP 连接
+	udp    atomic.Pointer[DatagramConn] // 握手中建立，之后不变
+	hb     *Heartbeat
+	hello  *Pb.ServerHello
+	schema *Pb.SchemaReport // 服务端回复了协议摘要时设置
+
+	handlersMu sync.RWMutex
+	handlers   map[reflect.Type]func(interface{})
+	unhandled  func(interface{})
+	onClose    func(error)
+	dispatchMu sync.Mutex // 串行化 KCP 与 UDP 两个接收协程的分发
+
+	writeMu   sync.Mutex
+	features  atomic.Pointer[Features]
+	dicts     atomic.Pointer[AcceptedDictionaries] // 握手协商的字典，发送时使用
+	local     AcceptedDictionaries                 // 本端持有的全部字典，接收时使用（握手回复前服务端不会以字典压缩）
+	seq       atomic.Uint32                        // 已按序收到的应用消息数（ServerHello 计入），用于确认帧
+	helloCh   chan *Pb.ServerHello
+	greeted   atomic.Bool // 已收到 ServerHello，之前收到的 Pb.ErrorResponse 视为握手被拒绝
+	done      chan struct{}
+	closeOnce sync.Once
+	err       error
+}
+
+// Dial 连接 addr（host:port），设置了 cfg.Hello 时完成握手后返回
+func Dial(addr string, cfg ClientConfig) (*Client, error) {
+	def := DefaultClientConfig()
+	if cfg.Codec == nil {
+		return nil, ErrNoClientCodec
+	}
+	if cfg.MaxFrameSize <= 0 {
+		cfg.MaxFrameSize = def.MaxFrameSize
+	}
+	if cfg.HandshakeTimeout <= 0 {
+		cfg.HandshakeTimeout = def.HandshakeTimeout
+	}
+	dicts := cfg.Compression.Dictionaries()
+	if hello := cfg.Hello; hello != nil && (hello.GetSchema() == nil || dicts != nil && len(hello.GetDictionaries()) == 0) {
+		hello = proto.Clone(hello).(*Pb.ClientHello)
+		if hello.Schema == nil {
+			hello.Schema = Pb.LocalDigest()
+		}
+		if dicts != nil && len(hello.Dictionaries) == 0 {
+			hello.Dictionaries = dicts.IDs()
+		}
+		cfg.Hello = hello
+	}
+	var sess net.Conn
+	var err error
+	switch cfg.Network {
+	case "", NetworkKCP:
+		sess, err = cfg.Transport.DialKCP(addr)
+	case NetworkTCP:
+		sess, err = cfg.Transport.DialTCP(addr, cfg.TLS)
+	default:
+		err = fmt.Errorf("%w: %s", ErrUnknownNetwork, cfg.Network)
+	}
+	if err != nil {
+		return nil, err
+	}
+	c := &Client{
+		cfg:      cfg,
+		sess:     sess,
+		hb:       NewHeartbeat(cfg.Heartbeat),
+		handlers: make(map[reflect.Type]func(interface{})),
+		helloCh:  make(chan *Pb.ServerHello, 1),
+		done:     make(chan struct{}),
+	}
+	c.features.Store(&Features{})
+	if dicts != nil {
+		c.local = dicts.Negotiate(dicts.IDs())
+	}
+	go c.readLoop()
+	if cfg.Hello != nil {
+		if err := c.handshake(addr); err != nil {
+			c.closeWith(err)
+			return nil, err
+		}
+	}
+	go c.heartbeatLoop()
+	return c, nil
+}
+
+// handshake 发送 ClientHello 并等待 ServerHello，按需建立 UDP 通道
+func (c *Client) handshake(addr string) error {
+	c.seq.Store(c.cfg.Hello.GetLastSeq())
+	if err := c.Send(c.cfg.Hello); err != nil {
+		return err
+	}
+	timer := time.NewTimer(c.cfg.HandshakeTimeout)
+	defer timer.Stop()
+	select {
+	case hello := <-c.helloCh:
+		c.hello = hello
+	case <-c.done:
+		return c.Err()
+	case <-timer.C:
+		return ErrHandshakeTimeout
+	}
+	if remote := c.hello.GetSchema(); remote != nil {
+		c.schema = Pb.CompareSchemas(c.cfg.Hello.GetSchema(), remote)
+		if err := c.schema.Err(); err != nil {
+			return err
+		}
+	}
+	features := Features(c.hello.GetFeatures()).Clone()
+	c.features.Store(&features)
+	if dicts := c.cfg.Compression.Dictionaries(); dicts != nil && features.Has(FeatureCompression) {
+		accepted := dicts.Negotiate(c.hello.GetDictionaries())
+		c.dicts.Store(&accepted)
+	}
+	if !c.cfg.UDP || c.hello.GetUdpKey() == 0 {
+		return nil
+	}
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return err
+	}
+	udp, err := DialDatagram(net.JoinHostPort(host, strconv.Itoa(int(c.hello.GetUdpPort()))), c.hello.GetUdpKey())
+	if err != nil {
+		return err
+	}
+	c.udp.Store(udp)
+	select {
+	case <-c.done:
+		_ = udp.Close()
+		return c.Err()
+	default:
+	}
+	// 先发一个 Ping 让服务端记录本端地址，之后才能经通道回发
+	_ = udp.WriteFrame(PingMessageID, []byte{0, 0, 0, 0})
+	go c.datagramLoop()
+	return nil
+}
+
+// Handle 登记类型为 T 的消息（如 *Pb.DataPacket）的处理函数，同一类型重复登记时覆盖
+func Handle[T any](c *Client, fn func(T)) {
+	c.handlersMu.Lock()
+	defer c.handlersMu.Unlock()
+	c.handlers[reflect.TypeOf((*T)(nil)).Elem()] = func(msg interface{}) {
+		fn(msg.(T))
+	}
+}
+
+// HandleUnhandled 没有处理函数的消息交给 fn，未设置时丢弃
+func (c *Client) HandleUnhandled(fn func(msg interface{})) {
+	c.handlersMu.Lock()
+	defer c.handlersMu.Unlock()
+	c.unhandled = fn
+}
+
+// OnClose 连接关闭时以原因调用 fn（主动 Close 时为 ErrClientClosed）
+func (c *Client) OnClose(fn func(err error)) {
+	c.handlersMu.Lock()
+	defer c.handlersMu.Unlock()
+	c.onClose = fn
+}
+
+// Send 经编解码器编码后发出，协商了压缩时大负载压缩后发出
+func (c *Client) Send(msg proto.Message) error {
+	f, err := c.cfg.Codec.Encode(msg)
+	if err != nil {
+		return err
+	}
+	return c.SendFrame(f.ID, f.Payload)
+}
+
+// SendFrame 直接发出一帧
+func (c *Client) SendFrame(id uint32, payload []byte) error {
+	buf := make([]byte, 0, FrameHeaderSize+len(payload))
+	if c.cfg.Compression != nil && c.Features().Has(FeatureCompression) {
+		buf = c.cfg.Compression.AppendFrameWith(buf, id, payload, c.Dictionaries())
+	} else {
+		buf = AppendFrame(buf, id, payload)
+	}
+	return c.write(buf)
+}
+
+// SendUnreliable 经 UDP 通道发出，未建立通道时返回 ErrNoDatagram
+func (c *Client) SendUnreliable(msg proto.Message) error {
+	udp := c.udp.Load()
+	if udp == nil {
+		return ErrNoDatagram
+	}
+	f, err := c.cfg.Codec.Encode(msg)
+	if err != nil {
+		return err
+	}
+	return udp.WriteFrame(f.ID, f.Payload)
+}
+
+func (c *Client) write(b []byte) error {
+	select {
+	case <-c.done:
+		return c.Err()
+	default:
+	}
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	_, err := c.sess.Write(b)
+	return err
+}
+
+// ServerHello 握手回复，未设置 Hello 时为 nil
+func (c *Client) ServerHello() *Pb.ServerHello {
+	return c.hello
+}
+
+// Schema 与服务端协议注册表的比对报告，服务端未回复摘要时为 nil；
+// SchemaDegraded 时不应发送 MissingRemote 中的类型
+func (c *Client) Schema() *Pb.SchemaReport {
+	return c.schema
+}
+
+// Features 与服务端协商的特性
+func (c *Client) Features() Features {
+	return *c.features.Load()
+}
+
+// Dictionaries 与服务端协商的压缩字典，未协商时为 nil
+func (c *Client) Dictionaries() AcceptedDictionaries {
+	if a := c.dicts.Load(); a != nil {
+		return *a
+	}
+	return nil
+}
+
+// ResumeToken 服务端下发的恢复令牌，重连时与 LastSeq 一起填入 ClientHello
+func (c *Client) ResumeToken() string {
+	return c.hello.GetResumeToken()
+}
+
+// LastSeq 已按序收到的最后一条应用消息序号
+func (c *Client) LastSeq() uint32 {
+	return c.seq.Load()
+}
+
+// Stats 心跳测得的 RTT 与丢包
+func (c *Client) Stats() HeartbeatStats {
+	return c.hb.Stats()
+}
+
+// LocalAddr 本端地址
+func (c *Client) LocalAddr() net.Addr {
+	return c.sess.LocalAddr()
+}
+
+// Done 连接关闭后关闭
+func (c *Client) Done() <-chan struct{} {
+	return c.done
+}
+
+// Err 关闭原因，未关闭时为 nil
+func (c *Client) Err() error {
+	select {
+	case <-c.done:
+		return c.err
+	default:
+		return nil
+	}
+}
+
+// Close 关闭连接
+func (c *Client) Close() error {
+	c.closeWith(ErrClientClosed)
+	return nil
+}
+
+func (c *Client) closeWith(err error) {
+	c.closeOnce.Do(func() {
+		c.err = err
+		close(c.done)
+		_ = c.sess.Close()
+		if udp := c.udp.Load(); udp != nil {
+			_ = udp.Close()
+		}
+		c.handlersMu.RLock()
+		fn := c.onClose
+		c.handlersMu.RUnlock()
+		if fn != nil {
+			fn(err)
+		}
+	})
+}
+
+// readLoop 读取 KCP 流并按帧分发，帧长度非法、无法解压或收到关闭通知时关闭连接
+func (c *Client) readLoop() {
+	r := NewReassembler(c.cfg.MaxFrameSize)
+	buf := make([]byte, 4<<10)
+	for {
+		n, err := c.sess.Read(buf)
+		if err != nil {
+			c.closeWith(err)
+			return
+		}
+		c.hb.Received(time.Now())
+		frames, err := r.Feed(buf[:n])
+		frames, xerr := c.cfg.Compression.ExpandWith(frames, c.local)
+		if c.dispatch(frames, true) {
+			err = ErrServerClosing
+		}
+		if err = errors.Join(err, xerr); err != nil {
+			c.closeWith(err)
+			return
+		}
+	}
+}
+
+// datagramLoop 读取 UDP 通道，服务端的 Pong 只用于保持路径，不计入心跳
+func (c *Client) datagramLoop() {
+	udp := c.udp.Load()
+	buf := make([]byte, MaxDatagramSize)
+	for {
+		f, err := udp.ReadFrame(buf)
+		if err != nil {
+			return
+		}
+		if IsControlID(f.ID) {
+			continue
+		}
+		c.dispatch([]Frame{f}, false)
+	}
+}
+
+// dispatch 应答控制帧并把应用消息解码后交给处理函数；ordered 为 false 的帧（UDP）不计入确认序号。
+// 收到关闭通知时返回 true
+func (c *Client) dispatch(frames []Frame, ordered bool) (closing bool) {
+	c.dispatchMu.Lock()
+	defer c.dispatchMu.Unlock()
+	for _, f := range frames {
+		switch f.ID {
+		case PingMessageID:
+			if seq, ok := HeartbeatSeq(f); ok {
+				_ = c.write(AppendHeartbeatFrame(nil, PongMessageID, seq))
+			}
+			continue
+		case PongMessageID:
+			if seq, ok := HeartbeatSeq(f); ok {
+				c.hb.Acked(seq, time.Now())
+			}
+			continue
+		case AckMessageID:
+			continue
+		case ClosingMessageID:
+			if hint, err := ClosingHint(f); err == nil {
+				c.deliver(hint)
+			}
+			closing = true
+			continue
+		}
+		if ordered {
+			c.seq.Add(1)
+		}
+		msg, err := c.cfg.Codec.Decode(f)
+		if err != nil {
+			continue
+		}
+		if mismatch, ok := msg.(*Pb.SchemaMismatch); ok {
+			// 服务端因协议不兼容拒绝握手，随后关闭连接
+			c.closeWith(fmt.Errorf("%w: rejected by server: %s", Pb.ErrSchemaIncompatible, mismatch.Report()))
+			continue
+		}
+		if reject, ok := msg.(*Pb.ErrorResponse); ok && c.cfg.Hello != nil && !c.greeted.Load() {
+			// 服务端拒绝握手（认证失败、名额已满等），随后关闭连接
+			c.closeWith(fmt.Errorf("%w: code %d: %s", ErrHandshakeRejected, reject.GetCode(), reject.GetMessage()))
+			continue
+		}
+		if hello, ok := msg.(*Pb.ServerHello); ok {
+			c.greeted.Store(true)
+			if !hello.GetResumed() {
+				// 新会话从 ServerHello 起重新编号
+				c.seq.Store(1)
+			}
+			select {
+			case c.helloCh <- hello:
+			default:
+			}
+			continue
+		}
+		c.deliver(msg)
+	}
+	return closing
+}
+
+func (c *Client) deliver(msg interface{}) {
+	c.handlersMu.RLock()
+	fn, ok := c.handlers[reflect.TypeOf(msg)]
+	if !ok {
+		fn = c.unhandled
+	}
+	c.handlersMu.RUnlock()
+	if fn != nil {
+		fn(msg)
+	}
+}
+
+// heartbeatLoop 按自适应间隔发送心跳与确认帧，UDP 通道同时发 Ping 保持 NAT 映射；超时未收到数据时关闭连接
+func (c *Client) heartbeatLoop() {
+	var seq, acked uint32
+	timer := time.NewTimer(c.hb.Interval())
+	defer timer.Stop()
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-timer.C:
+		}
+		now := time.Now()
+		if c.hb.Expired(now) {
+			c.closeWith(fmt.Errorf("%w after %v", ErrHeartbeatTimeout, c.hb.Timeout()))
+			return
+		}
+		seq++
+		c.hb.Sent(seq, now)
+		frames := AppendHeartbeatFrame(nil, PingMessageID, seq)
+		if last := c.seq.Load(); last != acked {
+			frames, acked = AppendAckFrame(frames, last), last
+		}
+		_ = c.write(frames)
+		if udp := c.udp.Load(); udp != nil {
+			_ = udp.WriteFrame(PingMessageID, frames[FrameHeaderSize:FrameHeaderSize+4])
+		}
+		timer.Reset(c.hb.Interval())
+	}
+}
@@ -0,0 +1,147 @@
+package Net
+
+import (
+	"encoding/binary"
+	"errors"
+	"expvar"
+	"fmt"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+var (
+	ErrCompressionDisabled = errors.New("compressed frame on a connection without compression")
+
+	compressionBytes = expvar.NewMap("net.compression") // in / out 压缩前后字节数，frames 压缩帧数，errors 解压失败数
+)
+
+// DefaultCompressionThreshold 负载达到该大小才尝试压缩，小包压缩收益不抵开销
+const DefaultCompressionThreshold = 512
+
+// Compression 超过阈值的负载以 zstd 压缩，帧头长度字段最高位标记；
+// 只对协商了 FeatureCompression 的会话压缩发送，收到的压缩帧经 Expand 还原。
+// 设置了字典时，归入类别且会话协商了该字典的消息不论大小都以字典压缩（AppendFrameWith / ExpandWith）
+type Compression struct {
+	threshold int
+	maxSize   int
+	enc       *zstd.Encoder
+	dec       *zstd.Decoder
+	dicts     *Dictionaries
+}
+
+// NewCompression 创建压缩器，threshold 为 0 时使用 DefaultCompressionThreshold，
+// maxSize 为解压后负载上限（0 为 DefaultMaxFrameSize）
+func NewCompression(threshold, maxSize int) (*Compression, error) {
+	if threshold <= 0 {
+		threshold = DefaultCompressionThreshold
+	}
+	if maxSize <= 0 {
+		maxSize = DefaultMaxFrameSize
+	}
+	enc, err := zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.SpeedFastest), zstd.WithEncoderCRC(false))
+	if err != nil {
+		return nil, err
+	}
+	dec, err := zstd.NewReader(nil, zstd.WithDecoderConcurrency(0), zstd.WithDecoderMaxMemory(uint64(maxSize)))
+	if err != nil {
+		return nil, err
+	}
+	return &Compression{threshold: threshold, maxSize: maxSize, enc: enc, dec: dec}, nil
+}
+
+// Threshold 压缩阈值
+func (c *Compression) Threshold() int {
+	return c.threshold
+}
+
+// SetDictionaries 启用预训练字典，须在开始收发前设置
+func (c *Compression) SetDictionaries(d *Dictionaries) {
+	c.dicts = d
+}
+
+// Dictionaries 启用的字典，未设置时为 nil
+func (c *Compression) Dictionaries() *Dictionaries {
+	if c == nil {
+		return nil
+	}
+	return c.dicts
+}
+
+// AppendFrameWith 同 AppendFrame，消息 ID 归入的类别在 accepted 中协商了字典时以字典压缩，
+// 字典压缩后不更小时按阈值规则处理
+func (c *Compression) AppendFrameWith(dst []byte, id uint32, payload []byte, accepted AcceptedDictionaries) []byte {
+	if c.dicts != nil && len(accepted) > 0 {
+		if dict, ok := c.dicts.ForMessage(id); ok && accepted.Has(dict.ID) {
+			if out, ok := c.dicts.Compress(dict.Class, payload, accepted); ok {
+				start := len(dst)
+				dst = AppendFrame(dst, id, out)
+				compressionBytes.Add("dict_frames", 1)
+				binary.BigEndian.PutUint32(dst[start:], uint32(len(out))|frameCompressed)
+				return dst
+			}
+		}
+	}
+	return c.AppendFrame(dst, id, payload)
+}
+
+// AppendFrame 把帧追加到 dst，负载达到阈值且压缩后更小时写为压缩帧
+func (c *Compression) AppendFrame(dst []byte, id uint32, payload []byte) []byte {
+	if len(payload) < c.threshold {
+		return AppendFrame(dst, id, payload)
+	}
+	start := len(dst)
+	dst = AppendFrame(dst, id, nil)
+	dst = c.enc.EncodeAll(payload, dst)
+	size := len(dst) - start - FrameHeaderSize
+	compressionBytes.Add("in", int64(len(payload)))
+	if size >= len(payload) {
+		compressionBytes.Add("out", int64(len(payload)))
+		return AppendFrame(dst[:start], id, payload)
+	}
+	compressionBytes.Add("out", int64(size))
+	compressionBytes.Add("frames", 1)
+	binary.BigEndian.PutUint32(dst[start:], uint32(size)|frameCompressed)
+	return dst
+}
+
+// Expand 就地解压 frames 中的压缩帧；c 为 nil 时收到压缩帧返回 ErrCompressionDisabled，
+// 解压后超过上限返回 ErrFrameTooLarge，连接应关闭。以字典压缩的帧返回 ErrUnknownDictionary，见 ExpandWith
+func (c *Compression) Expand(frames []Frame) ([]Frame, error) {
+	return c.ExpandWith(frames, nil)
+}
+
+// ExpandWith 同 Expand，以字典压缩的帧只接受 accepted 中协商过的字典
+func (c *Compression) ExpandWith(frames []Frame, accepted AcceptedDictionaries) ([]Frame, error) {
+	for i := range frames {
+		f := &frames[i]
+		if !f.Compressed {
+			continue
+		}
+		if c == nil {
+			compressionBytes.Add("errors", 1)
+			return frames[:i], ErrCompressionDisabled
+		}
+		var (
+			payload []byte
+			err     error
+		)
+		if dictionaryID(f.Payload) != 0 {
+			// 字典解码器共享 DefaultMaxFrameSize 的内存上限，按本端上限再检查一次
+			if payload, err = c.dicts.Decompress(f.Payload, accepted); err == nil && len(payload) > c.maxSize {
+				err = zstd.ErrDecoderSizeExceeded
+			}
+		} else {
+			payload, err = c.dec.DecodeAll(f.Payload, nil)
+		}
+		if err != nil {
+			compressionBytes.Add("errors", 1)
+			if errors.Is(err, zstd.ErrDecoderSizeExceeded) {
+				err = fmt.Errorf("%w: decompressed > %d", ErrFrameTooLarge, c.maxSize)
+			}
+			return frames[:i], err
+		}
+		f.Release()
+		f.Payload, f.Compressed = payload, false
+	}
+	return frames, nil
+}
@@ -0,0 +1,111 @@
+package Net
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+)
+
+var (
+	ErrBadDatagram      = errors.New("malformed datagram")
+	ErrDatagramTooLarge = errors.New("datagram exceeds size limit")
+)
+
+// DatagramHeaderSize 数据报头：8 字节会话密钥（大端），其后是恰好一个完整帧
+const DatagramHeaderSize = 8
+
+// MaxDatagramSize 单个数据报上限，保证不在 IP 层分片
+const MaxDatagramSize = 1200
+
+// NewDatagramKey 生成会话的 UDP 通道密钥（非零），握手时经 ServerHello 下发
+func NewDatagramKey() uint64 {
+	var b [8]byte
+	for {
+		_, _ = rand.Read(b[:])
+		if key := binary.BigEndian.Uint64(b[:]); key != 0 {
+			return key
+		}
+	}
+}
+
+// AppendDatagram 追加一个数据报：会话密钥 + 一帧，超过 MaxDatagramSize 时返回 ErrDatagramTooLarge
+func AppendDatagram(dst []byte, key uint64, id uint32, payload []byte) ([]byte, error) {
+	if size := DatagramHeaderSize + FrameHeaderSize + len(payload); size > MaxDatagramSize {
+		return dst, fmt.Errorf("%w: %d > %d", ErrDatagramTooLarge, size, MaxDatagramSize)
+	}
+	dst = binary.BigEndian.AppendUint64(dst, key)
+	return AppendFrame(dst, id, payload), nil
+}
+
+// ParseDatagram 解析数据报，帧长度须与数据报一致；返回的 Payload 引用 b
+func ParseDatagram(b []byte) (uint64, Frame, error) {
+	if len(b) < DatagramHeaderSize+FrameHeaderSize {
+		return 0, Frame{}, ErrBadDatagram
+	}
+	key := binary.BigEndian.Uint64(b)
+	b = b[DatagramHeaderSize:]
+	size := binary.BigEndian.Uint32(b)
+	compressed := size&frameCompressed != 0
+	size &^= frameCompressed
+	if int64(size) != int64(len(b)-FrameHeaderSize) {
+		return 0, Frame{}, fmt.Errorf("%w: frame length %d, datagram carries %d", ErrBadDatagram, size, len(b)-FrameHeaderSize)
+	}
+	return key, Frame{ID: binary.BigEndian.Uint32(b[4:]), Payload: b[FrameHeaderSize:], Compressed: compressed}, nil
+}
+
+// DatagramConn 客户端的 UDP 通道：以 ServerHello 中的密钥收发不可靠消息（不重发、不保序、不加密）。
+// 服务端收到该通道的第一个数据报后才能回发，可先发一个 Ping 控制帧建立路径并保持 NAT 映射
+type DatagramConn struct {
+	conn *net.UDPConn
+	key  uint64
+}
+
+// DialDatagram 连接服务端的 UDP 通道
+func DialDatagram(addr string, key uint64) (*DatagramConn, error) {
+	raddr, err := net.ResolveUDPAddr("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := net.DialUDP("udp", nil, raddr)
+	if err != nil {
+		return nil, err
+	}
+	return &DatagramConn{conn: conn, key: key}, nil
+}
+
+// WriteFrame 以一个数据报发出一帧
+func (c *DatagramConn) WriteFrame(id uint32, payload []byte) error {
+	b, err := AppendDatagram(make([]byte, 0, DatagramHeaderSize+FrameHeaderSize+len(payload)), c.key, id, payload)
+	if err != nil {
+		return err
+	}
+	_, err = c.conn.Write(b)
+	return err
+}
+
+// ReadFrame 读取下一个数据报中的帧，Payload 引用 buf（至少 MaxDatagramSize 字节）；密钥不符的数据报被跳过
+func (c *DatagramConn) ReadFrame(buf []byte) (Frame, error) {
+	for {
+		n, err := c.conn.Read(buf)
+		if err != nil {
+			return Frame{}, err
+		}
+		key, f, err := ParseDatagram(buf[:n])
+		if err != nil || key != c.key {
+			continue
+		}
+		return f, nil
+	}
+}
+
+// Conn 底层 UDP 连接（设置读超时等）
+func (c *DatagramConn) Conn() *net.UDPConn {
+	return c.conn
+}
+
+// Close 关闭通道
+func (c *DatagramConn) Close() error {
+	return c.conn.Close()
+}
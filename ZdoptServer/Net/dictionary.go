@@ -0,0 +1,298 @@
+package Net
+
+import (
+	"errors"
+	"expvar"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"zdopt/ZdoptServer/Pb"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+var (
+	ErrUnknownDictionary = errors.New("unknown compression dictionary")
+	ErrInvalidDictionary = errors.New("invalid compression dictionary")
+
+	dictionaryBytes = expvar.NewMap("net.dictionary.bytes") // 按消息类别统计压缩前 (in) / 压缩后 (out) 字节数
+)
+
+// DictionaryExt 字典文件扩展名，文件名（不含扩展名）为消息类别
+const DictionaryExt = ".dict"
+
+// DefaultDictionarySize 训练字典的默认大小
+const DefaultDictionarySize = 16 << 10
+
+// Dictionary 按消息类别加载的预训练 zstd 字典
+type Dictionary struct {
+	ID    uint32
+	Class string
+	Size  int
+}
+
+type dictCodec struct {
+	Dictionary
+	raw []byte
+	enc *zstd.Encoder
+}
+
+// Dictionaries 压缩字典集合：每个消息类别一个字典，按字典 ID 在握手时协商。
+// 小包用通用压缩几乎没有收益，预训练字典能把重复的字段名与结构提前放进压缩窗口。
+// 经 Compression.SetDictionaries 接入帧编码后，Bind 到类别的消息 ID 按会话协商结果用字典压缩
+type Dictionaries struct {
+	mu      sync.RWMutex
+	byID    map[uint32]*dictCodec
+	byClass map[string]*dictCodec
+	classOf map[uint32]string // 消息 ID → 类别
+	dec     *zstd.Decoder     // 注册了全部字典，按帧头中的字典 ID 选择
+}
+
+// NewDictionaries 创建空集合
+func NewDictionaries() *Dictionaries {
+	return &Dictionaries{
+		byID:    make(map[uint32]*dictCodec),
+		byClass: make(map[string]*dictCodec),
+		classOf: make(map[uint32]string),
+	}
+}
+
+// Bind 把消息 ID 归入类别，帧编码时这些消息使用该类别的字典；可先于字典加载配置
+func (d *Dictionaries) Bind(class string, ids ...uint32) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, id := range ids {
+		d.classOf[id] = class
+	}
+}
+
+// ForMessage 消息 ID 所属类别的字典，未归入类别或类别没有字典时返回 false
+func (d *Dictionaries) ForMessage(id uint32) (Dictionary, bool) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	c, ok := d.byClass[d.classOf[id]]
+	if !ok {
+		return Dictionary{}, false
+	}
+	return c.Dictionary, true
+}
+
+// Add 加载消息类别的字典（zstd 字典格式，含 ID），同一类别重复加载时替换
+func (d *Dictionaries) Add(class string, raw []byte) (Dictionary, error) {
+	info, err := zstd.InspectDictionary(raw)
+	if err != nil {
+		return Dictionary{}, fmt.Errorf("%w: %s: %v", ErrInvalidDictionary, class, err)
+	}
+	if info.ID() == 0 {
+		return Dictionary{}, fmt.Errorf("%w: %s: dictionary id must be non-zero", ErrInvalidDictionary, class)
+	}
+	// 小包不写校验和，完整性由传输层保证
+	enc, err := zstd.NewWriter(nil, zstd.WithEncoderDict(raw), zstd.WithEncoderCRC(false))
+	if err != nil {
+		return Dictionary{}, fmt.Errorf("%w: %s: %v", ErrInvalidDictionary, class, err)
+	}
+	c := &dictCodec{
+		Dictionary: Dictionary{ID: info.ID(), Class: class, Size: len(raw)},
+		raw:        raw,
+		enc:        enc,
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if other, ok := d.byID[c.ID]; ok && other.Class != class {
+		_ = enc.Close()
+		return Dictionary{}, fmt.Errorf("%w: id %d already used by %s", ErrInvalidDictionary, c.ID, other.Class)
+	}
+	if old, ok := d.byClass[class]; ok {
+		delete(d.byID, old.ID)
+		_ = old.enc.Close()
+	}
+	d.byID[c.ID] = c
+	d.byClass[class] = c
+	return c.Dictionary, d.rebuildDecoder()
+}
+
+// rebuildDecoder 按当前字典重建解码器（调用方持有写锁）
+func (d *Dictionaries) rebuildDecoder() error {
+	raws := make([][]byte, 0, len(d.byID))
+	for _, c := range d.byID {
+		raws = append(raws, c.raw)
+	}
+	dec, err := zstd.NewReader(nil, zstd.WithDecoderDicts(raws...), zstd.WithDecoderConcurrency(0),
+		zstd.WithDecoderMaxMemory(DefaultMaxFrameSize))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidDictionary, err)
+	}
+	if d.dec != nil {
+		d.dec.Close()
+	}
+	d.dec = dec
+	return nil
+}
+
+// LoadDir 加载目录下全部 *.dict 文件，文件名为消息类别（如 move.dict）
+func (d *Dictionaries) LoadDir(dir string) error {
+	paths, err := filepath.Glob(filepath.Join(dir, "*"+DictionaryExt))
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, path := range paths {
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		class := strings.TrimSuffix(filepath.Base(path), DictionaryExt)
+		if _, err := d.Add(class, raw); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// List 已加载的字典（按 ID 排序）
+func (d *Dictionaries) List() []Dictionary {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	list := make([]Dictionary, 0, len(d.byID))
+	for _, c := range d.byID {
+		list = append(list, c.Dictionary)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	return list
+}
+
+// IDs 已加载的字典 ID（排序），用于握手
+func (d *Dictionaries) IDs() []uint32 {
+	list := d.List()
+	ids := make([]uint32, len(list))
+	for i, dict := range list {
+		ids[i] = dict.ID
+	}
+	return ids
+}
+
+// AcceptedDictionaries 会话协商后双方都持有的字典 ID
+type AcceptedDictionaries map[uint32]struct{}
+
+// Has 是否可以使用该字典
+func (a AcceptedDictionaries) Has(id uint32) bool {
+	_, ok := a[id]
+	return ok
+}
+
+// Negotiate 与对端持有的字典 ID 取交集
+func (d *Dictionaries) Negotiate(remote []uint32) AcceptedDictionaries {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	accepted := make(AcceptedDictionaries)
+	for _, id := range remote {
+		if _, ok := d.byID[id]; ok {
+			accepted[id] = struct{}{}
+		}
+	}
+	return accepted
+}
+
+// AcceptDictionaries 服务端握手：按客户端持有的字典协商，结果写入回复
+func (d *Dictionaries) AcceptDictionaries(hello *Pb.ClientHello, reply *Pb.ServerHello) AcceptedDictionaries {
+	accepted := d.Negotiate(hello.GetDictionaries())
+	reply.Dictionaries = reply.Dictionaries[:0]
+	for id := range accepted {
+		reply.Dictionaries = append(reply.Dictionaries, id)
+	}
+	sort.Slice(reply.Dictionaries, func(i, j int) bool { return reply.Dictionaries[i] < reply.Dictionaries[j] })
+	return accepted
+}
+
+// Compress 用消息类别的字典压缩负载：类别没有字典、会话未协商该字典或压缩后不更小时返回原负载与 false，
+// 调用方需在帧中标记是否压缩
+func (d *Dictionaries) Compress(class string, payload []byte, accepted AcceptedDictionaries) ([]byte, bool) {
+	d.mu.RLock()
+	c, ok := d.byClass[class]
+	d.mu.RUnlock()
+	if !ok || !accepted.Has(c.ID) {
+		return payload, false
+	}
+	out := c.enc.EncodeAll(payload, nil)
+	dictionaryBytes.Add(class+".in", int64(len(payload)))
+	if len(out) >= len(payload) {
+		dictionaryBytes.Add(class+".out", int64(len(payload)))
+		return payload, false
+	}
+	dictionaryBytes.Add(class+".out", int64(len(out)))
+	return out, true
+}
+
+// Decompress 解压 Compress 的输出，字典由帧头中的字典 ID 决定；会话未协商的字典返回 ErrUnknownDictionary
+func (d *Dictionaries) Decompress(data []byte, accepted AcceptedDictionaries) ([]byte, error) {
+	var header zstd.Header
+	if err := header.Decode(data); err != nil {
+		return nil, err
+	}
+	if d == nil {
+		return nil, fmt.Errorf("%w: %d", ErrUnknownDictionary, header.DictionaryID)
+	}
+	if header.DictionaryID != 0 && !accepted.Has(header.DictionaryID) {
+		return nil, fmt.Errorf("%w: %d", ErrUnknownDictionary, header.DictionaryID)
+	}
+	d.mu.RLock()
+	dec := d.dec
+	d.mu.RUnlock()
+	if dec == nil {
+		return nil, fmt.Errorf("%w: %d", ErrUnknownDictionary, header.DictionaryID)
+	}
+	return dec.DecodeAll(data, nil)
+}
+
+// dictionaryID zstd 帧头中的字典 ID，未使用字典或帧头无法解析时为 0
+func dictionaryID(data []byte) uint32 {
+	var header zstd.Header
+	if header.Decode(data) != nil {
+		return 0
+	}
+	return header.DictionaryID
+}
+
+// TrainDictionary 由抓取的样本包离线训练字典：取样本拼接为字典内容（不超过 size），再按样本生成熵编码表
+func TrainDictionary(id uint32, samples [][]byte, size int) ([]byte, error) {
+	if id == 0 {
+		return nil, fmt.Errorf("%w: dictionary id must be non-zero", ErrInvalidDictionary)
+	}
+	if len(samples) == 0 {
+		return nil, fmt.Errorf("%w: no samples", ErrInvalidDictionary)
+	}
+	if size <= 0 {
+		size = DefaultDictionarySize
+	}
+	// 重复的样本只保留一份，越靠后的样本越靠近窗口末尾（匹配距离更短）
+	seen := make(map[string]struct{}, len(samples))
+	var history []byte
+	for i := len(samples) - 1; i >= 0 && len(history) < size; i-- {
+		s := samples[i]
+		if _, ok := seen[string(s)]; ok || len(s) == 0 {
+			continue
+		}
+		seen[string(s)] = struct{}{}
+		if len(history)+len(s) > size {
+			s = s[:size-len(history)]
+		}
+		history = append(append([]byte(nil), s...), history...)
+	}
+	// 样本全部落在历史窗口内时没有字面量，熵表统计为空（BuildDict 会除零）；
+	// 补一份覆盖全部字节值的样本，同时保证样本中未出现的字节也能编码
+	seed := make([]byte, 256)
+	for i := range seed {
+		seed[i] = byte(i)
+	}
+	return zstd.BuildDict(zstd.BuildDictOptions{
+		ID:       id,
+		Contents: append(samples[:len(samples):len(samples)], seed),
+		History:  history,
+		Offsets:  [3]int{1, 4, 8},
+	})
+}
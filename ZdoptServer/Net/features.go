@@ -0,0 +1,123 @@
+package Net
+
+import (
+	"context"
+	"expvar"
+	"sort"
+	"strconv"
+	"strings"
+	"zdopt/ZdoptServer/Pb"
+)
+
+// 协议特性名称，版本从 1 开始，0 表示不支持
+const (
+	FeatureCompression = "compression"  // 负载压缩
+	FeatureDeltaSync   = "delta_sync"   // 状态增量同步
+	FeatureReliable    = "reliable"     // 可靠传输层（确认与重传）
+	FeatureBatchFrames = "batch_frames" // 多条消息合并为一帧
+)
+
+var negotiatedFeatures = expvar.NewMap("net.features.negotiated") // 按特性统计协商启用的会话数
+
+// Features 特性 -> 版本
+type Features map[string]uint32
+
+// Has 是否启用了特性
+func (f Features) Has(name string) bool {
+	return f[name] > 0
+}
+
+// Version 特性版本，未启用时为 0
+func (f Features) Version(name string) uint32 {
+	return f[name]
+}
+
+// AtLeast 特性是否启用且版本不低于 v
+func (f Features) AtLeast(name string, v uint32) bool {
+	return f[name] >= v && v > 0
+}
+
+// Names 启用的特性名称（排序）
+func (f Features) Names() []string {
+	names := make([]string, 0, len(f))
+	for name, v := range f {
+		if v > 0 {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (f Features) String() string {
+	parts := make([]string, 0, len(f))
+	for _, name := range f.Names() {
+		parts = append(parts, name+"@"+strconv.FormatUint(uint64(f[name]), 10))
+	}
+	return strings.Join(parts, ",")
+}
+
+// Clone 复制一份，调用方可安全修改
+func (f Features) Clone() Features {
+	c := make(Features, len(f))
+	for name, v := range f {
+		c[name] = v
+	}
+	return c
+}
+
+// Negotiate 协商双方都支持的特性，版本取双方最高版本中较低的一个
+func Negotiate(local, remote Features) Features {
+	out := make(Features)
+	for name, lv := range local {
+		if rv := remote[name]; lv > 0 && rv > 0 {
+			out[name] = min(lv, rv)
+		}
+	}
+	return out
+}
+
+// DefaultFeatures 当前版本服务端支持的特性
+func DefaultFeatures() Features {
+	return Features{
+		FeatureCompression: 1,
+		FeatureDeltaSync:   1,
+		FeatureReliable:    1,
+		FeatureBatchFrames: 1,
+	}
+}
+
+// AcceptHello 服务端处理客户端握手：按本端支持的特性协商，返回握手回复与协商结果；
+// 旧版客户端不发送特性时结果为空，双方均按基础协议通信
+func AcceptHello(local Features, hello *Pb.ClientHello) (*Pb.ServerHello, Features) {
+	negotiated := Negotiate(local, Features(hello.GetFeatures()))
+	for name := range negotiated {
+		negotiatedFeatures.Add(name, 1)
+	}
+	return &Pb.ServerHello{Features: negotiated.Clone()}, negotiated
+}
+
+// NewClientHello 客户端握手消息，携带本端支持的特性
+func NewClientHello(region string, local Features) *Pb.ClientHello {
+	return &Pb.ClientHello{Region: region, Features: local.Clone()}
+}
+
+// ClientFeatures 客户端根据服务端回复确定启用的特性（与本端支持的再取一次交集，防止服务端回复本端不支持的特性）
+func ClientFeatures(local Features, reply *Pb.ServerHello) Features {
+	return Negotiate(local, Features(reply.GetFeatures()))
+}
+
+type featuresKey struct{}
+
+// WithFeatures 把会话协商结果放入 ctx，处理该会话消息的模块通过 FeaturesFrom 读取
+func WithFeatures(ctx context.Context, f Features) context.Context {
+	return context.WithValue(ctx, featuresKey{}, f)
+}
+
+// FeaturesFrom 读取会话协商结果，未握手的会话返回空集合
+func FeaturesFrom(ctx context.Context) Features {
+	if f, ok := ctx.Value(featuresKey{}).(Features); ok {
+		return f
+	}
+	return Features{}
+}
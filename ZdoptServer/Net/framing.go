@@ -0,0 +1,263 @@
+package Net
+
+import (
+	"encoding/binary"
+	"errors"
+	"expvar"
+	"fmt"
+	"io"
+	"math"
+	"sync"
+	"zdopt/ZdoptServer/ObjectPool"
+	"zdopt/ZdoptServer/Pb"
+
+	"google.golang.org/protobuf/proto"
+)
+
+var (
+	ErrFrameTooLarge      = errors.New("frame exceeds size limit")
+	ErrUnknownMessageID   = errors.New("unknown message id")
+	ErrUnknownMessageType = errors.New("message type has no id")
+
+	framingErrors = expvar.NewMap("net.framing.errors") // too_large / decode / encode
+)
+
+// FrameHeaderSize 帧头：4 字节负载长度 + 4 字节消息 ID（大端）
+const FrameHeaderSize = 8
+
+// frameCompressed 长度字段最高位：负载经压缩（见 Compression）
+const frameCompressed = 1 << 31
+
+// DefaultMaxFrameSize 默认单帧负载上限，超出视为流已损坏
+const DefaultMaxFrameSize = 1 << 20
+
+// Frame 一条完整的消息帧
+type Frame struct {
+	ID         uint32
+	Payload    []byte
+	Compressed bool               // 负载经压缩，需先经 Compression.Expand 还原
+	Buf        *ObjectPool.Buffer // 负载所在的池化缓冲，nil 表示负载为普通分配
+}
+
+// Release 归还负载所在的池化缓冲，之后不得再访问 Payload
+func (f *Frame) Release() {
+	f.Buf.Release()
+	f.Buf, f.Payload = nil, nil
+}
+
+// AppendFrame 把帧追加到 dst
+func AppendFrame(dst []byte, id uint32, payload []byte) []byte {
+	dst = binary.BigEndian.AppendUint32(dst, uint32(len(payload)))
+	dst = binary.BigEndian.AppendUint32(dst, id)
+	return append(dst, payload...)
+}
+
+// WriteFrame 以一次 Write 写出整帧（报文型传输上一帧对应一个报文）
+func WriteFrame(w io.Writer, id uint32, payload []byte) error {
+	buf := AppendFrame(make([]byte, 0, FrameHeaderSize+len(payload)), id, payload)
+	_, err := w.Write(buf)
+	return err
+}
+
+// FrameReader 从字节流读取完整帧，跨多次 Read 的帧自动拼接
+type FrameReader struct {
+	r   io.Reader
+	max int
+	hdr [FrameHeaderSize]byte
+}
+
+// NewFrameReader 创建帧读取器，maxSize 为 0 时使用 DefaultMaxFrameSize
+func NewFrameReader(r io.Reader, maxSize int) *FrameReader {
+	if maxSize <= 0 {
+		maxSize = DefaultMaxFrameSize
+	}
+	return &FrameReader{r: r, max: maxSize}
+}
+
+// ReadFrame 读取下一帧，负载超过上限时返回 ErrFrameTooLarge（之后流不可再用）
+func (fr *FrameReader) ReadFrame() (Frame, error) {
+	if _, err := io.ReadFull(fr.r, fr.hdr[:]); err != nil {
+		return Frame{}, err
+	}
+	size := binary.BigEndian.Uint32(fr.hdr[:4])
+	compressed := size&frameCompressed != 0
+	size &^= frameCompressed
+	if int64(size) > int64(fr.max) {
+		framingErrors.Add("too_large", 1)
+		return Frame{}, fmt.Errorf("%w: %d > %d", ErrFrameTooLarge, size, fr.max)
+	}
+	f := Frame{ID: binary.BigEndian.Uint32(fr.hdr[4:]), Payload: make([]byte, size), Compressed: compressed}
+	if _, err := io.ReadFull(fr.r, f.Payload); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return Frame{}, err
+	}
+	return f, nil
+}
+
+// Reassembler 报文型传输的帧重组：一次收到的数据可能是半帧、整帧或多帧，未完成的部分留待下次
+type Reassembler struct {
+	buf  []byte
+	max  int
+	pool *ObjectPool.BufferPool
+}
+
+// NewReassembler 创建帧重组器，maxSize 为 0 时使用 DefaultMaxFrameSize
+func NewReassembler(maxSize int) *Reassembler {
+	if maxSize <= 0 {
+		maxSize = DefaultMaxFrameSize
+	}
+	return &Reassembler{max: maxSize}
+}
+
+// SetBufferPool 之后帧负载从 pool 分配，由持有者经 Frame.Release 归还
+func (r *Reassembler) SetBufferPool(pool *ObjectPool.BufferPool) {
+	r.pool = pool
+}
+
+// Feed 追加收到的数据并返回其中已完整的帧（负载为独立副本）。
+// 返回 ErrFrameTooLarge 时已缓冲的数据被丢弃，连接应关闭
+func (r *Reassembler) Feed(data []byte) ([]Frame, error) {
+	r.buf = append(r.buf, data...)
+	var frames []Frame
+	off := 0
+	for len(r.buf)-off >= FrameHeaderSize {
+		size := binary.BigEndian.Uint32(r.buf[off:])
+		compressed := size&frameCompressed != 0
+		size &^= frameCompressed
+		if int64(size) > int64(r.max) {
+			framingErrors.Add("too_large", 1)
+			r.buf = r.buf[:0]
+			return frames, fmt.Errorf("%w: %d > %d", ErrFrameTooLarge, size, r.max)
+		}
+		end := off + FrameHeaderSize + int(size)
+		if end > len(r.buf) {
+			break
+		}
+		f := Frame{ID: binary.BigEndian.Uint32(r.buf[off+4:]), Compressed: compressed}
+		if r.pool != nil {
+			f.Buf = r.pool.Get(int(size))
+			f.Payload = f.Buf.B
+			copy(f.Payload, r.buf[off+FrameHeaderSize:end])
+		} else {
+			f.Payload = append([]byte(nil), r.buf[off+FrameHeaderSize:end]...)
+		}
+		frames = append(frames, f)
+		off = end
+	}
+	// 剩余的半帧移到缓冲区开头
+	r.buf = r.buf[:copy(r.buf, r.buf[off:])]
+	return frames, nil
+}
+
+// Buffered 尚未组成完整帧的字节数
+func (r *Reassembler) Buffered() int {
+	return len(r.buf)
+}
+
+// Codec 消息与帧之间的编解码，传输层经 Codec 交付类型化的完整消息
+type Codec interface {
+	Encode(msg interface{}) (Frame, error)
+	Decode(f Frame) (interface{}, error)
+}
+
+// PbCodec 按消息 ID 映射 protobuf 类型的编解码器，类型需已在 Pb 注册。ID 绑定在 Pb 的进程内消息 ID 表
+// （见 Pb.BindID），与信封及 Actor.MessageRouter 共用，同一类型在各编解码器中 ID 相同；编解码器只收发登记过的类型。
+// 该表的 ID 与信封头一致为 2 字节，帧 ID 在此换算
+type PbCodec struct {
+	mu  sync.RWMutex
+	ids map[uint16]struct{}
+}
+
+// NewPbCodec 创建空的 protobuf 编解码器
+func NewPbCodec() *PbCodec {
+	return &PbCodec{ids: make(map[uint16]struct{})}
+}
+
+// Register 把协议全名绑定到消息 ID 并由本编解码器收发，ID 超出 2 字节时返回错误，
+// ID 或类型已绑定到其他对象时返回 Pb.ErrIDConflict
+func (c *PbCodec) Register(id uint32, name string) error {
+	if IsControlID(id) {
+		return fmt.Errorf("message id %d is reserved for control frames", id)
+	}
+	if id > math.MaxUint16 {
+		return fmt.Errorf("message id %d exceeds %d", id, math.MaxUint16)
+	}
+	if err := Pb.BindID(uint16(id), name); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ids[uint16(id)] = struct{}{}
+	return nil
+}
+
+// RegisterMessage 按类型分配消息 ID
+func RegisterMessage[T proto.Message](c *PbCodec, id uint32) error {
+	var zero T
+	return c.Register(id, Pb.TypeName(zero))
+}
+
+// ID 协议全名对应的消息 ID，类型未在本编解码器登记时返回 false
+func (c *PbCodec) ID(name string) (uint32, bool) {
+	id, ok := Pb.IDOf(name)
+	return uint32(id), ok && c.has(id)
+}
+
+func (c *PbCodec) has(id uint16) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	_, ok := c.ids[id]
+	return ok
+}
+
+func (c *PbCodec) Encode(msg interface{}) (Frame, error) {
+	pm, ok := msg.(proto.Message)
+	if !ok {
+		framingErrors.Add("encode", 1)
+		return Frame{}, fmt.Errorf("%w: %T is not a protobuf message", ErrUnknownMessageType, msg)
+	}
+	id, ok := Pb.MessageID(pm)
+	if !ok || !c.has(id) {
+		framingErrors.Add("encode", 1)
+		return Frame{}, fmt.Errorf("%w: %s", ErrUnknownMessageType, Pb.TypeName(pm))
+	}
+	payload, err := Pb.Serialize(pm)
+	if err != nil {
+		framingErrors.Add("encode", 1)
+		return Frame{}, err
+	}
+	return Frame{ID: uint32(id), Payload: payload}, nil
+}
+
+func (c *PbCodec) Decode(f Frame) (interface{}, error) {
+	if f.ID > math.MaxUint16 || !c.has(uint16(f.ID)) {
+		framingErrors.Add("decode", 1)
+		return nil, fmt.Errorf("%w: %d", ErrUnknownMessageID, f.ID)
+	}
+	msg, err := Pb.DeserializeByID(uint16(f.ID), f.Payload)
+	if err != nil {
+		framingErrors.Add("decode", 1)
+		return nil, err
+	}
+	return msg, nil
+}
+
+// WriteMessage 编码消息并以一帧写出
+func WriteMessage(w io.Writer, codec Codec, msg interface{}) error {
+	f, err := codec.Encode(msg)
+	if err != nil {
+		return err
+	}
+	return WriteFrame(w, f.ID, f.Payload)
+}
+
+// ReadMessage 读取下一帧并解码
+func ReadMessage(fr *FrameReader, codec Codec) (interface{}, error) {
+	f, err := fr.ReadFrame()
+	if err != nil {
+		return nil, err
+	}
+	return codec.Decode(f)
+}
@@ -0,0 +1,267 @@
+package Net
+
+import (
+	"encoding/binary"
+	"expvar"
+	"math"
+	"sync"
+	"time"
+)
+
+var (
+	heartbeatIntervals = expvar.NewMap("net.heartbeat.intervals") // 按区间统计选定的心跳间隔
+	heartbeatTimeouts  = expvar.NewInt("net.heartbeat.timeouts")
+)
+
+// HeartbeatConfig 自适应心跳参数
+type HeartbeatConfig struct {
+	MinInterval time.Duration // 差链路上的最短心跳间隔（尽快发现断线）
+	MaxInterval time.Duration // 好链路上的最长心跳间隔（节省带宽）
+	MinTimeout  time.Duration
+	MaxTimeout  time.Duration
+	MissedBeats int // 判定超时前允许丢失的心跳数（丢包率高时自动增加）
+	LossWindow  int // 计算丢包率的最近心跳数
+}
+
+// DefaultHeartbeatConfig 默认心跳参数
+func DefaultHeartbeatConfig() HeartbeatConfig {
+	return HeartbeatConfig{
+		MinInterval: time.Second,
+		MaxInterval: 10 * time.Second,
+		MinTimeout:  3 * time.Second,
+		MaxTimeout:  60 * time.Second,
+		MissedBeats: 3,
+		LossWindow:  32,
+	}
+}
+
+// HeartbeatStats 会话心跳状态快照
+type HeartbeatStats struct {
+	SRTT     time.Duration
+	RTTVar   time.Duration
+	Loss     float64
+	Interval time.Duration
+	Timeout  time.Duration
+}
+
+// Heartbeat 单个会话的自适应心跳：按 RTT 波动与丢包率调整发送间隔和超时
+type Heartbeat struct {
+	mu       sync.Mutex
+	cfg      HeartbeatConfig
+	srtt     time.Duration
+	rttvar   time.Duration
+	pending  map[uint32]time.Time // 未确认的心跳 seq -> 发送时间
+	outcomes []bool               // 最近心跳结果，true 表示丢失
+	next     int
+	filled   bool
+	lastSeen time.Time
+	interval time.Duration
+	timeout  time.Duration
+}
+
+// NewHeartbeat 创建会话心跳状态，未设置的参数使用默认值
+func NewHeartbeat(cfg HeartbeatConfig) *Heartbeat {
+	def := DefaultHeartbeatConfig()
+	if cfg.MinInterval <= 0 {
+		cfg.MinInterval = def.MinInterval
+	}
+	if cfg.MaxInterval < cfg.MinInterval {
+		cfg.MaxInterval = max(def.MaxInterval, cfg.MinInterval)
+	}
+	if cfg.MinTimeout <= 0 {
+		cfg.MinTimeout = def.MinTimeout
+	}
+	if cfg.MaxTimeout < cfg.MinTimeout {
+		cfg.MaxTimeout = max(def.MaxTimeout, cfg.MinTimeout)
+	}
+	if cfg.MissedBeats <= 0 {
+		cfg.MissedBeats = def.MissedBeats
+	}
+	if cfg.LossWindow <= 0 {
+		cfg.LossWindow = def.LossWindow
+	}
+
+	h := &Heartbeat{
+		cfg:      cfg,
+		pending:  make(map[uint32]time.Time),
+		outcomes: make([]bool, cfg.LossWindow),
+		lastSeen: time.Now(),
+	}
+	// 尚无测量数据时按最保守的间隔探测
+	h.interval = cfg.MinInterval
+	h.timeout = clampDuration(cfg.MinInterval*time.Duration(cfg.MissedBeats), cfg.MinTimeout, cfg.MaxTimeout)
+	return h
+}
+
+// Sent 记录一次心跳发送；超过超时仍未确认的旧心跳计为丢失
+func (h *Heartbeat) Sent(seq uint32, now time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for s, at := range h.pending {
+		if now.Sub(at) > h.timeout {
+			delete(h.pending, s)
+			h.record(true)
+		}
+	}
+	h.pending[seq] = now
+}
+
+// Acked 收到心跳回应，更新 RTT 估计并重新计算间隔与超时
+func (h *Heartbeat) Acked(seq uint32, now time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.lastSeen = now
+	sent, ok := h.pending[seq]
+	if !ok {
+		return
+	}
+	delete(h.pending, seq)
+	h.record(false)
+	h.sample(now.Sub(sent))
+	h.adjust()
+}
+
+// Received 收到任意入站数据，刷新活跃时间
+func (h *Heartbeat) Received(now time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.lastSeen = now
+}
+
+// Interval 当前心跳发送间隔
+func (h *Heartbeat) Interval() time.Duration {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.interval
+}
+
+// Timeout 当前判定断线的静默时长
+func (h *Heartbeat) Timeout() time.Duration {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.timeout
+}
+
+// Expired 距最后一次收到数据是否已超过超时
+func (h *Heartbeat) Expired(now time.Time) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if now.Sub(h.lastSeen) <= h.timeout {
+		return false
+	}
+	heartbeatTimeouts.Add(1)
+	return true
+}
+
+// Stats 返回心跳状态快照
+func (h *Heartbeat) Stats() HeartbeatStats {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return HeartbeatStats{
+		SRTT:     h.srtt,
+		RTTVar:   h.rttvar,
+		Loss:     h.loss(),
+		Interval: h.interval,
+		Timeout:  h.timeout,
+	}
+}
+
+// sample 按 RFC 6298 平滑 RTT 与波动
+func (h *Heartbeat) sample(rtt time.Duration) {
+	if h.srtt == 0 {
+		h.srtt = rtt
+		h.rttvar = rtt / 2
+		return
+	}
+	diff := h.srtt - rtt
+	if diff < 0 {
+		diff = -diff
+	}
+	h.rttvar = (3*h.rttvar + diff) / 4
+	h.srtt = (7*h.srtt + rtt) / 8
+}
+
+func (h *Heartbeat) record(lost bool) {
+	h.outcomes[h.next] = lost
+	h.next = (h.next + 1) % len(h.outcomes)
+	if h.next == 0 {
+		h.filled = true
+	}
+}
+
+func (h *Heartbeat) loss() float64 {
+	n := h.next
+	if h.filled {
+		n = len(h.outcomes)
+	}
+	if n == 0 {
+		return 0
+	}
+	lost := 0
+	for _, l := range h.outcomes[:n] {
+		if l {
+			lost++
+		}
+	}
+	return float64(lost) / float64(n)
+}
+
+// adjust 链路越稳定（波动小、丢包少）间隔越长；丢包越多允许丢失的心跳越多，避免误判断线
+func (h *Heartbeat) adjust() {
+	loss := h.loss()
+	quality := 1 - math.Min(1, loss*4)
+	if h.srtt > 0 {
+		quality /= 1 + float64(h.rttvar)/float64(h.srtt)
+	}
+
+	span := h.cfg.MaxInterval - h.cfg.MinInterval
+	interval := h.cfg.MinInterval + time.Duration(float64(span)*quality)
+
+	beats := h.cfg.MissedBeats + int(math.Ceil(loss*10))
+	timeout := interval*time.Duration(beats) + h.srtt + 4*h.rttvar
+
+	h.interval = interval
+	h.timeout = clampDuration(timeout, h.cfg.MinTimeout, h.cfg.MaxTimeout)
+	heartbeatIntervals.Add(intervalBucket(interval), 1)
+}
+
+// intervalBucket 心跳间隔的统计区间（按秒取整）
+func intervalBucket(d time.Duration) string {
+	return (d.Round(time.Second)).String()
+}
+
+func clampDuration(d, lo, hi time.Duration) time.Duration {
+	if d < lo {
+		return lo
+	}
+	if d > hi {
+		return hi
+	}
+	return d
+}
+
+// 心跳控制帧的保留消息 ID，负载为 4 字节序号（大端）；收到 Ping 的一端以相同序号回 Pong
+const (
+	PingMessageID uint32 = 0xFFFFFFFE
+	PongMessageID uint32 = 0xFFFFFFFF
+)
+
+// IsControlID 是否为保留的控制帧 ID（心跳、AckMessageID 与 ClosingMessageID），应用消息不得使用
+func IsControlID(id uint32) bool {
+	return id >= ClosingMessageID
+}
+
+// AppendHeartbeatFrame 追加一个心跳控制帧
+func AppendHeartbeatFrame(dst []byte, id, seq uint32) []byte {
+	return AppendFrame(dst, id, binary.BigEndian.AppendUint32(nil, seq))
+}
+
+// HeartbeatSeq 心跳控制帧中的序号，负载格式不对时返回 false
+func HeartbeatSeq(f Frame) (uint32, bool) {
+	if len(f.Payload) != 4 {
+		return 0, false
+	}
+	return binary.BigEndian.Uint32(f.Payload), true
+}
@@ -0,0 +1,363 @@
+package Net
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"expvar"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"zdopt/ZdoptServer/Pb"
+)
+
+// 观察者可订阅的只读流
+const (
+	StreamRooms   = "rooms"   // 房间创建、销毁与人数变化
+	StreamMatches = "matches" // 对局开始、结束与结算
+	StreamLoad    = "load"    // 节点负载与指标采样
+)
+
+var (
+	ErrReadOnlySession       = errors.New("read-only observer session")
+	ErrInvalidObserverToken  = errors.New("invalid observer token")
+	ErrObserverTokenExpired  = errors.New("observer token expired")
+	ErrStreamNotPermitted    = errors.New("stream not permitted for observer")
+	ErrObserverLimitExceeded = errors.New("too many observer sessions")
+
+	errObserverTokenMalformed = fmt.Errorf("%w: malformed", ErrInvalidObserverToken)
+	errObserverTokenSignature = fmt.Errorf("%w: signature mismatch", ErrInvalidObserverToken)
+
+	observerEvents   = expvar.NewMap("net.observer.events") // sent / dropped / rejected_commands
+	observerSessions = expvar.NewInt("net.observer.sessions")
+)
+
+// SessionClass 会话类别：玩家会话可以发送玩法指令，观察者会话只能订阅只读流
+type SessionClass int
+
+const (
+	ClassPlayer SessionClass = iota
+	ClassObserver
+)
+
+func (c SessionClass) String() string {
+	switch c {
+	case ClassPlayer:
+		return "player"
+	case ClassObserver:
+		return "observer"
+	default:
+		return fmt.Sprintf("SessionClass(%d)", int(c))
+	}
+}
+
+type sessionClassKey struct{}
+
+// WithSessionClass 把会话类别放入 ctx
+func WithSessionClass(ctx context.Context, c SessionClass) context.Context {
+	return context.WithValue(ctx, sessionClassKey{}, c)
+}
+
+// SessionClassFrom 读取会话类别，未设置时为玩家
+func SessionClassFrom(ctx context.Context) SessionClass {
+	c, _ := ctx.Value(sessionClassKey{}).(SessionClass)
+	return c
+}
+
+// CheckCommand 玩法指令入口调用：观察者会话返回 ErrReadOnlySession
+func CheckCommand(ctx context.Context) error {
+	if SessionClassFrom(ctx) == ClassObserver {
+		observerEvents.Add("rejected_commands", 1)
+		return ErrReadOnlySession
+	}
+	return nil
+}
+
+// ObserverConfig 观察者参数，零值字段使用默认值
+type ObserverConfig struct {
+	Key         []byte        // 令牌签名密钥，为空时随机生成（仅本节点签发的令牌有效）
+	TokenTTL    time.Duration // 令牌有效期
+	Rate        float64       // 每个观察者每秒最多推送的事件数
+	Burst       int           // 突发上限
+	Queue       int           // 每个观察者的发送队列长度，满时丢弃
+	MaxSessions int           // 观察者会话上限，避免仪表盘挤占玩家连接
+	Encode      func(*Pb.ObserverEvent) ([]byte, error)
+}
+
+// DefaultObserverConfig 默认参数：令牌 24 小时，每秒 50 条、突发 200 条，最多 32 个观察者
+func DefaultObserverConfig() ObserverConfig {
+	return ObserverConfig{
+		TokenTTL:    24 * time.Hour,
+		Rate:        50,
+		Burst:       200,
+		Queue:       256,
+		MaxSessions: 32,
+		Encode:      func(e *Pb.ObserverEvent) ([]byte, error) { return Pb.Serialize(e) },
+	}
+}
+
+// ObserverClaims 令牌中的授权范围
+type ObserverClaims struct {
+	Name    string
+	Streams []string
+	Expires time.Time
+}
+
+// Permits 是否授权订阅该流
+func (c ObserverClaims) Permits(stream string) bool {
+	return slices.Contains(c.Streams, stream)
+}
+
+// ObserverHub 观察者会话管理：按令牌授权订阅只读流，事件按观察者独立限流与排队推送
+type ObserverHub struct {
+	cfg       ObserverConfig
+	mu        sync.RWMutex
+	observers map[*Observer]struct{}
+	seq       sync.Map // stream -> *atomic.Uint64
+}
+
+// NewObserverHub 创建观察者管理，未设置的参数使用默认值
+func NewObserverHub(cfg ObserverConfig) *ObserverHub {
+	def := DefaultObserverConfig()
+	if cfg.TokenTTL <= 0 {
+		cfg.TokenTTL = def.TokenTTL
+	}
+	if cfg.Rate <= 0 {
+		cfg.Rate = def.Rate
+	}
+	if cfg.Burst <= 0 {
+		cfg.Burst = def.Burst
+	}
+	if cfg.Queue <= 0 {
+		cfg.Queue = def.Queue
+	}
+	if cfg.MaxSessions <= 0 {
+		cfg.MaxSessions = def.MaxSessions
+	}
+	if cfg.Encode == nil {
+		cfg.Encode = def.Encode
+	}
+	if len(cfg.Key) == 0 {
+		cfg.Key = make([]byte, 32)
+		_, _ = rand.Read(cfg.Key)
+	}
+	return &ObserverHub{cfg: cfg, observers: make(map[*Observer]struct{})}
+}
+
+// IssueToken 签发观察者令牌：名称、授权的流与过期时间，HMAC-SHA256 签名
+func (h *ObserverHub) IssueToken(name string, streams ...string) string {
+	var exp [8]byte
+	binary.BigEndian.PutUint64(exp[:], uint64(time.Now().Add(h.cfg.TokenTTL).Unix()))
+	payload := append(exp[:], name+"\x00"+strings.Join(streams, ",")...)
+	return base64.RawURLEncoding.EncodeToString(payload) + "." + base64.RawURLEncoding.EncodeToString(h.sign(payload))
+}
+
+// VerifyToken 校验令牌，返回授权范围
+func (h *ObserverHub) VerifyToken(token string) (ObserverClaims, error) {
+	body, sig, ok := strings.Cut(token, ".")
+	if !ok {
+		return ObserverClaims{}, errObserverTokenMalformed
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(body)
+	if err != nil || len(payload) < 8 {
+		return ObserverClaims{}, errObserverTokenMalformed
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(mac, h.sign(payload)) {
+		return ObserverClaims{}, errObserverTokenSignature
+	}
+	claims := ObserverClaims{Expires: time.Unix(int64(binary.BigEndian.Uint64(payload[:8])), 0)}
+	if time.Now().After(claims.Expires) {
+		return ObserverClaims{}, ErrObserverTokenExpired
+	}
+	name, streams, _ := strings.Cut(string(payload[8:]), "\x00")
+	claims.Name = name
+	if streams != "" {
+		claims.Streams = strings.Split(streams, ",")
+	}
+	return claims, nil
+}
+
+func (h *ObserverHub) sign(payload []byte) []byte {
+	mac := hmac.New(sha256.New, h.cfg.Key)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+// Attach 处理观察者握手：校验令牌，申请的流必须都在授权范围内（未申请时订阅全部授权的流）
+func (h *ObserverHub) Attach(s Session, hello *Pb.ObserverHello) (*Observer, error) {
+	claims, err := h.VerifyToken(hello.GetToken())
+	if err != nil {
+		return nil, err
+	}
+	streams := hello.GetStreams()
+	if len(streams) == 0 {
+		streams = claims.Streams
+	}
+	for _, stream := range streams {
+		if !claims.Permits(stream) {
+			return nil, fmt.Errorf("%w: %s", ErrStreamNotPermitted, stream)
+		}
+	}
+
+	o := &Observer{
+		hub:     h,
+		session: s,
+		claims:  claims,
+		streams: slices.Clone(streams),
+		queue:   make(chan []byte, h.cfg.Queue),
+		done:    make(chan struct{}),
+		tokens:  float64(h.cfg.Burst),
+		last:    time.Now(),
+	}
+	h.mu.Lock()
+	if len(h.observers) >= h.cfg.MaxSessions {
+		h.mu.Unlock()
+		return nil, ErrObserverLimitExceeded
+	}
+	h.observers[o] = struct{}{}
+	h.mu.Unlock()
+	observerSessions.Add(1)
+	go o.writeLoop()
+	return o, nil
+}
+
+// Publish 向订阅了该流的观察者推送事件；单个观察者超过限流或队列已满时丢弃，不阻塞发布方
+func (h *ObserverHub) Publish(stream string, data []byte) {
+	v, _ := h.seq.LoadOrStore(stream, new(atomic.Uint64))
+	event := &Pb.ObserverEvent{
+		Stream: stream,
+		Seq:    v.(*atomic.Uint64).Add(1),
+		Data:   data,
+		UnixMs: time.Now().UnixMilli(),
+	}
+	var frame []byte
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for o := range h.observers {
+		if !o.subscribed(stream) {
+			continue
+		}
+		if frame == nil {
+			var err error
+			if frame, err = h.cfg.Encode(event); err != nil {
+				return
+			}
+		}
+		o.offer(frame)
+	}
+}
+
+// Observers 当前观察者会话数
+func (h *ObserverHub) Observers() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.observers)
+}
+
+func (h *ObserverHub) detach(o *Observer) {
+	h.mu.Lock()
+	_, ok := h.observers[o]
+	delete(h.observers, o)
+	h.mu.Unlock()
+	if ok {
+		observerSessions.Add(-1)
+	}
+}
+
+// Observer 一个观察者会话：只接收订阅流的事件，不接受任何玩法指令
+type Observer struct {
+	hub     *ObserverHub
+	session Session
+	claims  ObserverClaims
+	streams []string
+	queue   chan []byte
+	done    chan struct{}
+	once    sync.Once
+
+	mu     sync.Mutex // 保护令牌桶
+	tokens float64
+	last   time.Time
+}
+
+// Name 令牌中的观察者名称
+func (o *Observer) Name() string {
+	return o.claims.Name
+}
+
+// Streams 订阅的流
+func (o *Observer) Streams() []string {
+	return slices.Clone(o.streams)
+}
+
+// Class 会话类别
+func (o *Observer) Class() SessionClass {
+	return ClassObserver
+}
+
+// Inbound 观察者发来的任何消息都被拒绝（订阅只在握手时确定）
+func (o *Observer) Inbound([]byte) error {
+	observerEvents.Add("rejected_commands", 1)
+	return ErrReadOnlySession
+}
+
+// Close 注销并关闭会话
+func (o *Observer) Close() error {
+	var err error
+	o.once.Do(func() {
+		o.hub.detach(o)
+		close(o.done)
+		err = o.session.Close()
+	})
+	return err
+}
+
+func (o *Observer) subscribed(stream string) bool {
+	return slices.Contains(o.streams, stream)
+}
+
+// offer 按令牌桶限流后放入发送队列
+func (o *Observer) offer(frame []byte) {
+	o.mu.Lock()
+	now := time.Now()
+	cfg := o.hub.cfg
+	o.tokens = min(o.tokens+now.Sub(o.last).Seconds()*cfg.Rate, float64(cfg.Burst))
+	o.last = now
+	allowed := o.tokens >= 1
+	if allowed {
+		o.tokens--
+	}
+	o.mu.Unlock()
+	if !allowed {
+		observerEvents.Add("dropped", 1)
+		return
+	}
+	select {
+	case o.queue <- frame:
+	default:
+		observerEvents.Add("dropped", 1)
+	}
+}
+
+// writeLoop 逐帧写出，写失败时关闭会话
+func (o *Observer) writeLoop() {
+	for {
+		select {
+		case <-o.done:
+			return
+		case frame := <-o.queue:
+			if _, err := o.session.Write(frame); err != nil {
+				_ = o.Close()
+				return
+			}
+			observerEvents.Add("sent", 1)
+		}
+	}
+}
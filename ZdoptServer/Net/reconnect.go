@@ -0,0 +1,261 @@
+package Net
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"expvar"
+	"fmt"
+	mrand "math/rand/v2"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"zdopt/ZdoptServer/Pb"
+)
+
+// 重连原因
+const (
+	ReasonDrain       = "drain"
+	ReasonMigrate     = "migrate"
+	ReasonMaintenance = "maintenance"
+	ReasonShutdown    = "shutdown"
+)
+
+var (
+	ErrSessionNotFound    = errors.New("session not found")
+	ErrInvalidResumeToken = errors.New("invalid resume token")
+	ErrResumeTokenExpired = errors.New("resume token expired")
+	ErrDrainIncomplete    = errors.New("drain incomplete")
+
+	reconnectHints = expvar.NewMap("net.reconnect.hints") // 按原因统计已发送的重连提示
+)
+
+// ClosingMessageID 服务端关闭通知控制帧，负载为序列化的 Pb.Reconnect：对端应停止发送，连接关闭后按提示重连
+const ClosingMessageID uint32 = 0xFFFFFFFC
+
+// AppendClosingFrame 追加一个关闭通知控制帧
+func AppendClosingFrame(dst []byte, hint *Pb.Reconnect) ([]byte, error) {
+	payload, err := Pb.Serialize(hint)
+	if err != nil {
+		return dst, err
+	}
+	return AppendFrame(dst, ClosingMessageID, payload), nil
+}
+
+// ClosingHint 解析关闭通知控制帧中的重连提示
+func ClosingHint(f Frame) (*Pb.Reconnect, error) {
+	return Pb.Deserialize[*Pb.Reconnect](f.Payload)
+}
+
+// Session 可接收重连提示的连接（*kcp.UDPSession 满足）
+type Session interface {
+	Write(b []byte) (int, error)
+	Close() error
+}
+
+// DrainConfig 排空参数，零值字段使用默认值
+type DrainConfig struct {
+	Endpoint   string        // 默认重连目标，空表示重连原入口
+	RetryAfter time.Duration // 最短重连等待
+	Jitter     time.Duration // 在 RetryAfter 之上随机附加，避免全部客户端同时重连
+	TokenTTL   time.Duration // 恢复令牌有效期
+	Linger     time.Duration // 发送提示后到关闭连接的等待，让提示先送达
+	Key        []byte        // 恢复令牌签名密钥，集群内各节点需一致；为空时随机生成（仅本节点可验证）
+	Encode     func(*Pb.Reconnect) ([]byte, error)
+}
+
+// DefaultDrainConfig 默认排空参数
+func DefaultDrainConfig() DrainConfig {
+	return DrainConfig{
+		RetryAfter: 2 * time.Second,
+		Jitter:     3 * time.Second,
+		TokenTTL:   5 * time.Minute,
+		Linger:     200 * time.Millisecond,
+		Encode:     func(msg *Pb.Reconnect) ([]byte, error) { return Pb.Serialize(msg) },
+	}
+}
+
+// Drainer 跟踪在线会话，在排空或迁移时先下发重连提示（原因、目标节点、恢复令牌、重连等待）再关闭连接
+type Drainer struct {
+	cfg      DrainConfig
+	mu       sync.Mutex
+	sessions map[string]Session
+	draining atomic.Bool
+}
+
+// NewDrainer 创建排空器，未设置的参数使用默认值
+func NewDrainer(cfg DrainConfig) *Drainer {
+	def := DefaultDrainConfig()
+	if cfg.RetryAfter <= 0 {
+		cfg.RetryAfter = def.RetryAfter
+	}
+	if cfg.Jitter <= 0 {
+		cfg.Jitter = def.Jitter
+	}
+	if cfg.TokenTTL <= 0 {
+		cfg.TokenTTL = def.TokenTTL
+	}
+	if cfg.Linger <= 0 {
+		cfg.Linger = def.Linger
+	}
+	if cfg.Encode == nil {
+		cfg.Encode = def.Encode
+	}
+	if len(cfg.Key) == 0 {
+		cfg.Key = make([]byte, 32)
+		_, _ = rand.Read(cfg.Key)
+	}
+	return &Drainer{cfg: cfg, sessions: make(map[string]Session)}
+}
+
+// Track 登记会话，返回的函数在会话正常结束时调用以注销
+func (d *Drainer) Track(id string, s Session) (untrack func()) {
+	d.mu.Lock()
+	d.sessions[id] = s
+	d.mu.Unlock()
+	return func() {
+		d.mu.Lock()
+		if d.sessions[id] == s {
+			delete(d.sessions, id)
+		}
+		d.mu.Unlock()
+	}
+}
+
+// Sessions 当前登记的会话数
+func (d *Drainer) Sessions() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return len(d.sessions)
+}
+
+// Draining 是否正在排空（此时应拒绝新连接）
+func (d *Drainer) Draining() bool {
+	return d.draining.Load()
+}
+
+// Hint 为会话生成重连提示，endpoint 为空时使用默认重连目标
+func (d *Drainer) Hint(id, reason, endpoint, message string) *Pb.Reconnect {
+	if endpoint == "" {
+		endpoint = d.cfg.Endpoint
+	}
+	retry := d.cfg.RetryAfter
+	if d.cfg.Jitter > 0 {
+		retry += time.Duration(mrand.Int64N(int64(d.cfg.Jitter)))
+	}
+	return &Pb.Reconnect{
+		Reason:       reason,
+		Endpoint:     endpoint,
+		ResumeToken:  d.IssueToken(id),
+		RetryAfterMs: uint32(retry / time.Millisecond),
+		Message:      message,
+	}
+}
+
+// ClosingFrame 为会话生成带重连提示的关闭通知控制帧（见 AppendClosingFrame），供自行管理连接的传输层在排空时下发
+func (d *Drainer) ClosingFrame(id, reason, endpoint, message string) ([]byte, error) {
+	hint := d.Hint(id, reason, endpoint, message)
+	frame, err := AppendClosingFrame(nil, hint)
+	if err != nil {
+		return nil, err
+	}
+	reconnectHints.Add(hint.Reason, 1)
+	return frame, nil
+}
+
+// Migrate 通知单个会话迁移到 endpoint 后关闭连接
+func (d *Drainer) Migrate(id, endpoint, message string) error {
+	d.mu.Lock()
+	s, ok := d.sessions[id]
+	delete(d.sessions, id)
+	d.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrSessionNotFound, id)
+	}
+	err := d.notify(s, d.Hint(id, ReasonMigrate, endpoint, message))
+	time.AfterFunc(d.cfg.Linger, func() { _ = s.Close() })
+	return err
+}
+
+// Drain 标记排空，向全部会话下发重连提示，等待 Linger 后关闭连接。
+// ctx 结束时立即关闭剩余连接并返回 ErrDrainIncomplete
+func (d *Drainer) Drain(ctx context.Context, reason, message string) error {
+	d.draining.Store(true)
+	d.mu.Lock()
+	sessions := d.sessions
+	d.sessions = make(map[string]Session)
+	d.mu.Unlock()
+
+	var errs error
+	for id, s := range sessions {
+		if ctx.Err() != nil {
+			break
+		}
+		if err := d.notify(s, d.Hint(id, reason, "", message)); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("session %s: %w", id, err))
+		}
+	}
+
+	timer := time.NewTimer(d.cfg.Linger)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+		errs = errors.Join(errs, fmt.Errorf("%w: %v", ErrDrainIncomplete, ctx.Err()))
+	}
+	for _, s := range sessions {
+		_ = s.Close()
+	}
+	return errs
+}
+
+func (d *Drainer) notify(s Session, hint *Pb.Reconnect) error {
+	data, err := d.cfg.Encode(hint)
+	if err != nil {
+		return err
+	}
+	if _, err := s.Write(data); err != nil {
+		return err
+	}
+	reconnectHints.Add(hint.Reason, 1)
+	return nil
+}
+
+// IssueToken 签发恢复令牌：会话 ID 与过期时间，HMAC-SHA256 签名
+func (d *Drainer) IssueToken(id string) string {
+	var exp [8]byte
+	binary.BigEndian.PutUint64(exp[:], uint64(time.Now().Add(d.cfg.TokenTTL).Unix()))
+	payload := append(exp[:], id...)
+	return base64.RawURLEncoding.EncodeToString(payload) + "." + base64.RawURLEncoding.EncodeToString(d.sign(payload))
+}
+
+// VerifyToken 校验恢复令牌，返回会话 ID
+func (d *Drainer) VerifyToken(token string) (string, error) {
+	body, sig, ok := strings.Cut(token, ".")
+	if !ok {
+		return "", ErrInvalidResumeToken
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(body)
+	if err != nil || len(payload) < 8 {
+		return "", ErrInvalidResumeToken
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(mac, d.sign(payload)) {
+		return "", ErrInvalidResumeToken
+	}
+	if time.Now().Unix() > int64(binary.BigEndian.Uint64(payload[:8])) {
+		return "", ErrResumeTokenExpired
+	}
+	return string(payload[8:]), nil
+}
+
+func (d *Drainer) sign(payload []byte) []byte {
+	h := hmac.New(sha256.New, d.cfg.Key)
+	h.Write(payload)
+	return h.Sum(nil)
+}
@@ -0,0 +1,82 @@
+package Net
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"sync"
+)
+
+// AckMessageID 客户端确认控制帧，负载为 4 字节序号（大端）：已按序收到的最后一条应用消息，
+// 服务端据此释放重发缓冲
+const AckMessageID uint32 = 0xFFFFFFFD
+
+// AppendAckFrame 追加一个确认控制帧
+func AppendAckFrame(dst []byte, seq uint32) []byte {
+	return AppendHeartbeatFrame(dst, AckMessageID, seq)
+}
+
+// NewResumeToken 生成不可猜测的会话恢复令牌
+func NewResumeToken() string {
+	b := make([]byte, 18)
+	_, _ = rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// ResumeBuffer 会话出站应用消息的重发缓冲：按发送顺序从 1 编号，保存对端尚未确认的整帧，
+// 超过容量时丢弃最早的（之后从更早序号恢复将失败）
+type ResumeBuffer struct {
+	mu     sync.Mutex
+	frames [][]byte
+	first  uint32 // frames[0] 的序号
+	max    int
+}
+
+// NewResumeBuffer 创建最多保存 max 帧的重发缓冲
+func NewResumeBuffer(max int) *ResumeBuffer {
+	return &ResumeBuffer{first: 1, max: max}
+}
+
+// Push 记录一帧，返回其序号；frame 入缓冲后不得修改
+func (b *ResumeBuffer) Push(frame []byte) uint32 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(b.frames) >= b.max {
+		b.frames[0] = nil
+		b.frames = b.frames[1:]
+		b.first++
+	}
+	b.frames = append(b.frames, frame)
+	return b.first + uint32(len(b.frames)) - 1
+}
+
+// Ack 释放序号不大于 seq 的帧
+func (b *ResumeBuffer) Ack(seq uint32) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	n := int(int64(seq) - int64(b.first) + 1)
+	if n <= 0 {
+		return
+	}
+	n = min(n, len(b.frames))
+	clear(b.frames[:n])
+	b.frames = b.frames[n:]
+	b.first += uint32(n)
+}
+
+// Since 序号大于 seq 的全部帧；其中有帧已被丢弃或 seq 超过已发送序号时返回 false
+func (b *ResumeBuffer) Since(seq uint32) ([][]byte, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	last := b.first + uint32(len(b.frames)) - 1
+	if seq+1 < b.first || seq > last {
+		return nil, false
+	}
+	return append([][]byte(nil), b.frames[seq+1-b.first:]...), true
+}
+
+// Len 未确认的帧数
+func (b *ResumeBuffer) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.frames)
+}
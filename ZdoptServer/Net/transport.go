@@ -0,0 +1,315 @@
+package Net
+
+import (
+	"crypto/sha1"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+
+	"github.com/xtaci/kcp-go"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+var (
+	ErrUnknownCipher  = errors.New("unknown kcp cipher")
+	ErrMissingKey     = errors.New("kcp cipher requires a key")
+	ErrInvalidTLS     = errors.New("invalid tls config")
+	ErrUnknownVersion = errors.New("unknown tls version")
+	ErrUnknownProfile = errors.New("unknown kcp profile")
+	ErrInvalidKCP     = errors.New("invalid kcp config")
+	ErrUnknownNetwork = errors.New("unknown transport network")
+)
+
+// 加密方式
+const (
+	CipherNone    = "none"
+	CipherAES     = "aes" // AES-256
+	CipherAES128  = "aes-128"
+	CipherAES192  = "aes-192"
+	CipherSalsa20 = "salsa20"
+	CipherSM4     = "sm4"
+	CipherTwofish = "twofish"
+)
+
+// 会话传输：KCP（可靠 UDP，默认）或 TCP（可选 TLS，供 UDP 受限的网络使用）
+const (
+	NetworkKCP = "kcp"
+	NetworkTCP = "tcp"
+)
+
+// pbkdf2Iterations 预共享密钥派生迭代次数，两端必须一致
+const pbkdf2Iterations = 4096
+
+// TransportConfig 传输层参数：KCP 内置分组加密、FEC 与协议调优，TCP 监听的 TLS
+type TransportConfig struct {
+	Cipher       string // 见 Cipher* 常量，空为 none
+	Key          string // 预共享密钥，经 PBKDF2 派生为加密密钥
+	Salt         string // 派生盐，空时使用 "zdopt"
+	DataShards   int
+	ParityShards int
+	KCP          KCPConfig // 零值时使用 DefaultKCPConfig
+	TLS          TLSConfig
+}
+
+// KCP 调优档位，各档位均开启快速重传并关闭拥塞控制
+const (
+	KCPNormal = "normal" // 40ms 刷新
+	KCPFast   = "fast"   // 30ms 刷新
+	KCPTurbo  = "turbo"  // 无延迟模式，10ms 刷新
+)
+
+// KCPConfig 每个 KCP 会话的协议参数，对应 SetNoDelay / SetWindowSize / SetMtu
+type KCPConfig struct {
+	NoDelay      bool
+	Interval     int // 内部刷新间隔（毫秒）
+	Resend       int // 跳过该数量的 ACK 即快速重传，0 关闭
+	NoCongestion bool
+	SndWnd       int
+	RcvWnd       int
+	MTU          int
+	AckNoDelay   bool // 收到数据立即回 ACK，不等下次刷新
+	FEC          bool // 关闭时忽略 DataShards / ParityShards
+}
+
+// DefaultKCPConfig 默认参数：turbo 档位，开启 FEC
+func DefaultKCPConfig() KCPConfig {
+	c, _ := KCPProfile(KCPTurbo)
+	return c
+}
+
+// KCPProfile 按档位名称返回参数
+func KCPProfile(name string) (KCPConfig, error) {
+	c := KCPConfig{SndWnd: 256, RcvWnd: 256, MTU: 1350, FEC: true}
+	switch name {
+	case KCPNormal:
+		c.Interval, c.Resend, c.NoCongestion = 40, 2, true
+	case KCPFast:
+		c.Interval, c.Resend, c.NoCongestion = 30, 2, true
+	case KCPTurbo, "":
+		c.NoDelay, c.Interval, c.Resend, c.NoCongestion = true, 10, 2, true
+	default:
+		return KCPConfig{}, fmt.Errorf("%w: %s", ErrUnknownProfile, name)
+	}
+	return c, nil
+}
+
+func (c KCPConfig) withDefaults() KCPConfig {
+	if c == (KCPConfig{}) {
+		return DefaultKCPConfig()
+	}
+	def := DefaultKCPConfig()
+	if c.Interval <= 0 {
+		c.Interval = def.Interval
+	}
+	if c.SndWnd <= 0 {
+		c.SndWnd = def.SndWnd
+	}
+	if c.RcvWnd <= 0 {
+		c.RcvWnd = def.RcvWnd
+	}
+	if c.MTU <= 0 {
+		c.MTU = def.MTU
+	}
+	return c
+}
+
+// Validate 校验刷新间隔与 MTU 范围
+func (c KCPConfig) Validate() error {
+	c = c.withDefaults()
+	if c.Interval < 10 || c.Interval > 5000 {
+		return fmt.Errorf("%w: interval %dms out of [10, 5000]", ErrInvalidKCP, c.Interval)
+	}
+	if c.MTU < 50 || c.MTU > 1500 {
+		return fmt.Errorf("%w: mtu %d out of [50, 1500]", ErrInvalidKCP, c.MTU)
+	}
+	if c.Resend < 0 {
+		return fmt.Errorf("%w: resend %d", ErrInvalidKCP, c.Resend)
+	}
+	return nil
+}
+
+// Apply 把参数应用到会话，监听端对每个 AcceptKCP 得到的会话调用（DialKCP 已自动应用）
+func (c KCPConfig) Apply(s *kcp.UDPSession) {
+	c = c.withDefaults()
+	s.SetNoDelay(boolInt(c.NoDelay), c.Interval, c.Resend, boolInt(c.NoCongestion))
+	s.SetWindowSize(c.SndWnd, c.RcvWnd)
+	s.SetMtu(c.MTU)
+	s.SetACKNoDelay(c.AckNoDelay)
+}
+
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// TLSConfig TCP 监听的 TLS 参数，CertFile 为空时不启用
+type TLSConfig struct {
+	CertFile     string
+	KeyFile      string
+	ClientCAFile string // 非空时要求并校验客户端证书
+	MinVersion   string // "1.2"（默认）或 "1.3"
+}
+
+// DefaultTransportConfig 默认参数：不加密，FEC 10/3，KCP turbo 档位
+func DefaultTransportConfig() TransportConfig {
+	return TransportConfig{Cipher: CipherNone, DataShards: 10, ParityShards: 3, KCP: DefaultKCPConfig()}
+}
+
+func (c TransportConfig) withDefaults() TransportConfig {
+	def := DefaultTransportConfig()
+	if c.Cipher == "" {
+		c.Cipher = def.Cipher
+	}
+	if c.Salt == "" {
+		c.Salt = "zdopt"
+	}
+	if c.DataShards <= 0 {
+		c.DataShards = def.DataShards
+	}
+	if c.ParityShards < 0 {
+		c.ParityShards = def.ParityShards
+	}
+	c.KCP = c.KCP.withDefaults()
+	if !c.KCP.FEC {
+		c.DataShards, c.ParityShards = 0, 0
+	}
+	return c
+}
+
+// Encrypted KCP 流量是否加密
+func (c TransportConfig) Encrypted() bool {
+	return c.Cipher != "" && c.Cipher != CipherNone
+}
+
+// Validate 校验加密方式、密钥、KCP 参数与 TLS 文件
+func (c TransportConfig) Validate() error {
+	if _, err := c.BlockCrypt(); err != nil {
+		return err
+	}
+	if err := c.KCP.Validate(); err != nil {
+		return err
+	}
+	if c.TLS.Enabled() {
+		if _, err := c.TLS.ServerConfig(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// BlockCrypt 按配置创建 KCP 分组加密，未加密时返回 nil
+func (c TransportConfig) BlockCrypt() (kcp.BlockCrypt, error) {
+	c = c.withDefaults()
+	if !c.Encrypted() {
+		return nil, nil
+	}
+	if c.Key == "" {
+		return nil, fmt.Errorf("%w: %s", ErrMissingKey, c.Cipher)
+	}
+	derive := func(n int) []byte {
+		return pbkdf2.Key([]byte(c.Key), []byte(c.Salt), pbkdf2Iterations, n, sha1.New)
+	}
+	switch c.Cipher {
+	case CipherAES:
+		return kcp.NewAESBlockCrypt(derive(32))
+	case CipherAES128:
+		return kcp.NewAESBlockCrypt(derive(16))
+	case CipherAES192:
+		return kcp.NewAESBlockCrypt(derive(24))
+	case CipherSalsa20:
+		return kcp.NewSalsa20BlockCrypt(derive(32))
+	case CipherSM4:
+		return kcp.NewSM4BlockCrypt(derive(16))
+	case CipherTwofish:
+		return kcp.NewTwofishBlockCrypt(derive(32))
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnknownCipher, c.Cipher)
+}
+
+// ListenKCP 按配置监听 KCP，接受的会话需经 KCP.Apply 应用协议参数
+func (c TransportConfig) ListenKCP(addr string) (*kcp.Listener, error) {
+	block, err := c.BlockCrypt()
+	if err != nil {
+		return nil, err
+	}
+	c = c.withDefaults()
+	return kcp.ListenWithOptions(addr, block, c.DataShards, c.ParityShards)
+}
+
+// DialKCP 按配置拨号 KCP 并应用协议参数，加密方式、密钥与 FEC 须与服务端一致
+func (c TransportConfig) DialKCP(addr string) (*kcp.UDPSession, error) {
+	block, err := c.BlockCrypt()
+	if err != nil {
+		return nil, err
+	}
+	c = c.withDefaults()
+	sess, err := kcp.DialWithOptions(addr, block, c.DataShards, c.ParityShards)
+	if err != nil {
+		return nil, err
+	}
+	c.KCP.Apply(sess)
+	return sess, nil
+}
+
+// ListenTCP 监听 TCP，配置了 TLS 时返回 TLS 监听（供 Actor.WithTCP 的 TCP 会话使用）
+func (c TransportConfig) ListenTCP(addr string) (net.Listener, error) {
+	var tlsCfg *tls.Config
+	if c.TLS.Enabled() {
+		var err error
+		if tlsCfg, err = c.TLS.ServerConfig(); err != nil {
+			return nil, err
+		}
+	}
+	ln, err := net.Listen("tcp", addr)
+	if err != nil || tlsCfg == nil {
+		return ln, err
+	}
+	return tls.NewListener(ln, tlsCfg), nil
+}
+
+// DialTCP 连接 ListenTCP 监听的服务端，tlsCfg 非 nil 时以 TLS 连接并完成 TLS 握手
+func (c TransportConfig) DialTCP(addr string, tlsCfg *tls.Config) (net.Conn, error) {
+	if tlsCfg == nil {
+		return net.Dial("tcp", addr)
+	}
+	return tls.Dial("tcp", addr, tlsCfg)
+}
+
+// Enabled 是否配置了证书
+func (c TLSConfig) Enabled() bool {
+	return c.CertFile != ""
+}
+
+// ServerConfig 加载证书并生成服务端 TLS 参数
+func (c TLSConfig) ServerConfig() (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTLS, err)
+	}
+	cfg := &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}
+	switch c.MinVersion {
+	case "", "1.2":
+	case "1.3":
+		cfg.MinVersion = tls.VersionTLS13
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownVersion, c.MinVersion)
+	}
+	if c.ClientCAFile != "" {
+		pem, err := os.ReadFile(c.ClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidTLS, err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("%w: no certificates in %s", ErrInvalidTLS, c.ClientCAFile)
+		}
+		cfg.ClientCAs = pool
+		cfg.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return cfg, nil
+}
@@ -0,0 +1,84 @@
+package ObjectPool
+
+import (
+	"expvar"
+	"sort"
+)
+
+var bufferOversize = expvar.NewInt("pool.buffers.oversize") // 超过最大档位、未经池分配的缓冲数
+
+// DefaultBufferTiers 默认缓冲档位：覆盖小消息、单次 KCP 读取、大状态同步与最大帧
+var DefaultBufferTiers = []int{512, 4 << 10, 16 << 10, 64 << 10}
+
+// Buffer 池化的字节缓冲：B 的容量为所在档位大小，持有者用完后调用 Release 归还
+type Buffer struct {
+	B    []byte
+	pool *GenericObjectPool[*Buffer] // 超过最大档位时为 nil
+}
+
+func (b *Buffer) OnGet() {}
+
+func (b *Buffer) OnRelease() {
+	b.B = b.B[:0]
+}
+
+// Release 归还缓冲，之后不得再访问 B；超过最大档位的缓冲交给 GC
+func (b *Buffer) Release() {
+	if b == nil || b.pool == nil {
+		return
+	}
+	_ = b.pool.ReleaseObj(b)
+}
+
+// BufferPool 按容量分档的字节缓冲池，每档一个 GenericObjectPool
+type BufferPool struct {
+	sizes []int
+	tiers []*GenericObjectPool[*Buffer]
+}
+
+// NewBufferPool 按给定档位（字节）创建缓冲池，未指定时使用 DefaultBufferTiers
+func NewBufferPool(sizes ...int) *BufferPool {
+	if len(sizes) == 0 {
+		sizes = DefaultBufferTiers
+	}
+	sizes = append([]int(nil), sizes...)
+	sort.Ints(sizes)
+	p := &BufferPool{sizes: sizes, tiers: make([]*GenericObjectPool[*Buffer], len(sizes))}
+	for i, size := range sizes {
+		var tier *GenericObjectPool[*Buffer]
+		tier = NewGenericObjectPool(func() *Buffer {
+			return &Buffer{B: make([]byte, 0, size), pool: tier}
+		})
+		p.tiers[i] = tier
+	}
+	return p
+}
+
+// Get 取容量不小于 n 的最小档位缓冲，B 的长度为 n；超过最大档位时直接分配
+func (p *BufferPool) Get(n int) *Buffer {
+	i := sort.SearchInts(p.sizes, n)
+	if i == len(p.sizes) {
+		bufferOversize.Add(1)
+		return &Buffer{B: make([]byte, n)}
+	}
+	b, ok := p.tiers[i].GetObj(nil, nil, nil).(*Buffer)
+	if !ok {
+		return &Buffer{B: make([]byte, n)}
+	}
+	b.B = b.B[:n]
+	return b
+}
+
+// Tiers 各档位大小
+func (p *BufferPool) Tiers() []int {
+	return append([]int(nil), p.sizes...)
+}
+
+// Stats 各档位热缓存统计，键为档位大小
+func (p *BufferPool) Stats() map[int]HotCacheStats {
+	stats := make(map[int]HotCacheStats, len(p.sizes))
+	for i, size := range p.sizes {
+		stats[size] = p.tiers[i].HotCacheStats()
+	}
+	return stats
+}
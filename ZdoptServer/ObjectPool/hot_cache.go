@@ -0,0 +1,231 @@
+package ObjectPool
+
+import (
+	"expvar"
+	"sync"
+	"time"
+	"zdopt/ZdoptServer/Logs"
+)
+
+var (
+	hotCacheAdvisories = expvar.NewMap("pool.hotcache.advisories")
+
+	poolLogger = Logs.CreateConsoleLogConfig("ObjectPool")
+)
+
+// HotCacheConfig 热缓存参数：热缓存是 sync.Pool 之前的定长后进先出缓存，不受 GC 清空影响
+type HotCacheConfig struct {
+	Size       int           // 热缓存容量，0 表示关闭
+	Window     time.Duration // 命中率统计窗口
+	MinHitRate float64       // 窗口内命中率低于该值时给出扩容建议
+	MinSamples int64         // 窗口内获取次数不足时不评估
+	OnAdvisory func(HotCacheAdvisory)
+}
+
+// DefaultHotCacheConfig 默认热缓存参数：容量 64，30 秒窗口内命中率低于 80% 时告警
+func DefaultHotCacheConfig() HotCacheConfig {
+	return HotCacheConfig{
+		Size:       64,
+		Window:     30 * time.Second,
+		MinHitRate: 0.8,
+		MinSamples: 1000,
+	}
+}
+
+// GenericPoolOption 泛型对象池构造选项
+type GenericPoolOption func(*HotCacheConfig)
+
+// WithHotCache 设置热缓存参数，未设置的字段使用默认值
+func WithHotCache(cfg HotCacheConfig) GenericPoolOption {
+	return func(c *HotCacheConfig) {
+		def := DefaultHotCacheConfig()
+		if cfg.Size < 0 {
+			cfg.Size = 0
+		}
+		if cfg.Window <= 0 {
+			cfg.Window = def.Window
+		}
+		if cfg.MinHitRate <= 0 {
+			cfg.MinHitRate = def.MinHitRate
+		}
+		if cfg.MinSamples <= 0 {
+			cfg.MinSamples = def.MinSamples
+		}
+		*c = cfg
+	}
+}
+
+// HotCacheAdvisory 热缓存容量建议：Suggested 大于 Size 表示过小，小于表示过大
+type HotCacheAdvisory struct {
+	Pool      string
+	Size      int
+	Suggested int
+	HitRate   float64
+	Gets      int64
+	Misses    int64
+	Overflows int64 // 归还时热缓存已满、落入 sync.Pool 的次数
+	Window    time.Duration
+}
+
+// HotCacheStats 热缓存当前状态与本窗口计数
+type HotCacheStats struct {
+	Size      int
+	Cached    int
+	Gets      int64
+	Misses    int64
+	Overflows int64
+}
+
+// hotCache 定长 LIFO 缓存及命中率统计
+type hotCache[T any] struct {
+	mu        sync.Mutex
+	cfg       HotCacheConfig
+	name      string
+	items     []T
+	gets      int64
+	misses    int64
+	overflows int64
+	peakUsed  int64 // 窗口内借出对象数峰值
+	peakIdle  int   // 窗口内缓存对象数峰值
+	since     time.Time
+	suggested int // 上次建议值，相同建议不重复发出
+}
+
+func newHotCache[T any](cfg HotCacheConfig) *hotCache[T] {
+	return &hotCache[T]{cfg: cfg, items: make([]T, 0, cfg.Size), since: time.Now()}
+}
+
+// get 从热缓存取对象，used 为本次借出后的借出数
+func (c *hotCache[T]) get(used int64) (obj T, ok bool, advice *HotCacheAdvisory) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.gets++
+	c.peakUsed = max(c.peakUsed, used)
+	if n := len(c.items); n > 0 {
+		obj, ok = c.items[n-1], true
+		var zero T
+		c.items[n-1] = zero
+		c.items = c.items[:n-1]
+	} else {
+		c.misses++
+	}
+	if c.gets&63 == 0 {
+		advice = c.evaluate(time.Now())
+	}
+	return obj, ok, advice
+}
+
+// put 归还对象到热缓存，已满时返回 false
+func (c *hotCache[T]) put(obj T) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.items) >= c.cfg.Size {
+		c.overflows++
+		return false
+	}
+	c.items = append(c.items, obj)
+	c.peakIdle = max(c.peakIdle, len(c.items))
+	return true
+}
+
+// evaluate 窗口结束时评估命中率与占用，需要调整时返回建议并开始新窗口
+func (c *hotCache[T]) evaluate(now time.Time) *HotCacheAdvisory {
+	elapsed := now.Sub(c.since)
+	if elapsed < c.cfg.Window {
+		return nil
+	}
+	defer c.resetWindow(now)
+	if c.gets < c.cfg.MinSamples {
+		return nil
+	}
+
+	hitRate := float64(c.gets-c.misses) / float64(c.gets)
+	suggested := c.cfg.Size
+	switch {
+	case hitRate < c.cfg.MinHitRate:
+		// 缓存需容纳窗口内同时借出的峰值，才能让归还的对象都被再次命中
+		suggested = max(int(c.peakUsed), c.cfg.Size*2)
+	case c.overflows == 0 && c.peakIdle < c.cfg.Size/4:
+		// 命中率达标但缓存长期只用到一小部分
+		suggested = max(c.peakIdle*2, 1)
+	}
+	if suggested == c.cfg.Size || suggested == c.suggested {
+		return nil
+	}
+	c.suggested = suggested
+	return &HotCacheAdvisory{
+		Pool:      c.name,
+		Size:      c.cfg.Size,
+		Suggested: suggested,
+		HitRate:   hitRate,
+		Gets:      c.gets,
+		Misses:    c.misses,
+		Overflows: c.overflows,
+		Window:    elapsed,
+	}
+}
+
+func (c *hotCache[T]) resetWindow(now time.Time) {
+	c.gets, c.misses, c.overflows, c.peakUsed = 0, 0, 0, 0
+	c.peakIdle = len(c.items)
+	c.since = now
+}
+
+// resize 调整容量并开始新的统计窗口，返回因缩容移出的对象
+func (c *hotCache[T]) resize(n int) []T {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cfg.Size = max(n, 0)
+	c.suggested = 0
+	defer c.resetWindow(time.Now())
+	if len(c.items) <= c.cfg.Size {
+		return nil
+	}
+	evicted := append([]T(nil), c.items[c.cfg.Size:]...)
+	var zero T
+	for i := c.cfg.Size; i < len(c.items); i++ {
+		c.items[i] = zero
+	}
+	c.items = c.items[:c.cfg.Size]
+	return evicted
+}
+
+// drain 取出全部缓存对象（关闭时销毁）
+func (c *hotCache[T]) drain() []T {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	items := c.items
+	c.items = nil
+	return items
+}
+
+func (c *hotCache[T]) stats() HotCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return HotCacheStats{
+		Size:      c.cfg.Size,
+		Cached:    len(c.items),
+		Gets:      c.gets,
+		Misses:    c.misses,
+		Overflows: c.overflows,
+	}
+}
+
+func (c *hotCache[T]) setName(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.name == "" {
+		c.name = name
+	}
+}
+
+// advise 发出容量建议：有回调时交给回调，否则记录日志
+func (c *hotCache[T]) advise(a *HotCacheAdvisory) {
+	hotCacheAdvisories.Add(a.Pool, 1)
+	if c.cfg.OnAdvisory != nil {
+		c.cfg.OnAdvisory(*a)
+		return
+	}
+	poolLogger.Printf("pool %q hot cache mis-sized: hit rate %.1f%% (%d gets, %d misses, %d overflows in %v), size %d, suggested %d",
+		a.Pool, a.HitRate*100, a.Gets, a.Misses, a.Overflows, a.Window.Round(time.Second), a.Size, a.Suggested)
+}
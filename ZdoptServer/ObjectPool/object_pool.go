@@ -1,6 +1,7 @@
 package ObjectPool
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"sync"
@@ -11,6 +12,7 @@ type ObjectPool[T ObjectBase] struct {
 	pool     []*PObject[T]
 	FreeList []*PObject[T]
 	mu       sync.Mutex
+	closed   bool
 }
 
 // NewObjectPool 创建对象池（泛型 T 必须实现 ObjectBase）
@@ -33,11 +35,15 @@ func (op *ObjectPool[T]) AddObj(factory func() T) *PObject[T] {
 	return pObj
 }
 
-// GetObj 从对象池获取对象（内部方法，保持泛型约束）
+// GetObj 从对象池获取对象（内部方法，保持泛型约束），池关闭后返回零值
 func (op *ObjectPool[T]) GetObj(init func(T), callback func(T), factory func() T) T {
 	op.mu.Lock()
 	defer op.mu.Unlock()
 
+	if op.closed {
+		var zero T
+		return zero
+	}
 	if len(op.FreeList) > 0 {
 		pObj := op.FreeList[0]
 		op.FreeList = op.FreeList[1:]
@@ -45,7 +51,9 @@ func (op *ObjectPool[T]) GetObj(init func(T), callback func(T), factory func() T
 		return obj
 	}
 
-	pObj := op.AddObj(factory)
+	// 已持有 op.mu，直接创建（不经 AddObj，也不放入空闲列表）
+	pObj := NewPObject(factory())
+	op.pool = append(op.pool, pObj)
 	obj, _ := pObj.GetObj(init, callback)
 	return obj
 }
@@ -57,19 +65,47 @@ func (op *ObjectPool[T]) ReleaseObj(obj T) error {
 
 	var released bool
 	for _, pObj := range op.pool {
+		if !pObj.Holds(obj) {
+			continue
+		}
 		if pObj.ReleaseObj(obj) {
 			op.FreeList = append(op.FreeList, pObj)
 			released = true
-			break
 		}
+		break
 	}
 
 	if !released {
 		return fmt.Errorf("object not found or already released: %v", obj)
 	}
+	if op.closed {
+		return destroy(obj)
+	}
 	return nil
 }
 
+// Close 拒绝新的获取，等待借出对象归还（ctx 结束时不再等待）后销毁空闲对象；
+// 之后归还的对象在归还时销毁
+func (op *ObjectPool[T]) Close(ctx context.Context) error {
+	op.mu.Lock()
+	op.closed = true
+	op.mu.Unlock()
+
+	err := waitDrained(ctx, func() bool {
+		op.mu.Lock()
+		defer op.mu.Unlock()
+		return len(op.FreeList) == len(op.pool)
+	})
+
+	op.mu.Lock()
+	defer op.mu.Unlock()
+	for _, pObj := range op.FreeList {
+		err = errors.Join(err, destroy(pObj.date))
+	}
+	op.FreeList = op.FreeList[:0]
+	return err
+}
+
 // GetObjAdapter 实现 Pool 接口的适配器方法（显式类型转换)
 func (op *ObjectPool[T]) GetObjAdapter(
 	init func(ObjectBase),
@@ -1,13 +1,22 @@
 package ObjectPool
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"reflect"
+	"sort"
 	"sync"
+	"sync/atomic"
+	"time"
+	"zdopt/ZdoptServer/Strict"
 )
 
 var (
 	ErrPoolAlreadyRegistered = errors.New("pool already registered")
 	ErrPoolNotFound          = errors.New("pool not found")
+	ErrDoubleRelease         = errors.New("object released twice")
+	ErrPoolClosed            = errors.New("pool closed")
 )
 
 type ObjectBase interface {
@@ -15,10 +24,21 @@ type ObjectBase interface {
 	OnRelease()
 }
 
+// Destroyer 池关闭时对象的可选销毁钩子（释放缓冲区、文件句柄等）
+type Destroyer interface {
+	Destroy() error
+}
+
+// Closer 可关闭的对象池：等待借出对象归还后销毁池中对象
+type Closer interface {
+	Close(ctx context.Context) error
+}
+
 // Manager 结构体用于管理多个对象池
 type Manager struct {
-	mu    sync.Mutex
-	pools map[string]Pool
+	mu     sync.Mutex
+	pools  map[string]Pool
+	closed bool
 }
 
 func NewManager() *Manager {
@@ -29,60 +49,220 @@ func NewManager() *Manager {
 
 // GenericObjectPool 结构体用于封装泛型对象池
 type GenericObjectPool[T ObjectBase] struct {
-	pool sync.Pool
+	pool        sync.Pool // 不设置 New，取空时调用 factory，关闭时可取尽
+	factory     func() T
+	released    sync.Map // 严格模式：已归还对象 -> 归还时堆栈
+	mu          sync.RWMutex
+	closed      bool
+	outstanding atomic.Int64 // 借出未归还的对象数
+	hot         *hotCache[T]
+}
+
+// NewGenericObjectPool 创建泛型对象池，默认启用 DefaultHotCacheConfig 热缓存
+func NewGenericObjectPool[T ObjectBase](factory func() T, opts ...GenericPoolOption) *GenericObjectPool[T] {
+	cfg := DefaultHotCacheConfig()
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return &GenericObjectPool[T]{factory: factory, hot: newHotCache[T](cfg)}
 }
 
-// NewGenericObjectPool 创建泛型对象池
-func NewGenericObjectPool[T ObjectBase](factory func() T) *GenericObjectPool[T] {
-	return &GenericObjectPool[T]{
-		pool: sync.Pool{
-			New: func() any {
-				return factory()
-			},
-		},
+// Warmup 预热：提前创建 n 个对象放入池中（先填满热缓存），避免开服瞬间集中分配
+func (gop *GenericObjectPool[T]) Warmup(n int) {
+	for i := 0; i < n; i++ {
+		obj := gop.factory()
+		if !gop.hot.put(obj) {
+			gop.pool.Put(obj)
+		}
 	}
 }
 
-// GetObj 实现Pool接口
+// ResizeHotCache 运行时调整热缓存容量，缩容移出的对象转入 sync.Pool
+func (gop *GenericObjectPool[T]) ResizeHotCache(n int) {
+	for _, obj := range gop.hot.resize(n) {
+		gop.pool.Put(obj)
+	}
+}
+
+// HotCacheStats 热缓存状态与当前统计窗口的计数
+func (gop *GenericObjectPool[T]) HotCacheStats() HotCacheStats {
+	return gop.hot.stats()
+}
+
+// setName 注册到管理器时记录池名称，用于容量建议
+func (gop *GenericObjectPool[T]) setName(name string) {
+	gop.hot.setName(name)
+}
+
+// GetObj 实现Pool接口，池关闭后返回 nil（经 GetPool 获取时先得到 ErrPoolClosed）
 func (gop *GenericObjectPool[T]) GetObj(
 	init func(ObjectBase),
 	callback func(ObjectBase),
 	factory func() ObjectBase,
 ) ObjectBase {
-	obj := gop.pool.Get().(T)
+	gop.mu.RLock()
+	defer gop.mu.RUnlock()
+	if gop.closed {
+		return nil
+	}
+	obj, ok, advice := gop.hot.get(gop.outstanding.Add(1))
+	if !ok {
+		if obj, ok = gop.pool.Get().(T); !ok {
+			obj = gop.factory()
+		}
+	}
+	if advice != nil {
+		gop.hot.advise(advice)
+	}
+	if Strict.Enabled() && comparable(obj) {
+		gop.released.Delete(any(obj))
+	}
 	obj.OnGet()
 	return obj
 }
 
+// ReleaseObj 归还对象，池关闭后直接销毁
 func (gop *GenericObjectPool[T]) ReleaseObj(obj ObjectBase) error {
 	tObj, ok := obj.(T)
 	if !ok {
 		return errors.New("object is not T")
 	}
+	if Strict.Enabled() && comparable(tObj) {
+		if first, loaded := gop.released.LoadOrStore(any(tObj), Strict.Stack()); loaded {
+			Strict.Failf("pool.double-release", "%T %p released twice; first release at:\n%s", tObj, any(tObj), first)
+			return fmt.Errorf("%w: %T", ErrDoubleRelease, tObj)
+		}
+	}
 	tObj.OnRelease()
-	gop.pool.Put(tObj)
+	gop.outstanding.Add(-1)
+
+	gop.mu.RLock()
+	defer gop.mu.RUnlock()
+	if gop.closed {
+		return destroy(tObj)
+	}
+	if !gop.hot.put(tObj) {
+		gop.pool.Put(tObj)
+	}
+	return nil
+}
+
+// Close 拒绝新的获取，等待借出对象归还（ctx 结束时不再等待）后销毁池中对象
+func (gop *GenericObjectPool[T]) Close(ctx context.Context) error {
+	gop.mu.Lock()
+	gop.closed = true
+	gop.mu.Unlock()
+
+	err := waitDrained(ctx, func() bool { return gop.outstanding.Load() <= 0 })
+	for _, obj := range gop.hot.drain() {
+		err = errors.Join(err, destroy(obj))
+	}
+	for {
+		obj, ok := gop.pool.Get().(T)
+		if !ok {
+			break
+		}
+		err = errors.Join(err, destroy(obj))
+	}
+	return err
+}
+
+// destroy 调用对象的 Destroy 钩子（如有）
+func destroy(obj any) error {
+	if d, ok := obj.(Destroyer); ok {
+		return d.Destroy()
+	}
+	return nil
+}
+
+// waitDrained 等待借出对象全部归还，ctx 结束时返回剩余未归还的错误
+func waitDrained(ctx context.Context, drained func() bool) error {
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	for !drained() {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("objects still in use: %w", ctx.Err())
+		case <-ticker.C:
+		}
+	}
 	return nil
 }
 
+// comparable 对象能否作为重复释放检测的键（指针等可比较类型）
+func comparable(obj any) bool {
+	t := reflect.TypeOf(obj)
+	return t != nil && t.Comparable()
+}
+
 // RegisterPool 注册和获取逻辑
 func RegisterPool(opm *Manager, name string, pool Pool) error {
 	opm.mu.Lock()
 	defer opm.mu.Unlock()
 
+	if opm.closed {
+		return ErrPoolClosed
+	}
 	if _, exists := opm.pools[name]; exists {
 		return ErrPoolAlreadyRegistered
 	}
+	if n, ok := pool.(interface{ setName(string) }); ok {
+		n.setName(name)
+	}
 	opm.pools[name] = pool
 	return nil
 }
 
+// GetPool 获取已注册的池，管理器关闭后返回 ErrPoolClosed
 func GetPool(opm *Manager, name string) (Pool, error) {
 	opm.mu.Lock()
 	defer opm.mu.Unlock()
 
+	if opm.closed {
+		return nil, fmt.Errorf("%w: %s", ErrPoolClosed, name)
+	}
 	pool, exists := opm.pools[name]
 	if !exists {
 		return nil, ErrPoolNotFound
 	}
 	return pool, nil
 }
+
+// ReleaseToPool 归还对象到指定池，管理器关闭后仍可归还（对象被销毁）
+func ReleaseToPool(opm *Manager, name string, obj ObjectBase) error {
+	opm.mu.Lock()
+	pool, exists := opm.pools[name]
+	opm.mu.Unlock()
+	if !exists {
+		return ErrPoolNotFound
+	}
+	return pool.ReleaseObj(obj)
+}
+
+// Close 关闭管理器：之后 GetPool 返回 ErrPoolClosed，各池等待借出对象归还后销毁对象。
+// 重复调用返回 nil
+func (opm *Manager) Close(ctx context.Context) error {
+	opm.mu.Lock()
+	if opm.closed {
+		opm.mu.Unlock()
+		return nil
+	}
+	opm.closed = true
+	names := make([]string, 0, len(opm.pools))
+	for name := range opm.pools {
+		names = append(names, name)
+	}
+	pools := opm.pools
+	opm.mu.Unlock()
+
+	sort.Strings(names)
+	var err error
+	for _, name := range names {
+		if c, ok := pools[name].(Closer); ok {
+			if cerr := c.Close(ctx); cerr != nil {
+				err = errors.Join(err, fmt.Errorf("close pool %s: %w", name, cerr))
+			}
+		}
+	}
+	return err
+}
@@ -2,6 +2,7 @@ package ObjectPool
 
 import (
 	"sync"
+	"zdopt/ZdoptServer/Strict"
 )
 
 // PObject 结构体用于封装池中的对象
@@ -11,6 +12,7 @@ type PObject[T any] struct {
 	init     func(T)
 	callback func(T)
 	mu       sync.Mutex
+	freedAt  []byte // 严格模式：最近一次归还时的堆栈
 }
 
 // NewPObject 创建PObject 实例
@@ -29,6 +31,7 @@ func (p *PObject[T]) GetObj(init func(T), callback func(T)) (T, bool) {
 	}
 
 	p.isUsing = true
+	p.freedAt = nil
 	p.init = init
 	p.callback = callback
 
@@ -38,16 +41,30 @@ func (p *PObject[T]) GetObj(init func(T), callback func(T)) (T, bool) {
 	return p.date, true
 }
 
-// ReleaseObj 释放对象回收池
+// Holds 是否封装了 obj
+func (p *PObject[T]) Holds(obj T) bool {
+	return any(p.date) == any(obj)
+}
+
+// ReleaseObj 释放对象回收池，对象不属于本封装或未被借出时返回 false
 func (p *PObject[T]) ReleaseObj(obj T) bool {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
-	if p.isUsing {
+	if !p.Holds(obj) {
+		return false
+	}
+	if !p.isUsing {
+		if Strict.Enabled() {
+			Strict.Failf("pool.double-release", "%T released twice; first release at:\n%s", obj, p.freedAt)
+		}
 		return false
 	}
 
 	p.isUsing = false
+	if Strict.Enabled() {
+		p.freedAt = Strict.Stack()
+	}
 	if p.callback != nil {
 		p.callback(obj)
 	}
@@ -0,0 +1,142 @@
+package Pb
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"sync"
+	"zdopt/ZdoptServer/Strict"
+)
+
+// EnvelopeHeaderSize 信封头长度：2 字节消息 ID（大端），其后为 protobuf 负载
+const EnvelopeHeaderSize = 2
+
+var (
+	ErrUnknownID     = errors.New("envelope id not registered")
+	ErrShortEnvelope = errors.New("envelope shorter than header")
+	ErrIDConflict    = errors.New("message id conflict")
+)
+
+// idRegistry 进程内唯一的消息 ID 表：信封、Net.PbCodec 的帧与 Actor.MessageRouter 的路由共用，
+// 同一类型只有一个 ID。按描述符（进程内唯一）查 ID，避免逐包比较类型全名
+var idRegistry = struct {
+	sync.RWMutex
+	byID   map[uint16]protoreflect.MessageType
+	byDesc map[protoreflect.MessageDescriptor]uint16
+}{
+	byID:   make(map[uint16]protoreflect.MessageType),
+	byDesc: make(map[protoreflect.MessageDescriptor]uint16),
+}
+
+// BindID 把已注册（RegisterType）的协议类型绑定到消息 ID，重复绑定相同的对应关系无效果；
+// ID 或类型已绑定到其他对象时返回 ErrIDConflict
+func BindID(id uint16, name string) error {
+	v, ok := typeRegistry.Load(protoreflect.FullName(name))
+	if !ok {
+		return fmt.Errorf("%w: %s not registered", ErrInvalidType, name)
+	}
+	typ := v.(protoreflect.MessageType)
+	desc := typ.Descriptor()
+
+	r := &idRegistry
+	r.Lock()
+	defer r.Unlock()
+	if old, ok := r.byID[id]; ok && old.Descriptor() != desc {
+		return fmt.Errorf("%w: id %d already bound to %s", ErrIDConflict, id, old.Descriptor().FullName())
+	}
+	if old, ok := r.byDesc[desc]; ok && old != id {
+		return fmt.Errorf("%w: %s already bound to id %d", ErrIDConflict, desc.FullName(), old)
+	}
+	r.byID[id] = typ
+	r.byDesc[desc] = id
+	return nil
+}
+
+// RegisterTypeWithID 注册协议类型并绑定消息 ID（RegisterType 后 BindID），线上格式只依赖 ID，两端须一致。
+// 绑定冲突时 panic（启动期的配置错误），需要返回错误时用 BindID
+func RegisterTypeWithID[T proto.Message](id uint16) {
+	var zero T
+	RegisterType[T]()
+	if err := BindID(id, TypeName(zero)); err != nil {
+		panic("Pb: " + err.Error())
+	}
+}
+
+// MessageID 消息类型绑定的 ID
+func MessageID(msg proto.Message) (uint16, bool) {
+	r := &idRegistry
+	r.RLock()
+	defer r.RUnlock()
+	id, ok := r.byDesc[msg.ProtoReflect().Descriptor()]
+	return id, ok
+}
+
+// IDOf 协议全名绑定的 ID
+func IDOf(name string) (uint16, bool) {
+	v, ok := typeRegistry.Load(protoreflect.FullName(name))
+	if !ok {
+		return 0, false
+	}
+	r := &idRegistry
+	r.RLock()
+	defer r.RUnlock()
+	id, ok := r.byDesc[v.(protoreflect.MessageType).Descriptor()]
+	return id, ok
+}
+
+// NameOf ID 绑定的协议全名
+func NameOf(id uint16) (string, bool) {
+	r := &idRegistry
+	r.RLock()
+	defer r.RUnlock()
+	typ, ok := r.byID[id]
+	if !ok {
+		return "", false
+	}
+	return string(typ.Descriptor().FullName()), true
+}
+
+// DeserializeByID 按绑定的消息 ID 反序列化，ID 未绑定时返回 ErrUnknownID
+func DeserializeByID(id uint16, data []byte) (proto.Message, error) {
+	r := &idRegistry
+	r.RLock()
+	typ, ok := r.byID[id]
+	r.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %d", ErrUnknownID, id)
+	}
+	msg := typ.New().Interface()
+	if err := proto.Unmarshal(data, msg); err != nil {
+		return nil, fmt.Errorf("deserialize %s failed: %w", typ.Descriptor().FullName(), err)
+	}
+	return msg, nil
+}
+
+// Pack 把消息封装为信封：ID 头 + 序列化负载，类型未绑定 ID 时返回 ErrInvalidType
+func Pack(msg proto.Message) ([]byte, error) {
+	id, ok := MessageID(msg)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s has no message id", ErrInvalidType, TypeName(msg))
+	}
+	buf := binary.BigEndian.AppendUint16(make([]byte, 0, EnvelopeHeaderSize+proto.Size(msg)), id)
+	data, err := proto.MarshalOptions{}.MarshalAppend(buf, msg)
+	if err != nil {
+		return nil, fmt.Errorf("pack %s failed: %w", TypeName(msg), err)
+	}
+	if Strict.Enabled() {
+		verifyRoundTrip(msg, data[EnvelopeHeaderSize:])
+	}
+	return data, nil
+}
+
+// Unpack 解析信封，返回消息与其 ID
+func Unpack(data []byte) (proto.Message, uint16, error) {
+	if len(data) < EnvelopeHeaderSize {
+		return nil, 0, ErrShortEnvelope
+	}
+	id := binary.BigEndian.Uint16(data)
+	msg, err := DeserializeByID(id, data[EnvelopeHeaderSize:])
+	return msg, id, err
+}
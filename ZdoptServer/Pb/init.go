@@ -1,6 +1,24 @@
 package Pb
 
 func init() {
+	// init.go 按文件名排序先于 mainPb.pb.go 初始化，需先确保协议描述已构建
+	file_mainPb_proto_init()
+
 	// 自动注册协议类型
 	RegisterType[*DataPacket]()
+	RegisterType[*ErrorResponse]()
+	RegisterType[*SchemaDigest]()
+	RegisterType[*SchemaMismatch]()
+	RegisterType[*ExportEvent]()
+	RegisterType[*ExportBatch]()
+	RegisterType[*Passthrough]()
+	RegisterType[*DataPushHello]()
+	RegisterType[*DataPushChunk]()
+	RegisterType[*DataPushAck]()
+	RegisterType[*ClientHello]()
+	RegisterType[*ServerHello]()
+	RegisterType[*Reconnect]()
+	RegisterType[*ObserverHello]()
+	RegisterType[*ObserverEvent]()
+	RegisterType[*MaintenanceNotice]()
 }
@@ -65,15 +65,1238 @@ func (x *DataPacket) GetContent() string {
 	return ""
 }
 
+// ErrorResponse 面向客户端的错误回包：稳定错误码 + 按会话语言本地化后的文本
+type ErrorResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          uint32                 `protobuf:"varint,1,opt,name=Code,proto3" json:"Code,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=Message,proto3" json:"Message,omitempty"`
+	Locale        string                 `protobuf:"bytes,3,opt,name=Locale,proto3" json:"Locale,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ErrorResponse) Reset() {
+	*x = ErrorResponse{}
+	mi := &file_mainPb_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ErrorResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ErrorResponse) ProtoMessage() {}
+
+func (x *ErrorResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_mainPb_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ErrorResponse.ProtoReflect.Descriptor instead.
+func (*ErrorResponse) Descriptor() ([]byte, []int) {
+	return file_mainPb_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ErrorResponse) GetCode() uint32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *ErrorResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *ErrorResponse) GetLocale() string {
+	if x != nil {
+		return x.Locale
+	}
+	return ""
+}
+
+// SchemaDigest 节点握手时交换的协议注册表摘要：消息全名 -> 字段哈希
+type SchemaDigest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Messages      map[string]uint64      `protobuf:"bytes,1,rep,name=Messages,proto3" json:"Messages,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SchemaDigest) Reset() {
+	*x = SchemaDigest{}
+	mi := &file_mainPb_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SchemaDigest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SchemaDigest) ProtoMessage() {}
+
+func (x *SchemaDigest) ProtoReflect() protoreflect.Message {
+	mi := &file_mainPb_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SchemaDigest.ProtoReflect.Descriptor instead.
+func (*SchemaDigest) Descriptor() ([]byte, []int) {
+	return file_mainPb_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *SchemaDigest) GetMessages() map[string]uint64 {
+	if x != nil {
+		return x.Messages
+	}
+	return nil
+}
+
+// SchemaMismatch 握手因协议不兼容被拒绝时下发的比对报告（以拒绝方为本端），随后关闭连接
+type SchemaMismatch struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Mismatched    []string               `protobuf:"bytes,1,rep,name=Mismatched,proto3" json:"Mismatched,omitempty"`       // 双方都有但字段定义不同的消息
+	MissingRemote []string               `protobuf:"bytes,2,rep,name=MissingRemote,proto3" json:"MissingRemote,omitempty"` // 拒绝方有、对端没有
+	MissingLocal  []string               `protobuf:"bytes,3,rep,name=MissingLocal,proto3" json:"MissingLocal,omitempty"`   // 对端有、拒绝方没有
+	Digest        *SchemaDigest          `protobuf:"bytes,4,opt,name=Digest,proto3" json:"Digest,omitempty"`               // 拒绝方的协议注册表摘要
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SchemaMismatch) Reset() {
+	*x = SchemaMismatch{}
+	mi := &file_mainPb_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SchemaMismatch) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SchemaMismatch) ProtoMessage() {}
+
+func (x *SchemaMismatch) ProtoReflect() protoreflect.Message {
+	mi := &file_mainPb_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SchemaMismatch.ProtoReflect.Descriptor instead.
+func (*SchemaMismatch) Descriptor() ([]byte, []int) {
+	return file_mainPb_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *SchemaMismatch) GetMismatched() []string {
+	if x != nil {
+		return x.Mismatched
+	}
+	return nil
+}
+
+func (x *SchemaMismatch) GetMissingRemote() []string {
+	if x != nil {
+		return x.MissingRemote
+	}
+	return nil
+}
+
+func (x *SchemaMismatch) GetMissingLocal() []string {
+	if x != nil {
+		return x.MissingLocal
+	}
+	return nil
+}
+
+func (x *SchemaMismatch) GetDigest() *SchemaDigest {
+	if x != nil {
+		return x.Digest
+	}
+	return nil
+}
+
+// ExportEvent 导出到外部分析系统的单条事件
+type ExportEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Topic         string                 `protobuf:"bytes,1,opt,name=Topic,proto3" json:"Topic,omitempty"`
+	Timestamp     int64                  `protobuf:"varint,2,opt,name=Timestamp,proto3" json:"Timestamp,omitempty"` // Unix 毫秒
+	Type          string                 `protobuf:"bytes,3,opt,name=Type,proto3" json:"Type,omitempty"`            // 负载的协议全名
+	Payload       []byte                 `protobuf:"bytes,4,opt,name=Payload,proto3" json:"Payload,omitempty"`      // 负载的 protobuf 编码
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExportEvent) Reset() {
+	*x = ExportEvent{}
+	mi := &file_mainPb_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExportEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportEvent) ProtoMessage() {}
+
+func (x *ExportEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_mainPb_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportEvent.ProtoReflect.Descriptor instead.
+func (*ExportEvent) Descriptor() ([]byte, []int) {
+	return file_mainPb_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ExportEvent) GetTopic() string {
+	if x != nil {
+		return x.Topic
+	}
+	return ""
+}
+
+func (x *ExportEvent) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+func (x *ExportEvent) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *ExportEvent) GetPayload() []byte {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+// ExportBatch 批量导出的事件
+type ExportBatch struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Node          string                 `protobuf:"bytes,1,opt,name=Node,proto3" json:"Node,omitempty"`
+	Events        []*ExportEvent         `protobuf:"bytes,2,rep,name=Events,proto3" json:"Events,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExportBatch) Reset() {
+	*x = ExportBatch{}
+	mi := &file_mainPb_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExportBatch) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportBatch) ProtoMessage() {}
+
+func (x *ExportBatch) ProtoReflect() protoreflect.Message {
+	mi := &file_mainPb_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportBatch.ProtoReflect.Descriptor instead.
+func (*ExportBatch) Descriptor() ([]byte, []int) {
+	return file_mainPb_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ExportBatch) GetNode() string {
+	if x != nil {
+		return x.Node
+	}
+	return ""
+}
+
+func (x *ExportBatch) GetEvents() []*ExportEvent {
+	if x != nil {
+		return x.Events
+	}
+	return nil
+}
+
+// Passthrough 网关透传帧：未在本地注册的消息按类型全名原样转发给后端节点
+type Passthrough struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Type          string                 `protobuf:"bytes,1,opt,name=Type,proto3" json:"Type,omitempty"`       // 负载的协议全名
+	Payload       []byte                 `protobuf:"bytes,2,opt,name=Payload,proto3" json:"Payload,omitempty"` // 负载的 protobuf 编码，网关不解码
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Passthrough) Reset() {
+	*x = Passthrough{}
+	mi := &file_mainPb_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Passthrough) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Passthrough) ProtoMessage() {}
+
+func (x *Passthrough) ProtoReflect() protoreflect.Message {
+	mi := &file_mainPb_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Passthrough.ProtoReflect.Descriptor instead.
+func (*Passthrough) Descriptor() ([]byte, []int) {
+	return file_mainPb_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Passthrough) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Passthrough) GetPayload() []byte {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+// DataPushHello 客户端上报本地数据表版本及未完成的传输进度（断线续传）
+type DataPushHello struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Versions      map[string]uint64      `protobuf:"bytes,1,rep,name=Versions,proto3" json:"Versions,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"` // 表名 -> 本地版本，0 表示没有
+	Partial       []*DataPushAck         `protobuf:"bytes,2,rep,name=Partial,proto3" json:"Partial,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DataPushHello) Reset() {
+	*x = DataPushHello{}
+	mi := &file_mainPb_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DataPushHello) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DataPushHello) ProtoMessage() {}
+
+func (x *DataPushHello) ProtoReflect() protoreflect.Message {
+	mi := &file_mainPb_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DataPushHello.ProtoReflect.Descriptor instead.
+func (*DataPushHello) Descriptor() ([]byte, []int) {
+	return file_mainPb_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *DataPushHello) GetVersions() map[string]uint64 {
+	if x != nil {
+		return x.Versions
+	}
+	return nil
+}
+
+func (x *DataPushHello) GetPartial() []*DataPushAck {
+	if x != nil {
+		return x.Partial
+	}
+	return nil
+}
+
+// DataPushChunk 数据表更新分片：Full 为 true 时拼接结果即新版本内容，否则为相对 FromVersion 的补丁
+type DataPushChunk struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Table         string                 `protobuf:"bytes,1,opt,name=Table,proto3" json:"Table,omitempty"`
+	FromVersion   uint64                 `protobuf:"varint,2,opt,name=FromVersion,proto3" json:"FromVersion,omitempty"`
+	ToVersion     uint64                 `protobuf:"varint,3,opt,name=ToVersion,proto3" json:"ToVersion,omitempty"`
+	Full          bool                   `protobuf:"varint,4,opt,name=Full,proto3" json:"Full,omitempty"`
+	Offset        uint64                 `protobuf:"varint,5,opt,name=Offset,proto3" json:"Offset,omitempty"` // 本分片在传输内容中的偏移
+	Total         uint64                 `protobuf:"varint,6,opt,name=Total,proto3" json:"Total,omitempty"`   // 传输内容总长度
+	Data          []byte                 `protobuf:"bytes,7,opt,name=Data,proto3" json:"Data,omitempty"`
+	Checksum      uint32                 `protobuf:"varint,8,opt,name=Checksum,proto3" json:"Checksum,omitempty"` // 新版本内容的 CRC32
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DataPushChunk) Reset() {
+	*x = DataPushChunk{}
+	mi := &file_mainPb_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DataPushChunk) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DataPushChunk) ProtoMessage() {}
+
+func (x *DataPushChunk) ProtoReflect() protoreflect.Message {
+	mi := &file_mainPb_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DataPushChunk.ProtoReflect.Descriptor instead.
+func (*DataPushChunk) Descriptor() ([]byte, []int) {
+	return file_mainPb_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *DataPushChunk) GetTable() string {
+	if x != nil {
+		return x.Table
+	}
+	return ""
+}
+
+func (x *DataPushChunk) GetFromVersion() uint64 {
+	if x != nil {
+		return x.FromVersion
+	}
+	return 0
+}
+
+func (x *DataPushChunk) GetToVersion() uint64 {
+	if x != nil {
+		return x.ToVersion
+	}
+	return 0
+}
+
+func (x *DataPushChunk) GetFull() bool {
+	if x != nil {
+		return x.Full
+	}
+	return false
+}
+
+func (x *DataPushChunk) GetOffset() uint64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *DataPushChunk) GetTotal() uint64 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *DataPushChunk) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *DataPushChunk) GetChecksum() uint32 {
+	if x != nil {
+		return x.Checksum
+	}
+	return 0
+}
+
+// DataPushAck 客户端确认已连续收到的字节数
+type DataPushAck struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Table         string                 `protobuf:"bytes,1,opt,name=Table,proto3" json:"Table,omitempty"`
+	FromVersion   uint64                 `protobuf:"varint,2,opt,name=FromVersion,proto3" json:"FromVersion,omitempty"`
+	ToVersion     uint64                 `protobuf:"varint,3,opt,name=ToVersion,proto3" json:"ToVersion,omitempty"`
+	Offset        uint64                 `protobuf:"varint,4,opt,name=Offset,proto3" json:"Offset,omitempty"`
+	Failed        bool                   `protobuf:"varint,5,opt,name=Failed,proto3" json:"Failed,omitempty"` // 补丁无法应用或校验失败，服务端改发完整内容
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DataPushAck) Reset() {
+	*x = DataPushAck{}
+	mi := &file_mainPb_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DataPushAck) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DataPushAck) ProtoMessage() {}
+
+func (x *DataPushAck) ProtoReflect() protoreflect.Message {
+	mi := &file_mainPb_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DataPushAck.ProtoReflect.Descriptor instead.
+func (*DataPushAck) Descriptor() ([]byte, []int) {
+	return file_mainPb_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *DataPushAck) GetTable() string {
+	if x != nil {
+		return x.Table
+	}
+	return ""
+}
+
+func (x *DataPushAck) GetFromVersion() uint64 {
+	if x != nil {
+		return x.FromVersion
+	}
+	return 0
+}
+
+func (x *DataPushAck) GetToVersion() uint64 {
+	if x != nil {
+		return x.ToVersion
+	}
+	return 0
+}
+
+func (x *DataPushAck) GetOffset() uint64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *DataPushAck) GetFailed() bool {
+	if x != nil {
+		return x.Failed
+	}
+	return false
+}
+
+// ClientHello 客户端握手：自报地域（或地理提示）及到各地域的实测往返延迟，用于就近分配节点；
+// Features 为客户端支持的协议特性及其最高版本
+type ClientHello struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Region        string                 `protobuf:"bytes,1,opt,name=Region,proto3" json:"Region,omitempty"`
+	RegionRTT     map[string]uint32      `protobuf:"bytes,2,rep,name=RegionRTT,proto3" json:"RegionRTT,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"` // 地域 -> 往返延迟（毫秒）
+	Features      map[string]uint32      `protobuf:"bytes,3,rep,name=Features,proto3" json:"Features,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`   // 特性 -> 最高版本
+	Dictionaries  []uint32               `protobuf:"varint,4,rep,packed,name=Dictionaries,proto3" json:"Dictionaries,omitempty"`                                                              // 本端持有的压缩字典 ID
+	Token         string                 `protobuf:"bytes,5,opt,name=Token,proto3" json:"Token,omitempty"`                                                                                    // 会话认证令牌，服务端配置了握手校验时必填
+	ResumeToken   string                 `protobuf:"bytes,6,opt,name=ResumeToken,proto3" json:"ResumeToken,omitempty"`                                                                        // 断线重连时携带上次 ServerHello 中的恢复令牌
+	LastSeq       uint32                 `protobuf:"varint,7,opt,name=LastSeq,proto3" json:"LastSeq,omitempty"`                                                                               // 上次连接收到的最后一条应用消息序号
+	Locales       []string               `protobuf:"bytes,8,rep,name=Locales,proto3" json:"Locales,omitempty"`                                                                                // 语言偏好，按优先级排列（如 zh-CN、en）
+	Schema        *SchemaDigest          `protobuf:"bytes,9,opt,name=Schema,proto3" json:"Schema,omitempty"`                                                                                  // 本端协议注册表摘要，服务端据此拒绝不兼容的客户端
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ClientHello) Reset() {
+	*x = ClientHello{}
+	mi := &file_mainPb_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ClientHello) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClientHello) ProtoMessage() {}
+
+func (x *ClientHello) ProtoReflect() protoreflect.Message {
+	mi := &file_mainPb_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClientHello.ProtoReflect.Descriptor instead.
+func (*ClientHello) Descriptor() ([]byte, []int) {
+	return file_mainPb_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ClientHello) GetRegion() string {
+	if x != nil {
+		return x.Region
+	}
+	return ""
+}
+
+func (x *ClientHello) GetRegionRTT() map[string]uint32 {
+	if x != nil {
+		return x.RegionRTT
+	}
+	return nil
+}
+
+func (x *ClientHello) GetFeatures() map[string]uint32 {
+	if x != nil {
+		return x.Features
+	}
+	return nil
+}
+
+func (x *ClientHello) GetDictionaries() []uint32 {
+	if x != nil {
+		return x.Dictionaries
+	}
+	return nil
+}
+
+func (x *ClientHello) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *ClientHello) GetResumeToken() string {
+	if x != nil {
+		return x.ResumeToken
+	}
+	return ""
+}
+
+func (x *ClientHello) GetLastSeq() uint32 {
+	if x != nil {
+		return x.LastSeq
+	}
+	return 0
+}
+
+func (x *ClientHello) GetLocales() []string {
+	if x != nil {
+		return x.Locales
+	}
+	return nil
+}
+
+func (x *ClientHello) GetSchema() *SchemaDigest {
+	if x != nil {
+		return x.Schema
+	}
+	return nil
+}
+
+// ServerHello 服务端握手回复：双方都支持的特性及协商后的版本，双方只启用其中的特性
+type ServerHello struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Features      map[string]uint32      `protobuf:"bytes,1,rep,name=Features,proto3" json:"Features,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"` // 特性 -> 协商版本
+	Dictionaries  []uint32               `protobuf:"varint,2,rep,packed,name=Dictionaries,proto3" json:"Dictionaries,omitempty"`                                                            // 双方都持有的压缩字典 ID
+	ResumeToken   string                 `protobuf:"bytes,3,opt,name=ResumeToken,proto3" json:"ResumeToken,omitempty"`                                                                      // 断线后在有效期内凭此恢复会话，空表示不支持
+	Resumed       bool                   `protobuf:"varint,4,opt,name=Resumed,proto3" json:"Resumed,omitempty"`                                                                             // 本次为恢复会话，错过的消息已在本回复之前重发
+	UdpKey        uint64                 `protobuf:"fixed64,5,opt,name=UdpKey,proto3" json:"UdpKey,omitempty"`                                                                              // UDP 通道密钥（见 Net.DialDatagram），0 表示未开启
+	UdpPort       uint32                 `protobuf:"varint,6,opt,name=UdpPort,proto3" json:"UdpPort,omitempty"`                                                                             // UDP 通道端口，与 KCP 同一主机
+	Locale        string                 `protobuf:"bytes,7,opt,name=Locale,proto3" json:"Locale,omitempty"`                                                                                // 协商的会话语言，错误回包按此本地化
+	Schema        *SchemaDigest          `protobuf:"bytes,8,opt,name=Schema,proto3" json:"Schema,omitempty"`                                                                                // 服务端协议注册表摘要，客户端据此判定兼容性
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ServerHello) Reset() {
+	*x = ServerHello{}
+	mi := &file_mainPb_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ServerHello) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ServerHello) ProtoMessage() {}
+
+func (x *ServerHello) ProtoReflect() protoreflect.Message {
+	mi := &file_mainPb_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ServerHello.ProtoReflect.Descriptor instead.
+func (*ServerHello) Descriptor() ([]byte, []int) {
+	return file_mainPb_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ServerHello) GetFeatures() map[string]uint32 {
+	if x != nil {
+		return x.Features
+	}
+	return nil
+}
+
+func (x *ServerHello) GetDictionaries() []uint32 {
+	if x != nil {
+		return x.Dictionaries
+	}
+	return nil
+}
+
+func (x *ServerHello) GetResumeToken() string {
+	if x != nil {
+		return x.ResumeToken
+	}
+	return ""
+}
+
+func (x *ServerHello) GetResumed() bool {
+	if x != nil {
+		return x.Resumed
+	}
+	return false
+}
+
+func (x *ServerHello) GetUdpKey() uint64 {
+	if x != nil {
+		return x.UdpKey
+	}
+	return 0
+}
+
+func (x *ServerHello) GetUdpPort() uint32 {
+	if x != nil {
+		return x.UdpPort
+	}
+	return 0
+}
+
+func (x *ServerHello) GetLocale() string {
+	if x != nil {
+		return x.Locale
+	}
+	return ""
+}
+
+func (x *ServerHello) GetSchema() *SchemaDigest {
+	if x != nil {
+		return x.Schema
+	}
+	return nil
+}
+
+// Reconnect 断开前下发的重连提示：节点排空、会话迁移或停机时发送，随后关闭连接
+type Reconnect struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Reason        string                 `protobuf:"bytes,1,opt,name=Reason,proto3" json:"Reason,omitempty"`              // drain / migrate / maintenance / shutdown
+	Endpoint      string                 `protobuf:"bytes,2,opt,name=Endpoint,proto3" json:"Endpoint,omitempty"`          // 建议重连的节点地址，空表示重连原入口
+	ResumeToken   string                 `protobuf:"bytes,3,opt,name=ResumeToken,proto3" json:"ResumeToken,omitempty"`    // 重连时携带以恢复会话
+	RetryAfterMs  uint32                 `protobuf:"varint,4,opt,name=RetryAfterMs,proto3" json:"RetryAfterMs,omitempty"` // 至少等待该时长后重连
+	Message       string                 `protobuf:"bytes,5,opt,name=Message,proto3" json:"Message,omitempty"`            // 可展示给玩家的说明
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Reconnect) Reset() {
+	*x = Reconnect{}
+	mi := &file_mainPb_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Reconnect) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Reconnect) ProtoMessage() {}
+
+func (x *Reconnect) ProtoReflect() protoreflect.Message {
+	mi := &file_mainPb_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Reconnect.ProtoReflect.Descriptor instead.
+func (*Reconnect) Descriptor() ([]byte, []int) {
+	return file_mainPb_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *Reconnect) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *Reconnect) GetEndpoint() string {
+	if x != nil {
+		return x.Endpoint
+	}
+	return ""
+}
+
+func (x *Reconnect) GetResumeToken() string {
+	if x != nil {
+		return x.ResumeToken
+	}
+	return ""
+}
+
+func (x *Reconnect) GetRetryAfterMs() uint32 {
+	if x != nil {
+		return x.RetryAfterMs
+	}
+	return 0
+}
+
+func (x *Reconnect) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+// ObserverHello 观察者（仪表盘等只读消费方）握手：携带签发的令牌与申请订阅的只读流
+type ObserverHello struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Token         string                 `protobuf:"bytes,1,opt,name=Token,proto3" json:"Token,omitempty"`
+	Streams       []string               `protobuf:"bytes,2,rep,name=Streams,proto3" json:"Streams,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ObserverHello) Reset() {
+	*x = ObserverHello{}
+	mi := &file_mainPb_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ObserverHello) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ObserverHello) ProtoMessage() {}
+
+func (x *ObserverHello) ProtoReflect() protoreflect.Message {
+	mi := &file_mainPb_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ObserverHello.ProtoReflect.Descriptor instead.
+func (*ObserverHello) Descriptor() ([]byte, []int) {
+	return file_mainPb_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *ObserverHello) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *ObserverHello) GetStreams() []string {
+	if x != nil {
+		return x.Streams
+	}
+	return nil
+}
+
+// ObserverEvent 推送给观察者的只读事件
+type ObserverEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Stream        string                 `protobuf:"bytes,1,opt,name=Stream,proto3" json:"Stream,omitempty"`
+	Seq           uint64                 `protobuf:"varint,2,opt,name=Seq,proto3" json:"Seq,omitempty"` // 流内序号，观察者据此发现被限流丢弃的事件
+	Data          []byte                 `protobuf:"bytes,3,opt,name=Data,proto3" json:"Data,omitempty"`
+	UnixMs        int64                  `protobuf:"varint,4,opt,name=UnixMs,proto3" json:"UnixMs,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ObserverEvent) Reset() {
+	*x = ObserverEvent{}
+	mi := &file_mainPb_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ObserverEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ObserverEvent) ProtoMessage() {}
+
+func (x *ObserverEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_mainPb_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ObserverEvent.ProtoReflect.Descriptor instead.
+func (*ObserverEvent) Descriptor() ([]byte, []int) {
+	return file_mainPb_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *ObserverEvent) GetStream() string {
+	if x != nil {
+		return x.Stream
+	}
+	return ""
+}
+
+func (x *ObserverEvent) GetSeq() uint64 {
+	if x != nil {
+		return x.Seq
+	}
+	return 0
+}
+
+func (x *ObserverEvent) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *ObserverEvent) GetUnixMs() int64 {
+	if x != nil {
+		return x.UnixMs
+	}
+	return 0
+}
+
+// MaintenanceNotice 维护倒计时通知：窗口开始前在各通知点广播给在线玩家，开始时 RemainingMs 为 0
+type MaintenanceNotice struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	WindowID      int64                  `protobuf:"varint,1,opt,name=WindowID,proto3" json:"WindowID,omitempty"`
+	StartUnixMs   int64                  `protobuf:"varint,2,opt,name=StartUnixMs,proto3" json:"StartUnixMs,omitempty"` // 窗口开始时间（Unix 毫秒）
+	DurationMs    int64                  `protobuf:"varint,3,opt,name=DurationMs,proto3" json:"DurationMs,omitempty"`   // 预计维护时长
+	RemainingMs   int64                  `protobuf:"varint,4,opt,name=RemainingMs,proto3" json:"RemainingMs,omitempty"` // 距开始的剩余时间，0 表示已开始
+	Reason        string                 `protobuf:"bytes,5,opt,name=Reason,proto3" json:"Reason,omitempty"`            // 可展示给玩家的说明
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MaintenanceNotice) Reset() {
+	*x = MaintenanceNotice{}
+	mi := &file_mainPb_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MaintenanceNotice) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MaintenanceNotice) ProtoMessage() {}
+
+func (x *MaintenanceNotice) ProtoReflect() protoreflect.Message {
+	mi := &file_mainPb_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MaintenanceNotice.ProtoReflect.Descriptor instead.
+func (*MaintenanceNotice) Descriptor() ([]byte, []int) {
+	return file_mainPb_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *MaintenanceNotice) GetWindowID() int64 {
+	if x != nil {
+		return x.WindowID
+	}
+	return 0
+}
+
+func (x *MaintenanceNotice) GetStartUnixMs() int64 {
+	if x != nil {
+		return x.StartUnixMs
+	}
+	return 0
+}
+
+func (x *MaintenanceNotice) GetDurationMs() int64 {
+	if x != nil {
+		return x.DurationMs
+	}
+	return 0
+}
+
+func (x *MaintenanceNotice) GetRemainingMs() int64 {
+	if x != nil {
+		return x.RemainingMs
+	}
+	return 0
+}
+
+func (x *MaintenanceNotice) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
 var File_mainPb_proto protoreflect.FileDescriptor
 
 var file_mainPb_proto_rawDesc = string([]byte{
 	0x0a, 0x0c, 0x6d, 0x61, 0x69, 0x6e, 0x50, 0x62, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x26,
 	0x0a, 0x0a, 0x44, 0x61, 0x74, 0x61, 0x50, 0x61, 0x63, 0x6b, 0x65, 0x74, 0x12, 0x18, 0x0a, 0x07,
 	0x43, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x43,
-	0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x42, 0x16, 0x5a, 0x14, 0x7a, 0x64, 0x6f, 0x70, 0x74, 0x2f,
-	0x5a, 0x64, 0x6f, 0x70, 0x74, 0x53, 0x65, 0x72, 0x76, 0x65, 0x72, 0x2f, 0x50, 0x62, 0x62, 0x06,
-	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x22, 0x55, 0x0a, 0x0d, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x43, 0x6f, 0x64, 0x65, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x04, 0x43, 0x6f, 0x64, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x4d,
+	0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x4d, 0x65,
+	0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x4c, 0x6f, 0x63, 0x61, 0x6c, 0x65, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x4c, 0x6f, 0x63, 0x61, 0x6c, 0x65, 0x22, 0x84, 0x01,
+	0x0a, 0x0c, 0x53, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x44, 0x69, 0x67, 0x65, 0x73, 0x74, 0x12, 0x37,
+	0x0a, 0x08, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x1b, 0x2e, 0x53, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x44, 0x69, 0x67, 0x65, 0x73, 0x74, 0x2e,
+	0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x08, 0x4d,
+	0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x1a, 0x3b, 0x0a, 0x0d, 0x4d, 0x65, 0x73, 0x73, 0x61,
+	0x67, 0x65, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61,
+	0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65,
+	0x3a, 0x02, 0x38, 0x01, 0x22, 0xa1, 0x01, 0x0a, 0x0e, 0x53, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x4d,
+	0x69, 0x73, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x12, 0x1e, 0x0a, 0x0a, 0x4d, 0x69, 0x73, 0x6d, 0x61,
+	0x74, 0x63, 0x68, 0x65, 0x64, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0a, 0x4d, 0x69, 0x73,
+	0x6d, 0x61, 0x74, 0x63, 0x68, 0x65, 0x64, 0x12, 0x24, 0x0a, 0x0d, 0x4d, 0x69, 0x73, 0x73, 0x69,
+	0x6e, 0x67, 0x52, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0d,
+	0x4d, 0x69, 0x73, 0x73, 0x69, 0x6e, 0x67, 0x52, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x12, 0x22, 0x0a,
+	0x0c, 0x4d, 0x69, 0x73, 0x73, 0x69, 0x6e, 0x67, 0x4c, 0x6f, 0x63, 0x61, 0x6c, 0x18, 0x03, 0x20,
+	0x03, 0x28, 0x09, 0x52, 0x0c, 0x4d, 0x69, 0x73, 0x73, 0x69, 0x6e, 0x67, 0x4c, 0x6f, 0x63, 0x61,
+	0x6c, 0x12, 0x25, 0x0a, 0x06, 0x44, 0x69, 0x67, 0x65, 0x73, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x0d, 0x2e, 0x53, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x44, 0x69, 0x67, 0x65, 0x73, 0x74,
+	0x52, 0x06, 0x44, 0x69, 0x67, 0x65, 0x73, 0x74, 0x22, 0x6f, 0x0a, 0x0b, 0x45, 0x78, 0x70, 0x6f,
+	0x72, 0x74, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x54, 0x6f, 0x70, 0x69, 0x63,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x54, 0x6f, 0x70, 0x69, 0x63, 0x12, 0x1c, 0x0a,
+	0x09, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03,
+	0x52, 0x09, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x12, 0x12, 0x0a, 0x04, 0x54,
+	0x79, 0x70, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x54, 0x79, 0x70, 0x65, 0x12,
+	0x18, 0x0a, 0x07, 0x50, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0c,
+	0x52, 0x07, 0x50, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x22, 0x47, 0x0a, 0x0b, 0x45, 0x78, 0x70,
+	0x6f, 0x72, 0x74, 0x42, 0x61, 0x74, 0x63, 0x68, 0x12, 0x12, 0x0a, 0x04, 0x4e, 0x6f, 0x64, 0x65,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x4e, 0x6f, 0x64, 0x65, 0x12, 0x24, 0x0a, 0x06,
+	0x45, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x45,
+	0x78, 0x70, 0x6f, 0x72, 0x74, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x52, 0x06, 0x45, 0x76, 0x65, 0x6e,
+	0x74, 0x73, 0x22, 0x3b, 0x0a, 0x0b, 0x50, 0x61, 0x73, 0x73, 0x74, 0x68, 0x72, 0x6f, 0x75, 0x67,
+	0x68, 0x12, 0x12, 0x0a, 0x04, 0x54, 0x79, 0x70, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x04, 0x54, 0x79, 0x70, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x50, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x07, 0x50, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x22,
+	0xae, 0x01, 0x0a, 0x0d, 0x44, 0x61, 0x74, 0x61, 0x50, 0x75, 0x73, 0x68, 0x48, 0x65, 0x6c, 0x6c,
+	0x6f, 0x12, 0x38, 0x0a, 0x08, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x01, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x1c, 0x2e, 0x44, 0x61, 0x74, 0x61, 0x50, 0x75, 0x73, 0x68, 0x48, 0x65,
+	0x6c, 0x6c, 0x6f, 0x2e, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x45, 0x6e, 0x74, 0x72,
+	0x79, 0x52, 0x08, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x26, 0x0a, 0x07, 0x50,
+	0x61, 0x72, 0x74, 0x69, 0x61, 0x6c, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x44,
+	0x61, 0x74, 0x61, 0x50, 0x75, 0x73, 0x68, 0x41, 0x63, 0x6b, 0x52, 0x07, 0x50, 0x61, 0x72, 0x74,
+	0x69, 0x61, 0x6c, 0x1a, 0x3b, 0x0a, 0x0d, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x45,
+	0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01,
+	0x22, 0xd7, 0x01, 0x0a, 0x0d, 0x44, 0x61, 0x74, 0x61, 0x50, 0x75, 0x73, 0x68, 0x43, 0x68, 0x75,
+	0x6e, 0x6b, 0x12, 0x14, 0x0a, 0x05, 0x54, 0x61, 0x62, 0x6c, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x05, 0x54, 0x61, 0x62, 0x6c, 0x65, 0x12, 0x20, 0x0a, 0x0b, 0x46, 0x72, 0x6f, 0x6d,
+	0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0b, 0x46,
+	0x72, 0x6f, 0x6d, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x1c, 0x0a, 0x09, 0x54, 0x6f,
+	0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x09, 0x54,
+	0x6f, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x12, 0x0a, 0x04, 0x46, 0x75, 0x6c, 0x6c,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x04, 0x46, 0x75, 0x6c, 0x6c, 0x12, 0x16, 0x0a, 0x06,
+	0x4f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x04, 0x52, 0x06, 0x4f, 0x66,
+	0x66, 0x73, 0x65, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x54, 0x6f, 0x74, 0x61, 0x6c, 0x18, 0x06, 0x20,
+	0x01, 0x28, 0x04, 0x52, 0x05, 0x54, 0x6f, 0x74, 0x61, 0x6c, 0x12, 0x12, 0x0a, 0x04, 0x44, 0x61,
+	0x74, 0x61, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x44, 0x61, 0x74, 0x61, 0x12, 0x1a,
+	0x0a, 0x08, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x73, 0x75, 0x6d, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0d,
+	0x52, 0x08, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x73, 0x75, 0x6d, 0x22, 0x93, 0x01, 0x0a, 0x0b, 0x44,
+	0x61, 0x74, 0x61, 0x50, 0x75, 0x73, 0x68, 0x41, 0x63, 0x6b, 0x12, 0x14, 0x0a, 0x05, 0x54, 0x61,
+	0x62, 0x6c, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x54, 0x61, 0x62, 0x6c, 0x65,
+	0x12, 0x20, 0x0a, 0x0b, 0x46, 0x72, 0x6f, 0x6d, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0b, 0x46, 0x72, 0x6f, 0x6d, 0x56, 0x65, 0x72, 0x73, 0x69,
+	0x6f, 0x6e, 0x12, 0x1c, 0x0a, 0x09, 0x54, 0x6f, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x09, 0x54, 0x6f, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e,
+	0x12, 0x16, 0x0a, 0x06, 0x4f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x04,
+	0x52, 0x06, 0x4f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x46, 0x61, 0x69, 0x6c,
+	0x65, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x46, 0x61, 0x69, 0x6c, 0x65, 0x64,
+	0x22, 0xca, 0x03, 0x0a, 0x0b, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x48, 0x65, 0x6c, 0x6c, 0x6f,
+	0x12, 0x16, 0x0a, 0x06, 0x52, 0x65, 0x67, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x06, 0x52, 0x65, 0x67, 0x69, 0x6f, 0x6e, 0x12, 0x39, 0x0a, 0x09, 0x52, 0x65, 0x67, 0x69,
+	0x6f, 0x6e, 0x52, 0x54, 0x54, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x43, 0x6c,
+	0x69, 0x65, 0x6e, 0x74, 0x48, 0x65, 0x6c, 0x6c, 0x6f, 0x2e, 0x52, 0x65, 0x67, 0x69, 0x6f, 0x6e,
+	0x52, 0x54, 0x54, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x09, 0x52, 0x65, 0x67, 0x69, 0x6f, 0x6e,
+	0x52, 0x54, 0x54, 0x12, 0x36, 0x0a, 0x08, 0x46, 0x65, 0x61, 0x74, 0x75, 0x72, 0x65, 0x73, 0x18,
+	0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x48, 0x65,
+	0x6c, 0x6c, 0x6f, 0x2e, 0x46, 0x65, 0x61, 0x74, 0x75, 0x72, 0x65, 0x73, 0x45, 0x6e, 0x74, 0x72,
+	0x79, 0x52, 0x08, 0x46, 0x65, 0x61, 0x74, 0x75, 0x72, 0x65, 0x73, 0x12, 0x22, 0x0a, 0x0c, 0x44,
+	0x69, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x61, 0x72, 0x69, 0x65, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28,
+	0x0d, 0x52, 0x0c, 0x44, 0x69, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x61, 0x72, 0x69, 0x65, 0x73, 0x12,
+	0x14, 0x0a, 0x05, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05,
+	0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x12, 0x20, 0x0a, 0x0b, 0x52, 0x65, 0x73, 0x75, 0x6d, 0x65, 0x54,
+	0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x52, 0x65, 0x73, 0x75,
+	0x6d, 0x65, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x12, 0x18, 0x0a, 0x07, 0x4c, 0x61, 0x73, 0x74, 0x53,
+	0x65, 0x71, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07, 0x4c, 0x61, 0x73, 0x74, 0x53, 0x65,
+	0x71, 0x12, 0x18, 0x0a, 0x07, 0x4c, 0x6f, 0x63, 0x61, 0x6c, 0x65, 0x73, 0x18, 0x08, 0x20, 0x03,
+	0x28, 0x09, 0x52, 0x07, 0x4c, 0x6f, 0x63, 0x61, 0x6c, 0x65, 0x73, 0x12, 0x25, 0x0a, 0x06, 0x53,
+	0x63, 0x68, 0x65, 0x6d, 0x61, 0x18, 0x09, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0d, 0x2e, 0x53, 0x63,
+	0x68, 0x65, 0x6d, 0x61, 0x44, 0x69, 0x67, 0x65, 0x73, 0x74, 0x52, 0x06, 0x53, 0x63, 0x68, 0x65,
+	0x6d, 0x61, 0x1a, 0x3c, 0x0a, 0x0e, 0x52, 0x65, 0x67, 0x69, 0x6f, 0x6e, 0x52, 0x54, 0x54, 0x45,
+	0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01,
+	0x1a, 0x3b, 0x0a, 0x0d, 0x46, 0x65, 0x61, 0x74, 0x75, 0x72, 0x65, 0x73, 0x45, 0x6e, 0x74, 0x72,
+	0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03,
+	0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x0d, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0xd3, 0x02,
+	0x0a, 0x0b, 0x53, 0x65, 0x72, 0x76, 0x65, 0x72, 0x48, 0x65, 0x6c, 0x6c, 0x6f, 0x12, 0x36, 0x0a,
+	0x08, 0x46, 0x65, 0x61, 0x74, 0x75, 0x72, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x1a, 0x2e, 0x53, 0x65, 0x72, 0x76, 0x65, 0x72, 0x48, 0x65, 0x6c, 0x6c, 0x6f, 0x2e, 0x46, 0x65,
+	0x61, 0x74, 0x75, 0x72, 0x65, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x08, 0x46, 0x65, 0x61,
+	0x74, 0x75, 0x72, 0x65, 0x73, 0x12, 0x22, 0x0a, 0x0c, 0x44, 0x69, 0x63, 0x74, 0x69, 0x6f, 0x6e,
+	0x61, 0x72, 0x69, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0d, 0x52, 0x0c, 0x44, 0x69, 0x63,
+	0x74, 0x69, 0x6f, 0x6e, 0x61, 0x72, 0x69, 0x65, 0x73, 0x12, 0x20, 0x0a, 0x0b, 0x52, 0x65, 0x73,
+	0x75, 0x6d, 0x65, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b,
+	0x52, 0x65, 0x73, 0x75, 0x6d, 0x65, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x12, 0x18, 0x0a, 0x07, 0x52,
+	0x65, 0x73, 0x75, 0x6d, 0x65, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x52, 0x65,
+	0x73, 0x75, 0x6d, 0x65, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x55, 0x64, 0x70, 0x4b, 0x65, 0x79, 0x18,
+	0x05, 0x20, 0x01, 0x28, 0x06, 0x52, 0x06, 0x55, 0x64, 0x70, 0x4b, 0x65, 0x79, 0x12, 0x18, 0x0a,
+	0x07, 0x55, 0x64, 0x70, 0x50, 0x6f, 0x72, 0x74, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07,
+	0x55, 0x64, 0x70, 0x50, 0x6f, 0x72, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x4c, 0x6f, 0x63, 0x61, 0x6c,
+	0x65, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x4c, 0x6f, 0x63, 0x61, 0x6c, 0x65, 0x12,
+	0x25, 0x0a, 0x06, 0x53, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x0d, 0x2e, 0x53, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x44, 0x69, 0x67, 0x65, 0x73, 0x74, 0x52, 0x06,
+	0x53, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x1a, 0x3b, 0x0a, 0x0d, 0x46, 0x65, 0x61, 0x74, 0x75, 0x72,
+	0x65, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c,
+	0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a,
+	0x02, 0x38, 0x01, 0x22, 0x9f, 0x01, 0x0a, 0x09, 0x52, 0x65, 0x63, 0x6f, 0x6e, 0x6e, 0x65, 0x63,
+	0x74, 0x12, 0x16, 0x0a, 0x06, 0x52, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x06, 0x52, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x12, 0x1a, 0x0a, 0x08, 0x45, 0x6e, 0x64,
+	0x70, 0x6f, 0x69, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x45, 0x6e, 0x64,
+	0x70, 0x6f, 0x69, 0x6e, 0x74, 0x12, 0x20, 0x0a, 0x0b, 0x52, 0x65, 0x73, 0x75, 0x6d, 0x65, 0x54,
+	0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x52, 0x65, 0x73, 0x75,
+	0x6d, 0x65, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x12, 0x22, 0x0a, 0x0c, 0x52, 0x65, 0x74, 0x72, 0x79,
+	0x41, 0x66, 0x74, 0x65, 0x72, 0x4d, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0c, 0x52,
+	0x65, 0x74, 0x72, 0x79, 0x41, 0x66, 0x74, 0x65, 0x72, 0x4d, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x4d,
+	0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x4d, 0x65,
+	0x73, 0x73, 0x61, 0x67, 0x65, 0x22, 0x3f, 0x0a, 0x0d, 0x4f, 0x62, 0x73, 0x65, 0x72, 0x76, 0x65,
+	0x72, 0x48, 0x65, 0x6c, 0x6c, 0x6f, 0x12, 0x14, 0x0a, 0x05, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x12, 0x18, 0x0a, 0x07,
+	0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x07, 0x53,
+	0x74, 0x72, 0x65, 0x61, 0x6d, 0x73, 0x22, 0x65, 0x0a, 0x0d, 0x4f, 0x62, 0x73, 0x65, 0x72, 0x76,
+	0x65, 0x72, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x53, 0x74, 0x72, 0x65, 0x61,
+	0x6d, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x12,
+	0x10, 0x0a, 0x03, 0x53, 0x65, 0x71, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x03, 0x53, 0x65,
+	0x71, 0x12, 0x12, 0x0a, 0x04, 0x44, 0x61, 0x74, 0x61, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52,
+	0x04, 0x44, 0x61, 0x74, 0x61, 0x12, 0x16, 0x0a, 0x06, 0x55, 0x6e, 0x69, 0x78, 0x4d, 0x73, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x06, 0x55, 0x6e, 0x69, 0x78, 0x4d, 0x73, 0x22, 0xab, 0x01,
+	0x0a, 0x11, 0x4d, 0x61, 0x69, 0x6e, 0x74, 0x65, 0x6e, 0x61, 0x6e, 0x63, 0x65, 0x4e, 0x6f, 0x74,
+	0x69, 0x63, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x57, 0x69, 0x6e, 0x64, 0x6f, 0x77, 0x49, 0x44, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x57, 0x69, 0x6e, 0x64, 0x6f, 0x77, 0x49, 0x44, 0x12,
+	0x20, 0x0a, 0x0b, 0x53, 0x74, 0x61, 0x72, 0x74, 0x55, 0x6e, 0x69, 0x78, 0x4d, 0x73, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x03, 0x52, 0x0b, 0x53, 0x74, 0x61, 0x72, 0x74, 0x55, 0x6e, 0x69, 0x78, 0x4d,
+	0x73, 0x12, 0x1e, 0x0a, 0x0a, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x4d, 0x73, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0a, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x4d,
+	0x73, 0x12, 0x20, 0x0a, 0x0b, 0x52, 0x65, 0x6d, 0x61, 0x69, 0x6e, 0x69, 0x6e, 0x67, 0x4d, 0x73,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0b, 0x52, 0x65, 0x6d, 0x61, 0x69, 0x6e, 0x69, 0x6e,
+	0x67, 0x4d, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x52, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x18, 0x05, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x06, 0x52, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x42, 0x16, 0x5a, 0x14, 0x7a,
+	0x64, 0x6f, 0x70, 0x74, 0x2f, 0x5a, 0x64, 0x6f, 0x70, 0x74, 0x53, 0x65, 0x72, 0x76, 0x65, 0x72,
+	0x2f, 0x50, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 })
 
 var (
@@ -88,16 +1311,46 @@ func file_mainPb_proto_rawDescGZIP() []byte {
 	return file_mainPb_proto_rawDescData
 }
 
-var file_mainPb_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_mainPb_proto_msgTypes = make([]protoimpl.MessageInfo, 21)
 var file_mainPb_proto_goTypes = []any{
-	(*DataPacket)(nil), // 0: DataPacket
+	(*DataPacket)(nil),        // 0: DataPacket
+	(*ErrorResponse)(nil),     // 1: ErrorResponse
+	(*SchemaDigest)(nil),      // 2: SchemaDigest
+	(*SchemaMismatch)(nil),    // 3: SchemaMismatch
+	(*ExportEvent)(nil),       // 4: ExportEvent
+	(*ExportBatch)(nil),       // 5: ExportBatch
+	(*Passthrough)(nil),       // 6: Passthrough
+	(*DataPushHello)(nil),     // 7: DataPushHello
+	(*DataPushChunk)(nil),     // 8: DataPushChunk
+	(*DataPushAck)(nil),       // 9: DataPushAck
+	(*ClientHello)(nil),       // 10: ClientHello
+	(*ServerHello)(nil),       // 11: ServerHello
+	(*Reconnect)(nil),         // 12: Reconnect
+	(*ObserverHello)(nil),     // 13: ObserverHello
+	(*ObserverEvent)(nil),     // 14: ObserverEvent
+	(*MaintenanceNotice)(nil), // 15: MaintenanceNotice
+	nil,                       // 16: SchemaDigest.MessagesEntry
+	nil,                       // 17: DataPushHello.VersionsEntry
+	nil,                       // 18: ClientHello.RegionRTTEntry
+	nil,                       // 19: ClientHello.FeaturesEntry
+	nil,                       // 20: ServerHello.FeaturesEntry
 }
 var file_mainPb_proto_depIdxs = []int32{
-	0, // [0:0] is the sub-list for method output_type
-	0, // [0:0] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	16, // 0: SchemaDigest.Messages:type_name -> SchemaDigest.MessagesEntry
+	2,  // 1: SchemaMismatch.Digest:type_name -> SchemaDigest
+	4,  // 2: ExportBatch.Events:type_name -> ExportEvent
+	17, // 3: DataPushHello.Versions:type_name -> DataPushHello.VersionsEntry
+	9,  // 4: DataPushHello.Partial:type_name -> DataPushAck
+	18, // 5: ClientHello.RegionRTT:type_name -> ClientHello.RegionRTTEntry
+	19, // 6: ClientHello.Features:type_name -> ClientHello.FeaturesEntry
+	2,  // 7: ClientHello.Schema:type_name -> SchemaDigest
+	20, // 8: ServerHello.Features:type_name -> ServerHello.FeaturesEntry
+	2,  // 9: ServerHello.Schema:type_name -> SchemaDigest
+	10, // [10:10] is the sub-list for method output_type
+	10, // [10:10] is the sub-list for method input_type
+	10, // [10:10] is the sub-list for extension type_name
+	10, // [10:10] is the sub-list for extension extendee
+	0,  // [0:10] is the sub-list for field type_name
 }
 
 func init() { file_mainPb_proto_init() }
@@ -111,7 +1364,7 @@ func file_mainPb_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_mainPb_proto_rawDesc), len(file_mainPb_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   1,
+			NumMessages:   21,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
@@ -6,6 +6,7 @@ import (
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/reflect/protoreflect"
 	"sync"
+	"zdopt/ZdoptServer/Strict"
 )
 
 var (
@@ -25,7 +26,23 @@ func Serialize(msg proto.Message) ([]byte, error) {
 	if err := validateMessage(msg); err != nil {
 		return nil, fmt.Errorf("serialize validation failed: %w", err)
 	}
-	return proto.Marshal(msg)
+	data, err := proto.Marshal(msg)
+	if err == nil && Strict.Enabled() {
+		verifyRoundTrip(msg, data)
+	}
+	return data, err
+}
+
+// verifyRoundTrip 严格模式：序列化结果必须能还原为等价消息
+func verifyRoundTrip(msg proto.Message, data []byte) {
+	decoded := msg.ProtoReflect().New().Interface()
+	if err := proto.Unmarshal(data, decoded); err != nil {
+		Strict.Failf("codec.round-trip", "%s: unmarshal failed: %v", msg.ProtoReflect().Descriptor().FullName(), err)
+		return
+	}
+	if !proto.Equal(msg, decoded) {
+		Strict.Failf("codec.round-trip", "%s: decoded message differs from original", msg.ProtoReflect().Descriptor().FullName())
+	}
 }
 
 // Deserialize 安全反序列化（带类型校验）
@@ -54,3 +71,40 @@ func validateMessage(msg proto.Message) error {
 	}
 	return nil
 }
+
+// UnregisteredTypes 返回协议文件中已定义但尚未注册的消息类型（启动自检使用）
+func UnregisteredTypes() []string {
+	var missing []string
+	msgs := File_mainPb_proto.Messages()
+	for i := 0; i < msgs.Len(); i++ {
+		name := msgs.Get(i).FullName()
+		if _, ok := typeRegistry.Load(name); !ok {
+			missing = append(missing, string(name))
+		}
+	}
+	return missing
+}
+
+// IsRegistered 协议全名是否已在本地注册
+func IsRegistered(name string) bool {
+	_, ok := typeRegistry.Load(protoreflect.FullName(name))
+	return ok
+}
+
+// DeserializeByName 按协议全名动态反序列化（类型在运行时才确定的场景，如网关透传）
+func DeserializeByName(name string, data []byte) (proto.Message, error) {
+	typ, ok := typeRegistry.Load(protoreflect.FullName(name))
+	if !ok {
+		return nil, fmt.Errorf("%w: %s not registered", ErrInvalidType, name)
+	}
+	msg := typ.(protoreflect.MessageType).New().Interface()
+	if err := proto.Unmarshal(data, msg); err != nil {
+		return nil, fmt.Errorf("deserialize %s failed: %w", name, err)
+	}
+	return msg, nil
+}
+
+// TypeName 消息的协议全名
+func TypeName(msg proto.Message) string {
+	return string(msg.ProtoReflect().Descriptor().FullName())
+}
@@ -0,0 +1,150 @@
+package Pb
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strings"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+var ErrSchemaIncompatible = errors.New("protobuf schema incompatible")
+
+// SchemaStatus 协议兼容性判定结果
+type SchemaStatus int
+
+const (
+	SchemaCompatible   SchemaStatus = iota // 完全一致
+	SchemaDegraded                         // 仅一方缺少部分消息类型，可降级通信（不发送对方未知的类型）
+	SchemaIncompatible                     // 同名消息字段定义不一致，应拒绝建立连接
+)
+
+func (s SchemaStatus) String() string {
+	return [...]string{"compatible", "degraded", "incompatible"}[s]
+}
+
+// SchemaReport 两端协议注册表的比对报告
+type SchemaReport struct {
+	MissingRemote []string // 本地有、对端没有
+	MissingLocal  []string // 对端有、本地没有
+	Mismatched    []string // 双方都有但字段哈希不同
+}
+
+// Status 判定兼容性
+func (r *SchemaReport) Status() SchemaStatus {
+	switch {
+	case len(r.Mismatched) > 0:
+		return SchemaIncompatible
+	case len(r.MissingRemote) > 0 || len(r.MissingLocal) > 0:
+		return SchemaDegraded
+	default:
+		return SchemaCompatible
+	}
+}
+
+// Err 不兼容时返回带明细的错误
+func (r *SchemaReport) Err() error {
+	if r.Status() != SchemaIncompatible {
+		return nil
+	}
+	return fmt.Errorf("%w: mismatched %s", ErrSchemaIncompatible, strings.Join(r.Mismatched, ", "))
+}
+
+// String 可读报告
+func (r *SchemaReport) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "schema %s", r.Status())
+	if len(r.Mismatched) > 0 {
+		fmt.Fprintf(&sb, "; mismatched: %s", strings.Join(r.Mismatched, ", "))
+	}
+	if len(r.MissingRemote) > 0 {
+		fmt.Fprintf(&sb, "; missing on remote: %s", strings.Join(r.MissingRemote, ", "))
+	}
+	if len(r.MissingLocal) > 0 {
+		fmt.Fprintf(&sb, "; missing locally: %s", strings.Join(r.MissingLocal, ", "))
+	}
+	return sb.String()
+}
+
+// Proto 转为握手拒绝时下发给对端的 SchemaMismatch，附带本端摘要
+func (r *SchemaReport) Proto() *SchemaMismatch {
+	return &SchemaMismatch{
+		Mismatched:    r.Mismatched,
+		MissingRemote: r.MissingRemote,
+		MissingLocal:  r.MissingLocal,
+		Digest:        LocalDigest(),
+	}
+}
+
+// Report 按接收方视角还原比对报告：对端缺少的即本端缺少的
+func (m *SchemaMismatch) Report() *SchemaReport {
+	return &SchemaReport{
+		MissingRemote: m.GetMissingLocal(),
+		MissingLocal:  m.GetMissingRemote(),
+		Mismatched:    m.GetMismatched(),
+	}
+}
+
+// LocalDigest 计算本地已注册协议类型的摘要，用于节点握手交换
+func LocalDigest() *SchemaDigest {
+	digest := &SchemaDigest{Messages: make(map[string]uint64)}
+	typeRegistry.Range(func(key, value any) bool {
+		mt := value.(protoreflect.MessageType)
+		digest.Messages[string(key.(protoreflect.FullName))] = fieldHash(mt.Descriptor())
+		return true
+	})
+	return digest
+}
+
+// CompareSchemas 比对本地与对端摘要
+func CompareSchemas(local, remote *SchemaDigest) *SchemaReport {
+	report := &SchemaReport{}
+	for name, hash := range local.GetMessages() {
+		remoteHash, ok := remote.GetMessages()[name]
+		switch {
+		case !ok:
+			report.MissingRemote = append(report.MissingRemote, name)
+		case remoteHash != hash:
+			report.Mismatched = append(report.Mismatched, name)
+		}
+	}
+	for name := range remote.GetMessages() {
+		if _, ok := local.GetMessages()[name]; !ok {
+			report.MissingLocal = append(report.MissingLocal, name)
+		}
+	}
+	sort.Strings(report.MissingRemote)
+	sort.Strings(report.MissingLocal)
+	sort.Strings(report.Mismatched)
+	return report
+}
+
+// fieldHash 对字段编号、名称、类型与基数做稳定哈希（与字段声明顺序无关）
+func fieldHash(desc protoreflect.MessageDescriptor) uint64 {
+	fields := desc.Fields()
+	lines := make([]string, 0, fields.Len())
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		typeName := fd.Kind().String()
+		switch {
+		case fd.IsMap():
+			typeName = fmt.Sprintf("map<%s,%s>", fd.MapKey().Kind(), fd.MapValue().Kind())
+		case fd.Message() != nil:
+			typeName = string(fd.Message().FullName())
+		case fd.Enum() != nil:
+			typeName = string(fd.Enum().FullName())
+		}
+		lines = append(lines, fmt.Sprintf("%d:%s:%s:%s", fd.Number(), fd.Name(), typeName, fd.Cardinality()))
+	}
+	sort.Strings(lines)
+
+	h := fnv.New64a()
+	h.Write([]byte(desc.FullName()))
+	for _, line := range lines {
+		h.Write([]byte{'\n'})
+		h.Write([]byte(line))
+	}
+	return h.Sum64()
+}
@@ -0,0 +1,340 @@
+package Persist
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	ErrStoreExists      = errors.New("persistent store already registered")
+	ErrChecksumMismatch = errors.New("backup checksum mismatch")
+	ErrJournalsOpen     = errors.New("store has open journals")
+)
+
+const (
+	manifestName    = "manifest.json"
+	manifestVersion = 1
+)
+
+// Store 已登记的持久化存储目录（日志、快照、任务队列、计数器等）
+// 写入方通过 Write 共享持有存储锁，备份时独占该锁，短暂暂停所有写入
+type Store struct {
+	Name string
+	Dir  string
+	mu   sync.RWMutex
+
+	journals atomic.Int32 // 经 OpenJournal 打开且未关闭的日志数，非零时拒绝恢复
+}
+
+// Write 在存储锁保护下执行写操作（与备份互斥，写操作之间不互斥）
+func (s *Store) Write(fn func() error) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return fn()
+}
+
+// WriteFile 在存储锁保护下以容器格式写入存储目录内的文件
+func (s *Store) WriteFile(rel string, data []byte, opts StoreOptions) error {
+	return s.Write(func() error {
+		return WriteFile(filepath.Join(s.Dir, rel), data, opts)
+	})
+}
+
+// Remove 在存储锁保护下删除存储目录内的文件，文件不存在时不报错
+func (s *Store) Remove(rel string) error {
+	return s.Write(func() error {
+		if err := os.Remove(filepath.Join(s.Dir, rel)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		return nil
+	})
+}
+
+// ReadFile 读取存储目录内的文件
+func (s *Store) ReadFile(rel string) ([]byte, error) {
+	return ReadFile(filepath.Join(s.Dir, rel))
+}
+
+var (
+	storesMu sync.Mutex
+	stores   = map[string]*Store{}
+)
+
+// RegisterStore 登记持久化存储，参与备份与恢复
+func RegisterStore(name, dir string) (*Store, error) {
+	storesMu.Lock()
+	defer storesMu.Unlock()
+	if _, ok := stores[name]; ok {
+		return nil, fmt.Errorf("%w: %s", ErrStoreExists, name)
+	}
+	s := &Store{Name: name, Dir: dir}
+	stores[name] = s
+	return s, nil
+}
+
+// UnregisterStore 取消登记存储，之后的备份与恢复不再包含它；目录内容保持不变
+func UnregisterStore(name string) {
+	storesMu.Lock()
+	defer storesMu.Unlock()
+	delete(stores, name)
+}
+
+// Stores 已登记的存储（按名称排序）
+func Stores() []*Store {
+	storesMu.Lock()
+	defer storesMu.Unlock()
+	out := make([]*Store, 0, len(stores))
+	for _, s := range stores {
+		out = append(out, s)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// Manifest 备份清单
+type Manifest struct {
+	Version int             `json:"version"`
+	Created time.Time       `json:"created"`
+	Stores  []ManifestStore `json:"stores"`
+}
+
+// ManifestStore 单个存储的备份内容
+type ManifestStore struct {
+	Name  string         `json:"name"`
+	Files []ManifestFile `json:"files"`
+}
+
+// ManifestFile 备份文件及校验和
+type ManifestFile struct {
+	Path   string `json:"path"` // 相对存储目录
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// Backup 对所有已登记存储做时间点备份：独占所有存储锁后复制文件，
+// 写入 <dir>/backup-<UTC时间>/ 并生成带校验和的清单，返回备份目录
+func Backup(dir string) (string, error) {
+	list := Stores()
+	for _, s := range list {
+		s.mu.Lock()
+	}
+	defer func() {
+		for _, s := range list {
+			s.mu.Unlock()
+		}
+	}()
+
+	now := time.Now().UTC()
+	target := filepath.Join(dir, "backup-"+now.Format("20060102T150405.000Z"))
+	tmp := target + ".partial"
+	if err := os.MkdirAll(tmp, 0755); err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(tmp)
+
+	manifest := Manifest{Version: manifestVersion, Created: now}
+	for _, s := range list {
+		files, err := copyTree(s.Dir, filepath.Join(tmp, s.Name))
+		if err != nil {
+			return "", fmt.Errorf("backup store %s: %w", s.Name, err)
+		}
+		manifest.Stores = append(manifest.Stores, ManifestStore{Name: s.Name, Files: files})
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	if err := writeAtomic(filepath.Join(tmp, manifestName), func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	}); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmp, target); err != nil {
+		return "", err
+	}
+	return target, nil
+}
+
+// ReadManifest 读取备份清单
+func ReadManifest(backup string) (*Manifest, error) {
+	data, err := os.ReadFile(filepath.Join(backup, manifestName))
+	if err != nil {
+		return nil, err
+	}
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("parse manifest: %w", err)
+	}
+	if m.Version != manifestVersion {
+		return nil, fmt.Errorf("%w: manifest version %d", ErrUnsupportedVersion, m.Version)
+	}
+	return &m, nil
+}
+
+// Verify 校验备份中所有文件的大小与校验和
+func Verify(backup string) (*Manifest, error) {
+	m, err := ReadManifest(backup)
+	if err != nil {
+		return nil, err
+	}
+	for _, st := range m.Stores {
+		for _, f := range st.Files {
+			size, sum, err := checksum(filepath.Join(backup, st.Name, f.Path))
+			if err != nil {
+				return nil, err
+			}
+			if size != f.Size || sum != f.SHA256 {
+				return nil, fmt.Errorf("%w: %s/%s", ErrChecksumMismatch, st.Name, f.Path)
+			}
+		}
+	}
+	return m, nil
+}
+
+// Restore 校验备份后恢复所有已登记存储：先完整复制到临时目录，再在存储锁下整体替换。
+// 备份中存在但当前未登记的存储会被跳过并返回其名称；存储仍有打开的日志时返回 ErrJournalsOpen，
+// 调用方应先关闭日志，恢复后重新打开
+func Restore(backup string) (skipped []string, err error) {
+	m, err := Verify(backup)
+	if err != nil {
+		return nil, err
+	}
+
+	storesMu.Lock()
+	registered := make(map[string]*Store, len(stores))
+	for name, s := range stores {
+		registered[name] = s
+	}
+	storesMu.Unlock()
+
+	for _, st := range m.Stores {
+		s, ok := registered[st.Name]
+		if !ok {
+			skipped = append(skipped, st.Name)
+			continue
+		}
+		if err := s.restoreFrom(filepath.Join(backup, st.Name)); err != nil {
+			return skipped, fmt.Errorf("restore store %s: %w", st.Name, err)
+		}
+	}
+	return skipped, nil
+}
+
+func (s *Store) restoreFrom(src string) error {
+	if s.journals.Load() > 0 {
+		return s.errJournalsOpen()
+	}
+	staging := s.Dir + ".restore"
+	if err := os.RemoveAll(staging); err != nil {
+		return err
+	}
+	if _, err := copyTree(src, staging); err != nil {
+		os.RemoveAll(staging)
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// 日志在存储锁下打开，持锁后计数不会再增加
+	if s.journals.Load() > 0 {
+		os.RemoveAll(staging)
+		return s.errJournalsOpen()
+	}
+
+	old := s.Dir + ".pre-restore"
+	if err := os.RemoveAll(old); err != nil {
+		return err
+	}
+	if err := os.Rename(s.Dir, old); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if err := os.Rename(staging, s.Dir); err != nil {
+		// 回滚到恢复前的目录
+		_ = os.Rename(old, s.Dir)
+		return err
+	}
+	return os.RemoveAll(old)
+}
+
+func (s *Store) errJournalsOpen() error {
+	return fmt.Errorf("%w: %d", ErrJournalsOpen, s.journals.Load())
+}
+
+// copyTree 复制目录下的所有普通文件并计算校验和，源目录不存在时视为空
+func copyTree(src, dst string) ([]ManifestFile, error) {
+	var files []ManifestFile
+	if err := os.MkdirAll(dst, 0755); err != nil {
+		return nil, err
+	}
+	err := filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) && path == src {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		size, sum, err := copyFile(path, filepath.Join(dst, rel))
+		if err != nil {
+			return err
+		}
+		files = append(files, ManifestFile{Path: filepath.ToSlash(rel), Size: size, SHA256: sum})
+		return nil
+	})
+	return files, err
+}
+
+func copyFile(src, dst string) (int64, string, error) {
+	in, err := os.Open(src)
+	if err != nil {
+		return 0, "", err
+	}
+	defer in.Close()
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return 0, "", err
+	}
+	out, err := os.Create(dst)
+	if err != nil {
+		return 0, "", err
+	}
+	h := sha256.New()
+	size, err := io.Copy(io.MultiWriter(out, h), in)
+	if err == nil {
+		err = out.Sync()
+	}
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	return size, hex.EncodeToString(h.Sum(nil)), err
+}
+
+func checksum(path string) (int64, string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	size, err := io.Copy(h, f)
+	return size, hex.EncodeToString(h.Sum(nil)), err
+}
@@ -0,0 +1,113 @@
+package Persist
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func registerTestStore(t *testing.T) *Store {
+	t.Helper()
+	s, err := RegisterStore(t.Name(), filepath.Join(t.TempDir(), "store"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { UnregisterStore(s.Name) })
+	return s
+}
+
+func TestRestoreRoundTrip(t *testing.T) {
+	s := registerTestStore(t)
+	if err := s.WriteFile("state.bin", []byte("v1"), StoreOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	j, err := s.OpenJournal("events.log", JournalConfig{Durability: DurabilityStrict})
+	if err != nil {
+		t.Fatal(err)
+	}
+	j.Append([]byte("a"))
+	j.Close()
+
+	backup, err := Backup(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 备份后继续修改，恢复应回到备份时的内容
+	if err := s.WriteFile("state.bin", []byte("v2"), StoreOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(s.Dir, "extra"), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Restore(backup); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := s.ReadFile("state.bin"); err != nil || string(data) != "v1" {
+		t.Fatalf("state after restore = %q, %v", data, err)
+	}
+	if _, err := os.Stat(filepath.Join(s.Dir, "extra")); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("file written after backup survived restore: %v", err)
+	}
+	if got := replayAll(t, filepath.Join(s.Dir, "events.log")); len(got) != 1 || got[0] != "a" {
+		t.Fatalf("journal after restore = %q", got)
+	}
+}
+
+func TestRestoreRefusedWhileJournalOpen(t *testing.T) {
+	s := registerTestStore(t)
+	j, err := s.OpenJournal("events.log", JournalConfig{Durability: DurabilityStrict})
+	if err != nil {
+		t.Fatal(err)
+	}
+	j.Append([]byte("a"))
+
+	backup, err := Backup(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	j.Append([]byte("b"))
+
+	if _, err := Restore(backup); !errors.Is(err, ErrJournalsOpen) {
+		t.Fatalf("restore with open journal = %v, want ErrJournalsOpen", err)
+	}
+	// 拒绝恢复后日志仍可正常写入，目录未被替换
+	if _, err := j.Append([]byte("c")); err != nil {
+		t.Fatal(err)
+	}
+	j.Close()
+	if got := replayAll(t, filepath.Join(s.Dir, "events.log")); len(got) != 3 {
+		t.Fatalf("journal after refused restore = %q", got)
+	}
+
+	// 关闭后恢复成功，重新打开从备份时的序号继续
+	if _, err := Restore(backup); err != nil {
+		t.Fatal(err)
+	}
+	j, err = s.OpenJournal("events.log", JournalConfig{Durability: DurabilityStrict})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer j.Close()
+	if seq, err := j.Append([]byte("b2")); err != nil || seq != 2 {
+		t.Fatalf("append after restore = %d, %v", seq, err)
+	}
+}
+
+func TestRestoreRejectsCorruptBackup(t *testing.T) {
+	s := registerTestStore(t)
+	if err := s.WriteFile("state.bin", []byte("v1"), StoreOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	backup, err := Backup(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(backup, s.Name, "state.bin"), []byte("tampered"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Restore(backup); !errors.Is(err, ErrChecksumMismatch) {
+		t.Fatalf("restore of corrupt backup = %v, want ErrChecksumMismatch", err)
+	}
+}
@@ -0,0 +1,121 @@
+package Persist
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/klauspost/compress/snappy"
+	"github.com/klauspost/compress/zstd"
+)
+
+var (
+	ErrUnknownCodec       = errors.New("unknown persistence codec")
+	ErrUnsupportedVersion = errors.New("unsupported container version")
+)
+
+// 容器格式：magic(4) + version(1) + codec(1) + 压缩流
+// 没有 magic 的文件视为旧版未压缩格式，原样读取
+var magic = [4]byte{'Z', 'D', 'P', 'C'}
+
+const (
+	containerVersion = 1
+	headerSize       = 6
+)
+
+// Codec 持久化文件压缩方式
+type Codec byte
+
+const (
+	CodecNone Codec = iota
+	CodecZstd
+	CodecSnappy
+)
+
+func (c Codec) String() string {
+	switch c {
+	case CodecNone:
+		return "none"
+	case CodecZstd:
+		return "zstd"
+	case CodecSnappy:
+		return "snappy"
+	default:
+		return fmt.Sprintf("codec(%d)", byte(c))
+	}
+}
+
+// ParseCodec 解析配置中的压缩方式名称
+func ParseCodec(name string) (Codec, error) {
+	switch name {
+	case "", "none":
+		return CodecNone, nil
+	case "zstd":
+		return CodecZstd, nil
+	case "snappy":
+		return CodecSnappy, nil
+	default:
+		return CodecNone, fmt.Errorf("%w: %q", ErrUnknownCodec, name)
+	}
+}
+
+// NewWriter 写入容器头并返回流式压缩写入器，Close 时刷新压缩流（不关闭底层 w）
+func NewWriter(w io.Writer, codec Codec) (io.WriteCloser, error) {
+	header := [headerSize]byte{magic[0], magic[1], magic[2], magic[3], containerVersion, byte(codec)}
+	if _, err := w.Write(header[:]); err != nil {
+		return nil, fmt.Errorf("write container header: %w", err)
+	}
+
+	switch codec {
+	case CodecNone:
+		return nopWriteCloser{w}, nil
+	case CodecZstd:
+		return zstd.NewWriter(w)
+	case CodecSnappy:
+		return snappy.NewBufferedWriter(w), nil
+	default:
+		return nil, fmt.Errorf("%w: %d", ErrUnknownCodec, byte(codec))
+	}
+}
+
+// NewReader 识别容器头并返回流式解压读取器；无容器头时按旧版未压缩格式读取
+func NewReader(r io.Reader) (io.ReadCloser, Codec, error) {
+	br := bufio.NewReader(r)
+	header, err := br.Peek(headerSize)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, bufio.ErrBufferFull) {
+		return nil, CodecNone, fmt.Errorf("read container header: %w", err)
+	}
+	if len(header) < headerSize || !bytes.Equal(header[:4], magic[:]) {
+		return io.NopCloser(br), CodecNone, nil
+	}
+	if header[4] != containerVersion {
+		return nil, CodecNone, fmt.Errorf("%w: %d", ErrUnsupportedVersion, header[4])
+	}
+	codec := Codec(header[5])
+	if _, err := br.Discard(headerSize); err != nil {
+		return nil, codec, err
+	}
+
+	switch codec {
+	case CodecNone:
+		return io.NopCloser(br), codec, nil
+	case CodecZstd:
+		dec, err := zstd.NewReader(br)
+		if err != nil {
+			return nil, codec, err
+		}
+		return dec.IOReadCloser(), codec, nil
+	case CodecSnappy:
+		return io.NopCloser(snappy.NewReader(br)), codec, nil
+	default:
+		return nil, codec, fmt.Errorf("%w: %d", ErrUnknownCodec, byte(codec))
+	}
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
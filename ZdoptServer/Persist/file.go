@@ -0,0 +1,116 @@
+package Persist
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// StoreOptions 单个存储（快照/日志）的持久化选项
+type StoreOptions struct {
+	Codec Codec
+}
+
+// WriteFile 以容器格式原子写入文件（先写临时文件再重命名）
+func WriteFile(path string, data []byte, opts StoreOptions) error {
+	return writeAtomic(path, func(w io.Writer) error {
+		cw, err := NewWriter(w, opts.Codec)
+		if err != nil {
+			return err
+		}
+		if _, err := cw.Write(data); err != nil {
+			_ = cw.Close()
+			return err
+		}
+		return cw.Close()
+	})
+}
+
+// ReadFile 读取容器格式或旧版未压缩文件
+func ReadFile(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r, _, err := NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("open %s: %w", path, err)
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
+
+// Migrate 将文件重写为指定压缩方式（旧版未压缩文件迁移），已是目标格式时不做处理
+func Migrate(path string, opts StoreOptions) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	r, codec, err := NewReader(f)
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("open %s: %w", path, err)
+	}
+	legacy, err := isLegacy(path)
+	if err != nil || (!legacy && codec == opts.Codec) {
+		r.Close()
+		f.Close()
+		return err
+	}
+
+	err = writeAtomic(path, func(w io.Writer) error {
+		cw, err := NewWriter(w, opts.Codec)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(cw, r); err != nil {
+			_ = cw.Close()
+			return err
+		}
+		return cw.Close()
+	})
+	r.Close()
+	f.Close()
+	return err
+}
+
+// isLegacy 文件是否为无容器头的旧格式
+func isLegacy(path string) (bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	var header [4]byte
+	n, _ := io.ReadFull(f, header[:])
+	return n < len(header) || header != magic, nil
+}
+
+func writeAtomic(path string, write func(w io.Writer) error) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := write(tmp); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write %s: %w", path, err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,403 @@
+package Persist
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"expvar"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+var (
+	ErrJournalClosed     = errors.New("journal closed")
+	ErrJournalFailed     = errors.New("journal failed, reopen to recover")
+	ErrJournalCorrupt    = errors.New("journal header corrupt")
+	ErrRecordTooLarge    = errors.New("journal record too large")
+	ErrUnknownDurability = errors.New("unknown journal durability")
+
+	journalRecords = expvar.NewInt("persist.journal.records")
+	journalBatches = expvar.NewInt("persist.journal.batches")
+	journalFsyncs  = expvar.NewInt("persist.journal.fsyncs")
+)
+
+// 日志格式：magic(4) + version(1)，之后每条记录为 len(4) + crc32c(4) + 数据，均为小端
+var journalMagic = [4]byte{'Z', 'D', 'P', 'J'}
+
+const (
+	journalVersion    = 1
+	journalHeaderSize = 5
+	recordHeaderSize  = 8
+	maxRecordSize     = 16 << 20
+)
+
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+// Durability 日志写入的持久化级别
+type Durability int
+
+const (
+	DurabilityNone    Durability = iota // 只写入页缓存，不 fsync，进程崩溃不丢、机器掉电可能丢
+	DurabilityBatched                   // 组提交：窗口内的写入合并为一次写入与一次 fsync 后返回
+	DurabilityStrict                    // 每条记录单独 fsync 后返回
+)
+
+func (d Durability) String() string {
+	switch d {
+	case DurabilityNone:
+		return "none"
+	case DurabilityBatched:
+		return "batched"
+	case DurabilityStrict:
+		return "strict"
+	default:
+		return fmt.Sprintf("durability(%d)", int(d))
+	}
+}
+
+// ParseDurability 解析配置中的持久化级别名称，空串为 batched
+func ParseDurability(name string) (Durability, error) {
+	switch name {
+	case "", "batched":
+		return DurabilityBatched, nil
+	case "none":
+		return DurabilityNone, nil
+	case "strict":
+		return DurabilityStrict, nil
+	default:
+		return DurabilityBatched, fmt.Errorf("%w: %q", ErrUnknownDurability, name)
+	}
+}
+
+// JournalConfig 日志参数
+type JournalConfig struct {
+	Durability    Durability
+	Window        time.Duration // 组提交等待窗口，从批次第一条记录到达开始计算
+	MaxBatch      int           // 批次记录数达到上限时立即提交
+	MaxBatchBytes int           // 批次字节数达到上限时立即提交
+}
+
+// DefaultJournalConfig 默认参数：组提交，2ms 窗口，最多 512 条或 1MB 一批
+func DefaultJournalConfig() JournalConfig {
+	return JournalConfig{
+		Durability:    DurabilityBatched,
+		Window:        2 * time.Millisecond,
+		MaxBatch:      512,
+		MaxBatchBytes: 1 << 20,
+	}
+}
+
+// Journal 追加写日志，多个 Actor 共享时在组提交模式下合并写入与 fsync
+type Journal struct {
+	cfg   JournalConfig
+	store *Store // 非空时写入持有存储锁，与备份互斥
+
+	wmu     sync.Mutex // 串行化文件写入
+	f       *os.File
+	end     int64 // 已确认内容的结束偏移，写入失败时回退到此
+	nextSeq uint64
+	failed  error // 写入失败且无法回退（或 fsync 失败）后拒绝继续写入
+
+	mu     sync.RWMutex // 保护 closed 与 queue 的关闭
+	closed bool
+	queue  chan *pendingRecord
+	done   chan struct{}
+}
+
+type pendingRecord struct {
+	data []byte
+	seq  uint64
+	err  chan error
+}
+
+// OpenJournal 打开或创建日志；已有日志末尾不完整的记录（崩溃残留）被截断
+func OpenJournal(path string, cfg JournalConfig) (*Journal, error) {
+	def := DefaultJournalConfig()
+	if cfg.Window <= 0 {
+		cfg.Window = def.Window
+	}
+	if cfg.MaxBatch <= 0 {
+		cfg.MaxBatch = def.MaxBatch
+	}
+	if cfg.MaxBatchBytes <= 0 {
+		cfg.MaxBatchBytes = def.MaxBatchBytes
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	count, end, err := scanJournal(f, nil)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("open journal %s: %w", path, err)
+	}
+	if err := f.Truncate(end); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if _, err := f.Seek(end, io.SeekStart); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if end == 0 {
+		// 新文件或写头时崩溃残留的不完整文件头
+		if _, err := f.Write(append(journalMagic[:], journalVersion)); err != nil {
+			f.Close()
+			return nil, err
+		}
+		end = journalHeaderSize
+	}
+
+	j := &Journal{cfg: cfg, f: f, end: end, nextSeq: count + 1}
+	if cfg.Durability == DurabilityBatched {
+		j.queue = make(chan *pendingRecord, cfg.MaxBatch)
+		j.done = make(chan struct{})
+		go j.run()
+	}
+	return j, nil
+}
+
+// OpenJournal 在存储目录内打开日志，写入与备份互斥；关闭前该存储拒绝恢复
+func (s *Store) OpenJournal(rel string, cfg JournalConfig) (*Journal, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	j, err := OpenJournal(filepath.Join(s.Dir, rel), cfg)
+	if err != nil {
+		return nil, err
+	}
+	j.store = s
+	s.journals.Add(1)
+	return j, nil
+}
+
+// Append 追加一条记录，按持久化级别写入后返回记录序号（从 1 开始）
+func (j *Journal) Append(data []byte) (uint64, error) {
+	if len(data) > maxRecordSize {
+		return 0, fmt.Errorf("%w: %d bytes", ErrRecordTooLarge, len(data))
+	}
+	j.mu.RLock()
+	if j.closed {
+		j.mu.RUnlock()
+		return 0, ErrJournalClosed
+	}
+	if j.queue == nil {
+		defer j.mu.RUnlock()
+		return j.write([][]byte{data}, j.cfg.Durability == DurabilityStrict)
+	}
+	p := &pendingRecord{data: data, err: make(chan error, 1)}
+	j.queue <- p
+	j.mu.RUnlock()
+	if err := <-p.err; err != nil {
+		return 0, err
+	}
+	return p.seq, nil
+}
+
+// Close 提交已排队的记录后关闭文件
+func (j *Journal) Close() error {
+	j.mu.Lock()
+	if j.closed {
+		j.mu.Unlock()
+		return nil
+	}
+	j.closed = true
+	if j.queue != nil {
+		close(j.queue)
+	}
+	j.mu.Unlock()
+	if j.done != nil {
+		<-j.done
+	}
+
+	j.wmu.Lock()
+	defer j.wmu.Unlock()
+	if j.store != nil {
+		defer j.store.journals.Add(-1)
+	}
+	if j.cfg.Durability != DurabilityNone {
+		if err := j.f.Sync(); err != nil {
+			j.f.Close()
+			return err
+		}
+	}
+	return j.f.Close()
+}
+
+// run 组提交循环：收集窗口内的记录，一次写入、一次 fsync 后逐条回复
+func (j *Journal) run() {
+	defer close(j.done)
+	batch := make([]*pendingRecord, 0, j.cfg.MaxBatch)
+	data := make([][]byte, 0, j.cfg.MaxBatch)
+	timer := time.NewTimer(j.cfg.Window)
+	timer.Stop()
+	for {
+		p, ok := <-j.queue
+		if !ok {
+			return
+		}
+		batch = append(batch[:0], p)
+		size := len(p.data)
+		timer.Reset(j.cfg.Window)
+	collect:
+		for len(batch) < j.cfg.MaxBatch && size < j.cfg.MaxBatchBytes {
+			select {
+			case p, ok := <-j.queue:
+				if !ok {
+					break collect
+				}
+				batch = append(batch, p)
+				size += len(p.data)
+			case <-timer.C:
+				break collect
+			}
+		}
+		timer.Stop()
+
+		data = data[:0]
+		for _, p := range batch {
+			data = append(data, p.data)
+		}
+		first, err := j.write(data, true)
+		for i, p := range batch {
+			p.seq = first + uint64(i)
+			p.err <- err
+		}
+		clear(batch)
+	}
+}
+
+// write 把一组记录合并为一次写入，sync 为 true 时写入后 fsync，返回第一条记录的序号。
+// 写入失败时截断回写入前的偏移，避免残缺记录之后追加的已确认记录在重新打开时被一并截掉；
+// 无法回退或 fsync 失败（页缓存状态未知）时日志进入失败状态，之后的写入返回 ErrJournalFailed
+func (j *Journal) write(records [][]byte, sync bool) (uint64, error) {
+	size := 0
+	for _, rec := range records {
+		size += recordHeaderSize + len(rec)
+	}
+	buf := make([]byte, 0, size)
+	for _, rec := range records {
+		buf = binary.LittleEndian.AppendUint32(buf, uint32(len(rec)))
+		buf = binary.LittleEndian.AppendUint32(buf, crc32.Checksum(rec, crcTable))
+		buf = append(buf, rec...)
+	}
+
+	do := func() error {
+		if _, err := j.f.Write(buf); err != nil {
+			if rerr := j.rollback(); rerr != nil {
+				j.failed = errors.Join(err, rerr)
+			}
+			return err
+		}
+		if sync {
+			journalFsyncs.Add(1)
+			if err := j.f.Sync(); err != nil {
+				j.failed = err
+				return err
+			}
+		}
+		return nil
+	}
+
+	j.wmu.Lock()
+	defer j.wmu.Unlock()
+	if j.failed != nil {
+		return 0, fmt.Errorf("%w: %v", ErrJournalFailed, j.failed)
+	}
+	var err error
+	if j.store != nil {
+		err = j.store.Write(do)
+	} else {
+		err = do()
+	}
+	if err != nil {
+		return 0, fmt.Errorf("journal write: %w", err)
+	}
+	j.end += int64(len(buf))
+	first := j.nextSeq
+	j.nextSeq += uint64(len(records))
+	journalRecords.Add(int64(len(records)))
+	journalBatches.Add(1)
+	return first, nil
+}
+
+// rollback 丢弃上次确认之后写入的部分内容
+func (j *Journal) rollback() error {
+	if err := j.f.Truncate(j.end); err != nil {
+		return err
+	}
+	_, err := j.f.Seek(j.end, io.SeekStart)
+	return err
+}
+
+// ReplayJournal 按顺序读取日志记录，返回有效记录数；末尾不完整或校验失败的记录视为崩溃残留并忽略
+func ReplayJournal(path string, fn func(seq uint64, rec []byte) error) (uint64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	count, _, err := scanJournal(f, fn)
+	return count, err
+}
+
+// scanJournal 从头扫描日志，返回有效记录数与有效内容的结束偏移（空文件为 0）
+func scanJournal(f *os.File, fn func(seq uint64, rec []byte) error) (uint64, int64, error) {
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return 0, 0, err
+	}
+	r := bufio.NewReader(f)
+	var header [journalHeaderSize]byte
+	_, err := io.ReadFull(r, header[:])
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		// 空文件或写文件头时崩溃，不可能含有记录
+		return 0, 0, nil
+	}
+	if err != nil || [4]byte(header[:4]) != journalMagic {
+		return 0, 0, ErrJournalCorrupt
+	}
+	if header[4] != journalVersion {
+		return 0, 0, fmt.Errorf("%w: %d", ErrUnsupportedVersion, header[4])
+	}
+
+	var (
+		count  uint64
+		offset int64 = journalHeaderSize
+		rh     [recordHeaderSize]byte
+		rec    []byte
+	)
+	for {
+		if _, err := io.ReadFull(r, rh[:]); err != nil {
+			return count, offset, nil
+		}
+		size := binary.LittleEndian.Uint32(rh[:4])
+		if size > maxRecordSize {
+			return count, offset, nil
+		}
+		if cap(rec) < int(size) {
+			rec = make([]byte, size)
+		}
+		rec = rec[:size]
+		if _, err := io.ReadFull(r, rec); err != nil {
+			return count, offset, nil
+		}
+		if crc32.Checksum(rec, crcTable) != binary.LittleEndian.Uint32(rh[4:]) {
+			return count, offset, nil
+		}
+		count++
+		if fn != nil {
+			if err := fn(count, rec); err != nil {
+				return count, offset, err
+			}
+		}
+		offset += recordHeaderSize + int64(size)
+	}
+}
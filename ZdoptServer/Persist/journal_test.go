@@ -0,0 +1,138 @@
+package Persist
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+func replayAll(t *testing.T, path string) []string {
+	t.Helper()
+	var recs []string
+	if _, err := ReplayJournal(path, func(_ uint64, rec []byte) error {
+		recs = append(recs, string(rec))
+		return nil
+	}); err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	return recs
+}
+
+func TestJournalShortHeaderIsEmpty(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "j.log")
+	if err := os.WriteFile(path, journalMagic[:3], 0644); err != nil {
+		t.Fatal(err)
+	}
+	j, err := OpenJournal(path, JournalConfig{Durability: DurabilityStrict})
+	if err != nil {
+		t.Fatalf("open journal with torn header: %v", err)
+	}
+	if seq, err := j.Append([]byte("a")); err != nil || seq != 1 {
+		t.Fatalf("append = %d, %v", seq, err)
+	}
+	if err := j.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if got := replayAll(t, path); len(got) != 1 || got[0] != "a" {
+		t.Fatalf("replay = %q", got)
+	}
+}
+
+func TestJournalTornTailTruncatedOnOpen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "j.log")
+	j, err := OpenJournal(path, JournalConfig{Durability: DurabilityStrict})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, rec := range []string{"a", "b"} {
+		if _, err := j.Append([]byte(rec)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	j.Close()
+
+	// 模拟写到一半崩溃：记录头完整、数据不完整
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Write([]byte{10, 0, 0, 0, 1, 2, 3, 4, 'x'})
+	f.Close()
+
+	j, err = OpenJournal(path, JournalConfig{Durability: DurabilityStrict})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if seq, err := j.Append([]byte("c")); err != nil || seq != 3 {
+		t.Fatalf("append = %d, %v", seq, err)
+	}
+	j.Close()
+	if got := replayAll(t, path); len(got) != 3 || got[2] != "c" {
+		t.Fatalf("replay = %q", got)
+	}
+}
+
+func TestJournalRefusesWritesAfterUnrecoverableFailure(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "j.log")
+	j, err := OpenJournal(path, JournalConfig{Durability: DurabilityNone})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := j.Append([]byte("a")); err != nil {
+		t.Fatal(err)
+	}
+	// 文件句柄失效后写入与回退都失败
+	j.f.Close()
+	if _, err := j.Append([]byte("b")); err == nil {
+		t.Fatal("append on closed file succeeded")
+	}
+	if _, err := j.Append([]byte("c")); !errors.Is(err, ErrJournalFailed) {
+		t.Fatalf("append after failure = %v, want ErrJournalFailed", err)
+	}
+}
+
+// BenchmarkJournalAppend 各持久化级别下并发追加的吞吐（ns/op）与单次 Append 延迟分位
+func BenchmarkJournalAppend(b *testing.B) {
+	for _, d := range []Durability{DurabilityNone, DurabilityBatched, DurabilityStrict} {
+		b.Run(d.String(), func(b *testing.B) {
+			j, err := OpenJournal(filepath.Join(b.TempDir(), "j.log"), JournalConfig{Durability: d})
+			if err != nil {
+				b.Fatal(err)
+			}
+			defer j.Close()
+			rec := make([]byte, 128)
+			var (
+				mu        sync.Mutex
+				latencies = make([]time.Duration, 0, b.N)
+			)
+			b.SetBytes(int64(len(rec)))
+			b.SetParallelism(16) // 模拟多个 Actor 共享日志
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				local := make([]time.Duration, 0, 1024)
+				for pb.Next() {
+					start := time.Now()
+					if _, err := j.Append(rec); err != nil {
+						b.Error(err)
+						return
+					}
+					local = append(local, time.Since(start))
+				}
+				mu.Lock()
+				latencies = append(latencies, local...)
+				mu.Unlock()
+			})
+			b.StopTimer()
+			if len(latencies) == 0 {
+				return
+			}
+			sort.Slice(latencies, func(i, k int) bool { return latencies[i] < latencies[k] })
+			b.ReportMetric(float64(latencies[len(latencies)/2].Microseconds()), "p50-µs")
+			b.ReportMetric(float64(latencies[len(latencies)*99/100].Microseconds()), "p99-µs")
+		})
+	}
+}
@@ -0,0 +1,214 @@
+package Room
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+var ErrHistoryUnavailable = errors.New("history before this point is not retained")
+
+// Entry 一条房间历史记录（聊天、事件等）
+type Entry struct {
+	Seq    uint64    `json:"seq"`
+	Time   time.Time `json:"time"`
+	Kind   string    `json:"kind"` // 如 "chat"、"event"
+	Sender int64     `json:"sender,omitempty"`
+	Data   []byte    `json:"data,omitempty"`
+}
+
+// Storage 历史溢出存储：按分段追加，按序号向前分页读取
+type Storage interface {
+	Append(room string, entries []Entry) error
+	// Load 返回 Seq < before 的最近 limit 条记录（按 Seq 升序）
+	Load(room string, before uint64, limit int) ([]Entry, error)
+	Prune(room string, policy RetentionPolicy, now time.Time) error
+}
+
+// History 单个房间的有界历史：内存环形保留最近记录，超出部分按策略溢出到 Storage
+type History struct {
+	mu      sync.RWMutex
+	room    string
+	policy  RetentionPolicy
+	storage Storage
+	entries []Entry // 按 Seq 升序
+	seq     uint64
+	resumed bool // 已从 Storage 接续最新序号（没有 Storage 时无需接续）
+}
+
+// NewHistory 创建房间历史，storage 为空时不溢出。
+// 有 storage 时从中读取最新记录的序号，房间重建后序号接续，不与已溢出的记录重复；
+// 读取失败时在下次 Append/Page 重试，接续之前 Append 返回错误而不分配序号
+func NewHistory(room string, policy RetentionPolicy, storage Storage) *History {
+	if policy.MaxEntries <= 0 {
+		policy.MaxEntries = DefaultRetention().MaxEntries
+	}
+	h := &History{
+		room:    room,
+		policy:  policy,
+		storage: storage,
+		entries: make([]Entry, 0, policy.MaxEntries),
+	}
+	_ = h.resume()
+	return h
+}
+
+// resume 从 Storage 接续最新序号（调用方持有写锁或独占 h）
+func (h *History) resume() error {
+	if h.resumed {
+		return nil
+	}
+	if h.storage != nil {
+		last, err := h.storage.Load(h.room, math.MaxUint64, 1)
+		if err != nil {
+			return fmt.Errorf("load %s history: %w", h.room, err)
+		}
+		if len(last) > 0 {
+			h.seq = max(h.seq, last[len(last)-1].Seq)
+		}
+	}
+	h.resumed = true
+	return nil
+}
+
+// ensureResumed 尚未接续序号时重试
+func (h *History) ensureResumed() error {
+	h.mu.RLock()
+	resumed := h.resumed
+	h.mu.RUnlock()
+	if resumed {
+		return nil
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.resume()
+}
+
+// Append 追加一条记录并返回带序号的记录
+func (h *History) Append(kind string, sender int64, data []byte) (Entry, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if err := h.resume(); err != nil {
+		return Entry{}, err
+	}
+	h.seq++
+	e := Entry{Seq: h.seq, Time: time.Now(), Kind: kind, Sender: sender, Data: data}
+	h.entries = append(h.entries, e)
+	return e, h.enforce(e.Time)
+}
+
+// Page 分页查询 Seq < before 的最近 limit 条记录（升序），before 为 0 表示从最新开始
+// 内存不足 limit 条时从 Storage 补齐；更早的记录已不保留（未溢出、已淘汰或已过期）而不足 limit 条时，
+// 返回已有的记录与 ErrHistoryUnavailable
+func (h *History) Page(before uint64, limit int) ([]Entry, error) {
+	if limit <= 0 {
+		return nil, nil
+	}
+	if err := h.ensureResumed(); err != nil {
+		return nil, err
+	}
+
+	h.mu.RLock()
+	if before == 0 || before > h.seq {
+		before = h.seq + 1
+	}
+	end := len(h.entries)
+	for end > 0 && h.entries[end-1].Seq >= before {
+		end--
+	}
+	start := end - limit
+	if start < 0 {
+		start = 0
+	}
+	page := append([]Entry(nil), h.entries[start:end]...)
+	storage := h.storage
+	h.mu.RUnlock()
+
+	next := before
+	if len(page) > 0 {
+		next = page[0].Seq
+	}
+	if len(page) == limit || next <= 1 || storage == nil || !h.policy.Spill {
+		return h.complete(page, limit, before)
+	}
+
+	// 内存中的记录不够，向 Storage 继续取更早的部分
+	older, err := storage.Load(h.room, next, limit-len(page))
+	if err != nil {
+		return h.filterAge(page), fmt.Errorf("load %s history: %w", h.room, err)
+	}
+	return h.complete(append(older, page...), limit, before)
+}
+
+// complete 按时长过滤分页结果；不足 limit 条而更早的序号仍存在过时附带 ErrHistoryUnavailable
+func (h *History) complete(entries []Entry, limit int, before uint64) ([]Entry, error) {
+	entries = h.filterAge(entries)
+	first := before
+	if len(entries) > 0 {
+		first = entries[0].Seq
+	}
+	if len(entries) < limit && first > 1 {
+		return entries, fmt.Errorf("%w: %s before seq %d", ErrHistoryUnavailable, h.room, first)
+	}
+	return entries, nil
+}
+
+// Len 内存中的记录数
+func (h *History) Len() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.entries)
+}
+
+// Seq 最新记录序号
+func (h *History) Seq() uint64 {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.seq
+}
+
+// enforce 应用保留策略：淘汰过期记录，超出容量的部分溢出或丢弃（调用方持有写锁）
+func (h *History) enforce(now time.Time) error {
+	if h.policy.MaxAge > 0 {
+		cutoff := now.Add(-h.policy.MaxAge)
+		i := 0
+		for i < len(h.entries) && h.entries[i].Time.Before(cutoff) {
+			i++
+		}
+		h.entries = h.entries[i:]
+	}
+
+	overflow := len(h.entries) - h.policy.MaxEntries
+	if overflow <= 0 {
+		return nil
+	}
+	// 一次溢出一半容量，减少分段数量
+	if half := h.policy.MaxEntries / 2; overflow < half {
+		overflow = half
+	}
+	evicted := append([]Entry(nil), h.entries[:overflow]...)
+	h.entries = append(h.entries[:0], h.entries[overflow:]...)
+
+	if !h.policy.Spill || h.storage == nil {
+		return nil
+	}
+	if err := h.storage.Append(h.room, evicted); err != nil {
+		return fmt.Errorf("spill %s history: %w", h.room, err)
+	}
+	return h.storage.Prune(h.room, h.policy, now)
+}
+
+func (h *History) filterAge(entries []Entry) []Entry {
+	if h.policy.MaxAge <= 0 {
+		return entries
+	}
+	cutoff := time.Now().Add(-h.policy.MaxAge)
+	i := 0
+	for i < len(entries) && entries[i].Time.Before(cutoff) {
+		i++
+	}
+	return entries[i:]
+}
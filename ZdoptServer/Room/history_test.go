@@ -0,0 +1,117 @@
+package Room
+
+import (
+	"errors"
+	"testing"
+	"time"
+	"zdopt/ZdoptServer/Persist"
+)
+
+func appendN(t *testing.T, h *History, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		if _, err := h.Append("chat", 1, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func seqs(entries []Entry) []uint64 {
+	out := make([]uint64, len(entries))
+	for i, e := range entries {
+		out[i] = e.Seq
+	}
+	return out
+}
+
+func TestHistoryResumesSeqFromStorage(t *testing.T) {
+	storage := NewFileStorage(t.TempDir(), Persist.StoreOptions{})
+	policy := RetentionPolicy{MaxEntries: 4, Spill: true}
+	h := NewHistory("r1", policy, storage)
+	appendN(t, h, 10)
+	spilled, err := storage.Load("r1", 11, 10)
+	if err != nil || len(spilled) == 0 {
+		t.Fatalf("nothing spilled: %v", err)
+	}
+	last := spilled[len(spilled)-1].Seq
+
+	// 房间重建：序号接续已溢出的记录，分页不出现重复序号
+	h = NewHistory("r1", policy, storage)
+	if h.Seq() != last {
+		t.Fatalf("resumed seq = %d, want %d", h.Seq(), last)
+	}
+	e, err := h.Append("chat", 1, nil)
+	if err != nil || e.Seq != last+1 {
+		t.Fatalf("append after resume = %d, %v", e.Seq, err)
+	}
+	page, err := h.Page(0, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := seqs(page); len(got) != 3 || got[0] != last-1 || got[2] != last+1 {
+		t.Fatalf("page = %v", got)
+	}
+}
+
+// flakyStorage Load 在 fail 为 true 时失败
+type flakyStorage struct {
+	fail    bool
+	entries []Entry
+}
+
+func (s *flakyStorage) Append(room string, entries []Entry) error {
+	s.entries = append(s.entries, entries...)
+	return nil
+}
+
+func (s *flakyStorage) Load(room string, before uint64, limit int) ([]Entry, error) {
+	if s.fail {
+		return nil, errors.New("disk offline")
+	}
+	end := len(s.entries)
+	for end > 0 && s.entries[end-1].Seq >= before {
+		end--
+	}
+	return append([]Entry(nil), s.entries[max(0, end-limit):end]...), nil
+}
+
+func (s *flakyStorage) Prune(room string, policy RetentionPolicy, now time.Time) error { return nil }
+
+func TestHistoryRetriesResumeBeforeAppending(t *testing.T) {
+	storage := &flakyStorage{fail: true, entries: []Entry{{Seq: 41}, {Seq: 42}}}
+	h := NewHistory("r1", RetentionPolicy{MaxEntries: 8, Spill: true}, storage)
+	if _, err := h.Append("chat", 1, nil); err == nil {
+		t.Fatal("append before the last seq was loaded should fail")
+	}
+	if _, err := h.Page(0, 1); err == nil {
+		t.Fatal("page before the last seq was loaded should fail")
+	}
+	storage.fail = false
+	if e, err := h.Append("chat", 1, nil); err != nil || e.Seq != 43 {
+		t.Fatalf("append after recovery = %d, %v", e.Seq, err)
+	}
+}
+
+func TestHistoryReportsUnretainedEntries(t *testing.T) {
+	h := NewHistory("r1", RetentionPolicy{MaxEntries: 4}, nil)
+	appendN(t, h, 10)
+
+	page, err := h.Page(0, 2)
+	if err != nil || len(page) != 2 || page[1].Seq != 10 {
+		t.Fatalf("page within memory = %v, %v", seqs(page), err)
+	}
+	page, err = h.Page(0, 10)
+	if !errors.Is(err, ErrHistoryUnavailable) {
+		t.Fatalf("page beyond memory without spill = %v, want ErrHistoryUnavailable", err)
+	}
+	if len(page) != h.Len() || page[len(page)-1].Seq != 10 {
+		t.Fatalf("retained entries not returned: %v", seqs(page))
+	}
+
+	// 从第 1 条开始的完整历史不足 limit 条时不是错误
+	fresh := NewHistory("r2", RetentionPolicy{MaxEntries: 4}, nil)
+	appendN(t, fresh, 2)
+	if page, err := fresh.Page(0, 10); err != nil || len(page) != 2 {
+		t.Fatalf("short complete history = %v, %v", seqs(page), err)
+	}
+}
@@ -0,0 +1,44 @@
+package Room
+
+import (
+	"sync"
+	"time"
+)
+
+// RetentionPolicy 房间历史保留策略
+type RetentionPolicy struct {
+	MaxEntries  int           // 内存中保留的最大条数
+	MaxAge      time.Duration // 超过该时长的记录被淘汰，0 不限
+	Spill       bool          // 超出内存容量的记录写入 Storage，而不是直接丢弃
+	MaxSegments int           // Storage 中保留的最大分段数，0 不限
+}
+
+// DefaultRetention 默认保留策略：内存 200 条，不落盘
+func DefaultRetention() RetentionPolicy {
+	return RetentionPolicy{MaxEntries: 200}
+}
+
+var (
+	retentionMu sync.RWMutex
+	retention   = map[string]RetentionPolicy{}
+)
+
+// SetRetention 设置某类房间的保留策略
+func SetRetention(roomType string, policy RetentionPolicy) {
+	if policy.MaxEntries <= 0 {
+		policy.MaxEntries = DefaultRetention().MaxEntries
+	}
+	retentionMu.Lock()
+	defer retentionMu.Unlock()
+	retention[roomType] = policy
+}
+
+// RetentionFor 返回某类房间的保留策略，未设置时使用默认策略
+func RetentionFor(roomType string) RetentionPolicy {
+	retentionMu.RLock()
+	defer retentionMu.RUnlock()
+	if p, ok := retention[roomType]; ok {
+		return p
+	}
+	return DefaultRetention()
+}
@@ -0,0 +1,170 @@
+package Room
+
+import (
+	"zdopt/ZdoptServer/Discovery"
+	"zdopt/ZdoptServer/StateSync"
+)
+
+// Room 房间：同步状态与聊天/事件历史
+type Room struct {
+	ID      string
+	Type    string
+	Region  string             // 承载节点所在地域，由分配层（Discovery.Placer）决定
+	Node    Discovery.Endpoint // 经 WithPlacement 创建时选中的承载节点
+	State   *StateSync.RoomState
+	Sync    *StateSync.Scheduler // 状态更新经此合并，tick 结束时写入 State 并下发给订阅会话
+	history *History
+	release func() // 归还创建时占用的房间额度
+}
+
+// Admission 房间创建准入（如 License.Enforcer），成功时返回房间销毁时调用的释放函数
+type Admission interface {
+	AdmitRoom(id string) (func(), error)
+}
+
+// Option 房间构造选项
+type Option func(*roomOptions)
+
+type roomOptions struct {
+	storage      Storage
+	retention    *RetentionPolicy
+	stateHistory int
+	region       string
+	admissions   []Admission
+	placement    *placement
+}
+
+type placement struct {
+	placer    *Discovery.Placer
+	client    Discovery.ClientRegion
+	endpoints []Discovery.Endpoint
+}
+
+// WithStorage 设置历史溢出存储（保留策略开启 Spill 时生效）
+func WithStorage(storage Storage) Option {
+	return func(o *roomOptions) {
+		o.storage = storage
+	}
+}
+
+// WithRetention 覆盖房间类型的保留策略
+func WithRetention(policy RetentionPolicy) Option {
+	return func(o *roomOptions) {
+		o.retention = &policy
+	}
+}
+
+// WithStateHistory 设置状态同步增量缓冲长度
+func WithStateHistory(n int) Option {
+	return func(o *roomOptions) {
+		o.stateHistory = n
+	}
+}
+
+// WithRegion 设置房间所在地域
+func WithRegion(region string) Option {
+	return func(o *roomOptions) {
+		o.region = region
+	}
+}
+
+// WithPlacement 创建房间时由 placer 按发起会话的地域（如 Actor.Session.Region）在 endpoints 中就近选择承载节点，
+// 房间的 Region 与 Node 取选中的节点；仅 Create 生效，没有可用节点时 Create 返回 Discovery.ErrNoPlacement
+func WithPlacement(placer *Discovery.Placer, client Discovery.ClientRegion, endpoints []Discovery.Endpoint) Option {
+	return func(o *roomOptions) {
+		o.placement = &placement{placer: placer, client: client, endpoints: endpoints}
+	}
+}
+
+// WithAdmission 创建房间前经 admission 准入（如授权额度、维护调度），可多次使用，按顺序全部通过才创建，见 Create
+func WithAdmission(admission Admission) Option {
+	return func(o *roomOptions) {
+		o.admissions = append(o.admissions, admission)
+	}
+}
+
+// Create 创建房间：设置了分配时先选择承载节点，再依次经准入检查，任一拒绝时返回其错误；房间销毁时调用 Close 归还额度
+func Create(id, roomType string, opts ...Option) (*Room, error) {
+	o := roomOptions{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	var node Discovery.Endpoint
+	if pl := o.placement; pl != nil {
+		chosen, err := pl.placer.Place(pl.client, pl.endpoints)
+		if err != nil {
+			return nil, err
+		}
+		node = chosen.Endpoint
+		opts = append(opts, WithRegion(node.Region))
+	}
+	releases := make([]func(), 0, len(o.admissions))
+	release := func() {
+		for i := len(releases) - 1; i >= 0; i-- {
+			releases[i]()
+		}
+	}
+	for _, admission := range o.admissions {
+		rel, err := admission.AdmitRoom(id)
+		if err != nil {
+			// 已通过的准入归还额度
+			release()
+			return nil, err
+		}
+		releases = append(releases, rel)
+	}
+	r := NewRoom(id, roomType, opts...)
+	r.Node, r.release = node, release
+	return r, nil
+}
+
+// Close 销毁房间时调用，归还准入额度（可重复调用）
+func (r *Room) Close() {
+	if r.release != nil {
+		r.release()
+		r.release = nil
+	}
+}
+
+// NewRoom 创建房间（不经准入检查），历史保留策略默认取房间类型对应的配置
+func NewRoom(id, roomType string, opts ...Option) *Room {
+	o := roomOptions{stateHistory: 256}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	policy := RetentionFor(roomType)
+	if o.retention != nil {
+		policy = *o.retention
+	}
+	state := StateSync.NewRoomState(o.stateHistory)
+	return &Room{
+		ID:      id,
+		Type:    roomType,
+		Region:  o.region,
+		State:   state,
+		Sync:    StateSync.NewScheduler(state),
+		history: NewHistory(id, policy, o.storage),
+	}
+}
+
+// Record 记录一条聊天/事件历史
+func (r *Room) Record(kind string, sender int64, data []byte) (Entry, error) {
+	return r.history.Append(kind, sender, data)
+}
+
+// History 分页查询历史：返回序号小于 before 的最近 limit 条（升序），before 为 0 从最新开始；
+// 更早的记录已不保留时连同已有记录返回 ErrHistoryUnavailable（见 History.Page）
+func (r *Room) History(before uint64, limit int) ([]Entry, error) {
+	return r.history.Page(before, limit)
+}
+
+// Join 会话加入或断线重连后恢复房间同步：lastSeq 为客户端已确认的状态序号（首次加入为 0），
+// 见 StateSync.Scheduler.Subscribe；追帧内容与之后的实时更新按序在下一 tick 下发
+func (r *Room) Join(session int64, lastSeq uint64, quality *StateSync.SessionQuality, filter StateSync.InterestFilter, send StateSync.SendFunc) {
+	r.Sync.Subscribe(session, lastSeq, quality, filter, send)
+}
+
+// Leave 会话离开房间，停止同步
+func (r *Room) Leave(session int64) {
+	r.Sync.Unsubscribe(session)
+}
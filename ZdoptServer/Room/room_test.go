@@ -0,0 +1,46 @@
+package Room
+
+import (
+	"errors"
+	"testing"
+)
+
+// quota 计数准入，reject 非 nil 时拒绝
+type quota struct {
+	held   int
+	reject error
+}
+
+func (q *quota) AdmitRoom(id string) (func(), error) {
+	if q.reject != nil {
+		return nil, q.reject
+	}
+	q.held++
+	return func() { q.held-- }, nil
+}
+
+func TestCreateChecksEveryAdmission(t *testing.T) {
+	license, blocked := &quota{}, errors.New("maintenance")
+	gate := &quota{}
+	r, err := Create("r1", "arena", WithAdmission(license), WithAdmission(gate))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if license.held != 1 || gate.held != 1 {
+		t.Fatalf("held %d/%d after create", license.held, gate.held)
+	}
+	r.Close()
+	r.Close()
+	if license.held != 0 || gate.held != 0 {
+		t.Fatalf("held %d/%d after close", license.held, gate.held)
+	}
+
+	// 后面的准入拒绝时，前面已占用的额度归还
+	gate.reject = blocked
+	if _, err := Create("r2", "arena", WithAdmission(license), WithAdmission(gate)); !errors.Is(err, blocked) {
+		t.Fatalf("create = %v, want rejection", err)
+	}
+	if license.held != 0 {
+		t.Fatalf("license still holds %d after rejected create", license.held)
+	}
+}
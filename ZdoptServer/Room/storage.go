@@ -0,0 +1,167 @@
+package Room
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"zdopt/ZdoptServer/Persist"
+)
+
+const segmentExt = ".hist"
+
+// FileStorage 以持久化容器分段文件保存溢出的房间历史：<Dir>/<room>/<首序号>-<末序号>.hist
+type FileStorage struct {
+	Dir     string
+	Options Persist.StoreOptions
+	// Store 非 nil 时写入与淘汰经其存储锁执行，与备份互斥
+	Store *Persist.Store
+	mu    sync.Mutex
+}
+
+// NewFileStorage 创建文件溢出存储
+func NewFileStorage(dir string, opts Persist.StoreOptions) *FileStorage {
+	return &FileStorage{Dir: dir, Options: opts}
+}
+
+// NewStoreStorage 在已登记的持久化存储目录下创建溢出存储，参与备份与恢复
+func NewStoreStorage(store *Persist.Store, opts Persist.StoreOptions) *FileStorage {
+	return &FileStorage{Dir: store.Dir, Options: opts, Store: store}
+}
+
+type segment struct {
+	path        string
+	first, last uint64
+}
+
+func (s *FileStorage) Append(room string, entries []Entry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%020d-%020d%s", entries[0].Seq, entries[len(entries)-1].Seq, segmentExt)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.write(func() error {
+		return Persist.WriteFile(filepath.Join(s.roomDir(room), name), data, s.Options)
+	})
+}
+
+func (s *FileStorage) Load(room string, before uint64, limit int) ([]Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	segs, err := s.segments(room)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []Entry
+	for i := len(segs) - 1; i >= 0 && len(out) < limit; i-- {
+		if segs[i].first >= before {
+			continue
+		}
+		entries, err := readSegment(segs[i].path)
+		if err != nil {
+			return nil, err
+		}
+		end := len(entries)
+		for end > 0 && entries[end-1].Seq >= before {
+			end--
+		}
+		start := end - (limit - len(out))
+		if start < 0 {
+			start = 0
+		}
+		out = append(append([]Entry(nil), entries[start:end]...), out...)
+	}
+	return out, nil
+}
+
+// Prune 按分段数与时长淘汰最旧的分段
+func (s *FileStorage) Prune(room string, policy RetentionPolicy, now time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	segs, err := s.segments(room)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for i, seg := range segs {
+		drop := policy.MaxSegments > 0 && len(segs)-i > policy.MaxSegments
+		if !drop && policy.MaxAge > 0 {
+			if info, err := os.Stat(seg.path); err == nil && now.Sub(info.ModTime()) > policy.MaxAge {
+				drop = true
+			}
+		}
+		if !drop {
+			break
+		}
+		if err := s.write(func() error { return os.Remove(seg.path) }); err != nil && !errors.Is(err, os.ErrNotExist) {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// write 有 Store 时在存储锁下执行写操作
+func (s *FileStorage) write(fn func() error) error {
+	if s.Store == nil {
+		return fn()
+	}
+	return s.Store.Write(fn)
+}
+
+func (s *FileStorage) roomDir(room string) string {
+	return filepath.Join(s.Dir, filepath.Base(room))
+}
+
+// segments 按序号升序列出房间的分段文件
+func (s *FileStorage) segments(room string) ([]segment, error) {
+	files, err := os.ReadDir(s.roomDir(room))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var segs []segment
+	for _, f := range files {
+		name := f.Name()
+		if f.IsDir() || !strings.HasSuffix(name, segmentExt) {
+			continue
+		}
+		var seg segment
+		if _, err := fmt.Sscanf(strings.TrimSuffix(name, segmentExt), "%d-%d", &seg.first, &seg.last); err != nil {
+			continue
+		}
+		seg.path = filepath.Join(s.roomDir(room), name)
+		segs = append(segs, seg)
+	}
+	sort.Slice(segs, func(i, j int) bool { return segs[i].first < segs[j].first })
+	return segs, nil
+}
+
+func readSegment(path string) ([]Entry, error) {
+	data, err := Persist.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var entries []Entry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("decode %s: %w", path, err)
+	}
+	return entries, nil
+}
@@ -0,0 +1,202 @@
+package Rpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+	"zdopt/ZdoptServer/Actor"
+	"zdopt/ZdoptServer/Pb"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+var ErrUnexpectedReply = errors.New("unexpected rpc reply type")
+
+// TellFrom 在连接不可用时重试同一请求的次数与首次等待（之后翻倍）
+const (
+	tellRetries      = 3
+	tellRetryBackoff = 50 * time.Millisecond
+)
+
+// Client 调用远端节点经 Server 暴露的 Actor
+type Client struct {
+	stub ActorServiceClient
+	conn *grpc.ClientConn // Dial 创建时由 Close 关闭
+	node string           // SetNode 设置的本节点标识
+	seqs sync.Map         // seqKey -> *uint64，各发送方发往各名称的序号
+}
+
+type seqKey struct {
+	sender int64
+	name   string
+}
+
+// NewClient 基于已有连接创建客户端（自定义 TLS、拦截器等）
+func NewClient(conn grpc.ClientConnInterface) *Client {
+	return &Client{stub: NewActorServiceClient(conn)}
+}
+
+// Dial 连接远端节点，未给出选项时使用明文（集群内网）；按服务名经服务发现连接见 DialService。
+// 连接不做协议比对，需要时调用 Handshake 或改用 Connect
+func Dial(addr string, opts ...grpc.DialOption) (*Client, error) {
+	if len(opts) == 0 {
+		opts = []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
+	}
+	conn, err := grpc.NewClient(addr, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{stub: NewActorServiceClient(conn), conn: conn}, nil
+}
+
+// Connect 连接远端节点并以 node 为本节点标识完成握手（见 Handshake），协议不兼容时关闭连接并返回错误
+func Connect(ctx context.Context, addr, node string, opts ...grpc.DialOption) (*Client, *Pb.SchemaReport, error) {
+	c, err := Dial(addr, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+	c.SetNode(node)
+	report, err := c.Handshake(ctx)
+	if err != nil {
+		_ = c.Close()
+		return nil, report, err
+	}
+	return c, report, nil
+}
+
+// Handshake 与远端交换协议注册表摘要并返回比对报告（以本端为 local），不一致时记录日志；
+// 同名消息定义不一致时返回包装 Pb.ErrSchemaIncompatible 的错误，此时远端也会拒绝本节点之后的调用
+func (c *Client) Handshake(ctx context.Context) (*Pb.SchemaReport, error) {
+	local := Pb.LocalDigest()
+	reply, err := c.stub.Handshake(ctx, &HandshakeRequest{Node: c.node, Schema: local.GetMessages()})
+	if status.Code(err) == codes.FailedPrecondition {
+		return nil, fmt.Errorf("%w: rejected by remote: %s", Pb.ErrSchemaIncompatible, status.Convert(err).Message())
+	}
+	if err != nil {
+		return nil, err
+	}
+	report := Pb.CompareSchemas(local, &Pb.SchemaDigest{Messages: reply.GetSchema()})
+	if report.Status() != Pb.SchemaCompatible {
+		logger.Printf("handshake with node %q: %s", reply.GetNode(), report)
+	}
+	return report, report.Err()
+}
+
+// Close 关闭 Dial 创建的连接
+func (c *Client) Close() error {
+	if c.conn == nil {
+		return nil
+	}
+	return c.conn.Close()
+}
+
+// SetNode 设置本节点标识（须在首次调用前设置）：之后 TellFrom / AskFrom 携带发送方 Actor ID 与序号，
+// 远端目标开启重放保护（Actor.EnableReplayProtection）时据此丢弃重复投递
+func (c *Client) SetNode(node string) {
+	c.node = node
+}
+
+// request 打包请求，self 非空且已设置节点时携带发送方与其发往 name 的下一个序号
+func (c *Client) request(self *Actor.BaseActor, name string, msg proto.Message) (*ActorRequest, error) {
+	packed, err := anypb.New(msg)
+	if err != nil {
+		return nil, err
+	}
+	req := &ActorRequest{Name: name, Message: packed}
+	if self != nil && self.ID() != 0 && c.node != "" {
+		v, _ := c.seqs.LoadOrStore(seqKey{sender: self.ID(), name: name}, new(uint64))
+		req.Node, req.Sender, req.Seq = c.node, self.ID(), atomic.AddUint64(v.(*uint64), 1)
+	}
+	return req, nil
+}
+
+// Ask 向远端名为 name 的 Actor 发送请求并等待回复，超时由 ctx 控制；失败时返回 gRPC 状态错误
+func (c *Client) Ask(ctx context.Context, name string, msg proto.Message) (proto.Message, error) {
+	req, err := c.request(nil, name, msg)
+	if err != nil {
+		return nil, err
+	}
+	return c.ask(ctx, req)
+}
+
+func (c *Client) ask(ctx context.Context, req *ActorRequest) (proto.Message, error) {
+	reply, err := c.stub.Ask(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return reply.GetMessage().UnmarshalNew()
+}
+
+// Tell 单向投递到远端名为 name 的 Actor，进入目标邮箱后返回
+func (c *Client) Tell(ctx context.Context, name string, msg proto.Message) error {
+	packed, err := anypb.New(msg)
+	if err != nil {
+		return err
+	}
+	_, err = c.stub.Tell(ctx, &ActorRequest{Name: name, Message: packed})
+	return err
+}
+
+// TellFrom 以 self 的身份单向投递：请求携带发送方与序号，连接不可用时以相同序号重试，
+// 远端已收到过（AlreadyExists）视为成功，因此目标开启重放保护时重试不会造成重复处理。未设置节点时不重试，等同 Tell
+func (c *Client) TellFrom(ctx context.Context, self *Actor.BaseActor, name string, msg proto.Message) error {
+	req, err := c.request(self, name, msg)
+	if err != nil {
+		return err
+	}
+	backoff := tellRetryBackoff
+	for attempt := 0; ; attempt++ {
+		_, err = c.stub.Tell(ctx, req)
+		code := status.Code(err)
+		if code == codes.OK || code == codes.AlreadyExists {
+			return nil
+		}
+		// 未携带序号的请求重试可能重复处理
+		if code != codes.Unavailable || req.GetSeq() == 0 || attempt >= tellRetries {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// AskFrom 在 Actor 内发起远程请求：调用不阻塞消息循环，结果经 self.Execute 回到 self 的消息循环中交给 fn。
+// 已设置节点时请求携带 self 的发送方与序号
+func (c *Client) AskFrom(ctx context.Context, self *Actor.BaseActor, name string, msg proto.Message, fn func(proto.Message, error)) {
+	req, err := c.request(self, name, msg)
+	go func() {
+		var resp proto.Message
+		if err == nil {
+			resp, err = c.ask(ctx, req)
+		}
+		self.Execute(func() {
+			fn(resp, err)
+		})
+	}()
+}
+
+// AskAs 类型化的 Ask，回复类型不是 R 时返回 ErrUnexpectedReply
+func AskAs[R proto.Message](ctx context.Context, c *Client, name string, msg proto.Message) (R, error) {
+	var zero R
+	resp, err := c.Ask(ctx, name, msg)
+	if err != nil {
+		return zero, err
+	}
+	r, ok := resp.(R)
+	if !ok {
+		return zero, fmt.Errorf("%w: got %s, want %T", ErrUnexpectedReply, proto.MessageName(resp), zero)
+	}
+	return r, nil
+}
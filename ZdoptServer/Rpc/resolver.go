@@ -0,0 +1,111 @@
+package Rpc
+
+import (
+	"sync"
+	"zdopt/ZdoptServer/Actor"
+	"zdopt/ZdoptServer/Discovery"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/resolver"
+)
+
+// Scheme 经 Discovery.Watcher 解析的目标地址前缀，目标形如 zdopt:///<服务名>
+const Scheme = "zdopt"
+
+// ResolveFailedTopic 远端服务解析失败时在事件总线上发布的主题，消息为 ResolveFailed
+const ResolveFailedTopic = "rpc.resolve.failed"
+
+// ResolveFailed 远端服务解析失败事件：Watcher 保留上一次成功的端点（Endpoints），已建立的连接不变
+type ResolveFailed struct {
+	Service   string
+	Endpoints []Discovery.Endpoint
+	Err       error
+}
+
+// PublishResolveFailures 把 watcher 的解析失败以 ResolveFailed 发布到 bus，返回取消函数
+func PublishResolveFailures(watcher *Discovery.Watcher, bus *Actor.EventBus) (cancel func()) {
+	return watcher.Notify(func(ev Discovery.Event) {
+		if ev.Err != nil {
+			_, _ = bus.Publish(ResolveFailedTopic, ResolveFailed{Service: ev.Service, Endpoints: ev.Endpoints, Err: ev.Err})
+		}
+	})
+}
+
+// watcherBuilder 把 Watcher 的端点变化转换为 gRPC 解析结果
+type watcherBuilder struct {
+	watcher *Discovery.Watcher
+}
+
+// NewResolverBuilder 基于 watcher 创建 gRPC 解析器，端点集合变化时连接随之迁移到新的节点
+func NewResolverBuilder(watcher *Discovery.Watcher) resolver.Builder {
+	return &watcherBuilder{watcher: watcher}
+}
+
+func (b *watcherBuilder) Scheme() string { return Scheme }
+
+func (b *watcherBuilder) Build(target resolver.Target, cc resolver.ClientConn, _ resolver.BuildOptions) (resolver.Resolver, error) {
+	r := &watcherResolver{watcher: b.watcher, service: target.Endpoint(), cc: cc}
+	r.cancel = b.watcher.Notify(func(ev Discovery.Event) {
+		if ev.Service != r.service {
+			return
+		}
+		switch {
+		case ev.Err == nil:
+			r.update(ev.Endpoints)
+		case len(ev.Endpoints) == 0:
+			// 从未解析成功：把原因交给 gRPC，调用以该错误失败而不是一直等待
+			r.cc.ReportError(ev.Err)
+		}
+		// 解析失败时 Watcher 保留上一次的端点，连接不变
+	})
+	b.watcher.Watch(r.service)
+	if endpoints := b.watcher.Endpoints(r.service); len(endpoints) > 0 {
+		r.update(endpoints)
+	}
+	return r, nil
+}
+
+type watcherResolver struct {
+	watcher *Discovery.Watcher
+	service string
+	cc      resolver.ClientConn
+	cancel  func()
+
+	mu sync.Mutex
+}
+
+func (r *watcherResolver) update(endpoints []Discovery.Endpoint) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(endpoints) == 0 {
+		r.cc.ReportError(Discovery.ErrNoEndpoints)
+		return
+	}
+	addrs := make([]resolver.Address, 0, len(endpoints))
+	for _, e := range endpoints {
+		addrs = append(addrs, resolver.Address{Addr: e.Addr()})
+	}
+	_ = r.cc.UpdateState(resolver.State{Addresses: addrs})
+}
+
+// ResolveNow 连接失败时 gRPC 请求重新解析
+func (r *watcherResolver) ResolveNow(resolver.ResolveNowOptions) {
+	r.watcher.Refresh()
+}
+
+// Close 连接关闭时停止监听并撤销对服务的 Watch，Watcher 不再为其解析
+func (r *watcherResolver) Close() {
+	r.cancel()
+	r.watcher.Unwatch(r.service)
+}
+
+// DialService 按服务名连接远端节点，端点由 watcher（需已 Start）提供并随其刷新更新；
+// 未给出选项时使用明文（集群内网）
+func DialService(watcher *Discovery.Watcher, service string, opts ...grpc.DialOption) (*Client, error) {
+	if len(opts) == 0 {
+		opts = []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
+	}
+	opts = append(opts, grpc.WithResolvers(NewResolverBuilder(watcher)))
+	return Dial(Scheme+":///"+service, opts...)
+}
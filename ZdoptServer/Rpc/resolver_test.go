@@ -0,0 +1,182 @@
+package Rpc
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+	"zdopt/ZdoptServer/Actor"
+	"zdopt/ZdoptServer/Discovery"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// namedBackend 对任何请求回复自己的名字
+type namedBackend struct {
+	UnimplementedActorServiceServer
+	name string
+}
+
+func (b *namedBackend) Ask(context.Context, *ActorRequest) (*ActorReply, error) {
+	packed, err := anypb.New(wrapperspb.String(b.name))
+	if err != nil {
+		return nil, err
+	}
+	return &ActorReply{Message: packed}, nil
+}
+
+func startBackend(t *testing.T, name string) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	gs := grpc.NewServer()
+	RegisterActorServiceServer(gs, &namedBackend{name: name})
+	go gs.Serve(ln)
+	t.Cleanup(gs.Stop)
+	return ln.Addr().String()
+}
+
+func TestDialServiceFollowsEndpointChanges(t *testing.T) {
+	a, b := startBackend(t, "a"), startBackend(t, "b")
+	static := Discovery.NewStaticResolver()
+	if err := static.Set("room", a); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	watcher := Discovery.NewWatcher(static, time.Hour, nil)
+	watcher.Start(ctx)
+
+	c, err := DialService(watcher, "room")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	ask := func() string {
+		askCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+		reply, err := AskAs[*wrapperspb.StringValue](askCtx, c, "any", wrapperspb.String("who"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return reply.GetValue()
+	}
+	if got := ask(); got != "a" {
+		t.Fatalf("first call reached %q, want a", got)
+	}
+
+	// 端点集合换成 b 后，调用迁移到 b
+	if err := static.Set("room", b); err != nil {
+		t.Fatal(err)
+	}
+	watcher.Refresh()
+	deadline := time.Now().Add(5 * time.Second)
+	for ask() != "b" {
+		if time.Now().After(deadline) {
+			t.Fatal("calls still reach a after the endpoint set changed")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// countingResolver 记录解析次数，err 非 nil 时解析失败
+type countingResolver struct {
+	Discovery.Resolver
+	calls atomic.Int64
+	err   error
+}
+
+func (r *countingResolver) Resolve(ctx context.Context, service string) ([]Discovery.Endpoint, error) {
+	r.calls.Add(1)
+	if r.err != nil {
+		return nil, r.err
+	}
+	return r.Resolver.Resolve(ctx, service)
+}
+
+func TestClosedClientStopsResolving(t *testing.T) {
+	static := Discovery.NewStaticResolver()
+	if err := static.Set("room", startBackend(t, "a")); err != nil {
+		t.Fatal(err)
+	}
+	counting := &countingResolver{Resolver: static}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	watcher := Discovery.NewWatcher(counting, time.Hour, nil)
+	watcher.Start(ctx)
+
+	c, err := DialService(watcher, "room")
+	if err != nil {
+		t.Fatal(err)
+	}
+	askCtx, askCancel := context.WithTimeout(ctx, 5*time.Second)
+	defer askCancel()
+	if _, err := AskAs[*wrapperspb.StringValue](askCtx, c, "any", wrapperspb.String("who")); err != nil {
+		t.Fatal(err)
+	}
+	c.Close()
+
+	// 关闭后 Watcher 丢弃该服务，之后的刷新不再解析
+	deadline := time.Now().Add(time.Second)
+	for len(watcher.Endpoints("room")) > 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if len(watcher.Endpoints("room")) > 0 {
+		t.Fatal("service still watched after the client closed")
+	}
+	time.Sleep(20 * time.Millisecond)
+	before := counting.calls.Load()
+	watcher.Refresh()
+	time.Sleep(50 * time.Millisecond)
+	if after := counting.calls.Load(); after != before {
+		t.Fatalf("%d resolutions after close", after-before)
+	}
+}
+
+func TestResolveFailuresPublishedOnEventBus(t *testing.T) {
+	sys := Actor.NewSystem()
+	defer sys.Stop()
+	failures := make(chan ResolveFailed, 4)
+	if _, err := sys.EventBus().Tap(ResolveFailedTopic, func(_ string, msg interface{}) {
+		failures <- msg.(ResolveFailed)
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	down := errors.New("registry unreachable")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	watcher := Discovery.NewWatcher(&countingResolver{err: down}, time.Hour, nil)
+	defer PublishResolveFailures(watcher, sys.EventBus())()
+	watcher.Start(ctx)
+
+	c, err := DialService(watcher, "room")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	// 连接在首次调用时才开始解析；从未解析成功时，调用以解析错误失败而不是等到超时
+	askCtx, askCancel := context.WithTimeout(ctx, 5*time.Second)
+	defer askCancel()
+	start := time.Now()
+	if _, err := c.Ask(askCtx, "any", wrapperspb.String("who")); err == nil {
+		t.Fatal("ask succeeded without endpoints")
+	}
+	if time.Since(start) > 4*time.Second {
+		t.Fatal("ask waited for its deadline instead of failing on the resolver error")
+	}
+	select {
+	case ev := <-failures:
+		if ev.Service != "room" || !errors.Is(ev.Err, down) {
+			t.Fatalf("failure event %+v", ev)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("resolution failure not published")
+	}
+}
@@ -0,0 +1,222 @@
+package Rpc
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"net"
+	"sort"
+	"sync"
+	"time"
+	"zdopt/ZdoptServer/Actor"
+	"zdopt/ZdoptServer/Logs"
+	"zdopt/ZdoptServer/Metrics"
+	"zdopt/ZdoptServer/Pb"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+var (
+	ErrNotExposed = errors.New("actor not exposed over rpc")
+
+	rpcCalls     = expvar.NewMap("rpc.calls")  // 按 <ask|tell|handshake>.<gRPC 状态码> 统计服务端处理的调用
+	schemaChecks = expvar.NewMap("rpc.schema") // 节点握手的协议比对结果：compatible / degraded / incompatible
+
+	logger = Logs.CreateConsoleLogConfig("Rpc")
+)
+
+// Server 把选定的具名 Actor 暴露为 gRPC 服务 ActorService：只有经 Expose 登记的名称可被远端调用，
+// 请求中的消息解包后按 System.AskName / SendName 投递，处理器返回的 protobuf 消息作为回复。
+// 请求消息须为已在 Pb 注册的类型，其他 Any 类型一律以 InvalidArgument 拒绝。
+// 握手时协议不兼容的节点之后的调用以 FailedPrecondition 拒绝，直到其以兼容的协议重新握手
+type Server struct {
+	UnimplementedActorServiceServer
+	system   *Actor.System
+	node     string
+	mu       sync.RWMutex
+	exposed  map[string]struct{}
+	rejected sync.Map // 节点标识 -> *Pb.SchemaReport，握手时协议不兼容的节点
+}
+
+// NewServer 创建服务，names 为初始暴露的 Actor 名称
+func NewServer(system *Actor.System, names ...string) *Server {
+	s := &Server{system: system, exposed: make(map[string]struct{})}
+	s.Expose(names...)
+	return s
+}
+
+// SetNode 设置本节点标识，握手时回复给对端
+func (s *Server) SetNode(node string) {
+	s.node = node
+}
+
+// Expose 允许远端调用这些名称（或别名）的 Actor
+func (s *Server) Expose(names ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, name := range names {
+		s.exposed[name] = struct{}{}
+	}
+}
+
+// Unexpose 停止暴露该名称
+func (s *Server) Unexpose(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.exposed, name)
+}
+
+// Exposed 已暴露的名称（排序）
+func (s *Server) Exposed() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	names := make([]string, 0, len(s.exposed))
+	for name := range s.exposed {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Register 注册到 gRPC 服务器
+func (s *Server) Register(gs grpc.ServiceRegistrar) {
+	RegisterActorServiceServer(gs, s)
+}
+
+// Start 在 addr 上启动只承载本服务的 gRPC 服务器，ctx 结束时优雅停止；返回实际监听地址
+func (s *Server) Start(ctx context.Context, addr string, opts ...grpc.ServerOption) (net.Addr, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	gs := grpc.NewServer(opts...)
+	s.Register(gs)
+	go func() {
+		<-ctx.Done()
+		gs.GracefulStop()
+	}()
+	go func() {
+		_ = gs.Serve(ln)
+	}()
+	return ln.Addr(), nil
+}
+
+func (s *Server) Ask(ctx context.Context, req *ActorRequest) (*ActorReply, error) {
+	start := time.Now()
+	msg, err := s.unpack(req)
+	if err != nil {
+		return nil, record("ask", err)
+	}
+	defer Metrics.DefaultLatency.Since("grpc", string(proto.MessageName(msg)), start)
+	resp, err := s.system.AskNameFrom(ctx, req.GetName(), origin(req), msg)
+	if err != nil {
+		return nil, record("ask", toStatus(err))
+	}
+	pm, ok := resp.(proto.Message)
+	if !ok {
+		return nil, record("ask", status.Errorf(codes.Internal, "reply %T is not a protobuf message", resp))
+	}
+	packed, err := anypb.New(pm)
+	if err != nil {
+		return nil, record("ask", status.Error(codes.Internal, err.Error()))
+	}
+	record("ask", nil)
+	return &ActorReply{Message: packed}, nil
+}
+
+func (s *Server) Tell(ctx context.Context, req *ActorRequest) (*TellAck, error) {
+	msg, err := s.unpack(req)
+	if err != nil {
+		return nil, record("tell", err)
+	}
+	if err := s.system.SendNameFrom(req.GetName(), origin(req), msg); err != nil {
+		return nil, record("tell", toStatus(err))
+	}
+	record("tell", nil)
+	return &TellAck{}, nil
+}
+
+// Handshake 比对调用方的协议注册表摘要并记录日志：同名消息定义不一致时以 FailedPrecondition 拒绝并拒绝该节点之后的调用，
+// 否则回复本端摘要
+func (s *Server) Handshake(ctx context.Context, req *HandshakeRequest) (*HandshakeReply, error) {
+	local := Pb.LocalDigest()
+	report := Pb.CompareSchemas(local, &Pb.SchemaDigest{Messages: req.GetSchema()})
+	schemaChecks.Add(report.Status().String(), 1)
+	if report.Status() != Pb.SchemaCompatible {
+		logger.Printf("node %q handshake: %s", req.GetNode(), report)
+	}
+	if err := report.Err(); err != nil {
+		if req.GetNode() != "" {
+			s.rejected.Store(req.GetNode(), report)
+		}
+		return nil, record("handshake", status.Error(codes.FailedPrecondition, err.Error()))
+	}
+	s.rejected.Delete(req.GetNode())
+	record("handshake", nil)
+	return &HandshakeReply{Node: s.node, Schema: local.GetMessages()}, nil
+}
+
+// unpack 校验目标已暴露并按 Pb 注册的类型解包消息，未注册的类型不会被构造
+func (s *Server) unpack(req *ActorRequest) (proto.Message, error) {
+	if report, ok := s.rejected.Load(req.GetNode()); ok && req.GetNode() != "" {
+		return nil, status.Errorf(codes.FailedPrecondition, "node %q: %v", req.GetNode(), report.(*Pb.SchemaReport).Err())
+	}
+	s.mu.RLock()
+	_, ok := s.exposed[req.GetName()]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, status.Errorf(codes.PermissionDenied, "%v: %q", ErrNotExposed, req.GetName())
+	}
+	if req.GetMessage() == nil {
+		return nil, status.Error(codes.InvalidArgument, "request carries no message")
+	}
+	name := string(req.GetMessage().MessageName())
+	if !Pb.IsRegistered(name) {
+		return nil, status.Errorf(codes.InvalidArgument, "message type %q not registered", req.GetMessage().GetTypeUrl())
+	}
+	msg, err := Pb.DeserializeByName(name, req.GetMessage().GetValue())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "unpack %s: %v", req.GetMessage().GetTypeUrl(), err)
+	}
+	return msg, nil
+}
+
+// origin 请求携带的远端发送方，目标开启重放保护时按其去重
+func origin(req *ActorRequest) Actor.Origin {
+	return Actor.Origin{Node: req.GetNode(), Sender: req.GetSender(), Seq: req.GetSeq()}
+}
+
+// toStatus 把 Actor 错误映射为 gRPC 状态，处理器自行返回的状态错误原样保留
+func toStatus(err error) error {
+	if st, ok := status.FromError(err); ok {
+		return st.Err()
+	}
+	code := codes.Unknown
+	switch {
+	case errors.Is(err, Actor.ErrNameNotFound), errors.Is(err, Actor.ErrActorNotFound):
+		code = codes.NotFound
+	case errors.Is(err, Actor.ErrSystemStopping):
+		code = codes.Unavailable
+	case errors.Is(err, Actor.ErrMailboxFull):
+		code = codes.ResourceExhausted
+	case errors.Is(err, Actor.ErrAskUnsupported):
+		code = codes.Unimplemented
+	case errors.Is(err, Actor.ErrDuplicateMessage):
+		code = codes.AlreadyExists
+	case errors.Is(err, context.DeadlineExceeded):
+		code = codes.DeadlineExceeded
+	case errors.Is(err, context.Canceled):
+		code = codes.Canceled
+	}
+	return status.Error(code, err.Error())
+}
+
+// record 按方法与状态码计数，原样返回 err
+func record(method string, err error) error {
+	rpcCalls.Add(method+"."+status.Code(err).String(), 1)
+	return err
+}
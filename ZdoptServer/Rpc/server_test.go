@@ -0,0 +1,218 @@
+package Rpc
+
+import (
+	"context"
+	"testing"
+	"time"
+	"zdopt/ZdoptServer/Actor"
+	"zdopt/ZdoptServer/Pb"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// slowActor 处理 DataPacket 时阻塞到 release 关闭，用于占满邮箱
+type slowActor struct {
+	*Actor.BaseActor
+}
+
+func (a *slowActor) Start()                     {}
+func (a *slowActor) Stop()                      {}
+func (a *slowActor) Update(delta time.Duration) {}
+func (a *slowActor) Receive(msg interface{})    {}
+
+func request(t *testing.T, name string, msg proto.Message) *ActorRequest {
+	t.Helper()
+	packed, err := anypb.New(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &ActorRequest{Name: name, Message: packed}
+}
+
+func TestRejectsUnregisteredMessageType(t *testing.T) {
+	sys := Actor.NewSystem()
+	defer sys.Stop()
+	s := NewServer(sys, "echo")
+	_, err := s.Ask(context.Background(), request(t, "echo", wrapperspb.String("x")))
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("ask with unregistered type = %v, want InvalidArgument", err)
+	}
+	if _, err := s.Tell(context.Background(), request(t, "echo", wrapperspb.String("x"))); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("tell with unregistered type = %v, want InvalidArgument", err)
+	}
+}
+
+func TestAskHonoursDeadlineOnFullMailbox(t *testing.T) {
+	sys := Actor.NewSystem()
+	defer sys.Stop()
+	started, release := make(chan struct{}, 1), make(chan struct{})
+	defer close(release)
+	a := &slowActor{BaseActor: sys.NewBaseActor(2, Actor.WithMailboxPolicy(Actor.Block))}
+	Actor.RegisterHandler(a.BaseActor, func(*Pb.DataPacket) {
+		select {
+		case started <- struct{}{}:
+		default:
+		}
+		<-release
+	})
+	sys.AddGroupActors(1, []func() Actor.Actor{func() Actor.Actor { return a }})
+	if err := sys.RegisterName("slow", a); err != nil {
+		t.Fatal(err)
+	}
+
+	// 第一条阻塞在处理中，再投递到邮箱容量把邮箱占满
+	if err := sys.SendName("slow", &Pb.DataPacket{}); err != nil {
+		t.Fatal(err)
+	}
+	<-started
+	for i := 0; i < 2; i++ {
+		if err := sys.SendName("slow", &Pb.DataPacket{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	s := NewServer(sys, "slow")
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	req := request(t, "slow", &Pb.DataPacket{Content: "ping"})
+	result := make(chan error, 1)
+	go func() {
+		_, err := s.Ask(ctx, req)
+		result <- err
+	}()
+	select {
+	case err := <-result:
+		if status.Code(err) != codes.DeadlineExceeded {
+			t.Fatalf("ask on full mailbox = %v, want DeadlineExceeded", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("rpc ask still blocked on full mailbox after its deadline")
+	}
+}
+
+func TestRedeliveredRequestsAreDeduplicated(t *testing.T) {
+	sys := Actor.NewSystem()
+	defer sys.Stop()
+	got := make(chan string, 16)
+	a := &slowActor{BaseActor: sys.NewBaseActor(16, Actor.WithProcessingMode(Actor.Sequential))}
+	Actor.HandleAsk(a.BaseActor, func(_ *Actor.MessageContext, p *Pb.DataPacket) (*Pb.DataPacket, error) {
+		got <- p.GetContent()
+		return p, nil
+	})
+	a.EnableReplayProtection(Actor.ReplayConfig{})
+	sys.AddGroupActors(1, []func() Actor.Actor{func() Actor.Actor { return a }})
+	if err := sys.RegisterName("echo", a); err != nil {
+		t.Fatal(err)
+	}
+	s := NewServer(sys, "echo")
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	// 同一请求重复到达（客户端超时后重试）只处理一次，重复的 Ask 以 AlreadyExists 拒绝
+	tell := request(t, "echo", &Pb.DataPacket{Content: "tell"})
+	tell.Node, tell.Sender, tell.Seq = "node-a", 1, 1
+	for i := 0; i < 2; i++ {
+		if _, err := s.Tell(ctx, tell); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ask := request(t, "echo", &Pb.DataPacket{Content: "ask"})
+	ask.Node, ask.Sender, ask.Seq = "node-a", 1, 2
+	if _, err := s.Ask(ctx, ask); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Ask(ctx, ask); status.Code(err) != codes.AlreadyExists {
+		t.Fatalf("redelivered ask = %v, want AlreadyExists", err)
+	}
+
+	// 经客户端发送时每条消息取新序号
+	addr, err := s.Start(ctx, "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := Dial(addr.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	c.SetNode("node-b")
+	self := sys.NewBaseActor(1)
+	if err := sys.Register(sys.NextID(), &slowActor{BaseActor: self}); err != nil {
+		t.Fatal(err)
+	}
+	for _, content := range []string{"first", "second"} {
+		if err := c.TellFrom(ctx, self, "echo", &Pb.DataPacket{Content: content}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, want := range []string{"tell", "ask", "first", "second"} {
+		select {
+		case content := <-got:
+			if content != want {
+				t.Fatalf("processed %q, want %q", content, want)
+			}
+		case <-ctx.Done():
+			t.Fatalf("%q never processed", want)
+		}
+	}
+	select {
+	case content := <-got:
+		t.Fatalf("duplicate %q processed", content)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestHandshakeExchangesSchemaDigest(t *testing.T) {
+	sys := Actor.NewSystem()
+	defer sys.Stop()
+	a := &slowActor{BaseActor: sys.NewBaseActor(16)}
+	Actor.HandleAsk(a.BaseActor, func(_ *Actor.MessageContext, p *Pb.DataPacket) (*Pb.DataPacket, error) { return p, nil })
+	sys.AddGroupActors(1, []func() Actor.Actor{func() Actor.Actor { return a }})
+	if err := sys.RegisterName("echo", a); err != nil {
+		t.Fatal(err)
+	}
+	s := NewServer(sys, "echo")
+	s.SetNode("node-b")
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	addr, err := s.Start(ctx, "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c, report, err := Connect(ctx, addr.String(), "node-a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	if report.Status() != Pb.SchemaCompatible {
+		t.Fatalf("report = %s", report)
+	}
+
+	// 同名消息字段哈希不同：握手被拒绝，该节点之后的调用也被拒绝
+	digest := Pb.LocalDigest()
+	digest.Messages[string(Pb.TypeName(&Pb.DataPacket{}))]++
+	if _, err := c.stub.Handshake(ctx, &HandshakeRequest{Node: "node-a", Schema: digest.GetMessages()}); status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("incompatible handshake = %v, want FailedPrecondition", err)
+	}
+	self := sys.NewBaseActor(1)
+	if err := sys.Register(sys.NextID(), &slowActor{BaseActor: self}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.TellFrom(ctx, self, "echo", &Pb.DataPacket{}); status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("tell from rejected node = %v, want FailedPrecondition", err)
+	}
+
+	// 以兼容的协议重新握手后恢复
+	if _, err := c.Handshake(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.TellFrom(ctx, self, "echo", &Pb.DataPacket{}); err != nil {
+		t.Fatalf("tell after compatible handshake = %v", err)
+	}
+}
@@ -0,0 +1,44 @@
+package Script
+
+import (
+	"context"
+	"fmt"
+	"zdopt/ZdoptServer/Actor"
+)
+
+// Message 脚本经 zdopt.send 发给Actor的消息，Actor 以 Actor.RegisterHandler[*Script.Message] 处理
+type Message struct {
+	Script string // 发送脚本名
+	Name   string
+	Data   map[string]interface{}
+}
+
+// SnapshotRequest 脚本读取Actor快照的请求：Actor 以 HandleAsk 注册处理器并返回 map[string]interface{}，
+// 快照在Actor自身消息循环中生成，脚本不直接读Actor内部状态
+type SnapshotRequest struct{}
+
+// Bindings 脚本可调用的宿主能力，未设置的能力在脚本中调用时报错
+type Bindings struct {
+	Send     func(id int64, msg *Message) error
+	Snapshot func(ctx context.Context, id int64) (map[string]interface{}, error)
+}
+
+// SystemBindings 基于Actor系统的绑定：按 ID 投递消息、以 Ask 读取快照
+func SystemBindings(sys *Actor.System) Bindings {
+	return Bindings{
+		Send: func(id int64, msg *Message) error {
+			return sys.Send(id, msg)
+		},
+		Snapshot: func(ctx context.Context, id int64) (map[string]interface{}, error) {
+			resp, err := sys.Ask(ctx, id, &SnapshotRequest{})
+			if err != nil {
+				return nil, err
+			}
+			snap, ok := resp.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("actor %d snapshot: unexpected reply %T", id, resp)
+			}
+			return snap, nil
+		},
+	}
+}
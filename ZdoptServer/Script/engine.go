@@ -0,0 +1,473 @@
+package Script
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"zdopt/ZdoptServer/Logs"
+
+	lua "github.com/yuin/gopher-lua"
+)
+
+var (
+	ErrScriptNotFound = errors.New("script not found")
+	ErrScriptTimeout  = errors.New("script call timed out")
+	ErrBudgetExceeded = errors.New("script cpu budget exceeded")
+	ErrScriptUnloaded = errors.New("script unloaded")
+	ErrNoSuchFunction = errors.New("script function not defined")
+	ErrEngineClosed   = errors.New("script engine closed")
+
+	scriptCalls    = expvar.NewMap("script.calls")
+	scriptErrors   = expvar.NewMap("script.errors")
+	scriptTimeouts = expvar.NewMap("script.timeouts")
+	scriptReloads  = expvar.NewInt("script.reloads")
+)
+
+// Config 脚本引擎参数，零值字段使用默认值
+type Config struct {
+	Dir             string        // 脚本目录，加载其中的 *.lua，文件名（不含扩展名）为脚本名
+	Timeout         time.Duration // 单次调用（含加载时的顶层代码与定时器回调）的时间上限
+	CPUBudget       time.Duration // 每个脚本在 BudgetWindow 内可用的执行时间，超出后调用被拒绝直到窗口结束
+	BudgetWindow    time.Duration
+	ReloadInterval  time.Duration // 检查脚本文件变更的间隔
+	MaxTimers       int           // 每个脚本未触发的定时器上限
+	CallStackSize   int
+	RegistryMaxSize int // Lua 栈/寄存器上限，约束单个脚本的内存
+	Logger          *log.Logger
+}
+
+// DefaultConfig 默认参数：单次调用 20ms，每秒最多 100ms CPU，每秒检查一次文件变更
+func DefaultConfig() Config {
+	return Config{
+		Timeout:         20 * time.Millisecond,
+		CPUBudget:       100 * time.Millisecond,
+		BudgetWindow:    time.Second,
+		ReloadInterval:  time.Second,
+		MaxTimers:       256,
+		CallStackSize:   256,
+		RegistryMaxSize: 64 * 1024,
+	}
+}
+
+// Status 脚本状态
+type Status struct {
+	Name      string        `json:"name"`
+	Path      string        `json:"path"`
+	LoadedAt  time.Time     `json:"loaded_at"`
+	Calls     int64         `json:"calls"`
+	Errors    int64         `json:"errors"`
+	Timeouts  int64         `json:"timeouts"`
+	CPU       time.Duration `json:"cpu"` // 当前预算窗口内已用时间
+	Timers    int           `json:"timers"`
+	LastError string        `json:"last_error,omitempty"` // 最近一次调用或重载失败
+}
+
+// Engine 脚本引擎：每个脚本一个独立沙箱，调用串行执行；文件变更时重新加载，加载失败保留旧版本
+type Engine struct {
+	cfg      Config
+	bindings Bindings
+	logger   *log.Logger
+
+	mu      sync.RWMutex
+	scripts map[string]*script
+	failed  map[string]string // 加载失败且没有旧版本可用的脚本 -> 错误
+	closed  bool
+}
+
+// NewEngine 创建脚本引擎，需调用 Load 或 Run 加载脚本
+func NewEngine(cfg Config, bindings Bindings) *Engine {
+	def := DefaultConfig()
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = def.Timeout
+	}
+	if cfg.CPUBudget <= 0 {
+		cfg.CPUBudget = def.CPUBudget
+	}
+	if cfg.BudgetWindow <= 0 {
+		cfg.BudgetWindow = def.BudgetWindow
+	}
+	if cfg.ReloadInterval <= 0 {
+		cfg.ReloadInterval = def.ReloadInterval
+	}
+	if cfg.MaxTimers <= 0 {
+		cfg.MaxTimers = def.MaxTimers
+	}
+	if cfg.CallStackSize <= 0 {
+		cfg.CallStackSize = def.CallStackSize
+	}
+	if cfg.RegistryMaxSize <= 0 {
+		cfg.RegistryMaxSize = def.RegistryMaxSize
+	}
+	logger := cfg.Logger
+	if logger == nil {
+		logger = Logs.CreateConsoleLogConfig("Script")
+	}
+	return &Engine{
+		cfg:      cfg,
+		bindings: bindings,
+		logger:   logger,
+		scripts:  make(map[string]*script),
+		failed:   make(map[string]string),
+	}
+}
+
+// Run 加载脚本并按 ReloadInterval 检查文件变更，直到 ctx 结束后关闭引擎
+func (e *Engine) Run(ctx context.Context) {
+	e.Load()
+	ticker := time.NewTicker(e.cfg.ReloadInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			e.Close()
+			return
+		case <-ticker.C:
+			e.Load()
+		}
+	}
+}
+
+// Load 扫描脚本目录：加载新增与变更的脚本，卸载已删除的脚本。返回本次加载失败的错误
+func (e *Engine) Load() error {
+	paths, err := filepath.Glob(filepath.Join(e.cfg.Dir, "*.lua"))
+	if err != nil {
+		return err
+	}
+	seen := make(map[string]bool, len(paths))
+	var errs error
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		name := strings.TrimSuffix(filepath.Base(path), ".lua")
+		seen[name] = true
+
+		e.mu.RLock()
+		old := e.scripts[name]
+		unchanged := old != nil && old.modTime.Equal(info.ModTime()) && old.size == info.Size()
+		e.mu.RUnlock()
+		if unchanged {
+			continue
+		}
+		if err := e.load(name, path, info, old); err != nil {
+			errs = errors.Join(errs, err)
+		}
+	}
+
+	e.mu.Lock()
+	var removed []*script
+	for name, s := range e.scripts {
+		if !seen[name] {
+			removed = append(removed, s)
+			delete(e.scripts, name)
+		}
+	}
+	for name := range e.failed {
+		if !seen[name] {
+			delete(e.failed, name)
+		}
+	}
+	e.mu.Unlock()
+	for _, s := range removed {
+		e.logger.Printf("script %s removed", s.name)
+		s.close()
+	}
+	return errs
+}
+
+// Check 只编译 dir 中的 *.lua 而不执行，返回目录不可读或各脚本的语法错误（启动自检用）
+func Check(dir string) error {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", dir)
+	}
+	paths, err := filepath.Glob(filepath.Join(dir, "*.lua"))
+	if err != nil {
+		return err
+	}
+	L := lua.NewState(lua.Options{SkipOpenLibs: true})
+	defer L.Close()
+	var errs error
+	for _, path := range paths {
+		if _, err := L.LoadFile(path); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("compile %s: %w", filepath.Base(path), err))
+		}
+	}
+	return errs
+}
+
+// load 编译并执行脚本顶层代码，成功后替换旧版本
+func (e *Engine) load(name, path string, info os.FileInfo, old *script) error {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("script %s: %w", name, err)
+	}
+	s := newScript(e, name, path, info)
+	if err := s.init(string(src)); err != nil {
+		err = fmt.Errorf("script %s: load: %w", name, err)
+		e.mu.Lock()
+		if old != nil {
+			// 保留旧版本继续运行，但记住失败的文件版本，避免每次轮询重复报错
+			old.modTime, old.size = info.ModTime(), info.Size()
+			old.mu.Lock()
+			old.lastError = err.Error()
+			old.mu.Unlock()
+		} else {
+			e.failed[name] = err.Error()
+		}
+		e.mu.Unlock()
+		e.logger.Printf("%v", err)
+		return err
+	}
+
+	e.mu.Lock()
+	if e.closed {
+		e.mu.Unlock()
+		s.close()
+		return ErrEngineClosed
+	}
+	e.scripts[name] = s
+	delete(e.failed, name)
+	e.mu.Unlock()
+	if old != nil {
+		old.close()
+		scriptReloads.Add(1)
+		e.logger.Printf("script %s reloaded", name)
+	}
+	return nil
+}
+
+// Call 调用脚本中的全局函数，返回值转为 Go 值
+func (e *Engine) Call(name, fn string, args ...interface{}) (interface{}, error) {
+	e.mu.RLock()
+	s, ok := e.scripts[name]
+	e.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrScriptNotFound, name)
+	}
+	return s.callGlobal(fn, args)
+}
+
+// Emit 向定义了函数 event 的全部脚本广播事件，返回各脚本的错误
+func (e *Engine) Emit(event string, args ...interface{}) error {
+	e.mu.RLock()
+	targets := make([]*script, 0, len(e.scripts))
+	for _, s := range e.scripts {
+		targets = append(targets, s)
+	}
+	e.mu.RUnlock()
+	sort.Slice(targets, func(i, j int) bool { return targets[i].name < targets[j].name })
+
+	var errs error
+	for _, s := range targets {
+		if _, err := s.callGlobal(event, args); err != nil && !errors.Is(err, ErrNoSuchFunction) {
+			errs = errors.Join(errs, err)
+		}
+	}
+	return errs
+}
+
+// Statuses 全部脚本状态（含加载失败的脚本），按名称排序
+func (e *Engine) Statuses() []Status {
+	e.mu.RLock()
+	out := make([]Status, 0, len(e.scripts)+len(e.failed))
+	for _, s := range e.scripts {
+		out = append(out, s.status())
+	}
+	for name, err := range e.failed {
+		out = append(out, Status{Name: name, LastError: err})
+	}
+	e.mu.RUnlock()
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// Close 卸载全部脚本并取消未触发的定时器
+func (e *Engine) Close() {
+	e.mu.Lock()
+	e.closed = true
+	scripts := e.scripts
+	e.scripts = make(map[string]*script)
+	e.mu.Unlock()
+	for _, s := range scripts {
+		s.close()
+	}
+}
+
+// script 一个已加载的脚本版本
+type script struct {
+	engine  *Engine
+	name    string
+	path    string
+	modTime time.Time // 文件版本，由 Engine.mu 保护
+	size    int64
+
+	mu        sync.Mutex
+	L         *lua.LState
+	loadedAt  time.Time
+	closed    bool
+	timers    map[int]*time.Timer
+	nextTimer int
+	window    time.Time     // 当前预算窗口起点
+	used      time.Duration // 当前窗口已用时间
+	calls     int64
+	errors    int64
+	timeouts  int64
+	lastError string
+}
+
+func newScript(e *Engine, name, path string, info os.FileInfo) *script {
+	return &script{
+		engine:  e,
+		name:    name,
+		path:    path,
+		modTime: info.ModTime(),
+		size:    info.Size(),
+		timers:  make(map[int]*time.Timer),
+	}
+}
+
+// init 创建沙箱并执行顶层代码
+func (s *script) init(src string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.L = s.newState()
+	s.loadedAt = time.Now()
+	fn, err := s.L.Load(strings.NewReader(src), s.name+".lua")
+	if err != nil {
+		s.L.Close()
+		return luaError(err)
+	}
+	if _, err := s.invoke(fn, nil); err != nil {
+		s.stopTimers()
+		s.L.Close()
+		return err
+	}
+	return nil
+}
+
+// callGlobal 调用全局函数
+func (s *script) callGlobal(name string, args []interface{}) (interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil, fmt.Errorf("%w: %s", ErrScriptUnloaded, s.name)
+	}
+	fn, ok := s.L.GetGlobal(name).(*lua.LFunction)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s.%s", ErrNoSuchFunction, s.name, name)
+	}
+	return s.invoke(fn, args)
+}
+
+// fire 定时器回调
+func (s *script) fire(id int, fn *lua.LFunction) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	if _, ok := s.timers[id]; !ok {
+		return
+	}
+	delete(s.timers, id)
+	if _, err := s.invoke(fn, nil); err != nil {
+		s.engine.logger.Printf("script %s timer: %v", s.name, err)
+	}
+}
+
+// invoke 在超时与 CPU 预算约束下执行函数（调用方持有 s.mu）
+func (s *script) invoke(fn *lua.LFunction, args []interface{}) (interface{}, error) {
+	now := time.Now()
+	if now.Sub(s.window) >= s.engine.cfg.BudgetWindow {
+		s.window, s.used = now, 0
+	}
+	if s.used >= s.engine.cfg.CPUBudget {
+		s.errors++
+		scriptErrors.Add(s.name, 1)
+		return nil, fmt.Errorf("%w: %s used %v within %v", ErrBudgetExceeded, s.name, s.used, s.engine.cfg.BudgetWindow)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.engine.cfg.Timeout)
+	defer cancel()
+	s.L.SetContext(ctx)
+	defer s.L.RemoveContext()
+
+	largs := make([]lua.LValue, len(args))
+	for i, arg := range args {
+		largs[i] = toLua(s.L, arg)
+	}
+	top := s.L.GetTop()
+	err := s.L.CallByParam(lua.P{Fn: fn, NRet: 1, Protect: true}, largs...)
+	s.used += time.Since(now)
+	s.calls++
+	scriptCalls.Add(s.name, 1)
+	if err != nil {
+		s.L.SetTop(top)
+		s.errors++
+		scriptErrors.Add(s.name, 1)
+		if ctx.Err() != nil {
+			s.timeouts++
+			scriptTimeouts.Add(s.name, 1)
+			err = fmt.Errorf("%w: %s after %v", ErrScriptTimeout, s.name, s.engine.cfg.Timeout)
+		} else {
+			err = luaError(err)
+		}
+		s.lastError = err.Error()
+		return nil, err
+	}
+	ret := fromLua(s.L.Get(-1))
+	s.L.SetTop(top)
+	return ret, nil
+}
+
+func (s *script) status() Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	used := s.used
+	if time.Since(s.window) >= s.engine.cfg.BudgetWindow {
+		used = 0
+	}
+	return Status{
+		Name:      s.name,
+		Path:      s.path,
+		LoadedAt:  s.loadedAt,
+		Calls:     s.calls,
+		Errors:    s.errors,
+		Timeouts:  s.timeouts,
+		CPU:       used,
+		Timers:    len(s.timers),
+		LastError: s.lastError,
+	}
+}
+
+// close 卸载：取消定时器并释放 Lua 状态，进行中的调用结束后生效
+func (s *script) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	s.closed = true
+	s.stopTimers()
+	s.L.Close()
+}
+
+func (s *script) stopTimers() {
+	for id, t := range s.timers {
+		t.Stop()
+		delete(s.timers, id)
+	}
+}
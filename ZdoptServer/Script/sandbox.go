@@ -0,0 +1,209 @@
+package Script
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	lua "github.com/yuin/gopher-lua"
+)
+
+// 沙箱只开放纯计算库，不开放 io/os/package/debug 与动态加载
+var sandboxLibs = []struct {
+	name string
+	open lua.LGFunction
+}{
+	{lua.BaseLibName, lua.OpenBase},
+	{lua.TabLibName, lua.OpenTable},
+	{lua.StringLibName, lua.OpenString},
+	{lua.MathLibName, lua.OpenMath},
+}
+
+var sandboxRemoved = []string{"dofile", "loadfile", "load", "loadstring", "require", "module", "collectgarbage", "getfenv", "setfenv", "_printregs"}
+
+// newState 创建沙箱 Lua 状态并注入 zdopt 模块
+func (s *script) newState() *lua.LState {
+	L := lua.NewState(lua.Options{
+		SkipOpenLibs:    true,
+		CallStackSize:   s.engine.cfg.CallStackSize,
+		RegistrySize:    1024,
+		RegistryMaxSize: s.engine.cfg.RegistryMaxSize,
+	})
+	for _, lib := range sandboxLibs {
+		L.Push(L.NewFunction(lib.open))
+		L.Push(lua.LString(lib.name))
+		L.Call(1, 0)
+	}
+	for _, name := range sandboxRemoved {
+		L.SetGlobal(name, lua.LNil)
+	}
+	L.SetGlobal("print", L.NewFunction(s.luaLog))
+
+	mod := L.NewTable()
+	L.SetFuncs(mod, map[string]lua.LGFunction{
+		"send":     s.luaSend,
+		"after":    s.luaAfter,
+		"cancel":   s.luaCancel,
+		"snapshot": s.luaSnapshot,
+		"log":      s.luaLog,
+		"now":      luaNow,
+	})
+	L.SetGlobal("zdopt", mod)
+	return L
+}
+
+// zdopt.send(id, name, data) -> ok, err
+func (s *script) luaSend(L *lua.LState) int {
+	id := L.CheckInt64(1)
+	name := L.CheckString(2)
+	data, _ := fromLua(L.OptTable(3, L.NewTable())).(map[string]interface{})
+	if s.engine.bindings.Send == nil {
+		L.RaiseError("zdopt.send is not available")
+	}
+	if err := s.engine.bindings.Send(id, &Message{Script: s.name, Name: name, Data: data}); err != nil {
+		L.Push(lua.LFalse)
+		L.Push(lua.LString(err.Error()))
+		return 2
+	}
+	L.Push(lua.LTrue)
+	return 1
+}
+
+// zdopt.after(ms, fn) -> timer id；回调受同样的超时与 CPU 预算约束，脚本重载后未触发的定时器作废
+func (s *script) luaAfter(L *lua.LState) int {
+	ms := L.CheckNumber(1)
+	fn := L.CheckFunction(2)
+	if len(s.timers) >= s.engine.cfg.MaxTimers {
+		L.RaiseError("too many pending timers (max %d)", s.engine.cfg.MaxTimers)
+	}
+	s.nextTimer++
+	id := s.nextTimer
+	s.timers[id] = time.AfterFunc(time.Duration(float64(ms)*float64(time.Millisecond)), func() {
+		s.fire(id, fn)
+	})
+	L.Push(lua.LNumber(id))
+	return 1
+}
+
+// zdopt.cancel(id) -> 是否取消成功
+func (s *script) luaCancel(L *lua.LState) int {
+	id := L.CheckInt(1)
+	t, ok := s.timers[id]
+	if ok {
+		t.Stop()
+		delete(s.timers, id)
+	}
+	L.Push(lua.LBool(ok))
+	return 1
+}
+
+// zdopt.snapshot(id) -> table 或 nil, err
+func (s *script) luaSnapshot(L *lua.LState) int {
+	id := L.CheckInt64(1)
+	if s.engine.bindings.Snapshot == nil {
+		L.RaiseError("zdopt.snapshot is not available")
+	}
+	snap, err := s.engine.bindings.Snapshot(L.Context(), id)
+	if err != nil {
+		L.Push(lua.LNil)
+		L.Push(lua.LString(err.Error()))
+		return 2
+	}
+	L.Push(toLua(L, snap))
+	return 1
+}
+
+// zdopt.log(...) 与 print 相同，带脚本名前缀写入引擎日志
+func (s *script) luaLog(L *lua.LState) int {
+	parts := make([]string, L.GetTop())
+	for i := range parts {
+		parts[i] = L.ToStringMeta(L.Get(i + 1)).String()
+	}
+	s.engine.logger.Printf("[script %s] %s", s.name, strings.Join(parts, "\t"))
+	return 0
+}
+
+// zdopt.now() -> 毫秒时间戳
+func luaNow(L *lua.LState) int {
+	L.Push(lua.LNumber(time.Now().UnixMilli()))
+	return 1
+}
+
+// fromLua Lua 值转 Go 值：连续整数键的表转为切片，其余表转为 map
+func fromLua(v lua.LValue) interface{} {
+	switch v := v.(type) {
+	case lua.LBool:
+		return bool(v)
+	case lua.LNumber:
+		return float64(v)
+	case lua.LString:
+		return string(v)
+	case *lua.LTable:
+		if n := v.Len(); n > 0 {
+			arr := make([]interface{}, 0, n)
+			for i := 1; i <= n; i++ {
+				arr = append(arr, fromLua(v.RawGetInt(i)))
+			}
+			return arr
+		}
+		m := make(map[string]interface{})
+		v.ForEach(func(k, val lua.LValue) {
+			m[k.String()] = fromLua(val)
+		})
+		return m
+	default:
+		return nil
+	}
+}
+
+// toLua Go 值转 Lua 值，不支持的类型转为字符串
+func toLua(L *lua.LState, v interface{}) lua.LValue {
+	switch v := v.(type) {
+	case nil:
+		return lua.LNil
+	case bool:
+		return lua.LBool(v)
+	case string:
+		return lua.LString(v)
+	case int:
+		return lua.LNumber(v)
+	case int32:
+		return lua.LNumber(v)
+	case int64:
+		return lua.LNumber(v)
+	case uint32:
+		return lua.LNumber(v)
+	case uint64:
+		return lua.LNumber(v)
+	case float32:
+		return lua.LNumber(v)
+	case float64:
+		return lua.LNumber(v)
+	case []interface{}:
+		t := L.CreateTable(len(v), 0)
+		for _, item := range v {
+			t.Append(toLua(L, item))
+		}
+		return t
+	case map[string]interface{}:
+		t := L.CreateTable(0, len(v))
+		for k, item := range v {
+			t.RawSetString(k, toLua(L, item))
+		}
+		return t
+	case error:
+		return lua.LString(v.Error())
+	default:
+		return lua.LString(fmt.Sprint(v))
+	}
+}
+
+// luaError 去掉 gopher-lua 错误中的堆栈，保留首行
+func luaError(err error) error {
+	var apiErr *lua.ApiError
+	if errors.As(err, &apiErr) && apiErr.Object != nil {
+		return errors.New(apiErr.Object.String())
+	}
+	return err
+}
@@ -0,0 +1,14 @@
+//go:build unix
+
+package SelfTest
+
+import "syscall"
+
+// freeDisk 返回目录所在文件系统对当前用户可用的剩余空间
+func freeDisk(dir string) (uint64, error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(dir, &st); err != nil {
+		return 0, err
+	}
+	return st.Bavail * uint64(st.Bsize), nil
+}
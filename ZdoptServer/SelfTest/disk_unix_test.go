@@ -0,0 +1,15 @@
+//go:build unix
+
+package SelfTest
+
+import "testing"
+
+func TestCheckDirRequiresFreeSpace(t *testing.T) {
+	dir := t.TempDir()
+	if err := CheckDir(dir, 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := CheckDir(dir, 1<<62); err == nil {
+		t.Fatal("impossible free-space requirement satisfied")
+	}
+}
@@ -0,0 +1,224 @@
+package SelfTest
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"zdopt/ZdoptServer/Pb"
+)
+
+var ErrSelfTestFailed = errors.New("self test failed")
+
+// Config 自检所需的启动配置
+type Config struct {
+	Port        int      // 服务监听端口（同时检查 UDP 与 TCP）
+	Dirs        []string // 需要可写的目录（日志、持久化等）
+	MinFreeDisk uint64   // 每个目录所在磁盘的最小剩余空间（字节），0 表示不检查
+}
+
+// DefaultConfig 默认自检配置
+func DefaultConfig(port int) Config {
+	return Config{
+		Port:        port,
+		Dirs:        []string{"logs"},
+		MinFreeDisk: 100 << 20,
+	}
+}
+
+// Result 单项检查结果
+type Result struct {
+	Name     string
+	Err      error
+	Duration time.Duration
+}
+
+// Report 汇总报告
+type Report struct {
+	Results []Result
+}
+
+// Failed 返回所有失败项
+func (r *Report) Failed() []Result {
+	var failed []Result
+	for _, res := range r.Results {
+		if res.Err != nil {
+			failed = append(failed, res)
+		}
+	}
+	return failed
+}
+
+// Err 汇总所有失败项为一个错误，全部通过时返回 nil
+func (r *Report) Err() error {
+	failed := r.Failed()
+	if len(failed) == 0 {
+		return nil
+	}
+	errs := make([]error, 0, len(failed))
+	for _, res := range failed {
+		errs = append(errs, fmt.Errorf("%s: %w", res.Name, res.Err))
+	}
+	return fmt.Errorf("%w: %w", ErrSelfTestFailed, errors.Join(errs...))
+}
+
+// String 生成可读的自检报告
+func (r *Report) String() string {
+	var sb strings.Builder
+	for _, res := range r.Results {
+		status := "OK  "
+		if res.Err != nil {
+			status = "FAIL"
+		}
+		fmt.Fprintf(&sb, "[%s] %-20s %8s", status, res.Name, res.Duration.Round(time.Microsecond))
+		if res.Err != nil {
+			fmt.Fprintf(&sb, "  %v", res.Err)
+		}
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+// Check 单项检查
+type Check struct {
+	Name string
+	Fn   func(cfg Config) error
+}
+
+// DefaultChecks 内置检查项
+func DefaultChecks() []Check {
+	return []Check{
+		{Name: "config", Fn: checkConfig},
+		{Name: "port", Fn: checkPort},
+		{Name: "dirs", Fn: checkDirs},
+		{Name: "clock", Fn: checkClock},
+		{Name: "proto-registry", Fn: checkProtoRegistry},
+	}
+}
+
+// Run 执行全部检查（不会中途退出），返回汇总报告
+func Run(cfg Config, extra ...Check) *Report {
+	report := &Report{}
+	for _, c := range append(DefaultChecks(), extra...) {
+		start := time.Now()
+		err := c.Fn(cfg)
+		report.Results = append(report.Results, Result{
+			Name:     c.Name,
+			Err:      err,
+			Duration: time.Since(start),
+		})
+	}
+	return report
+}
+
+func checkConfig(cfg Config) error {
+	if cfg.Port <= 0 || cfg.Port > 65535 {
+		return fmt.Errorf("port %d out of range", cfg.Port)
+	}
+	for _, dir := range cfg.Dirs {
+		if strings.TrimSpace(dir) == "" {
+			return errors.New("empty directory path")
+		}
+	}
+	return nil
+}
+
+func checkPort(cfg Config) error {
+	addr := ":" + strconv.Itoa(cfg.Port)
+	return errors.Join(CheckListen("udp", addr), CheckListen("tcp", addr))
+}
+
+// CheckListen 检查 network（udp / tcp）上的 addr 当前可以监听
+func CheckListen(network, addr string) error {
+	switch network {
+	case "udp", "udp4", "udp6":
+		l, err := net.ListenPacket(network, addr)
+		if err != nil {
+			return fmt.Errorf("%s %s unavailable: %w", network, addr, err)
+		}
+		return l.Close()
+	default:
+		l, err := net.Listen(network, addr)
+		if err != nil {
+			return fmt.Errorf("%s %s unavailable: %w", network, addr, err)
+		}
+		return l.Close()
+	}
+}
+
+func checkDirs(cfg Config) error {
+	var errs []error
+	for _, dir := range cfg.Dirs {
+		errs = append(errs, CheckDir(dir, cfg.MinFreeDisk))
+	}
+	return errors.Join(errs...)
+}
+
+// CheckDir 检查目录可创建、可写，且所在磁盘剩余空间不少于 minFree（0 表示不检查）
+func CheckDir(dir string, minFree uint64) error {
+	if strings.TrimSpace(dir) == "" {
+		return errors.New("empty directory path")
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("create %s: %w", dir, err)
+	}
+
+	probe, err := os.CreateTemp(dir, ".selftest-*")
+	if err != nil {
+		return fmt.Errorf("%s not writable: %w", dir, err)
+	}
+	_ = probe.Close()
+	_ = os.Remove(probe.Name())
+
+	if minFree == 0 {
+		return nil
+	}
+	free, err := freeDisk(dir)
+	if err != nil {
+		return fmt.Errorf("stat %s: %w", dir, err)
+	}
+	if free < minFree {
+		abs, _ := filepath.Abs(dir)
+		return fmt.Errorf("%s: %d MB free, need %d MB", abs, free>>20, minFree>>20)
+	}
+	return nil
+}
+
+// checkClock 校验单调时钟不回退，且与墙上时钟偏差在合理范围
+func checkClock(Config) error {
+	const samples = 1000
+	const sleep = 10 * time.Millisecond
+
+	prev := time.Now()
+	for i := 0; i < samples; i++ {
+		now := time.Now()
+		if now.Before(prev) {
+			return errors.New("monotonic clock went backwards")
+		}
+		prev = now
+	}
+
+	start := time.Now()
+	wall := start.Round(0)
+	time.Sleep(sleep)
+	mono := time.Since(start)
+	wallElapsed := time.Now().Round(0).Sub(wall)
+	if mono < sleep {
+		return fmt.Errorf("monotonic clock too slow: slept %v, measured %v", sleep, mono)
+	}
+	if drift := wallElapsed - mono; drift > time.Second || drift < -time.Second {
+		return fmt.Errorf("wall clock drift %v against monotonic clock", drift)
+	}
+	return nil
+}
+
+func checkProtoRegistry(Config) error {
+	if missing := Pb.UnregisteredTypes(); len(missing) > 0 {
+		return fmt.Errorf("unregistered message types: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
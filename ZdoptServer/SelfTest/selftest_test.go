@@ -0,0 +1,86 @@
+package SelfTest
+
+import (
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// freePort 取一个当前空闲的端口
+func freePort(t *testing.T) int {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port
+}
+
+func TestRunPassesOnHealthyConfig(t *testing.T) {
+	cfg := Config{Port: freePort(t), Dirs: []string{filepath.Join(t.TempDir(), "logs")}}
+	report := Run(cfg)
+	if err := report.Err(); err != nil {
+		t.Fatalf("self test failed:\n%s", report)
+	}
+	if len(report.Results) != len(DefaultChecks()) {
+		t.Fatalf("%d results, want one per check", len(report.Results))
+	}
+	if _, err := os.Stat(cfg.Dirs[0]); err != nil {
+		t.Fatalf("directory not created: %v", err)
+	}
+}
+
+func TestRunReportsEveryFailure(t *testing.T) {
+	boom := errors.New("boom")
+	report := Run(Config{Port: 0}, Check{Name: "extra", Fn: func(Config) error { return boom }})
+	failed := map[string]bool{}
+	for _, res := range report.Failed() {
+		failed[res.Name] = true
+	}
+	if !failed["config"] || !failed["extra"] || failed["clock"] {
+		t.Fatalf("failed checks = %v", failed)
+	}
+	err := report.Err()
+	if !errors.Is(err, ErrSelfTestFailed) || !errors.Is(err, boom) {
+		t.Fatalf("report error = %v", err)
+	}
+	if out := report.String(); !strings.Contains(out, "[FAIL] extra") || !strings.Contains(out, "[OK  ] clock") {
+		t.Fatalf("report:\n%s", out)
+	}
+}
+
+func TestCheckListenDetectsBoundPort(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	if err := CheckListen("tcp", l.Addr().String()); err == nil {
+		t.Fatal("bound port reported available")
+	}
+	if err := CheckListen("udp", "127.0.0.1:0"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCheckDir(t *testing.T) {
+	dir := t.TempDir()
+	if err := CheckDir(filepath.Join(dir, "a", "b"), 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := CheckDir(" ", 0); err == nil {
+		t.Fatal("blank path accepted")
+	}
+	// 路径被普通文件占用
+	file := filepath.Join(dir, "file")
+	if err := os.WriteFile(file, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := CheckDir(filepath.Join(file, "sub"), 0); err == nil {
+		t.Fatal("directory under a file accepted")
+	}
+}
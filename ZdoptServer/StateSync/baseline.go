@@ -0,0 +1,161 @@
+package StateSync
+
+import (
+	"errors"
+	"sync"
+)
+
+var ErrHistoryTruncated = errors.New("delta history truncated, baseline required")
+
+// Delta 一次状态变化，Seq 在房间内单调递增
+type Delta struct {
+	Seq     uint64
+	Key     Key
+	State   interface{}
+	Deleted bool
+	Reset   bool // 基线开始：客户端清空本地状态，随后同一 Seq 的条目组成完整快照（仅出现在追帧内容中）
+}
+
+// Baseline 某一序号时刻的完整房间状态
+type Baseline struct {
+	Seq     uint64
+	Entries map[Key]interface{}
+}
+
+// InterestFilter 兴趣过滤：返回 false 的键不下发给该玩家
+type InterestFilter func(key Key) bool
+
+// CatchUp 迟到加入/断线恢复时的追帧计划：先发 Baseline（可为空），再按序发 Deltas
+type CatchUp struct {
+	Baseline *Baseline
+	Deltas   []Delta
+}
+
+// RoomState 房间权威状态 + 最近增量环形缓冲，用于生成基线快照与追帧序列
+type RoomState struct {
+	mu      sync.RWMutex
+	seq     uint64
+	state   map[Key]interface{}
+	history []Delta // 环形缓冲
+	start   int     // 最早增量在 history 中的位置
+	size    int
+}
+
+// NewRoomState 创建房间状态，history 为保留的最近增量条数
+func NewRoomState(history int) *RoomState {
+	if history <= 0 {
+		history = 1024
+	}
+	return &RoomState{
+		state:   make(map[Key]interface{}),
+		history: make([]Delta, history),
+	}
+}
+
+// Apply 写入实体字段组的最新状态，返回分配的序号
+func (r *RoomState) Apply(key Key, state interface{}) uint64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.state[key] = state
+	return r.record(Delta{Key: key, State: state})
+}
+
+// Delete 删除实体字段组（实体离开房间等）
+func (r *RoomState) Delete(key Key) uint64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.state, key)
+	return r.record(Delta{Key: key, Deleted: true})
+}
+
+func (r *RoomState) record(d Delta) uint64 {
+	r.seq++
+	d.Seq = r.seq
+
+	if r.size < len(r.history) {
+		r.history[(r.start+r.size)%len(r.history)] = d
+		r.size++
+	} else {
+		r.history[r.start] = d
+		r.start = (r.start + 1) % len(r.history)
+	}
+	return r.seq
+}
+
+// Seq 当前最新序号
+func (r *RoomState) Seq() uint64 {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.seq
+}
+
+// Baseline 生成带兴趣过滤的完整快照
+func (r *RoomState) Baseline(filter InterestFilter) *Baseline {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.baseline(filter)
+}
+
+func (r *RoomState) baseline(filter InterestFilter) *Baseline {
+	b := &Baseline{Seq: r.seq, Entries: make(map[Key]interface{}, len(r.state))}
+	for key, state := range r.state {
+		if filter == nil || filter(key) {
+			b.Entries[key] = state
+		}
+	}
+	return b
+}
+
+// DeltasSince 返回序号 seq 之后的增量；缓冲区已覆盖所需增量时返回 ErrHistoryTruncated
+func (r *RoomState) DeltasSince(seq uint64, filter InterestFilter) ([]Delta, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.deltasSince(seq, filter)
+}
+
+func (r *RoomState) deltasSince(seq uint64, filter InterestFilter) ([]Delta, error) {
+	if seq >= r.seq {
+		return nil, nil
+	}
+	if r.size == 0 || r.history[r.start].Seq > seq+1 {
+		return nil, ErrHistoryTruncated
+	}
+
+	deltas := make([]Delta, 0, r.seq-seq)
+	for i := 0; i < r.size; i++ {
+		d := r.history[(r.start+i)%len(r.history)]
+		if d.Seq <= seq {
+			continue
+		}
+		if filter == nil || filter(d.Key) {
+			deltas = append(deltas, d)
+		}
+	}
+	return deltas, nil
+}
+
+// CatchUp 生成追帧计划：lastSeq 为客户端已确认的序号，0 表示首次加入
+// 增量足够时只补发增量，否则下发基线（之后的变化由实时广播继续推送）
+func (r *RoomState) CatchUp(lastSeq uint64, filter InterestFilter) CatchUp {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if lastSeq > 0 {
+		if deltas, err := r.deltasSince(lastSeq, filter); err == nil {
+			return CatchUp{Deltas: deltas}
+		}
+	}
+	return CatchUp{Baseline: r.baseline(filter)}
+}
+
+// Recorder 包装合并缓冲区的下发回调：先写入房间状态再继续下发
+func (r *RoomState) Recorder(next FlushFunc) FlushFunc {
+	return func(key Key, state interface{}) {
+		r.Apply(key, state)
+		if next != nil {
+			next(key, state)
+		}
+	}
+}
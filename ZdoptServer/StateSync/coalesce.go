@@ -0,0 +1,93 @@
+package StateSync
+
+import (
+	"expvar"
+	"sync"
+	"time"
+)
+
+var (
+	coalescedUpdates = expvar.NewInt("statesync.coalesced") // 被合并（丢弃）的重复更新数
+	flushedUpdates   = expvar.NewInt("statesync.flushed")   // 实际下发的更新数
+)
+
+// Key 合并键：同一实体的同一字段组在一个 tick 内只保留最后一次状态
+type Key struct {
+	Entity int64
+	Group  string
+}
+
+// FlushFunc 下发回调，tick 结束时对每个键调用一次
+type FlushFunc func(key Key, state interface{})
+
+// Coalescer 按 tick 合并重复更新的广播缓冲区（线程安全）
+type Coalescer struct {
+	mu         sync.Mutex
+	pending    map[Key]interface{}
+	order      []Key // 首次写入顺序，保证下发顺序稳定
+	flush      FlushFunc
+	suppressed uint64
+}
+
+// NewCoalescer 创建合并缓冲区
+func NewCoalescer(flush FlushFunc) *Coalescer {
+	return &Coalescer{
+		pending: make(map[Key]interface{}),
+		order:   make([]Key, 0, 64),
+		flush:   flush,
+	}
+}
+
+// Put 写入实体字段组的最新状态，覆盖本 tick 内之前的状态
+func (c *Coalescer) Put(entity int64, group string, state interface{}) {
+	key := Key{Entity: entity, Group: group}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.pending[key]; ok {
+		c.suppressed++
+		coalescedUpdates.Add(1)
+	} else {
+		c.order = append(c.order, key)
+	}
+	c.pending[key] = state
+}
+
+// Pending 当前 tick 待下发的键数量
+func (c *Coalescer) Pending() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.order)
+}
+
+// Suppressed 累计被合并的重复更新数
+func (c *Coalescer) Suppressed() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.suppressed
+}
+
+// Flush 在 tick 结束时下发所有合并后的状态，返回下发数量
+// 下发回调在锁外执行，回调中可以安全地再次 Put（计入下一个 tick）
+func (c *Coalescer) Flush() int {
+	c.mu.Lock()
+	order := c.order
+	pending := c.pending
+	c.order = make([]Key, 0, cap(order))
+	c.pending = make(map[Key]interface{}, len(pending))
+	c.mu.Unlock()
+
+	if c.flush != nil {
+		for _, key := range order {
+			c.flush(key, pending[key])
+		}
+	}
+	flushedUpdates.Add(int64(len(order)))
+	return len(order)
+}
+
+// Update 以 tick 驱动下发，便于挂在同步调度循环的末尾
+func (c *Coalescer) Update(time.Duration) {
+	c.Flush()
+}
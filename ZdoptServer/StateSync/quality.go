@@ -0,0 +1,100 @@
+package StateSync
+
+import (
+	"fmt"
+	"math"
+)
+
+// QualityTier 会话的同步质量档位，由网络层按估计带宽给出
+type QualityTier int
+
+const (
+	TierFull    QualityTier = iota // 每 tick 下发，不降精度
+	TierReduced                    // 降低频率与精度
+	TierMinimal                    // 受限链路：最低频率与精度
+)
+
+func (t QualityTier) String() string {
+	switch t {
+	case TierFull:
+		return "full"
+	case TierReduced:
+		return "reduced"
+	case TierMinimal:
+		return "minimal"
+	default:
+		return fmt.Sprintf("QualityTier(%d)", int(t))
+	}
+}
+
+// QualitySource 提供会话当前档位（如 Net.BandwidthEstimator）
+type QualitySource interface {
+	QualityTier() QualityTier
+}
+
+// QualityProfile 档位对应的下发参数
+type QualityProfile struct {
+	Every int     // 每 Every 个 tick 下发一次，<= 1 表示每 tick
+	Step  float64 // 数值量化步长，0 表示不量化
+}
+
+// DefaultQualityProfiles 默认档位参数：全量每 tick；降级每 2 tick、精度 0.01；最低每 4 tick、精度 0.1
+func DefaultQualityProfiles() [3]QualityProfile {
+	return [3]QualityProfile{
+		TierFull:    {Every: 1},
+		TierReduced: {Every: 2, Step: 0.01},
+		TierMinimal: {Every: 4, Step: 0.1},
+	}
+}
+
+// SessionQuality 单个会话的自适应下发：每 tick 按当前档位决定是否下发以及量化精度。
+// 跳过的 tick 中的更新由 Scheduler 合并，下次下发时只发最新状态
+type SessionQuality struct {
+	source   QualitySource
+	profiles [3]QualityProfile
+	tick     uint64
+	current  QualityProfile
+}
+
+// NewSessionQuality 创建会话下发控制，source 为 nil 时始终按全量档位
+func NewSessionQuality(source QualitySource, profiles [3]QualityProfile) *SessionQuality {
+	q := &SessionQuality{source: source, profiles: profiles}
+	q.current = q.profileFor(TierFull)
+	return q
+}
+
+// Tick 推进一个同步 tick，返回本 tick 是否向该会话下发
+func (q *SessionQuality) Tick() bool {
+	tier := TierFull
+	if q.source != nil {
+		tier = q.source.QualityTier()
+	}
+	q.current = q.profileFor(tier)
+	q.tick++
+	return q.current.Every <= 1 || q.tick%uint64(q.current.Every) == 0
+}
+
+// Profile 当前 tick 使用的下发参数
+func (q *SessionQuality) Profile() QualityProfile {
+	return q.current
+}
+
+// Quantize 按当前档位的精度量化数值
+func (q *SessionQuality) Quantize(v float64) float64 {
+	return Quantize(v, q.current.Step)
+}
+
+func (q *SessionQuality) profileFor(tier QualityTier) QualityProfile {
+	if tier < TierFull || int(tier) >= len(q.profiles) {
+		tier = TierMinimal
+	}
+	return q.profiles[tier]
+}
+
+// Quantize 把 v 取整到 step 的整数倍，step <= 0 时原样返回
+func Quantize(v, step float64) float64 {
+	if step <= 0 {
+		return v
+	}
+	return math.Round(v/step) * step
+}
@@ -0,0 +1,196 @@
+package StateSync
+
+import (
+	"errors"
+	"expvar"
+	"sync"
+	"time"
+)
+
+var sendErrors = expvar.NewInt("statesync.send_errors") // 下发回调返回的错误数
+
+// SendFunc 向单个会话下发一批更新（按序号升序，同一键只出现一次）
+type SendFunc func(deltas []Delta) error
+
+// Scheduler 房间的同步调度：tick 内的更新经 Coalescer 合并，tick 结束时写入 RoomState，
+// 再按各订阅会话的质量档位下发；会话跳过的 tick 中同一键只保留最新状态
+type Scheduler struct {
+	coalescer *Coalescer
+	room      *RoomState
+
+	mu   sync.Mutex
+	subs map[int64]*subscriber
+}
+
+type subscriber struct {
+	quality *SessionQuality
+	filter  InterestFilter
+	send    SendFunc
+	pending []Delta
+	index   map[Key]int // 键在 pending 中最新一条的位置，其余同键条目已被覆盖
+	stale   int         // pending 中被覆盖的条目数
+	catchUp bool        // 待发队列中有追帧内容，下一 tick 不论质量档位都下发
+}
+
+// live 该位置的条目是否仍需下发（基线标记总是下发）
+func (sub *subscriber) live(i int, d Delta) bool {
+	return d.Reset || sub.index[d.Key] == i
+}
+
+// push 追加一条更新，覆盖同键的旧条目
+func (sub *subscriber) push(d Delta) {
+	if d.Reset {
+		sub.pending = append(sub.pending, d)
+		return
+	}
+	if _, ok := sub.index[d.Key]; ok {
+		// 会话跳过的 tick 中的旧状态被覆盖：旧条目留在原位，下发时跳过，新条目追加到队尾保持序号升序
+		coalescedUpdates.Add(1)
+		sub.stale++
+	}
+	sub.index[d.Key] = len(sub.pending)
+	sub.pending = append(sub.pending, d)
+	if sub.stale > len(sub.index) {
+		sub.compact()
+	}
+}
+
+// drain 按序号升序取出待下发的更新，跳过被覆盖的条目，线性时间
+func (sub *subscriber) drain() []Delta {
+	out := sub.pending
+	if sub.stale > 0 {
+		out = make([]Delta, 0, len(sub.index))
+		for i, d := range sub.pending {
+			if sub.live(i, d) {
+				out = append(out, d)
+			}
+		}
+	}
+	sub.pending = nil
+	sub.stale = 0
+	sub.catchUp = false
+	clear(sub.index)
+	return out
+}
+
+// compact 原地移除被覆盖的条目，避免长期跳过 tick 的会话积压
+func (sub *subscriber) compact() {
+	live := sub.pending[:0]
+	for i, d := range sub.pending {
+		if sub.live(i, d) {
+			if !d.Reset {
+				sub.index[d.Key] = len(live)
+			}
+			live = append(live, d)
+		}
+	}
+	clear(sub.pending[len(live):])
+	sub.pending = live
+	sub.stale = 0
+}
+
+// tombstone 合并缓冲区中表示删除的状态
+type tombstone struct{}
+
+// NewScheduler 创建房间同步调度，合并后的更新写入 room
+func NewScheduler(room *RoomState) *Scheduler {
+	s := &Scheduler{room: room, subs: make(map[int64]*subscriber)}
+	s.coalescer = NewCoalescer(s.commit)
+	return s
+}
+
+// Put 写入实体字段组的最新状态，本 tick 结束时下发
+func (s *Scheduler) Put(entity int64, group string, state interface{}) {
+	s.coalescer.Put(entity, group, state)
+}
+
+// Delete 删除实体字段组（实体离开房间等），本 tick 结束时以 Deleted 增量下发；同一 tick 内之后的 Put 覆盖删除
+func (s *Scheduler) Delete(entity int64, group string) {
+	s.coalescer.Put(entity, group, tombstone{})
+}
+
+// Subscribe 订阅房间更新并完成追帧：lastSeq 为客户端已确认的序号，0 表示首次加入。
+// 房间增量缓冲覆盖 lastSeq 之后的变化时只补发这些增量，否则先下发基线：一条 Reset 标记（客户端清空本地状态）
+// 加上基线序号下的全部条目。追帧内容与订阅登记在同一把锁内完成，排在之后的实时更新之前，下一 tick 一并下发，
+// 追帧与实时更新之间不会遗漏或重复。quality 为 nil 时每 tick 下发，filter 为 nil 时不过滤；同一 id 重复订阅时替换
+func (s *Scheduler) Subscribe(id int64, lastSeq uint64, quality *SessionQuality, filter InterestFilter, send SendFunc) {
+	if quality == nil {
+		quality = NewSessionQuality(nil, DefaultQualityProfiles())
+	}
+	sub := &subscriber{quality: quality, filter: filter, send: send, index: make(map[Key]int)}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	plan := s.room.CatchUp(lastSeq, filter)
+	// 首次加入空房间时没有需要追的内容
+	if b := plan.Baseline; b != nil && (lastSeq > 0 || b.Seq > 0) {
+		sub.push(Delta{Seq: b.Seq, Reset: true})
+		for key, state := range b.Entries {
+			sub.push(Delta{Seq: b.Seq, Key: key, State: state})
+		}
+	}
+	for _, d := range plan.Deltas {
+		sub.push(d)
+	}
+	sub.catchUp = len(sub.pending) > 0
+	s.subs[id] = sub
+}
+
+// Unsubscribe 取消订阅，未下发的更新被丢弃
+func (s *Scheduler) Unsubscribe(id int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.subs, id)
+}
+
+// Tick 结束当前 tick：下发合并后的更新并推进各会话的质量档位，返回下发回调的错误
+func (s *Scheduler) Tick() error {
+	s.coalescer.Flush()
+
+	type batch struct {
+		send   SendFunc
+		deltas []Delta
+	}
+	var due []batch
+	s.mu.Lock()
+	for _, sub := range s.subs {
+		if !sub.quality.Tick() && !sub.catchUp || len(sub.pending) == 0 {
+			continue
+		}
+		due = append(due, batch{send: sub.send, deltas: sub.drain()})
+	}
+	s.mu.Unlock()
+
+	// 回调在锁外执行
+	var errs []error
+	for _, b := range due {
+		if err := b.send(b.deltas); err != nil {
+			sendErrors.Add(1)
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Update 以 tick 驱动下发，便于挂在同步调度循环的末尾
+func (s *Scheduler) Update(time.Duration) {
+	_ = s.Tick()
+}
+
+// commit 把合并后的状态写入房间并加入各订阅会话的待下发队列；两步在同一把锁内完成，
+// 与 Subscribe 的追帧互斥，每条增量要么已在追帧内容中，要么排在其后
+func (s *Scheduler) commit(key Key, state interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	d := Delta{Key: key}
+	if _, deleted := state.(tombstone); deleted {
+		d.Seq, d.Deleted = s.room.Delete(key), true
+	} else {
+		d.Seq, d.State = s.room.Apply(key, state), state
+	}
+	for _, sub := range s.subs {
+		if sub.filter != nil && !sub.filter(key) {
+			continue
+		}
+		sub.push(d)
+	}
+}
@@ -0,0 +1,208 @@
+package StateSync
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fixedTier QualityTier
+
+func (t fixedTier) QualityTier() QualityTier { return QualityTier(t) }
+
+func TestSchedulerSendsLastStatePerTick(t *testing.T) {
+	room := NewRoomState(64)
+	s := NewScheduler(room)
+	var full, reduced [][]Delta
+	s.Subscribe(1, 0, nil, nil, func(d []Delta) error {
+		full = append(full, d)
+		return nil
+	})
+	s.Subscribe(2, 0, NewSessionQuality(fixedTier(TierReduced), DefaultQualityProfiles()), nil, func(d []Delta) error {
+		reduced = append(reduced, d)
+		return nil
+	})
+
+	before := coalescedUpdates.Value()
+	for i := 1; i <= 10; i++ {
+		s.Put(7, "pos", i)
+	}
+	s.Put(8, "pos", 0)
+	if err := s.Tick(); err != nil {
+		t.Fatal(err)
+	}
+	if got := coalescedUpdates.Value() - before; got != 9 {
+		t.Fatalf("coalesced = %d, want 9", got)
+	}
+	// 同一 tick 内改动 10 次，订阅者只收到最后的状态
+	if len(full) != 1 || len(full[0]) != 2 || full[0][0].State != 10 || full[0][0].Key != (Key{Entity: 7, Group: "pos"}) {
+		t.Fatalf("full tier batches = %+v", full)
+	}
+	if len(reduced) != 0 {
+		t.Fatalf("reduced tier sent on its skipped tick: %+v", reduced)
+	}
+	if b := room.Baseline(nil); b.Entries[Key{Entity: 7, Group: "pos"}] != 10 {
+		t.Fatalf("room state = %+v", b.Entries)
+	}
+
+	// 降级档位跳过的 tick 中的状态被下一 tick 覆盖，下发时同一键只有一条且按序号升序
+	s.Put(7, "pos", 11)
+	if err := s.Tick(); err != nil {
+		t.Fatal(err)
+	}
+	if len(reduced) != 1 || len(reduced[0]) != 2 {
+		t.Fatalf("reduced tier batches = %+v", reduced)
+	}
+	if d := reduced[0]; d[0].Key.Entity != 8 || d[1].State != 11 || d[0].Seq >= d[1].Seq {
+		t.Fatalf("reduced tier deltas = %+v", d)
+	}
+	if len(full) != 2 || len(full[1]) != 1 || full[1][0].State != 11 {
+		t.Fatalf("full tier batches = %+v", full)
+	}
+}
+
+func TestSchedulerCoalescesAcrossSkippedTicks(t *testing.T) {
+	s := NewScheduler(NewRoomState(64))
+	var got [][]Delta
+	s.Subscribe(1, 0, NewSessionQuality(fixedTier(TierMinimal), DefaultQualityProfiles()), nil, func(d []Delta) error {
+		got = append(got, d)
+		return nil
+	})
+
+	// 最低档位每 4 个 tick 下发一次，期间每个键被覆盖 3 次
+	for tick := 1; tick <= 4; tick++ {
+		for e := int64(0); e < 3; e++ {
+			s.Put(e, "pos", tick)
+		}
+		if err := s.Tick(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(got) != 1 || len(got[0]) != 3 {
+		t.Fatalf("batches = %+v", got)
+	}
+	for i, d := range got[0] {
+		if d.State != 4 || (i > 0 && got[0][i-1].Seq >= d.Seq) {
+			t.Fatalf("deltas = %+v", got[0])
+		}
+	}
+}
+
+// replica 按下发顺序重建客户端本地状态
+type replica struct {
+	seq      uint64
+	snapshot bool // 正在接收基线，条目与 Reset 标记同序号
+	state    map[Key]interface{}
+}
+
+func (r *replica) apply(deltas []Delta) error {
+	for _, d := range deltas {
+		if d.Reset {
+			r.seq, r.snapshot, r.state = d.Seq, true, make(map[Key]interface{})
+			continue
+		}
+		if d.Seq < r.seq || d.Seq == r.seq && !r.snapshot {
+			return fmt.Errorf("delta %+v at or before %d", d, r.seq)
+		}
+		if d.Deleted {
+			delete(r.state, d.Key)
+		} else {
+			r.state[d.Key] = d.State
+		}
+		r.snapshot = r.snapshot && d.Seq == r.seq
+		r.seq = d.Seq
+	}
+	return nil
+}
+
+func TestSchedulerJoinDuringTraffic(t *testing.T) {
+	room := NewRoomState(8)
+	s := NewScheduler(room)
+
+	// 写入与 tick 持续进行，期间会话加入；增量缓冲很短，加入时走基线
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			e := int64(i % 16)
+			if i%5 == 4 {
+				s.Delete(e, "pos")
+			} else {
+				s.Put(e, "pos", i)
+			}
+			if i%3 == 0 {
+				_ = s.Tick()
+			}
+		}
+	}()
+
+	var mu sync.Mutex
+	clients := make([]*replica, 8)
+	for i := range clients {
+		c := &replica{state: make(map[Key]interface{})}
+		clients[i] = c
+		time.Sleep(time.Millisecond)
+		s.Subscribe(int64(i), 0, nil, nil, func(d []Delta) error {
+			mu.Lock()
+			defer mu.Unlock()
+			return c.apply(d)
+		})
+	}
+	close(stop)
+	<-done
+	if err := s.Tick(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := room.Baseline(nil)
+	for i, c := range clients {
+		if c.seq != want.Seq || !reflect.DeepEqual(c.state, want.Entries) {
+			t.Fatalf("client %d at seq %d with %v, room at %d with %v", i, c.seq, c.state, want.Seq, want.Entries)
+		}
+	}
+}
+
+func TestSchedulerResumeCatchUp(t *testing.T) {
+	room := NewRoomState(4)
+	s := NewScheduler(room)
+	for e := int64(0); e < 3; e++ {
+		s.Put(e, "pos", e)
+	}
+	s.Tick()
+
+	// 增量缓冲覆盖 lastSeq 之后的变化：只补发增量，包括删除
+	s.Delete(0, "pos")
+	s.Tick()
+	var got [][]Delta
+	s.Subscribe(1, 3, NewSessionQuality(fixedTier(TierMinimal), DefaultQualityProfiles()), nil, func(d []Delta) error {
+		got = append(got, d)
+		return nil
+	})
+	s.Tick()
+	if len(got) != 1 || len(got[0]) != 1 || !got[0][0].Deleted || got[0][0].Key.Entity != 0 || got[0][0].Seq != 4 {
+		t.Fatalf("resume batches = %+v", got)
+	}
+
+	// 增量缓冲已被覆盖：先下发 Reset 标记再下发基线
+	for i := 0; i < 4; i++ {
+		s.Put(1, "pos", i)
+		s.Tick()
+	}
+	got = nil
+	s.Subscribe(2, 3, nil, nil, func(d []Delta) error {
+		got = append(got, d)
+		return nil
+	})
+	s.Tick()
+	if len(got) != 1 || len(got[0]) != 3 || !got[0][0].Reset || got[0][0].Seq != room.Seq() {
+		t.Fatalf("truncated resume batches = %+v", got)
+	}
+}
@@ -0,0 +1,6 @@
+//go:build !zdopt_release
+
+package Strict
+
+// Compiled 严格模式检查是否编入当前构建（以 -tags zdopt_release 构建时整体剔除）
+const Compiled = true
@@ -0,0 +1,98 @@
+package Strict
+
+import (
+	"expvar"
+	"fmt"
+	"log"
+	"runtime/debug"
+	"sync/atomic"
+	"time"
+)
+
+var violations = expvar.NewMap("strict.violations")
+
+// Config 严格模式配置
+type Config struct {
+	Enabled         bool
+	Panic           bool          // 违例时 panic，默认仅记录日志与堆栈
+	TimerDriftLimit time.Duration // 关键帧触发允许的额外延迟（超出一帧步长的部分）
+}
+
+// DefaultConfig 默认严格模式配置（关闭）
+func DefaultConfig() Config {
+	return Config{TimerDriftLimit: 50 * time.Millisecond}
+}
+
+// Violation 一次不变量违例
+type Violation struct {
+	Check   string // 检查项，如 pool.double-release
+	Message string
+	Stack   []byte
+}
+
+func (v *Violation) Error() string {
+	return fmt.Sprintf("strict: %s: %s", v.Check, v.Message)
+}
+
+var (
+	enabled    atomic.Bool
+	current    atomic.Pointer[Config]
+	handler    atomic.Pointer[func(*Violation)]
+	defaultCfg = DefaultConfig()
+)
+
+// Configure 应用严格模式配置，返回是否实际生效（发布构建中始终为 false）
+func Configure(cfg Config) bool {
+	if cfg.TimerDriftLimit <= 0 {
+		cfg.TimerDriftLimit = defaultCfg.TimerDriftLimit
+	}
+	current.Store(&cfg)
+	enabled.Store(Compiled && cfg.Enabled)
+	return Enabled()
+}
+
+// Enabled 严格模式是否开启；发布构建中为常量 false，检查代码被编译器剔除
+func Enabled() bool {
+	return Compiled && enabled.Load()
+}
+
+// Settings 返回当前严格模式配置
+func Settings() Config {
+	if cfg := current.Load(); cfg != nil {
+		return *cfg
+	}
+	return defaultCfg
+}
+
+// SetHandler 设置违例处理函数（测试、上报等），nil 恢复默认处理
+func SetHandler(fn func(*Violation)) {
+	if fn == nil {
+		handler.Store(nil)
+		return
+	}
+	handler.Store(&fn)
+}
+
+// Failf 报告违例：附带当前堆栈，交由处理函数，默认记录日志（Panic 配置下 panic）
+func Failf(check, format string, args ...interface{}) {
+	v := &Violation{
+		Check:   check,
+		Message: fmt.Sprintf(format, args...),
+		Stack:   debug.Stack(),
+	}
+	violations.Add(check, 1)
+
+	if fn := handler.Load(); fn != nil {
+		(*fn)(v)
+		return
+	}
+	if Settings().Panic {
+		panic(v)
+	}
+	log.Printf("%v\n%s", v, v.Stack)
+}
+
+// Stack 记录当前堆栈，供后续违例报告引用（如首次释放位置）
+func Stack() []byte {
+	return debug.Stack()
+}
@@ -1,6 +1,7 @@
 package Timer
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log"
@@ -29,9 +30,42 @@ type KeyFrame struct {
 	Time      float32
 	Action    func()
 	IsTrigger bool
+	Label     string     // 可选标签，用于按名称触发/重置/移除及日志
+	Group     string     // 可选分组，如 "phase2"
+	Mode      ExecMode   // 动作执行方式，ExecDefault 跟随定时器
+	Payload   any        // 显式存放的回调参数，随对象池回收清空
 	mu        sync.Mutex // 为并发操作添加互斥锁
 }
 
+// KeyFrameOption 关键帧可选配置
+type KeyFrameOption func(*KeyFrame)
+
+// WithLabel 设置关键帧标签
+func WithLabel(label string) KeyFrameOption {
+	return func(kf *KeyFrame) {
+		kf.Label = label
+	}
+}
+
+// WithGroup 设置关键帧分组
+func WithGroup(group string) KeyFrameOption {
+	return func(kf *KeyFrame) {
+		kf.Group = group
+	}
+}
+
+// Name 日志中使用的关键帧名称（标签/分组/时间）
+func (kf *KeyFrame) Name() string {
+	name := fmt.Sprintf("%.2fs", kf.Time)
+	if kf.Label != "" {
+		name = kf.Label + "@" + name
+	}
+	if kf.Group != "" {
+		name = kf.Group + "/" + name
+	}
+	return name
+}
+
 // OnGet 对象从池中取出时调用
 func (kf *KeyFrame) OnGet() {
 	kf.mu.Lock()
@@ -39,6 +73,10 @@ func (kf *KeyFrame) OnGet() {
 
 	kf.IsTrigger = false
 	kf.Action = nil // 清空旧回调
+	kf.Label = ""
+	kf.Group = ""
+	kf.Mode = ExecDefault
+	kf.Payload = nil
 }
 
 // OnRelease 对象放回池时调用
@@ -49,6 +87,10 @@ func (kf *KeyFrame) OnRelease() {
 	kf.Time = 0
 	kf.Action = nil
 	kf.IsTrigger = false
+	kf.Label = ""
+	kf.Group = ""
+	kf.Mode = ExecDefault
+	kf.Payload = nil
 }
 
 // Validate 验证关键帧有效性
@@ -88,6 +130,29 @@ func InitKeyFramePool() error {
 	return poolInitError
 }
 
+// CloseKeyFramePool 关闭对象池管理器（服务停机时调用），之后 GetKeyFrame 返回 ObjectPool.ErrPoolClosed
+func CloseKeyFramePool(ctx context.Context) error {
+	if err := InitKeyFramePool(); err != nil {
+		return err
+	}
+	return ObjectPoolManager.Close(ctx)
+}
+
+// WarmupKeyFramePool 预热关键帧对象池
+func WarmupKeyFramePool(n int) error {
+	if err := InitKeyFramePool(); err != nil {
+		return fmt.Errorf("%w: %v", ErrKeyFramePoolNotRegistered, err)
+	}
+	pool, err := ObjectPool.GetPool(ObjectPoolManager, poolName)
+	if err != nil {
+		return fmt.Errorf("failed to get pool: %w", err)
+	}
+	if w, ok := pool.(interface{ Warmup(int) }); ok {
+		w.Warmup(n)
+	}
+	return nil
+}
+
 // GetKeyFrame 安全获取关键帧对象
 func GetKeyFrame(time float32, action func()) (*KeyFrame, error) {
 	// 参数校验
@@ -145,27 +210,17 @@ func ReleaseKeyFrame(kf *KeyFrame) error {
 		return errors.New("cannot release nil keyframe")
 	}
 
+	// 防止重复释放（检查后立即解锁，OnRelease 回调会再次加锁）
 	kf.mu.Lock()
-	defer kf.mu.Unlock()
-
-	// 防止重复释放
-	if kf.Time == 0 && kf.Action == nil {
+	released := kf.Time == 0 && kf.Action == nil
+	kf.mu.Unlock()
+	if released {
 		return ErrKeyFrameDoubleRelease
 	}
 
-	// 获取对象池
-	pool, err := ObjectPool.GetPool(ObjectPoolManager, poolName)
-	if err != nil {
-		return fmt.Errorf("failed to get pool: %w", err)
-	}
-
-	// 调用池的 ReleaseObj 方法
-	if err := pool.ReleaseObj(kf); err != nil {
+	// 归还对象池（OnRelease 清空字段，即标记为已释放）；池关闭后仍可归还
+	if err := ObjectPool.ReleaseToPool(ObjectPoolManager, poolName, kf); err != nil {
 		return fmt.Errorf("release failed: %w", err)
 	}
-
-	// 标记为已释放
-	kf.Time = 0
-	kf.Action = nil
 	return nil
 }
@@ -0,0 +1,72 @@
+package Timer
+
+import (
+	"sync/atomic"
+	"zdopt/ZdoptServer/Limit"
+)
+
+// ExecMode 关键帧动作的执行方式
+type ExecMode int32
+
+const (
+	ExecDefault      ExecMode = iota // 跟随定时器设置（关键帧级别的零值）
+	ExecInline                       // 在 Update 调用方协程中同步执行，保证顺序
+	ExecAsync                        // 新协程执行，不阻塞定时器推进；全局并发额度耗尽时同步执行
+	ExecActorMailbox                 // 投递到定时器所属 Actor 的消息循环中执行
+)
+
+func (m ExecMode) String() string {
+	switch m {
+	case ExecInline:
+		return "inline"
+	case ExecAsync:
+		return "async"
+	case ExecActorMailbox:
+		return "actor-mailbox"
+	default:
+		return "default"
+	}
+}
+
+// WithExecMode 为单个关键帧指定执行方式，覆盖定时器设置
+func WithExecMode(mode ExecMode) KeyFrameOption {
+	return func(kf *KeyFrame) {
+		kf.Mode = mode
+	}
+}
+
+// SetExecMode 设置定时器默认执行方式（ExecDefault 视为 ExecInline）
+func (zt *ZTimer) SetExecMode(mode ExecMode) {
+	atomic.StoreInt32((*int32)(&zt.execMode), int32(mode))
+}
+
+// ExecMode 返回定时器默认执行方式
+func (zt *ZTimer) ExecMode() ExecMode {
+	mode := ExecMode(atomic.LoadInt32((*int32)(&zt.execMode)))
+	if mode == ExecDefault {
+		return ExecInline
+	}
+	return mode
+}
+
+// fire 按关键帧/定时器的执行方式触发关键帧
+func (zt *ZTimer) fire(kf *KeyFrame) {
+	mode := kf.Mode
+	if mode == ExecDefault {
+		mode = zt.ExecMode()
+	}
+
+	switch mode {
+	case ExecAsync:
+		kf.TriggerWith(func(action func()) { Limit.Go("timer.keyframe", zt.wrap(kf, action)) })
+	case ExecActorMailbox:
+		// 未绑定 Actor 时退化为同步执行
+		if actor := zt.MyActorBase; actor != nil {
+			kf.TriggerWith(func(action func()) { actor.Execute(zt.wrap(kf, action)) })
+			return
+		}
+		kf.TriggerWith(func(action func()) { zt.wrap(kf, action)() })
+	default:
+		kf.TriggerWith(func(action func()) { zt.wrap(kf, action)() })
+	}
+}
@@ -0,0 +1,123 @@
+package Timer
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrKeyFrameNotFound = errors.New("no keyframe matched")
+
+// TriggerLabel 立即触发指定标签的关键帧，返回触发数量
+func (zt *ZTimer) TriggerLabel(label string) int {
+	return zt.triggerMatched(func(kf *KeyFrame) bool { return kf.Label == label })
+}
+
+// TriggerGroup 立即触发指定分组的所有关键帧，返回触发数量
+func (zt *ZTimer) TriggerGroup(group string) int {
+	return zt.triggerMatched(func(kf *KeyFrame) bool { return kf.Group == group })
+}
+
+// ResetLabel 重置指定标签的关键帧，使其可以再次触发
+func (zt *ZTimer) ResetLabel(label string) int {
+	return zt.resetMatched(func(kf *KeyFrame) bool { return kf.Label == label })
+}
+
+// ResetGroup 重置指定分组的所有关键帧，如 ResetGroup("phase2")
+func (zt *ZTimer) ResetGroup(group string) int {
+	return zt.resetMatched(func(kf *KeyFrame) bool { return kf.Group == group })
+}
+
+// RemoveLabel 移除指定标签的关键帧并归还对象池
+func (zt *ZTimer) RemoveLabel(label string) error {
+	return zt.removeMatched(fmt.Sprintf("label %q", label), func(kf *KeyFrame) bool { return kf.Label == label })
+}
+
+// RemoveGroup 移除指定分组的所有关键帧并归还对象池
+func (zt *ZTimer) RemoveGroup(group string) error {
+	return zt.removeMatched(fmt.Sprintf("group %q", group), func(kf *KeyFrame) bool { return kf.Group == group })
+}
+
+// Labels 返回所有已设置的关键帧标签（按添加顺序）
+func (zt *ZTimer) Labels() []string {
+	zt.mu.RLock()
+	defer zt.mu.RUnlock()
+
+	labels := make([]string, 0, len(zt._keyFrames))
+	for _, kf := range zt._keyFrames {
+		if kf.Label != "" {
+			labels = append(labels, kf.Label)
+		}
+	}
+	return labels
+}
+
+func (zt *ZTimer) matched(match func(*KeyFrame) bool) []*KeyFrame {
+	zt.mu.RLock()
+	defer zt.mu.RUnlock()
+
+	var frames []*KeyFrame
+	for _, kf := range zt._keyFrames {
+		if match(kf) {
+			frames = append(frames, kf)
+		}
+	}
+	return frames
+}
+
+func (zt *ZTimer) triggerMatched(match func(*KeyFrame) bool) int {
+	count := 0
+	for _, kf := range zt.matched(match) {
+		if kf.IsTriggered() {
+			continue
+		}
+		zt.fire(kf)
+		count++
+		zt.logger.Debug(fmt.Sprintf("KeyFrame %s triggered manually", kf.Name()))
+	}
+	return count
+}
+
+func (zt *ZTimer) resetMatched(match func(*KeyFrame) bool) int {
+	frames := zt.matched(match)
+	for _, kf := range frames {
+		kf.Reset()
+		zt.logger.Debug(fmt.Sprintf("KeyFrame %s reset", kf.Name()))
+	}
+	return len(frames)
+}
+
+func (zt *ZTimer) removeMatched(desc string, match func(*KeyFrame) bool) error {
+	zt.mu.Lock()
+	kept := zt._keyFrames[:0]
+	var removed []*KeyFrame
+	for _, kf := range zt._keyFrames {
+		if match(kf) {
+			removed = append(removed, kf)
+		} else {
+			kept = append(kept, kf)
+		}
+	}
+	zt._keyFrames = kept
+
+	// 重新计算最大关键帧时间
+	zt.maxTimer = 0
+	for _, kf := range zt._keyFrames {
+		if kf.Time > zt.maxTimer {
+			zt.maxTimer = kf.Time
+		}
+	}
+	zt.mu.Unlock()
+
+	if len(removed) == 0 {
+		return fmt.Errorf("%w: %s", ErrKeyFrameNotFound, desc)
+	}
+
+	var errs []error
+	for _, kf := range removed {
+		zt.logger.Debug(fmt.Sprintf("KeyFrame %s removed", kf.Name()))
+		if err := ReleaseKeyFrame(kf); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,112 @@
+package Timer
+
+import (
+	"expvar"
+	"sync"
+	"time"
+	"zdopt/ZdoptServer/Logs"
+)
+
+var (
+	keyFrameRuns     = expvar.NewMap("timer.keyframe.runs")
+	keyFrameDuration = expvar.NewMap("timer.keyframe.duration_ns")
+	keyFramePaused   = expvar.NewInt("timer.keyframe.paused")
+)
+
+// KeyFrameHandler 关键帧动作的执行函数
+type KeyFrameHandler func(kf *KeyFrame, action func())
+
+// KeyFrameMiddleware 关键帧执行拦截器，包裹下一层处理函数
+type KeyFrameMiddleware func(next KeyFrameHandler) KeyFrameHandler
+
+var (
+	globalMiddlewareMu sync.RWMutex
+	globalMiddlewares  []KeyFrameMiddleware
+)
+
+// UseGlobal 注册全局拦截器，作用于所有定时器（位于定时器自身拦截器外层）
+func UseGlobal(mw ...KeyFrameMiddleware) {
+	globalMiddlewareMu.Lock()
+	defer globalMiddlewareMu.Unlock()
+	globalMiddlewares = append(globalMiddlewares, mw...)
+}
+
+// Use 为当前定时器注册拦截器
+func (zt *ZTimer) Use(mw ...KeyFrameMiddleware) {
+	zt.mwMu.Lock()
+	defer zt.mwMu.Unlock()
+	zt.middlewares = append(zt.middlewares, mw...)
+}
+
+// wrap 按 全局 -> 定时器 的顺序组装拦截器链，返回最终执行的动作
+func (zt *ZTimer) wrap(kf *KeyFrame, action func()) func() {
+	globalMiddlewareMu.RLock()
+	chain := make([]KeyFrameMiddleware, 0, len(globalMiddlewares))
+	chain = append(chain, globalMiddlewares...)
+	globalMiddlewareMu.RUnlock()
+	zt.mwMu.RLock()
+	chain = append(chain, zt.middlewares...)
+	zt.mwMu.RUnlock()
+
+	if len(chain) == 0 {
+		return action
+	}
+
+	handler := KeyFrameHandler(func(_ *KeyFrame, action func()) { action() })
+	for i := len(chain) - 1; i >= 0; i-- {
+		handler = chain[i](handler)
+	}
+	return func() { handler(kf, action) }
+}
+
+// BeforeAfter 构造前后钩子拦截器，after 收到动作耗时
+func BeforeAfter(before func(kf *KeyFrame), after func(kf *KeyFrame, elapsed time.Duration)) KeyFrameMiddleware {
+	return func(next KeyFrameHandler) KeyFrameHandler {
+		return func(kf *KeyFrame, action func()) {
+			if before != nil {
+				before(kf)
+			}
+			start := time.Now()
+			next(kf, action)
+			if after != nil {
+				after(kf, time.Since(start))
+			}
+		}
+	}
+}
+
+// TimingMiddleware 按关键帧标签（无标签记为 unlabeled）统计执行次数与累计耗时
+func TimingMiddleware() KeyFrameMiddleware {
+	return BeforeAfter(nil, func(kf *KeyFrame, elapsed time.Duration) {
+		label := kf.Label
+		if label == "" {
+			label = "unlabeled"
+		}
+		keyFrameRuns.Add(label, 1)
+		keyFrameDuration.Add(label, elapsed.Nanoseconds())
+	})
+}
+
+// LoggingMiddleware 以关键帧名称记录执行日志
+func LoggingMiddleware(logger *Logs.ZLogger) KeyFrameMiddleware {
+	return BeforeAfter(nil, func(kf *KeyFrame, elapsed time.Duration) {
+		if logger.Enabled(Logs.Debug) {
+			logger.Debugw("KeyFrame executed", Logs.Str("keyframe", kf.Name()), Logs.Dur("took", elapsed))
+		}
+	})
+}
+
+// PauseWhen 条件成立时（如房间处于降级负载模式）暂停动作：
+// 关键帧被重置为未触发，待条件解除后的下一次 Update 再执行
+func PauseWhen(paused func() bool) KeyFrameMiddleware {
+	return func(next KeyFrameHandler) KeyFrameHandler {
+		return func(kf *KeyFrame, action func()) {
+			if paused() {
+				keyFramePaused.Add(1)
+				kf.Reset()
+				return
+			}
+			next(kf, action)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package Timer
+
+import "fmt"
+
+// KeyFrameInfo 关键帧快照，用于定时器内省与序列化
+type KeyFrameInfo struct {
+	Time      float32
+	Label     string
+	Group     string
+	Mode      ExecMode
+	Payload   any
+	Triggered bool
+}
+
+// AddKeyFrameWithPayload 添加携带类型化参数的关键帧
+// 动作收到添加时的参数；KeyFrame.Payload 仅供内省，对象池回收或复用后不影响已投递的动作
+func AddKeyFrameWithPayload[T any](zt *ZTimer, time float32, payload T, fn func(T), opts ...KeyFrameOption) error {
+	if fn == nil {
+		return fmt.Errorf("%w: keyframe action cannot be nil", ErrInvalidTimerParameters)
+	}
+
+	action := func() {
+		fn(payload)
+	}
+	opts = append(opts, func(kf *KeyFrame) {
+		kf.Payload = payload
+	})
+	return zt.AddKeyFrame(time, action, opts...)
+}
+
+// KeyFrames 返回所有关键帧的快照（按添加顺序）
+func (zt *ZTimer) KeyFrames() []KeyFrameInfo {
+	zt.mu.RLock()
+	defer zt.mu.RUnlock()
+
+	infos := make([]KeyFrameInfo, 0, len(zt._keyFrames))
+	for _, kf := range zt._keyFrames {
+		kf.mu.Lock()
+		infos = append(infos, KeyFrameInfo{
+			Time:      kf.Time,
+			Label:     kf.Label,
+			Group:     kf.Group,
+			Mode:      kf.Mode,
+			Payload:   kf.Payload,
+			Triggered: kf.IsTrigger,
+		})
+		kf.mu.Unlock()
+	}
+	return infos
+}
@@ -0,0 +1,39 @@
+package Timer
+
+import "testing"
+
+func TestPayloadSurvivesKeyFrameReuse(t *testing.T) {
+	zt, err := NewZTimer(0.1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 拦截器截留动作，模拟投递到 Actor 邮箱后延迟执行
+	var deferred []func()
+	zt.Use(func(next KeyFrameHandler) KeyFrameHandler {
+		return func(kf *KeyFrame, action func()) {
+			deferred = append(deferred, func() { next(kf, action) })
+		}
+	})
+
+	var got []string
+	record := func(s string) { got = append(got, s) }
+	if err := AddKeyFrameWithPayload(zt, 1, "first", record, WithLabel("a")); err != nil {
+		t.Fatal(err)
+	}
+	if n := zt.TriggerLabel("a"); n != 1 {
+		t.Fatalf("triggered %d keyframes", n)
+	}
+	// 关键帧回收后被新关键帧复用，已投递的动作仍应收到原参数
+	if err := zt.RemoveLabel("a"); err != nil {
+		t.Fatal(err)
+	}
+	if err := AddKeyFrameWithPayload(zt, 1, "second", record, WithLabel("b")); err != nil {
+		t.Fatal(err)
+	}
+	for _, run := range deferred {
+		run()
+	}
+	if len(got) != 1 || got[0] != "first" {
+		t.Fatalf("payloads = %q, want [first]", got)
+	}
+}
@@ -38,6 +38,20 @@ func (kf *KeyFrame) Trigger() {
 	}
 }
 
+// TriggerWith 标记关键帧已触发，并由 run 决定动作的执行方式（同步/异步/投递）
+func (kf *KeyFrame) TriggerWith(run func(action func())) {
+	kf.mu.Lock()
+	if kf.IsTrigger || kf.Action == nil {
+		kf.mu.Unlock()
+		return
+	}
+	kf.IsTrigger = true
+	action := kf.Action
+	kf.mu.Unlock()
+
+	run(action)
+}
+
 // Reset 重置关键帧状态
 func (kf *KeyFrame) Reset() {
 	kf.mu.Lock()
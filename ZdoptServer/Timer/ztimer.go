@@ -3,9 +3,14 @@ package Timer
 import (
 	"errors"
 	"fmt"
+	"math"
 	"sync"
+	"sync/atomic"
+	"time"
 	"zdopt/ZdoptServer/Actor"
+	"zdopt/ZdoptServer/Clock"
 	"zdopt/ZdoptServer/Logs"
+	"zdopt/ZdoptServer/Strict"
 )
 
 // 定义错误类型
@@ -30,6 +35,11 @@ type ZTimer struct {
 	OffsetTime   float32
 	mu           sync.RWMutex // 读写锁保护并发访问
 	stopChan     chan struct{}
+	execMode     ExecMode // 关键帧默认执行方式
+	middlewares  []KeyFrameMiddleware
+	mwMu         sync.RWMutex // 拦截器单独加锁，Update 持有 mu 时也可组装拦截器链
+	paused       atomic.Bool  // 暂停期间 Update 不推进
+	lastStep     atomic.Int64 // 上一次正常的步长（time.Duration），时钟跳变按 Skip 策略时以此推进
 }
 
 // NewZTimer 创建定时器实例（带参数验证）
@@ -52,8 +62,8 @@ func NewZTimer(offsetTime float32) (*ZTimer, error) {
 	}, nil
 }
 
-// AddKeyFrame 增强版关键帧添加（带参数验证和状态检查）
-func (zt *ZTimer) AddKeyFrame(time float32, action func()) error {
+// AddKeyFrame 增强版关键帧添加（带参数验证和状态检查），可选标签与分组
+func (zt *ZTimer) AddKeyFrame(time float32, action func(), opts ...KeyFrameOption) error {
 	zt.mu.Lock()
 	defer zt.mu.Unlock()
 
@@ -75,9 +85,12 @@ func (zt *ZTimer) AddKeyFrame(time float32, action func()) error {
 	}
 
 	kf.Set(time, action)
+	for _, opt := range opts {
+		opt(kf)
+	}
 	zt._keyFrames = append(zt._keyFrames, kf)
 
-	zt.logger.Debug(fmt.Sprintf("KeyFrame added at %.2fs", time))
+	zt.logger.Debug(fmt.Sprintf("KeyFrame %s added", kf.Name()))
 	return nil
 }
 
@@ -127,9 +140,23 @@ func (zt *ZTimer) Update(deltaTime float32) {
 	zt.mu.RLock()
 	defer zt.mu.RUnlock()
 
-	if !zt.isRun || deltaTime <= 0 {
+	if !zt.isRun || zt.paused.Load() || deltaTime == 0 {
 		return
 	}
+	// 负数、非数或超过上限的步长视为时钟跳变，按 timer 子系统的策略截断或暂停
+	delta := secondsToDuration(deltaTime)
+	if step, jump, ok := Clock.Clamp("timer", delta, time.Duration(zt.lastStep.Load())); !ok {
+		if jump.Policy == Clock.Pause {
+			zt.paused.Store(true)
+			zt.logger.Warn(fmt.Sprintf("Timer %d paused after clock jump", zt.TimerId))
+		}
+		return
+	} else {
+		if step == delta {
+			zt.lastStep.Store(int64(step))
+		}
+		deltaTime = float32(step.Seconds())
+	}
 
 	select {
 	case <-zt.stopChan:
@@ -155,8 +182,13 @@ func (zt *ZTimer) Update(deltaTime float32) {
 	// 触发关键帧
 	for _, kf := range zt._keyFrames {
 		if !kf.IsTriggered() && zt.currentTimer >= kf.Time-zt.OffsetTime {
-			kf.Trigger()
-			zt.logger.Debug(fmt.Sprintf("KeyFrame triggered at %.2fs", kf.Time))
+			if Strict.Enabled() {
+				checkDrift(kf, zt.currentTimer-zt.OffsetTime, deltaTime)
+			}
+			zt.fire(kf)
+			if zt.logger.Enabled(Logs.Debug) {
+				zt.logger.Debugw("KeyFrame triggered", Logs.Int("timer", zt.TimerId), Logs.Str("keyframe", kf.Name()))
+			}
 		}
 	}
 }
@@ -248,6 +280,21 @@ func (zt *ZTimer) releaseResources() error {
 // 状态查询方法
 // --------------------------
 
+// Pause 暂停推进，关键帧进度保留
+func (zt *ZTimer) Pause() {
+	zt.paused.Store(true)
+}
+
+// Resume 恢复推进（含时钟跳变后被 Pause 策略暂停的定时器）
+func (zt *ZTimer) Resume() {
+	zt.paused.Store(false)
+}
+
+// Paused 是否已暂停
+func (zt *ZTimer) Paused() bool {
+	return zt.paused.Load()
+}
+
 func (zt *ZTimer) IsRunning() bool {
 	zt.mu.RLock()
 	defer zt.mu.RUnlock()
@@ -262,3 +309,29 @@ func (zt *ZTimer) CurrentProgress() float32 {
 	}
 	return zt.currentTimer / zt.maxTimer
 }
+
+// secondsToDuration 秒数转为时长，非数与无穷大按溢出处理
+func secondsToDuration(s float32) time.Duration {
+	f := float64(s)
+	switch {
+	case math.IsNaN(f):
+		return 0
+	case f*float64(time.Second) >= math.MaxInt64:
+		return math.MaxInt64
+	case f*float64(time.Second) <= math.MinInt64:
+		return math.MinInt64
+	}
+	return time.Duration(f * float64(time.Second))
+}
+
+// checkDrift 严格模式：本步跨过触发点的关键帧，实际触发时刻晚于触发点的量不得超过容差
+// （此前已到点、因重置/暂停而推迟的关键帧不计入）
+func checkDrift(kf *KeyFrame, now, deltaTime float32) {
+	late := now - kf.Time
+	if late > deltaTime {
+		return
+	}
+	if limit := float32(Strict.Settings().TimerDriftLimit.Seconds()); late > limit {
+		Strict.Failf("timer.drift", "keyframe %s fired %.3fs late (step %.3fs, limit %.3fs)", kf.Name(), late, deltaTime, limit)
+	}
+}
@@ -0,0 +1,155 @@
+// bench-client 参考压测客户端：并发以 Net.Client 连接并完成握手，发送 DataPacket 并统计回显延迟
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"zdopt/ZdoptServer/Logs"
+	"zdopt/ZdoptServer/Net"
+	"zdopt/ZdoptServer/Pb"
+)
+
+var logger = Logs.CreateConsoleLogConfig("BenchClient")
+
+// 协议消息 ID，须与 echo-server 一致
+const (
+	clientHelloID uint32 = 1
+	serverHelloID uint32 = 2
+	dataPacketID  uint32 = 3
+	maintenanceID uint32 = 7
+	schemaID      uint32 = 8
+	errorID       uint32 = 9
+)
+
+// newCodec 握手与回显消息的编解码器
+func newCodec() (*Net.PbCodec, error) {
+	codec := Net.NewPbCodec()
+	return codec, errors.Join(
+		Net.RegisterMessage[*Pb.ClientHello](codec, clientHelloID),
+		Net.RegisterMessage[*Pb.ServerHello](codec, serverHelloID),
+		Net.RegisterMessage[*Pb.DataPacket](codec, dataPacketID),
+		Net.RegisterMessage[*Pb.MaintenanceNotice](codec, maintenanceID),
+		Net.RegisterMessage[*Pb.SchemaMismatch](codec, schemaID),
+		Net.RegisterMessage[*Pb.ErrorResponse](codec, errorID),
+	)
+}
+
+type result struct {
+	latencies []time.Duration
+	errors    int
+}
+
+func main() {
+	addr := flag.String("addr", "127.0.0.1:7777", "server address")
+	conns := flag.Int("conns", 10, "concurrent connections")
+	requests := flag.Int("n", 1000, "requests per connection")
+	payload := flag.Int("payload", 64, "payload size in bytes")
+	timeout := flag.Duration("timeout", 3*time.Second, "per request timeout")
+	cipher := flag.String("cipher", Net.CipherNone, "kcp cipher, must match the server transport config")
+	key := flag.String("key", "", "kcp pre-shared key")
+	flag.Parse()
+
+	cfg := Net.DefaultClientConfig()
+	cfg.Transport.Cipher, cfg.Transport.Key = *cipher, *key
+	if _, err := cfg.Transport.BlockCrypt(); err != nil {
+		logger.Fatalf("transport: %v", err)
+	}
+	codec, err := newCodec()
+	if err != nil {
+		logger.Fatalf("codec: %v", err)
+	}
+	cfg.Codec = codec
+
+	content := strings.Repeat("x", *payload)
+	results := make([]result, *conns)
+
+	var wg sync.WaitGroup
+	start := time.Now()
+	for i := 0; i < *conns; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = run(cfg, *addr, *requests, content, *timeout)
+		}(i)
+	}
+	wg.Wait()
+	elapsed := time.Since(start)
+
+	var all []time.Duration
+	errors := 0
+	for _, r := range results {
+		all = append(all, r.latencies...)
+		errors += r.errors
+	}
+	if len(all) == 0 {
+		logger.Printf("no successful requests (%d errors)", errors)
+		os.Exit(1)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i] < all[j] })
+
+	fmt.Printf("requests: %d ok, %d errors in %v\n", len(all), errors, elapsed.Round(time.Millisecond))
+	fmt.Printf("throughput: %.0f req/s\n", float64(len(all))/elapsed.Seconds())
+	fmt.Printf("latency: p50=%v p90=%v p99=%v max=%v\n",
+		percentile(all, 0.50), percentile(all, 0.90), percentile(all, 0.99), all[len(all)-1])
+}
+
+// run 单连接串行请求-回显，超时未回显的请求计为错误；连接被服务端关闭后剩余请求均计为错误
+func run(cfg Net.ClientConfig, addr string, n int, content string, timeout time.Duration) result {
+	var r result
+	cfg.Hello = &Pb.ClientHello{}
+	c, err := Net.Dial(addr, cfg)
+	if err != nil {
+		logger.Printf("dial %s failed: %v", addr, err)
+		r.errors = n
+		return r
+	}
+	defer c.Close()
+
+	replies := make(chan *Pb.DataPacket, 1)
+	Net.Handle(c, func(p *Pb.DataPacket) {
+		select {
+		case replies <- p:
+		default: // 已超时请求的迟到回显
+		}
+	})
+	Net.Handle(c, func(h *Pb.Reconnect) {
+		logger.Printf("server closing (%s), retry after %dms", h.GetReason(), h.GetRetryAfterMs())
+	})
+	Net.Handle(c, func(n *Pb.MaintenanceNotice) {
+		logger.Printf("maintenance #%d in %dms: %s", n.GetWindowID(), n.GetRemainingMs(), n.GetReason())
+	})
+
+	req := &Pb.DataPacket{Content: content}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for i := 0; i < n; i++ {
+		sent := time.Now()
+		if err := c.Send(req); err != nil {
+			r.errors += n - i
+			return r
+		}
+		timer.Reset(timeout)
+		select {
+		case <-replies:
+			r.latencies = append(r.latencies, time.Since(sent))
+		case <-timer.C:
+			r.errors++
+		case <-c.Done():
+			r.errors += n - i
+			return r
+		}
+	}
+	return r
+}
+
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	idx := int(float64(len(sorted)-1) * p)
+	return sorted[idx]
+}
@@ -0,0 +1,383 @@
+// echo-server 最小可运行服务端示例：KCPListener（握手、分帧、编解码）-> MessageRouter -> Actor 处理 -> 经会话原样回包
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"zdopt/ZdoptServer/Actor"
+	"zdopt/ZdoptServer/Admin"
+	"zdopt/ZdoptServer/Clock"
+	"zdopt/ZdoptServer/Config"
+	"zdopt/ZdoptServer/DataPush" // 可选模块：配置 modules.datapush 启用
+	_ "zdopt/ZdoptServer/Export" // 可选模块：配置 modules.export 启用
+	"zdopt/ZdoptServer/I18n"
+	"zdopt/ZdoptServer/License"
+	"zdopt/ZdoptServer/Lifecycle"
+	"zdopt/ZdoptServer/Limit"
+	"zdopt/ZdoptServer/Logs"
+	"zdopt/ZdoptServer/Maintenance"
+	_ "zdopt/ZdoptServer/MatchReport" // 可选模块：配置 modules.matchreport 启用
+	"zdopt/ZdoptServer/Metrics"
+	"zdopt/ZdoptServer/Net"
+	"zdopt/ZdoptServer/Pb"
+	"zdopt/ZdoptServer/Script"
+	"zdopt/ZdoptServer/Strict"
+	"zdopt/ZdoptServer/Timer"
+)
+
+var logger = Logs.CreateConsoleLogConfig("EchoServer")
+
+// 协议消息 ID，须与 bench-client 一致
+const (
+	clientHelloID   uint32 = 1
+	serverHelloID   uint32 = 2
+	dataPacketID    uint32 = 3
+	dataPushHelloID uint32 = 4
+	dataPushChunkID uint32 = 5
+	dataPushAckID   uint32 = 6
+	maintenanceID   uint32 = 7
+	schemaID        uint32 = 8
+	errorID         uint32 = 9
+)
+
+// newCodec 握手与回显消息的编解码器
+func newCodec() (*Net.PbCodec, error) {
+	codec := Net.NewPbCodec()
+	return codec, errors.Join(
+		Net.RegisterMessage[*Pb.ClientHello](codec, clientHelloID),
+		Net.RegisterMessage[*Pb.ServerHello](codec, serverHelloID),
+		Net.RegisterMessage[*Pb.DataPacket](codec, dataPacketID),
+		Net.RegisterMessage[*Pb.DataPushHello](codec, dataPushHelloID),
+		Net.RegisterMessage[*Pb.DataPushChunk](codec, dataPushChunkID),
+		Net.RegisterMessage[*Pb.DataPushAck](codec, dataPushAckID),
+		Net.RegisterMessage[*Pb.MaintenanceNotice](codec, maintenanceID),
+		Net.RegisterMessage[*Pb.SchemaMismatch](codec, schemaID),
+		Net.RegisterMessage[*Pb.ErrorResponse](codec, errorID),
+	)
+}
+
+// echoActor 在 Actor 消息循环中完成回显，MessageRouter 按名称 "echo" 投递 DataPacket；
+// 处理器并发执行，计数使用原子操作
+type echoActor struct {
+	*Actor.BaseActor
+	guard     *Lifecycle.Guard // 为 nil 时不经模块守卫（拓扑声明的 worker）
+	admission *admission       // 为 nil 时不处理会话关闭
+	handled   atomic.Int64
+}
+
+func newEchoActor(base *Actor.BaseActor) *echoActor {
+	e := &echoActor{BaseActor: base}
+	Actor.RegisterHandler(base, e.onMessage)
+	Actor.RegisterHandler(base, e.onSessionClosed)
+	return e
+}
+
+func init() {
+	// 配置 topology 段可声明额外的回显 worker，如 {"name":"echo","factory":"echo","count":4}，
+	// 并以 routing 段把 DataPacket 路由到其中一个
+	Actor.RegisterFactory("echo", func(ctx *Actor.FactoryContext) (Actor.Actor, error) {
+		e := newEchoActor(ctx.NewBaseActor())
+		return e, ctx.System.RegisterName(ctx.Name, e)
+	})
+}
+
+func (e *echoActor) Start()                     {}
+func (e *echoActor) Stop()                      {}
+func (e *echoActor) Update(delta time.Duration) {}
+func (e *echoActor) Receive(msg interface{})    {}
+
+// onMessage 回显一条网络消息并归还消息对象；模块停用期间丢弃，panic 计入模块预算
+func (e *echoActor) onMessage(msg *Actor.Message) {
+	defer msg.Release()
+	packet, ok := msg.Value.(*Pb.DataPacket)
+	if !ok || msg.From == nil {
+		return
+	}
+	echo := func() {
+		start := time.Now()
+		if err := msg.From.Send(packet); err != nil {
+			logger.Printf("reply to %s failed: %v", msg.From.Remote(), err)
+			return
+		}
+		e.handled.Add(1)
+		Metrics.DefaultLatency.Since("kcp", Pb.TypeName(packet), start)
+	}
+	if e.guard == nil {
+		echo()
+		return
+	}
+	_ = e.guard.Run(echo)
+}
+
+func (e *echoActor) onSessionClosed(ev Actor.SessionClosed) {
+	if e.admission != nil {
+		e.admission.release(ev.SessionID)
+	}
+}
+
+// admission 握手成功时占用授权的在线名额，会话关闭（SessionClosed）时释放
+type admission struct {
+	license  *License.Enforcer
+	mu       sync.Mutex
+	releases map[uint64]func()
+}
+
+func newAdmission(license *License.Enforcer) *admission {
+	return &admission{license: license, releases: make(map[uint64]func())}
+}
+
+// handshake 在 next 之前检查授权名额，超出上限时拒绝握手
+func (a *admission) handshake(next Actor.Handshake) Actor.Handshake {
+	return Actor.HandshakeFunc(func(s *Actor.Session, msg *Actor.Message) (Actor.Identity, bool, error) {
+		release, err := a.license.AdmitSession(s.Remote())
+		if err != nil {
+			return Actor.Identity{}, false, I18n.WithCode(I18n.CodeServerBusy, err)
+		}
+		id, done, err := next.Authenticate(s, msg)
+		if err != nil || !done {
+			release()
+			return id, done, err
+		}
+		a.mu.Lock()
+		a.releases[s.ID()] = release
+		a.mu.Unlock()
+		return id, true, nil
+	})
+}
+
+func (a *admission) release(sessionID uint64) {
+	a.mu.Lock()
+	release, ok := a.releases[sessionID]
+	delete(a.releases, sessionID)
+	a.mu.Unlock()
+	if ok {
+		release()
+	}
+}
+
+func main() {
+	configPath := flag.String("config", "", "JSON config file (defaults to the SmallGame preset)")
+	port := flag.Int("port", 0, "KCP listen port, overrides config")
+	selfTest := flag.Bool("selftest", false, "run startup self test and exit")
+	strict := flag.Bool("strict", false, "enable strict mode invariant checks, overrides config")
+	admin := flag.String("admin", "", "admin endpoint address (e.g. 127.0.0.1:6060), overrides config; empty disables")
+	flag.Parse()
+
+	cfg := Config.Default()
+	if *configPath != "" {
+		loaded, err := Config.Load(*configPath)
+		if err != nil {
+			logger.Fatalf("load config: %v", err)
+		}
+		cfg = loaded
+	}
+	if *port > 0 {
+		cfg.Port = *port
+	}
+	if *strict {
+		cfg.Strict.Enabled = true
+	}
+	if Strict.Configure(cfg.Strict.StrictConfig()) {
+		logger.Printf("strict mode enabled")
+	}
+	Limit.Configure(cfg.Limits.LimitConfig())
+	Clock.Configure(cfg.Clock.ClockConfig())
+	limits, err := cfg.License.Limits()
+	if err != nil {
+		logger.Fatalf("load license: %v", err)
+	}
+	license := License.NewEnforcer(limits, func(ev License.BlockedEvent) {
+		// 结构化事件，供托管方日志采集
+		if data, err := json.Marshal(ev); err == nil {
+			logger.Printf("license.blocked %s", data)
+		}
+	})
+
+	system := Actor.NewSystemWithConfig(cfg.Actor.SystemConfig())
+	modules := Lifecycle.NewManager(func(a Lifecycle.Alert) {
+		logger.Printf("module %s %s: %v", a.Module, a.Kind, a.Value)
+	})
+	if *admin != "" {
+		cfg.Admin.Addr = *admin
+	}
+	plugins := Lifecycle.NewServer(modules, Lifecycle.Deps{System: system, Config: cfg})
+	// 自检在监听端口之前执行，端口检查才有意义
+	report := plugins.SelfTest()
+	if *selfTest || report.Err() != nil {
+		fmt.Print(report.String())
+		if err := report.Err(); err != nil {
+			os.Exit(1)
+		}
+		return
+	}
+
+	if err := Timer.WarmupKeyFramePool(cfg.Actor.PoolWarmup); err != nil {
+		logger.Printf("keyframe pool warmup failed: %v", err)
+	}
+	echo := newEchoActor(system.NewBaseActor(1024))
+	echo.admission = newAdmission(license)
+	system.AddGroupActors(1, []func() Actor.Actor{
+		func() Actor.Actor { return echo },
+	})
+	if err := system.RegisterName("echo", echo); err != nil {
+		logger.Fatalf("register echo actor: %v", err)
+	}
+	if err := system.EventBus().Subscribe(echo.BaseActor, Actor.SessionClosedTopic); err != nil {
+		logger.Fatalf("subscribe session events: %v", err)
+	}
+	if _, err := system.Build(cfg.Topology.Topology()); err != nil {
+		logger.Fatalf("build actor topology: %v", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	codec, err := newCodec()
+	if err != nil {
+		logger.Fatalf("codec: %v", err)
+	}
+	router, err := cfg.Routing.MessageRouter(system)
+	if err != nil {
+		logger.Fatalf("message routes: %v", err)
+	}
+	// 配置未声明时 DataPacket 交给主回显 Actor
+	if err := Actor.RouteMessage[*Pb.DataPacket](router, "echo"); err != nil && !errors.Is(err, Actor.ErrRouteExists) {
+		logger.Fatalf("message routes: %v", err)
+	}
+	// 数据表推送握手与确认交给 datapush 模块的服务Actor（模块未启用时投递失败进入死信）
+	for _, err := range []error{
+		Actor.RouteMessage[*Pb.DataPushHello](router, DataPush.ActorName),
+		Actor.RouteMessage[*Pb.DataPushAck](router, DataPush.ActorName),
+	} {
+		if err != nil && !errors.Is(err, Actor.ErrRouteExists) {
+			logger.Fatalf("message routes: %v", err)
+		}
+	}
+	compression, err := cfg.Compression.Compression(Net.DefaultMaxFrameSize)
+	if err != nil {
+		logger.Fatalf("compression: %v", err)
+	}
+	transport := cfg.Transport.TransportConfig()
+	anonymous := func(string) (Actor.Identity, error) { return Actor.Identity{}, nil }
+	// 监听的上下文独立于信号，收到信号后先排空再停止
+	opts := []Actor.KCPOption{
+		Actor.WithTransport(transport),
+		Actor.WithCodec(codec),
+		Actor.WithCompression(compression),
+		Actor.WithHeartbeat(cfg.Heartbeat.HeartbeatConfig()),
+		Actor.WithHandshake(echo.admission.handshake(Actor.TokenHandshake(anonymous)), 0),
+		Actor.WithEventBus(system.EventBus()),
+		Actor.WithRouter(router),
+	}
+	if cfg.TCPPort != 0 {
+		opts = append(opts, Actor.WithTCP(fmt.Sprintf(":%d", cfg.TCPPort)))
+	}
+	listener := Actor.NewKCPListener(cfg.Port, context.Background(), opts...)
+	if err := listener.Start(); err != nil {
+		logger.Fatalf("listen failed: %v", err)
+	}
+	if !transport.Encrypted() {
+		logger.Printf("transport encryption disabled, traffic is plaintext")
+	}
+	logger.Printf("echo server listening on %s", listener.Addr())
+	if addr := listener.TCPAddr(); addr != nil {
+		if !transport.TLS.Enabled() {
+			logger.Printf("tcp transport without tls, traffic is plaintext")
+		}
+		logger.Printf("echo server accepting tcp sessions on %s", addr)
+	}
+
+	broadcastNotice := Maintenance.Broadcast(listener.Broadcast, func(n Maintenance.Notice, err error) {
+		logger.Printf("broadcast maintenance #%d notice: %v", n.Window.ID, err)
+	})
+	maintenance := Maintenance.NewScheduler(Maintenance.Config{
+		Notifier: func(n Maintenance.Notice) {
+			logger.Printf("maintenance #%d in %v: %s", n.Window.ID, n.Remaining, n.Window.Reason)
+			broadcastNotice(n)
+		},
+		Drainer: func(ctx context.Context, w Maintenance.Window) error {
+			logger.Printf("maintenance #%d started, no longer accepting connections", w.ID)
+			return listener.Drain(ctx, Net.ReasonMaintenance, w.Reason)
+		},
+	})
+	go maintenance.Run(ctx)
+
+	echo.guard, err = modules.Register("echo", Lifecycle.DefaultBudget(), Lifecycle.Hooks{})
+	if err != nil {
+		logger.Fatalf("register module: %v", err)
+	}
+	if err := plugins.Load(cfg.Modules); err != nil {
+		logger.Fatalf("load modules: %v", err)
+	}
+	if err := plugins.Start(ctx); err != nil {
+		logger.Fatalf("start modules: %v", err)
+	}
+
+	var scripts *Script.Engine
+	if cfg.Script.Dir != "" {
+		scripts = Script.NewEngine(cfg.Script.EngineConfig(), Script.SystemBindings(system))
+		go scripts.Run(ctx)
+		logger.Printf("scripts loaded from %s", cfg.Script.Dir)
+	}
+
+	if cfg.Admin.Addr != "" {
+		store := Metrics.NewDefaultStore()
+		store.Start(ctx)
+		server := Admin.New(cfg.Admin.AdminConfig(store), system)
+		server.Handle("/admin/maintenance", maintenance.Handler())
+		server.Handle("/admin/modules", modules.Handler())
+		server.Handle("/admin/license", license.Handler())
+		plugins.Mount(server.Mux())
+		if cfg.Admin.BackupDir != "" {
+			server.Handle("/admin/backup", plugins.BackupHandler(cfg.Admin.BackupDir))
+		}
+		if scripts != nil {
+			server.Handle("/admin/scripts", scripts.Handler())
+		}
+		server.AddCheck("draining", func() error {
+			if listener.Draining() {
+				return errors.New("draining sessions")
+			}
+			return nil
+		})
+		if err := server.Start(ctx); err != nil {
+			logger.Fatalf("admin endpoint: %v", err)
+		}
+		logger.Printf("admin endpoint on %s", server.Addr())
+	}
+
+	<-ctx.Done()
+	logger.Printf("shutting down")
+	drainCtx, cancelDrain := context.WithTimeout(context.Background(), 2*time.Second)
+	if err := listener.Drain(drainCtx, Net.ReasonShutdown, ""); err != nil {
+		logger.Printf("drain sessions: %v", err)
+	}
+	cancelDrain()
+	stopCtx, cancelStop := context.WithTimeout(context.Background(), 5*time.Second)
+	if err := plugins.Stop(stopCtx); err != nil {
+		logger.Printf("stop modules: %v", err)
+	}
+	cancelStop()
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), 5*time.Second)
+	if err := system.Shutdown(shutdownCtx); err != nil {
+		logger.Printf("stop actors: %v", err)
+	}
+	cancelShutdown()
+	logger.Printf("echoed %d packets", echo.handled.Load())
+
+	closeCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := Timer.CloseKeyFramePool(closeCtx); err != nil {
+		logger.Printf("close object pools: %v", err)
+	}
+}
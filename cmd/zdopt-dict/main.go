@@ -0,0 +1,82 @@
+// zdopt-dict 由抓取的样本包离线训练 zstd 字典，输出 <class>.dict 供服务端与客户端按消息类别加载
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"zdopt/ZdoptServer/Logs"
+	"zdopt/ZdoptServer/Net"
+)
+
+var logger = Logs.CreateConsoleLogConfig("Dict")
+
+func main() {
+	id := flag.Uint("id", 0, "dictionary id (non-zero, unique across classes)")
+	class := flag.String("class", "", "message class the dictionary is trained for")
+	size := flag.Int("size", Net.DefaultDictionarySize, "dictionary size in bytes")
+	out := flag.String("out", ".", "output directory")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: zdopt-dict -id N -class NAME [flags] SAMPLE...\n"+
+			"each sample file (or every file under a sample directory) is one captured packet\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if *id == 0 || *class == "" || flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	samples, err := readSamples(flag.Args())
+	if err != nil {
+		logger.Fatalf("read samples: %v", err)
+	}
+	dict, err := Net.TrainDictionary(uint32(*id), samples, *size)
+	if err != nil {
+		logger.Fatalf("train: %v", err)
+	}
+
+	// 用训练结果回压样本，输出压缩效果
+	dicts := Net.NewDictionaries()
+	if _, err := dicts.Add(*class, dict); err != nil {
+		logger.Fatalf("load trained dictionary: %v", err)
+	}
+	accepted := dicts.Negotiate([]uint32{uint32(*id)})
+	var in, compressed int
+	for _, s := range samples {
+		c, _ := dicts.Compress(*class, s, accepted)
+		in += len(s)
+		compressed += len(c)
+	}
+
+	path := filepath.Join(*out, *class+Net.DictionaryExt)
+	if err := os.WriteFile(path, dict, 0o644); err != nil {
+		logger.Fatalf("write dictionary: %v", err)
+	}
+	fmt.Printf("%s: id %d, %d bytes, %d samples, %d -> %d bytes (%.1f%%)\n",
+		path, *id, len(dict), len(samples), in, compressed, 100*float64(compressed)/float64(max(in, 1)))
+}
+
+func readSamples(paths []string) ([][]byte, error) {
+	var samples [][]byte
+	for _, root := range paths {
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			samples = append(samples, data)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return samples, nil
+}
@@ -0,0 +1,93 @@
+// zdopt-metrics 从管理端点拉取节点本地时序，以迷你折线形式输出，便于无 Prometheus 时排查负载
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"zdopt/ZdoptServer/Logs"
+	"zdopt/ZdoptServer/Metrics"
+)
+
+var logger = Logs.CreateConsoleLogConfig("Metrics")
+
+type metricNames []string
+
+func (m *metricNames) String() string     { return strings.Join(*m, ",") }
+func (m *metricNames) Set(v string) error { *m = append(*m, v); return nil }
+
+func main() {
+	var names metricNames
+	addr := flag.String("addr", "127.0.0.1:6060", "admin endpoint address")
+	window := flag.Duration("window", 5*time.Minute, "time window to show")
+	width := flag.Int("width", 60, "sparkline width in characters")
+	watch := flag.Duration("watch", 0, "refresh interval, 0 prints once")
+	flag.Var(&names, "name", "metric name (repeatable), defaults to all")
+	flag.Parse()
+
+	for {
+		series, err := fetch(*addr, names, *window)
+		if err != nil {
+			logger.Printf("fetch metrics: %v", err)
+			os.Exit(1)
+		}
+		if *watch > 0 {
+			fmt.Print("\033[H\033[2J")
+		}
+		render(series, *width)
+		if *watch <= 0 {
+			return
+		}
+		time.Sleep(*watch)
+	}
+}
+
+func fetch(addr string, names []string, window time.Duration) ([]Metrics.Series, error) {
+	q := url.Values{"window": {window.String()}}
+	for _, n := range names {
+		q.Add("name", n)
+	}
+	resp, err := http.Get(fmt.Sprintf("http://%s/debug/timeseries?%s", addr, q.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("admin endpoint returned %s", resp.Status)
+	}
+
+	var series []Metrics.Series
+	if err := json.NewDecoder(resp.Body).Decode(&series); err != nil {
+		return nil, err
+	}
+	return series, nil
+}
+
+func render(series []Metrics.Series, width int) {
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "METRIC\tMIN\tMAX\tLAST\t")
+	for _, s := range series {
+		values := Metrics.Values(s.Samples)
+		if len(values) == 0 {
+			fmt.Fprintf(tw, "%s\t-\t-\t-\t\n", s.Name)
+			continue
+		}
+		lo, hi := math.Inf(1), math.Inf(-1)
+		for _, v := range values {
+			if !math.IsNaN(v) && !math.IsInf(v, 0) {
+				lo = math.Min(lo, v)
+				hi = math.Max(hi, v)
+			}
+		}
+		fmt.Fprintf(tw, "%s\t%g\t%g\t%g\t%s\n", s.Name, lo, hi, values[len(values)-1], Metrics.Sparkline(values, width))
+	}
+	_ = tw.Flush()
+}
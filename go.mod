@@ -2,7 +2,15 @@ module zdopt
 
 go 1.23.4
 
-require github.com/xtaci/kcp-go v5.4.20+incompatible
+require (
+	github.com/klauspost/compress v1.18.0
+	github.com/xtaci/kcp-go v5.4.20+incompatible
+	github.com/yuin/gopher-lua v1.1.1
+	golang.org/x/crypto v0.36.0
+	golang.org/x/net v0.37.0
+	google.golang.org/grpc v1.71.1
+	google.golang.org/protobuf v1.36.5
+)
 
 require (
 	github.com/klauspost/cpuid/v2 v2.2.8 // indirect
@@ -12,7 +20,7 @@ require (
 	github.com/templexxx/xor v0.0.0-20191217153810-f85b25db303b // indirect
 	github.com/tjfoc/gmsm v1.4.1 // indirect
 	github.com/xtaci/lossyconn v0.0.0-20200209145036-adba10fffc37 // indirect
-	golang.org/x/crypto v0.36.0 // indirect
-	golang.org/x/net v0.37.0 // indirect
 	golang.org/x/sys v0.31.0 // indirect
+	golang.org/x/text v0.23.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20250115164207-1a7da9e5054f // indirect
 )